@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// result 单个基准测试多次运行的中位数
+type result struct {
+	NsPerOp     float64
+	AllocsPerOp float64
+}
+
+// 基准测试名称末尾的GOMAXPROCS后缀，不同机器上不同，比较时去掉
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// parse 解析go test -bench的输出，同一基准测试的多次运行(-count)取中位数
+// 名称带包路径前缀，避免不同包中的同名基准测试互相覆盖
+func parse(r io.Reader) (map[string]result, error) {
+	samples := make(map[string][]result)
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "pkg:" {
+			pkg = fields[1]
+			continue
+		}
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+
+		var sample result
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("无法解析%s的结果: %w", name, err)
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				sample.NsPerOp = value
+			case "allocs/op":
+				sample.AllocsPerOp = value
+			}
+		}
+		samples[name] = append(samples[name], sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]result, len(samples))
+	for name, runs := range samples {
+		results[name] = result{
+			NsPerOp:     median(runs, func(r result) float64 { return r.NsPerOp }),
+			AllocsPerOp: median(runs, func(r result) float64 { return r.AllocsPerOp }),
+		}
+	}
+	return results, nil
+}
+
+// median 返回指定指标的中位数
+func median(runs []result, metric func(result) float64) float64 {
+	values := make([]float64, len(runs))
+	for i, run := range runs {
+		values[i] = metric(run)
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// report 比较结果
+type report struct {
+	Lines       []string // 每个基准测试的比较结果
+	Regressions []string // 退化的基准测试名称
+}
+
+// String 返回可读的比较结果
+func (r report) String() string {
+	return strings.Join(r.Lines, "\n") + "\n"
+}
+
+// compare 按名称比较当前结果与基线，ns/op或allocs/op超过基线threshold百分比时记为退化
+// 基线中没有的基准测试只提示，基线中有但当前缺失的也只提示，避免新增或改名时阻塞
+func compare(baseline, current map[string]result, threshold float64) report {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var r report
+	for _, name := range names {
+		cur := current[name]
+		base, ok := baseline[name]
+		if !ok {
+			r.Lines = append(r.Lines, fmt.Sprintf("%-70s 新增 %.0f ns/op %.0f allocs/op", name, cur.NsPerOp, cur.AllocsPerOp))
+			continue
+		}
+		nsDelta := percentChange(base.NsPerOp, cur.NsPerOp)
+		allocsDelta := percentChange(base.AllocsPerOp, cur.AllocsPerOp)
+		status := "ok"
+		if nsDelta > threshold || allocsDelta > threshold {
+			status = "退化"
+			r.Regressions = append(r.Regressions, name)
+		}
+		r.Lines = append(r.Lines, fmt.Sprintf("%-70s %s ns/op %+.1f%% (%.0f -> %.0f) allocs/op %+.1f%% (%.0f -> %.0f)",
+			name, status, nsDelta, base.NsPerOp, cur.NsPerOp, allocsDelta, base.AllocsPerOp, cur.AllocsPerOp))
+	}
+
+	missing := make([]string, 0)
+	for name := range baseline {
+		if _, ok := current[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		r.Lines = append(r.Lines, fmt.Sprintf("%-70s 缺失，基线中存在但本次未运行", name))
+	}
+	return r
+}
+
+// percentChange 返回相对基线的变化百分比，基线为0时只要当前大于0即视为无限退化
+func percentChange(base, cur float64) float64 {
+	if base == 0 {
+		if cur == 0 {
+			return 0
+		}
+		return 100 * cur
+	}
+	return (cur - base) / base * 100
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleOutput = `goos: linux
+goarch: amd64
+pkg: ginproject/entity/utility
+BenchmarkHexToJson-8   	  100000	     10000 ns/op	    3734 B/op	      74 allocs/op
+BenchmarkHexToJson-8   	  100000	     12000 ns/op	    3734 B/op	      74 allocs/op
+BenchmarkHexToJson-8   	  100000	     50000 ns/op	    3734 B/op	      74 allocs/op
+BenchmarkClassifyScript/ft_code-8   	  100000	      4000 ns/op	     436 B/op	      35 allocs/op
+PASS
+pkg: ginproject/entity/script
+BenchmarkSplitHistory-4 	     100	    160000 ns/op	  328672 B/op	       6 allocs/op
+`
+
+func TestParse(t *testing.T) {
+	results, err := parse(strings.NewReader(sampleOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("解析出%d项, 期望 3", len(results))
+	}
+	// 多次运行取中位数，去掉GOMAXPROCS后缀
+	if got := results["ginproject/entity/utility.BenchmarkHexToJson"]; got.NsPerOp != 12000 || got.AllocsPerOp != 74 {
+		t.Errorf("HexToJson = %+v", got)
+	}
+	if _, ok := results["ginproject/entity/script.BenchmarkSplitHistory"]; !ok {
+		t.Error("缺少其它包的基准测试")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := map[string]result{
+		"a":    {NsPerOp: 100, AllocsPerOp: 2},
+		"b":    {NsPerOp: 100, AllocsPerOp: 0},
+		"c":    {NsPerOp: 100, AllocsPerOp: 2},
+		"gone": {NsPerOp: 1},
+	}
+	current := map[string]result{
+		"a":   {NsPerOp: 120, AllocsPerOp: 2}, // 在阈值内
+		"b":   {NsPerOp: 90, AllocsPerOp: 1},  // 从无分配变为有分配
+		"c":   {NsPerOp: 200, AllocsPerOp: 2}, // 耗时翻倍
+		"new": {NsPerOp: 5},
+	}
+
+	r := compare(baseline, current, 25)
+	if strings.Join(r.Regressions, ",") != "b,c" {
+		t.Errorf("退化项 = %v, 期望 [b c]", r.Regressions)
+	}
+	if len(r.Lines) != 5 {
+		t.Errorf("输出%d行, 期望 5:\n%s", len(r.Lines), r)
+	}
+}
@@ -0,0 +1,50 @@
+// benchgate 比较基准测试结果与基线，性能显著退化时返回非零退出码
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	baselinePath := flag.String("baseline", "bench/baseline.txt", "基线结果文件，go test -bench -benchmem的输出")
+	threshold := flag.Float64("threshold", 30, "允许的退化百分比，ns/op或allocs/op超过基线该比例时判定为退化")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: benchgate [-baseline 文件] [-threshold 百分比] 当前结果文件")
+		os.Exit(2)
+	}
+
+	baseline, err := parseFile(*baselinePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "读取基线失败:", err)
+		os.Exit(2)
+	}
+	current, err := parseFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "读取当前结果失败:", err)
+		os.Exit(2)
+	}
+	if len(current) == 0 {
+		fmt.Fprintln(os.Stderr, "当前结果中没有基准测试数据")
+		os.Exit(2)
+	}
+
+	report := compare(baseline, current, *threshold)
+	fmt.Print(report.String())
+	if len(report.Regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "%d项基准测试退化超过%.0f%%\n", len(report.Regressions), *threshold)
+		os.Exit(1)
+	}
+}
+
+// parseFile 读取并解析基准测试输出文件
+func parseFile(path string) (map[string]result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f)
+}
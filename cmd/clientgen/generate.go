@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ginproject/service/registry"
+)
+
+// 生成文件的头部说明
+const header = "// Code generated by clientgen from the route registry. DO NOT EDIT.\n\n"
+
+// generator 生成过程中的状态
+type generator struct {
+	imports map[string]string // 包路径到包名
+	names   map[string]string // 包名到包路径，用于发现同名包
+}
+
+// Generate 根据路由生成客户端代码，每个路由生成一个方法，声明了查询参数的路由额外生成查询参数结构体
+func Generate(routes []registry.Route, prefix string) ([]byte, error) {
+	g := &generator{imports: map[string]string{}, names: map[string]string{}}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// APIPrefix 接口路径前缀\nconst APIPrefix = %q\n", prefix)
+
+	seen := make(map[string]string)
+	for _, route := range routes {
+		name := route.HandlerName()
+		if !token.IsExported(name) {
+			return nil, fmt.Errorf("路由 %s %s 的处理函数名%q不能用作方法名，请使用registry.WithName", route.Method, route.Path, name)
+		}
+		key := route.Method + " " + route.Path
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("路由 %s 与 %s 的方法名都是%s，请使用registry.WithName区分", key, other, name)
+		}
+		seen[name] = key
+
+		if err := g.route(&body, name, route); err != nil {
+			return nil, fmt.Errorf("路由 %s: %w", key, err)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	out.WriteString("package client\n\nimport (\n\t\"context\"\n\t\"net/http\"\n\t\"net/url\"\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if len(paths) > 0 {
+		out.WriteString("\n")
+	}
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n\n")
+	out.Write(body.Bytes())
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成代码失败: %w", err)
+	}
+	return code, nil
+}
+
+// route 生成单个路由的查询参数结构体和方法
+func (g *generator) route(w *bytes.Buffer, name string, route registry.Route) error {
+	args := []string{"ctx context.Context"}
+	for _, param := range route.PathParams() {
+		args = append(args, paramName(param)+" string")
+	}
+
+	queryExpr := "nil"
+	if len(route.Query) > 0 {
+		queryType := name + "Query"
+		writeQueryType(w, name, queryType, route.Query)
+		args = append(args, "query *"+queryType)
+		queryExpr = "query.values()"
+	}
+
+	bodyExpr := "nil"
+	switch {
+	case route.Request != nil:
+		typeName, err := g.typeExpr(route.Request)
+		if err != nil {
+			return err
+		}
+		args = append(args, "body "+pointerTo(route.Request, typeName))
+		bodyExpr = "body"
+	case route.Method == http.MethodPost:
+		args = append(args, "body any")
+		bodyExpr = "body"
+	}
+
+	resultType := "[]byte"
+	if route.Response != nil {
+		typeName, err := g.typeExpr(route.Response)
+		if err != nil {
+			return err
+		}
+		resultType = pointerTo(route.Response, typeName)
+	}
+
+	fmt.Fprintf(w, "\n// %s %s\n// %s %s\n", name, route.Summary, route.Method, route.Path)
+	fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), resultType)
+	call := fmt.Sprintf("c.do(ctx, http.Method%s, %s, %s, %s, ", methodConst(route.Method), pathExpr(route.Path), queryExpr, bodyExpr)
+	switch {
+	case route.Response == nil:
+		fmt.Fprintf(w, "\tvar out []byte\n\terr := %s&out)\n\treturn out, err\n}\n", call)
+	case strings.HasPrefix(resultType, "*"):
+		fmt.Fprintf(w, "\tout := new(%s)\n\tif err := %sout); err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n", resultType[1:], call)
+	default:
+		fmt.Fprintf(w, "\tvar out %s\n\tif err := %s&out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n", resultType, call)
+	}
+	return nil
+}
+
+// writeQueryType 生成查询参数结构体，空字符串的参数不发送
+func writeQueryType(w *bytes.Buffer, name, queryType string, params []string) {
+	fmt.Fprintf(w, "\n// %s %s的查询参数\ntype %s struct {\n", queryType, name, queryType)
+	for _, param := range params {
+		fmt.Fprintf(w, "\t%s string // %s\n", exportedName(param), param)
+	}
+	fmt.Fprintf(w, "}\n\nfunc (q *%s) values() url.Values {\n\tif q == nil {\n\t\treturn nil\n\t}\n\tvalues := url.Values{}\n", queryType)
+	for _, param := range params {
+		fmt.Fprintf(w, "\tif q.%s != \"\" {\n\t\tvalues.Set(%q, q.%s)\n\t}\n", exportedName(param), param, exportedName(param))
+	}
+	fmt.Fprintf(w, "\treturn values\n}\n")
+}
+
+// typeExpr 返回类型在生成代码中的写法，并登记需要导入的包
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	switch {
+	case t.Name() != "" && t.PkgPath() == "":
+		return t.Name(), nil
+	case t.Name() != "":
+		pkg := t.String()[:strings.LastIndex(t.String(), ".")]
+		if path, ok := g.names[pkg]; ok && path != t.PkgPath() {
+			return "", fmt.Errorf("包%s与%s同名", t.PkgPath(), path)
+		}
+		g.names[pkg] = t.PkgPath()
+		g.imports[t.PkgPath()] = pkg
+		return t.String(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	default:
+		return "", fmt.Errorf("不支持的类型%s", t)
+	}
+}
+
+// pointerTo 结构体类型使用指针，切片、映射和指针类型保持原样
+func pointerTo(t reflect.Type, typeName string) string {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer:
+		return typeName
+	default:
+		return "*" + typeName
+	}
+}
+
+// pathExpr 生成拼接请求路径的表达式，路径参数经过转义
+func pathExpr(path string) string {
+	var parts []string
+	literal := ""
+	for i, segment := range strings.Split(path, "/") {
+		if i > 0 {
+			literal += "/"
+		}
+		idx := strings.IndexAny(segment, ":*")
+		if idx < 0 {
+			literal += segment
+			continue
+		}
+		literal += segment[:idx]
+		if literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+			literal = ""
+		}
+		parts = append(parts, "url.PathEscape("+paramName(segment[idx+1:])+")")
+	}
+	if literal != "" {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, "+")
+}
+
+// methodConst 返回HTTP方法对应的net/http常量名后缀
+func methodConst(method string) string {
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+// exportedName 将下划线分隔的参数名转换为导出的字段名，如from_height转换为FromHeight
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// paramName 将路径参数名转换为方法参数名，如ft_contract_id转换为ftContractID
+func paramName(name string) string {
+	first, rest, _ := strings.Cut(name, "_")
+	param := strings.ToLower(first) + exportedName(rest)
+	if token.IsKeyword(param) {
+		param += "Param"
+	}
+	return param
+}
+
+// 转换名称时整体大写的缩写
+var initialisms = map[string]bool{"ID": true, "URL": true, "NFT": true, "FT": true, "LP": true}
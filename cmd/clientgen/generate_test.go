@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// 路由变更后需要执行make client重新生成客户端
+func TestGeneratedClientUpToDate(t *testing.T) {
+	want, err := generateFromServices()
+	if err != nil {
+		t.Fatalf("生成客户端失败: %v", err)
+	}
+	got, err := os.ReadFile("../../pkg/client/client_gen.go")
+	if err != nil {
+		t.Fatalf("读取生成的客户端失败: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("pkg/client/client_gen.go与路由注册表不一致，请执行make client")
+	}
+}
+
+func TestPathExpr(t *testing.T) {
+	tests := map[string]string{
+		"/health":                   `"/health"`,
+		"/address/:address/history": `"/address/"+url.PathEscape(address)+"/history"`,
+		"/ft/lp/unspent/by/script/hash:script_hash": `"/ft/lp/unspent/by/script/hash"+url.PathEscape(scriptHash)`,
+		"/blocks/:from/:to/txs":                     `"/blocks/"+url.PathEscape(from)+"/"+url.PathEscape(to)+"/txs"`,
+	}
+	for path, want := range tests {
+		if got := pathExpr(path); got != want {
+			t.Errorf("pathExpr(%q) = %s, 期望 %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// clientgen 根据路由注册表生成pkg/client中的类型化客户端
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"ginproject/service"
+	"ginproject/service/registry"
+)
+
+func main() {
+	output := flag.String("o", "pkg/client/client_gen.go", "生成文件的路径")
+	flag.Parse()
+
+	code, err := generateFromServices()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "生成客户端失败:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, code, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "写入客户端失败:", err)
+		os.Exit(1)
+	}
+}
+
+// generateFromServices 注册全部服务的路由并生成客户端代码
+func generateFromServices() ([]byte, error) {
+	reg := registry.New()
+	service.RegisterServices(reg)
+	return Generate(reg.Routes(), service.APIPrefix)
+}
@@ -0,0 +1,62 @@
+// consistency 抽样比较ElectrumX路径和数据库路径的结果，发现差异时返回非零退出码，用于索引器发布前的核验
+// 使用与API服务相同的配置文件连接数据库、节点和ElectrumX
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"ginproject/entity/consistency"
+	consistencylogic "ginproject/logic/consistency"
+	"ginproject/logic/job"
+	"ginproject/repo"
+)
+
+func main() {
+	addresses := flag.Int("addresses", consistency.DefaultSampleSize, "抽样的地址数")
+	ftHoldings := flag.Int("ft-holdings", consistency.DefaultSampleSize, "抽样的代币持有数")
+	settleDepth := flag.Int64("settle-depth", consistency.DefaultSettleDepth, "只比较至少有该确认数的数据")
+	timeout := flag.Duration("timeout", 30*time.Minute, "整个检查的超时时间")
+	output := flag.String("o", "", "报告输出文件，为空时输出到标准输出")
+	flag.Parse()
+
+	req := &consistency.CheckRequest{Addresses: *addresses, FtHoldings: *ftHoldings, SettleDepth: *settleDepth}
+	if err := req.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "参数无效:", err)
+		os.Exit(2)
+	}
+	if err := repo.Global_init(); err != nil {
+		fmt.Fprintln(os.Stderr, "初始化失败:", err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, *timeout)
+	defer cancelTimeout()
+
+	report, err := consistencylogic.Run(ctx, req, &job.Progress{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "一致性检查失败:", err)
+		os.Exit(2)
+	}
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	if *output == "" {
+		fmt.Println(string(data))
+	} else if err := os.WriteFile(*output, append(data, '\n'), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "写入报告失败:", err)
+		os.Exit(2)
+	}
+
+	fmt.Fprintf(os.Stderr, "抽样地址%d个、代币持有%d个，完成%d项检查，跳过%d项，发现%d处差异\n",
+		report.Addresses, report.FtHoldings, report.Checks, report.Skipped, len(report.Discrepancies))
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
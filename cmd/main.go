@@ -1,24 +1,36 @@
 package main
 
 import (
+	"context"
 	"os"
-
+	"time"
+
+	addresslogic "ginproject/logic/address"
+	alertlogic "ginproject/logic/alert"
+	analyticslogic "ginproject/logic/analytics"
+	broadcastlogic "ginproject/logic/broadcast"
+	eventslogic "ginproject/logic/events"
+	exchangelogic "ginproject/logic/exchange"
+	ftlogic "ginproject/logic/ft"
+	nftlogic "ginproject/logic/nft"
+	"ginproject/logic/scheduler"
+	subscriptionlogic "ginproject/logic/subscription"
+	walletlogic "ginproject/logic/wallet"
+	"ginproject/middleware/auth"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/costclass"
+	"ginproject/middleware/errmap"
+	"ginproject/middleware/fingerprint"
+	"ginproject/middleware/healthscore"
+	"ginproject/middleware/httpcache"
 	"ginproject/middleware/log"
+	"ginproject/middleware/ratelimit"
+	"ginproject/middleware/recovery"
 	"ginproject/middleware/trace"
 	"ginproject/repo"
+	"ginproject/repo/db"
 	"ginproject/service"
-	address_service "ginproject/service/address_service"
-	block_service "ginproject/service/block_service"
-	chain_info_service "ginproject/service/chain_info_service"
-	exchange_service "ginproject/service/exchange_service"
-	ft_service "ginproject/service/ft_service"
-	health_service "ginproject/service/health_service"
-	mempool_service "ginproject/service/mempool_service"
-	multisig_service "ginproject/service/multisig_service"
-	nft_service "ginproject/service/nft_service"
-	script_service "ginproject/service/script_service"
-	transaction_service "ginproject/service/transaction"
-	tx_broadcast_service "ginproject/service/tx_broadcast_service"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,9 +39,15 @@ var router *gin.Engine
 
 func init() {
 	router = gin.New()
-	router.Use(gin.Recovery())
+	// 处理函数把gin.Context直接作为context传给下层时，截止时间和取消信号取自请求上下文
+	router.ContextWithFallback = true
+	router.Use(recovery.Recovery())
 	// 添加trace中间件
 	router.Use(trace.GinMiddleware())
+	// 添加访问日志中间件，记录请求指纹
+	router.Use(fingerprint.AccessLog())
+	// 添加响应压缩中间件，按配置压缩较大的JSON响应
+	router.Use(httpcache.Gzip())
 }
 
 func main() {
@@ -38,140 +56,115 @@ func main() {
 		log.Error("全局初始化失败", "错误:", err)
 		os.Exit(1)
 	}
-	// 注册路由
-	registerRoutes(router)
+	// 集群模式下从Redis同步运行时切换的只读开关
+	auth.StartReadOnlySync(context.Background())
+
+	// 启动区块监听器，统一维护计算确认数使用的链顶
+	chaintip.StartListener(context.Background())
+
+	// 启动跟踪钱包后台同步任务
+	walletlogic.StartSyncer(context.Background())
+
+	// 启动跟踪钱包每日汇总通知任务
+	walletlogic.StartDigest(context.Background())
+
+	// 开启事件发布时跟随链顶发布区块、交易和转账事件
+	eventslogic.StartPublisher(context.Background())
+
+	// 从事件总线接收区块事件，出块后清空随新区块失效的缓存
+	eventslogic.StartInvalidator(context.Background())
+
+	// 启用分析库时从事件总线消费交易事件写入分析库
+	analyticslogic.StartIngester(context.Background())
+
+	// 从事件总线消费交易事件，维护地址首次出现和最近活动的区块
+	addresslogic.StartActivityIndexer(context.Background())
+
+	// 从事件总线消费代币转账事件，记录转入销毁地址的代币
+	ftlogic.StartBurnIndexer(context.Background())
+
+	// 从事件总线消费代币转账事件，记录池兑换并聚合代币价格K线
+	ftlogic.StartCandleIndexer(context.Background())
+
+	// 启用WebSocket订阅时建立到ElectrumX的订阅连接，并从事件总线接收区块事件推送给订阅的客户端
+	subscriptionlogic.StartHub(context.Background())
+
+	// 注册并启动周期任务，共享任务通过数据库锁保证多个实例中同一时间只有一个在运行
+	registerJobs()
+	scheduler.Start(context.Background())
+
+	// 注册路由，配置了内部监听时管理接口只挂载到内部路由
+	var internal *gin.Engine
+	if service.InternalEnabled() {
+		internal = service.NewInternalRouter()
+	}
+	registerRoutes(router, internal)
+	for _, r := range []*gin.Engine{router, internal} {
+		if r == nil {
+			continue
+		}
+		if err := service.TrustProxies(r); err != nil {
+			log.Error("受信任的反向代理配置无效", "错误:", err)
+			os.Exit(1)
+		}
+	}
 
 	// 创建HTTP服务器并启动
-	srv := service.CreateServer(router)
+	srv := service.CreateServer(router, internal)
 	srv.Start()
 }
 
-func registerRoutes(r *gin.Engine) {
+func registerJobs() {
+	// 每个实例各自刷新进程内的汇率快照和缓存
+	scheduler.Register(scheduler.Job{Name: "exchange_rate", Interval: time.Minute, Local: true, Run: exchangelogic.RefreshExchangeRate})
+	// 按各实例自身观察到的指标评估告警规则，未配置规则时不做任何事
+	scheduler.Register(scheduler.Job{Name: "alert_evaluate", Interval: time.Minute, Local: true, Run: alertlogic.Evaluate})
+	if db.GetDB() == nil {
+		return
+	}
+	scheduler.Register(scheduler.Job{Name: "ft_units_warm", Interval: 10 * time.Minute, Local: true, Run: ftlogic.WarmTokenUnits})
+	scheduler.Register(scheduler.Job{Name: "pool_reserve_refresh", Interval: time.Minute, Local: true, Run: ftlogic.RefreshPoolReserves})
+
+	// 按配置周期保存代币持有者排名快照，用于计算名次变化
+	scheduler.Register(scheduler.Job{Name: "ft_holder_snapshot", Interval: 10 * time.Minute, Run: ftlogic.SnapshotHolderRanks})
+	// 增量解析NFT的JSON属性建立属性索引，供NFT搜索按属性过滤
+	scheduler.Register(scheduler.Job{Name: "nft_attribute_index", Interval: time.Minute, Run: nftlogic.IndexNftAttributes})
+	// 清理过期的已重放广播失败记录
+	scheduler.Register(scheduler.Job{Name: "broadcast_failure_prune", Interval: time.Hour, Run: broadcastlogic.PruneBroadcastFailures})
+}
+
+func registerRoutes(r *gin.Engine, internal *gin.Engine) {
+	// 各服务将路由及其元数据注册到路由表，再统一挂载到API路由组
+	reg := registry.New()
+	reg.UseScope(registry.ScopeAdmin, auth.AdminToken())
+	// 只读模式下拒绝所有写入接口，管理接口不受影响，便于在运行时切换
+	reg.UseScope(registry.ScopeWrite, auth.ReadOnly())
+	// 开启按客户端限流时各开销等级分别计数，一个客户端的heavy请求不会占用其light配额
+	for _, cost := range []registry.CostClass{registry.CostLight, registry.CostNormal, registry.CostHeavy} {
+		reg.UseCost(cost, ratelimit.Group(string(cost)))
+	}
+	// 开启限流时健康评分过低按开销等级拒绝请求，light接口始终放行
+	reg.UseCost(registry.CostHeavy, healthscore.ShedHeavy())
+	reg.UseCost(registry.CostNormal, healthscore.ShedNormal())
+	// 各开销等级按配置设置请求超时和并发上限，heavy请求排满时不会拖慢其它等级
+	for _, cost := range []registry.CostClass{registry.CostLight, registry.CostNormal, registry.CostHeavy} {
+		reg.UseCost(cost, costclass.Limit(string(cost)))
+	}
+	// 可缓存的GET接口按响应内容生成ETag，客户端轮询时内容未变则返回304
+	reg.UseCacheable(httpcache.ETag())
+	// 处理函数通过c.Error记录的错误统一转换为带错误码的响应，需在ETag等缓冲响应的中间件之内执行
+	reg.UseInner(errmap.Handler())
+
+	// 各服务的路由
+	service.RegisterServices(reg)
+
 	// 创建API路由组，设置前缀
-	apiGroup := r.Group("/v1/tbc/main")
-
-	// 添加健康检查端点
-	apiGroup.GET("/health", health_service.NewHealthService().HealthCheck)
-
-	// 注册交易所服务API
-	exchangeService := exchange_service.NewExchangeService()
-	apiGroup.GET("/exchangerate", exchangeService.GetExchangeRate)
-
-	// 注册FT服务API
-	ftService := ft_service.NewFtService()
-	apiGroup.GET("/ft/balance/address/:address/contract/:contract_id", ftService.GetFtBalanceByAddress)
-	apiGroup.GET("/ft/utxo/address/:address/contract/:contract_id", ftService.GetFtUtxoByAddress)
-	apiGroup.GET("/ft/info/contract/id/:contract_id", ftService.GetFtInfoByContractId)
-	apiGroup.POST("/ft/balance/address/:address/contract/ids", ftService.GetMultiFtBalanceByAddress)
-	apiGroup.GET("/ft/pool/nft/info/contract/id/:ft_contract_id", ftService.GetPoolNFTInfoByContractId)
-	apiGroup.GET("/ft/lp/unspent/by/script/hash:script_hash", ftService.GetLPUnspentByScriptHash)
-	apiGroup.GET("/ft/history/address/:address/contract/:contract_id/page/:page/size/:size", ftService.GetFtHistoryByAddress)
-	// 添加获取代币列表的路由
-	apiGroup.GET("/ft/tokens/page/:page/size/:size/orderby/:order_by", ftService.GetFtTokenList)
-	// 添加通过合并脚本获取代币列表的路由
-	apiGroup.GET("/ft/tokens/held/by/combine/script/:combine_script", ftService.GetFtTokenListHeldByCombineScript)
-	// 添加解析FT交易历史的路由
-	apiGroup.GET("/ft/decode/tx/history/:txid", ftService.DecodeFtTransactionHistory)
-	// 添加获取代币相关流动池列表的路由
-	apiGroup.GET("/ft/pools/of/token/contract/id/:ft_contract_id", ftService.GetPoolsOfTokenByContractId)
-	// 添加获取代币历史交易记录的路由
-	apiGroup.GET("/ft/token/history/contract/id/:ft_contract_id/page/:page/size/:size", ftService.GetTokenHistoryByContractId)
-	// 添加获取池子历史记录的路由
-	apiGroup.GET("/ft/pool/history/pool/id/:pool_id/page/:page/size/:size", ftService.GetPoolHistoryByPoolId)
-	// 添加获取交易池列表的路由
-	apiGroup.GET("/ft/pool/list/page/:page/size/:size", ftService.GetPoolList)
-	// 添加获取地址持有的代币列表的路由
-	apiGroup.GET("/ft/tokens/held/by/address/:address", ftService.GetTokenListHeldByAddress)
-	// 添加获取代币持有者排名的路由
-	apiGroup.GET("/ft/holder/rank/contract/:contract_id/page/:page/size/:size", ftService.GetHolderRankByContractId)
-	// 添加根据合并脚本和合约ID获取FT UTXO的路由
-	apiGroup.GET("/ft/utxo/combine/script/:combine_script/contract/:contract_id", ftService.GetFtUtxoByCombineScript)
-	// 添加合并脚本和合约哈希获取FT余额的路由
-	apiGroup.GET("/ft/balance/combine/script/:combine_script/contract/:contract_hash", ftService.GetFtBalanceByCombineScript)
-
-	// 注册地址服务API
-	addressService := address_service.NewAddressService()
-	apiGroup.GET("/address/:address/unspent", addressService.GetAddressUnspentUtxos)
-	// 添加获取地址历史交易的路由
-	apiGroup.GET("/address/:address/history", addressService.GetAddressHistory)
-	// 添加获取地址历史交易分页的路由
-	apiGroup.GET("/address/:address/history/page/:page", addressService.GetAddressHistoryPagedFromDB)
-	// 添加获取地址历史交易记录分页使用数据库查询的路由
-	apiGroup.GET("/address/:address/allhistory/page/:page", addressService.GetAddressHistoryPaged)
-	// 添加获取地址余额的路由
-	apiGroup.GET("/address/:address/get/balance", addressService.GetAddressBalance)
-	// 添加获取地址冻结余额的路由
-	apiGroup.GET("/address/:address/get/balance/frozen", addressService.GetAddressFrozenBalance)
-
-	// 注册区块服务API
-	blockService := block_service.NewBlockService()
-	// 添加通过高度获取区块详情的路由
-	apiGroup.GET("/block/height/:height", blockService.GetBlockByHeight)
-	// 添加通过哈希获取区块详情的路由
-	apiGroup.GET("/block/hash/:hash", blockService.GetBlockByHash)
-	// 添加通过高度获取区块头信息的路由
-	apiGroup.GET("/block/height/:height/header", blockService.GetBlockHeaderByHeight)
-	// 添加通过哈希获取区块头信息的路由
-	apiGroup.GET("/block/hash/:hash/header", blockService.GetBlockHeaderByHash)
-	// 添加获取附近10个区块头信息的路由
-	apiGroup.GET("/block/headers", blockService.GetNearby10Headers)
-
-	// 注册区块链信息服务API
-	chainInfoService := chain_info_service.NewChainInfoService()
-	// 添加获取区块链信息的路由
-	apiGroup.GET("/chain/info", chainInfoService.GetChainInfo)
-
-	// 注册内存池服务API
-	mempoolService := mempool_service.NewMempoolService()
-	// 添加获取内存池交易列表的路由
-	apiGroup.GET("/mempool/mempool/txs", mempoolService.GetMemPoolTxs)
-
-	// 注册脚本服务API
-	scriptService := script_service.NewScriptService()
-	apiGroup.GET("/script/hash/:script_hash/unspent", scriptService.GetScriptUnspent)
-	apiGroup.GET("/script/hash/:script_hash/history", scriptService.GetScriptHistory)
-
-	// 注册多签名服务API
-	multisigService := multisig_service.NewMultisigService()
-	// 添加根据地址获取多签名地址及其公钥列表的路由
-	apiGroup.GET("/multisig/pubkeys/address/:address", multisigService.GetMultiWalletByAddress)
-
-	// 注册NFT服务API
-	nftService := nft_service.NewNftService()
-	// 1. 获取地址的NFT集合
-	apiGroup.GET("/nft/collection/address/:address/page/:page/size/:size", nftService.GetCollectionsByAddress)
-	// 2. 获取地址的NFT资产
-	apiGroup.GET("/nft/address/:address/page/:page/size/:size", nftService.GetNftsByAddress)
-	// 3. 获取脚本哈希的NFT资产
-	apiGroup.GET("/nft/script/hash/:script_hash/page/:page/size/:size", nftService.GetNftsByScriptHash)
-	// 4. 获取集合的NFT资产
-	apiGroup.GET("/nft/collection/id/:collection_id/page/:page/size/:size", nftService.GetNftsByCollectionId)
-	// 5. 获取地址的NFT交易历史
-	apiGroup.GET("/nft/history/address/:address/page/:page/size/:size", nftService.GetNftHistory)
-	// 6. 获取所有NFT集合
-	apiGroup.GET("/nft/collections/page/:page/size/:size", nftService.GetAllCollections)
-	// 7. 获取集合详细信息
-	apiGroup.GET("/nft/collection/info/:collection_id", nftService.GetDetailCollectionInfo)
-	// 8. 根据合约ID获取NFT信息
-	apiGroup.POST("/nft/infos/contract_ids", nftService.GetNftsByContractIds)
-
-	// 注册交易广播服务API
-	txBroadcastService := tx_broadcast_service.NewTxBroadcastService()
-	// 广播单笔原始交易
-	apiGroup.POST("/broadcast/tx/raw", txBroadcastService.BroadcastTxRaw)
-	// 批量广播原始交易
-	apiGroup.POST("/broadcast/txs/raw", txBroadcastService.BroadcastTxsRaw)
-
-	// 注册交易服务API
-	txService := transaction_service.NewTransactionService()
-	// 广播单笔原始交易
-	apiGroup.POST("/tx/raw", txBroadcastService.BroadcastTxRaw)
-	// 解码原始交易
-	apiGroup.POST("/tx/raw/decode", txService.DecodeTxRaw)
-	// 获取交易原始十六进制数据
-	apiGroup.GET("/tx/hex/:txid", txService.GetTxRawHex)
-	// 通过交易ID解码交易
-	apiGroup.GET("/tx/hex/:txid/decode", txService.DecodeTxByHash)
-	// 获取交易输入数据
-	apiGroup.POST("/tx/vins", txService.GetTxVins)
+	if internal == nil {
+		reg.Mount(r.Group(service.APIPrefix))
+	} else {
+		isAdmin := func(route registry.Route) bool { return route.Auth == registry.ScopeAdmin }
+		reg.MountIf(r.Group(service.APIPrefix), func(route registry.Route) bool { return !isAdmin(route) })
+		reg.MountIf(internal.Group(service.APIPrefix), isAdmin)
+	}
+	log.Info("路由注册完成", "数量:", len(reg.Routes()))
 }
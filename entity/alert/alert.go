@@ -0,0 +1,23 @@
+package alert
+
+// 告警规则的状态
+const (
+	StateOk      = "ok"      // 未满足告警条件
+	StatePending = "pending" // 已满足条件，连续次数未达到要求
+	StateFiring  = "firing"  // 告警中
+	StateNoData  = "nodata"  // 指标暂无数据，保持上一次的告警状态
+)
+
+// RuleStatus 告警规则的最近一次评估结果，每个实例各自评估
+type RuleStatus struct {
+	Name        string  `json:"name"`
+	Metric      string  `json:"metric"`
+	Condition   string  `json:"condition"`       // 告警条件，如：mempool_size > 50000
+	State       string  `json:"state"`           // ok、pending、firing或nodata
+	Value       float64 `json:"value"`           // 最近一次参与比较的值，变化率规则为窗口内的变化量
+	Matches     int     `json:"matches"`         // 连续满足条件的次数
+	FiringSince int64   `json:"firing_since"`    // 开始告警的时间(Unix秒)，未告警时为0
+	NotifiedAt  int64   `json:"notified_at"`     // 最近一次发送通知的时间(Unix秒)
+	EvaluatedAt int64   `json:"evaluated_at"`    // 最近一次评估的时间(Unix秒)
+	Error       string  `json:"error,omitempty"` // 最近一次发送通知失败的原因
+}
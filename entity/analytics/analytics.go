@@ -0,0 +1,93 @@
+package analytics
+
+import (
+	"errors"
+
+	"ginproject/entity/event"
+	"ginproject/entity/transaction"
+)
+
+const (
+	// MinActivityInterval 活跃度时间序列的最小统计周期(秒)
+	MinActivityInterval = 60
+	// MaxActivityBuckets 单次查询最多返回的时间桶数量
+	MaxActivityBuckets = 1000
+)
+
+// 错误定义
+var (
+	ErrInvalidActivityRange    = errors.New("起始时间必须小于结束时间")
+	ErrInvalidActivityInterval = errors.New("统计周期不能小于60秒")
+	ErrTooManyActivityBuckets  = errors.New("时间范围过大，单次最多返回1000个统计周期")
+)
+
+// TxRecord 写入分析库的交易摘要
+type TxRecord struct {
+	Height    int64              `json:"height"`
+	BlockTime int64              `json:"block_time"`
+	Txid      string             `json:"txid"`
+	TxIndex   int                `json:"tx_index"`
+	TxType    transaction.TxType `json:"tx_type"`
+	Size      int                `json:"size"`
+	VinCount  int                `json:"vin_count"`
+	VoutCount int                `json:"vout_count"`
+	TotalOut  float64            `json:"total_out"`
+	Addresses []string           `json:"addresses"`
+}
+
+// NewTxRecord 将交易事件转换为分析库记录
+func NewTxRecord(e event.TxEvent) TxRecord {
+	addresses := e.Addresses
+	if addresses == nil {
+		addresses = []string{}
+	}
+	return TxRecord{
+		Height:    e.Height,
+		BlockTime: e.BlockTime,
+		Txid:      e.Txid,
+		TxIndex:   e.Index,
+		TxType:    e.TxType,
+		Size:      e.Size,
+		VinCount:  e.VinCount,
+		VoutCount: e.VoutCount,
+		TotalOut:  e.TotalOut,
+		Addresses: addresses,
+	}
+}
+
+// ActivityQuery 活跃度时间序列查询参数，时间均为Unix秒
+type ActivityQuery struct {
+	From     int64
+	To       int64
+	Interval int64
+}
+
+// Validate 校验查询参数
+func (q ActivityQuery) Validate() error {
+	if q.From >= q.To {
+		return ErrInvalidActivityRange
+	}
+	if q.Interval < MinActivityInterval {
+		return ErrInvalidActivityInterval
+	}
+	if (q.To-q.From)/q.Interval >= MaxActivityBuckets {
+		return ErrTooManyActivityBuckets
+	}
+	return nil
+}
+
+// ActivityPoint 一个统计周期内的链上活跃度
+type ActivityPoint struct {
+	Time   int64    `json:"time"` // 周期起始时间
+	Txs    int64    `json:"txs"`
+	Volume *float64 `json:"volume,omitempty"` // 输出总额(TBC)，仅分析库提供
+}
+
+// ActivityResponse 活跃度时间序列响应
+type ActivityResponse struct {
+	Source   string          `json:"source"` // 数据来源：mysql或分析库类型
+	From     int64           `json:"from"`
+	To       int64           `json:"to"`
+	Interval int64           `json:"interval"`
+	Points   []ActivityPoint `json:"points"`
+}
@@ -0,0 +1,21 @@
+package analytics
+
+import "testing"
+
+func TestActivityQueryValidate(t *testing.T) {
+	tests := []struct {
+		query ActivityQuery
+		want  error
+	}{
+		{ActivityQuery{From: 0, To: 3600, Interval: 60}, nil},
+		{ActivityQuery{From: 10, To: 10, Interval: 60}, ErrInvalidActivityRange},
+		{ActivityQuery{From: 0, To: 3600, Interval: 59}, ErrInvalidActivityInterval},
+		{ActivityQuery{From: 0, To: 60 * MaxActivityBuckets, Interval: 60}, ErrTooManyActivityBuckets},
+		{ActivityQuery{From: 0, To: 60*MaxActivityBuckets - 1, Interval: 60}, nil},
+	}
+	for _, tt := range tests {
+		if err := tt.query.Validate(); err != tt.want {
+			t.Errorf("%+v: 期望 %v，实际 %v", tt.query, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code 机器可读的错误码，客户端据此决定是否重试
+type Code string
+
+const (
+	CodeInvalidParam    Code = "INVALID_PARAM"    // 请求参数无效，不应重试
+	CodeNotFound        Code = "NOT_FOUND"        // 记录不存在，不应重试
+	CodeUpstreamTimeout Code = "UPSTREAM_TIMEOUT" // 节点或ElectrumX响应超时，可以重试
+	CodeRequestTimeout  Code = "REQUEST_TIMEOUT"  // 请求处理超过所在开销等级的时限，可以缩小查询范围或稍后重试
+	CodeNodeUnavailable Code = "NODE_UNAVAILABLE" // 节点或ElectrumX不可用，可以稍后重试
+	CodeInternal        Code = "INTERNAL"         // 其它服务端错误
+)
+
+// ErrNotFound 记录不存在，数据库和节点RPC的未找到错误都包装了该错误，错误处理中间件将其转换为CodeNotFound
+var ErrNotFound = errors.New("记录不存在")
+
+// Error 带错误码的应用错误，由错误处理中间件转换为对应的HTTP状态码和响应体
+type Error struct {
+	Code    Code
+	Message string // 返回给客户端的说明
+	Err     error  // 原始错误，只记录日志，不返回给客户端
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status 返回错误码对应的HTTP状态码
+func (e *Error) Status() int {
+	switch e.Code {
+	case CodeInvalidParam:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeUpstreamTimeout, CodeRequestTimeout:
+		return http.StatusGatewayTimeout
+	case CodeNodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Retryable 相同请求稍后重试是否可能成功
+func (e *Error) Retryable() bool {
+	return e.Code == CodeUpstreamTimeout || e.Code == CodeRequestTimeout || e.Code == CodeNodeUnavailable
+}
+
+// InvalidParam 创建参数错误
+func InvalidParam(message string) *Error {
+	return &Error{Code: CodeInvalidParam, Message: message}
+}
+
+// InvalidParamf 按格式创建参数错误
+func InvalidParamf(format string, args ...any) *Error {
+	return InvalidParam(fmt.Sprintf(format, args...))
+}
+
+// NotFound 创建记录不存在错误
+func NotFound(message string) *Error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+// UpstreamTimeout 创建上游超时错误
+func UpstreamTimeout(message string, err error) *Error {
+	return &Error{Code: CodeUpstreamTimeout, Message: message, Err: err}
+}
+
+// RequestTimeout 创建请求处理超时错误
+func RequestTimeout(message string, err error) *Error {
+	return &Error{Code: CodeRequestTimeout, Message: message, Err: err}
+}
+
+// NodeUnavailable 创建上游不可用错误
+func NodeUnavailable(message string, err error) *Error {
+	return &Error{Code: CodeNodeUnavailable, Message: message, Err: err}
+}
+
+// Internal 创建服务端错误，message为返回给客户端的说明
+// 错误处理中间件仍会按err的原因细分为记录不存在、上游超时或不可用
+func Internal(message string, err error) *Error {
+	return &Error{Code: CodeInternal, Message: message, Err: err}
+}
+
+// As 返回错误链中的应用错误
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Response 错误响应体，code与HTTP状态码一致
+type Response struct {
+	Code      int    `json:"code"`
+	ErrorCode Code   `json:"error_code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+}
+
+// NewResponse 创建错误响应体
+func NewResponse(e *Error) Response {
+	return Response{Code: e.Status(), ErrorCode: e.Code, Message: e.Message, Retryable: e.Retryable()}
+}
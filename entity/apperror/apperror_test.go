@@ -0,0 +1,38 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("连接被拒绝")
+	cases := []struct {
+		err       *Error
+		status    int
+		retryable bool
+	}{
+		{InvalidParamf("页码%d无效", -1), http.StatusBadRequest, false},
+		{NotFound("代币不存在"), http.StatusNotFound, false},
+		{UpstreamTimeout("节点响应超时", cause), http.StatusGatewayTimeout, true},
+		{RequestTimeout("请求处理超时", cause), http.StatusGatewayTimeout, true},
+		{NodeUnavailable("节点不可用", cause), http.StatusServiceUnavailable, true},
+		{Internal("查询失败", cause), http.StatusInternalServerError, false},
+	}
+	for _, tc := range cases {
+		if tc.err.Status() != tc.status || tc.err.Retryable() != tc.retryable {
+			t.Errorf("%s: 状态码 %d 可重试 %v, 期望 %d %v", tc.err.Code, tc.err.Status(), tc.err.Retryable(), tc.status, tc.retryable)
+		}
+	}
+
+	wrapped := fmt.Errorf("处理请求: %w", NodeUnavailable("节点不可用", cause))
+	e, ok := As(wrapped)
+	if !ok || e.Code != CodeNodeUnavailable || !errors.Is(wrapped, cause) {
+		t.Fatalf("As(%v) = %v, %v", wrapped, e, ok)
+	}
+	if resp := NewResponse(e); resp.Code != http.StatusServiceUnavailable || resp.Message != "节点不可用" || !resp.Retryable {
+		t.Fatalf("NewResponse = %+v, 响应中不应包含原始错误", resp)
+	}
+}
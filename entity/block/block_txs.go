@@ -0,0 +1,45 @@
+package block
+
+import (
+	"fmt"
+
+	"ginproject/entity/utility"
+)
+
+// BlockTxsRequest 分页获取区块交易的请求参数
+type BlockTxsRequest struct {
+	Hash string `uri:"hash" binding:"required"`
+	Page int    `uri:"page"` // 页码（从0开始）
+	Size int    `uri:"size"` // 每页交易数
+}
+
+// Validate 验证请求参数的合法性
+func (req *BlockTxsRequest) Validate() error {
+	if err := ValidateBlockHash(req.Hash); err != nil {
+		return err
+	}
+	if req.Page < 0 {
+		return fmt.Errorf("页码必须大于等于0")
+	}
+	return utility.ValidatePageSize(utility.PageEndpointBlockTxs, req.Size)
+}
+
+// BlockTxsResponse 区块交易分页响应，交易按在区块中的顺序排列
+type BlockTxsResponse struct {
+	Hash          string            `json:"hash"`
+	Height        int64             `json:"height"`
+	Confirmations int64             `json:"confirmations"` // 小于0表示区块已不在主链上
+	TxCount       int               `json:"tx_count"`
+	Result        []TxScanSummary   `json:"result"`
+	Meta          *utility.PageMeta `json:"meta"`
+}
+
+// PageTxids 返回从0开始的第page页交易ID，页码超出范围时返回空列表
+func PageTxids(txids []string, page, size int) []string {
+	start := int64(page) * int64(size)
+	if start >= int64(len(txids)) {
+		return []string{}
+	}
+	end := min(start+int64(size), int64(len(txids)))
+	return txids[start:end]
+}
@@ -0,0 +1,24 @@
+package block
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPageTxids(t *testing.T) {
+	txids := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		page, size int
+		want       []string
+	}{
+		{page: 0, size: 2, want: []string{"a", "b"}},
+		{page: 2, size: 2, want: []string{"e"}},
+		{page: 3, size: 2, want: []string{}},
+		{page: 0, size: 10, want: txids},
+	}
+	for _, tt := range tests {
+		if got := PageTxids(txids, tt.page, tt.size); !slices.Equal(got, tt.want) {
+			t.Errorf("PageTxids(%d, %d) = %v, 期望 %v", tt.page, tt.size, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package block
+
+import "sort"
+
+// 节点getchaintips返回的链顶状态
+const (
+	ChainTipActive       = "active"        // 当前活跃链
+	ChainTipValidFork    = "valid-fork"    // 完整验证过但不在活跃链上的分叉
+	ChainTipValidHeaders = "valid-headers" // 区块已下载且区块头有效，但未完整验证
+	ChainTipHeadersOnly  = "headers-only"  // 只有区块头，区块本身未下载
+	ChainTipInvalid      = "invalid"       // 包含无效区块的分叉
+)
+
+// ChainTip 节点getchaintips返回的单个链顶
+type ChainTip struct {
+	Height    int64  `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int64  `json:"branchlen"` // 分叉链的长度，活跃链为0
+	Status    string `json:"status"`
+}
+
+// ChainTipInfo 带标注的链顶
+type ChainTipInfo struct {
+	ChainTip
+	Active       bool   `json:"active"`
+	ValidFork    bool   `json:"valid_fork"`
+	HeadersOnly  bool   `json:"headers_only"`
+	Invalid      bool   `json:"invalid"`
+	ForkHeight   int64  `json:"fork_height"`         // 与活跃链的分叉点高度
+	BehindActive int64  `json:"behind_active"`       // 比活跃链顶低的高度差，高于活跃链顶时为负数
+	ChainWork    string `json:"chainwork,omitempty"` // 累计工作量，只查询高度最高的若干个链顶
+}
+
+// ChainTipsResponse 链顶和分叉信息
+type ChainTipsResponse struct {
+	ActiveHeight int64          `json:"active_height"`
+	ActiveHash   string         `json:"active_hash"`
+	ActiveWork   string         `json:"active_chainwork,omitempty"`
+	Forks        int            `json:"forks"` // 除活跃链以外的链顶数
+	Tips         []ChainTipInfo `json:"tips"`  // 按高度从高到低排列
+}
+
+// NewChainTipsResponse 标注节点返回的链顶，计算分叉点和与活跃链的高度差
+func NewChainTipsResponse(tips []ChainTip) *ChainTipsResponse {
+	response := &ChainTipsResponse{Tips: make([]ChainTipInfo, 0, len(tips))}
+	for _, tip := range tips {
+		if tip.Status == ChainTipActive {
+			response.ActiveHeight = tip.Height
+			response.ActiveHash = tip.Hash
+		}
+	}
+
+	for _, tip := range tips {
+		info := ChainTipInfo{
+			ChainTip:     tip,
+			Active:       tip.Status == ChainTipActive,
+			ValidFork:    tip.Status == ChainTipValidFork,
+			HeadersOnly:  tip.Status == ChainTipHeadersOnly,
+			Invalid:      tip.Status == ChainTipInvalid,
+			ForkHeight:   tip.Height - tip.BranchLen,
+			BehindActive: response.ActiveHeight - tip.Height,
+		}
+		if !info.Active {
+			response.Forks++
+		}
+		response.Tips = append(response.Tips, info)
+	}
+	sort.SliceStable(response.Tips, func(i, j int) bool { return response.Tips[i].Height > response.Tips[j].Height })
+	return response
+}
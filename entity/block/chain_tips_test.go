@@ -0,0 +1,24 @@
+package block
+
+import "testing"
+
+func TestNewChainTipsResponse(t *testing.T) {
+	response := NewChainTipsResponse([]ChainTip{
+		{Height: 95, Hash: "old", BranchLen: 2, Status: ChainTipValidFork},
+		{Height: 100, Hash: "tip", BranchLen: 0, Status: ChainTipActive},
+		{Height: 101, Hash: "hdr", BranchLen: 3, Status: ChainTipHeadersOnly},
+	})
+
+	if response.ActiveHeight != 100 || response.ActiveHash != "tip" || response.Forks != 2 {
+		t.Fatalf("活跃链或分叉数不一致: %+v", response)
+	}
+	if got := response.Tips[0]; got.Hash != "hdr" || !got.HeadersOnly || got.ForkHeight != 98 || got.BehindActive != -1 {
+		t.Errorf("只有区块头的链顶标注不一致: %+v", got)
+	}
+	if got := response.Tips[1]; !got.Active || got.ForkHeight != 100 || got.BehindActive != 0 {
+		t.Errorf("活跃链顶标注不一致: %+v", got)
+	}
+	if got := response.Tips[2]; !got.ValidFork || got.ForkHeight != 93 || got.BehindActive != 5 {
+		t.Errorf("有效分叉标注不一致: %+v", got)
+	}
+}
@@ -0,0 +1,30 @@
+package block
+
+import (
+	"errors"
+	"strconv"
+)
+
+const (
+	// DefaultNearbyHeaderCount 默认返回的最近区块头数量
+	DefaultNearbyHeaderCount = 10
+	// MaxNearbyHeaderCount 单次最多返回的最近区块头数量
+	MaxNearbyHeaderCount = 100
+	// NearbyHeaderWorkers 并发获取区块头的最大协程数
+	NearbyHeaderWorkers = 10
+)
+
+// ErrInvalidNearbyHeaderCount 区块头数量无效
+var ErrInvalidNearbyHeaderCount = errors.New("count必须为1到100之间的整数")
+
+// ParseNearbyHeaderCount 解析需要返回的最近区块头数量，为空时使用默认值
+func ParseNearbyHeaderCount(s string) (int, error) {
+	if s == "" {
+		return DefaultNearbyHeaderCount, nil
+	}
+	count, err := strconv.Atoi(s)
+	if err != nil || count < 1 || count > MaxNearbyHeaderCount {
+		return 0, ErrInvalidNearbyHeaderCount
+	}
+	return count, nil
+}
@@ -0,0 +1,24 @@
+package block
+
+import "testing"
+
+func TestParseNearbyHeaderCount(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{input: "", want: DefaultNearbyHeaderCount},
+		{input: "1", want: 1},
+		{input: "100", want: 100},
+		{input: "0", wantErr: true},
+		{input: "101", wantErr: true},
+		{input: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseNearbyHeaderCount(tt.input)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseNearbyHeaderCount(%q) = %d, %v", tt.input, got, err)
+		}
+	}
+}
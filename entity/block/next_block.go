@@ -0,0 +1,36 @@
+package block
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultNextBlockTimeout 等待下一个区块的默认时长
+	DefaultNextBlockTimeout = 30 * time.Second
+	// MaxNextBlockTimeout 等待下一个区块的最长时长，避免连接被代理或负载均衡断开
+	MaxNextBlockTimeout = 60 * time.Second
+)
+
+// ErrInvalidNextBlockTimeout 等待时长无效
+var ErrInvalidNextBlockTimeout = errors.New("timeout必须为1s到60s之间的时长，如30s或30")
+
+// ParseNextBlockTimeout 解析等待下一个区块的时长，支持30s这类时长写法和按秒计的整数，为空时使用默认值
+func ParseNextBlockTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return DefaultNextBlockTimeout, nil
+	}
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(s)
+		if convErr != nil {
+			return 0, ErrInvalidNextBlockTimeout
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout < time.Second || timeout > MaxNextBlockTimeout {
+		return 0, ErrInvalidNextBlockTimeout
+	}
+	return timeout, nil
+}
@@ -0,0 +1,126 @@
+package block
+
+import (
+	"ginproject/entity/blockchain"
+	"ginproject/entity/transaction"
+)
+
+// MaxTxScanRange 区块范围交易扫描单次请求允许的最大区块数
+const MaxTxScanRange = 100
+
+// 扫描输出中每行记录的类型
+const (
+	TxScanRecordBlock = "block" // 区块标记，位于该区块的交易之前
+	TxScanRecordTx    = "tx"    // 交易摘要
+	TxScanRecordEnd   = "end"   // 扫描结束
+	TxScanRecordError = "error" // 扫描中途出错，之后不再输出
+)
+
+// 错误定义
+var (
+	ErrInvalidBlockRange  = NewBlockError("起始高度不能大于结束高度")
+	ErrBlockRangeTooLarge = NewBlockError("区块范围过大，单次最多扫描100个区块")
+)
+
+// BlockWithTxs 包含完整交易信息的区块(verbosity=2)
+type BlockWithTxs struct {
+	BlockHeader
+	Size int32                            `json:"size"`
+	Tx   []blockchain.TransactionResponse `json:"tx"`
+}
+
+// TxScanBlockMarker 区块标记
+type TxScanBlockMarker struct {
+	Type    string `json:"type"`
+	Height  int64  `json:"height"`
+	Hash    string `json:"hash"`
+	Time    int64  `json:"time"`
+	TxCount int    `json:"tx_count"`
+}
+
+// TxScanSummary 交易摘要
+type TxScanSummary struct {
+	Type      string             `json:"type"`
+	Height    int64              `json:"height"`
+	Index     int                `json:"index"` // 交易在区块中的位置
+	Txid      string             `json:"txid"`
+	Size      int                `json:"size"`
+	TxType    transaction.TxType `json:"tx_type"`
+	VinCount  int                `json:"vin_count"`
+	VoutCount int                `json:"vout_count"`
+	TotalOut  float64            `json:"total_out"`           // 输出总额(TBC)
+	Addresses []string           `json:"addresses,omitempty"` // 输出中出现的地址，按首次出现的顺序去重
+}
+
+// TxScanEnd 扫描结束标记
+type TxScanEnd struct {
+	Type   string `json:"type"`
+	From   int64  `json:"from"`
+	To     int64  `json:"to"`
+	Blocks int    `json:"blocks"`
+	Txs    int    `json:"txs"`
+}
+
+// TxScanError 扫描错误标记
+type TxScanError struct {
+	Type   string `json:"type"`
+	Height int64  `json:"height"`
+	Error  string `json:"error"`
+}
+
+// ValidateBlockRange 验证区块范围参数
+func ValidateBlockRange(from, to int64) error {
+	if err := ValidateBlockHeight(from); err != nil {
+		return err
+	}
+	if from > to {
+		return ErrInvalidBlockRange
+	}
+	if to-from+1 > MaxTxScanRange {
+		return ErrBlockRangeTooLarge
+	}
+	return nil
+}
+
+// NewTxScanBlockMarker 生成区块标记
+func NewTxScanBlockMarker(b *BlockWithTxs) TxScanBlockMarker {
+	return TxScanBlockMarker{
+		Type:    TxScanRecordBlock,
+		Height:  b.Height,
+		Hash:    b.Hash,
+		Time:    b.Time,
+		TxCount: len(b.Tx),
+	}
+}
+
+// SummarizeTx 生成区块中第index笔交易的摘要，交易类型与地址历史使用同一套分类规则
+func SummarizeTx(height int64, index int, tx *blockchain.TransactionResponse) TxScanSummary {
+	summary := TxScanSummary{
+		Type:      TxScanRecordTx,
+		Height:    height,
+		Index:     index,
+		Txid:      tx.Txid,
+		Size:      tx.Size,
+		VinCount:  len(tx.Vin),
+		VoutCount: len(tx.Vout),
+	}
+
+	outputs := make([]transaction.TxOutputScript, 0, len(tx.Vout))
+	seen := make(map[string]struct{})
+	for _, vout := range tx.Vout {
+		summary.TotalOut += vout.Value
+		outputs = append(outputs, transaction.TxOutputScript{Type: vout.ScriptPubKey.Type, Asm: vout.ScriptPubKey.Asm})
+		for _, address := range vout.ScriptPubKey.Addresses {
+			if _, ok := seen[address]; ok {
+				continue
+			}
+			seen[address] = struct{}{}
+			summary.Addresses = append(summary.Addresses, address)
+		}
+	}
+
+	// 挖矿交易的输入没有txid
+	isCoinbase := len(tx.Vin) > 0 && tx.Vin[0].Txid == ""
+	summary.TxType = transaction.ClassifyTx(isCoinbase, outputs)
+	return summary
+}
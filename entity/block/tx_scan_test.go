@@ -0,0 +1,58 @@
+package block
+
+import (
+	"testing"
+
+	"ginproject/entity/blockchain"
+	"ginproject/entity/transaction"
+)
+
+func TestValidateBlockRange(t *testing.T) {
+	tests := []struct {
+		from, to int64
+		want     error
+	}{
+		{from: 0, to: 0},
+		{from: 10, to: 10 + MaxTxScanRange - 1},
+		{from: -1, to: 5, want: ErrInvalidBlockHeight},
+		{from: 6, to: 5, want: ErrInvalidBlockRange},
+		{from: 10, to: 10 + MaxTxScanRange, want: ErrBlockRangeTooLarge},
+	}
+	for _, tt := range tests {
+		if err := ValidateBlockRange(tt.from, tt.to); err != tt.want {
+			t.Errorf("ValidateBlockRange(%d, %d) = %v, 期望 %v", tt.from, tt.to, err, tt.want)
+		}
+	}
+}
+
+func TestSummarizeTx(t *testing.T) {
+	p2pkh := func(value float64, address string) blockchain.VoutItem {
+		return blockchain.VoutItem{Value: value, ScriptPubKey: blockchain.ScriptPubKey{Type: "pubkeyhash", Addresses: []string{address}}}
+	}
+
+	coinbase := blockchain.TransactionResponse{
+		Txid: "aa",
+		Vin:  []blockchain.VinItem{{}},
+		Vout: []blockchain.VoutItem{p2pkh(50, "miner")},
+	}
+	if summary := SummarizeTx(7, 0, &coinbase); summary.TxType != transaction.TxTypeCoinbase || summary.Height != 7 {
+		t.Fatalf("挖矿交易摘要不一致: %+v", summary)
+	}
+
+	transfer := blockchain.TransactionResponse{
+		Txid: "bb",
+		Size: 225,
+		Vin:  []blockchain.VinItem{{Txid: "aa"}},
+		Vout: []blockchain.VoutItem{p2pkh(1.5, "alice"), p2pkh(0.25, "bob"), p2pkh(0.25, "alice")},
+	}
+	summary := SummarizeTx(7, 1, &transfer)
+	if summary.Type != TxScanRecordTx || summary.TxType != transaction.TxTypeP2PKH || summary.Index != 1 {
+		t.Fatalf("转账交易摘要不一致: %+v", summary)
+	}
+	if summary.TotalOut != 2 || summary.VinCount != 1 || summary.VoutCount != 3 {
+		t.Fatalf("输入输出统计不一致: %+v", summary)
+	}
+	if len(summary.Addresses) != 2 || summary.Addresses[0] != "alice" || summary.Addresses[1] != "bob" {
+		t.Fatalf("地址去重结果不一致: %v", summary.Addresses)
+	}
+}
@@ -15,6 +15,15 @@ type ScriptPubKey struct {
 	Addresses []string `json:"addresses,omitempty"`
 }
 
+// DecodedScript 表示节点decodescript的返回结构
+type DecodedScript struct {
+	Asm       string   `json:"asm"`
+	ReqSigs   int      `json:"reqSigs,omitempty"`
+	Type      string   `json:"type"`
+	Addresses []string `json:"addresses,omitempty"`
+	P2SH      string   `json:"p2sh,omitempty"`
+}
+
 // VinItem 表示交易输入项
 type VinItem struct {
 	Txid      string    `json:"txid"`
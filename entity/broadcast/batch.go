@@ -0,0 +1,314 @@
+package broadcast
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 批量广播中单笔交易的状态
+const (
+	TxStatusAccepted = "accepted" // 节点接受，或节点已有该交易
+	TxStatusRejected = "rejected" // 节点拒绝
+	TxStatusSkipped  = "skipped"  // 批内依赖的交易未被接受，或与前面的交易重复，未广播
+	TxStatusError    = "error"    // 广播请求失败，节点是否接受未知
+)
+
+// TxBroadcastStatus 批量广播中单笔交易的结果
+type TxBroadcastStatus struct {
+	Index        int    `json:"index"` // 在请求中的序号，从0开始
+	TxID         string `json:"txid,omitempty"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty"` // 拒绝原因分类，与预检拒绝原因一致
+	RejectCode   int    `json:"reject_code,omitempty"`
+	RejectReason string `json:"reject_reason,omitempty"`
+	FailedParent string `json:"failed_parent,omitempty"` // 因批内父交易未被接受而跳过时为父交易ID
+	AlreadyKnown bool   `json:"already_known,omitempty"` // 节点已有该交易
+}
+
+// ParentTxIds 解析原始交易，返回输入引用的交易ID(去重，保持输入顺序)
+func ParentTxIds(txHex string) ([]string, error) {
+	raw, err := hex.DecodeString(strings.TrimSpace(txHex))
+	if err != nil {
+		return nil, fmt.Errorf("无效的交易16进制字符串: %w", err)
+	}
+
+	r := txReader{data: raw}
+	r.skip(4) // version
+	count := r.varInt()
+	if r.err == nil && count > uint64(len(raw)/41) {
+		return nil, fmt.Errorf("交易输入数量无效: %d", count)
+	}
+
+	parents := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		prev := r.bytes(32)
+		r.skip(4) // vout
+		r.skip(int(r.varInt()))
+		r.skip(4) // sequence
+		if r.err != nil {
+			break
+		}
+		txid := reversedHex(prev)
+		if !seen[txid] {
+			seen[txid] = true
+			parents = append(parents, txid)
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("解析交易输入失败: %w", r.err)
+	}
+	return parents, nil
+}
+
+// BatchBroadcast 按批内依赖关系分层广播的一批交易
+// 子交易花费同批父交易的输出时，父交易在更早的层中广播，父交易未被接受时子交易不再广播
+type BatchBroadcast struct {
+	hexes    []string
+	statuses []TxBroadcastStatus
+	parents  [][]int // 每笔交易依赖的批内交易序号
+	levels   [][]int
+}
+
+// NewBatchBroadcast 计算批内交易的依赖层级，同一层内保持请求顺序
+// 无法计算交易ID的交易直接标记为拒绝，重复的交易标记为跳过
+func NewBatchBroadcast(req TxsBroadcastRequest) *BatchBroadcast {
+	b := &BatchBroadcast{
+		hexes:    make([]string, len(req)),
+		statuses: make([]TxBroadcastStatus, len(req)),
+		parents:  make([][]int, len(req)),
+	}
+
+	indexByTxId := make(map[string]int, len(req))
+	for i, tx := range req {
+		b.hexes[i] = strings.TrimSpace(tx.TxHex)
+		b.statuses[i].Index = i
+		txid, err := TxIdFromHex(b.hexes[i])
+		if err != nil {
+			b.reject(i, &BroadcastError{Code: -22, Message: err.Error()})
+			continue
+		}
+		b.statuses[i].TxID = txid
+		if first, ok := indexByTxId[txid]; ok {
+			b.statuses[i].Status = TxStatusSkipped
+			b.statuses[i].RejectReason = fmt.Sprintf("与第%d笔交易重复", first)
+			continue
+		}
+		indexByTxId[txid] = i
+	}
+
+	for i := range req {
+		if b.statuses[i].Status != "" {
+			continue
+		}
+		// 无法解析输入时不计算依赖，由节点判断交易是否有效
+		parentIds, _ := ParentTxIds(b.hexes[i])
+		for _, parentId := range parentIds {
+			if parent, ok := indexByTxId[parentId]; ok && parent != i {
+				b.parents[i] = append(b.parents[i], parent)
+			}
+		}
+	}
+
+	b.levels = b.computeLevels()
+	return b
+}
+
+// computeLevels 按拓扑顺序分层，父交易都在更早的层中；存在环的交易标记为拒绝
+func (b *BatchBroadcast) computeLevels() [][]int {
+	pending := make([]int, len(b.hexes))
+	children := make([][]int, len(b.hexes))
+	var ready []int
+	for i, parents := range b.parents {
+		if b.statuses[i].Status != "" {
+			continue
+		}
+		pending[i] = len(parents)
+		for _, parent := range parents {
+			children[parent] = append(children[parent], i)
+		}
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	var levels [][]int
+	placed := 0
+	for len(ready) > 0 {
+		levels = append(levels, ready)
+		placed += len(ready)
+		var next []int
+		for _, i := range ready {
+			for _, child := range children[i] {
+				if pending[child]--; pending[child] == 0 {
+					next = append(next, child)
+				}
+			}
+		}
+		slices.Sort(next)
+		ready = next
+	}
+
+	if placed < len(b.hexes) {
+		for i := range b.hexes {
+			if b.statuses[i].Status == "" && pending[i] > 0 {
+				b.reject(i, &BroadcastError{Code: -26, Message: "批内交易存在循环依赖"})
+			}
+		}
+	}
+	return levels
+}
+
+// Levels 返回按广播顺序排列的层，每层为交易序号
+func (b *BatchBroadcast) Levels() [][]int {
+	return b.levels
+}
+
+// Hex 返回交易的原始数据
+func (b *BatchBroadcast) Hex(index int) string {
+	return b.hexes[index]
+}
+
+// Ready 返回一层中可以广播的交易，批内父交易未被接受的交易标记为跳过
+func (b *BatchBroadcast) Ready(level []int) []int {
+	ready := make([]int, 0, len(level))
+	for _, i := range level {
+		if b.statuses[i].Status != "" {
+			continue
+		}
+		if parent, ok := b.failedParent(i); ok {
+			b.statuses[i].Status = TxStatusSkipped
+			b.statuses[i].FailedParent = b.statuses[parent].TxID
+			b.statuses[i].RejectReason = "批内父交易未被接受"
+			continue
+		}
+		ready = append(ready, i)
+	}
+	return ready
+}
+
+// failedParent 返回第一个未被接受的批内父交易
+func (b *BatchBroadcast) failedParent(index int) (int, bool) {
+	for _, parent := range b.parents[index] {
+		if b.statuses[parent].Status != TxStatusAccepted {
+			return parent, true
+		}
+	}
+	return 0, false
+}
+
+// Record 记录节点对交易的广播结果，节点已有该交易时视为接受
+func (b *BatchBroadcast) Record(index int, resp *BroadcastResponse) {
+	if resp.Error == nil {
+		b.statuses[index].Status = TxStatusAccepted
+		return
+	}
+	reason, _ := ParseRejectReason(resp.Error.Message)
+	if reason == RejectAlreadyInChain || reason == RejectAlreadyInMempool {
+		b.statuses[index].Status = TxStatusAccepted
+		b.statuses[index].AlreadyKnown = true
+		return
+	}
+	b.reject(index, resp.Error)
+}
+
+// Fail 记录广播请求失败的交易
+func (b *BatchBroadcast) Fail(index int, err error) {
+	b.statuses[index].Status = TxStatusError
+	b.statuses[index].RejectReason = err.Error()
+}
+
+// reject 将交易标记为被拒绝
+func (b *BatchBroadcast) reject(index int, e *BroadcastError) {
+	reason, _ := ParseRejectReason(e.Message)
+	if e.Code == -22 {
+		reason = RejectSyntax
+	}
+	b.statuses[index].Status = TxStatusRejected
+	b.statuses[index].Reason = reason
+	b.statuses[index].RejectCode = e.Code
+	b.statuses[index].RejectReason = e.Message
+}
+
+// Statuses 返回各笔交易的结果，顺序与请求一致
+func (b *BatchBroadcast) Statuses() []TxBroadcastStatus {
+	return b.statuses
+}
+
+// Result 生成批量广播结果，TxIDs为被接受的交易，其余交易列入Invalid
+func (b *BatchBroadcast) Result() *TxsBroadcastResult {
+	result := &TxsBroadcastResult{TxIDs: []string{}, Invalid: []InvalidTx{}, Txs: b.statuses}
+	for _, status := range b.statuses {
+		if status.Status == TxStatusAccepted {
+			result.TxIDs = append(result.TxIDs, status.TxID)
+			continue
+		}
+		result.Invalid = append(result.Invalid, InvalidTx{
+			TxID:         status.TxID,
+			RejectCode:   status.RejectCode,
+			RejectReason: status.RejectReason,
+		})
+	}
+	return result
+}
+
+// txReader 顺序读取原始交易，出错后的读取均被忽略
+type txReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *txReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	// 先比较剩余长度，n接近int上限时r.pos+n会溢出
+	if n < 0 || n > len(r.data)-r.pos {
+		r.err = fmt.Errorf("交易数据在第%d字节处截断", r.pos)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *txReader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *txReader) varInt() uint64 {
+	prefix := r.bytes(1)
+	if prefix == nil {
+		return 0
+	}
+	switch prefix[0] {
+	case 0xfd:
+		if b := r.bytes(2); b != nil {
+			return uint64(binary.LittleEndian.Uint16(b))
+		}
+	case 0xfe:
+		if b := r.bytes(4); b != nil {
+			return uint64(binary.LittleEndian.Uint32(b))
+		}
+	case 0xff:
+		if b := r.bytes(8); b != nil {
+			return binary.LittleEndian.Uint64(b)
+		}
+	default:
+		return uint64(prefix[0])
+	}
+	return 0
+}
+
+// reversedHex 按字节倒序编码为16进制，交易ID按小端序存储
+func reversedHex(b []byte) string {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return hex.EncodeToString(reversed)
+}
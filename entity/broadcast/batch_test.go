@@ -0,0 +1,114 @@
+package broadcast
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// buildTx 构造花费parents中各交易第0个输出的交易，tag用于区分交易ID
+func buildTx(tag byte, parents ...string) string {
+	var b strings.Builder
+	b.WriteString("01000000")
+	b.WriteString(hex.EncodeToString([]byte{byte(len(parents))}))
+	for _, parent := range parents {
+		raw, _ := hex.DecodeString(parent)
+		b.WriteString(reversedHex(raw))
+		b.WriteString("00000000" + "00" + "ffffffff")
+	}
+	b.WriteString("01" + "0000000000000000" + "01" + hex.EncodeToString([]byte{tag}))
+	b.WriteString("00000000")
+	return b.String()
+}
+
+func mustTxId(t *testing.T, txHex string) string {
+	t.Helper()
+	txid, err := TxIdFromHex(txHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txid
+}
+
+func TestParentTxIds(t *testing.T) {
+	parent := strings.Repeat("ab", 31) + "01"
+	parents, err := ParentTxIds(buildTx(1, parent, parent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 1 || parents[0] != parent {
+		t.Errorf("ParentTxIds = %v, want [%s]", parents, parent)
+	}
+	if _, err := ParentTxIds("0100000001"); err == nil {
+		t.Error("截断的交易应返回错误")
+	}
+	// 脚本长度接近int上限时不应因溢出越过边界检查
+	overflow := "01000000" + "01" + strings.Repeat("00", 32) + "00000000" + "ff" + "ffffffffffffff7f"
+	if _, err := ParentTxIds(overflow); err == nil {
+		t.Error("脚本长度超出交易数据时应返回错误")
+	}
+}
+
+func TestBatchBroadcastOrdering(t *testing.T) {
+	external := strings.Repeat("cd", 32)
+	parent := buildTx(1, external)
+	child := buildTx(2, mustTxId(t, parent))
+	grandchild := buildTx(3, mustTxId(t, child))
+	other := buildTx(4, external)
+
+	// 子交易排在父交易之前
+	req := TxsBroadcastRequest{{TxHex: grandchild}, {TxHex: child}, {TxHex: other}, {TxHex: parent}, {TxHex: other}}
+	batch := NewBatchBroadcast(req)
+	levels := batch.Levels()
+	if len(levels) != 3 || len(levels[0]) != 2 || levels[0][0] != 2 || levels[0][1] != 3 || levels[1][0] != 1 || levels[2][0] != 0 {
+		t.Fatalf("Levels = %v", levels)
+	}
+	if status := batch.Statuses()[4]; status.Status != TxStatusSkipped {
+		t.Errorf("重复的交易应跳过: %+v", status)
+	}
+
+	// 父交易被拒绝，子交易和孙交易均跳过
+	for _, i := range batch.Ready(levels[0]) {
+		if i == 3 {
+			batch.Record(i, &BroadcastResponse{Error: &BroadcastError{Code: -26, Message: "66: insufficient priority"}})
+		} else {
+			batch.Record(i, &BroadcastResponse{Result: mustTxId(t, req[i].TxHex)})
+		}
+	}
+	if ready := batch.Ready(levels[1]); len(ready) != 0 {
+		t.Errorf("父交易被拒绝时不应广播子交易: %v", ready)
+	}
+	batch.Ready(levels[2])
+
+	statuses := batch.Statuses()
+	if statuses[3].Status != TxStatusRejected || statuses[3].Reason != RejectFeeTooLow || statuses[3].RejectCode != -26 {
+		t.Errorf("父交易状态 = %+v", statuses[3])
+	}
+	if statuses[1].Status != TxStatusSkipped || statuses[1].FailedParent != mustTxId(t, parent) {
+		t.Errorf("子交易状态 = %+v", statuses[1])
+	}
+	if statuses[0].Status != TxStatusSkipped || statuses[0].FailedParent != mustTxId(t, child) {
+		t.Errorf("孙交易状态 = %+v", statuses[0])
+	}
+
+	result := batch.Result()
+	if len(result.TxIDs) != 1 || result.TxIDs[0] != mustTxId(t, other) || len(result.Invalid) != 4 || len(result.Txs) != 5 {
+		t.Errorf("Result = %+v", result)
+	}
+}
+
+func TestBatchBroadcastAlreadyKnown(t *testing.T) {
+	parent := buildTx(1, strings.Repeat("cd", 32))
+	child := buildTx(2, mustTxId(t, parent))
+	batch := NewBatchBroadcast(TxsBroadcastRequest{{TxHex: parent}, {TxHex: child}})
+
+	// 节点已有父交易时视为接受，继续广播子交易
+	levels := batch.Levels()
+	batch.Record(batch.Ready(levels[0])[0], &BroadcastResponse{Error: &BroadcastError{Code: -27, Message: "Transaction already in block chain"}})
+	if status := batch.Statuses()[0]; status.Status != TxStatusAccepted || !status.AlreadyKnown {
+		t.Errorf("父交易状态 = %+v", status)
+	}
+	if ready := batch.Ready(levels[1]); len(ready) != 1 {
+		t.Errorf("父交易已在链上时应广播子交易: %v", ready)
+	}
+}
@@ -15,8 +15,10 @@ type TxsBroadcastRequest []TxBroadcastRequest
 
 // BroadcastResponse 单笔交易广播响应
 type BroadcastResponse struct {
-	Result string          `json:"result,omitempty"`
-	Error  *BroadcastError `json:"error,omitempty"`
+	Result           string          `json:"result,omitempty"`
+	Error            *BroadcastError `json:"error,omitempty"`
+	ConsistencyToken string          `json:"consistency_token,omitempty"` // 读取接口可通过after参数传入，等待交易可见
+	DryRun           *DryRunResult   `json:"dry_run,omitempty"`           // dry_run模式的预检结果，此时交易未被广播
 }
 
 // BroadcastError 广播错误信息
@@ -34,14 +36,16 @@ type InvalidTx struct {
 
 // TxsBroadcastResponse 批量交易广播响应
 type TxsBroadcastResponse struct {
-	Result *TxsBroadcastResult `json:"result,omitempty"`
-	Error  *BroadcastError     `json:"error,omitempty"`
+	Result           *TxsBroadcastResult `json:"result,omitempty"`
+	Error            *BroadcastError     `json:"error,omitempty"`
+	ConsistencyToken string              `json:"consistency_token,omitempty"` // 覆盖所有广播成功的交易
 }
 
 // TxsBroadcastResult 批量交易广播结果
 type TxsBroadcastResult struct {
-	TxIDs   []string    `json:"txids,omitempty"`
-	Invalid []InvalidTx `json:"invalid,omitempty"`
+	TxIDs   []string            `json:"txids,omitempty"`
+	Invalid []InvalidTx         `json:"invalid,omitempty"`
+	Txs     []TxBroadcastStatus `json:"txs,omitempty"` // 各笔交易的结果，顺序与请求一致
 }
 
 // ValidateTxHex 验证交易16进制字符串
@@ -0,0 +1,84 @@
+package broadcast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"ginproject/entity/utility"
+)
+
+// 广播失败记录状态
+const (
+	FailureStatusPending  = "pending"  // 待处理
+	FailureStatusReplayed = "replayed" // 已重放成功
+)
+
+// TxIdFromHex 由原始交易16进制数据计算交易ID
+func TxIdFromHex(txHex string) (string, error) {
+	raw, err := hex.DecodeString(strings.TrimSpace(txHex))
+	if err != nil {
+		return "", fmt.Errorf("无效的交易16进制字符串: %w", err)
+	}
+	first := sha256.Sum256(raw)
+	hash := sha256.Sum256(first[:])
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// BroadcastFailuresRequest 查询广播失败记录请求
+type BroadcastFailuresRequest struct {
+	Status string `form:"status"` // 按状态过滤，为空时返回全部
+	Page   int    `form:"page"`   // 页码（从0开始）
+	Size   int    `form:"size"`   // 每页记录数，默认为20
+}
+
+// Validate 验证请求参数的合法性
+func (req *BroadcastFailuresRequest) Validate() error {
+	switch req.Status {
+	case "", FailureStatusPending, FailureStatusReplayed:
+	default:
+		return fmt.Errorf("无效的状态: %s", req.Status)
+	}
+	if req.Page < 0 {
+		return fmt.Errorf("页码必须大于或等于0")
+	}
+	if req.Size == 0 {
+		req.Size = 20
+	}
+	return utility.ValidatePageSize(utility.PageEndpointBroadcastFailures, req.Size)
+}
+
+// BroadcastFailure 广播失败记录
+type BroadcastFailure struct {
+	Id        int64  `json:"id"`
+	TxId      string `json:"txid"`
+	TxHex     string `json:"tx_hex"`
+	Error     string `json:"error"`    // 最近一次广播的错误信息
+	Attempts  int    `json:"attempts"` // 广播失败次数，包括重放
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"created_at"` // 首次失败时间(Unix秒)
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// BroadcastFailuresResponse 广播失败记录列表响应
+type BroadcastFailuresResponse struct {
+	Result []BroadcastFailure `json:"result"`
+	Meta   *utility.PageMeta  `json:"meta,omitempty"`
+}
+
+// ReplayFailureRequest 重放广播失败记录请求
+type ReplayFailureRequest struct {
+	Id int64 `uri:"id" binding:"required"`
+}
+
+// ReplayFailureResponse 重放广播失败记录响应
+type ReplayFailureResponse struct {
+	Id     int64  `json:"id"`
+	TxId   string `json:"txid"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"` // 重放失败时节点返回的错误
+}
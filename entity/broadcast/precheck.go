@@ -0,0 +1,126 @@
+package broadcast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 预检拒绝原因
+const (
+	RejectSyntax           = "syntax"             // 交易无法解析
+	RejectMissingInputs    = "missing_inputs"     // 引用的输出不存在或已被花费
+	RejectFeeTooLow        = "fee_too_low"        // 手续费低于节点的最低要求
+	RejectAlreadyInChain   = "already_in_chain"   // 交易已经确认
+	RejectAlreadyInMempool = "already_in_mempool" // 交易已经在内存池中
+	RejectInvalidScript    = "invalid_script"     // 解锁脚本验证失败
+	RejectNonStandard      = "non_standard"       // 不符合节点的标准交易策略
+	RejectOther            = "rejected"           // 其它节点拒绝原因
+)
+
+// 节点不支持而跳过的检查
+const CheckMempoolAccept = "mempool_accept"
+
+// MempoolAccept 节点testmempoolaccept对单笔交易的结果
+type MempoolAccept struct {
+	TxID         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	RejectReason string `json:"reject-reason"` // 形如"66: insufficient priority"
+}
+
+// NodePrecheck 节点对未广播交易的各项检查结果
+type NodePrecheck struct {
+	DecodeError   *BroadcastError // decoderawtransaction失败时的节点错误
+	Size          int             // 解码得到的交易大小
+	Known         bool            // 节点能查到该交易
+	Confirmations int64           // 节点能查到交易时的确认数，0表示在内存池中
+	Accept        *MempoolAccept  // 节点不支持testmempoolaccept时为nil
+}
+
+// TxRejection 结构化的拒绝原因
+type TxRejection struct {
+	Reason  string `json:"reason"`         // 拒绝原因分类
+	Code    int    `json:"code,omitempty"` // 节点的拒绝码
+	Message string `json:"message"`        // 节点返回的原始信息
+}
+
+// DryRunResult 预检结果，交易未被广播
+type DryRunResult struct {
+	TxID       string        `json:"txid,omitempty"`
+	Allowed    bool          `json:"allowed"` // 没有任何拒绝原因，广播预计会被接受
+	Size       int           `json:"size,omitempty"`
+	Rejections []TxRejection `json:"rejections"`
+	Skipped    []string      `json:"skipped,omitempty"` // 节点不支持而未执行的检查
+}
+
+// rejectReasonKeywords 节点拒绝信息中的关键字与拒绝原因的对应关系，按顺序匹配
+var rejectReasonKeywords = []struct {
+	keyword string
+	reason  string
+}{
+	{"missing-inputs", RejectMissingInputs},
+	{"missingorspent", RejectMissingInputs},
+	{"txn-mempool-conflict", RejectMissingInputs},
+	{"already-known", RejectAlreadyInMempool},
+	{"already-in-mempool", RejectAlreadyInMempool},
+	{"already-confirmed", RejectAlreadyInChain},
+	{"already in block chain", RejectAlreadyInChain},
+	{"insufficient priority", RejectFeeTooLow},
+	{"min relay fee not met", RejectFeeTooLow},
+	{"mempool min fee not met", RejectFeeTooLow},
+	{"insufficient fee", RejectFeeTooLow},
+	{"script-verify-flag-failed", RejectInvalidScript},
+	{"scriptsig", RejectInvalidScript},
+	{"dust", RejectNonStandard},
+	{"non-standard", RejectNonStandard},
+	{"nonstandard", RejectNonStandard},
+	{"tx-size", RejectNonStandard},
+}
+
+// ParseRejectReason 解析节点形如"66: insufficient priority"的拒绝信息，返回拒绝原因分类和拒绝码
+func ParseRejectReason(message string) (string, int) {
+	code := 0
+	if prefix, _, ok := strings.Cut(message, ":"); ok {
+		code, _ = strconv.Atoi(strings.TrimSpace(prefix))
+	}
+	lower := strings.ToLower(message)
+	for _, item := range rejectReasonKeywords {
+		if strings.Contains(lower, item.keyword) {
+			return item.reason, code
+		}
+	}
+	return RejectOther, code
+}
+
+// NewDryRunResult 由节点检查结果生成预检结果，相同原因只列出一次
+func NewDryRunResult(txid string, check *NodePrecheck) *DryRunResult {
+	result := &DryRunResult{TxID: txid, Size: check.Size, Rejections: []TxRejection{}}
+	add := func(rejection TxRejection) {
+		for _, existing := range result.Rejections {
+			if existing.Reason == rejection.Reason {
+				return
+			}
+		}
+		result.Rejections = append(result.Rejections, rejection)
+	}
+
+	if check.DecodeError != nil {
+		add(TxRejection{Reason: RejectSyntax, Code: check.DecodeError.Code, Message: check.DecodeError.Message})
+	}
+	if check.Known {
+		if check.Confirmations > 0 {
+			add(TxRejection{Reason: RejectAlreadyInChain, Message: "交易已有" + strconv.FormatInt(check.Confirmations, 10) + "个确认"})
+		} else {
+			add(TxRejection{Reason: RejectAlreadyInMempool, Message: "交易已在内存池中"})
+		}
+	}
+	switch {
+	case check.Accept == nil:
+		result.Skipped = append(result.Skipped, CheckMempoolAccept)
+	case !check.Accept.Allowed:
+		reason, code := ParseRejectReason(check.Accept.RejectReason)
+		add(TxRejection{Reason: reason, Code: code, Message: check.Accept.RejectReason})
+	}
+
+	result.Allowed = len(result.Rejections) == 0
+	return result
+}
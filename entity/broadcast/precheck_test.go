@@ -0,0 +1,51 @@
+package broadcast
+
+import "testing"
+
+func TestParseRejectReason(t *testing.T) {
+	cases := []struct {
+		message string
+		reason  string
+		code    int
+	}{
+		{"66: insufficient priority", RejectFeeTooLow, 66},
+		{"66: mempool min fee not met, 100 < 250", RejectFeeTooLow, 66},
+		{"missing-inputs", RejectMissingInputs, 0},
+		{"16: mandatory-script-verify-flag-failed (Script failed an OP_EQUALVERIFY operation)", RejectInvalidScript, 16},
+		{"18: txn-already-known", RejectAlreadyInMempool, 18},
+		{"Transaction already in block chain", RejectAlreadyInChain, 0},
+		{"64: dust", RejectNonStandard, 64},
+		{"16: bad-txns-vout-negative", RejectOther, 16},
+	}
+	for _, c := range cases {
+		reason, code := ParseRejectReason(c.message)
+		if reason != c.reason || code != c.code {
+			t.Errorf("ParseRejectReason(%q) = %s, %d, want %s, %d", c.message, reason, code, c.reason, c.code)
+		}
+	}
+}
+
+func TestNewDryRunResult(t *testing.T) {
+	result := NewDryRunResult("aa", &NodePrecheck{Size: 225, Accept: &MempoolAccept{TxID: "aa", Allowed: true}})
+	if !result.Allowed || len(result.Rejections) != 0 || len(result.Skipped) != 0 || result.Size != 225 {
+		t.Errorf("可接受的交易不应有拒绝原因: %+v", result)
+	}
+
+	// 已确认的交易testmempoolaccept也会拒绝，相同原因只列出一次
+	result = NewDryRunResult("aa", &NodePrecheck{
+		Known:         true,
+		Confirmations: 3,
+		Accept:        &MempoolAccept{TxID: "aa", RejectReason: "Transaction already in block chain"},
+	})
+	if result.Allowed || len(result.Rejections) != 1 || result.Rejections[0].Reason != RejectAlreadyInChain {
+		t.Errorf("已确认的交易应只有一个拒绝原因: %+v", result)
+	}
+
+	result = NewDryRunResult("aa", &NodePrecheck{DecodeError: &BroadcastError{Code: -22, Message: "TX decode failed"}})
+	if result.Allowed || result.Rejections[0].Reason != RejectSyntax || result.Rejections[0].Code != -22 {
+		t.Errorf("解码失败应返回语法错误: %+v", result)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != CheckMempoolAccept {
+		t.Errorf("未执行内存池接受检查时应列入Skipped: %+v", result)
+	}
+}
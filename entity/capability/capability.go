@@ -0,0 +1,47 @@
+package capability
+
+// 可选功能名称
+const (
+	FeatureWebSocket = "websocket"  // WebSocket订阅推送
+	FeatureWebhooks  = "webhooks"   // Webhook回调通知
+	FeatureDBHistory = "db-history" // 基于数据库的地址历史分页和导出
+	FeatureAnalytics = "analytics"  // 独立分析库支持的统计接口
+	FeatureFaucet    = "faucet"     // 测试网水龙头
+)
+
+// APIVersion 能力描述格式的版本，字段含义发生不兼容变化时递增
+const APIVersion = "1"
+
+// Feature 单个可选功能的启用状态
+type Feature struct {
+	Enabled bool   `json:"enabled"`
+	Version string `json:"version,omitempty"` // 功能接口版本，未启用时为空
+	Backend string `json:"backend,omitempty"` // 提供该功能的后端，如分析库类型
+}
+
+// Network 当前部署提供服务的网络
+type Network struct {
+	Name   string `json:"name"`   // 网络名称，与接口路径中的网络段一致
+	Prefix string `json:"prefix"` // 接口路径前缀
+	Chain  string `json:"chain"`  // 节点报告的链名称，节点不可用时为空
+}
+
+// Upstream 上游服务信息
+type Upstream struct {
+	ElectrumXServer   string `json:"electrumx_server,omitempty"`   // ElectrumX服务器软件版本
+	ElectrumXProtocol string `json:"electrumx_protocol,omitempty"` // 协商的ElectrumX协议版本
+}
+
+// CapabilitiesResponse 部署能力描述，客户端据此判断可用功能而不必逐个探测接口
+type CapabilitiesResponse struct {
+	APIVersion string             `json:"api_version"`
+	ReadOnly   bool               `json:"read_only"`
+	Features   map[string]Feature `json:"features"`
+	Networks   []Network          `json:"networks"`
+	Upstream   Upstream           `json:"upstream"`
+}
+
+// Enabled 判断功能是否启用，未列出的功能视为未启用
+func (r *CapabilitiesResponse) Enabled(name string) bool {
+	return r.Features[name].Enabled
+}
@@ -14,18 +14,61 @@ func init() {
 
 // TBCConfig 总配置结构
 type TBCConfig struct {
-	Server    ServerConfig    `yaml:"server"`
-	Log       LogConfig       `yaml:"log"`
-	DB        DBConfig        `yaml:"db"`
-	TBCNode   TBCNodeConfig   `yaml:"tbcnode"`
-	ElectrumX ElectrumXConfig `yaml:"electrumx"`
+	Server         ServerConfig         `yaml:"server"`
+	Log            LogConfig            `yaml:"log"`
+	DB             DBConfig             `yaml:"db"`
+	TBCNode        TBCNodeConfig        `yaml:"tbcnode"`
+	ElectrumX      ElectrumXConfig      `yaml:"electrumx"`
+	Pagination     PaginationConfig     `yaml:"pagination"`
+	Shadow         ShadowConfig         `yaml:"shadow"`
+	Admin          AdminConfig          `yaml:"admin"`
+	Faucet         FaucetConfig         `yaml:"faucet"`
+	Wallet         WalletConfig         `yaml:"wallet"`
+	ScriptHash     ScriptHashConfig     `yaml:"scripthash"`
+	Export         ExportConfig         `yaml:"export"`
+	Icon           IconConfig           `yaml:"icon"`
+	Analytics      AnalyticsConfig      `yaml:"analytics"`
+	EventBus       EventBusConfig       `yaml:"eventbus"`
+	WebSocket      WebSocketConfig      `yaml:"websocket"`
+	Notify         NotifyConfig         `yaml:"notify"`
+	Cache          CacheConfig          `yaml:"cache"`
+	Health         HealthConfig         `yaml:"health"`
+	HolderSnapshot HolderSnapshotConfig `yaml:"holdersnapshot"`
+	Scheduler      SchedulerConfig      `yaml:"scheduler"`
+	RateLimit      RateLimitConfig      `yaml:"ratelimit"`
+	Cost           CostConfig           `yaml:"cost"`
+	Alert          AlertConfig          `yaml:"alert"`
+	Trace          TraceConfig          `yaml:"trace"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Name string `yaml:"name"`
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Name     string `yaml:"name"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	ReadOnly bool   `yaml:"readonly"` // 只读模式，开启后广播等写入接口返回403，用于部署公开镜像
+	// 受信任的反向代理地址或网段，只有来自这些地址的请求才从X-Forwarded-For等请求头取客户端IP
+	// 未配置时不信任任何代理，客户端IP取连接的对端地址
+	TrustedProxies []string `yaml:"trustedproxies"`
+	// 广播返回的一致性令牌的签名密钥，为空时每次启动随机生成，多实例部署时需要配置为相同的值
+	ConsistencyKey string `yaml:"consistencykey"`
+
+	Internal InternalServerConfig `yaml:"internal"` // 内部监听配置
+	Gzip     GzipConfig           `yaml:"gzip"`     // 响应压缩配置
+}
+
+// GzipConfig 响应gzip压缩配置
+type GzipConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Level   int  `yaml:"level"`   // 压缩级别1-9，0表示使用默认级别
+	MinSize int  `yaml:"minsize"` // 响应体达到该字节数才压缩，0表示使用默认值1024
+}
+
+// InternalServerConfig 内部监听配置，/metrics、管理接口和pprof只在该地址上提供
+type InternalServerConfig struct {
+	Host  string `yaml:"host"`
+	Port  int    `yaml:"port"`  // 为0时不启用内部监听，管理接口仍挂载在公开地址上
+	Pprof bool   `yaml:"pprof"` // 是否注册/debug/pprof性能分析接口
 }
 
 // LogConfig 日志配置
@@ -44,6 +87,7 @@ type DBConfig struct {
 	Charset      string `yaml:"charset"`
 	MaxIdleConns int    `yaml:"maxidleconns"`
 	MaxOpenConns int    `yaml:"maxopenconns"`
+	QueryTimeout int    `yaml:"querytimeout"` // 历史查询并发子查询的超时时间(秒)
 }
 
 // TBCNodeConfig RPC客户端配置
@@ -54,6 +98,32 @@ type TBCNodeConfig struct {
 	Timeout      int    `yaml:"timeout"`
 	MaxIdleConns int    `yaml:"maxidleconns"`
 	MaxOpenConns int    `yaml:"maxopenconns"`
+
+	// 服务发现配置
+	Discovery         string `yaml:"discovery"`         // 发现模式：空为静态地址，srv或dns
+	DiscoveryName     string `yaml:"discoveryname"`     // 解析的服务名，为空时使用URL中的主机名
+	DiscoveryInterval int    `yaml:"discoveryinterval"` // 刷新周期(秒)
+
+	WarmUpConns int `yaml:"warmupconns"` // 启动时预热的连接数，0表示不预热
+
+	MaxConnLifetime   int `yaml:"maxconnlifetime"`   // 连接最大寿命(秒)，到期后回收重建
+	ValidateAfterIdle int `yaml:"validateafteridle"` // 连接空闲超过该时长(秒)后取用前强制验证
+
+	// 对冲请求配置，仅作用于幂等的读请求
+	Hedge       bool `yaml:"hedge"`       // 是否启用对冲请求
+	HedgeDelay  int  `yaml:"hedgedelay"`  // 发出对冲请求前的等待时间(毫秒)，0表示使用观测到的P95延迟
+	HedgeBudget int  `yaml:"hedgebudget"` // 允许对冲的请求占比(百分比)
+
+	BatchSize int `yaml:"batchsize"` // 单个JSON-RPC批量请求包含的最大调用数，0表示使用默认值
+
+	// 多节点故障切换配置，配置了备用节点或启用服务发现时生效
+	URLs                []string `yaml:"urls"`                // 备用节点URL，与url一起按权重轮询
+	Weights             []int    `yaml:"weights"`             // url和urls依次对应的权重，未配置或不大于0时为1
+	FailureThreshold    int      `yaml:"failurethreshold"`    // 连续失败多少次后断开节点，0表示使用默认值
+	MaxBackoff          int      `yaml:"maxbackoff"`          // 断开的节点重新试探前等待的上限(秒)，0表示使用默认值
+	HealthCheckInterval int      `yaml:"healthcheckinterval"` // 健康检查周期(秒)，0表示使用默认值
+
+	Breaker BreakerConfig `yaml:"breaker"` // 按方法的断路器配置
 }
 
 // ElectrumXConfig ElectrumX RPC客户端配置
@@ -66,6 +136,260 @@ type ElectrumXConfig struct {
 	Protocol     string `yaml:"protocol"`
 	MaxIdleConns int    `yaml:"maxidleconns"`
 	MaxOpenConns int    `yaml:"maxopenconns"`
+
+	// 服务发现配置
+	Discovery         string `yaml:"discovery"`         // 发现模式：空为静态地址，srv或dns
+	DiscoveryName     string `yaml:"discoveryname"`     // 解析的服务名，为空时使用Host
+	DiscoveryInterval int    `yaml:"discoveryinterval"` // 刷新周期(秒)
+
+	WarmUpConns int `yaml:"warmupconns"` // 启动时预热的连接数，0表示不预热
+
+	MaxConnLifetime   int `yaml:"maxconnlifetime"`   // 连接最大寿命(秒)，到期后回收重建
+	ValidateAfterIdle int `yaml:"validateafteridle"` // 连接空闲超过该时长(秒)后取用前强制验证
+
+	// 对冲请求配置，仅作用于幂等的读请求
+	Hedge       bool `yaml:"hedge"`       // 是否启用对冲请求
+	HedgeDelay  int  `yaml:"hedgedelay"`  // 发出对冲请求前的等待时间(毫秒)，0表示使用观测到的P95延迟
+	HedgeBudget int  `yaml:"hedgebudget"` // 允许对冲的请求占比(百分比)
+
+	// 多服务器故障切换配置，配置了备用服务器或启用服务发现时生效
+	Servers             []string `yaml:"servers"`             // 备用服务器地址(host:port)，按配置顺序排在host:port之后
+	FailureThreshold    int      `yaml:"failurethreshold"`    // 连续失败多少次后切换到其它服务器，0表示使用默认值
+	MaxBackoff          int      `yaml:"maxbackoff"`          // 不健康服务器重试等待的上限(秒)，0表示使用默认值
+	HealthCheckInterval int      `yaml:"healthcheckinterval"` // 健康检查周期(秒)，0表示使用默认值
+
+	Breaker BreakerConfig `yaml:"breaker"` // 按方法的断路器配置
+
+	MaxHistoryItems int `yaml:"maxhistoryitems"` // 单次历史查询允许的记录数上限，超出时返回错误，0表示使用默认值
+}
+
+// BreakerConfig 上游调用断路器配置，每个方法独立计数
+type BreakerConfig struct {
+	FailureThreshold int                   `yaml:"failurethreshold"` // 同一方法连续失败多少次后打开，0表示使用默认值
+	OpenTimeout      int                   `yaml:"opentimeout"`      // 打开后等待多久(秒)进入半开放行试探调用，0表示使用默认值
+	HalfOpenRequests int                   `yaml:"halfopenrequests"` // 半开时同时放行的试探调用数，0表示使用默认值
+	MaxInFlight      int                   `yaml:"maxinflight"`      // 同一方法同时进行的调用数上限，0表示不限制
+	Methods          []BreakerMethodConfig `yaml:"methods"`          // 按方法覆盖的参数
+}
+
+// BreakerMethodConfig 单个方法的断路器参数，为0的字段沿用BreakerConfig中的值
+type BreakerMethodConfig struct {
+	Method           string `yaml:"method"`
+	FailureThreshold int    `yaml:"failurethreshold"`
+	OpenTimeout      int    `yaml:"opentimeout"`
+	HalfOpenRequests int    `yaml:"halfopenrequests"`
+	MaxInFlight      int    `yaml:"maxinflight"`
+}
+
+// PaginationConfig 分页配置
+type PaginationConfig struct {
+	MaxPageSize int            `yaml:"maxpagesize"` // 默认的每页最大记录数
+	Endpoints   map[string]int `yaml:"endpoints"`   // 按接口覆盖的每页最大记录数
+}
+
+// ShadowConfig 影子流量配置，用于在切换数据源前比对数据库路径与ElectrumX路径的结果
+type ShadowConfig struct {
+	Routes  map[string]int `yaml:"routes"`  // 按路由配置的影子请求采样比例(百分比)，未配置表示关闭
+	Timeout int            `yaml:"timeout"` // 影子请求超时时间(秒)
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Token string `yaml:"token"` // 管理接口令牌，为空时禁用所有管理接口
+}
+
+// FaucetConfig 测试网水龙头配置，仅在节点连接测试网或回归测试网时生效
+type FaucetConfig struct {
+	Enabled         bool    `yaml:"enabled"`         // 是否启用水龙头接口
+	Amount          float64 `yaml:"amount"`          // 每次发放的金额(TBC)
+	AddressCooldown int     `yaml:"addresscooldown"` // 同一地址两次领取的最小间隔(秒)
+	IPCooldown      int     `yaml:"ipcooldown"`      // 同一IP两次领取的最小间隔(秒)
+}
+
+// WalletConfig 跟踪钱包配置
+type WalletConfig struct {
+	MaxAddresses int `yaml:"maxaddresses"` // 单个钱包最多跟踪的地址数量
+	Lookahead    int `yaml:"lookahead"`    // 扩展公钥默认在收款和找零链上各派生的地址数量
+	SyncInterval int `yaml:"syncinterval"` // 后台刷新钱包汇总数据的周期(秒)，0表示关闭后台刷新
+}
+
+// ScriptHashConfig 地址与脚本哈希映射配置
+type ScriptHashConfig struct {
+	CacheSize int  `yaml:"cachesize"` // 内存中缓存的映射数量
+	Persist   bool `yaml:"persist"`   // 是否将映射写入数据库，开启后可按脚本哈希反查地址
+}
+
+// ExportConfig 历史导出任务配置
+type ExportConfig struct {
+	Dir        string   `yaml:"dir"`        // 导出文件的存储目录
+	SigningKey string   `yaml:"signingkey"` // 下载链接的签名密钥，为空时每次启动随机生成
+	URLTTL     int      `yaml:"urlttl"`     // 下载链接的有效期(秒)
+	Retention  int      `yaml:"retention"`  // 任务和导出文件的保留时间(秒)
+	MaxJobs    int      `yaml:"maxjobs"`    // 同时运行的导出任务数上限
+	S3         S3Config `yaml:"s3"`         // 配置存储桶时导出文件写入S3兼容存储，否则写入Dir
+}
+
+// S3Config S3兼容对象存储配置，Bucket为空时不启用
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`  // 服务地址，如https://s3.us-east-1.amazonaws.com
+	Region    string `yaml:"region"`    // 区域
+	Bucket    string `yaml:"bucket"`    // 存储桶
+	AccessKey string `yaml:"accesskey"` // 访问密钥ID
+	SecretKey string `yaml:"secretkey"` // 访问密钥
+	Prefix    string `yaml:"prefix"`    // 对象键前缀
+	PathStyle bool   `yaml:"pathstyle"` // 使用路径风格的地址，MinIO等自建服务通常需要开启
+}
+
+// IconConfig 代币图标抓取和缓存配置
+type IconConfig struct {
+	Dir          string `yaml:"dir"`          // 校验后图标的缓存目录
+	MaxBytes     int64  `yaml:"maxbytes"`     // 远程图标的大小上限(字节)
+	MaxDimension int    `yaml:"maxdimension"` // 远程图标宽和高的上限(像素)
+	Timeout      int    `yaml:"timeout"`      // 单次抓取的超时时间(秒)
+	TTL          int    `yaml:"ttl"`          // 缓存的图标超过该时长(秒)后重新抓取
+	FailureTTL   int    `yaml:"failurettl"`   // 抓取失败后在该时长(秒)内不再重试
+}
+
+// AnalyticsConfig 分析存储配置，启用后新区块的交易摘要同时写入分析库，统计接口改为查询分析库
+type AnalyticsConfig struct {
+	Driver   string `yaml:"driver"`   // 分析库类型，目前支持clickhouse，为空时不启用
+	URL      string `yaml:"url"`      // HTTP接口地址
+	Database string `yaml:"database"` // 数据库名
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Timeout  int    `yaml:"timeout"` // 单次请求超时时间(秒)
+}
+
+// EventBusConfig 事件总线配置
+type EventBusConfig struct {
+	Driver    string `yaml:"driver"`    // 事件日志类型：memory、file或redis，file和redis模式下重启后可按偏移量重放，redis模式下多个实例共享事件日志
+	Dir       string `yaml:"dir"`       // file模式下事件和消费偏移量的存储目录
+	Retention int    `yaml:"retention"` // 每个主题保留的事件数
+	Publish   bool   `yaml:"publish"`   // 是否跟随链顶发布区块、交易和转账事件，redis模式下只应在一个实例上开启
+}
+
+// WebSocketConfig WebSocket订阅配置
+type WebSocketConfig struct {
+	Enabled          bool `yaml:"enabled"`          // 是否启用/ws订阅接口
+	MaxConnections   int  `yaml:"maxconnections"`   // 同时在线的连接数上限，0表示不限制
+	MaxSubscriptions int  `yaml:"maxsubscriptions"` // 单个连接最多订阅的地址和脚本哈希数量
+}
+
+// CacheConfig 共享缓存配置
+type CacheConfig struct {
+	Redis RedisConfig `yaml:"redis"`
+	// 集群模式，水龙头冷却、限流配额、只读开关和导出任务状态通过Redis在实例之间共享，需要配置Redis
+	// WebSocket订阅和开销等级的并发名额绑定在实例的连接上，仍按实例维护；导出文件需要写入S3兼容存储或共享目录
+	Cluster bool `yaml:"cluster"`
+}
+
+// RedisConfig Redis连接配置，Addr为空时不启用
+type RedisConfig struct {
+	Addr     string `yaml:"addr"` // 地址，如：127.0.0.1:6379
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+	Prefix   string `yaml:"prefix"`  // 键前缀，多个部署共用同一个Redis时用于隔离
+	Timeout  int    `yaml:"timeout"` // 单次命令超时时间(毫秒)
+}
+
+// HealthConfig 综合健康评分和自适应限流配置，未配置的阈值使用默认值
+type HealthConfig struct {
+	LatencyTarget int     `yaml:"latencytarget"` // 上游P95延迟目标(毫秒)，不超过时延迟项满分
+	MaxErrorRate  float64 `yaml:"maxerrorrate"`  // 上游错误率达到该值时错误项为0
+	MaxLag        int64   `yaml:"maxlag"`        // 索引落后链顶的区块数达到该值时滞后项为0
+	Shed          bool    `yaml:"shed"`          // 是否在评分过低时按开销等级拒绝请求
+	ShedHeavy     int     `yaml:"shedheavy"`     // 评分低于该值时拒绝heavy接口
+	ShedNormal    int     `yaml:"shednormal"`    // 评分低于该值时拒绝normal接口
+	HedgeMin      int     `yaml:"hedgemin"`      // 评分低于该值时停止对冲请求，避免加重上游负载
+}
+
+// RateLimitConfig 按客户端限流配置，每个实例分别计数
+type RateLimitConfig struct {
+	Enabled    bool                     `yaml:"enabled"`
+	KeyHeader  string                   `yaml:"keyheader"`  // 携带API密钥的请求头，默认为X-API-Key
+	APIKeys    []RateLimitAPIKey        `yaml:"apikeys"`    // 已登记的API密钥，未登记的密钥按客户端IP限流
+	Groups     map[string]RateLimitRule `yaml:"groups"`     // 按路由分组(开销等级light、normal、heavy)配置的配额，未配置的分组不限流
+	MaxClients int                      `yaml:"maxclients"` // 同时跟踪的客户端数量，超出后淘汰最久未请求的客户端
+}
+
+// RateLimitAPIKey 已登记的API密钥
+type RateLimitAPIKey struct {
+	Name       string  `yaml:"name"` // 日志中显示的名称，不记录密钥本身
+	Key        string  `yaml:"key"`
+	Multiplier float64 `yaml:"multiplier"` // 配额倍数，为0时按1计算
+}
+
+// RateLimitRule 令牌桶配额
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate"`  // 每秒补充的令牌数，为0时不限流
+	Burst int     `yaml:"burst"` // 令牌桶容量，即允许的突发请求数
+}
+
+// CostConfig 按路由开销等级配置的请求超时和并发上限，限流配额见RateLimitConfig.Groups
+type CostConfig struct {
+	Classes map[string]CostClassRule `yaml:"classes"` // 按开销等级(light、normal、heavy)配置，未配置的等级不设超时和并发上限
+}
+
+// CostClassRule 单个开销等级的请求处理参数
+type CostClassRule struct {
+	Timeout       int `yaml:"timeout"`       // 默认请求超时(秒)，0表示不设超时
+	MaxTimeout    int `yaml:"maxtimeout"`    // 客户端通过X-Request-Timeout请求头可指定的最大超时(秒)，0表示不允许客户端调整
+	MaxConcurrent int `yaml:"maxconcurrent"` // 同时处理的请求数上限，0表示不限制
+	QueueWait     int `yaml:"queuewait"`     // 达到并发上限时排队等待的最长时间(毫秒)，超时返回503
+}
+
+// HolderSnapshotConfig 代币持有者排名快照配置
+type HolderSnapshotConfig struct {
+	Interval int `yaml:"interval"` // 快照周期(小时)，0表示不生成快照，排名接口不返回名次变化
+	TopN     int `yaml:"topn"`     // 每个代币保存的前N名持有者，之后的持有者按新上榜处理
+}
+
+// SchedulerConfig 周期任务配置
+type SchedulerConfig struct {
+	LockTimeout      int            `yaml:"locktimeout"`      // 单次运行的最长时间(秒)，也是数据库锁的租约，实例异常退出后其它实例最迟在此之后接管
+	Jobs             map[string]int `yaml:"jobs"`             // 按任务名覆盖运行周期(秒)，0表示停用，未配置的任务使用默认周期
+	FailureRetention int            `yaml:"failureretention"` // 已重放成功的广播失败记录保留天数，0表示使用默认值
+}
+
+// NotifyConfig 跟踪钱包通知配置
+type NotifyConfig struct {
+	SMTP SMTPConfig `yaml:"smtp"`
+}
+
+// SMTPConfig 邮件通知的SMTP服务配置，Host为空时不启用邮件通知
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"` // 587等端口在服务端支持时使用STARTTLS，465端口使用TLS直连
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`    // 发件人地址
+	Timeout  int    `yaml:"timeout"` // 单封邮件的发送超时时间(秒)
+}
+
+// AlertConfig 内置告警配置，规则由周期任务alert_evaluate评估，通过已启用的通知渠道发送
+type AlertConfig struct {
+	Channel string      `yaml:"channel"` // 通知渠道，默认为email
+	Target  string      `yaml:"target"`  // 通知接收方，为空时不发送通知，只在/admin/alerts中显示状态
+	Rules   []AlertRule `yaml:"rules"`
+}
+
+// AlertRule 告警规则，指标为健康评分的评分项名称(如indexer_lag、node_errors、electrumx_latency)、
+// health_score，或节点内存池的mempool_size、mempool_bytes
+type AlertRule struct {
+	Name      string  `yaml:"name"`
+	Metric    string  `yaml:"metric"`
+	Op        string  `yaml:"op"` // 比较方式：>、>=、<、<=，默认为>
+	Threshold float64 `yaml:"threshold"`
+	Change    int     `yaml:"change"` // 大于0时比较该时长(秒)内的变化量而不是当前值
+	For       int     `yaml:"for"`    // 连续满足条件的评估次数达到该值才告警，0按1计算
+	Repeat    int     `yaml:"repeat"` // 持续告警时重复通知的间隔(秒)，0表示只在开始和恢复时通知
+}
+
+// TraceConfig 上游调用与API请求trace的关联配置
+type TraceConfig struct {
+	Propagation   string `yaml:"propagation"`   // trace带入节点RPC的方式：log只记录旁路日志(默认)，id编码进请求ID，header写入请求头
+	SlowThreshold int    `yaml:"slowthreshold"` // 上游调用超过该耗时(毫秒)记为慢调用，0表示使用默认值500
+	RecentCalls   int    `yaml:"recentcalls"`   // 保留的最近上游调用数量，慢请求日志从中按trace关联，0表示使用默认值1024
 }
 
 // GetConfig 获取配置
@@ -98,3 +422,103 @@ func (c *TBCConfig) GetTBCNodeConfig() *TBCNodeConfig {
 func (c *TBCConfig) GetElectrumXConfig() *ElectrumXConfig {
 	return &c.ElectrumX
 }
+
+// GetPaginationConfig 获取分页配置
+func (c *TBCConfig) GetPaginationConfig() *PaginationConfig {
+	return &c.Pagination
+}
+
+// GetShadowConfig 获取影子流量配置
+func (c *TBCConfig) GetShadowConfig() *ShadowConfig {
+	return &c.Shadow
+}
+
+// GetAdminConfig 获取管理接口配置
+func (c *TBCConfig) GetAdminConfig() *AdminConfig {
+	return &c.Admin
+}
+
+// GetFaucetConfig 获取水龙头配置
+func (c *TBCConfig) GetFaucetConfig() *FaucetConfig {
+	return &c.Faucet
+}
+
+// GetWalletConfig 获取跟踪钱包配置
+func (c *TBCConfig) GetWalletConfig() *WalletConfig {
+	return &c.Wallet
+}
+
+// GetScriptHashConfig 获取地址与脚本哈希映射配置
+func (c *TBCConfig) GetScriptHashConfig() *ScriptHashConfig {
+	return &c.ScriptHash
+}
+
+// GetExportConfig 获取历史导出任务配置
+func (c *TBCConfig) GetExportConfig() *ExportConfig {
+	return &c.Export
+}
+
+// GetIconConfig 获取代币图标抓取和缓存配置
+func (c *TBCConfig) GetIconConfig() *IconConfig {
+	return &c.Icon
+}
+
+// GetAnalyticsConfig 获取分析存储配置
+func (c *TBCConfig) GetAnalyticsConfig() *AnalyticsConfig {
+	return &c.Analytics
+}
+
+// GetEventBusConfig 获取事件总线配置
+func (c *TBCConfig) GetEventBusConfig() *EventBusConfig {
+	return &c.EventBus
+}
+
+// GetWebSocketConfig 获取WebSocket订阅配置
+func (c *TBCConfig) GetWebSocketConfig() *WebSocketConfig {
+	return &c.WebSocket
+}
+
+// GetNotifyConfig 获取跟踪钱包通知配置
+func (c *TBCConfig) GetNotifyConfig() *NotifyConfig {
+	return &c.Notify
+}
+
+// GetCacheConfig 获取共享缓存配置
+func (c *TBCConfig) GetCacheConfig() *CacheConfig {
+	return &c.Cache
+}
+
+// GetHealthConfig 获取健康评分配置
+func (c *TBCConfig) GetHealthConfig() *HealthConfig {
+	return &c.Health
+}
+
+// GetHolderSnapshotConfig 获取代币持有者排名快照配置
+func (c *TBCConfig) GetHolderSnapshotConfig() *HolderSnapshotConfig {
+	return &c.HolderSnapshot
+}
+
+// GetSchedulerConfig 获取周期任务配置
+func (c *TBCConfig) GetSchedulerConfig() *SchedulerConfig {
+	return &c.Scheduler
+}
+
+// GetRateLimitConfig 获取限流配置
+func (c *TBCConfig) GetRateLimitConfig() *RateLimitConfig {
+	return &c.RateLimit
+}
+
+// GetCostConfig 获取按开销等级的请求处理配置
+func (c *TBCConfig) GetCostConfig() *CostConfig {
+	return &c.Cost
+}
+
+// GetAlertConfig 获取告警配置
+func (c *TBCConfig) GetAlertConfig() *AlertConfig {
+	return &c.Alert
+}
+
+// GetTraceConfig 获取上游调用trace关联配置
+func (c *TBCConfig) GetTraceConfig() *TraceConfig {
+	return &c.Trace
+}
@@ -0,0 +1,79 @@
+package consistency
+
+import "fmt"
+
+// 一致性检查项
+const (
+	CheckHistory   = "history"    // 地址交易历史，ElectrumX历史与地址交易记录表比较
+	CheckFtUtxo    = "ft_utxo"    // 代币未花费输出集合，ElectrumX与代币输出表比较
+	CheckFtBalance = "ft_balance" // 代币余额，ElectrumX未花费输出的代币数量之和与代币输出表比较
+)
+
+const (
+	// DefaultSampleSize 未指定时抽样的地址数和代币持有数
+	DefaultSampleSize = 20
+	// MaxSampleSize 单次检查最多抽样的地址数和代币持有数
+	MaxSampleSize = 500
+	// DefaultSettleDepth 未指定时只比较至少有该确认数的数据，索引器处理最近的区块有延迟
+	DefaultSettleDepth = 6
+)
+
+// CheckRequest 一致性检查参数
+type CheckRequest struct {
+	Addresses   int   `form:"addresses" json:"addresses"`       // 抽样的地址数，为0时使用默认值
+	FtHoldings  int   `form:"ft_holdings" json:"ft_holdings"`   // 抽样的代币持有数(持有者和合约的组合)，为0时使用默认值
+	SettleDepth int64 `form:"settle_depth" json:"settle_depth"` // 只比较至少有该确认数的数据，为0时使用默认值
+}
+
+// Validate 校验参数并填充默认值
+func (req *CheckRequest) Validate() error {
+	if req.Addresses < 0 || req.Addresses > MaxSampleSize {
+		return fmt.Errorf("地址抽样数必须在0到%d之间", MaxSampleSize)
+	}
+	if req.FtHoldings < 0 || req.FtHoldings > MaxSampleSize {
+		return fmt.Errorf("代币持有抽样数必须在0到%d之间", MaxSampleSize)
+	}
+	if req.SettleDepth < 0 {
+		return fmt.Errorf("确认深度不能为负数")
+	}
+	if req.Addresses == 0 {
+		req.Addresses = DefaultSampleSize
+	}
+	if req.FtHoldings == 0 {
+		req.FtHoldings = DefaultSampleSize
+	}
+	if req.SettleDepth == 0 {
+		req.SettleDepth = DefaultSettleDepth
+	}
+	return nil
+}
+
+// Discrepancy 单个抽样对象在某个检查项上的差异
+type Discrepancy struct {
+	Check   string   `json:"check"`
+	Subject string   `json:"subject"`           // 地址，代币检查为"组合脚本:合约ID"
+	Live    string   `json:"live"`              // ElectrumX路径的结果，历史和输出集合为数量
+	DB      string   `json:"db"`                // 数据库路径的结果
+	Missing []string `json:"missing,omitempty"` // ElectrumX有而数据库缺失的交易或输出，最多列出MaxListed个
+	Extra   []string `json:"extra,omitempty"`   // 数据库有而ElectrumX没有的交易或输出，最多列出MaxListed个
+}
+
+// MaxListed 每项差异最多列出的交易或输出数
+const MaxListed = 10
+
+// Report 一致性检查报告
+type Report struct {
+	StartedAt     int64         `json:"started_at"`
+	FinishedAt    int64         `json:"finished_at"`
+	SettleHeight  int64         `json:"settle_height"` // 只比较该高度及以下的已确认数据
+	Addresses     int           `json:"addresses"`     // 实际抽样的地址数
+	FtHoldings    int           `json:"ft_holdings"`   // 实际抽样的代币持有数
+	Checks        int           `json:"checks"`        // 完成比较的检查项数
+	Skipped       int           `json:"skipped"`       // 请求失败或数据量超限而未比较的检查项数
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// Passed 是否没有发现差异
+func (r *Report) Passed() bool {
+	return len(r.Discrepancies) == 0
+}
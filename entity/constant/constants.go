@@ -6,6 +6,10 @@ const (
 	CodeSuccess = 200
 	// 参数错误
 	CodeInvalidParams = 400
+	// 未授权
+	CodeUnauthorized = 401
+	// 禁止访问
+	CodeForbidden = 403
 	// 未找到记录
 	CodeNotFound = 404
 	// 内部服务器错误
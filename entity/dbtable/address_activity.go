@@ -0,0 +1,22 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// AddressActivity 地址活跃度索引表实体
+type AddressActivity struct {
+	Address          string    `db:"address" gorm:"column:address;primaryKey"`
+	FirstSeenHeight  int64     `db:"first_seen_height" gorm:"column:first_seen_height"`
+	FirstSeenTime    int64     `db:"first_seen_time" gorm:"column:first_seen_time"`
+	FirstSeenTx      string    `db:"first_seen_tx" gorm:"column:first_seen_tx"`
+	LastActiveHeight int64     `db:"last_active_height" gorm:"column:last_active_height"`
+	LastActiveTime   int64     `db:"last_active_time" gorm:"column:last_active_time"`
+	LastActiveTx     string    `db:"last_active_tx" gorm:"column:last_active_tx"`
+	UpdatedAt        time.Time `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (AddressActivity) TableName() string {
+	return "TBC20721.address_activity"
+}
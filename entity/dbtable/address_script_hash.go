@@ -0,0 +1,19 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// AddressScriptHash 地址与脚本哈希映射表实体
+type AddressScriptHash struct {
+	ScriptHash string    `db:"script_hash" gorm:"column:script_hash;primaryKey"`
+	Address    string    `db:"address" gorm:"column:address;index:idx_address_kind"`
+	Kind       string    `db:"kind" gorm:"column:kind;index:idx_address_kind"`
+	Qualifier  string    `db:"qualifier" gorm:"column:qualifier"` // FT脚本为合约ID，其他类型为空
+	CreatedAt  time.Time `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 返回表名
+func (AddressScriptHash) TableName() string {
+	return "TBC20721.address_script_hashes"
+}
@@ -0,0 +1,22 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// BroadcastFailure 广播失败记录表实体
+type BroadcastFailure struct {
+	Id        int64     `db:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	TxId      string    `db:"txid" gorm:"column:txid;uniqueIndex:uk_txid"`
+	TxHex     string    `db:"tx_hex" gorm:"column:tx_hex"`
+	Error     string    `db:"error" gorm:"column:error"`
+	Attempts  int       `db:"attempts" gorm:"column:attempts"`
+	Status    string    `db:"status" gorm:"column:status"` // 取值见broadcast.FailureStatus*
+	CreatedAt time.Time `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (BroadcastFailure) TableName() string {
+	return "TBC20721.broadcast_failures"
+}
@@ -0,0 +1,27 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// FtBurn 代币销毁记录表实体
+type FtBurn struct {
+	Txid         string    `db:"txid" gorm:"column:txid;primaryKey"`
+	Vout         int       `db:"vout" gorm:"column:vout;primaryKey"`
+	FtContractId string    `db:"ft_contract_id" gorm:"column:ft_contract_id"`
+	Amount       uint64    `db:"amount" gorm:"column:amount"`
+	Height       int64     `db:"height" gorm:"column:height"`         // 历史回填且未知时为0
+	BlockTime    int64     `db:"block_time" gorm:"column:block_time"` // 历史回填时为0
+	CreatedAt    time.Time `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 返回表名
+func (FtBurn) TableName() string {
+	return "TBC20721.ft_burns"
+}
+
+// FtBurnTotals 代币的销毁汇总
+type FtBurnTotals struct {
+	BurnCount   int64  `gorm:"column:burn_count"`
+	TotalBurned uint64 `gorm:"column:total_burned"`
+}
@@ -0,0 +1,44 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// FtPoolSwap 池兑换记录表实体
+type FtPoolSwap struct {
+	Txid         string    `db:"txid" gorm:"column:txid;primaryKey"`
+	FtContractId string    `db:"ft_contract_id" gorm:"column:ft_contract_id"`
+	Height       int64     `db:"height" gorm:"column:height"`
+	BlockTime    int64     `db:"block_time" gorm:"column:block_time"`
+	TxIndex      int       `db:"tx_index" gorm:"column:tx_index"`
+	TbcChange    int64     `db:"tbc_change" gorm:"column:tbc_change"` // 正数表示买入代币
+	FtChange     int64     `db:"ft_change" gorm:"column:ft_change"`
+	TbcReserve   int64     `db:"tbc_reserve" gorm:"column:tbc_reserve"`
+	FtReserve    int64     `db:"ft_reserve" gorm:"column:ft_reserve"`
+	Price        float64   `db:"price" gorm:"column:price"` // 每个代币的TBC数量，已按精度换算
+	CreatedAt    time.Time `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 返回表名
+func (FtPoolSwap) TableName() string {
+	return "TBC20721.ft_pool_swaps"
+}
+
+// FtPriceCandle 代币价格K线表实体
+type FtPriceCandle struct {
+	FtContractId string  `db:"ft_contract_id" gorm:"column:ft_contract_id;primaryKey"`
+	IntervalName string  `db:"interval_name" gorm:"column:interval_name;primaryKey"`
+	BucketStart  int64   `db:"bucket_start" gorm:"column:bucket_start;primaryKey"`
+	Open         float64 `db:"open" gorm:"column:open"`
+	High         float64 `db:"high" gorm:"column:high"`
+	Low          float64 `db:"low" gorm:"column:low"`
+	Close        float64 `db:"close" gorm:"column:close"`
+	VolumeTbc    uint64  `db:"volume_tbc" gorm:"column:volume_tbc"`
+	VolumeFt     uint64  `db:"volume_ft" gorm:"column:volume_ft"`
+	Trades       int     `db:"trades" gorm:"column:trades"`
+}
+
+// TableName 返回表名
+func (FtPriceCandle) TableName() string {
+	return "TBC20721.ft_price_candles"
+}
@@ -0,0 +1,19 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// FtHolderRankSnapshot 代币持有者排名快照表实体
+type FtHolderRankSnapshot struct {
+	FtContractId          string    `db:"ft_contract_id" gorm:"column:ft_contract_id;primaryKey"`
+	FtHolderCombineScript string    `db:"ft_holder_combine_script" gorm:"column:ft_holder_combine_script;primaryKey"`
+	HolderRank            int       `db:"holder_rank" gorm:"column:holder_rank"`
+	FtBalance             uint64    `db:"ft_balance" gorm:"column:ft_balance"`
+	SnapshotAt            time.Time `db:"snapshot_at" gorm:"column:snapshot_at"`
+}
+
+// TableName 返回表名
+func (FtHolderRankSnapshot) TableName() string {
+	return "TBC20721.ft_holder_rank_snapshot"
+}
@@ -20,6 +20,10 @@ type FtTxoSet struct {
 	FtBalance uint64 `gorm:"column:ft_balance;type:bigint unsigned"`
 	// 是否已花费
 	IfSpend bool `gorm:"column:if_spend;type:tinyint(1);index:idx_if_spend"`
+	// 输出所在交易的区块高度，未回填时为NULL
+	CreateHeight *int64 `gorm:"column:create_height;type:bigint"`
+	// 花费该输出的交易的区块高度，未花费或未回填时为NULL
+	SpendHeight *int64 `gorm:"column:spend_height;type:bigint"`
 
 	// gorm.Model的字段不包含在原始表中，但添加便于GORM管理
 	gorm.Model `gorm:"-"` // 使用-标记表示该字段不存储到数据库
@@ -29,3 +33,17 @@ type FtTxoSet struct {
 func (FtTxoSet) TableName() string {
 	return "TBC20721.ft_txo_set"
 }
+
+// FtTxoKey 代币交易输出的自然键
+type FtTxoKey struct {
+	UtxoTxid string
+	UtxoVout int
+}
+
+// FtSupplyMetrics 按高度聚合的代币供应量统计
+type FtSupplyMetrics struct {
+	CirculatingSupply uint64 `gorm:"column:circulating_supply"` // 未花费输出的代币总量
+	DormantSupply     uint64 `gorm:"column:dormant_supply"`     // 创建高度不晚于休眠截止高度的未花费代币总量
+	UnindexedSupply   uint64 `gorm:"column:unindexed_supply"`   // 尚未回填创建高度的未花费代币总量
+	TransferredSupply uint64 `gorm:"column:transferred_supply"` // 统计周期内被花费的代币总量
+}
@@ -0,0 +1,14 @@
+package dbtable
+
+// NftAttributeIndex NFT属性索引表实体，每个NFT的每个属性键一条记录
+type NftAttributeIndex struct {
+	NftContractId      string `db:"nft_contract_id" gorm:"column:nft_contract_id;primaryKey"`
+	AttrKey            string `db:"attr_key" gorm:"column:attr_key;primaryKey"`
+	AttrValue          string `db:"attr_value" gorm:"column:attr_value"`
+	NftCreateTimestamp int    `db:"nft_create_timestamp" gorm:"column:nft_create_timestamp"`
+}
+
+// TableName 返回表名
+func (NftAttributeIndex) TableName() string {
+	return "TBC20721.nft_attribute_index"
+}
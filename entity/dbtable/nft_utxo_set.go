@@ -40,6 +40,8 @@ type NftUtxoSet struct {
 	NftLastTransferTimestamp int `gorm:"column:nft_last_transfer_timestamp;type:int"`
 	// NFT图标
 	NftIcon string `gorm:"column:nft_icon;type:mediumtext"`
+	// NFT UTXO所在交易的区块高度，未回填时为NULL
+	NftUtxoHeight *int64 `gorm:"column:nft_utxo_height;type:bigint"`
 
 	// gorm.Model的字段不包含在原始表中，但添加便于GORM管理
 	gorm.Model `gorm:"-"` // 使用-标记表示该字段不存储到数据库
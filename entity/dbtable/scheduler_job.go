@@ -0,0 +1,23 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// SchedulerJob 周期任务表实体，记录任务的抢占租约和最近一次运行结果
+type SchedulerJob struct {
+	JobName        string     `db:"job_name" gorm:"column:job_name;primaryKey"`
+	Owner          string     `db:"owner" gorm:"column:owner"`
+	LockedUntil    *time.Time `db:"locked_until" gorm:"column:locked_until"`
+	LastStartedAt  *time.Time `db:"last_started_at" gorm:"column:last_started_at"`
+	LastFinishedAt *time.Time `db:"last_finished_at" gorm:"column:last_finished_at"`
+	LastStatus     string     `db:"last_status" gorm:"column:last_status"`
+	LastError      string     `db:"last_error" gorm:"column:last_error"`
+	LastDurationMs int64      `db:"last_duration_ms" gorm:"column:last_duration_ms"`
+	RunCount       int64      `db:"run_count" gorm:"column:run_count"`
+}
+
+// TableName 返回表名
+func (SchedulerJob) TableName() string {
+	return "TBC20721.scheduler_jobs"
+}
@@ -0,0 +1,25 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// TokenRegistry 代币元数据登记表实体
+type TokenRegistry struct {
+	FtContractId       string     `db:"ft_contract_id" gorm:"column:ft_contract_id;primaryKey"`
+	LogoUrl            string     `db:"logo_url" gorm:"column:logo_url"`
+	Website            string     `db:"website" gorm:"column:website"`
+	Description        string     `db:"description" gorm:"column:description"`
+	Tags               string     `db:"tags" gorm:"column:tags"`       // 逗号分隔的标签
+	Socials            string     `db:"socials" gorm:"column:socials"` // JSON格式的社交媒体链接
+	Verified           bool       `db:"verified" gorm:"column:verified;index"`
+	VerificationMethod string     `db:"verification_method" gorm:"column:verification_method"` // registry为人工核验，creator为创建者签名核验
+	VerifiedAt         *time.Time `db:"verified_at" gorm:"column:verified_at"`
+	CreatedAt          time.Time  `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time  `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (TokenRegistry) TableName() string {
+	return "TBC20721.token_registry"
+}
@@ -0,0 +1,79 @@
+package dbtable
+
+import (
+	"time"
+)
+
+// TrackedWallet 跟踪钱包表实体
+type TrackedWallet struct {
+	WalletId           string     `db:"wallet_id" gorm:"column:wallet_id;primaryKey"`
+	Name               string     `db:"name" gorm:"column:name"`
+	Confirmed          int64      `db:"confirmed" gorm:"column:confirmed"`
+	Unconfirmed        int64      `db:"unconfirmed" gorm:"column:unconfirmed"`
+	TxCount            int        `db:"tx_count" gorm:"column:tx_count"`
+	LastActivityHeight int64      `db:"last_activity_height" gorm:"column:last_activity_height"`
+	LastActivityTime   int64      `db:"last_activity_time" gorm:"column:last_activity_time"`
+	SyncedAt           *time.Time `db:"synced_at" gorm:"column:synced_at;index"`             // 为空表示尚未同步
+	LastAttemptAt      *time.Time `db:"last_attempt_at" gorm:"column:last_attempt_at;index"` // 最近一次尝试同步的时间，为空表示从未尝试
+	SyncFailures       int        `db:"sync_failures" gorm:"column:sync_failures"`           // 连续同步失败的次数
+	NextAttemptAt      *time.Time `db:"next_attempt_at" gorm:"column:next_attempt_at"`       // 同步失败后下一次允许重试的时间
+	CreatedAt          time.Time  `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time  `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (TrackedWallet) TableName() string {
+	return "TBC20721.tracked_wallets"
+}
+
+// TrackedWalletAddress 跟踪钱包地址表实体
+type TrackedWalletAddress struct {
+	WalletId           string     `db:"wallet_id" gorm:"column:wallet_id;primaryKey"`
+	Address            string     `db:"address" gorm:"column:address;primaryKey"`
+	ScriptHash         string     `db:"script_hash" gorm:"column:script_hash"`
+	Source             string     `db:"source" gorm:"column:source"`                   // 来源扩展公钥或输出描述符
+	DerivationPath     string     `db:"derivation_path" gorm:"column:derivation_path"` // 相对扩展公钥的派生路径，描述符为展开序号
+	WatchStatus        string     `db:"watch_status" gorm:"column:watch_status;default:watching"`
+	ExpiresAt          *time.Time `db:"expires_at" gorm:"column:expires_at"`                 // 为空表示不过期
+	StopConfirmations  int        `db:"stop_confirmations" gorm:"column:stop_confirmations"` // 首笔充值达到该确认数后停止监听，0表示不因充值停止
+	RegisteredHeight   int64      `db:"registered_height" gorm:"column:registered_height"`   // 登记时的链顶高度，只有之后确认的交易视为充值
+	FirstDepositTxid   string     `db:"first_deposit_txid" gorm:"column:first_deposit_txid"`
+	FirstDepositHeight int64      `db:"first_deposit_height" gorm:"column:first_deposit_height"`
+	StoppedAt          *time.Time `db:"stopped_at" gorm:"column:stopped_at"`
+	CreatedAt          time.Time  `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 返回表名
+func (TrackedWalletAddress) TableName() string {
+	return "TBC20721.tracked_wallet_addresses"
+}
+
+// TrackedWalletTx 跟踪钱包交易表实体
+type TrackedWalletTx struct {
+	WalletId  string `db:"wallet_id" gorm:"column:wallet_id;primaryKey"`
+	TxHash    string `db:"tx_hash" gorm:"column:tx_hash;primaryKey"`
+	Height    int64  `db:"height" gorm:"column:height"`
+	Addresses string `db:"addresses" gorm:"column:addresses"` // 逗号分隔的钱包地址
+}
+
+// TableName 返回表名
+func (TrackedWalletTx) TableName() string {
+	return "TBC20721.tracked_wallet_txs"
+}
+
+// TrackedWalletNotification 跟踪钱包通知设置表实体
+type TrackedWalletNotification struct {
+	WalletId       string     `db:"wallet_id" gorm:"column:wallet_id;primaryKey"`
+	Channel        string     `db:"channel" gorm:"column:channel"`
+	Target         string     `db:"target" gorm:"column:target"`
+	Mode           string     `db:"mode" gorm:"column:mode"`
+	NotifiedHeight int64      `db:"notified_height" gorm:"column:notified_height"` // 已通知的最高区块高度
+	LastSentAt     *time.Time `db:"last_sent_at" gorm:"column:last_sent_at"`       // 为空表示尚未检查过
+	CreatedAt      time.Time  `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time  `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (TrackedWalletNotification) TableName() string {
+	return "TBC20721.tracked_wallet_notifications"
+}
@@ -15,9 +15,9 @@ const (
 // TransactionParticipant 交易参与方信息表实体
 type TransactionParticipant struct {
 	Fid       int64     `db:"Fid" gorm:"column:Fid;primaryKey"`
-	TxHash    string    `db:"tx_hash" gorm:"column:tx_hash;index"`
-	Address   string    `db:"address" gorm:"column:address;index"`
-	Role      Role      `db:"role" gorm:"column:role;type:enum('sender','recipient')"`
+	TxHash    string    `db:"tx_hash" gorm:"column:tx_hash;index;uniqueIndex:uk_tx_address_role"`
+	Address   string    `db:"address" gorm:"column:address;index;uniqueIndex:uk_tx_address_role"`
+	Role      Role      `db:"role" gorm:"column:role;type:enum('sender','recipient');uniqueIndex:uk_tx_address_role"`
 	CreatedAt time.Time `db:"created_at" gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 }
@@ -2,18 +2,20 @@ package dbtable
 
 import (
 	"time"
+
+	"ginproject/entity/transaction"
 )
 
 // Transaction 交易主表实体
 type Transaction struct {
-	Fid       int64     `db:"Fid" gorm:"column:Fid;primaryKey"`
-	TxHash    string    `db:"tx_hash" gorm:"column:tx_hash;uniqueIndex"`
-	Fee       float64   `db:"fee" gorm:"column:fee"`
-	TimeStamp int64     `db:"time_stamp" gorm:"column:time_stamp;index"`
-	UtcTime   string    `db:"transaction_utc_time" gorm:"column:transaction_utc_time"`
-	TxType    string    `db:"tx_type" gorm:"column:tx_type;index"`
-	CreatedAt time.Time `db:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	Fid       int64              `db:"Fid" gorm:"column:Fid;primaryKey"`
+	TxHash    string             `db:"tx_hash" gorm:"column:tx_hash;uniqueIndex"`
+	Fee       float64            `db:"fee" gorm:"column:fee"`
+	TimeStamp int64              `db:"time_stamp" gorm:"column:time_stamp;index"`
+	UtcTime   string             `db:"transaction_utc_time" gorm:"column:transaction_utc_time"`
+	TxType    transaction.TxType `db:"tx_type" gorm:"column:tx_type;index"`
+	CreatedAt time.Time          `db:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time          `db:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 }
 
 // TableName 返回表名
@@ -25,7 +27,13 @@ func (Transaction) TableName() string {
 type TransactionParam struct {
 	TxHash    string
 	TimeStamp int64
-	TxType    string
+	TxType    transaction.TxType
 	Offset    int
 	Limit     int
 }
+
+// TransactionActivity 按统计周期聚合的交易数
+type TransactionActivity struct {
+	Bucket int64 `gorm:"column:bucket"` // 周期起始时间
+	Txs    int64 `gorm:"column:txs"`
+}
@@ -0,0 +1,17 @@
+package electrumx
+
+// AddressSummaryResponse 地址概要，包含余额和地址首次出现、最近活动的区块
+// 活跃区间来自索引表，地址尚未被索引时Indexed为false，相关字段为零值
+type AddressSummaryResponse struct {
+	Address          string `json:"address"`
+	Balance          int64  `json:"balance"`     // 总余额（已确认+未确认）
+	Confirmed        int64  `json:"confirmed"`   // 已确认的余额
+	Unconfirmed      int64  `json:"unconfirmed"` // 未确认的余额
+	Indexed          bool   `json:"indexed"`     // 活跃区间是否已被索引
+	FirstSeenHeight  int64  `json:"first_seen_height,omitempty"`
+	FirstSeenTime    int64  `json:"first_seen_time,omitempty"`
+	FirstSeenTx      string `json:"first_seen_tx,omitempty"`
+	LastActiveHeight int64  `json:"last_active_height,omitempty"`
+	LastActiveTime   int64  `json:"last_active_time,omitempty"`
+	LastActiveTx     string `json:"last_active_tx,omitempty"`
+}
@@ -1,9 +1,15 @@
 package electrumx
 
+import (
+	"ginproject/entity/transaction"
+	"ginproject/entity/utility"
+)
+
 // ElectrumXHistoryItem 表示单个交易历史记录项
 type ElectrumXHistoryItem struct {
 	TxHash string `json:"tx_hash"`
-	Height int64    `json:"height"`
+	Height int64  `json:"height"`        // 内存池中的交易为0或-1（存在未确认的父交易）
+	Fee    int64  `json:"fee,omitempty"` // 交易手续费（聪），仅内存池中的交易返回
 }
 
 // ElectrumXHistoryResponse 表示从ElectrumX获取的历史记录响应
@@ -11,10 +17,12 @@ type ElectrumXHistoryResponse []ElectrumXHistoryItem
 
 // Utxo 表示未花费交易输出
 type Utxo struct {
-	TxHash string `json:"tx_hash"` // 交易哈希
-	TxPos  int    `json:"tx_pos"`  // 输出位置索引
-	Height int    `json:"height"`  // 包含该交易的区块高度
-	Value  int64  `json:"value"`   // UTXO金额（以聪为单位）
+	TxHash        string `json:"tx_hash"`                 // 交易哈希
+	TxPos         int    `json:"tx_pos"`                  // 输出位置索引
+	Height        int    `json:"height"`                  // 包含该交易的区块高度
+	Value         int64  `json:"value"`                   // UTXO金额（以聪为单位）
+	Confirmations int64  `json:"confirmations,omitempty"` // 按请求时的链顶计算的确认数，仅未花费输出接口返回，未确认时省略
+	BlockTime     int64  `json:"block_time,omitempty"`    // 所在区块的时间戳，仅未花费输出接口返回，未确认或查询失败时省略
 }
 
 // UtxoResponse 表示从ElectrumX获取的UTXO响应
@@ -22,22 +30,25 @@ type UtxoResponse []Utxo
 
 // AddressHistoryResponse 表示地址历史交易响应
 type AddressHistoryResponse struct {
-	Address      string        `json:"address"`       // 钱包地址
-	Script       string        `json:"script"`        // 地址对应的脚本哈希
-	HistoryCount int           `json:"history_count"` // 历史交易总数
-	Result       []HistoryItem `json:"result"`        // 历史交易列表
+	Address      string            `json:"address"`        // 钱包地址
+	Script       string            `json:"script"`         // 地址对应的脚本哈希
+	HistoryCount int               `json:"history_count"`  // 历史交易总数
+	Result       []HistoryItem     `json:"result"`         // 历史交易列表
+	Meta         *utility.PageMeta `json:"meta,omitempty"` // 分页元数据，仅分页模式返回
+	Truncated    bool              `json:"truncated,omitempty"`   // 截止时间前未能处理完本页，只返回了已完成的记录
+	NextCursor   string            `json:"next_cursor,omitempty"` // 截断时用于获取本页剩余记录的续查游标
 }
 
 // HistoryItem 表示单个历史交易记录
 type HistoryItem struct {
-	BalanceChange      string   `json:"balance_change"`       // 余额变动
-	TxHash             string   `json:"tx_hash"`              // 交易哈希
-	SenderAddresses    []string `json:"sender_addresses"`     // 发送方地址列表
-	RecipientAddresses []string `json:"recipient_addresses"`  // 接收方地址列表
-	Fee                string   `json:"fee"`                  // 交易费用
-	TimeStamp          int64    `json:"time_stamp,omitempty"` // 交易时间戳（可选）
-	UtcTime            string   `json:"utc_time"`             // UTC时间格式
-	TxType             string   `json:"tx_type,omitempty"`    // 交易类型（可选）
+	BalanceChange      string             `json:"balance_change"`       // 余额变动
+	TxHash             string             `json:"tx_hash"`              // 交易哈希
+	SenderAddresses    []string           `json:"sender_addresses"`     // 发送方地址列表
+	RecipientAddresses []string           `json:"recipient_addresses"`  // 接收方地址列表
+	Fee                string             `json:"fee"`                  // 交易费用
+	TimeStamp          int64              `json:"time_stamp,omitempty"` // 交易时间戳（可选）
+	UtcTime            string             `json:"utc_time"`             // UTC时间格式
+	TxType             transaction.TxType `json:"tx_type,omitempty"`    // 交易类型（可选）
 }
 
 // BalanceResponse 表示从ElectrumX获取的余额响应
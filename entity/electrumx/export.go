@@ -0,0 +1,53 @@
+package electrumx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// 历史导出文件格式
+const (
+	ExportFormatNDJSON = "ndjson" // 每行一个JSON对象
+	ExportFormatCSV    = "csv"
+)
+
+// ErrInvalidExportFormat 不支持的导出格式
+var ErrInvalidExportFormat = errors.New("导出格式只支持ndjson或csv")
+
+// ValidateExportFormat 校验导出格式，为空时使用ndjson
+func ValidateExportFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return ExportFormatNDJSON, nil
+	case ExportFormatNDJSON, ExportFormatCSV:
+		return format, nil
+	default:
+		return "", ErrInvalidExportFormat
+	}
+}
+
+// HistoryCSVHeader 导出CSV的表头，与HistoryItem.CSVRecord的字段顺序一致
+var HistoryCSVHeader = []string{"tx_hash", "time_stamp", "utc_time", "balance_change", "fee", "tx_type", "sender_addresses", "recipient_addresses"}
+
+// CSVRecord 将历史记录转换为CSV行，多个地址以分号分隔
+func (item HistoryItem) CSVRecord() []string {
+	return []string{
+		item.TxHash,
+		strconv.FormatInt(item.TimeStamp, 10),
+		item.UtcTime,
+		item.BalanceChange,
+		item.Fee,
+		item.TxType.String(),
+		strings.Join(item.SenderAddresses, ";"),
+		strings.Join(item.RecipientAddresses, ";"),
+	}
+}
+
+// HistoryExportResult 历史导出任务的结果
+type HistoryExportResult struct {
+	Address string `json:"address"`
+	Format  string `json:"format"`
+	Rows    int64  `json:"rows"`    // 写入的记录数
+	Skipped int64  `json:"skipped"` // 解析失败未写入的交易数
+}
@@ -0,0 +1,29 @@
+package electrumx
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 历史续查游标的前缀，便于以后调整游标格式
+const historyCursorPrefix = "h1:"
+
+// EncodeHistoryCursor 将从新到旧排列的历史记录中的偏移量编码为续查游标
+func EncodeHistoryCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(historyCursorPrefix + strconv.Itoa(offset)))
+}
+
+// ParseHistoryCursor 解析续查游标，返回历史记录偏移量
+func ParseHistoryCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), historyCursorPrefix) {
+		return 0, fmt.Errorf("无效的续查游标")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), historyCursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("无效的续查游标")
+	}
+	return offset, nil
+}
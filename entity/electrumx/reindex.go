@@ -0,0 +1,23 @@
+package electrumx
+
+// 脚本哈希重建索引的步骤
+const (
+	ReindexStepHistory = "history" // 重新获取交易历史，补写数据库中缺失的地址交易记录
+	ReindexStepFt      = "ft"      // 核对数据库中未花费的代币输出，标记链上已花费的输出
+	ReindexStepNft     = "nft"     // 核对数据库中该脚本哈希持有的NFT，列出链上已转出的NFT
+)
+
+// ScriptHashReindexResult 脚本哈希重建索引任务的结果
+type ScriptHashReindexResult struct {
+	ScriptHash string   `json:"script_hash"`
+	Address    string   `json:"address"`
+	Kind       string   `json:"kind"`                // 脚本类型，决定执行哪些步骤
+	Steps      []string `json:"steps"`               // 执行的步骤
+	HistoryTxs int64    `json:"history_txs"`         // 上游返回的历史交易数
+	Backfilled int64    `json:"backfilled"`          // 补写的地址交易记录数
+	Skipped    int64    `json:"skipped"`             // 解析失败未补写的交易数
+	FtChecked  int64    `json:"ft_checked"`          // 核对的未花费代币输出数
+	FtSpent    int64    `json:"ft_spent"`            // 标记为已花费的代币输出数
+	NftChecked int64    `json:"nft_checked"`         // 核对的NFT数
+	NftStale   []string `json:"nft_stale,omitempty"` // 数据库中仍记在该脚本哈希下、链上已转出的NFT合约ID
+}
@@ -0,0 +1,55 @@
+package electrumx
+
+import "ginproject/entity/utility"
+
+// SyncTx 增量同步中的新交易
+type SyncTx struct {
+	TxHash string `json:"tx_hash"`
+	Height int64  `json:"height"` // 未确认交易为0或-1
+}
+
+// SpentOutpoint 被新交易花费的本地址输出
+type SpentOutpoint struct {
+	TxHash  string `json:"tx_hash"`
+	TxPos   int    `json:"tx_pos"`
+	Value   int64  `json:"value"`    // 金额（聪）
+	SpentBy string `json:"spent_by"` // 花费该输出的交易哈希
+}
+
+// AddressSyncResponse 地址增量同步响应
+// 未确认交易每次同步都会重复返回，客户端应按交易哈希和输出位置去重
+type AddressSyncResponse struct {
+	Address        string                 `json:"address"`
+	SinceHeight    int64                  `json:"since_height"`
+	TipHeight      int64                  `json:"tip_height"`                // 下次同步时作为since_height传入
+	ResyncRequired bool                   `json:"resync_required,omitempty"` // 变化过多，客户端需要重新全量同步
+	NewTxs         []SyncTx               `json:"new_txs"`
+	SpentOutpoints []SpentOutpoint        `json:"spent_outpoints"`
+	NewUtxos       UtxoResponse           `json:"new_utxos"`
+	Balance        AddressBalanceResponse `json:"balance"`
+}
+
+// FilterNewUtxos 返回高度大于sinceHeight的UTXO，未确认的UTXO始终保留
+func FilterNewUtxos(utxos UtxoResponse, sinceHeight int64) UtxoResponse {
+	filtered := make(UtxoResponse, 0)
+	for _, utxo := range utxos {
+		if utxo.Height < 1 || int64(utxo.Height) > sinceHeight {
+			filtered = append(filtered, utxo)
+		}
+	}
+	return filtered
+}
+
+// FilterUtxosByHeight 返回高度不超过maxHeight的已确认UTXO，maxHeight小于0时原样返回
+func FilterUtxosByHeight(utxos UtxoResponse, maxHeight int64) UtxoResponse {
+	if maxHeight < 0 {
+		return utxos
+	}
+	filtered := make(UtxoResponse, 0, len(utxos))
+	for _, utxo := range utxos {
+		if utility.WithinHeightLimit(int64(utxo.Height), maxHeight) {
+			filtered = append(filtered, utxo)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,65 @@
+package electrumx
+
+import "testing"
+
+func TestFilterNewUtxos(t *testing.T) {
+	utxos := UtxoResponse{
+		{TxHash: "old", Height: 100},
+		{TxHash: "boundary", Height: 200},
+		{TxHash: "new", Height: 201},
+		{TxHash: "mempool", Height: 0},
+	}
+
+	got := FilterNewUtxos(utxos, 200)
+	if len(got) != 2 || got[0].TxHash != "new" || got[1].TxHash != "mempool" {
+		t.Fatalf("过滤结果不一致: %+v", got)
+	}
+	if got := FilterNewUtxos(utxos, 0); len(got) != len(utxos) {
+		t.Fatalf("since_height为0时应返回全部UTXO: %+v", got)
+	}
+}
+
+func TestNewUtxoAgeDistribution(t *testing.T) {
+	utxos := UtxoResponse{
+		{TxHash: "mempool", Height: 0, Value: 500},
+		{TxHash: "fresh", Height: 100000, Value: 2000},
+		{TxHash: "week", Height: 100000 - 7*blocksPerDay + 1, Value: 3000000},
+		{TxHash: "ancient", Height: 1, Value: 200000000},
+	}
+
+	got := NewUtxoAgeDistribution("addr", 100000, utxos)
+	if got.TotalCount != 4 || got.TotalValue != 203002500 {
+		t.Fatalf("合计不一致: %+v", got)
+	}
+	if len(got.AgeBuckets) != len(UtxoAgeBounds) {
+		t.Fatalf("应返回全部年龄区间: %d", len(got.AgeBuckets))
+	}
+
+	counts := map[string]int{}
+	for _, bucket := range got.AgeBuckets {
+		counts[bucket.Label] = bucket.Count
+	}
+	want := map[string]int{"unconfirmed": 1, "lt_1d": 1, "7d_30d": 1, "1d_7d": 0, "gte_1y": 1}
+	for label, count := range want {
+		if counts[label] != count {
+			t.Errorf("区间%s数量为%d，期望%d", label, counts[label], count)
+		}
+	}
+
+	fresh := got.AgeBuckets[1]
+	if fresh.ValueBuckets[1].Count != 1 || fresh.ValueBuckets[1].Value != 2000 {
+		t.Errorf("金额细分不一致: %+v", fresh.ValueBuckets)
+	}
+}
+
+func TestHistoryCursor(t *testing.T) {
+	offset, err := ParseHistoryCursor(EncodeHistoryCursor(27))
+	if err != nil || offset != 27 {
+		t.Fatalf("游标往返结果不一致: %d, %v", offset, err)
+	}
+	for _, bad := range []string{"", "abc", EncodeHistoryCursor(-1)} {
+		if _, err := ParseHistoryCursor(bad); err == nil {
+			t.Errorf("期望游标%q解析失败", bad)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package electrumx
+
+// 每日区块数，按10分钟出块估算
+const blocksPerDay = 144
+
+// UtxoBucketBound 分桶区间，Max为0表示不设上限
+type UtxoBucketBound struct {
+	Label string
+	Min   int64
+	Max   int64 // 不含
+}
+
+// contains 判断取值是否落在区间内
+func (b UtxoBucketBound) contains(v int64) bool {
+	return v >= b.Min && (b.Max == 0 || v < b.Max)
+}
+
+var (
+	// UtxoAgeBounds 按确认数划分的年龄区间，未确认的UTXO确认数为0
+	UtxoAgeBounds = []UtxoBucketBound{
+		{Label: "unconfirmed", Min: 0, Max: 1},
+		{Label: "lt_1d", Min: 1, Max: blocksPerDay},
+		{Label: "1d_7d", Min: blocksPerDay, Max: 7 * blocksPerDay},
+		{Label: "7d_30d", Min: 7 * blocksPerDay, Max: 30 * blocksPerDay},
+		{Label: "30d_180d", Min: 30 * blocksPerDay, Max: 180 * blocksPerDay},
+		{Label: "180d_1y", Min: 180 * blocksPerDay, Max: 365 * blocksPerDay},
+		{Label: "gte_1y", Min: 365 * blocksPerDay},
+	}
+
+	// UtxoValueBounds 按金额（聪）划分的区间，1 TBC = 1000000聪
+	UtxoValueBounds = []UtxoBucketBound{
+		{Label: "lt_0.001", Min: 0, Max: 1000},
+		{Label: "0.001_0.1", Min: 1000, Max: 100000},
+		{Label: "0.1_1", Min: 100000, Max: 1000000},
+		{Label: "1_100", Min: 1000000, Max: 100000000},
+		{Label: "gte_100", Min: 100000000},
+	}
+)
+
+// UtxoValueBucket 年龄区间内按金额细分的统计
+type UtxoValueBucket struct {
+	Label    string `json:"label"`
+	MinValue int64  `json:"min_value"`           // 区间下限（聪，含）
+	MaxValue int64  `json:"max_value,omitempty"` // 区间上限（聪，不含），最后一个区间不返回
+	Count    int    `json:"count"`
+	Value    int64  `json:"value"` // 区间内UTXO金额合计（聪）
+}
+
+// UtxoAgeBucket 按确认数划分的UTXO统计
+type UtxoAgeBucket struct {
+	Label            string            `json:"label"`
+	MinConfirmations int64             `json:"min_confirmations"`           // 区间下限（含）
+	MaxConfirmations int64             `json:"max_confirmations,omitempty"` // 区间上限（不含），最后一个区间不返回
+	Count            int               `json:"count"`
+	Value            int64             `json:"value"` // 区间内UTXO金额合计（聪）
+	ValueBuckets     []UtxoValueBucket `json:"value_buckets"`
+}
+
+// UtxoAgeDistributionResponse 地址UTXO的年龄和金额分布，供钱包规划低费率的合并时机
+type UtxoAgeDistributionResponse struct {
+	Address    string          `json:"address"`
+	TipHeight  int64           `json:"tip_height"` // 计算确认数时使用的链顶高度
+	TotalCount int             `json:"total_count"`
+	TotalValue int64           `json:"total_value"` // 全部UTXO金额合计（聪）
+	AgeBuckets []UtxoAgeBucket `json:"age_buckets"`
+}
+
+// NewUtxoAgeDistribution 按链顶高度计算确认数并将UTXO分桶，空桶同样返回，便于客户端按固定结构展示
+func NewUtxoAgeDistribution(address string, tipHeight int64, utxos UtxoResponse) *UtxoAgeDistributionResponse {
+	response := &UtxoAgeDistributionResponse{
+		Address:    address,
+		TipHeight:  tipHeight,
+		AgeBuckets: make([]UtxoAgeBucket, len(UtxoAgeBounds)),
+	}
+	for i, age := range UtxoAgeBounds {
+		bucket := UtxoAgeBucket{
+			Label:            age.Label,
+			MinConfirmations: age.Min,
+			MaxConfirmations: age.Max,
+			ValueBuckets:     make([]UtxoValueBucket, len(UtxoValueBounds)),
+		}
+		for j, value := range UtxoValueBounds {
+			bucket.ValueBuckets[j] = UtxoValueBucket{Label: value.Label, MinValue: value.Min, MaxValue: value.Max}
+		}
+		response.AgeBuckets[i] = bucket
+	}
+
+	for _, utxo := range utxos {
+		age := findBucket(UtxoAgeBounds, utxoConfirmations(utxo, tipHeight))
+		value := findBucket(UtxoValueBounds, utxo.Value)
+		if age < 0 || value < 0 {
+			continue
+		}
+
+		bucket := &response.AgeBuckets[age]
+		bucket.Count++
+		bucket.Value += utxo.Value
+		bucket.ValueBuckets[value].Count++
+		bucket.ValueBuckets[value].Value += utxo.Value
+		response.TotalCount++
+		response.TotalValue += utxo.Value
+	}
+	return response
+}
+
+// utxoConfirmations 计算UTXO的确认数，未确认或高度超过链顶时为0
+func utxoConfirmations(utxo Utxo, tipHeight int64) int64 {
+	if utxo.Height < 1 || int64(utxo.Height) > tipHeight {
+		return 0
+	}
+	return tipHeight - int64(utxo.Height) + 1
+}
+
+// findBucket 返回取值所在区间的下标，不在任何区间时返回-1
+func findBucket(bounds []UtxoBucketBound, v int64) int {
+	for i, b := range bounds {
+		if b.contains(v) {
+			return i
+		}
+	}
+	return -1
+}
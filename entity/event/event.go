@@ -0,0 +1,74 @@
+package event
+
+import (
+	"ginproject/entity/block"
+	"ginproject/entity/transaction"
+)
+
+// 事件总线主题
+const (
+	TopicBlock         = "block"          // 新区块
+	TopicTx            = "tx"             // 区块内的交易
+	TopicTokenTransfer = "token_transfer" // FT和流动池交易
+	TopicNFTTransfer   = "nft_transfer"   // NFT交易
+)
+
+// BlockEvent 新区块事件，在该区块的交易事件之后发布
+type BlockEvent struct {
+	Height  int64  `json:"height"`
+	Hash    string `json:"hash"`
+	Time    int64  `json:"time"`
+	TxCount int    `json:"tx_count"`
+}
+
+// TxEvent 交易事件
+type TxEvent struct {
+	Height    int64              `json:"height"`
+	BlockTime int64              `json:"block_time"`
+	Txid      string             `json:"txid"`
+	Index     int                `json:"index"` // 交易在区块中的位置
+	TxType    transaction.TxType `json:"tx_type"`
+	Size      int                `json:"size"`
+	VinCount  int                `json:"vin_count"`
+	VoutCount int                `json:"vout_count"`
+	TotalOut  float64            `json:"total_out"`
+	Addresses []string           `json:"addresses,omitempty"` // 输出中出现的地址
+}
+
+// NewBlockEvent 根据区块生成区块事件
+func NewBlockEvent(b *block.BlockWithTxs) BlockEvent {
+	return BlockEvent{Height: b.Height, Hash: b.Hash, Time: b.Time, TxCount: len(b.Tx)}
+}
+
+// NewTxEvents 根据区块内的交易生成交易事件
+func NewTxEvents(b *block.BlockWithTxs) []TxEvent {
+	events := make([]TxEvent, 0, len(b.Tx))
+	for i := range b.Tx {
+		summary := block.SummarizeTx(b.Height, i, &b.Tx[i])
+		events = append(events, TxEvent{
+			Height:    b.Height,
+			BlockTime: b.Time,
+			Txid:      summary.Txid,
+			Index:     i,
+			TxType:    summary.TxType,
+			Size:      summary.Size,
+			VinCount:  summary.VinCount,
+			VoutCount: summary.VoutCount,
+			TotalOut:  summary.TotalOut,
+			Addresses: summary.Addresses,
+		})
+	}
+	return events
+}
+
+// TransferTopic 返回交易对应的转账主题，非代币交易返回false
+func TransferTopic(txType transaction.TxType) (string, bool) {
+	switch txType {
+	case transaction.TxTypeTBC20, transaction.TxTypePool:
+		return TopicTokenTransfer, true
+	case transaction.TxTypeTBC721:
+		return TopicNFTTransfer, true
+	default:
+		return "", false
+	}
+}
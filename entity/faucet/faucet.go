@@ -0,0 +1,28 @@
+package faucet
+
+import (
+	"fmt"
+
+	"ginproject/entity/utility"
+)
+
+// FaucetRequest 水龙头领取请求
+type FaucetRequest struct {
+	Address string `uri:"address" binding:"required"`
+}
+
+// Validate 验证请求参数
+func (r *FaucetRequest) Validate() error {
+	if _, err := utility.ConvertAddressToPublicKeyHash(r.Address); err != nil {
+		return fmt.Errorf("地址格式无效: %w", err)
+	}
+	return nil
+}
+
+// FaucetResponse 水龙头领取响应
+type FaucetResponse struct {
+	TxId          string  `json:"txid"`
+	Address       string  `json:"address"`
+	Amount        float64 `json:"amount"`
+	NextRequestAt int64   `json:"next_request_at"` // 该地址下次可以领取的时间(Unix秒)
+}
@@ -33,6 +33,8 @@ type FtBalanceAddressResponse struct {
 	FtDecimal int `json:"ftDecimal"`
 	// FT余额
 	FtBalance uint64 `json:"ftBalance"`
+	// 代币单位信息
+	TokenInfo *TokenUnits `json:"token_info,omitempty"`
 }
 
 // Validate 验证FtBalanceAddressRequest的参数
@@ -114,6 +116,8 @@ type TBC20FTBalanceResponse struct {
 	FtDecimal int `json:"ftDecimal"`
 	// FT余额
 	FtBalance uint64 `json:"ftBalance"`
+	// 代币单位信息
+	TokenInfo *TokenUnits `json:"token_info,omitempty"`
 }
 
 // TBC20TokenListHeldByCombineScriptRequest 通过合并脚本获取代币列表请求
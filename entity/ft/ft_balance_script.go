@@ -22,6 +22,8 @@ type FtBalanceCombineScriptResponse struct {
 	FtDecimal int `json:"ftDecimal"`
 	// 余额
 	FtBalance uint64 `json:"ftBalance"`
+	// 代币单位信息
+	TokenInfo *TokenUnits `json:"token_info,omitempty"`
 }
 
 // Validate 验证FtBalanceCombineScriptRequest的参数
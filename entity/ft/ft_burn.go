@@ -0,0 +1,54 @@
+package ft
+
+import (
+	"fmt"
+
+	"ginproject/entity/utility"
+)
+
+// BurnAddress 销毁地址，转入该地址的代币无法再被花费
+const BurnAddress = "1BitcoinEaterAddressDontSendf59kuE"
+
+// 未指定时的每页记录数
+const defaultBurnPageSize = 20
+
+// FtBurnsRequest 获取代币销毁记录的请求参数
+type FtBurnsRequest struct {
+	ContractId string `uri:"contract_id" binding:"required"` // 代币合约ID
+	Page       int    `form:"page"`                          // 页码，从0开始
+	Size       int    `form:"size"`                          // 每页记录数（可选，默认20）
+}
+
+// Validate 验证请求参数的合法性，未指定每页记录数时使用默认值
+func (req *FtBurnsRequest) Validate() error {
+	if len(req.ContractId) != 64 {
+		return fmt.Errorf("合约ID格式不正确，应为64位十六进制字符串")
+	}
+	if req.Page < 0 {
+		return fmt.Errorf("页码不能小于0")
+	}
+	if req.Size == 0 {
+		req.Size = defaultBurnPageSize
+	}
+	return utility.ValidatePageSize(utility.PageEndpointFtBurns, req.Size)
+}
+
+// FtBurnItem 单次销毁记录
+type FtBurnItem struct {
+	Txid      string `json:"txid"`
+	Vout      int    `json:"vout"`
+	Amount    uint64 `json:"amount"`     // 销毁数量，未按精度换算
+	Height    int64  `json:"height"`     // 历史回填且未知时为0
+	BlockTime int64  `json:"block_time"` // 历史回填时为0
+}
+
+// FtBurnsResponse 代币销毁记录
+type FtBurnsResponse struct {
+	FtContractId string            `json:"ft_contract_id"`
+	FtDecimal    int               `json:"ft_decimal"`
+	BurnCount    int64             `json:"burn_count"`    // 销毁次数
+	TotalBurned  uint64            `json:"total_burned"`  // 累计销毁数量，未按精度换算
+	BurnedSupply string            `json:"burned_supply"` // 按精度换算后的累计销毁数量
+	Result       []FtBurnItem      `json:"result"`
+	Meta         *utility.PageMeta `json:"meta,omitempty"`
+}
@@ -0,0 +1,146 @@
+package ft
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// CandleIntervals 支持的K线周期及其秒数
+var CandleIntervals = map[string]int64{
+	"1m": 60,
+	"1h": 3600,
+	"1d": 86400,
+}
+
+const (
+	// 未指定周期时的默认周期
+	DefaultCandleInterval = "1h"
+	// 未指定起始时间时返回的K线数
+	DefaultCandleCount = 200
+	// 单次查询覆盖的最大K线数
+	MaxCandleCount = 1000
+)
+
+// FtCandlesRequest 获取代币价格K线的请求参数
+type FtCandlesRequest struct {
+	ContractId string `uri:"contract_id" binding:"required"` // 代币合约ID
+	Interval   string `form:"interval"`                      // K线周期（可选，默认1h）
+	From       int64  `form:"from"`                          // 起始Unix时间戳（可选，默认为结束时间前200个周期）
+	To         int64  `form:"to"`                            // 结束Unix时间戳，不包含（可选，默认当前时间）
+}
+
+// Validate 验证请求参数的合法性，补全默认的周期和时间范围
+func (req *FtCandlesRequest) Validate() error {
+	if len(req.ContractId) != 64 {
+		return fmt.Errorf("合约ID格式不正确，应为64位十六进制字符串")
+	}
+	if req.Interval == "" {
+		req.Interval = DefaultCandleInterval
+	}
+	seconds, ok := CandleIntervals[req.Interval]
+	if !ok {
+		return fmt.Errorf("不支持的K线周期: %s，可选1m、1h、1d", req.Interval)
+	}
+	if req.From < 0 || req.To < 0 {
+		return fmt.Errorf("时间戳不能为负数")
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+	if req.From == 0 {
+		req.From = max(req.To-DefaultCandleCount*seconds, 0)
+	}
+	if req.From >= req.To {
+		return fmt.Errorf("起始时间必须早于结束时间")
+	}
+	if (req.To-req.From+seconds-1)/seconds > MaxCandleCount {
+		return fmt.Errorf("时间范围过大，单次最多查询%d个周期", MaxCandleCount)
+	}
+	return nil
+}
+
+// FtCandleItem 单个周期的K线，价格为每个代币的TBC数量
+type FtCandleItem struct {
+	Time      int64   `json:"time"` // 周期起始的Unix时间戳
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	VolumeTbc uint64  `json:"volume_tbc"` // TBC成交量(聪)
+	VolumeFt  uint64  `json:"volume_ft"`  // 代币成交量，未按精度换算
+	Trades    int     `json:"trades"`
+}
+
+// FtCandlesResponse 代币价格K线，没有成交的周期不返回
+type FtCandlesResponse struct {
+	FtContractId string         `json:"ft_contract_id"`
+	FtDecimal    int            `json:"ft_decimal"`
+	Interval     string         `json:"interval"`
+	From         int64          `json:"from"`
+	To           int64          `json:"to"`
+	Result       []FtCandleItem `json:"result"`
+}
+
+// PoolSwap 池NFT交易前后储备变化得到的一次兑换
+type PoolSwap struct {
+	TbcChange int64   // 池中TBC的变化量(聪)，正数表示买入代币
+	FtChange  int64   // 池中代币的变化量，未按精度换算
+	Price     float64 // 成交价格，每个代币的TBC数量
+}
+
+// DetectPoolSwap 比较池NFT交易前后的LP数量、代币和TBC储备，LP数量不变且代币和TBC反向变化时为一次兑换
+// 添加、移除流动性和其它池操作返回false
+func DetectPoolSwap(prevLp, prevFt, prevTbc, lp, ftReserve, tbcReserve int64, decimal uint8) (PoolSwap, bool) {
+	swap := PoolSwap{TbcChange: tbcReserve - prevTbc, FtChange: ftReserve - prevFt}
+	if lp != prevLp || swap.TbcChange == 0 || swap.FtChange == 0 || (swap.TbcChange > 0) == (swap.FtChange > 0) {
+		return PoolSwap{}, false
+	}
+	tbc := math.Abs(float64(swap.TbcChange)) / 1e6
+	amount := math.Abs(float64(swap.FtChange)) / math.Pow10(int(decimal))
+	swap.Price = tbc / amount
+	return swap, true
+}
+
+// CandleBucket 返回时间戳所在周期的起始时间
+func CandleBucket(blockTime, seconds int64) int64 {
+	return blockTime - blockTime%seconds
+}
+
+// CandleTrade 参与K线聚合的一笔成交
+type CandleTrade struct {
+	BlockTime int64
+	Price     float64
+	VolumeTbc uint64
+	VolumeFt  uint64
+}
+
+// BuildCandles 将按成交顺序排列的成交聚合为K线，返回的K线按周期起始时间升序
+func BuildCandles(seconds int64, trades []CandleTrade) []FtCandleItem {
+	var candles []FtCandleItem
+	index := make(map[int64]int)
+	for _, trade := range trades {
+		bucket := CandleBucket(trade.BlockTime, seconds)
+		i, ok := index[bucket]
+		if !ok {
+			index[bucket] = len(candles)
+			candles = append(candles, FtCandleItem{
+				Time: bucket, Open: trade.Price, High: trade.Price, Low: trade.Price,
+			})
+			i = len(candles) - 1
+		}
+		candle := &candles[i]
+		candle.High = math.Max(candle.High, trade.Price)
+		candle.Low = math.Min(candle.Low, trade.Price)
+		candle.Close = trade.Price
+		candle.VolumeTbc += trade.VolumeTbc
+		candle.VolumeFt += trade.VolumeFt
+		candle.Trades++
+	}
+	slices.SortFunc(candles, func(a, b FtCandleItem) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+	return candles
+}
@@ -0,0 +1,82 @@
+package ft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFtCandlesRequestValidate(t *testing.T) {
+	contractId := strings.Repeat("a", 64)
+
+	req := FtCandlesRequest{ContractId: contractId, To: 100000}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if req.Interval != DefaultCandleInterval || req.From != 0 {
+		t.Errorf("未指定周期和起始时间时应使用默认值: %+v", req)
+	}
+
+	req = FtCandlesRequest{ContractId: contractId, Interval: "1m", To: 1_000_000}
+	if err := req.Validate(); err != nil || req.From != 1_000_000-DefaultCandleCount*60 {
+		t.Errorf("默认起始时间应为结束时间前%d个周期: from=%d err=%v", DefaultCandleCount, req.From, err)
+	}
+
+	for _, bad := range []FtCandlesRequest{
+		{ContractId: "abc"},
+		{ContractId: contractId, Interval: "5m"},
+		{ContractId: contractId, From: 200, To: 100},
+		{ContractId: contractId, Interval: "1m", From: 1, To: 1 + (MaxCandleCount+1)*60},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v 应返回错误", bad)
+		}
+	}
+}
+
+func TestDetectPoolSwap(t *testing.T) {
+	// 用2TBC买入100个精度为6的代币
+	swap, ok := DetectPoolSwap(1000, 500_000000, 10_000000, 1000, 400_000000, 12_000000, 6)
+	if !ok || swap.TbcChange != 2_000000 || swap.FtChange != -100_000000 || swap.Price != 0.02 {
+		t.Errorf("买入应识别为兑换: %+v ok=%v", swap, ok)
+	}
+
+	// 卖出时价格同样为正
+	swap, ok = DetectPoolSwap(1000, 400_000000, 12_000000, 1000, 500_000000, 10_000000, 6)
+	if !ok || swap.TbcChange >= 0 || swap.Price != 0.02 {
+		t.Errorf("卖出应识别为兑换: %+v ok=%v", swap, ok)
+	}
+
+	// 添加流动性时LP数量变化，储备同向增加
+	if _, ok := DetectPoolSwap(1000, 500, 100, 1100, 550, 110, 0); ok {
+		t.Error("添加流动性不应识别为兑换")
+	}
+	if _, ok := DetectPoolSwap(1000, 500, 100, 1000, 550, 110, 0); ok {
+		t.Error("储备同向变化不应识别为兑换")
+	}
+}
+
+func TestBuildCandles(t *testing.T) {
+	trades := []CandleTrade{
+		{BlockTime: 3600 + 10, Price: 2, VolumeTbc: 10, VolumeFt: 5},
+		{BlockTime: 3600 + 20, Price: 5, VolumeTbc: 20, VolumeFt: 4},
+		{BlockTime: 3600 + 30, Price: 1, VolumeTbc: 30, VolumeFt: 30},
+		{BlockTime: 3600 + 40, Price: 3, VolumeTbc: 40, VolumeFt: 13},
+		{BlockTime: 10, Price: 7, VolumeTbc: 7, VolumeFt: 1},
+	}
+
+	candles := BuildCandles(3600, trades)
+	if len(candles) != 2 {
+		t.Fatalf("K线数 = %d, want 2", len(candles))
+	}
+	if candles[0].Time != 0 || candles[0].Open != 7 || candles[0].Trades != 1 {
+		t.Errorf("K线应按时间升序: %+v", candles[0])
+	}
+	want := FtCandleItem{Time: 3600, Open: 2, High: 5, Low: 1, Close: 3, VolumeTbc: 100, VolumeFt: 52, Trades: 4}
+	if candles[1] != want {
+		t.Errorf("candles[1] = %+v, want %+v", candles[1], want)
+	}
+
+	if candles := BuildCandles(86400, trades); len(candles) != 1 || candles[0].Open != 2 || candles[0].Close != 7 {
+		t.Errorf("同一周期内应按成交顺序取开盘和收盘价: %+v", candles)
+	}
+}
@@ -1,11 +1,14 @@
 package ft
 
+import "ginproject/entity/utility"
+
 // FtHistoryRequest 获取FT交易历史的请求参数
 type FtHistoryRequest struct {
 	Address    string `uri:"address" binding:"required"`     // 用户地址
 	ContractId string `uri:"contract_id" binding:"required"` // 代币合约ID
 	Page       int    `uri:"page"`                           // 页码（从0开始）
 	Size       int    `uri:"size"`                           // 每页记录数
+	FromHeight int64  `form:"from_height"`                   // 起始区块高度（可选，用于增量同步）
 }
 
 // 验证请求参数是否合法
@@ -19,8 +22,11 @@ func (r *FtHistoryRequest) Validate() error {
 	if r.Page < 0 {
 		return NewValidationError("页码必须大于或等于0")
 	}
-	if r.Size < 1 || r.Size > 10000 {
-		return NewValidationError("每页记录数必须在1到10000之间")
+	if err := utility.ValidatePageSize(utility.PageEndpointFtHistory, r.Size); err != nil {
+		return err
+	}
+	if r.FromHeight < 0 {
+		return NewValidationError("起始高度必须大于或等于0")
 	}
 	return nil
 }
@@ -40,10 +46,11 @@ type FtHistoryRecord struct {
 
 // FtHistoryResponse FT交易历史响应
 type FtHistoryResponse struct {
-	Address      string            `json:"address"`       // 查询的地址
-	ScriptHash   string            `json:"script_hash"`   // 生成的脚本哈希
-	HistoryCount int               `json:"history_count"` // 历史记录总数
-	Result       []FtHistoryRecord `json:"result"`        // 历史记录列表
+	Address      string            `json:"address"`        // 查询的地址
+	ScriptHash   string            `json:"script_hash"`    // 生成的脚本哈希
+	HistoryCount int               `json:"history_count"`  // 历史记录总数
+	Result       []FtHistoryRecord `json:"result"`         // 历史记录列表
+	Meta         *utility.PageMeta `json:"meta,omitempty"` // 分页元数据
 }
 
 // ValidationError 参数验证错误
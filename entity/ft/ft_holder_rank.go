@@ -3,6 +3,8 @@ package ft
 import (
 	"fmt"
 	"strconv"
+
+	"ginproject/entity/utility"
 )
 
 // FtHolderRankRequest 获取代币持有者排名的请求参数
@@ -28,8 +30,8 @@ func (req *FtHolderRankRequest) Validate() error {
 	}
 
 	// 检查每页记录数是否合法
-	if req.Size <= 0 || req.Size > 10000 {
-		return fmt.Errorf("每页记录数必须在1-10000之间")
+	if err := utility.ValidatePageSize(utility.PageEndpointFtHolderRank, req.Size); err != nil {
+		return err
 	}
 
 	return nil
@@ -53,18 +55,56 @@ type FtHolderRankResponse struct {
 	FtDecimal int `json:"ft_decimal"`
 	// 代币持有者总数
 	FtHoldersCount int `json:"ft_holders_count"`
+	// 流通供应量，即全部持有者的余额之和
+	CirculatingSupply uint64 `json:"circulating_supply"`
+	// 计算名次变化使用的快照时间戳，没有快照时省略
+	SnapshotAt int64 `json:"snapshot_at,omitempty"`
 	// 持有者排名列表
 	HolderRank []HolderRankInfo `json:"holder_rank"`
+	// 分页元数据
+	Meta *utility.PageMeta `json:"meta,omitempty"`
 }
 
 // HolderRankInfo 持有者排名信息
 type HolderRankInfo struct {
-	// 持有者地址
+	// 持有者地址，池控制或多签脚本为带前缀的组合脚本
 	Address string `json:"address"`
+	// 持有者组合脚本
+	CombineScript string `json:"combine_script"`
 	// 持有代币余额
 	Balance uint64 `json:"balance"`
 	// 排名
 	Rank int `json:"rank"`
-	// 持有比例，取值0-1之间，保留4位小数
+	// 持有比例，占总供应量，取值0-1之间，保留4位小数
 	HoldRatio float64 `json:"hold_ratio"`
+	// 占流通供应量的比例，取值0-1之间，保留4位小数
+	CirculatingRatio float64 `json:"circulating_ratio"`
+	// 快照时的名次，没有快照或快照时不在前N名时省略
+	PreviousRank *int `json:"previous_rank,omitempty"`
+	// 名次变化，正数表示上升，没有快照或快照时不在前N名时省略
+	RankChange *int `json:"rank_change,omitempty"`
+	// 快照时不在前N名，本次新上榜
+	NewEntry bool `json:"new_entry,omitempty"`
+}
+
+// SetShares 按总供应量和流通供应量计算持有比例，供应量为0时比例为0
+func (info *HolderRankInfo) SetShares(totalSupply, circulatingSupply uint64) {
+	info.HoldRatio, info.CirculatingRatio = 0, 0
+	if totalSupply > 0 {
+		info.HoldRatio = roundRatio(float64(info.Balance) / float64(totalSupply))
+	}
+	if circulatingSupply > 0 {
+		info.CirculatingRatio = roundRatio(float64(info.Balance) / float64(circulatingSupply))
+	}
+}
+
+// CompareSnapshot 按快照时的名次计算名次变化，previousRank为0表示快照时不在前N名
+func (info *HolderRankInfo) CompareSnapshot(previousRank int) {
+	if previousRank <= 0 {
+		info.NewEntry = true
+		return
+	}
+	change := previousRank - info.Rank
+	info.PreviousRank = &previousRank
+	info.RankChange = &change
 }
@@ -0,0 +1,36 @@
+package ft
+
+import "testing"
+
+func TestHolderRankInfoSetShares(t *testing.T) {
+	info := HolderRankInfo{Balance: 1000}
+	info.SetShares(30000, 3000)
+	if info.HoldRatio != 0.0333 || info.CirculatingRatio != 0.3333 {
+		t.Fatalf("持有比例不正确: %+v", info)
+	}
+
+	info.SetShares(0, 0)
+	if info.HoldRatio != 0 || info.CirculatingRatio != 0 {
+		t.Fatalf("供应量为0时比例应为0: %+v", info)
+	}
+}
+
+func TestHolderRankInfoCompareSnapshot(t *testing.T) {
+	up := HolderRankInfo{Rank: 3}
+	up.CompareSnapshot(5)
+	if up.PreviousRank == nil || *up.PreviousRank != 5 || up.RankChange == nil || *up.RankChange != 2 || up.NewEntry {
+		t.Fatalf("名次上升计算不正确: %+v", up)
+	}
+
+	down := HolderRankInfo{Rank: 4}
+	down.CompareSnapshot(1)
+	if down.RankChange == nil || *down.RankChange != -3 {
+		t.Fatalf("名次下降计算不正确: %+v", down)
+	}
+
+	entry := HolderRankInfo{Rank: 7}
+	entry.CompareSnapshot(0)
+	if !entry.NewEntry || entry.PreviousRank != nil || entry.RankChange != nil {
+		t.Fatalf("新上榜持有者不应有名次变化: %+v", entry)
+	}
+}
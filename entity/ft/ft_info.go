@@ -27,18 +27,21 @@ func (req *FtInfoContractIdRequest) Validate() error {
 
 // TBC20FTInfoResponse FT信息响应
 type TBC20FTInfoResponse struct {
-	FtContractId           string  `json:"ftContractId"`           // FT合约ID
-	FtCodeScript           string  `json:"ftCodeScript"`           // FT代码脚本
-	FtTapeScript           string  `json:"ftTapeScript"`           // FT磁带脚本
-	FtSupply               float64 `json:"ftSupply"`               // FT总供应量（已考虑小数位）
-	FtDecimal              int     `json:"ftDecimal"`              // FT小数位数
-	FtName                 string  `json:"ftName"`                 // FT名称
-	FtSymbol               string  `json:"ftSymbol"`               // FT符号
-	FtDescription          string  `json:"ftDescription"`          // FT描述
-	FtOriginUtxo           string  `json:"ftOriginUtxo"`           // FT起源UTXO
-	FtCreatorCombineScript string  `json:"ftCreatorCombineScript"` // FT创建者的组合脚本
-	FtHoldersCount         int     `json:"ftHoldersCount"`         // FT持有者数量
-	FtIconUrl              string  `json:"ftIconUrl"`              // FT图标URL
-	FtCreateTimestamp      int     `json:"ftCreateTimestamp"`      // FT创建时间戳
-	FtTokenPrice           string `json:"ftTokenPrice"`           // FT代币价格
+	FtContractId           string         `json:"ftContractId"`                   // FT合约ID
+	FtCodeScript           string         `json:"ftCodeScript"`                   // FT代码脚本
+	FtTapeScript           string         `json:"ftTapeScript"`                   // FT磁带脚本
+	FtSupply               float64        `json:"ftSupply"`                       // FT总供应量（已考虑小数位）
+	FtDecimal              int            `json:"ftDecimal"`                      // FT小数位数
+	FtName                 string         `json:"ftName"`                         // FT名称
+	FtSymbol               string         `json:"ftSymbol"`                       // FT符号
+	FtDescription          string         `json:"ftDescription"`                  // FT描述
+	FtOriginUtxo           string         `json:"ftOriginUtxo"`                   // FT起源UTXO
+	FtCreatorCombineScript string         `json:"ftCreatorCombineScript"`         // FT创建者的组合脚本
+	FtHoldersCount         int            `json:"ftHoldersCount"`                 // FT持有者数量
+	FtIconUrl              string         `json:"ftIconUrl"`                      // FT图标URL
+	FtCreateTimestamp      int            `json:"ftCreateTimestamp"`              // FT创建时间戳
+	FtTokenPrice           string         `json:"ftTokenPrice"`                   // FT代币价格
+	FtVerified             bool           `json:"ftVerified"`                     // 是否经过人工核验或创建者签名核验
+	FtVerificationMethod   string         `json:"ftVerificationMethod,omitempty"` // 核验方式：registry或creator
+	FtMetadata             *TokenMetadata `json:"ftMetadata,omitempty"`           // 人工维护的代币元数据
 }
@@ -2,11 +2,16 @@ package ft
 
 import (
 	"fmt"
+
+	"ginproject/entity/utility"
 )
 
 // LPUnspentByScriptHashRequest 获取LP未花费交易输出请求
 type LPUnspentByScriptHashRequest struct {
 	ScriptHash string `uri:"script_hash" binding:"required"`
+	MinBalance uint64 `form:"min_balance"` // 最小LP余额（可选），只返回ftBalance不小于该值的输出
+	Page       int    `form:"page"`        // 页码（从0开始）
+	Size       int    `form:"size"`        // 每页记录数（可选），为0时返回全部
 }
 
 // Validate 验证请求参数的合法性
@@ -21,6 +26,18 @@ func (req *LPUnspentByScriptHashRequest) Validate() error {
 		return fmt.Errorf("脚本哈希格式不正确，应为64位十六进制字符串")
 	}
 
+	if req.Page < 0 {
+		return fmt.Errorf("页码必须大于或等于0")
+	}
+	if req.Size < 0 {
+		return utility.ErrInvalidPageSize
+	}
+	if req.Size > 0 {
+		if err := utility.ValidatePageSize(utility.PageEndpointFtLPUnspent, req.Size); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -39,10 +56,41 @@ func (req *LPUnspentByScriptHashRequest) Validate() error {
 }
 */
 
+// 批量查询LP未花费交易输出的最大脚本哈希数量
+const MaxLPUnspentScriptHashes = 20
+
+// LPUnspentByScriptHashesRequest 批量获取LP未花费交易输出请求
+type LPUnspentByScriptHashesRequest struct {
+	ScriptHashes []string `json:"scriptHashes" binding:"required"`
+}
+
+// Validate 验证请求参数的合法性
+func (req *LPUnspentByScriptHashesRequest) Validate() error {
+	if len(req.ScriptHashes) == 0 {
+		return fmt.Errorf("脚本哈希列表不能为空")
+	}
+
+	if len(req.ScriptHashes) > MaxLPUnspentScriptHashes {
+		return fmt.Errorf("脚本哈希数量不能超过%d", MaxLPUnspentScriptHashes)
+	}
+
+	for _, scriptHash := range req.ScriptHashes {
+		if len(scriptHash) != 64 {
+			return fmt.Errorf("脚本哈希格式不正确: %s", scriptHash)
+		}
+	}
+
+	return nil
+}
+
 // TBC20FTLPUnspentResponse 获取LP未花费交易输出响应
 type TBC20FTLPUnspentResponse struct {
 	// 数据
 	FtUtxoList []*TBC20FTLPUnspentItem `json:"ftUtxoList"`
+	// 符合过滤条件的总数
+	Total int `json:"total"`
+	// 分页元数据，未分页时为空
+	Meta *utility.PageMeta `json:"meta,omitempty"`
 }
 
 // TBC20FTLPUnspentItem LP未花费交易输出信息
@@ -60,3 +108,19 @@ type TBC20FTLPUnspentItem struct {
 	// FT余额
 	FtBalance int64 `json:"ftBalance"`
 }
+
+// TBC20FTLPUnspentMultiResponse 批量获取LP未花费交易输出响应
+type TBC20FTLPUnspentMultiResponse struct {
+	// 各脚本哈希的查询结果
+	Results []*TBC20FTLPUnspentScriptResult `json:"results"`
+}
+
+// TBC20FTLPUnspentScriptResult 单个脚本哈希的LP未花费交易输出
+type TBC20FTLPUnspentScriptResult struct {
+	// 脚本哈希
+	ScriptHash string `json:"scriptHash"`
+	// 数据
+	FtUtxoList []*TBC20FTLPUnspentItem `json:"ftUtxoList"`
+	// 查询失败时的错误信息
+	Error string `json:"error,omitempty"`
+}
@@ -2,6 +2,8 @@ package ft
 
 import (
 	"fmt"
+
+	"ginproject/entity/utility"
 )
 
 // TBC20PoolHistoryRequest 获取池子历史记录请求
@@ -24,8 +26,8 @@ func (req *TBC20PoolHistoryRequest) Validate() error {
 	}
 
 	// 检查每页大小是否合法
-	if req.Size <= 0 || req.Size > 10000 {
-		return fmt.Errorf("每页大小必须在1-10000之间")
+	if err := utility.ValidatePageSize(utility.PageEndpointFtPoolHistory, req.Size); err != nil {
+		return err
 	}
 
 	return nil
@@ -2,6 +2,8 @@ package ft
 
 import (
 	"fmt"
+
+	"ginproject/entity/utility"
 )
 
 // TBC20PoolListRequest 获取代币相关的流动池列表请求
@@ -54,8 +56,8 @@ func (req *TBC20PoolPageRequest) Validate() error {
 	}
 
 	// 检查每页大小是否合法
-	if req.Size <= 0 || req.Size > 10000 {
-		return fmt.Errorf("每页大小必须在1到10000之间")
+	if err := utility.ValidatePageSize(utility.PageEndpointFtPoolList, req.Size); err != nil {
+		return err
 	}
 
 	return nil
@@ -63,6 +65,7 @@ func (req *TBC20PoolPageRequest) Validate() error {
 
 // TBC20PoolPageResponse 分页获取所有流动池列表响应
 type TBC20PoolPageResponse struct {
-	TotalPoolCount int64           `json:"total_pool_count"` // 池总数
-	PoolList       []TBC20PoolInfo `json:"pool_list"`        // 流动池列表
+	TotalPoolCount int64             `json:"total_pool_count"` // 池总数
+	PoolList       []TBC20PoolInfo   `json:"pool_list"`        // 流动池列表
+	Meta           *utility.PageMeta `json:"meta,omitempty"`   // 分页元数据
 }
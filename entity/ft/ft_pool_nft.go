@@ -45,3 +45,29 @@ type TBC20PoolNFTInfoResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// TBC20PoolReservesRequest 获取池子当前储备请求
+type TBC20PoolReservesRequest struct {
+	PoolId string `uri:"pool_id" binding:"required"` // 池子ID，即池NFT合约ID
+}
+
+// Validate 验证请求参数的合法性
+func (req *TBC20PoolReservesRequest) Validate() error {
+	if len(req.PoolId) != 64 {
+		return fmt.Errorf("池子ID格式不正确，应为64位十六进制字符串")
+	}
+	return nil
+}
+
+// TBC20PoolReservesResponse 池子当前储备响应，数据直接解析自当前池NFT的tape
+type TBC20PoolReservesResponse struct {
+	PoolId          string `json:"pool_id"`            // 池子ID
+	FtLpBalance     int64  `json:"ft_lp_balance"`      // LP代币储备
+	FtABalance      int64  `json:"ft_a_balance"`       // 代币储备
+	TbcBalance      int64  `json:"tbc_balance"`        // TBC储备
+	FtAContractTxid string `json:"ft_a_contract_txid"` // 代币合约ID
+	SourceTxid      string `json:"source_txid"`        // 当前池NFT所在交易ID
+	SourceHeight    int64  `json:"source_height"`      // 交易所在区块高度，未确认时为0
+	Confirmations   int    `json:"confirmations"`      // 确认数
+	TipHeight       int64  `json:"tip_height"`         // 计算确认数时使用的链顶高度
+}
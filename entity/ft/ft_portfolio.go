@@ -0,0 +1,50 @@
+package ft
+
+import (
+	"fmt"
+)
+
+// FtPortfolioAddressRequest 获取地址FT资产估值的请求
+type FtPortfolioAddressRequest struct {
+	// 用户钱包地址
+	Address string `uri:"address" binding:"required"`
+}
+
+// Validate 验证FtPortfolioAddressRequest的参数
+func (req *FtPortfolioAddressRequest) Validate() error {
+	if req.Address == "" {
+		return fmt.Errorf("地址不能为空")
+	}
+	return nil
+}
+
+// FtPortfolioItem 单个代币的持仓估值
+type FtPortfolioItem struct {
+	TokenInfo
+	// 格式化后的余额（已考虑小数位）
+	FtBalanceDisplay string `json:"ft_balance_display"`
+	// 以TBC计价的代币价格
+	FtPrice string `json:"ft_price"`
+	// 持仓的TBC价值
+	ValueTbc string `json:"value_tbc"`
+	// 持仓的法币价值，汇率不可用时为空
+	ValueFiat string `json:"value_fiat,omitempty"`
+}
+
+// FtPortfolioResponse 地址FT资产估值响应
+type FtPortfolioResponse struct {
+	// 查询的地址
+	Address string `json:"address"`
+	// 持有的代币数量
+	TokenCount int `json:"token_count"`
+	// 持仓列表，按TBC价值从高到低排列
+	Tokens []FtPortfolioItem `json:"tokens"`
+	// 总TBC价值
+	TotalValueTbc string `json:"total_value_tbc"`
+	// 总法币价值，汇率不可用时为空
+	TotalValueFiat string `json:"total_value_fiat,omitempty"`
+	// 法币单位
+	FiatCurrency string `json:"fiat_currency"`
+	// TBC对法币汇率，获取失败时为0
+	TbcRate float64 `json:"tbc_rate"`
+}
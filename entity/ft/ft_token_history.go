@@ -2,6 +2,8 @@ package ft
 
 import (
 	"fmt"
+
+	"ginproject/entity/utility"
 )
 
 // FtTokenHistoryRequest 获取代币历史交易记录请求参数
@@ -35,8 +37,8 @@ func ValidateFtTokenHistoryRequest(req *FtTokenHistoryRequest) error {
 		return fmt.Errorf("页码不能小于0")
 	}
 
-	if req.Size <= 0 || req.Size > 10000 {
-		return fmt.Errorf("每页数量必须在1到10000之间")
+	if err := utility.ValidatePageSize(utility.PageEndpointFtTokenHistory, req.Size); err != nil {
+		return err
 	}
 
 	return nil
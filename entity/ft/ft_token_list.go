@@ -16,23 +16,27 @@ type FtTokenListRequest struct {
 
 // FtTokenInfo 代币信息
 type FtTokenInfo struct {
-	FtContractId      string  `json:"ftContractId"`
-	FtSupply          float64 `json:"ftSupply"`
-	FtDecimal         int     `json:"ftDecimal"`
-	FtName            string  `json:"ftName"`
-	FtSymbol          string  `json:"ftSymbol"`
-	FtDescription     string  `json:"ftDescription"`
-	FtCreatorAddress  string  `json:"ftCreatorAddress"`
-	FtCreateTimestamp int     `json:"ftCreateTimestamp"`
-	FtTokenPrice      string  `json:"ftTokenPrice"`
-	FtHoldersCount    int     `json:"ftHoldersCount"`
-	FtIconUrl         string  `json:"ftIconUrl"`
+	FtContractId         string         `json:"ftContractId"`
+	FtSupply             float64        `json:"ftSupply"`
+	FtDecimal            int            `json:"ftDecimal"`
+	FtName               string         `json:"ftName"`
+	FtSymbol             string         `json:"ftSymbol"`
+	FtDescription        string         `json:"ftDescription"`
+	FtCreatorAddress     string         `json:"ftCreatorAddress"`
+	FtCreateTimestamp    int            `json:"ftCreateTimestamp"`
+	FtTokenPrice         string         `json:"ftTokenPrice"`
+	FtHoldersCount       int            `json:"ftHoldersCount"`
+	FtIconUrl            string         `json:"ftIconUrl"`
+	FtVerified           bool           `json:"ftVerified"`
+	FtVerificationMethod string         `json:"ftVerificationMethod,omitempty"` // 核验方式：registry或creator
+	FtMetadata           *TokenMetadata `json:"ftMetadata,omitempty"`
 }
 
 // FtTokenListData 代币列表数据
 type FtTokenListData struct {
-	FtTokenCount int            `json:"ftTokenCount"`
-	FtTokenList  []*FtTokenInfo `json:"ftTokenList"`
+	FtTokenCount int               `json:"ftTokenCount"`
+	FtTokenList  []*FtTokenInfo    `json:"ftTokenList"`
+	Meta         *utility.PageMeta `json:"meta,omitempty"` // 分页元数据
 }
 
 // NewFtTokenListResponse 创建成功的代币列表响应
@@ -65,6 +69,10 @@ func (req *FtTokenListRequest) Validate() error {
 		return fmt.Errorf("每页大小不能小于0")
 	}
 
+	if max := utility.MaxPageSize(utility.PageEndpointFtTokenList); req.Size > max {
+		return fmt.Errorf("%w: 最大为%d，当前为%d", utility.ErrPageSizeTooLarge, max, req.Size)
+	}
+
 	if !isValid {
 		return fmt.Errorf("无效的排序字段，只支持'ftCreateTimestamp'或'ftHoldersCount'")
 	}
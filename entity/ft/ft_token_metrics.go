@@ -0,0 +1,88 @@
+package ft
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// BlocksPerDay 按10分钟出块估算的每日区块数
+	BlocksPerDay = 144
+	// 默认统计周期天数
+	defaultMetricsPeriodDays = 30
+	// 最大统计周期天数
+	maxMetricsPeriodDays = 365
+	// 默认休眠天数
+	defaultDormancyDays = 180
+	// 最大休眠天数
+	maxDormancyDays = 3650
+)
+
+// FtTokenMetricsRequest 获取代币流通速度和休眠比例的请求参数
+type FtTokenMetricsRequest struct {
+	ContractId   string `uri:"contract_id" binding:"required"` // 代币合约ID
+	PeriodDays   int    `form:"period_days"`                   // 流通速度的统计周期天数（可选，默认30）
+	DormancyDays int    `form:"dormancy_days"`                 // 超过该天数未移动的供应量计为休眠（可选，默认180）
+}
+
+// Validate 验证请求参数的合法性，未指定的天数使用默认值
+func (req *FtTokenMetricsRequest) Validate() error {
+	if len(req.ContractId) != 64 {
+		return fmt.Errorf("合约ID格式不正确，应为64位十六进制字符串")
+	}
+
+	if req.PeriodDays == 0 {
+		req.PeriodDays = defaultMetricsPeriodDays
+	}
+	if req.PeriodDays < 1 || req.PeriodDays > maxMetricsPeriodDays {
+		return fmt.Errorf("统计周期必须在1到%d天之间", maxMetricsPeriodDays)
+	}
+
+	if req.DormancyDays == 0 {
+		req.DormancyDays = defaultDormancyDays
+	}
+	if req.DormancyDays < 1 || req.DormancyDays > maxDormancyDays {
+		return fmt.Errorf("休眠天数必须在1到%d天之间", maxDormancyDays)
+	}
+	return nil
+}
+
+// MetricsHeights 根据链顶高度计算统计周期起始高度和休眠截止高度，不低于0
+func (req *FtTokenMetricsRequest) MetricsHeights(tipHeight int64) (periodStart, dormancyHeight int64) {
+	periodStart = max(tipHeight-int64(req.PeriodDays)*BlocksPerDay, 0)
+	dormancyHeight = max(tipHeight-int64(req.DormancyDays)*BlocksPerDay, 0)
+	return periodStart, dormancyHeight
+}
+
+// FtTokenMetricsResponse 代币流通速度和休眠比例
+type FtTokenMetricsResponse struct {
+	FtContractId      string  `json:"ft_contract_id"`      // 代币合约ID
+	FtDecimal         int     `json:"ft_decimal"`          // 代币精度，供应量均为未按精度换算的原始值
+	TipHeight         int64   `json:"tip_height"`          // 计算时使用的链顶高度
+	PeriodDays        int     `json:"period_days"`         // 统计周期天数
+	PeriodStartHeight int64   `json:"period_start_height"` // 统计周期起始高度（不含）
+	DormancyDays      int     `json:"dormancy_days"`       // 休眠天数
+	DormancyHeight    int64   `json:"dormancy_height"`     // 休眠截止高度，创建高度不晚于该高度的未花费输出计为休眠
+	CirculatingSupply uint64  `json:"circulating_supply"`  // 流通供应量，即全部未花费输出的代币总量
+	TransferredSupply uint64  `json:"transferred_supply"`  // 统计周期内被花费的代币总量
+	DormantSupply     uint64  `json:"dormant_supply"`      // 休眠供应量
+	UnindexedSupply   uint64  `json:"unindexed_supply"`    // 尚未回填创建高度的供应量，不计入休眠供应量
+	Velocity          float64 `json:"velocity"`            // 流通速度，周期内转移量/流通供应量
+	Dormancy          float64 `json:"dormancy"`            // 休眠比例，休眠供应量/流通供应量，取值0-1
+}
+
+// ComputeRatios 根据供应量计算流通速度和休眠比例，流通供应量为0时均为0，结果保留4位小数
+func (resp *FtTokenMetricsResponse) ComputeRatios() {
+	resp.Velocity, resp.Dormancy = 0, 0
+	if resp.CirculatingSupply == 0 {
+		return
+	}
+	circulating := float64(resp.CirculatingSupply)
+	resp.Velocity = roundRatio(float64(resp.TransferredSupply) / circulating)
+	resp.Dormancy = roundRatio(float64(resp.DormantSupply) / circulating)
+}
+
+// roundRatio 保留4位小数
+func roundRatio(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,52 @@
+package ft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFtTokenMetricsRequestValidate(t *testing.T) {
+	contractId := strings.Repeat("a", 64)
+
+	req := FtTokenMetricsRequest{ContractId: contractId}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("默认参数验证失败: %v", err)
+	}
+	if req.PeriodDays != defaultMetricsPeriodDays || req.DormancyDays != defaultDormancyDays {
+		t.Fatalf("未使用默认天数: %+v", req)
+	}
+
+	for _, bad := range []FtTokenMetricsRequest{
+		{ContractId: "abc"},
+		{ContractId: contractId, PeriodDays: -1},
+		{ContractId: contractId, PeriodDays: maxMetricsPeriodDays + 1},
+		{ContractId: contractId, DormancyDays: -1},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("期望验证失败: %+v", bad)
+		}
+	}
+}
+
+func TestFtTokenMetricsHeightsAndRatios(t *testing.T) {
+	req := FtTokenMetricsRequest{PeriodDays: 30, DormancyDays: 180}
+	periodStart, dormancyHeight := req.MetricsHeights(100000)
+	if periodStart != 100000-30*BlocksPerDay || dormancyHeight != 100000-180*BlocksPerDay {
+		t.Fatalf("高度计算错误: %d, %d", periodStart, dormancyHeight)
+	}
+	if periodStart, dormancyHeight = req.MetricsHeights(100); periodStart != 0 || dormancyHeight != 0 {
+		t.Fatalf("高度不应小于0: %d, %d", periodStart, dormancyHeight)
+	}
+
+	resp := FtTokenMetricsResponse{CirculatingSupply: 3000, TransferredSupply: 4500, DormantSupply: 1000}
+	resp.ComputeRatios()
+	if resp.Velocity != 1.5 || resp.Dormancy != 0.3333 {
+		t.Fatalf("比例计算错误: velocity=%v, dormancy=%v", resp.Velocity, resp.Dormancy)
+	}
+
+	empty := FtTokenMetricsResponse{TransferredSupply: 10}
+	empty.ComputeRatios()
+	if empty.Velocity != 0 || empty.Dormancy != 0 {
+		t.Fatalf("流通供应量为0时比例应为0: %+v", empty)
+	}
+}
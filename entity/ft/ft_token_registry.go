@@ -0,0 +1,102 @@
+package ft
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// MaxTokenRegistryImportItems 单次导入的最大代币数
+	MaxTokenRegistryImportItems = 500
+	// maxTokenRegistryTags 单个代币的最大标签数
+	maxTokenRegistryTags = 10
+)
+
+// TokenMetadata 人工维护的代币元数据，合并到代币信息和代币列表响应中
+type TokenMetadata struct {
+	LogoUrl     string            `json:"logoUrl,omitempty"`     // 代币图标URL
+	Website     string            `json:"website,omitempty"`     // 项目官网
+	Description string            `json:"description,omitempty"` // 项目描述
+	Tags        []string          `json:"tags,omitempty"`        // 标签
+	Socials     map[string]string `json:"socials,omitempty"`     // 社交媒体链接，如twitter、telegram
+}
+
+// TokenRegistryItem 待导入的单个代币元数据
+type TokenRegistryItem struct {
+	FtContractId string `json:"ftContractId"`
+	TokenMetadata
+	Verified bool `json:"verified"`
+}
+
+// TokenRegistryImportRequest 代币元数据导入请求
+type TokenRegistryImportRequest struct {
+	Tokens []TokenRegistryItem `json:"tokens"`
+}
+
+// TokenRegistryImportResponse 代币元数据导入响应
+type TokenRegistryImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+// Validate 验证TokenRegistryImportRequest的参数
+func (req *TokenRegistryImportRequest) Validate() error {
+	if len(req.Tokens) == 0 {
+		return fmt.Errorf("代币列表不能为空")
+	}
+	if len(req.Tokens) > MaxTokenRegistryImportItems {
+		return fmt.Errorf("单次最多导入%d个代币", MaxTokenRegistryImportItems)
+	}
+
+	seen := make(map[string]struct{}, len(req.Tokens))
+	for i := range req.Tokens {
+		item := &req.Tokens[i]
+		if len(item.FtContractId) != 64 {
+			return fmt.Errorf("第%d个代币的合约ID格式不正确", i+1)
+		}
+		if _, ok := seen[item.FtContractId]; ok {
+			return fmt.Errorf("合约ID重复: %s", item.FtContractId)
+		}
+		seen[item.FtContractId] = struct{}{}
+
+		if err := validateHTTPURL(item.LogoUrl); err != nil {
+			return fmt.Errorf("代币%s的图标URL无效: %w", item.FtContractId, err)
+		}
+		if err := validateHTTPURL(item.Website); err != nil {
+			return fmt.Errorf("代币%s的官网URL无效: %w", item.FtContractId, err)
+		}
+		for name, link := range item.Socials {
+			if err := validateHTTPURL(link); err != nil {
+				return fmt.Errorf("代币%s的%s链接无效: %w", item.FtContractId, name, err)
+			}
+		}
+		if len(item.Tags) > maxTokenRegistryTags {
+			return fmt.Errorf("代币%s的标签不能超过%d个", item.FtContractId, maxTokenRegistryTags)
+		}
+		for _, tag := range item.Tags {
+			if strings.Contains(tag, ",") {
+				return fmt.Errorf("代币%s的标签不能包含逗号", item.FtContractId)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateHTTPURL 校验可选的URL字段，为空时视为合法
+func validateHTTPURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("仅支持http或https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("缺少主机名")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package ft
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"ginproject/entity/utility"
+)
+
+const (
+	// 搜索关键字的最大长度，与代币名称和符号的列宽一致
+	maxSearchKeywordLength = 64
+	// 未指定时的每页记录数
+	defaultSearchPageSize = 10
+)
+
+// FtTokenSearchRequest 按名称或符号搜索代币的请求参数
+type FtTokenSearchRequest struct {
+	Keyword string `form:"keyword"` // 搜索关键字，不区分大小写匹配代币名称和符号
+	Page    int    `form:"page"`    // 页码，从0开始
+	Size    int    `form:"size"`    // 每页记录数（可选，默认10）
+}
+
+// Validate 验证请求参数的合法性，去除关键字首尾空白，未指定每页记录数时使用默认值
+func (req *FtTokenSearchRequest) Validate() error {
+	req.Keyword = strings.TrimSpace(req.Keyword)
+	if req.Keyword == "" {
+		return fmt.Errorf("搜索关键字不能为空")
+	}
+	if utf8.RuneCountInString(req.Keyword) > maxSearchKeywordLength {
+		return fmt.Errorf("搜索关键字不能超过%d个字符", maxSearchKeywordLength)
+	}
+
+	if req.Page < 0 {
+		return fmt.Errorf("页码不能小于0")
+	}
+	if req.Size == 0 {
+		req.Size = defaultSearchPageSize
+	}
+	return utility.ValidatePageSize(utility.PageEndpointFtTokenSearch, req.Size)
+}
@@ -0,0 +1,25 @@
+package ft
+
+import "testing"
+
+func TestFtTokenSearchRequestValidate(t *testing.T) {
+	req := FtTokenSearchRequest{Keyword: "  TBC  "}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("合法请求验证失败: %v", err)
+	}
+	if req.Keyword != "TBC" || req.Size != defaultSearchPageSize {
+		t.Fatalf("关键字和默认每页记录数不正确: %+v", req)
+	}
+
+	invalid := []FtTokenSearchRequest{
+		{Keyword: "   "},
+		{Keyword: string(make([]rune, maxSearchKeywordLength+1))},
+		{Keyword: "tbc", Page: -1},
+		{Keyword: "tbc", Size: -1},
+	}
+	for _, req := range invalid {
+		if err := req.Validate(); err == nil {
+			t.Errorf("请求 %+v 应验证失败", req)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ft
+
+import (
+	"ginproject/entity/utility"
+)
+
+// TokenUnitsExampleRaw 单位示例使用的最小单位金额，按各代币精度格式化后展示小数点位置
+const TokenUnitsExampleRaw = 123456789
+
+// TokenUnits 代币的单位信息，嵌入到余额和UTXO响应中，客户端无需再逐个查询代币信息接口
+type TokenUnits struct {
+	Decimals         int    `json:"decimals"`
+	Symbol           string `json:"symbol"`
+	DisplayName      string `json:"display_name"`
+	ExampleRaw       uint64 `json:"example_raw"`       // 最小单位的示例金额
+	ExampleFormatted string `json:"example_formatted"` // 示例金额按精度格式化并带符号后的结果
+}
+
+// NewTokenUnits 根据代币精度、名称和符号生成单位信息
+func NewTokenUnits(decimals uint8, name, symbol string) *TokenUnits {
+	formatted := utility.FormatUnits(TokenUnitsExampleRaw, int(decimals), false)
+	if symbol != "" {
+		formatted += " " + symbol
+	}
+	return &TokenUnits{
+		Decimals:         int(decimals),
+		Symbol:           symbol,
+		DisplayName:      name,
+		ExampleRaw:       TokenUnitsExampleRaw,
+		ExampleFormatted: formatted,
+	}
+}
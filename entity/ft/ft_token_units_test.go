@@ -0,0 +1,22 @@
+package ft
+
+import "testing"
+
+func TestNewTokenUnits(t *testing.T) {
+	tests := []struct {
+		decimals uint8
+		symbol   string
+		want     string
+	}{
+		{decimals: 6, symbol: "TBCX", want: "123.456789 TBCX"},
+		{decimals: 8, symbol: "USDT", want: "1.23456789 USDT"},
+		{decimals: 0, symbol: "PT", want: "123456789 PT"},
+		{decimals: 10, symbol: "", want: "0.0123456789"},
+	}
+	for _, tt := range tests {
+		units := NewTokenUnits(tt.decimals, "name", tt.symbol)
+		if units.ExampleFormatted != tt.want || units.Decimals != int(tt.decimals) || units.ExampleRaw != TokenUnitsExampleRaw {
+			t.Errorf("NewTokenUnits(%d, %q) = %+v, 期望示例 %q", tt.decimals, tt.symbol, units, tt.want)
+		}
+	}
+}
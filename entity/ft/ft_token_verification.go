@@ -0,0 +1,59 @@
+package ft
+
+import "fmt"
+
+// 代币的核验方式
+const (
+	VerificationMethodRegistry = "registry" // 管理员导入元数据时人工核验
+	VerificationMethodCreator  = "creator"  // 创建者用创世输出的私钥签名核验
+)
+
+// TokenVerificationChallengeRequest 获取代币核验挑战的请求参数
+type TokenVerificationChallengeRequest struct {
+	ContractId string `uri:"contract_id" binding:"required"`
+}
+
+// Validate 验证请求参数的合法性
+func (req *TokenVerificationChallengeRequest) Validate() error {
+	if len(req.ContractId) != 64 {
+		return fmt.Errorf("合约ID格式不正确")
+	}
+	return nil
+}
+
+// TokenVerificationChallengeResponse 代币核验挑战，创建者需用创建者地址的私钥对Challenge做signmessage签名
+type TokenVerificationChallengeResponse struct {
+	FtContractId   string `json:"ftContractId"`
+	CreatorAddress string `json:"creatorAddress"` // 需要用其私钥签名的创建者地址
+	Challenge      string `json:"challenge"`      // 待签名的消息原文
+	ExpiresAt      int64  `json:"expiresAt"`      // 挑战的过期时间(Unix秒)
+}
+
+// TokenVerificationRequest 提交代币核验签名的请求
+type TokenVerificationRequest struct {
+	ContractId string `uri:"contract_id" json:"-"`
+	Challenge  string `json:"challenge"` // 获取到的挑战原文
+	Signature  string `json:"signature"` // base64编码的signmessage紧凑签名
+}
+
+// Validate 验证请求参数的合法性
+func (req *TokenVerificationRequest) Validate() error {
+	if len(req.ContractId) != 64 {
+		return fmt.Errorf("合约ID格式不正确")
+	}
+	if req.Challenge == "" {
+		return fmt.Errorf("挑战不能为空")
+	}
+	if req.Signature == "" {
+		return fmt.Errorf("签名不能为空")
+	}
+	return nil
+}
+
+// TokenVerificationResponse 代币核验结果
+type TokenVerificationResponse struct {
+	FtContractId         string `json:"ftContractId"`
+	FtVerified           bool   `json:"ftVerified"`
+	FtVerificationMethod string `json:"ftVerificationMethod"`
+	FtVerifiedAt         int64  `json:"ftVerifiedAt"` // 核验通过的时间(Unix秒)
+}
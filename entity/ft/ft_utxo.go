@@ -12,6 +12,8 @@ type FtUtxoAddressRequest struct {
 	Address string `uri:"address" binding:"required"`
 	// FT合约ID
 	ContractId string `uri:"contract_id" binding:"required"`
+	// 最少确认数（可选），大于0时只返回确认数足够的UTXO
+	MinConfirmations int64 `form:"min_confirmations"`
 }
 
 // GetCombineScript 获取地址对应的组合脚本
@@ -43,6 +45,8 @@ type FtUtxoItem struct {
 type FtUtxoAddressResponse struct {
 	// FT UTXO列表
 	FtUtxoList []*FtUtxoItem `json:"ftUtxoList"`
+	// 代币单位信息，列表中的UTXO属于同一合约
+	TokenInfo *TokenUnits `json:"token_info,omitempty"`
 }
 
 // Validate 验证FtUtxoAddressRequest的参数
@@ -67,5 +71,5 @@ func (req *FtUtxoAddressRequest) Validate() error {
 		return fmt.Errorf("合约ID格式不正确")
 	}
 
-	return nil
+	return utility.ValidateMinConfirmations(req.MinConfirmations)
 }
@@ -2,6 +2,8 @@ package ft
 
 import (
 	"fmt"
+
+	"ginproject/entity/utility"
 )
 
 // FtUtxoCombineScriptRequest 获取指定合并脚本和合约的FT UTXO请求
@@ -10,6 +12,8 @@ type FtUtxoCombineScriptRequest struct {
 	CombineScript string `uri:"combine_script" binding:"required"`
 	// FT合约ID
 	ContractId string `uri:"contract_id" binding:"required"`
+	// 最少确认数（可选），大于0时只返回确认数足够的UTXO
+	MinConfirmations int64 `form:"min_confirmations"`
 }
 
 /*
@@ -34,6 +38,8 @@ type TBC20FTUtxoItem struct {
 type TBC20FTUtxoResponse struct {
 	// FT UTXO列表
 	FtUtxoList []*TBC20FTUtxoItem `json:"ftUtxoList"`
+	// 代币单位信息，列表中的UTXO属于同一合约
+	TokenInfo *TokenUnits `json:"token_info,omitempty"`
 }
 
 // Validate 验证FtUtxoCombineScriptRequest的参数
@@ -58,5 +64,5 @@ func (req *FtUtxoCombineScriptRequest) Validate() error {
 		return fmt.Errorf("合约ID格式不正确")
 	}
 
-	return nil
+	return utility.ValidateMinConfirmations(req.MinConfirmations)
 }
@@ -0,0 +1,127 @@
+package mempool
+
+import (
+	"fmt"
+	"math"
+)
+
+// 确认目标的区块数范围，与节点estimatesmartfee一致
+const (
+	MinFeeTargetBlocks     = 1
+	MaxFeeTargetBlocks     = 1008
+	DefaultFeeTargetBlocks = 6
+)
+
+// FeeEstimateHorizons 响应中固定返回的确认目标
+var FeeEstimateHorizons = []int{1, 2, 3, 6, 12, 24, 144}
+
+// 费率估算的数据来源
+const (
+	FeeEstimateSourceNode       = "node"        // 节点的estimatesmartfee
+	FeeEstimateSourceElectrumX  = "electrumx"   // ElectrumX的blockchain.estimatefee
+	FeeEstimateSourceMempoolMin = "mempool_min" // 两者都无法估算时使用内存池最低费率
+)
+
+// 估算积压时每个区块打包的交易大小(字节)
+const backlogBlockSize = 32 * 1000 * 1000
+
+// NodeSmartFee 节点estimatesmartfee的响应，数据不足时没有feerate并在errors中说明
+type NodeSmartFee struct {
+	FeeRate float64  `json:"feerate"` // TBC/kB
+	Blocks  int      `json:"blocks"`
+	Errors  []string `json:"errors"`
+}
+
+// FeeEstimateRequest 手续费估算请求
+type FeeEstimateRequest struct {
+	Blocks int `form:"blocks"` // 期望确认的区块数，0表示使用默认值
+}
+
+// Validate 验证手续费估算请求，未指定区块数时使用默认值
+func (r *FeeEstimateRequest) Validate() error {
+	if r.Blocks == 0 {
+		r.Blocks = DefaultFeeTargetBlocks
+	}
+	if r.Blocks < MinFeeTargetBlocks || r.Blocks > MaxFeeTargetBlocks {
+		return fmt.Errorf("blocks必须在%d到%d之间", MinFeeTargetBlocks, MaxFeeTargetBlocks)
+	}
+	return nil
+}
+
+// FeeTarget 单个确认目标的估算费率
+type FeeTarget struct {
+	Blocks  int     `json:"blocks"`
+	FeeRate float64 `json:"fee_rate"` // 聪/字节
+	Source  string  `json:"source"`
+}
+
+// FeeEstimateResponse 手续费估算响应，费率单位均为聪/字节
+type FeeEstimateResponse struct {
+	Blocks         int         `json:"blocks"`           // 请求的确认目标
+	Recommended    float64     `json:"recommended"`      // 推荐费率，取估算费率、积压费率和最低费率中的最大值
+	Estimate       float64     `json:"estimate"`         // 确认目标的估算费率
+	BacklogFeeRate float64     `json:"backlog_fee_rate"` // 按当前内存池积压在目标区块数内被打包所需的费率，积压不足时为0
+	MinFeeRate     float64     `json:"min_fee_rate"`     // 内存池最低费率
+	MempoolVSize   int64       `json:"mempool_vsize"`    // 内存池交易总大小，费率直方图不可用时为0
+	Targets        []FeeTarget `json:"targets"`          // 各确认目标的估算，按区块数从小到大
+}
+
+// FeeRateFromCoinPerKB 将节点和ElectrumX返回的TBC/kB费率转换为聪/字节，无法估算时返回0
+func FeeRateFromCoinPerKB(rate float64) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	return roundFeeRate(rate * satoshisPerCoin / 1000)
+}
+
+// PickFeeTarget 按节点、ElectrumX的顺序选取可用的估算(聪/字节)，都无法估算时使用内存池最低费率
+func PickFeeTarget(blocks int, node, electrumX, minFeeRate float64) FeeTarget {
+	switch {
+	case node > 0:
+		return FeeTarget{Blocks: blocks, FeeRate: node, Source: FeeEstimateSourceNode}
+	case electrumX > 0:
+		return FeeTarget{Blocks: blocks, FeeRate: electrumX, Source: FeeEstimateSourceElectrumX}
+	default:
+		return FeeTarget{Blocks: blocks, FeeRate: minFeeRate, Source: FeeEstimateSourceMempoolMin}
+	}
+}
+
+// BacklogFeeRate 返回在blocks个区块内被打包所需的费率，即费率从高到低累计超过blocks个区块容量的区间费率
+// 内存池积压不足blocks个区块或直方图不可用时返回0
+func BacklogFeeRate(histogram *FeeHistogramResponse, blocks int) float64 {
+	if histogram == nil {
+		return 0
+	}
+	capacity := int64(blocks) * backlogBlockSize
+	for _, bucket := range histogram.Buckets {
+		if bucket.CumulativeVSize > capacity {
+			return bucket.FeeRate
+		}
+	}
+	return 0
+}
+
+// NewFeeEstimateResponse 由各确认目标的估算生成响应，targets需包含blocks
+func NewFeeEstimateResponse(blocks int, targets []FeeTarget, minFeeRate float64, histogram *FeeHistogramResponse) *FeeEstimateResponse {
+	resp := &FeeEstimateResponse{
+		Blocks:         blocks,
+		BacklogFeeRate: BacklogFeeRate(histogram, blocks),
+		MinFeeRate:     roundFeeRate(minFeeRate),
+		Targets:        targets,
+	}
+	if histogram != nil {
+		resp.MempoolVSize = histogram.TotalVSize
+	}
+	for _, target := range targets {
+		if target.Blocks == blocks {
+			resp.Estimate = target.FeeRate
+		}
+	}
+	resp.Recommended = roundFeeRate(math.Max(resp.Estimate, math.Max(resp.BacklogFeeRate, resp.MinFeeRate)))
+	return resp
+}
+
+// roundFeeRate 费率保留三位小数
+func roundFeeRate(rate float64) float64 {
+	return math.Round(rate*1000) / 1000
+}
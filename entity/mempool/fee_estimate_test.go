@@ -0,0 +1,69 @@
+package mempool
+
+import "testing"
+
+func TestFeeEstimateRequestValidate(t *testing.T) {
+	req := FeeEstimateRequest{}
+	if err := req.Validate(); err != nil || req.Blocks != DefaultFeeTargetBlocks {
+		t.Errorf("未指定区块数应使用默认值: blocks=%d err=%v", req.Blocks, err)
+	}
+	for _, blocks := range []int{-1, MaxFeeTargetBlocks + 1} {
+		req := FeeEstimateRequest{Blocks: blocks}
+		if err := req.Validate(); err == nil {
+			t.Errorf("blocks=%d 应返回错误", blocks)
+		}
+	}
+}
+
+func TestPickFeeTarget(t *testing.T) {
+	if got := PickFeeTarget(2, 1.5, 3, 0.5); got.Source != FeeEstimateSourceNode || got.FeeRate != 1.5 {
+		t.Errorf("节点可用时应使用节点估算: %+v", got)
+	}
+	if got := PickFeeTarget(2, 0, 3, 0.5); got.Source != FeeEstimateSourceElectrumX || got.FeeRate != 3 {
+		t.Errorf("节点不可用时应使用ElectrumX估算: %+v", got)
+	}
+	if got := PickFeeTarget(2, 0, 0, 0.5); got.Source != FeeEstimateSourceMempoolMin || got.FeeRate != 0.5 {
+		t.Errorf("都不可用时应使用最低费率: %+v", got)
+	}
+}
+
+func TestFeeRateFromCoinPerKB(t *testing.T) {
+	// 0.0005 TBC/kB = 500聪/1000字节
+	if got := FeeRateFromCoinPerKB(0.0005); got != 0.5 {
+		t.Errorf("FeeRateFromCoinPerKB(0.0005) = %v, want 0.5", got)
+	}
+	if got := FeeRateFromCoinPerKB(-1); got != 0 {
+		t.Errorf("无法估算时应返回0: %v", got)
+	}
+}
+
+func TestNewFeeEstimateResponseBacklog(t *testing.T) {
+	targets := []FeeTarget{
+		{Blocks: 1, FeeRate: 2, Source: FeeEstimateSourceNode},
+		{Blocks: 6, FeeRate: 0.5, Source: FeeEstimateSourceNode},
+	}
+	// 费率不低于3的交易已超过一个区块的容量
+	histogram := NewFeeHistogramResponse(FeeHistogramSourceElectrumX, [][2]float64{
+		{10, backlogBlockSize / 2},
+		{3, backlogBlockSize},
+		{1, backlogBlockSize},
+	})
+
+	resp := NewFeeEstimateResponse(1, targets, 0.25, histogram)
+	if resp.Estimate != 2 || resp.BacklogFeeRate != 3 || resp.Recommended != 3 {
+		t.Errorf("积压超过目标容量时应按积压提高推荐费率: %+v", resp)
+	}
+	if resp.MempoolVSize != backlogBlockSize*5/2 {
+		t.Errorf("MempoolVSize = %d", resp.MempoolVSize)
+	}
+
+	resp = NewFeeEstimateResponse(6, targets, 0.25, histogram)
+	if resp.BacklogFeeRate != 0 || resp.Recommended != 0.5 {
+		t.Errorf("积压不足目标容量时应使用估算费率: %+v", resp)
+	}
+
+	resp = NewFeeEstimateResponse(6, targets, 0.8, nil)
+	if resp.Recommended != 0.8 {
+		t.Errorf("推荐费率不应低于最低费率: %+v", resp)
+	}
+}
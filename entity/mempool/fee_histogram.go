@@ -0,0 +1,74 @@
+package mempool
+
+import "sort"
+
+// 费率直方图的数据来源
+const (
+	FeeHistogramSourceElectrumX = "electrumx" // ElectrumX的mempool.get_fee_histogram
+	FeeHistogramSourceNode      = "node"      // 由节点的详细内存池计算
+)
+
+// 与ElectrumX一致的直方图压缩参数：首个区间约100KB，之后每个区间放大10%
+const (
+	feeHistogramInitialBinSize = 100000
+	feeHistogramBinGrowth      = 1.1
+)
+
+// MempoolFeeEntry 内存池中单笔交易的费率和大小
+type MempoolFeeEntry struct {
+	FeeRate float64 // 聪/字节
+	VSize   int64
+}
+
+// FeeHistogramBucket 费率直方图区间
+type FeeHistogramBucket struct {
+	FeeRate         float64 `json:"fee_rate"`         // 区间内的最低费率(聪/字节)
+	VSize           int64   `json:"vsize"`            // 区间内交易的总大小
+	CumulativeVSize int64   `json:"cumulative_vsize"` // 费率不低于fee_rate的交易总大小
+}
+
+// FeeHistogramResponse 内存池费率直方图响应，区间按费率从高到低排列
+type FeeHistogramResponse struct {
+	Source     string               `json:"source"`
+	TotalVSize int64                `json:"total_vsize"`
+	Buckets    []FeeHistogramBucket `json:"buckets"`
+}
+
+// BuildFeeHistogram 按ElectrumX的算法将内存池交易压缩为[费率, 大小]对，费率从高到低
+func BuildFeeHistogram(entries []MempoolFeeEntry) [][2]float64 {
+	sorted := make([]MempoolFeeEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].FeeRate > sorted[j].FeeRate })
+
+	histogram := make([][2]float64, 0)
+	binSize := float64(feeHistogramInitialBinSize)
+	var cumSize, overflow float64
+	for _, entry := range sorted {
+		cumSize += float64(entry.VSize)
+		if cumSize+overflow > binSize {
+			histogram = append(histogram, [2]float64{entry.FeeRate, cumSize})
+			overflow += cumSize - binSize
+			cumSize = 0
+			binSize *= feeHistogramBinGrowth
+		}
+	}
+	return histogram
+}
+
+// NewFeeHistogramResponse 由[费率, 大小]对生成响应并计算累计大小
+func NewFeeHistogramResponse(source string, histogram [][2]float64) *FeeHistogramResponse {
+	resp := &FeeHistogramResponse{
+		Source:  source,
+		Buckets: make([]FeeHistogramBucket, 0, len(histogram)),
+	}
+	for _, pair := range histogram {
+		vsize := int64(pair[1])
+		resp.TotalVSize += vsize
+		resp.Buckets = append(resp.Buckets, FeeHistogramBucket{
+			FeeRate:         pair[0],
+			VSize:           vsize,
+			CumulativeVSize: resp.TotalVSize,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,31 @@
+package mempool
+
+import "testing"
+
+func TestBuildFeeHistogram(t *testing.T) {
+	entries := []MempoolFeeEntry{
+		{FeeRate: 1, VSize: 60000},
+		{FeeRate: 5, VSize: 60000},
+		{FeeRate: 2, VSize: 60000},
+		{FeeRate: 0.5, VSize: 1000},
+	}
+	got := BuildFeeHistogram(entries)
+	// 5和2累计120000超过首个区间100000；剩余的1累计60000+溢出20000未超过110000，不单独成区间
+	want := [][2]float64{{2, 120000}}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("BuildFeeHistogram() = %v, want %v", got, want)
+	}
+}
+
+func TestNewFeeHistogramResponse(t *testing.T) {
+	resp := NewFeeHistogramResponse(FeeHistogramSourceElectrumX, [][2]float64{{10, 100}, {5, 250}, {1, 50}})
+	wantCum := []int64{100, 350, 400}
+	for i, bucket := range resp.Buckets {
+		if bucket.CumulativeVSize != wantCum[i] {
+			t.Errorf("bucket %d cumulative = %d, want %d", i, bucket.CumulativeVSize, wantCum[i])
+		}
+	}
+	if resp.TotalVSize != 400 {
+		t.Errorf("TotalVSize = %d, want 400", resp.TotalVSize)
+	}
+}
@@ -0,0 +1,99 @@
+package mempool
+
+import (
+	"math"
+
+	"ginproject/entity/transaction"
+)
+
+// 节点返回的手续费单位为TBC，1 TBC = 1000000 聪
+const satoshisPerCoin = 1000000
+
+// NodeMempoolInfo 节点getmempoolinfo的响应
+type NodeMempoolInfo struct {
+	Size          int64   `json:"size"`          // 交易数量
+	Bytes         int64   `json:"bytes"`         // 交易总大小
+	Usage         int64   `json:"usage"`         // 内存占用
+	MaxMempool    int64   `json:"maxmempool"`    // 内存池上限
+	MempoolMinFee float64 `json:"mempoolminfee"` // 最低费率，TBC/kB
+}
+
+// NodeMempoolEntry 节点getmempoolentry的响应，fee单位为TBC，祖先和后代的手续费单位为聪
+type NodeMempoolEntry struct {
+	Size            int64    `json:"size"`
+	Fee             float64  `json:"fee"`
+	Time            int64    `json:"time"`
+	Height          int64    `json:"height"`
+	DescendantCount int64    `json:"descendantcount"`
+	DescendantSize  int64    `json:"descendantsize"`
+	AncestorCount   int64    `json:"ancestorcount"`
+	AncestorSize    int64    `json:"ancestorsize"`
+	AncestorFees    int64    `json:"ancestorfees"`
+	Depends         []string `json:"depends"`
+}
+
+// MempoolInfoResponse 内存池概况响应
+type MempoolInfoResponse struct {
+	Size         int64                 `json:"size"`                    // 交易数量
+	Bytes        int64                 `json:"bytes"`                   // 交易总大小
+	Usage        int64                 `json:"usage"`                   // 内存占用
+	MaxMempool   int64                 `json:"max_mempool"`             // 内存池上限
+	MinFeeRate   float64               `json:"min_fee_rate"`            // 最低费率(聪/字节)
+	FeeHistogram *FeeHistogramResponse `json:"fee_histogram,omitempty"` // 获取失败时省略
+}
+
+// NewMempoolInfoResponse 由节点响应生成内存池概况，费率转换为聪/字节
+func NewMempoolInfoResponse(info *NodeMempoolInfo, histogram *FeeHistogramResponse) *MempoolInfoResponse {
+	return &MempoolInfoResponse{
+		Size:         info.Size,
+		Bytes:        info.Bytes,
+		Usage:        info.Usage,
+		MaxMempool:   info.MaxMempool,
+		MinFeeRate:   info.MempoolMinFee * satoshisPerCoin / 1000,
+		FeeHistogram: histogram,
+	}
+}
+
+// MempoolTxResponse 内存池中未确认交易的详情
+type MempoolTxResponse struct {
+	Tx              *transaction.TxDecodeResponse `json:"tx"`               // 解码后的交易
+	Fee             int64                         `json:"fee"`              // 手续费(聪)
+	FeeRate         float64                       `json:"fee_rate"`         // 费率(聪/字节)
+	Size            int64                         `json:"size"`             // 交易大小
+	Time            int64                         `json:"time"`             // 进入内存池的时间
+	Height          int64                         `json:"height"`           // 进入内存池时的区块高度
+	Depends         []string                      `json:"depends"`          // 直接依赖的内存池交易
+	Ancestors       []string                      `json:"ancestors"`        // 内存池中的全部祖先交易
+	AncestorCount   int64                         `json:"ancestor_count"`   // 祖先数量，包括自身
+	AncestorSize    int64                         `json:"ancestor_size"`    // 祖先总大小，包括自身
+	AncestorFees    int64                         `json:"ancestor_fees"`    // 祖先总手续费(聪)，包括自身
+	DescendantCount int64                         `json:"descendant_count"` // 后代数量，包括自身
+}
+
+// NewMempoolTxResponse 由节点的内存池条目生成交易详情，手续费转换为聪
+func NewMempoolTxResponse(tx *transaction.TxDecodeResponse, entry *NodeMempoolEntry, ancestors []string) *MempoolTxResponse {
+	fee := int64(math.Round(entry.Fee * satoshisPerCoin))
+	resp := &MempoolTxResponse{
+		Tx:              tx,
+		Fee:             fee,
+		Size:            entry.Size,
+		Time:            entry.Time,
+		Height:          entry.Height,
+		Depends:         entry.Depends,
+		Ancestors:       ancestors,
+		AncestorCount:   entry.AncestorCount,
+		AncestorSize:    entry.AncestorSize,
+		AncestorFees:    entry.AncestorFees,
+		DescendantCount: entry.DescendantCount,
+	}
+	if entry.Size > 0 {
+		resp.FeeRate = math.Round(float64(fee)/float64(entry.Size)*1000) / 1000
+	}
+	if resp.Depends == nil {
+		resp.Depends = []string{}
+	}
+	if resp.Ancestors == nil {
+		resp.Ancestors = []string{}
+	}
+	return resp
+}
@@ -0,0 +1,19 @@
+package mempool
+
+import "testing"
+
+func TestNewMempoolTxResponse(t *testing.T) {
+	entry := &NodeMempoolEntry{Size: 226, Fee: 0.000113, AncestorCount: 2, AncestorFees: 200, Depends: []string{"parent"}}
+	resp := NewMempoolTxResponse(nil, entry, nil)
+	if resp.Fee != 113 || resp.FeeRate != 0.5 {
+		t.Fatalf("手续费换算不正确: fee=%d rate=%v", resp.Fee, resp.FeeRate)
+	}
+	if resp.Ancestors == nil || len(resp.Depends) != 1 || resp.AncestorFees != 200 {
+		t.Fatalf("依赖信息不正确: %+v", resp)
+	}
+
+	info := NewMempoolInfoResponse(&NodeMempoolInfo{Size: 3, MempoolMinFee: 0.0005}, nil)
+	if info.MinFeeRate != 0.5 {
+		t.Fatalf("最低费率换算不正确: %v", info.MinFeeRate)
+	}
+}
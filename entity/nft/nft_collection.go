@@ -1,5 +1,7 @@
 package nft
 
+import "ginproject/entity/utility"
+
 // CollectionItem 表示单个NFT集合项目
 type CollectionItem struct {
 	CollectionId              string `json:"collectionId"`              // 集合ID
@@ -29,8 +31,9 @@ type CollectionDetailResponse struct {
 
 // CollectionListResponse 表示集合列表响应
 type CollectionListResponse struct {
-	CollectionCount int              `json:"collectionCount"` // 集合总数
-	CollectionList  []CollectionItem `json:"collectionList"`  // 集合列表
+	CollectionCount int               `json:"collectionCount"` // 集合总数
+	CollectionList  []CollectionItem  `json:"collectionList"`  // 集合列表
+	Meta            *utility.PageMeta `json:"meta,omitempty"`  // 分页元数据
 }
 
 // CollectionQueryParams 表示查询NFT集合的参数
@@ -55,7 +58,7 @@ type GetDetailCollectionInfoRequest struct {
 var (
 	ErrEmptyCollectionAddress = NewNftError(20001, "集合查询地址不能为空")
 	ErrInvalidCollectionPage  = NewNftError(20002, "集合查询页码不能为负数")
-	ErrInvalidCollectionSize  = NewNftError(20003, "集合查询每页大小必须在1-10000之间")
+	ErrInvalidCollectionSize  = NewNftError(20003, "集合查询每页大小无效")
 	ErrEmptyCollectionId      = NewNftError(20004, "集合ID不能为空")
 )
 
@@ -72,8 +75,8 @@ func ValidateCollectionQueryByAddress(address string, page, size int) error {
 	}
 
 	// 验证每页大小
-	if size <= 0 || size > 10000 {
-		return ErrInvalidCollectionSize
+	if err := validatePageSize(utility.PageEndpointNftCollectionByAddress, size, ErrInvalidCollectionSize); err != nil {
+		return err
 	}
 
 	return nil
@@ -87,8 +90,8 @@ func ValidateCollectionsPageSize(page, size int) error {
 	}
 
 	// 验证每页大小
-	if size <= 0 || size > 10000 {
-		return ErrInvalidCollectionSize
+	if err := validatePageSize(utility.PageEndpointNftAllCollections, size, ErrInvalidCollectionSize); err != nil {
+		return err
 	}
 
 	return nil
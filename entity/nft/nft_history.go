@@ -1,5 +1,7 @@
 package nft
 
+import "ginproject/entity/utility"
+
 // NftHistoryItem 表示NFT历史记录项
 type NftHistoryItem struct {
 	Txid               string   `json:"txid"`                // 交易ID
@@ -26,10 +28,11 @@ type NftHistoryRequest struct {
 
 // NftHistoryResponse 表示NFT历史记录响应
 type NftHistoryResponse struct {
-	Address      string           `json:"address"`       // NFT持有者地址
-	ScriptHash   string           `json:"script_hash"`   // 脚本哈希
-	HistoryCount int              `json:"history_count"` // 历史记录总数
-	Result       []NftHistoryItem `json:"result"`        // 历史记录列表
+	Address      string            `json:"address"`        // NFT持有者地址
+	ScriptHash   string            `json:"script_hash"`    // 脚本哈希
+	HistoryCount int               `json:"history_count"`  // 历史记录总数
+	Result       []NftHistoryItem  `json:"result"`         // 历史记录列表
+	Meta         *utility.PageMeta `json:"meta,omitempty"` // 分页元数据
 }
 
 // AddressToNftScriptHashRequest 表示地址转换为NFT脚本哈希的请求参数
@@ -46,8 +49,8 @@ func (r *NftHistoryRequest) Validate() error {
 	if r.Page < 0 {
 		return ErrInvalidPage
 	}
-	if r.Size <= 0 || r.Size > 10000 {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftHistory, r.Size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -60,17 +63,18 @@ func ValidateNftHistory(address string, page, size int) error {
 	if page < 0 {
 		return ErrInvalidPage
 	}
-	if size <= 0 || size > 10000 {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftHistory, size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
 
 // 错误定义
 var (
-	ErrEmptyAddress = NewNftError(10001, "地址不能为空")
-	ErrInvalidPage  = NewNftError(10002, "页码不能为负数")
-	ErrInvalidSize  = NewNftError(10003, "每页记录数必须在1-10000之间")
+	ErrEmptyAddress      = NewNftError(10001, "地址不能为空")
+	ErrInvalidPage       = NewNftError(10002, "页码不能为负数")
+	ErrInvalidSize       = NewNftError(10003, "每页记录数无效")
+	ErrInvalidFromHeight = NewNftError(10010, "起始高度不能为负数")
 )
 
 // NftError 表示NFT操作相关的错误
@@ -1,5 +1,7 @@
 package nft
 
+import "ginproject/entity/utility"
+
 // NftItem 表示单个NFT项目
 type NftItem struct {
 	CollectionId          string `json:"collectionId"`          // 集合ID
@@ -23,8 +25,9 @@ type NftItem struct {
 
 // NftListResponse 表示NFT列表响应
 type NftListResponse struct {
-	NftTotalCount int       `json:"nftTotalCount"` // NFT总数
-	NftList       []NftItem `json:"nftList"`       // NFT列表
+	NftTotalCount int               `json:"nftTotalCount"`  // NFT总数
+	NftList       []NftItem         `json:"nftList"`        // NFT列表
+	Meta          *utility.PageMeta `json:"meta,omitempty"` // 分页元数据
 }
 
 // NftInfoListResponse 表示NFT信息列表响应
@@ -102,6 +105,7 @@ type DetailCollectionInfoRequest struct {
 
 // 常量定义
 const (
+	// MaxPageSize 按合约ID批量查询时的最大数量
 	MaxPageSize = 10000
 )
 
@@ -132,8 +136,8 @@ func ValidateGetNftByAddressPageSize(address string, page, size int, ifExtraColl
 	if page < 0 {
 		return ErrInvalidPage
 	}
-	if size <= 0 || size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftByAddress, size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -146,8 +150,8 @@ func ValidateGetNftByScriptHashPageSize(scriptHash string, page, size int) error
 	if page < 0 {
 		return ErrInvalidPage
 	}
-	if size <= 0 || size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftByScriptHash, size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -160,8 +164,8 @@ func ValidateGetNftByCollectionIdPageSize(collectionId string, page, size int) e
 	if page < 0 {
 		return ErrInvalidPage
 	}
-	if size <= 0 || size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftByCollection, size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -174,8 +178,8 @@ func (r *NftsByAddressRequest) Validate() error {
 	if r.Page < 0 {
 		return ErrInvalidPage
 	}
-	if r.Size <= 0 || r.Size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftByAddress, r.Size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -188,8 +192,8 @@ func (r *NftsByScriptHashRequest) Validate() error {
 	if r.Page < 0 {
 		return ErrInvalidPage
 	}
-	if r.Size <= 0 || r.Size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftByScriptHash, r.Size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -202,8 +206,8 @@ func (r *NftsByCollectionIdRequest) Validate() error {
 	if r.Page < 0 {
 		return ErrInvalidPage
 	}
-	if r.Size <= 0 || r.Size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftByCollection, r.Size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -216,8 +220,8 @@ func (r *CollectionsByAddressRequest) Validate() error {
 	if r.Page < 0 {
 		return ErrInvalidPage
 	}
-	if r.Size <= 0 || r.Size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftCollectionByAddress, r.Size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -227,8 +231,8 @@ func (r *CollectionsPageRequest) Validate() error {
 	if r.Page < 0 {
 		return ErrInvalidPage
 	}
-	if r.Size <= 0 || r.Size > MaxPageSize {
-		return ErrInvalidSize
+	if err := validatePageSize(utility.PageEndpointNftAllCollections, r.Size, ErrInvalidSize); err != nil {
+		return err
 	}
 	return nil
 }
@@ -251,3 +255,38 @@ func (r *DetailCollectionInfoRequest) Validate() error {
 	}
 	return nil
 }
+
+// validatePageSize 校验每页记录数，不合法时返回带接口上限信息的NFT错误
+func validatePageSize(endpoint string, size int, invalid *NftError) error {
+	if err := utility.ValidatePageSize(endpoint, size); err != nil {
+		return NewNftError(invalid.Code, err.Error())
+	}
+	return nil
+}
+
+// NftCollectionHolding 表示地址在单个集合下的持仓汇总
+type NftCollectionHolding struct {
+	CollectionId          string `json:"collectionId"`          // 集合ID，不属于任何集合的NFT为空
+	CollectionName        string `json:"collectionName"`        // 集合名称
+	NftCount              int    `json:"nftCount"`              // 持有的NFT数量
+	LastActivityTimestamp int    `json:"lastActivityTimestamp"` // 集合内NFT最近一次转移的时间戳
+	FirstCreateTimestamp  int    `json:"firstCreateTimestamp"`  // 集合内最早创建的NFT时间戳
+	TotalTransferCount    int    `json:"totalTransferCount"`    // 集合内NFT累计转移次数
+}
+
+// NftPortfolioResponse 表示地址的NFT持仓汇总响应
+type NftPortfolioResponse struct {
+	Address               string                 `json:"address"`               // 查询的地址
+	NftTotalCount         int                    `json:"nftTotalCount"`         // NFT总数
+	CollectionCount       int                    `json:"collectionCount"`       // 持有的集合数量
+	LastActivityTimestamp int                    `json:"lastActivityTimestamp"` // 最近一次转移的时间戳
+	Collections           []NftCollectionHolding `json:"collections"`           // 按最近转移时间倒序排列的集合持仓
+}
+
+// ValidateNftPortfolio 验证获取NFT持仓汇总的参数
+func ValidateNftPortfolio(address string) error {
+	if address == "" {
+		return ErrEmptyAddress
+	}
+	return nil
+}
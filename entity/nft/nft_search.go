@@ -0,0 +1,148 @@
+package nft
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"ginproject/entity/utility"
+)
+
+const (
+	// 名称关键字的最大长度，与名称列宽一致
+	maxSearchKeywordLength = 64
+	// 单次搜索最多的属性过滤条件
+	maxSearchAttributes = 5
+	// 未指定时的每页记录数
+	defaultSearchPageSize = 10
+	// 属性键和值的最大长度，与属性索引表的列宽一致，超出的属性不建立索引
+	maxAttributeKeyLength   = 64
+	maxAttributeValueLength = 128
+)
+
+// 搜索参数错误
+var (
+	ErrEmptySearchFilter   = NewNftError(10013, "至少需要指定collection、name、creator或attr中的一个搜索条件")
+	ErrInvalidSearchAttr   = NewNftError(10014, "属性过滤格式应为key:value")
+	ErrTooManySearchAttrs  = NewNftError(10015, "属性过滤条件不能超过5个")
+	ErrSearchKeywordLength = NewNftError(10016, "搜索关键字不能超过64个字符")
+)
+
+// NftAttribute NFT的一个属性键值
+type NftAttribute struct {
+	Key   string
+	Value string
+}
+
+// NftSearchRequest NFT搜索请求参数，多个条件同时满足
+type NftSearchRequest struct {
+	Collection string   `form:"collection"` // 集合名称，不区分大小写的部分匹配
+	Name       string   `form:"name"`       // NFT名称，不区分大小写的部分匹配
+	Creator    string   `form:"creator"`    // 集合创建者地址
+	Attrs      []string `form:"attr"`       // 属性过滤，格式为key:value，可重复
+	Page       int      `form:"page"`       // 页码，从0开始
+	Size       int      `form:"size"`       // 每页记录数（可选，默认10）
+
+	Attributes []NftAttribute `form:"-"` // 由Attrs解析得到
+}
+
+// Validate 验证请求参数并解析属性过滤条件，未指定每页记录数时使用默认值
+func (req *NftSearchRequest) Validate() error {
+	req.Collection = strings.TrimSpace(req.Collection)
+	req.Name = strings.TrimSpace(req.Name)
+	req.Creator = strings.TrimSpace(req.Creator)
+	if utf8.RuneCountInString(req.Collection) > maxSearchKeywordLength || utf8.RuneCountInString(req.Name) > maxSearchKeywordLength {
+		return ErrSearchKeywordLength
+	}
+
+	if len(req.Attrs) > maxSearchAttributes {
+		return ErrTooManySearchAttrs
+	}
+	req.Attributes = make([]NftAttribute, 0, len(req.Attrs))
+	for _, attr := range req.Attrs {
+		key, value, ok := strings.Cut(attr, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return ErrInvalidSearchAttr
+		}
+		req.Attributes = append(req.Attributes, NftAttribute{Key: key, Value: value})
+	}
+
+	if req.Collection == "" && req.Name == "" && req.Creator == "" && len(req.Attributes) == 0 {
+		return ErrEmptySearchFilter
+	}
+	if req.Page < 0 {
+		return ErrInvalidPage
+	}
+	if req.Size == 0 {
+		req.Size = defaultSearchPageSize
+	}
+	return validatePageSize(utility.PageEndpointNftSearch, req.Size, ErrInvalidSize)
+}
+
+// NftSearchFilter NFT搜索的数据库过滤条件
+type NftSearchFilter struct {
+	CollectionIds []string       // 集合ID，为nil时不按集合过滤
+	Name          string         // NFT名称关键字
+	Attributes    []NftAttribute // 属性键值，需全部匹配
+}
+
+// ParseNftAttributes 解析NFT的JSON属性，支持{"key": value}对象和[{"trait_type": key, "value": value}]数组两种格式
+// 非字符串的值使用其JSON文本，不是JSON或键值超长的属性被忽略
+func ParseNftAttributes(raw string) []NftAttribute {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &object); err == nil {
+		attrs := make([]NftAttribute, 0, len(object))
+		for key, value := range object {
+			attrs = appendAttribute(attrs, key, value)
+		}
+		return attrs
+	}
+
+	var traits []struct {
+		TraitType string          `json:"trait_type"`
+		Key       string          `json:"key"`
+		Name      string          `json:"name"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(raw), &traits); err != nil {
+		return nil
+	}
+	attrs := make([]NftAttribute, 0, len(traits))
+	for _, trait := range traits {
+		key := trait.TraitType
+		if key == "" {
+			key = trait.Key
+		}
+		if key == "" {
+			key = trait.Name
+		}
+		attrs = appendAttribute(attrs, key, trait.Value)
+	}
+	return attrs
+}
+
+// appendAttribute 将JSON值转换为属性值后追加，空键、空值或超长的属性被忽略
+func appendAttribute(attrs []NftAttribute, key string, raw json.RawMessage) []NftAttribute {
+	key = strings.TrimSpace(key)
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		var compact bytes.Buffer
+		if json.Compact(&compact, raw) != nil || compact.String() == "null" {
+			return attrs
+		}
+		value = compact.String()
+	}
+	value = strings.TrimSpace(value)
+	if key == "" || value == "" ||
+		utf8.RuneCountInString(key) > maxAttributeKeyLength || utf8.RuneCountInString(value) > maxAttributeValueLength {
+		return attrs
+	}
+	return append(attrs, NftAttribute{Key: key, Value: value})
+}
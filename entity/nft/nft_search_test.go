@@ -0,0 +1,61 @@
+package nft
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseNftAttributes(t *testing.T) {
+	sortAttrs := func(attrs []NftAttribute) []NftAttribute {
+		sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+		return attrs
+	}
+
+	got := sortAttrs(ParseNftAttributes(`{"color":"red","level":3,"rare":true,"empty":"","none":null}`))
+	want := []NftAttribute{{"color", "red"}, {"level", "3"}, {"rare", "true"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("对象格式解析 = %v, 期望 %v", got, want)
+	}
+
+	got = ParseNftAttributes(`[{"trait_type":"Background","value":"Blue"},{"key":"eyes","value":"laser"},{"value":"no key"}]`)
+	want = []NftAttribute{{"Background", "Blue"}, {"eyes", "laser"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("数组格式解析 = %v, 期望 %v", got, want)
+	}
+
+	long := `{"desc":"` + strings.Repeat("a", maxAttributeValueLength+1) + `"}`
+	for _, raw := range []string{"", "plain text", long} {
+		if attrs := ParseNftAttributes(raw); len(attrs) != 0 {
+			t.Errorf("ParseNftAttributes(%q) = %v, 期望为空", raw, attrs)
+		}
+	}
+}
+
+func TestNftSearchRequestValidate(t *testing.T) {
+	req := NftSearchRequest{Name: " punk ", Attrs: []string{"color: red"}}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("验证失败: %v", err)
+	}
+	if req.Name != "punk" || req.Size != defaultSearchPageSize || !reflect.DeepEqual(req.Attributes, []NftAttribute{{"color", "red"}}) {
+		t.Fatalf("参数处理不正确: %+v", req)
+	}
+
+	tests := []struct {
+		req  NftSearchRequest
+		want error
+	}{
+		{NftSearchRequest{}, ErrEmptySearchFilter},
+		{NftSearchRequest{Attrs: []string{"color"}}, ErrInvalidSearchAttr},
+		{NftSearchRequest{Attrs: []string{"a:1", "b:2", "c:3", "d:4", "e:5", "f:6"}}, ErrTooManySearchAttrs},
+		{NftSearchRequest{Name: strings.Repeat("n", maxSearchKeywordLength+1)}, ErrSearchKeywordLength},
+		{NftSearchRequest{Creator: "addr", Page: -1}, ErrInvalidPage},
+	}
+	for _, tt := range tests {
+		if err := tt.req.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%+v) = %v, 期望 %v", tt.req, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package nft
+
+import "strings"
+
+// 集合NFT列表的排序字段
+const (
+	NftSortIndex         = "index"          // 集合索引，默认
+	NftSortMintTime      = "mint_time"      // 铸造时间
+	NftSortLastTransfer  = "last_transfer"  // 最后转移时间
+	NftSortTransferCount = "transfer_count" // 转移次数
+)
+
+// 排序参数错误
+var (
+	ErrInvalidSort  = NewNftError(10011, "排序字段无效，可选值为index、mint_time、last_transfer、transfer_count")
+	ErrInvalidOrder = NewNftError(10012, "排序方向无效，可选值为asc、desc")
+)
+
+// 排序字段对应的数据库列，均有(collection_id, 列, collection_index)联合索引
+var sortColumns = map[string]string{
+	NftSortIndex:         "collection_index",
+	NftSortMintTime:      "nft_create_timestamp",
+	NftSortLastTransfer:  "nft_last_transfer_timestamp",
+	NftSortTransferCount: "nft_transfer_time_count",
+}
+
+// NftSort 集合NFT列表的排序方式
+type NftSort struct {
+	Field string // 排序字段
+	Desc  bool   // 是否降序
+}
+
+// DefaultNftSort 默认按集合索引升序
+var DefaultNftSort = NftSort{Field: NftSortIndex}
+
+// ParseNftSort 解析sort和order查询参数
+// sort为空时按集合索引排序；order为空时集合索引默认升序，时间和次数默认降序
+func ParseNftSort(sort, order string) (NftSort, error) {
+	if sort == "" {
+		sort = NftSortIndex
+	}
+	if _, ok := sortColumns[sort]; !ok {
+		return NftSort{}, ErrInvalidSort
+	}
+
+	s := NftSort{Field: sort, Desc: sort != NftSortIndex}
+	switch strings.ToLower(order) {
+	case "":
+	case "asc":
+		s.Desc = false
+	case "desc":
+		s.Desc = true
+	default:
+		return NftSort{}, ErrInvalidOrder
+	}
+	return s, nil
+}
+
+// Column 返回排序字段对应的数据库列，未知字段按集合索引排序
+func (s NftSort) Column() string {
+	if column, ok := sortColumns[s.Field]; ok {
+		return column
+	}
+	return sortColumns[NftSortIndex]
+}
@@ -0,0 +1,36 @@
+package nft
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseNftSort(t *testing.T) {
+	tests := []struct {
+		sort, order string
+		want        NftSort
+		wantColumn  string
+		wantErr     error
+	}{
+		{want: DefaultNftSort, wantColumn: "collection_index"},
+		{sort: NftSortIndex, order: "DESC", want: NftSort{Field: NftSortIndex, Desc: true}, wantColumn: "collection_index"},
+		{sort: NftSortLastTransfer, want: NftSort{Field: NftSortLastTransfer, Desc: true}, wantColumn: "nft_last_transfer_timestamp"},
+		{sort: NftSortTransferCount, order: "asc", want: NftSort{Field: NftSortTransferCount}, wantColumn: "nft_transfer_time_count"},
+		{sort: "nft_name", wantErr: ErrInvalidSort},
+		{sort: NftSortMintTime, order: "up", wantErr: ErrInvalidOrder},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseNftSort(tt.sort, tt.order)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseNftSort(%q, %q) 错误 = %v, 期望 %v", tt.sort, tt.order, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got != tt.want || got.Column() != tt.wantColumn {
+			t.Errorf("ParseNftSort(%q, %q) = %+v(%s), 期望 %+v(%s)", tt.sort, tt.order, got, got.Column(), tt.want, tt.wantColumn)
+		}
+	}
+}
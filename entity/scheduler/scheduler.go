@@ -0,0 +1,22 @@
+package scheduler
+
+// 周期任务的运行结果
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// JobStatus 周期任务状态，共享任务的最近一次运行可能来自其它实例
+type JobStatus struct {
+	Name           string `json:"name"`
+	Local          bool   `json:"local"`    // 每个实例各自运行
+	Interval       int64  `json:"interval"` // 运行周期(秒)，0表示已停用
+	Running        bool   `json:"running"`
+	Owner          string `json:"owner,omitempty"` // 最近一次运行任务的实例
+	LastStartedAt  int64  `json:"last_started_at,omitempty"`
+	LastFinishedAt int64  `json:"last_finished_at,omitempty"`
+	LastStatus     string `json:"last_status,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+	LastDurationMs int64  `json:"last_duration_ms"`
+	RunCount       int64  `json:"run_count"`
+}
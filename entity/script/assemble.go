@@ -0,0 +1,123 @@
+package script
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 解码接口接受的脚本最大字节数
+const MaxScriptSize = 100 * 1024
+
+// 数据推送相关的操作码
+const (
+	opPushData1 = 0x4c
+	opPushData2 = 0x4d
+	opPushData4 = 0x4e
+	op1Negate   = 0x4f
+	op1         = 0x51
+)
+
+// opcodes ASM中的操作码名称，与节点输出的名称一致
+var opcodes = map[string]byte{
+	"OP_RESERVED": 0x50, "OP_NOP": 0x61, "OP_VER": 0x62, "OP_IF": 0x63, "OP_NOTIF": 0x64,
+	"OP_VERIF": 0x65, "OP_VERNOTIF": 0x66, "OP_ELSE": 0x67, "OP_ENDIF": 0x68, "OP_VERIFY": 0x69,
+	"OP_RETURN": 0x6a, "OP_TOALTSTACK": 0x6b, "OP_FROMALTSTACK": 0x6c, "OP_2DROP": 0x6d, "OP_2DUP": 0x6e,
+	"OP_3DUP": 0x6f, "OP_2OVER": 0x70, "OP_2ROT": 0x71, "OP_2SWAP": 0x72, "OP_IFDUP": 0x73,
+	"OP_DEPTH": 0x74, "OP_DROP": 0x75, "OP_DUP": 0x76, "OP_NIP": 0x77, "OP_OVER": 0x78,
+	"OP_PICK": 0x79, "OP_ROLL": 0x7a, "OP_ROT": 0x7b, "OP_SWAP": 0x7c, "OP_TUCK": 0x7d,
+	"OP_CAT": 0x7e, "OP_SPLIT": 0x7f, "OP_NUM2BIN": 0x80, "OP_BIN2NUM": 0x81, "OP_SIZE": 0x82,
+	"OP_INVERT": 0x83, "OP_AND": 0x84, "OP_OR": 0x85, "OP_XOR": 0x86, "OP_EQUAL": 0x87,
+	"OP_EQUALVERIFY": 0x88, "OP_RESERVED1": 0x89, "OP_RESERVED2": 0x8a, "OP_1ADD": 0x8b, "OP_1SUB": 0x8c,
+	"OP_2MUL": 0x8d, "OP_2DIV": 0x8e, "OP_NEGATE": 0x8f, "OP_ABS": 0x90, "OP_NOT": 0x91,
+	"OP_0NOTEQUAL": 0x92, "OP_ADD": 0x93, "OP_SUB": 0x94, "OP_MUL": 0x95, "OP_DIV": 0x96,
+	"OP_MOD": 0x97, "OP_LSHIFT": 0x98, "OP_RSHIFT": 0x99, "OP_BOOLAND": 0x9a, "OP_BOOLOR": 0x9b,
+	"OP_NUMEQUAL": 0x9c, "OP_NUMEQUALVERIFY": 0x9d, "OP_NUMNOTEQUAL": 0x9e, "OP_LESSTHAN": 0x9f, "OP_GREATERTHAN": 0xa0,
+	"OP_LESSTHANOREQUAL": 0xa1, "OP_GREATERTHANOREQUAL": 0xa2, "OP_MIN": 0xa3, "OP_MAX": 0xa4, "OP_WITHIN": 0xa5,
+	"OP_RIPEMD160": 0xa6, "OP_SHA1": 0xa7, "OP_SHA256": 0xa8, "OP_HASH160": 0xa9, "OP_HASH256": 0xaa,
+	"OP_CODESEPARATOR": 0xab, "OP_CHECKSIG": 0xac, "OP_CHECKSIGVERIFY": 0xad, "OP_CHECKMULTISIG": 0xae, "OP_CHECKMULTISIGVERIFY": 0xaf,
+	"OP_NOP1": 0xb0, "OP_CHECKLOCKTIMEVERIFY": 0xb1, "OP_NOP2": 0xb1, "OP_CHECKSEQUENCEVERIFY": 0xb2, "OP_NOP3": 0xb2,
+	"OP_NOP4": 0xb3, "OP_NOP5": 0xb4, "OP_NOP6": 0xb5, "OP_NOP7": 0xb6, "OP_NOP8": 0xb7,
+	"OP_NOP9": 0xb8, "OP_NOP10": 0xb9,
+	"OP_0": 0x00, "OP_FALSE": 0x00, "OP_1NEGATE": op1Negate, "OP_TRUE": op1,
+}
+
+func init() {
+	for n := 1; n <= 16; n++ {
+		opcodes[fmt.Sprintf("OP_%d", n)] = byte(op1 + n - 1)
+	}
+}
+
+// AssembleASM 将节点格式的ASM转换为脚本十六进制
+// 节点把不超过4字节的数据推送输出为十进制数字，因此不超过10位的纯数字按数字编码，其余按十六进制数据推送；
+// 这类数字与原始推送的字节可能不完全一致，需要精确字节的调用方应直接提供十六进制脚本
+func AssembleASM(asm string) (string, error) {
+	var script []byte
+	for i, token := range strings.Fields(asm) {
+		if op, ok := opcodes[strings.ToUpper(token)]; ok {
+			script = append(script, op)
+			continue
+		}
+		if n, err := strconv.ParseInt(token, 10, 32); err == nil && len(strings.TrimPrefix(token, "-")) <= 10 {
+			script = appendNumber(script, n)
+			continue
+		}
+		data, err := hex.DecodeString(token)
+		if err != nil {
+			return "", fmt.Errorf("第%d个元素%q既不是操作码也不是十六进制数据", i+1, token)
+		}
+		script = appendPush(script, data)
+	}
+	if len(script) > MaxScriptSize {
+		return "", fmt.Errorf("脚本不能超过%d字节", MaxScriptSize)
+	}
+	return hex.EncodeToString(script), nil
+}
+
+// appendNumber 追加数字，-1和0到16使用专用操作码，其余按脚本数字的最小编码推送
+func appendNumber(script []byte, n int64) []byte {
+	switch {
+	case n == 0:
+		return append(script, 0x00)
+	case n == -1:
+		return append(script, op1Negate)
+	case n >= 1 && n <= 16:
+		return append(script, byte(op1+n-1))
+	}
+
+	negative := n < 0
+	abs := n
+	if negative {
+		abs = -n
+	}
+	var data []byte
+	for abs > 0 {
+		data = append(data, byte(abs&0xff))
+		abs >>= 8
+	}
+	// 最高字节的最高位是符号位，被占用时补一个字节
+	if data[len(data)-1]&0x80 != 0 {
+		data = append(data, 0x00)
+	}
+	if negative {
+		data[len(data)-1] |= 0x80
+	}
+	return appendPush(script, data)
+}
+
+// appendPush 按数据长度选择最短的推送方式
+func appendPush(script []byte, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n < opPushData1:
+		script = append(script, byte(n))
+	case n <= 0xff:
+		script = append(script, opPushData1, byte(n))
+	case n <= 0xffff:
+		script = append(script, opPushData2, byte(n), byte(n>>8))
+	default:
+		script = append(script, opPushData4, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(script, data...)
+}
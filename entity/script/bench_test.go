@@ -0,0 +1,45 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"ginproject/entity/electrumx"
+)
+
+// 基准测试使用的历史记录数量，接近活跃地址单次返回的规模
+const benchHistorySize = 10000
+
+// benchHistory 生成按高度升序、末尾带少量内存池交易的历史记录
+func benchHistory() electrumx.ElectrumXHistoryResponse {
+	history := make(electrumx.ElectrumXHistoryResponse, 0, benchHistorySize)
+	for i := 0; i < benchHistorySize; i++ {
+		item := electrumx.ElectrumXHistoryItem{TxHash: fmt.Sprintf("%064x", i), Height: int64(800000 + i)}
+		if i >= benchHistorySize-10 {
+			item.Height, item.Fee = 0, 300
+		}
+		history = append(history, item)
+	}
+	return history
+}
+
+func BenchmarkSplitHistory(b *testing.B) {
+	history := benchHistory()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SplitHistory("hash", history)
+	}
+}
+
+func BenchmarkHistoryJSON(b *testing.B) {
+	response := SplitHistory("hash", benchHistory())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(response); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package script
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"ginproject/entity/transaction"
+)
+
+// 解码时需要识别的操作码
+const (
+	op16            = 0x60
+	opReturn        = 0x6a
+	opCheckMultisig = 0xae
+)
+
+// 裸多签脚本允许的最大公钥数
+const maxMultisigKeys = 15
+
+// DecodeScriptRequest 脚本解码请求，十六进制和ASM二选一
+type DecodeScriptRequest struct {
+	Hex string `json:"hex"`
+	Asm string `json:"asm"`
+}
+
+// Validate 校验请求，ASM会先转换为十六进制，校验通过后Hex为待解码的脚本
+func (req *DecodeScriptRequest) Validate() error {
+	req.Hex = strings.ToLower(strings.TrimSpace(req.Hex))
+	req.Asm = strings.TrimSpace(req.Asm)
+	switch {
+	case req.Hex == "" && req.Asm == "":
+		return fmt.Errorf("hex和asm必须提供一个")
+	case req.Hex != "" && req.Asm != "":
+		return fmt.Errorf("hex和asm只能提供一个")
+	case req.Asm != "":
+		scriptHex, err := AssembleASM(req.Asm)
+		if err != nil {
+			return err
+		}
+		req.Hex = scriptHex
+	}
+
+	if len(req.Hex)%2 != 0 {
+		return fmt.Errorf("脚本十六进制长度必须为偶数")
+	}
+	if len(req.Hex)/2 > MaxScriptSize {
+		return fmt.Errorf("脚本不能超过%d字节", MaxScriptSize)
+	}
+	if _, err := hex.DecodeString(req.Hex); err != nil {
+		return fmt.Errorf("脚本必须是有效的十六进制字符串")
+	}
+	return nil
+}
+
+// MultisigInfo 多签脚本的组成
+type MultisigInfo struct {
+	Required int      `json:"required"` // 所需签名数
+	Total    int      `json:"total"`    // 公钥总数
+	PubKeys  []string `json:"pubkeys"`
+	Address  string   `json:"address,omitempty"` // 多签地址
+}
+
+// DecodeScriptResponse 脚本解码结果
+type DecodeScriptResponse struct {
+	Hex        string             `json:"hex"`
+	Asm        string             `json:"asm"`
+	Type       string             `json:"type"`                  // 节点识别的标准脚本类型
+	Class      transaction.TxType `json:"class"`                 // 与交易分类一致的输出类型
+	TokenClass string             `json:"token_class,omitempty"` // 代币脚本分类
+	Holder     string             `json:"holder,omitempty"`      // 代币脚本中的持有者地址
+	Addresses  []string           `json:"addresses"`
+	ScriptHash string             `json:"script_hash"`
+	Multisig   *MultisigInfo      `json:"multisig,omitempty"`
+	OpReturn   []string           `json:"op_return,omitempty"` // OP_RETURN之后推送的数据，十六进制
+}
+
+// scriptOp 脚本中的一个操作，数据推送时Data为推送的内容
+type scriptOp struct {
+	Code byte
+	Data []byte
+}
+
+// parseOps 将脚本拆分为操作序列，推送长度超出脚本末尾时返回错误
+func parseOps(script []byte) ([]scriptOp, error) {
+	var ops []scriptOp
+	for i := 0; i < len(script); {
+		code := script[i]
+		i++
+		if code == 0x00 || code > opPushData4 {
+			ops = append(ops, scriptOp{Code: code})
+			continue
+		}
+
+		size := int(code)
+		var width int
+		switch code {
+		case opPushData1:
+			width = 1
+		case opPushData2:
+			width = 2
+		case opPushData4:
+			width = 4
+		}
+		if width > 0 {
+			if i+width > len(script) {
+				return nil, fmt.Errorf("第%d字节处的推送长度不完整", i-1)
+			}
+			size = 0
+			for b := width - 1; b >= 0; b-- {
+				size = size<<8 | int(script[i+b])
+			}
+			i += width
+		}
+		if size < 0 || i+size > len(script) {
+			return nil, fmt.Errorf("第%d字节处的推送超出脚本长度", i-1)
+		}
+		ops = append(ops, scriptOp{Code: code, Data: script[i : i+size]})
+		i += size
+	}
+	return ops, nil
+}
+
+// OpReturnData 返回脚本中第一个OP_RETURN之后的数据推送，不含OP_RETURN时返回nil
+func OpReturnData(scriptHex string) ([]string, error) {
+	ops, err := decodeOps(scriptHex)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if op.Code != opReturn {
+			continue
+		}
+		pushes := []string{}
+		for _, data := range ops[i+1:] {
+			if data.Code <= opPushData4 {
+				pushes = append(pushes, hex.EncodeToString(data.Data))
+			}
+		}
+		return pushes, nil
+	}
+	return nil, nil
+}
+
+// ParseMultisig 解析 <m> <公钥...> <n> OP_CHECKMULTISIG 形式的裸多签脚本，不是多签脚本时返回nil
+func ParseMultisig(scriptHex string) (*MultisigInfo, error) {
+	ops, err := decodeOps(scriptHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) < 4 || ops[len(ops)-1].Code != opCheckMultisig {
+		return nil, nil
+	}
+
+	required, ok := smallInt(ops[0])
+	if !ok {
+		return nil, nil
+	}
+	total, ok := smallInt(ops[len(ops)-2])
+	if !ok {
+		return nil, nil
+	}
+	keys := ops[1 : len(ops)-2]
+	if len(keys) != total || required < 1 || required > total || total > maxMultisigKeys {
+		return nil, nil
+	}
+
+	info := &MultisigInfo{Required: required, Total: total, PubKeys: make([]string, 0, total)}
+	for _, key := range keys {
+		if len(key.Data) != 33 && len(key.Data) != 65 {
+			return nil, nil
+		}
+		info.PubKeys = append(info.PubKeys, hex.EncodeToString(key.Data))
+	}
+	return info, nil
+}
+
+// decodeOps 解码十六进制脚本并拆分为操作序列
+func decodeOps(scriptHex string) ([]scriptOp, error) {
+	script, err := hex.DecodeString(scriptHex)
+	if err != nil {
+		return nil, fmt.Errorf("脚本必须是有效的十六进制字符串")
+	}
+	return parseOps(script)
+}
+
+// smallInt 读取OP_1到OP_16表示的数字
+func smallInt(op scriptOp) (int, bool) {
+	if op.Code < op1 || op.Code > op16 {
+		return 0, false
+	}
+	return int(op.Code-op1) + 1, true
+}
@@ -0,0 +1,86 @@
+package script
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAssembleASM(t *testing.T) {
+	pkh := "1234567890abcdef1234567890abcdef12345678"
+	cases := []struct {
+		asm  string
+		want string
+	}{
+		{"OP_DUP OP_HASH160 " + pkh + " OP_EQUALVERIFY OP_CHECKSIG", "76a914" + pkh + "88ac"},
+		// 节点把短推送输出为十进制数字
+		{"9 OP_PICK OP_TOALTSTACK", "59796b"},
+		{"1000 -1 0 -129", "02e8034f00028180"},
+		{"OP_FALSE OP_RETURN 56302043757272", "006a0756302043757272"},
+		{"OP_2 OP_16 op_checkmultisig", "5260ae"},
+	}
+	for _, c := range cases {
+		got, err := AssembleASM(c.asm)
+		if err != nil {
+			t.Fatalf("%q: %v", c.asm, err)
+		}
+		if got != c.want {
+			t.Fatalf("%q: 期望%s, 实际%s", c.asm, c.want, got)
+		}
+	}
+
+	if _, err := AssembleASM("OP_DUP OP_NOTANOPCODE"); err == nil {
+		t.Fatal("未知操作码应返回错误")
+	}
+	big, err := AssembleASM(strings.Repeat("ab", 300))
+	if err != nil || big[:6] != "4d2c01" {
+		t.Fatalf("超过255字节的推送应使用OP_PUSHDATA2: %s, %v", big[:6], err)
+	}
+}
+
+func TestDecodeScriptRequestValidate(t *testing.T) {
+	for _, req := range []DecodeScriptRequest{{}, {Hex: "76", Asm: "OP_DUP"}, {Hex: "7"}, {Hex: "zz"}} {
+		if err := req.Validate(); err == nil {
+			t.Fatalf("%+v 应校验失败", req)
+		}
+	}
+
+	req := DecodeScriptRequest{Asm: "OP_DUP OP_HASH160"}
+	if err := req.Validate(); err != nil || req.Hex != "76a9" {
+		t.Fatalf("ASM应转换为十六进制: %s, %v", req.Hex, err)
+	}
+}
+
+func TestParseMultisig(t *testing.T) {
+	key1 := "02" + strings.Repeat("11", 32)
+	key2 := "03" + strings.Repeat("22", 32)
+	info, err := ParseMultisig("5221" + key1 + "21" + key2 + "52ae")
+	if err != nil || info == nil {
+		t.Fatalf("应识别为多签脚本: %v", err)
+	}
+	if info.Required != 2 || info.Total != 2 || info.PubKeys[0] != key1 || info.PubKeys[1] != key2 {
+		t.Fatalf("多签组成不正确: %+v", info)
+	}
+
+	// 公钥数量与声明不一致
+	if info, _ := ParseMultisig("5121" + key1 + "52ae"); info != nil {
+		t.Fatalf("公钥数量不匹配时不应识别为多签: %+v", info)
+	}
+	if _, err := ParseMultisig("4c05aa"); err == nil {
+		t.Fatal("推送超出脚本长度应返回错误")
+	}
+}
+
+func TestOpReturnData(t *testing.T) {
+	pushes, err := OpReturnData("006a0756302043757272004c0201ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"56302043757272", "", "01ff"}
+	if strings.Join(pushes, ",") != strings.Join(want, ",") {
+		t.Fatalf("期望%v, 实际%v", want, pushes)
+	}
+
+	if pushes, _ := OpReturnData("76a988ac"); pushes != nil {
+		t.Fatalf("不含OP_RETURN时应返回nil: %v", pushes)
+	}
+}
@@ -0,0 +1,31 @@
+package script
+
+import "ginproject/entity/electrumx"
+
+// ScriptHistorySplitResponse 按确认状态拆分的脚本哈希历史
+type ScriptHistorySplitResponse struct {
+	ScriptHash       string                           `json:"script_hash"`
+	ConfirmedCount   int                              `json:"confirmed_count"`
+	UnconfirmedCount int                              `json:"unconfirmed_count"`
+	Confirmed        []electrumx.ElectrumXHistoryItem `json:"confirmed"`   // 已确认的交易，按区块高度升序
+	Unconfirmed      []electrumx.ElectrumXHistoryItem `json:"unconfirmed"` // 内存池中的交易，包含手续费
+}
+
+// SplitHistory 将ElectrumX返回的历史拆分为已确认和内存池两部分，高度小于等于0的记录视为内存池交易
+func SplitHistory(scriptHash string, history electrumx.ElectrumXHistoryResponse) *ScriptHistorySplitResponse {
+	response := &ScriptHistorySplitResponse{
+		ScriptHash:  scriptHash,
+		Confirmed:   make([]electrumx.ElectrumXHistoryItem, 0, len(history)),
+		Unconfirmed: []electrumx.ElectrumXHistoryItem{},
+	}
+	for _, item := range history {
+		if item.Height > 0 {
+			response.Confirmed = append(response.Confirmed, item)
+		} else {
+			response.Unconfirmed = append(response.Unconfirmed, item)
+		}
+	}
+	response.ConfirmedCount = len(response.Confirmed)
+	response.UnconfirmedCount = len(response.Unconfirmed)
+	return response
+}
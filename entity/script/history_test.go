@@ -0,0 +1,32 @@
+package script
+
+import (
+	"testing"
+
+	"ginproject/entity/electrumx"
+)
+
+func TestSplitHistory(t *testing.T) {
+	history := electrumx.ElectrumXHistoryResponse{
+		{TxHash: "a", Height: 100},
+		{TxHash: "b", Height: 101},
+		{TxHash: "c", Height: 0, Fee: 300},
+		{TxHash: "d", Height: -1, Fee: 450},
+	}
+
+	got := SplitHistory("hash", history)
+	if got.ConfirmedCount != 2 || got.UnconfirmedCount != 2 {
+		t.Fatalf("拆分数量: 已确认%d, 未确认%d", got.ConfirmedCount, got.UnconfirmedCount)
+	}
+	if got.Confirmed[0].TxHash != "a" || got.Confirmed[1].TxHash != "b" {
+		t.Fatalf("已确认交易顺序不正确: %+v", got.Confirmed)
+	}
+	if got.Unconfirmed[0].Fee != 300 || got.Unconfirmed[1].Height != -1 {
+		t.Fatalf("内存池交易不正确: %+v", got.Unconfirmed)
+	}
+
+	empty := SplitHistory("hash", nil)
+	if empty.Confirmed == nil || empty.Unconfirmed == nil {
+		t.Fatal("空历史应返回空列表而不是null")
+	}
+}
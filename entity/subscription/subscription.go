@@ -0,0 +1,89 @@
+package subscription
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"ginproject/entity/event"
+)
+
+// 客户端请求的操作
+const (
+	OpSubscribe   = "subscribe"
+	OpUnsubscribe = "unsubscribe"
+)
+
+// 订阅主题
+const (
+	TopicHeaders    = "headers"    // 新区块头
+	TopicBlocks     = "blocks"     // 新区块摘要，来自事件总线，需要开启事件发布
+	TopicAddress    = "address"    // 地址活动，按地址对应的脚本哈希订阅
+	TopicScriptHash = "scripthash" // 脚本哈希活动
+)
+
+// 服务端消息类型
+const (
+	MessageAck          = "ack"          // 请求处理成功
+	MessageError        = "error"        // 请求处理失败
+	MessageNotification = "notification" // 订阅通知
+)
+
+// ErrInvalidRequest 订阅请求无效
+var ErrInvalidRequest = errors.New("订阅请求无效")
+
+// Request 客户端通过WebSocket发送的请求
+type Request struct {
+	ID    int64  `json:"id"`              // 客户端请求ID，原样返回在对应的ack或error消息中
+	Op    string `json:"op"`              // subscribe或unsubscribe
+	Topic string `json:"topic"`           // headers、blocks、address或scripthash
+	Value string `json:"value,omitempty"` // 地址或脚本哈希，订阅区块头时为空
+}
+
+// Validate 验证请求参数的合法性，地址格式由业务层转换脚本哈希时校验
+func (r *Request) Validate() error {
+	if r.Op != OpSubscribe && r.Op != OpUnsubscribe {
+		return fmt.Errorf("%w: 不支持的操作 %q", ErrInvalidRequest, r.Op)
+	}
+	switch r.Topic {
+	case TopicHeaders, TopicBlocks:
+		if r.Value != "" {
+			return fmt.Errorf("%w: 订阅区块不需要value", ErrInvalidRequest)
+		}
+	case TopicAddress:
+		if r.Value == "" {
+			return fmt.Errorf("%w: 地址不能为空", ErrInvalidRequest)
+		}
+	case TopicScriptHash:
+		if b, err := hex.DecodeString(r.Value); err != nil || len(b) != 32 {
+			return fmt.Errorf("%w: 脚本哈希必须为64位16进制字符串", ErrInvalidRequest)
+		}
+	default:
+		return fmt.Errorf("%w: 不支持的主题 %q", ErrInvalidRequest, r.Topic)
+	}
+	return nil
+}
+
+// Header 区块头通知内容
+type Header struct {
+	Height int64  `json:"height"`
+	Hex    string `json:"hex"` // 区块头原始数据
+}
+
+// Message 服务端通过WebSocket发送的消息
+type Message struct {
+	ID         int64             `json:"id,omitempty"` // 对应的请求ID，通知消息为空
+	Type       string            `json:"type"`
+	Topic      string            `json:"topic,omitempty"`
+	Value      string            `json:"value,omitempty"` // 订阅时使用的地址或脚本哈希
+	Error      string            `json:"error,omitempty"`
+	Header     *Header           `json:"header,omitempty"`      // 区块头主题的当前链顶
+	Block      *event.BlockEvent `json:"block,omitempty"`       // 区块主题的新区块
+	ScriptHash string            `json:"script_hash,omitempty"` // 地址和脚本哈希主题对应的脚本哈希
+	Status     *string           `json:"status,omitempty"`      // ElectrumX的脚本哈希状态，历史为空时省略，变化即表示有新交易
+}
+
+// NewErrorMessage 创建请求失败消息
+func NewErrorMessage(id int64, err error) Message {
+	return Message{ID: id, Type: MessageError, Error: err.Error()}
+}
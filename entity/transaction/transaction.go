@@ -79,6 +79,10 @@ type Vout struct {
 	Value        float64       `json:"value"`
 	N            int           `json:"n"`
 	ScriptPubKey *ScriptPubKey `json:"scriptPubKey"`
+	ScriptKind   string        `json:"script_kind,omitempty"` // 输出脚本种类，无法识别时为unknown
+	// 代币输出的脚本分类和从脚本中解析出的持有者地址，节点无法解析地址时填充
+	ScriptClass      string   `json:"scriptClass,omitempty"`
+	DerivedAddresses []string `json:"derivedAddresses,omitempty"`
 }
 
 // TxDecodeResponse 解码交易响应
@@ -95,6 +99,7 @@ type TxDecodeResponse struct {
 	Time          int64  `json:"time,omitempty"`
 	BlockTime     int64  `json:"blocktime,omitempty"`
 	BlockHeight   int    `json:"blockheight,omitempty"`
+	TipHeight     int64  `json:"tip_height,omitempty"` // 计算确认数时使用的链顶高度
 	Hex           string `json:"hex"`
 }
 
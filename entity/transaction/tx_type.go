@@ -0,0 +1,177 @@
+package transaction
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// TxType 交易类型，实时路径的分类结果和数据库中的tx_type列使用同一组取值
+type TxType string
+
+const (
+	TxTypeP2PKH    TxType = "P2PKH"    // 普通转账
+	TxTypeTBC20    TxType = "TBC20"    // FT代币交易
+	TxTypeTBC721   TxType = "TBC721"   // NFT交易
+	TxTypeP2MS     TxType = "P2MS"     // 多签交易
+	TxTypePool     TxType = "POOL"     // 与流动池交互的FT交易
+	TxTypeCoinbase TxType = "COINBASE" // 挖矿交易
+	TxTypeUnknown  TxType = "UNKNOWN"  // 无法识别的交易
+)
+
+// AllTxTypes 全部交易类型
+var AllTxTypes = []TxType{
+	TxTypeP2PKH,
+	TxTypeTBC20,
+	TxTypeTBC721,
+	TxTypeP2MS,
+	TxTypePool,
+	TxTypeCoinbase,
+	TxTypeUnknown,
+}
+
+// ParseTxType 解析交易类型，忽略大小写和首尾空白
+func ParseTxType(s string) (TxType, error) {
+	normalized := TxType(strings.ToUpper(strings.TrimSpace(s)))
+	if normalized.IsValid() {
+		return normalized, nil
+	}
+	return TxTypeUnknown, fmt.Errorf("未知的交易类型: %q", s)
+}
+
+// IsValid 判断是否为已定义的交易类型
+func (t TxType) IsValid() bool {
+	for _, known := range AllTxTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回交易类型的字符串形式
+func (t TxType) String() string {
+	return string(t)
+}
+
+// MarshalText 序列化时未定义的取值统一输出为UNKNOWN
+func (t TxType) MarshalText() ([]byte, error) {
+	if t != "" && !t.IsValid() {
+		return []byte(TxTypeUnknown), nil
+	}
+	return []byte(t), nil
+}
+
+// UnmarshalText 反序列化时无法识别的取值按UNKNOWN处理
+func (t *TxType) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = ""
+		return nil
+	}
+	*t, _ = ParseTxType(string(text))
+	return nil
+}
+
+// Value 实现driver.Valuer
+func (t TxType) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// Scan 实现sql.Scanner，历史数据中的小写或未知取值在读取时规范化
+func (t *TxType) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = ""
+	case string:
+		return t.UnmarshalText([]byte(v))
+	case []byte:
+		return t.UnmarshalText(v)
+	default:
+		return fmt.Errorf("无法将%T转换为TxType", value)
+	}
+	return nil
+}
+
+// TxOutputScript 参与分类的输出脚本
+type TxOutputScript struct {
+	Type string // scriptPubKey.type
+	Asm  string // scriptPubKey.asm
+}
+
+const (
+	// FT代码脚本前缀
+	ftCodeScriptPrefix = "9 OP_PICK OP_TOALTSTACK"
+	// 由池合约持有的FT代码脚本后缀
+	ftPoolHolderSuffix = "01 32436f6465"
+)
+
+// ClassifyOutput 判断单个输出的类型，普通地址输出返回P2PKH，无法识别时返回UNKNOWN
+func ClassifyOutput(out TxOutputScript) TxType {
+	switch {
+	case out.Type == "pubkeyhash":
+		return TxTypeP2PKH
+	case strings.HasPrefix(out.Asm, ftCodeScriptPrefix):
+		if strings.HasSuffix(out.Asm, ftPoolHolderSuffix) {
+			return TxTypePool
+		}
+		return TxTypeTBC20
+	case strings.HasPrefix(out.Asm, "OP_RETURN"),
+		strings.HasPrefix(out.Asm, "0 OP_RETURN"),
+		strings.HasPrefix(out.Asm, "1 OP_PICK"):
+		return TxTypeTBC721
+	case strings.HasSuffix(out.Asm, "OP_CHECKMULTISIG"):
+		return TxTypeP2MS
+	default:
+		return TxTypeUnknown
+	}
+}
+
+// ScriptKindUnknown 无法识别的输出脚本种类
+const ScriptKindUnknown = "unknown"
+
+// ScriptKind 返回单个输出的脚本种类，取值为ClassifyOutput结果的小写形式，无法识别时为unknown
+func ScriptKind(out TxOutputScript) string {
+	return strings.ToLower(ClassifyOutput(out).String())
+}
+
+// ClassifyTx 根据输入和输出判断交易类型
+// 挖矿交易优先；否则以第一个合约类输出决定类型，FT交易中只要有输出由池持有即视为池交易；
+// 没有合约类输出时，全部为普通地址输出才是P2PKH，含有无法识别的输出时为UNKNOWN，避免新脚本模板被当作普通转账
+func ClassifyTx(isCoinbase bool, outputs []TxOutputScript) TxType {
+	if isCoinbase {
+		return TxTypeCoinbase
+	}
+
+	detected := TxType("")
+	hasP2PKH := false
+	hasUnknown := false
+	hasPool := false
+	for _, out := range outputs {
+		outType := ClassifyOutput(out)
+		switch outType {
+		case TxTypeP2PKH:
+			hasP2PKH = true
+			continue
+		case TxTypeUnknown:
+			hasUnknown = true
+			continue
+		case TxTypePool:
+			hasPool = true
+			outType = TxTypeTBC20
+		}
+		if detected == "" {
+			detected = outType
+		}
+	}
+
+	switch {
+	case detected == TxTypeTBC20 && hasPool:
+		return TxTypePool
+	case detected != "":
+		return detected
+	case hasP2PKH && !hasUnknown:
+		return TxTypeP2PKH
+	default:
+		return TxTypeUnknown
+	}
+}
@@ -0,0 +1,148 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// 各类输出脚本样例
+var (
+	p2pkhOut  = TxOutputScript{Type: "pubkeyhash", Asm: "OP_DUP OP_HASH160 0123 OP_EQUALVERIFY OP_CHECKSIG"}
+	ftOut     = TxOutputScript{Type: "nonstandard", Asm: ftCodeScriptPrefix + " 1234 00 32436f6465"}
+	ftPoolOut = TxOutputScript{Type: "nonstandard", Asm: ftCodeScriptPrefix + " 1234 01 32436f6465"}
+	nftOut    = TxOutputScript{Type: "nonstandard", Asm: "1 OP_PICK 1234"}
+	tapeOut   = TxOutputScript{Type: "nulldata", Asm: "0 OP_RETURN 1234"}
+	msOut     = TxOutputScript{Type: "nonstandard", Asm: "2 02aa 02bb 02cc 3 OP_CHECKMULTISIG"}
+	otherOut  = TxOutputScript{Type: "nonstandard", Asm: "OP_TRUE"}
+)
+
+func TestParseTxType(t *testing.T) {
+	for _, txType := range AllTxTypes {
+		parsed, err := ParseTxType(txType.String())
+		if err != nil || parsed != txType {
+			t.Errorf("%s 解析结果: %s, %v", txType, parsed, err)
+		}
+	}
+
+	tests := []struct {
+		input   string
+		want    TxType
+		wantErr bool
+	}{
+		{input: "p2pkh", want: TxTypeP2PKH},
+		{input: " tbc20 ", want: TxTypeTBC20},
+		{input: "Pool", want: TxTypePool},
+		{input: "", want: TxTypeUnknown, wantErr: true},
+		{input: "P2SH", want: TxTypeUnknown, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTxType(tt.input)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseTxType(%q) = %s, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestClassifyOutput(t *testing.T) {
+	tests := []struct {
+		out  TxOutputScript
+		want TxType
+	}{
+		{p2pkhOut, TxTypeP2PKH},
+		{ftOut, TxTypeTBC20},
+		{ftPoolOut, TxTypePool},
+		{nftOut, TxTypeTBC721},
+		{tapeOut, TxTypeTBC721},
+		{TxOutputScript{Asm: "OP_RETURN 1234"}, TxTypeTBC721},
+		{msOut, TxTypeP2MS},
+		{otherOut, TxTypeUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyOutput(tt.out); got != tt.want {
+			t.Errorf("ClassifyOutput(%q) = %s, 期望 %s", tt.out.Asm, got, tt.want)
+		}
+	}
+	if got := ScriptKind(otherOut); got != ScriptKindUnknown {
+		t.Errorf("ScriptKind(%q) = %s, 期望 %s", otherOut.Asm, got, ScriptKindUnknown)
+	}
+}
+
+func TestClassifyTx(t *testing.T) {
+	tests := []struct {
+		name     string
+		coinbase bool
+		outputs  []TxOutputScript
+		want     TxType
+	}{
+		{"普通转账", false, []TxOutputScript{p2pkhOut, p2pkhOut}, TxTypeP2PKH},
+		{"FT转账", false, []TxOutputScript{ftOut, tapeOut, p2pkhOut}, TxTypeTBC20},
+		{"池交易", false, []TxOutputScript{ftOut, tapeOut, ftPoolOut, p2pkhOut}, TxTypePool},
+		{"NFT转账", false, []TxOutputScript{nftOut, tapeOut, p2pkhOut}, TxTypeTBC721},
+		{"多签", false, []TxOutputScript{p2pkhOut, msOut}, TxTypeP2MS},
+		{"第一个合约类输出决定类型", false, []TxOutputScript{msOut, ftOut}, TxTypeP2MS},
+		{"NFT交易中的池输出不改变类型", false, []TxOutputScript{nftOut, ftPoolOut}, TxTypeTBC721},
+		{"挖矿交易", true, []TxOutputScript{p2pkhOut}, TxTypeCoinbase},
+		{"无法识别", false, []TxOutputScript{otherOut}, TxTypeUnknown},
+		{"普通转账中含有无法识别的输出", false, []TxOutputScript{p2pkhOut, otherOut}, TxTypeUnknown},
+		{"合约类输出优先于无法识别的输出", false, []TxOutputScript{otherOut, nftOut, p2pkhOut}, TxTypeTBC721},
+		{"没有输出", false, nil, TxTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyTx(tt.coinbase, tt.outputs)
+			if got != tt.want {
+				t.Fatalf("ClassifyTx = %s, 期望 %s", got, tt.want)
+			}
+
+			// 实时路径的分类结果写入数据库后读回必须保持一致
+			var stored TxType
+			if err := stored.Scan(got.String()); err != nil || stored != got {
+				t.Fatalf("数据库往返结果不一致: %s, %v", stored, err)
+			}
+		})
+	}
+}
+
+func TestTxTypeScanAndJSON(t *testing.T) {
+	var scanned TxType
+	for _, value := range []interface{}{"tbc721", []byte("TBC721")} {
+		if err := scanned.Scan(value); err != nil || scanned != TxTypeTBC721 {
+			t.Errorf("Scan(%v) = %s, %v", value, scanned, err)
+		}
+	}
+	if err := scanned.Scan("legacy"); err != nil || scanned != TxTypeUnknown {
+		t.Errorf("未知取值应规范化为UNKNOWN，实际: %s, %v", scanned, err)
+	}
+	if err := scanned.Scan(nil); err != nil || scanned != "" {
+		t.Errorf("NULL应解析为空值，实际: %q, %v", scanned, err)
+	}
+	if err := scanned.Scan(1); err == nil {
+		t.Error("非字符串取值应返回错误")
+	}
+
+	type item struct {
+		TxType TxType `json:"tx_type,omitempty"`
+	}
+	data, err := json.Marshal(item{TxType: TxTypePool})
+	if err != nil || string(data) != `{"tx_type":"POOL"}` {
+		t.Errorf("序列化结果: %s, %v", data, err)
+	}
+	data, _ = json.Marshal(item{})
+	if string(data) != `{}` {
+		t.Errorf("空类型应被省略，实际: %s", data)
+	}
+
+	var decoded item
+	if err := json.Unmarshal([]byte(`{"tx_type":"p2ms"}`), &decoded); err != nil || decoded.TxType != TxTypeP2MS {
+		t.Errorf("反序列化结果: %s, %v", decoded.TxType, err)
+	}
+}
+
+func BenchmarkClassifyTx(b *testing.B) {
+	outputs := []TxOutputScript{ftOut, p2pkhOut, tapeOut, p2pkhOut}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ClassifyTx(false, outputs)
+	}
+}
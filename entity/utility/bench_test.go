@@ -0,0 +1,73 @@
+package utility
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// 基准测试使用的样例数据
+const (
+	benchAddress  = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	benchPkHash   = "62e907b15cbf27d5425399ebf6f0fb50ebb88f18"
+	benchPoolTape = "0 OP_RETURN 01 " +
+		"e803000000000000" + "d007000000000000" + "b80b000000000000" + " 4654617065"
+)
+
+var (
+	benchFtCodeScript = strings.Repeat("51", 64) + benchPkHash + "00" + ftCodeSuffix
+	benchNftScript    = p2pkhPrefix + benchPkHash + nftHoldSuffix
+	benchTapeJson     = hex.EncodeToString([]byte(`{"name":"Bench NFT","symbol":"BN","description":"` +
+		strings.Repeat("x", 256) + `","attributes":{"rarity":"rare","level":7,"tags":["a","b","c"]}}`))
+)
+
+func BenchmarkClassifyScript(b *testing.B) {
+	b.Run("ft_code", func(b *testing.B) {
+		if ClassifyScript(benchFtCodeScript).Class != ScriptClassFtCode {
+			b.Fatal("样例脚本未识别为FT代码脚本")
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ClassifyScript(benchFtCodeScript)
+		}
+	})
+	b.Run("nft_hold", func(b *testing.B) {
+		if ClassifyScript(benchNftScript).Class != ScriptClassNftHold {
+			b.Fatal("样例脚本未识别为NFT持有脚本")
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ClassifyScript(benchNftScript)
+		}
+	})
+}
+
+func BenchmarkGetPoolBalanceFromTapeASM(b *testing.B) {
+	if _, _, _, err := GetPoolBalanceFromTapeASM(benchPoolTape); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _, _ = GetPoolBalanceFromTapeASM(benchPoolTape)
+	}
+}
+
+func BenchmarkHexToJson(b *testing.B) {
+	if _, err := HexToJson(benchTapeJson); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = HexToJson(benchTapeJson)
+	}
+}
+
+func BenchmarkAddressToScriptHash(b *testing.B) {
+	if _, err := AddressToScriptHash(benchAddress); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = AddressToScriptHash(benchAddress)
+	}
+}
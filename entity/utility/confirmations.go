@@ -0,0 +1,38 @@
+package utility
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// MaxMinConfirmations min_confirmations参数的上限
+const MaxMinConfirmations = 1000000
+
+// ErrInvalidMinConfirmations 最少确认数参数无效
+var ErrInvalidMinConfirmations = errors.New("min_confirmations参数无效")
+
+// ValidateMinConfirmations 校验最少确认数在0到上限之间
+func ValidateMinConfirmations(minConfirmations int64) error {
+	if minConfirmations < 0 || minConfirmations > MaxMinConfirmations {
+		return fmt.Errorf("%w: 取值范围为0到%d", ErrInvalidMinConfirmations, MaxMinConfirmations)
+	}
+	return nil
+}
+
+// ParseMinConfirmations 解析min_confirmations查询参数，未指定时为0，即不过滤
+func ParseMinConfirmations(raw string) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	minConfirmations, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidMinConfirmations
+	}
+	return minConfirmations, ValidateMinConfirmations(minConfirmations)
+}
+
+// WithinHeightLimit 判断区块高度是否不超过高度上限，maxHeight小于0表示不限制；未确认(height<1)在限制下不满足
+func WithinHeightLimit(height, maxHeight int64) bool {
+	return maxHeight < 0 || (height >= 1 && height <= maxHeight)
+}
@@ -0,0 +1,52 @@
+package utility
+
+import (
+	"math/big"
+	"strings"
+)
+
+// FormatUnits 将最小单位的整数金额格式化为十进制字符串
+// decimals为小数位数，结果去掉末尾多余的0；signed为true时正数带"+"前缀，0始终格式化为"0"
+func FormatUnits(amount int64, decimals int, signed bool) string {
+	value := new(big.Rat).SetFrac(big.NewInt(amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return formatRat(value, decimals, signed)
+}
+
+// FormatRat 将有理数按指定小数位数四舍五入后格式化为十进制字符串，用于多步计算后的金额
+func FormatRat(value *big.Rat, decimals int, signed bool) string {
+	return formatRat(value, decimals, signed)
+}
+
+// FormatDecimal 将浮点金额按指定小数位数四舍五入后格式化为十进制字符串
+// 不会输出科学计数法，格式规则与FormatUnits一致
+func FormatDecimal(value float64, decimals int, signed bool) string {
+	rat := new(big.Rat)
+	if rat.SetFloat64(value) == nil {
+		// NaN或Inf
+		return "0"
+	}
+	return formatRat(rat, decimals, signed)
+}
+
+// formatRat 按小数位数格式化有理数并去掉末尾的0
+func formatRat(value *big.Rat, decimals int, signed bool) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	str := value.FloatString(decimals)
+	if strings.Contains(str, ".") {
+		str = strings.TrimRight(str, "0")
+		str = strings.TrimSuffix(str, ".")
+	}
+
+	// 舍入后为0时统一输出"0"，避免出现"-0"或"+0"
+	if strings.TrimLeft(str, "-") == "0" {
+		return "0"
+	}
+
+	if signed && !strings.HasPrefix(str, "-") {
+		return "+" + str
+	}
+	return str
+}
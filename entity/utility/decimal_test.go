@@ -0,0 +1,63 @@
+package utility
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		decimals int
+		signed   bool
+		want     string
+	}{
+		{"零", 0, 6, true, "0"},
+		{"零无符号", 0, 6, false, "0"},
+		{"正数带符号", 1500000, 6, true, "+1.5"},
+		{"负数", -1500000, 6, true, "-1.5"},
+		{"整数去掉小数点", 10000000, 6, true, "+10"},
+		{"最小单位", 1, 6, false, "0.000001"},
+		{"最小负单位", -1, 6, true, "-0.000001"},
+		{"无小数位", 42, 0, false, "42"},
+		{"大额不使用科学计数法", math.MaxInt64, 6, false, "9223372036854.775807"},
+		{"最小值", math.MinInt64, 8, true, "-92233720368.54775808"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatUnits(tt.amount, tt.decimals, tt.signed); got != tt.want {
+				t.Errorf("FormatUnits(%d, %d, %v) = %q, want %q", tt.amount, tt.decimals, tt.signed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		decimals int
+		signed   bool
+		want     string
+	}{
+		{"零", 0, 8, false, "0"},
+		{"负零", math.Copysign(0, -1), 6, true, "0"},
+		{"舍入为零的负数", -0.0000001, 6, true, "0"},
+		{"正数带符号", 0.25, 6, true, "+0.25"},
+		{"负数", -3.125, 6, true, "-3.125"},
+		{"四舍五入", 0.1234567, 6, false, "0.123457"},
+		{"二进制误差", 0.1 + 0.2, 8, false, "0.3"},
+		{"大额不使用科学计数法", 1e21, 2, false, "1000000000000000000000"},
+		{"NaN", math.NaN(), 6, false, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDecimal(tt.value, tt.decimals, tt.signed); got != tt.want {
+				t.Errorf("FormatDecimal(%v, %d, %v) = %q, want %q", tt.value, tt.decimals, tt.signed, got, tt.want)
+			}
+		})
+	}
+}
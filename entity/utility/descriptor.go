@@ -0,0 +1,337 @@
+package utility
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// P2SH地址版本号
+const (
+	mainnetScriptHashVersion = 0x05
+	testnetScriptHashVersion = 0xc4
+)
+
+// P2SH赎回脚本的最大长度
+const maxRedeemScriptSize = 520
+
+// 描述符校验和使用的字符集，见BIP380
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// ErrInvalidDescriptor 输出描述符格式无效或不受支持
+var ErrInvalidDescriptor = errors.New("无效的输出描述符")
+
+// DescriptorOutput 描述符在某个序号展开得到的输出
+type DescriptorOutput struct {
+	Index      uint32 `json:"index"`       // 派生序号，不含通配符的描述符为0
+	Address    string `json:"address"`     // P2PKH或P2SH地址
+	Script     string `json:"script"`      // 输出脚本的十六进制
+	ScriptHash string `json:"script_hash"` // ElectrumX使用的脚本哈希
+}
+
+// Descriptor 解析后的输出描述符，支持pkh(KEY)、sh(multi(k,KEY,...))和sh(sortedmulti(k,KEY,...))
+// KEY可以是十六进制公钥，或带可选来源信息和非硬化派生路径的扩展公钥，路径最后一级可以是通配符*
+type Descriptor struct {
+	keys      []descriptorKey
+	multisig  bool
+	sorted    bool
+	threshold int
+	testnet   bool
+}
+
+// descriptorKey 描述符中的单个公钥表达式
+type descriptorKey struct {
+	pubKey   []byte          // 固定公钥，扩展公钥时为空
+	xpub     *ExtendedPubKey // 已按通配符之前的路径派生的扩展公钥
+	wildcard bool            // 路径最后一级为*
+}
+
+// ParseDescriptor 解析输出描述符，带有#校验和时校验其正确性
+func ParseDescriptor(desc string) (*Descriptor, error) {
+	desc = strings.TrimSpace(desc)
+	if body, checksum, ok := strings.Cut(desc, "#"); ok {
+		want, err := DescriptorChecksum(body)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != want {
+			return nil, fmt.Errorf("%w: 校验和错误，期望%s", ErrInvalidDescriptor, want)
+		}
+		desc = body
+	}
+
+	if inner, ok := unwrapDescriptor(desc, "pkh"); ok {
+		key, err := parseDescriptorKey(inner)
+		if err != nil {
+			return nil, err
+		}
+		d := &Descriptor{keys: []descriptorKey{key}}
+		d.testnet = key.xpub != nil && key.xpub.testnet
+		return d, nil
+	}
+
+	inner, ok := unwrapDescriptor(desc, "sh")
+	if !ok {
+		return nil, fmt.Errorf("%w: 只支持pkh和sh(multi)", ErrInvalidDescriptor)
+	}
+	d := &Descriptor{multisig: true}
+	args, ok := unwrapDescriptor(inner, "multi")
+	if !ok {
+		if args, ok = unwrapDescriptor(inner, "sortedmulti"); !ok {
+			return nil, fmt.Errorf("%w: sh只支持multi和sortedmulti", ErrInvalidDescriptor)
+		}
+		d.sorted = true
+	}
+
+	parts := strings.Split(args, ",")
+	if len(parts) < 2 || len(parts) > 17 {
+		return nil, fmt.Errorf("%w: 多签公钥数量必须在1到16之间", ErrInvalidDescriptor)
+	}
+	threshold, err := strconv.Atoi(parts[0])
+	if err != nil || threshold < 1 || threshold > len(parts)-1 {
+		return nil, fmt.Errorf("%w: 无效的签名数量%s", ErrInvalidDescriptor, parts[0])
+	}
+	d.threshold = threshold
+	for _, part := range parts[1:] {
+		key, err := parseDescriptorKey(part)
+		if err != nil {
+			return nil, err
+		}
+		if key.xpub != nil && key.xpub.testnet {
+			d.testnet = true
+		}
+		d.keys = append(d.keys, key)
+	}
+	return d, nil
+}
+
+// IsRange 描述符是否包含通配符，需要按序号范围展开
+func (d *Descriptor) IsRange() bool {
+	for _, key := range d.keys {
+		if key.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Expand 按序号展开描述符，不含通配符的描述符忽略index
+func (d *Descriptor) Expand(index uint32) (*DescriptorOutput, error) {
+	if !d.IsRange() {
+		index = 0
+	}
+	pubKeys := make([][]byte, 0, len(d.keys))
+	for _, key := range d.keys {
+		pubKey, err := key.derive(index)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	var script []byte
+	var address string
+	if d.multisig {
+		redeemScript := multisigScript(d.threshold, pubKeys, d.sorted)
+		if len(redeemScript) > maxRedeemScriptSize {
+			return nil, fmt.Errorf("%w: 赎回脚本超过%d字节", ErrInvalidDescriptor, maxRedeemScriptSize)
+		}
+		hash := hash160(redeemScript)
+		script = append(append([]byte{0xa9, 0x14}, hash...), 0x87)
+		version := byte(mainnetScriptHashVersion)
+		if d.testnet {
+			version = testnetScriptHashVersion
+		}
+		address = base58.CheckEncode(hash, version)
+	} else {
+		hash := hash160(pubKeys[0])
+		script = append(append([]byte{0x76, 0xa9, 0x14}, hash...), 0x88, 0xac)
+		version := byte(mainnetPubKeyHashVersion)
+		if d.testnet {
+			version = testnetPubKeyHashVersion
+		}
+		address = base58.CheckEncode(hash, version)
+	}
+
+	return &DescriptorOutput{
+		Index:      index,
+		Address:    address,
+		Script:     hex.EncodeToString(script),
+		ScriptHash: scriptHashOf(script),
+	}, nil
+}
+
+// DescriptorChecksum 计算描述符的8位校验和，算法见BIP380
+func DescriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("%w: 包含无效字符%q", ErrInvalidDescriptor, ch)
+		}
+		c = descriptorPolymod(c, pos&31)
+		cls = cls*3 + pos>>5
+		if clsCount++; clsCount == 3 {
+			c = descriptorPolymod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = descriptorPolymod(c, cls)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(checksum), nil
+}
+
+// descriptorPolymod 校验和使用的BCH码多项式运算
+func descriptorPolymod(c uint64, value int) uint64 {
+	c0 := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(value)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// unwrapDescriptor 去掉name(...)外层，返回括号内的内容
+func unwrapDescriptor(desc, name string) (string, bool) {
+	if !strings.HasPrefix(desc, name+"(") || !strings.HasSuffix(desc, ")") {
+		return "", false
+	}
+	return desc[len(name)+1 : len(desc)-1], true
+}
+
+// parseDescriptorKey 解析公钥表达式，来源信息[fingerprint/path]只用于钱包记录，忽略其内容
+func parseDescriptorKey(expr string) (descriptorKey, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "[") {
+		end := strings.Index(expr, "]")
+		if end < 0 {
+			return descriptorKey{}, fmt.Errorf("%w: 来源信息缺少]", ErrInvalidDescriptor)
+		}
+		expr = expr[end+1:]
+	}
+
+	segments := strings.Split(expr, "/")
+	if len(segments) == 1 && !strings.HasPrefix(expr, "xpub") && !strings.HasPrefix(expr, "tpub") {
+		pubKey, err := hex.DecodeString(expr)
+		if err != nil || !validPubKey(pubKey) {
+			return descriptorKey{}, fmt.Errorf("%w: 无效的公钥%s", ErrInvalidDescriptor, expr)
+		}
+		return descriptorKey{pubKey: pubKey}, nil
+	}
+
+	xpub, err := ParseExtendedPubKey(segments[0])
+	if err != nil {
+		return descriptorKey{}, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+	}
+	key := descriptorKey{xpub: xpub}
+	for i, segment := range segments[1:] {
+		if segment == "*" && i == len(segments)-2 {
+			key.wildcard = true
+			break
+		}
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || index >= hardenedKeyStart {
+			return descriptorKey{}, fmt.Errorf("%w: 扩展公钥只支持非硬化派生路径，无效的路径%s", ErrInvalidDescriptor, segment)
+		}
+		if key.xpub, err = key.xpub.Child(uint32(index)); err != nil {
+			return descriptorKey{}, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+	}
+	return key, nil
+}
+
+// derive 返回序号index对应的公钥，固定公钥和不含通配符的扩展公钥忽略index
+func (k descriptorKey) derive(index uint32) ([]byte, error) {
+	if k.xpub == nil {
+		return k.pubKey, nil
+	}
+	if !k.wildcard {
+		return k.xpub.PubKey(), nil
+	}
+	child, err := k.xpub.Child(index)
+	if err != nil {
+		return nil, err
+	}
+	return child.PubKey(), nil
+}
+
+// validPubKey 判断是否为压缩或未压缩格式的公钥
+func validPubKey(key []byte) bool {
+	switch len(key) {
+	case 33:
+		_, _, err := decompressPubKey(key)
+		return err == nil
+	case 65:
+		return key[0] == 0x04
+	default:
+		return false
+	}
+}
+
+// multisigScript 构造k-of-n的CHECKMULTISIG脚本，sorted为true时按公钥字节序排列
+func multisigScript(threshold int, pubKeys [][]byte, sorted bool) []byte {
+	if sorted {
+		pubKeys = append([][]byte(nil), pubKeys...)
+		for i := 1; i < len(pubKeys); i++ {
+			for j := i; j > 0 && bytes.Compare(pubKeys[j], pubKeys[j-1]) < 0; j-- {
+				pubKeys[j], pubKeys[j-1] = pubKeys[j-1], pubKeys[j]
+			}
+		}
+	}
+	script := []byte{byte(0x50 + threshold)}
+	for _, pubKey := range pubKeys {
+		script = append(script, byte(len(pubKey)))
+		script = append(script, pubKey...)
+	}
+	return append(script, byte(0x50+len(pubKeys)), 0xae)
+}
+
+// hash160 计算RIPEMD160(SHA256(data))
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+// scriptHashOf 计算ElectrumX使用的脚本哈希，即脚本SHA256的小端序十六进制
+func scriptHashOf(script []byte) string {
+	hash := sha256.Sum256(script)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,86 @@
+package utility
+
+import (
+	"errors"
+	"testing"
+)
+
+// secp256k1上2G和3G的压缩公钥
+const (
+	vectorPubKey2G = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+	vectorPubKey3G = "02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	// BIP380测试向量
+	checksum, err := DescriptorChecksum("raw(deadbeef)")
+	if err != nil || checksum != "89f8spxm" {
+		t.Fatalf("校验和 = %q, %v, 期望89f8spxm", checksum, err)
+	}
+
+	desc := "pkh(" + vectorPubKey2G + ")"
+	checksum, _ = DescriptorChecksum(desc)
+	if _, err := ParseDescriptor(desc + "#" + checksum); err != nil {
+		t.Fatalf("正确的校验和解析失败: %v", err)
+	}
+	if _, err := ParseDescriptor(desc + "#qqqqqqqq"); !errors.Is(err, ErrInvalidDescriptor) {
+		t.Fatalf("错误的校验和期望ErrInvalidDescriptor，实际: %v", err)
+	}
+}
+
+func TestDescriptorPkh(t *testing.T) {
+	d, err := ParseDescriptor("pkh(" + vectorPubKey2G + ")")
+	if err != nil {
+		t.Fatalf("解析描述符失败: %v", err)
+	}
+	output, err := d.Expand(5)
+	if err != nil {
+		t.Fatalf("展开描述符失败: %v", err)
+	}
+	if d.IsRange() || output.Index != 0 || output.Address != "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP" {
+		t.Fatalf("展开结果 = %+v", output)
+	}
+
+	// 扩展公钥通配符展开的地址与按路径派生的地址一致
+	d, err = ParseDescriptor("pkh([d34db33f/44'/0'/0']" + vectorXpub0H + "/1/*)")
+	if err != nil {
+		t.Fatalf("解析描述符失败: %v", err)
+	}
+	addresses, _ := DeriveXpubAddresses(vectorXpub0H, 1, 3)
+	for i, address := range addresses {
+		output, err := d.Expand(uint32(i))
+		if err != nil || output.Address != address {
+			t.Fatalf("序号%d展开为%+v, %v, 期望%s", i, output, err, address)
+		}
+	}
+}
+
+func TestDescriptorMultisig(t *testing.T) {
+	multi, err := ParseDescriptor("sh(sortedmulti(1," + vectorPubKey3G + "," + vectorPubKey2G + "))")
+	if err != nil {
+		t.Fatalf("解析描述符失败: %v", err)
+	}
+	sorted, _ := multi.Expand(0)
+	if sorted.Address[0] != '3' || len(sorted.Script) != 46 {
+		t.Fatalf("P2SH展开结果 = %+v", sorted)
+	}
+
+	// sortedmulti与按字节序排列公钥的multi等价
+	plain, _ := ParseDescriptor("sh(multi(1," + vectorPubKey2G + "," + vectorPubKey3G + "))")
+	if output, _ := plain.Expand(0); output.ScriptHash != sorted.ScriptHash {
+		t.Fatalf("sortedmulti = %s, multi = %s", sorted.ScriptHash, output.ScriptHash)
+	}
+
+	invalid := []string{
+		"wpkh(" + vectorPubKey2G + ")",
+		"sh(multi(3," + vectorPubKey2G + "," + vectorPubKey3G + "))",
+		"pkh(" + vectorXpub0H + "/0'/*)",
+		"pkh(" + vectorXpub0H + "/*/0)",
+		"pkh(02deadbeef)",
+	}
+	for _, desc := range invalid {
+		if _, err := ParseDescriptor(desc); !errors.Is(err, ErrInvalidDescriptor) {
+			t.Errorf("ParseDescriptor(%q) 期望ErrInvalidDescriptor，实际: %v", desc, err)
+		}
+	}
+}
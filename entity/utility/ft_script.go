@@ -0,0 +1,43 @@
+package utility
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// 代码脚本末尾组合脚本和"Code"标记的十六进制长度
+const ftCodeTailLen = ftCombineScriptLen + len(ftCodeSuffix)
+
+// 磁带脚本中代币数量字段的位置：OP_FALSE OP_RETURN PUSH48之后的6个8字节小端序数量
+const (
+	ftTapeAmountStart = 6
+	ftTapeAmountEnd   = ftTapeAmountStart + 6*16
+)
+
+// FtHolderScriptHash 将代币代码脚本中的组合脚本替换为持有者的组合脚本，返回ElectrumX使用的脚本哈希
+func FtHolderScriptHash(codeScript, combineScript string) (string, error) {
+	if len(codeScript) < ftCodeTailLen {
+		return "", fmt.Errorf("代码脚本长度不足: %d", len(codeScript))
+	}
+	if len(combineScript) != ftCombineScriptLen {
+		return "", fmt.Errorf("无效的组合脚本 '%s'", combineScript)
+	}
+	return ConvertStrToSha256(codeScript[:len(codeScript)-ftCodeTailLen] + combineScript + ftCodeSuffix)
+}
+
+// FtTapeAmount 解析磁带脚本中记录的代币数量，脚本过短或格式无效时返回0
+func FtTapeAmount(tapeScript string) uint64 {
+	if len(tapeScript) < ftTapeAmountEnd {
+		return 0
+	}
+	data, err := hex.DecodeString(tapeScript[ftTapeAmountStart:ftTapeAmountEnd])
+	if err != nil {
+		return 0
+	}
+	var amount uint64
+	for i := 0; i < len(data); i += 8 {
+		amount += binary.LittleEndian.Uint64(data[i : i+8])
+	}
+	return amount
+}
@@ -0,0 +1,36 @@
+package utility
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFtTapeAmount(t *testing.T) {
+	// 第一段为1000，第三段为5，其余为0
+	amounts := "e803000000000000" + strings.Repeat("0", 16) + "0500000000000000" + strings.Repeat("0", 48)
+	tape := "006a30" + amounts + "0000"
+	if got := FtTapeAmount(tape); got != 1005 {
+		t.Fatalf("FtTapeAmount = %d, 期望1005", got)
+	}
+	if got := FtTapeAmount("006a30e803"); got != 0 {
+		t.Fatalf("过短的脚本 = %d, 期望0", got)
+	}
+}
+
+func TestFtHolderScriptHash(t *testing.T) {
+	trait := "51"
+	creator := strings.Repeat("11", 20) + "00"
+	holder := strings.Repeat("22", 20) + "00"
+
+	got, err := FtHolderScriptHash(trait+creator+ftCodeSuffix, holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ConvertStrToSha256(trait + holder + ftCodeSuffix)
+	if got != want {
+		t.Fatalf("FtHolderScriptHash = %s, 期望 %s", got, want)
+	}
+	if _, err := FtHolderScriptHash(ftCodeSuffix, holder); err == nil {
+		t.Fatal("代码脚本过短时应返回错误")
+	}
+}
@@ -0,0 +1,103 @@
+package utility
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrTapeJsonTooLarge   = errors.New("磁带数据超过大小限制")
+	ErrTapeJsonTooDeep    = errors.New("磁带数据嵌套层数超过限制")
+	ErrTapeJsonStringLong = errors.New("磁带数据包含超长字符串")
+	ErrTapeJsonNotObject  = errors.New("磁带数据不是JSON对象")
+)
+
+// TapeJsonLimits 解析链上磁带JSON时的限制
+type TapeJsonLimits struct {
+	MaxBytes     int // 解码后的最大字节数
+	MaxDepth     int // 对象和数组的最大嵌套层数
+	MaxStringLen int // 单个字符串（含键名）的最大字节数
+}
+
+// DefaultTapeJsonLimits 默认限制，足以容纳正常的代币和NFT元数据
+var DefaultTapeJsonLimits = TapeJsonLimits{
+	MaxBytes:     1 << 20,
+	MaxDepth:     32,
+	MaxStringLen: 256 << 10,
+}
+
+// HexToJsonWithLimits 按指定限制将十六进制字符串解析为JSON对象
+// 先逐个读取token检查嵌套深度和字符串长度，通过后再反序列化，避免恶意数据导致过量内存占用
+func HexToJsonWithLimits(hexStr string, limits TapeJsonLimits) (map[string]interface{}, error) {
+	if limits.MaxBytes > 0 && len(hexStr) > limits.MaxBytes*2 {
+		return nil, fmt.Errorf("%w: %d字节", ErrTapeJsonTooLarge, len(hexStr)/2)
+	}
+
+	data, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTapeJson(data, limits); err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ErrTapeJsonNotObject
+	}
+
+	return result, nil
+}
+
+// checkTapeJson 扫描JSON token，检查顶层类型、嵌套深度和字符串长度
+func checkTapeJson(data []byte, limits TapeJsonLimits) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	depth := 0
+	first := true
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch v := token.(type) {
+		case json.Delim:
+			if first && v != '{' {
+				return ErrTapeJsonNotObject
+			}
+			if v == '{' || v == '[' {
+				depth++
+				if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+					return fmt.Errorf("%w: 超过%d层", ErrTapeJsonTooDeep, limits.MaxDepth)
+				}
+			} else {
+				depth--
+			}
+		case string:
+			if first {
+				return ErrTapeJsonNotObject
+			}
+			if limits.MaxStringLen > 0 && len(v) > limits.MaxStringLen {
+				return fmt.Errorf("%w: %d字节", ErrTapeJsonStringLong, len(v))
+			}
+		default:
+			if first {
+				return ErrTapeJsonNotObject
+			}
+		}
+		first = false
+	}
+}
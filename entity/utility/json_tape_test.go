@@ -0,0 +1,67 @@
+package utility
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHexToJsonWithLimits(t *testing.T) {
+	limits := TapeJsonLimits{MaxBytes: 256, MaxDepth: 3, MaxStringLen: 16}
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr error
+	}{
+		{"普通对象", `{"name":"tbc","pubkeys":["a","b"]}`, nil},
+		{"嵌套在限制内", `{"a":{"b":[1]}}`, nil},
+		{"嵌套超限", `{"a":{"b":[[1]]}}`, ErrTapeJsonTooDeep},
+		{"字符串超长", `{"a":"` + strings.Repeat("x", 17) + `"}`, ErrTapeJsonStringLong},
+		{"键名超长", `{"` + strings.Repeat("k", 17) + `":1}`, ErrTapeJsonStringLong},
+		{"总大小超限", `{"a":"` + strings.Repeat("x", 300) + `"}`, ErrTapeJsonTooLarge},
+		{"顶层为数组", `[1,2]`, ErrTapeJsonNotObject},
+		{"顶层为字符串", `"abc"`, ErrTapeJsonNotObject},
+		{"顶层为null", `null`, ErrTapeJsonNotObject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := HexToJsonWithLimits(hex.EncodeToString([]byte(tt.json)), limits)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("期望成功，实际错误: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("期望错误 %v，实际 %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHexToJsonInvalidHex(t *testing.T) {
+	if _, err := HexToJson("zz"); err == nil {
+		t.Fatal("非法十六进制应返回错误")
+	}
+}
+
+func FuzzHexToJson(f *testing.F) {
+	f.Add(hex.EncodeToString([]byte(`{"address":"1abc","pubkeys":["02aa","03bb"]}`)))
+	f.Add(hex.EncodeToString([]byte(`{"a":[[[[[[]]]]]]}`)))
+	f.Add(hex.EncodeToString([]byte(`[]`)))
+	f.Add("7b")
+
+	limits := TapeJsonLimits{MaxBytes: 4096, MaxDepth: 8, MaxStringLen: 512}
+	f.Fuzz(func(t *testing.T, hexStr string) {
+		result, err := HexToJsonWithLimits(hexStr, limits)
+		if err != nil {
+			return
+		}
+		if result == nil {
+			t.Fatal("解析成功时结果不能为nil")
+		}
+		if len(hexStr) > limits.MaxBytes*2 {
+			t.Fatalf("超过大小限制的输入不应解析成功: %d", len(hexStr))
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package utility
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// 签名消息的前缀，与节点signmessage/verifymessage一致
+const signedMessageMagic = "Bitcoin Signed Message:\n"
+
+// 紧凑签名的长度：1字节头部和各32字节的r、s
+const compactSignatureSize = 65
+
+// ErrInvalidSignature 签名格式无效或与消息不匹配
+var ErrInvalidSignature = errors.New("无效的签名")
+
+// MessageHash 计算签名消息的哈希，消息前加固定前缀后做两次SHA256
+func MessageHash(message string) []byte {
+	var buf bytes.Buffer
+	writeVarString(&buf, signedMessageMagic)
+	writeVarString(&buf, message)
+	return doubleSHA256(buf.Bytes())
+}
+
+// RecoverMessagePubKey 从signmessage生成的base64紧凑签名中恢复签名者公钥
+// 签名头部标记为压缩公钥时返回33字节压缩公钥，否则返回65字节未压缩公钥
+func RecoverMessagePubKey(signature, message string) ([]byte, error) {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != compactSignatureSize {
+		return nil, fmt.Errorf("%w: 签名必须为base64编码的%d字节紧凑签名", ErrInvalidSignature, compactSignatureSize)
+	}
+	header := int(sig[0]) - 27
+	if header < 0 || header > 7 {
+		return nil, fmt.Errorf("%w: 签名头部无效", ErrInvalidSignature)
+	}
+	compressed := header >= 4
+	recoveryId := header & 3
+
+	r := new(big.Int).SetBytes(sig[1:33])
+	s := new(big.Int).SetBytes(sig[33:65])
+	if r.Sign() == 0 || s.Sign() == 0 || r.Cmp(curveN) >= 0 || s.Cmp(curveN) >= 0 {
+		return nil, fmt.Errorf("%w: r或s超出范围", ErrInvalidSignature)
+	}
+
+	// R的x坐标为r+j·n，y坐标的奇偶由恢复ID的最低位决定
+	x := new(big.Int).Set(r)
+	if recoveryId >= 2 {
+		x.Add(x, curveN)
+	}
+	if x.Cmp(curveP) >= 0 {
+		return nil, fmt.Errorf("%w: R不在曲线上", ErrInvalidSignature)
+	}
+	encoded := make([]byte, 33)
+	encoded[0] = 0x02 | byte(recoveryId&1)
+	x.FillBytes(encoded[1:])
+	rx, ry, err := decompressPubKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	// Q = r⁻¹(s·R - e·G)
+	e := new(big.Int).SetBytes(MessageHash(message))
+	rInv := new(big.Int).ModInverse(r, curveN)
+	u1 := new(big.Int).Neg(e)
+	u1.Mul(u1, rInv).Mod(u1, curveN)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, curveN)
+	x1, y1 := scalarBaseMult(u1)
+	x2, y2 := scalarMult(rx, ry, u2)
+	qx, qy := addPoints(x1, y1, x2, y2)
+	if qx == nil {
+		return nil, fmt.Errorf("%w: 无法恢复公钥", ErrInvalidSignature)
+	}
+
+	if compressed {
+		return compressPubKey(qx, qy), nil
+	}
+	pubKey := make([]byte, 65)
+	pubKey[0] = 0x04
+	qx.FillBytes(pubKey[1:33])
+	qy.FillBytes(pubKey[33:])
+	return pubKey, nil
+}
+
+// VerifyMessage 校验签名是否由P2PKH地址对应的私钥对消息签名生成
+func VerifyMessage(address, signature, message string) error {
+	pubKeyHash, err := ConvertAddressToPublicKeyHash(address)
+	if err != nil {
+		return err
+	}
+	pubKey, err := RecoverMessagePubKey(signature, message)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(hash160(pubKey)) != pubKeyHash {
+		return fmt.Errorf("%w: 签名者与地址不匹配", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// writeVarString 按变长整数长度前缀写入字符串
+func writeVarString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.Write([]byte{0xfd, byte(n), byte(n >> 8)})
+	default:
+		buf.Write([]byte{0xfe, byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)})
+	}
+	buf.WriteString(s)
+}
@@ -0,0 +1,65 @@
+package utility
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// signCompact 按signmessage的紧凑格式对消息签名，仅用于测试
+func signCompact(privKey, nonce *big.Int, message string) string {
+	e := new(big.Int).SetBytes(MessageHash(message))
+	rx, ry := scalarBaseMult(nonce)
+	r := new(big.Int).Mod(rx, curveN)
+	s := new(big.Int).Mul(r, privKey)
+	s.Add(s, e).Mul(s, new(big.Int).ModInverse(nonce, curveN)).Mod(s, curveN)
+
+	sig := make([]byte, compactSignatureSize)
+	sig[0] = byte(27 + 4 + ry.Bit(0))
+	r.FillBytes(sig[1:33])
+	s.FillBytes(sig[33:])
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestVerifyMessage(t *testing.T) {
+	privKey := big.NewInt(1)
+	// 私钥为1时公钥为G，对应的P2PKH地址
+	const address = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	message := "verify token"
+	signature := signCompact(privKey, big.NewInt(0x1234567), message)
+
+	pubKey, err := RecoverMessagePubKey(signature, message)
+	if err != nil {
+		t.Fatalf("恢复公钥失败: %v", err)
+	}
+	if !bytes.Equal(pubKey, compressPubKey(curveGx, curveGy)) {
+		t.Fatalf("恢复的公钥 = %x", pubKey)
+	}
+	if err := VerifyMessage(address, signature, message); err != nil {
+		t.Fatalf("校验签名失败: %v", err)
+	}
+
+	if err := VerifyMessage(address, signature, "other message"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("消息不一致时期望ErrInvalidSignature，实际: %v", err)
+	}
+	if err := VerifyMessage(address, "not-base64", message); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("签名格式错误时期望ErrInvalidSignature，实际: %v", err)
+	}
+}
+
+func TestVerifyMessageNodeVector(t *testing.T) {
+	// 节点signmessagewithprivkey的功能测试向量，签名由节点生成而非本包的signCompact
+	const (
+		address   = "mpLQjfK79b7CCV4VMJWEWAj5Mpx8Up5zxB"
+		signature = "INbVnW4e6PeRmsv2Qgu8NuopvrVjkcxob+sX8OcZG0SALhWybUjzMLPdAsXI46YZGb0KQTRii+wWIQzRpG/U+S0="
+		message   = "This is just a test message"
+	)
+	if err := VerifyMessage(address, signature, message); err != nil {
+		t.Fatalf("校验节点生成的签名失败: %v", err)
+	}
+	if err := VerifyMessage(address, signature, message+"."); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("消息不一致时期望ErrInvalidSignature，实际: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package utility
+
+import (
+	"errors"
+	"fmt"
+
+	"ginproject/entity/config"
+)
+
+// 未配置时的每页最大记录数，与此前各接口的硬编码上限一致
+const defaultMaxPageSize = 10000
+
+// 分页接口名称，对应配置pagination.endpoints中的键
+const (
+	PageEndpointFtHistory              = "ft_history"
+	PageEndpointFtTokenHistory         = "ft_token_history"
+	PageEndpointFtPoolHistory          = "ft_pool_history"
+	PageEndpointFtPoolList             = "ft_pool_list"
+	PageEndpointFtHolderRank           = "ft_holder_rank"
+	PageEndpointFtLPUnspent            = "ft_lp_unspent"
+	PageEndpointFtTokenList            = "ft_token_list"
+	PageEndpointFtTokenSearch          = "ft_token_search"
+	PageEndpointFtBurns                = "ft_burns"
+	PageEndpointNftCollectionByAddress = "nft_collection_by_address"
+	PageEndpointNftAllCollections      = "nft_all_collections"
+	PageEndpointNftByAddress           = "nft_by_address"
+	PageEndpointNftByScriptHash        = "nft_by_script_hash"
+	PageEndpointNftByCollection        = "nft_by_collection"
+	PageEndpointNftHistory             = "nft_history"
+	PageEndpointNftSearch              = "nft_search"
+	PageEndpointWalletHistory          = "wallet_history"
+	PageEndpointWalletAddresses        = "wallet_addresses"
+	PageEndpointBroadcastFailures      = "broadcast_failures"
+	PageEndpointBlockTxs               = "block_txs"
+)
+
+var (
+	// ErrInvalidPageSize 每页记录数必须大于0
+	ErrInvalidPageSize = errors.New("每页记录数必须大于0")
+	// ErrPageSizeTooLarge 每页记录数超过接口上限
+	ErrPageSizeTooLarge = errors.New("每页记录数超过上限")
+)
+
+// MaxPageSize 获取指定接口的每页最大记录数
+func MaxPageSize(endpoint string) int {
+	pagination := config.GetConfig().GetPaginationConfig()
+	if max, ok := pagination.Endpoints[endpoint]; ok && max > 0 {
+		return max
+	}
+	if pagination.MaxPageSize > 0 {
+		return pagination.MaxPageSize
+	}
+	return defaultMaxPageSize
+}
+
+// ValidatePageSize 校验每页记录数在1到接口上限之间
+func ValidatePageSize(endpoint string, size int) error {
+	if size <= 0 {
+		return ErrInvalidPageSize
+	}
+	if max := MaxPageSize(endpoint); size > max {
+		return fmt.Errorf("%w: 最大为%d，当前为%d", ErrPageSizeTooLarge, max, size)
+	}
+	return nil
+}
+
+// PageMeta 分页列表响应的统一元数据
+type PageMeta struct {
+	Page    int   `json:"page"`     // 页码（从0开始）
+	Size    int   `json:"size"`     // 每页记录数，0表示未分页
+	Total   int64 `json:"total"`    // 总记录数
+	HasNext bool  `json:"has_next"` // 是否还有下一页
+}
+
+// NewPageMeta 根据从0开始的页码、每页记录数和总记录数生成分页元数据
+func NewPageMeta(page, size int, total int64) *PageMeta {
+	return &PageMeta{
+		Page:    page,
+		Size:    size,
+		Total:   total,
+		HasNext: size > 0 && int64(page+1)*int64(size) < total,
+	}
+}
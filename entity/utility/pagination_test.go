@@ -0,0 +1,24 @@
+package utility
+
+import "testing"
+
+func TestNewPageMeta(t *testing.T) {
+	tests := []struct {
+		page, size int
+		total      int64
+		hasNext    bool
+	}{
+		{page: 0, size: 10, total: 25, hasNext: true},
+		{page: 1, size: 10, total: 25, hasNext: true},
+		{page: 2, size: 10, total: 25, hasNext: false},
+		{page: 1, size: 10, total: 20, hasNext: false},
+		{page: 5, size: 10, total: 25, hasNext: false},
+		{page: 0, size: 0, total: 25, hasNext: false},
+	}
+	for _, tt := range tests {
+		meta := NewPageMeta(tt.page, tt.size, tt.total)
+		if meta.Page != tt.page || meta.Size != tt.size || meta.Total != tt.total || meta.HasNext != tt.hasNext {
+			t.Errorf("NewPageMeta(%d, %d, %d) = %+v", tt.page, tt.size, tt.total, meta)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package utility
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// 输出脚本分类
+const (
+	ScriptClassUnknown       = ""
+	ScriptClassFtCode        = "tbc20_code"        // TBC20代币代码脚本
+	ScriptClassNftHold       = "tbc721_hold"       // TBC721 NFT持有脚本
+	ScriptClassNftCollection = "tbc721_collection" // TBC721 集合铸造持有脚本
+)
+
+// 代币脚本特征
+const (
+	// ftCodeSuffix FT代码脚本末尾的"Code"标记
+	ftCodeSuffix = "0502436f6465"
+	// ftCombineScriptLen FT代码脚本中组合脚本的十六进制长度（20字节公钥哈希+1字节类型）
+	ftCombineScriptLen = 42
+	// p2pkhPrefix P2PKH脚本前缀 OP_DUP OP_HASH160 PUSH20
+	p2pkhPrefix = "76a914"
+	// nftHoldSuffix NFT持有脚本中P2PKH之后的 OP_EQUALVERIFY OP_CHECKSIG OP_RETURN "V0 Curr NHold"
+	nftHoldSuffix = "88ac6a0d56302043757272204e486f6c64"
+	// nftCollectionSuffix 集合持有脚本中P2PKH之后的 OP_EQUALVERIFY OP_CHECKSIG OP_RETURN "V0 Mint NHold"
+	nftCollectionSuffix = "88ac6a0d5630204d696e74204e486f6c64"
+)
+
+// ScriptClass 输出脚本分类结果
+type ScriptClass struct {
+	Class   string // 脚本分类
+	Address string // 从脚本中解析出的持有者地址
+}
+
+// ClassifyScript 识别节点无法解析地址的代币输出脚本，并提取其中的持有者地址
+// 无法识别时返回ScriptClassUnknown
+func ClassifyScript(scriptHex string) ScriptClass {
+	scriptHex = strings.ToLower(scriptHex)
+
+	// TBC20代码脚本: <合约特征> <组合脚本> "Code"
+	if strings.HasSuffix(scriptHex, ftCodeSuffix) && len(scriptHex) >= ftCombineScriptLen+len(ftCodeSuffix) {
+		end := len(scriptHex) - len(ftCodeSuffix)
+		combineScript := scriptHex[end-ftCombineScriptLen : end]
+		address, err := ConvertCombineScriptToAddress(combineScript)
+		if err != nil {
+			return ScriptClass{}
+		}
+		return ScriptClass{Class: ScriptClassFtCode, Address: address}
+	}
+
+	// TBC721持有脚本: P2PKH + OP_RETURN 标记
+	holdLen := len(p2pkhPrefix) + 40 + len(nftHoldSuffix)
+	if len(scriptHex) == holdLen && strings.HasPrefix(scriptHex, p2pkhPrefix) {
+		class := ScriptClassUnknown
+		switch scriptHex[len(p2pkhPrefix)+40:] {
+		case nftHoldSuffix:
+			class = ScriptClassNftHold
+		case nftCollectionSuffix:
+			class = ScriptClassNftCollection
+		default:
+			return ScriptClass{}
+		}
+
+		pubKeyHash, err := hex.DecodeString(scriptHex[len(p2pkhPrefix) : len(p2pkhPrefix)+40])
+		if err != nil {
+			return ScriptClass{}
+		}
+		return ScriptClass{Class: class, Address: base58.CheckEncode(pubKeyHash, 0x00)}
+	}
+
+	return ScriptClass{}
+}
@@ -0,0 +1,40 @@
+package utility
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyScript(t *testing.T) {
+	const (
+		address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+		pubKey  = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	)
+	ftCode := strings.Repeat("51", 64) + benchPkHash
+
+	cases := []struct {
+		name   string
+		script string
+		want   ScriptClass
+	}{
+		{"P2PKH", p2pkhPrefix + benchPkHash + "88ac", ScriptClass{}},
+		{"TBC20代码脚本", ftCode + "00" + ftCodeSuffix, ScriptClass{Class: ScriptClassFtCode, Address: address}},
+		{"TBC20大写脚本", strings.ToUpper(ftCode + "00" + ftCodeSuffix), ScriptClass{Class: ScriptClassFtCode, Address: address}},
+		{"TBC20池或多签持有", ftCode + "01" + ftCodeSuffix, ScriptClass{Class: ScriptClassFtCode, Address: "Pool_or_ms_hash_" + benchPkHash + "01"}},
+		{"TBC20脚本过短", benchPkHash + ftCodeSuffix, ScriptClass{}},
+		{"TBC721持有脚本", p2pkhPrefix + benchPkHash + nftHoldSuffix, ScriptClass{Class: ScriptClassNftHold, Address: address}},
+		{"TBC721集合脚本", p2pkhPrefix + benchPkHash + nftCollectionSuffix, ScriptClass{Class: ScriptClassNftCollection, Address: address}},
+		{"TBC721未知标记", p2pkhPrefix + benchPkHash + strings.Repeat("00", len(nftHoldSuffix)/2), ScriptClass{}},
+		{"TBC721公钥哈希无效", p2pkhPrefix + strings.Repeat("zz", 20) + nftHoldSuffix, ScriptClass{}},
+		{"裸多签", "52" + "21" + pubKey + "21" + pubKey + "52ae", ScriptClass{}},
+		{"OP_RETURN", "6a0568656c6c6f", ScriptClass{}},
+		{"空脚本", "", ScriptClass{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ClassifyScript(c.script); got != c.want {
+				t.Errorf("ClassifyScript = %+v, 期望 %+v", got, c.want)
+			}
+		})
+	}
+}
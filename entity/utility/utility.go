@@ -3,7 +3,6 @@ package utility
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -689,21 +688,9 @@ func ConvertAddressToNftScriptHash(address string, isCollection bool) (string, e
 }
 
 // HexToJson 将十六进制字符串转换为JSON对象
+// 数据来自链上，按DefaultTapeJsonLimits限制大小、嵌套深度和字符串长度
 func HexToJson(hexStr string) (map[string]interface{}, error) {
-	// 十六进制字符串转换为字节
-	bytes, err := hex.DecodeString(hexStr)
-	if err != nil {
-		return nil, err
-	}
-
-	// 字节解析为JSON
-	var result map[string]interface{}
-	err = json.Unmarshal(bytes, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return HexToJsonWithLimits(hexStr, DefaultTapeJsonLimits)
 }
 
 // ConvertCompressedPubkeyToLegacyAddress 将压缩公钥转换为传统地址
@@ -0,0 +1,232 @@
+package utility
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// 扩展公钥版本号
+var (
+	xpubVersion = []byte{0x04, 0x88, 0xb2, 0x1e}
+	tpubVersion = []byte{0x04, 0x35, 0x87, 0xcf}
+)
+
+// P2PKH地址版本号
+const (
+	mainnetPubKeyHashVersion = 0x00
+	testnetPubKeyHashVersion = 0x6f
+)
+
+// 硬化派生的起始序号，扩展公钥只能派生小于该值的子节点
+const hardenedKeyStart = 0x80000000
+
+// ErrInvalidXpub 扩展公钥格式无效
+var ErrInvalidXpub = errors.New("无效的扩展公钥")
+
+// secp256k1曲线参数
+var (
+	curveP, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	curveN, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	curveGx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	curveGy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+)
+
+// ExtendedPubKey BIP32扩展公钥
+type ExtendedPubKey struct {
+	key       []byte // 压缩公钥
+	chainCode []byte
+	testnet   bool
+}
+
+// ParseExtendedPubKey 解析xpub或tpub格式的扩展公钥
+func ParseExtendedPubKey(xpub string) (*ExtendedPubKey, error) {
+	payload, version, err := base58.CheckDecode(xpub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXpub, err)
+	}
+	// CheckDecode将第一个字节作为版本号返回，其余为载荷
+	data := append([]byte{version}, payload...)
+	if len(data) != 78 {
+		return nil, fmt.Errorf("%w: 长度为%d字节", ErrInvalidXpub, len(data))
+	}
+
+	var testnet bool
+	switch {
+	case bytes.Equal(data[:4], xpubVersion):
+	case bytes.Equal(data[:4], tpubVersion):
+		testnet = true
+	default:
+		return nil, fmt.Errorf("%w: 不支持的版本号%x", ErrInvalidXpub, data[:4])
+	}
+
+	key := data[45:78]
+	if _, _, err := decompressPubKey(key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXpub, err)
+	}
+	return &ExtendedPubKey{key: key, chainCode: data[13:45], testnet: testnet}, nil
+}
+
+// Child 按BIP32派生非硬化子公钥
+func (k *ExtendedPubKey) Child(index uint32) (*ExtendedPubKey, error) {
+	if index >= hardenedKeyStart {
+		return nil, fmt.Errorf("扩展公钥不能派生硬化子节点: %d", index)
+	}
+
+	data := make([]byte, 37)
+	copy(data, k.key)
+	binary.BigEndian.PutUint32(data[33:], index)
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveN) >= 0 {
+		return nil, fmt.Errorf("子节点%d无效，请跳过", index)
+	}
+	px, py, err := decompressPubKey(k.key)
+	if err != nil {
+		return nil, err
+	}
+	gx, gy := scalarBaseMult(il)
+	cx, cy := addPoints(gx, gy, px, py)
+	if cx == nil {
+		return nil, fmt.Errorf("子节点%d无效，请跳过", index)
+	}
+
+	return &ExtendedPubKey{key: compressPubKey(cx, cy), chainCode: sum[32:], testnet: k.testnet}, nil
+}
+
+// PubKey 返回压缩公钥
+func (k *ExtendedPubKey) PubKey() []byte {
+	return k.key
+}
+
+// Address 返回公钥对应的P2PKH地址
+func (k *ExtendedPubKey) Address() string {
+	sha := sha256.Sum256(k.key)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	version := byte(mainnetPubKeyHashVersion)
+	if k.testnet {
+		version = testnetPubKeyHashVersion
+	}
+	return base58.CheckEncode(hasher.Sum(nil), version)
+}
+
+// DeriveXpubAddresses 从扩展公钥的chain/0到chain/count-1派生地址，chain通常为0(收款)或1(找零)
+func DeriveXpubAddresses(xpub string, chain uint32, count int) ([]string, error) {
+	root, err := ParseExtendedPubKey(xpub)
+	if err != nil {
+		return nil, err
+	}
+	branch, err := root.Child(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, count)
+	for i := uint32(0); len(addresses) < count && i < hardenedKeyStart; i++ {
+		child, err := branch.Child(i)
+		if err != nil {
+			// 概率可忽略的无效子节点，按BIP32跳过
+			continue
+		}
+		addresses = append(addresses, child.Address())
+	}
+	return addresses, nil
+}
+
+// decompressPubKey 解压33字节的压缩公钥
+func decompressPubKey(key []byte) (*big.Int, *big.Int, error) {
+	if len(key) != 33 || (key[0] != 0x02 && key[0] != 0x03) {
+		return nil, nil, errors.New("公钥必须为压缩格式")
+	}
+	x := new(big.Int).SetBytes(key[1:])
+	if x.Cmp(curveP) >= 0 {
+		return nil, nil, errors.New("公钥不在曲线上")
+	}
+
+	// y² = x³ + 7，p ≡ 3 (mod 4)，平方根为 (y²)^((p+1)/4)
+	y2 := new(big.Int).Exp(x, big.NewInt(3), curveP)
+	y2.Add(y2, big.NewInt(7)).Mod(y2, curveP)
+	exp := new(big.Int).Add(curveP, big.NewInt(1))
+	exp.Rsh(exp, 2)
+	y := new(big.Int).Exp(y2, exp, curveP)
+	if new(big.Int).Exp(y, big.NewInt(2), curveP).Cmp(y2) != 0 {
+		return nil, nil, errors.New("公钥不在曲线上")
+	}
+	if y.Bit(0) != uint(key[0]&1) {
+		y.Sub(curveP, y)
+	}
+	return x, y, nil
+}
+
+// compressPubKey 将曲线上的点编码为33字节压缩公钥
+func compressPubKey(x, y *big.Int) []byte {
+	key := make([]byte, 33)
+	key[0] = 0x02 | byte(y.Bit(0))
+	x.FillBytes(key[1:])
+	return key
+}
+
+// addPoints 仿射坐标下的点加，nil表示无穷远点
+func addPoints(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	}
+	if x2 == nil {
+		return x1, y1
+	}
+
+	var lambda *big.Int
+	if x1.Cmp(x2) == 0 {
+		if new(big.Int).Add(y1, y2).Mod(new(big.Int).Add(y1, y2), curveP).Sign() == 0 {
+			return nil, nil
+		}
+		// 倍点：λ = 3x² / 2y
+		num := new(big.Int).Mul(x1, x1)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(y1, 1)
+		lambda = num.Mul(num, den.ModInverse(den, curveP))
+	} else {
+		// λ = (y2 - y1) / (x2 - x1)
+		num := new(big.Int).Sub(y2, y1)
+		den := new(big.Int).Sub(x2, x1)
+		den.Mod(den, curveP)
+		lambda = num.Mul(num, den.ModInverse(den, curveP))
+	}
+	lambda.Mod(lambda, curveP)
+
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, curveP)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda).Sub(y3, y1).Mod(y3, curveP)
+	return x3, y3
+}
+
+// scalarBaseMult 计算k·G
+func scalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	return scalarMult(curveGx, curveGy, k)
+}
+
+// scalarMult 计算k·P
+func scalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	var rx, ry *big.Int
+	px, py := x, y
+	for i := 0; i < k.BitLen(); i++ {
+		if k.Bit(i) == 1 {
+			rx, ry = addPoints(rx, ry, px, py)
+		}
+		px, py = addPoints(px, py, px, py)
+	}
+	return rx, ry
+}
@@ -0,0 +1,57 @@
+package utility
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// BIP32测试向量1中m/0H和m/0H/1的扩展公钥
+const (
+	vectorXpub0H  = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+	vectorXpub0H1 = "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"
+)
+
+func TestExtendedPubKeyChild(t *testing.T) {
+	parent, err := ParseExtendedPubKey(vectorXpub0H)
+	if err != nil {
+		t.Fatalf("解析扩展公钥失败: %v", err)
+	}
+	want, err := ParseExtendedPubKey(vectorXpub0H1)
+	if err != nil {
+		t.Fatalf("解析扩展公钥失败: %v", err)
+	}
+
+	child, err := parent.Child(1)
+	if err != nil {
+		t.Fatalf("派生子公钥失败: %v", err)
+	}
+	if !bytes.Equal(child.PubKey(), want.PubKey()) || !bytes.Equal(child.chainCode, want.chainCode) {
+		t.Fatalf("派生结果与测试向量不一致: %x", child.PubKey())
+	}
+
+	if _, err := parent.Child(hardenedKeyStart); err == nil {
+		t.Fatal("扩展公钥派生硬化子节点应返回错误")
+	}
+}
+
+func TestDeriveXpubAddresses(t *testing.T) {
+	addresses, err := DeriveXpubAddresses(vectorXpub0H, 1, 3)
+	if err != nil {
+		t.Fatalf("派生地址失败: %v", err)
+	}
+	if len(addresses) != 3 {
+		t.Fatalf("期望3个地址，实际: %d", len(addresses))
+	}
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		if _, err := ConvertAddressToPublicKeyHash(address); err != nil || seen[address] {
+			t.Fatalf("派生的地址无效或重复: %s", address)
+		}
+		seen[address] = true
+	}
+
+	if _, err := DeriveXpubAddresses("xpub-invalid", 0, 1); !errors.Is(err, ErrInvalidXpub) {
+		t.Fatalf("期望ErrInvalidXpub，实际: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// 单次扫描的最大候选地址数量
+	MaxRecoveryAddresses = 5000
+	// 扩展公钥扫描默认的地址间隔，与BIP44一致
+	DefaultRecoveryGapLimit = 20
+	// 扩展公钥扫描允许的最大地址间隔
+	MaxRecoveryGapLimit = 1000
+	// 扩展公钥每条链最多扫描的序号数量，避免持续有历史的链无限扫描
+	MaxRecoveryIndex = 10000
+)
+
+// RecoveryScanRequest 钱包恢复扫描请求，addresses和xpub二选一
+type RecoveryScanRequest struct {
+	Addresses []string `json:"addresses"` // 候选地址
+	Xpub      string   `json:"xpub"`      // 扩展公钥，在收款(0/i)和找零(1/i)链上按间隔扫描
+	GapLimit  int      `json:"gap_limit"` // 连续多少个地址没有历史时停止扫描该链，为0时使用默认值
+}
+
+// Validate 验证请求参数的合法性，去除地址和扩展公钥两端的空白
+func (req *RecoveryScanRequest) Validate() error {
+	req.Xpub = strings.TrimSpace(req.Xpub)
+	if (len(req.Addresses) == 0) == (req.Xpub == "") {
+		return fmt.Errorf("地址和扩展公钥必须且只能指定一项")
+	}
+	if len(req.Addresses) > MaxRecoveryAddresses {
+		return fmt.Errorf("单次扫描的地址不能超过%d个", MaxRecoveryAddresses)
+	}
+	for i, address := range req.Addresses {
+		req.Addresses[i] = strings.TrimSpace(address)
+	}
+	if req.GapLimit == 0 {
+		req.GapLimit = DefaultRecoveryGapLimit
+	}
+	if req.GapLimit < 1 || req.GapLimit > MaxRecoveryGapLimit {
+		return fmt.Errorf("地址间隔必须在1到%d之间", MaxRecoveryGapLimit)
+	}
+	return nil
+}
+
+// RecoveryAddress 有链上历史的地址
+type RecoveryAddress struct {
+	Address        string `json:"address"`
+	DerivationPath string `json:"derivation_path,omitempty"` // 扩展公钥扫描时为chain/index
+	FirstTxHash    string `json:"first_tx_hash"`             // 最早的一笔交易
+	FirstHeight    int64  `json:"first_height"`              // 最早交易所在的高度，未确认时小于等于0
+}
+
+// RecoveryFailure 查询失败的地址，其是否有历史未知
+type RecoveryFailure struct {
+	Address        string `json:"address"`
+	DerivationPath string `json:"derivation_path,omitempty"`
+	Error          string `json:"error"`
+}
+
+// RecoveryChain 扩展公钥单条链的扫描结果
+type RecoveryChain struct {
+	Chain         uint32 `json:"chain"`           // 0为收款链，1为找零链
+	Scanned       int    `json:"scanned"`         // 扫描的序号数量
+	LastUsedIndex int    `json:"last_used_index"` // 最后一个有历史的序号，没有时为-1
+	Truncated     bool   `json:"truncated"`       // 达到最大扫描序号仍未满足地址间隔
+}
+
+// RecoveryScanResponse 钱包恢复扫描响应
+type RecoveryScanResponse struct {
+	Scanned int               `json:"scanned"` // 查询的地址数量
+	Used    []RecoveryAddress `json:"used"`    // 有链上历史的地址，按请求顺序或派生路径排列
+	Failed  []RecoveryFailure `json:"failed,omitempty"`
+	Chains  []RecoveryChain   `json:"chains,omitempty"` // 仅扩展公钥扫描时返回
+}
@@ -0,0 +1,280 @@
+package wallet
+
+import (
+	"fmt"
+	"net/mail"
+
+	"ginproject/entity/utility"
+)
+
+// 钱包名称的最大长度
+const MaxWalletNameLength = 64
+
+// 单个扩展公钥在每条链上允许派生的最大地址数量
+const MaxLookahead = 500
+
+// CreateWalletRequest 创建跟踪钱包请求
+type CreateWalletRequest struct {
+	Name        string             `json:"name"`
+	Addresses   []string           `json:"addresses"`
+	Xpubs       []string           `json:"xpubs"`       // 扩展公钥，派生收款(0/i)和找零(1/i)地址
+	Descriptors []DescriptorImport `json:"descriptors"` // 输出描述符，展开为具体的脚本和地址
+	Lookahead   int                `json:"lookahead"`   // 每条链派生的地址数量，为0时使用配置的默认值
+}
+
+// DescriptorImport 导入的输出描述符，支持pkh(KEY)、sh(multi(...))和sh(sortedmulti(...))
+type DescriptorImport struct {
+	Desc  string   `json:"desc"`
+	Range []uint32 `json:"range"` // 含通配符时展开的序号范围：[end]或[begin,end]，均包含在内；为空时展开0到lookahead-1
+}
+
+// Validate 验证描述符的序号范围，描述符本身在展开时解析
+func (d *DescriptorImport) Validate() error {
+	if d.Desc == "" {
+		return fmt.Errorf("描述符不能为空")
+	}
+	switch len(d.Range) {
+	case 0:
+		return nil
+	case 1:
+		d.Range = []uint32{0, d.Range[0]}
+	case 2:
+	default:
+		return fmt.Errorf("描述符范围必须为[end]或[begin,end]")
+	}
+	if d.Range[0] > d.Range[1] {
+		return fmt.Errorf("描述符范围的起始序号不能大于结束序号")
+	}
+	if d.Range[1]-d.Range[0] >= MaxLookahead {
+		return fmt.Errorf("描述符范围不能超过%d个序号", MaxLookahead)
+	}
+	return nil
+}
+
+// Validate 验证请求参数的合法性
+func (req *CreateWalletRequest) Validate() error {
+	if len(req.Name) > MaxWalletNameLength {
+		return fmt.Errorf("钱包名称不能超过%d个字符", MaxWalletNameLength)
+	}
+	if len(req.Addresses) == 0 && len(req.Xpubs) == 0 && len(req.Descriptors) == 0 {
+		return fmt.Errorf("地址、扩展公钥和描述符不能同时为空")
+	}
+	for i := range req.Descriptors {
+		if err := req.Descriptors[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if req.Lookahead < 0 || req.Lookahead > MaxLookahead {
+		return fmt.Errorf("派生数量必须在0到%d之间", MaxLookahead)
+	}
+	return nil
+}
+
+// DescriptorExpandResponse 描述符展开预览响应
+type DescriptorExpandResponse struct {
+	Desc    string                      `json:"desc"`
+	IsRange bool                        `json:"is_range"` // 是否包含通配符
+	Outputs []*utility.DescriptorOutput `json:"outputs"`
+}
+
+// WalletIdRequest 按钱包ID操作的请求
+type WalletIdRequest struct {
+	WalletId string `uri:"wallet_id" binding:"required"`
+}
+
+// WalletHistoryRequest 获取钱包交易历史请求
+type WalletHistoryRequest struct {
+	WalletId string `uri:"wallet_id" binding:"required"`
+	Page     int    `form:"page"` // 页码（从0开始）
+	Size     int    `form:"size"` // 每页记录数，默认为20
+}
+
+// Validate 验证请求参数的合法性
+func (req *WalletHistoryRequest) Validate() error {
+	if req.Page < 0 {
+		return fmt.Errorf("页码必须大于或等于0")
+	}
+	if req.Size == 0 {
+		req.Size = 20
+	}
+	return utility.ValidatePageSize(utility.PageEndpointWalletHistory, req.Size)
+}
+
+// WalletSummaryResponse 钱包汇总数据
+type WalletSummaryResponse struct {
+	WalletId           string `json:"wallet_id"`
+	Name               string `json:"name"`
+	AddressCount       int    `json:"address_count"`
+	Balance            int64  `json:"balance"`     // 总余额（已确认+未确认）
+	Confirmed          int64  `json:"confirmed"`   // 已确认的余额
+	Unconfirmed        int64  `json:"unconfirmed"` // 未确认的余额
+	TxCount            int    `json:"tx_count"`    // 去重后的交易数量
+	LastActivityHeight int64  `json:"last_activity_height"`
+	LastActivityTime   int64  `json:"last_activity_time"`
+	SyncedAt           int64  `json:"synced_at"` // 汇总数据的同步时间(Unix秒)，0表示尚未同步
+}
+
+// WalletHistoryItem 钱包交易记录
+type WalletHistoryItem struct {
+	TxHash    string   `json:"tx_hash"`
+	Height    int64    `json:"height"`    // 未确认交易小于等于0
+	Addresses []string `json:"addresses"` // 交易涉及的钱包地址
+}
+
+// WalletHistoryResponse 钱包交易历史响应
+type WalletHistoryResponse struct {
+	WalletId string              `json:"wallet_id"`
+	TxCount  int                 `json:"tx_count"`
+	SyncedAt int64               `json:"synced_at"`
+	Result   []WalletHistoryItem `json:"result"`
+	Meta     *utility.PageMeta   `json:"meta,omitempty"`
+}
+
+// 钱包通知渠道
+const NotifyChannelEmail = "email"
+
+// 钱包通知方式
+const (
+	NotifyModeInstant = "instant" // 每次同步发现新交易后立即通知
+	NotifyModeDaily   = "daily"   // 每日汇总一次
+)
+
+// NotificationRequest 设置钱包通知请求
+type NotificationRequest struct {
+	Channel string `json:"channel"` // 通知渠道，目前支持email
+	Target  string `json:"target"`  // 通知接收方，email渠道为邮箱地址
+	Mode    string `json:"mode"`    // 通知方式：instant或daily，默认为daily
+}
+
+// Validate 验证请求参数的合法性，渠道是否可用由调用方检查
+func (req *NotificationRequest) Validate() error {
+	if req.Channel == "" {
+		return fmt.Errorf("通知渠道不能为空")
+	}
+	if req.Target == "" {
+		return fmt.Errorf("通知接收方不能为空")
+	}
+	if req.Channel == NotifyChannelEmail {
+		address, err := mail.ParseAddress(req.Target)
+		if err != nil {
+			return fmt.Errorf("无效的邮箱地址: %s", req.Target)
+		}
+		req.Target = address.Address
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = NotifyModeDaily
+	case NotifyModeInstant, NotifyModeDaily:
+	default:
+		return fmt.Errorf("不支持的通知方式: %s", req.Mode)
+	}
+	return nil
+}
+
+// NotificationResponse 钱包通知设置
+type NotificationResponse struct {
+	WalletId       string `json:"wallet_id"`
+	Channel        string `json:"channel"`
+	Target         string `json:"target"`
+	Mode           string `json:"mode"`
+	NotifiedHeight int64  `json:"notified_height"` // 已通知的最高区块高度
+	LastSentAt     int64  `json:"last_sent_at"`    // 最近一次检查并发送通知的时间(Unix秒)，0表示尚未检查
+}
+
+// 钱包地址的监听状态
+const (
+	WatchStatusWatching  = "watching"  // 监听中，参与同步
+	WatchStatusExpired   = "expired"   // 已到过期时间，停止监听
+	WatchStatusDeposited = "deposited" // 首笔充值已达到确认数，停止监听
+)
+
+const (
+	// 充值地址最长的监听时间(秒)
+	MaxDepositExpiresIn = 90 * 24 * 3600
+	// 收到充值后停止监听所需的最大确认数
+	MaxStopConfirmations = 100
+	// 单次登记的最大充值地址数量
+	MaxDepositAddresses = 500
+)
+
+// RegisterDepositAddressesRequest 向钱包登记充值地址请求，至少需要设置一种停止监听的策略
+// 重复登记已有地址时更新其策略并恢复监听
+type RegisterDepositAddressesRequest struct {
+	Addresses     []string `json:"addresses"`
+	ExpiresIn     int64    `json:"expires_in"`      // 登记后经过该秒数停止监听，0表示不过期
+	StopOnDeposit bool     `json:"stop_on_deposit"` // 首笔充值确认后停止监听
+	Confirmations int      `json:"confirmations"`   // 停止监听所需的确认数，stop_on_deposit为true时默认为1
+}
+
+// Validate 验证请求参数的合法性
+func (req *RegisterDepositAddressesRequest) Validate() error {
+	if len(req.Addresses) == 0 {
+		return fmt.Errorf("地址不能为空")
+	}
+	if len(req.Addresses) > MaxDepositAddresses {
+		return fmt.Errorf("单次登记的地址不能超过%d个", MaxDepositAddresses)
+	}
+	if req.ExpiresIn < 0 || req.ExpiresIn > MaxDepositExpiresIn {
+		return fmt.Errorf("过期时间必须在0到%d秒之间", MaxDepositExpiresIn)
+	}
+	if req.Confirmations < 0 || req.Confirmations > MaxStopConfirmations {
+		return fmt.Errorf("确认数必须在0到%d之间", MaxStopConfirmations)
+	}
+	if !req.StopOnDeposit {
+		if req.Confirmations > 0 {
+			return fmt.Errorf("设置确认数时stop_on_deposit必须为true")
+		}
+	} else if req.Confirmations == 0 {
+		req.Confirmations = 1
+	}
+	if req.ExpiresIn == 0 && !req.StopOnDeposit {
+		return fmt.Errorf("过期时间和stop_on_deposit至少设置一项")
+	}
+	return nil
+}
+
+// WalletAddressesRequest 获取钱包地址监听状态请求
+type WalletAddressesRequest struct {
+	WalletId string `uri:"wallet_id" binding:"required"`
+	Status   string `form:"status"` // 按监听状态过滤，为空时返回全部
+	Page     int    `form:"page"`   // 页码（从0开始）
+	Size     int    `form:"size"`   // 每页记录数，默认为100
+}
+
+// Validate 验证请求参数的合法性
+func (req *WalletAddressesRequest) Validate() error {
+	switch req.Status {
+	case "", WatchStatusWatching, WatchStatusExpired, WatchStatusDeposited:
+	default:
+		return fmt.Errorf("不支持的监听状态: %s", req.Status)
+	}
+	if req.Page < 0 {
+		return fmt.Errorf("页码必须大于或等于0")
+	}
+	if req.Size == 0 {
+		req.Size = 100
+	}
+	return utility.ValidatePageSize(utility.PageEndpointWalletAddresses, req.Size)
+}
+
+// WalletAddressStatus 钱包地址的监听状态
+type WalletAddressStatus struct {
+	Address            string `json:"address"`
+	Status             string `json:"status"`
+	Source             string `json:"source,omitempty"`
+	DerivationPath     string `json:"derivation_path,omitempty"`
+	ExpiresAt          int64  `json:"expires_at"`         // 过期时间(Unix秒)，0表示不过期
+	StopConfirmations  int    `json:"stop_confirmations"` // 首笔充值达到该确认数后停止监听，0表示不因充值停止
+	FirstDepositTxid   string `json:"first_deposit_txid,omitempty"`
+	FirstDepositHeight int64  `json:"first_deposit_height"`
+	StoppedAt          int64  `json:"stopped_at"` // 停止监听的时间(Unix秒)，0表示仍在监听
+	CreatedAt          int64  `json:"created_at"`
+}
+
+// WalletAddressesResponse 钱包地址监听状态响应
+type WalletAddressesResponse struct {
+	WalletId string                `json:"wallet_id"`
+	Watching int64                 `json:"watching"` // 监听中的地址数量
+	Result   []WalletAddressStatus `json:"result"`
+	Meta     *utility.PageMeta     `json:"meta,omitempty"`
+}
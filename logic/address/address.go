@@ -4,24 +4,28 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"math/big"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"ginproject/entity/blockchain"
 	"ginproject/entity/dbtable"
 	"ginproject/entity/electrumx"
+	"ginproject/entity/transaction"
 	utility "ginproject/entity/utility"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
-	"ginproject/repo/db/address_transactions_dao"
-	"ginproject/repo/db/transaction_participants_dao"
-	"ginproject/repo/db/transactions_dao"
 	rpcbchain "ginproject/repo/rpc/blockchain"
 	rpcex "ginproject/repo/rpc/electrumx"
+	"ginproject/repo/scripthash"
 )
 
+// 分页模式下地址历史的每页记录数
+const historyPageSize = 10
+
+// 不分页时返回的最新记录数
+const historyTailSize = 30
+
 // AsyncUtxoResult 异步UTXO结果
 type AsyncUtxoResult struct {
 	Utxos electrumx.UtxoResponse
@@ -37,7 +41,8 @@ func NewAddressLogic() *AddressLogic {
 }
 
 // GetAddressUnspentUtxos 获取地址的未花费交易输出（异步版本）
-func (l *AddressLogic) GetAddressUnspentUtxos(ctx context.Context, address string) chan *AsyncUtxoResult {
+// minConfirmations大于0时只返回确认数足够的输出；每个输出按本次请求的链顶附带确认数和区块时间
+func (l *AddressLogic) GetAddressUnspentUtxos(ctx context.Context, address string, minConfirmations int64) chan *AsyncUtxoResult {
 	resultChan := make(chan *AsyncUtxoResult, 1)
 
 	go func() {
@@ -59,7 +64,7 @@ func (l *AddressLogic) GetAddressUnspentUtxos(ctx context.Context, address strin
 		log.InfoWithContext(ctx, "地址验证通过", "address:", address, "type:", addrType)
 
 		// 将地址转换为脚本哈希
-		scriptHash, err := utility.AddressToScriptHash(address)
+		scriptHash, err := scripthash.Resolve(ctx, scripthash.KindP2PKH, address)
 		if err != nil {
 			log.ErrorWithContext(ctx, "地址转换为脚本哈希失败", "address:", address, "错误:", err)
 			resultChan <- &AsyncUtxoResult{
@@ -70,6 +75,16 @@ func (l *AddressLogic) GetAddressUnspentUtxos(ctx context.Context, address strin
 
 		log.InfoWithContext(ctx, "地址已转换为脚本哈希", "address:", address, "scriptHash:", scriptHash)
 
+		// 链顶在查询UTXO前获取，之后确认的UTXO按未确认处理
+		tip, err := chaintip.Snapshot(ctx)
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取链顶失败", "错误:", err)
+			resultChan <- &AsyncUtxoResult{
+				Error: fmt.Errorf("获取链顶失败: %w", err),
+			}
+			return
+		}
+
 		// 调用RPC获取UTXO列表
 		utxos, err := rpcex.GetListUnspent(ctx, scriptHash)
 		if err != nil {
@@ -83,7 +98,10 @@ func (l *AddressLogic) GetAddressUnspentUtxos(ctx context.Context, address strin
 			return
 		}
 
-		log.InfoWithContext(ctx, "成功获取地址UTXO", "address:", address, "count:", len(utxos))
+		utxos = electrumx.FilterUtxosByHeight(utxos, chaintip.ConfirmedHeightLimit(tip, minConfirmations))
+		l.fillUtxoConfirmations(ctx, tip, utxos)
+
+		log.InfoWithContext(ctx, "成功获取地址UTXO", "address:", address, "count:", len(utxos), "minConfirmations:", minConfirmations)
 		resultChan <- &AsyncUtxoResult{
 			Utxos: utxos,
 		}
@@ -92,13 +110,55 @@ func (l *AddressLogic) GetAddressUnspentUtxos(ctx context.Context, address strin
 	return resultChan
 }
 
+// fillUtxoConfirmations 按链顶填充每个UTXO的确认数和区块时间
+// 区块时间通过一次批量请求查询，失败时只记录日志，不影响UTXO列表本身
+func (l *AddressLogic) fillUtxoConfirmations(ctx context.Context, tip chaintip.Tip, utxos electrumx.UtxoResponse) {
+	heights := make([]int64, 0, len(utxos))
+	for i := range utxos {
+		utxos[i].Confirmations = chaintip.Confirmations(tip, int64(utxos[i].Height))
+		if utxos[i].Height > 0 {
+			heights = append(heights, int64(utxos[i].Height))
+		}
+	}
+	if len(heights) == 0 {
+		return
+	}
+
+	result := <-rpcbchain.FetchBlockTimes(ctx, heights)
+	if result.Error != nil {
+		log.WarnWithContext(ctx, "获取UTXO区块时间失败", "错误:", result.Error)
+		return
+	}
+	times, _ := result.Result.(map[int64]int64)
+	for i := range utxos {
+		utxos[i].BlockTime = times[int64(utxos[i].Height)]
+	}
+}
+
 // GetAddressHistoryPage 获取地址历史交易信息（支持分页）
-func (l *AddressLogic) GetAddressHistoryPage(ctx context.Context, address string, asPage bool, page int) (*electrumx.AddressHistoryResponse, error) {
+// fromHeight大于0时只返回该高度及之后的交易，便于客户端增量同步
+// cursor为上次截断响应返回的续查游标，非空时忽略page，返回游标所在页的剩余记录
+func (l *AddressLogic) GetAddressHistoryPage(ctx context.Context, address string, asPage bool, page int, fromHeight int64,
+	cursor string) (*electrumx.AddressHistoryResponse, error) {
 	// 记录开始处理的日志
 	log.InfoWithContext(ctx, "开始获取地址的交易历史(分页模式)",
 		"address:", address,
 		"asPage:", asPage,
-		"page:", page)
+		"page:", page,
+		"fromHeight:", fromHeight,
+		"cursor:", cursor)
+
+	cursorOffset := -1
+	if cursor != "" {
+		offset, err := electrumx.ParseHistoryCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursorOffset = offset
+		if asPage {
+			page = offset / historyPageSize
+		}
+	}
 
 	// 验证地址并获取脚本哈希
 	scriptHash, err := l.validateAddressAndGetScriptHash(ctx, address)
@@ -107,13 +167,13 @@ func (l *AddressLogic) GetAddressHistoryPage(ctx context.Context, address string
 	}
 
 	// 获取交易历史记录并分页
-	historyCount, neededItems, err := l.getPagedHistory(ctx, address, scriptHash, asPage, page)
+	historyCount, start, neededItems, err := l.getPagedHistory(ctx, address, scriptHash, asPage, page, fromHeight, cursorOffset)
 	if err != nil {
 		return nil, err
 	}
 
-	// 处理历史记录，创建结果列表
-	result := l.processHistoryItems(ctx, address, neededItems)
+	// 处理历史记录，创建结果列表；截止时间前未处理完时只返回已完成的部分
+	result, completed := l.processHistoryItems(ctx, address, neededItems)
 
 	// 按时间戳排序
 	l.sortHistoryByTimestamp(result)
@@ -124,6 +184,15 @@ func (l *AddressLogic) GetAddressHistoryPage(ctx context.Context, address string
 		Script:       scriptHash,
 		HistoryCount: historyCount,
 		Result:       result,
+		Meta:         historyPageMeta(asPage, page, historyCount),
+	}
+	if completed < len(neededItems) {
+		response.Truncated = true
+		response.NextCursor = electrumx.EncodeHistoryCursor(start + completed)
+		log.WarnWithContext(ctx, "截止时间前未能处理完地址交易历史，返回部分结果",
+			"address:", address,
+			"completed:", completed,
+			"needed:", len(neededItems))
 	}
 
 	log.InfoWithContext(ctx, "成功获取地址交易历史(分页模式)",
@@ -136,6 +205,14 @@ func (l *AddressLogic) GetAddressHistoryPage(ctx context.Context, address string
 	return response, nil
 }
 
+// historyPageMeta 生成地址历史的分页元数据，非分页模式只返回最近的记录，不生成元数据
+func historyPageMeta(asPage bool, page, historyCount int) *utility.PageMeta {
+	if !asPage {
+		return nil
+	}
+	return utility.NewPageMeta(page, historyPageSize, int64(historyCount))
+}
+
 // validateAddressAndGetScriptHash 验证地址合法性并获取脚本哈希
 func (l *AddressLogic) validateAddressAndGetScriptHash(ctx context.Context, address string) (string, error) {
 	// 验证地址合法性
@@ -148,7 +225,7 @@ func (l *AddressLogic) validateAddressAndGetScriptHash(ctx context.Context, addr
 	log.InfoWithContext(ctx, "地址验证通过", "address:", address, "type:", addrType)
 
 	// 将地址转换为脚本哈希
-	scriptHash, err := utility.AddressToScriptHash(address)
+	scriptHash, err := scripthash.Resolve(ctx, scripthash.KindP2PKH, address)
 	if err != nil {
 		log.ErrorWithContext(ctx, "地址转换为脚本哈希失败", "address:", address, "错误:", err)
 		return "", fmt.Errorf("地址转换失败: %w", err)
@@ -158,37 +235,46 @@ func (l *AddressLogic) validateAddressAndGetScriptHash(ctx context.Context, addr
 	return scriptHash, nil
 }
 
-// getPagedHistory 获取历史记录并应用分页
-func (l *AddressLogic) getPagedHistory(ctx context.Context, address, scriptHash string, asPage bool, page int) (
+// getPagedHistory 获取历史记录并应用分页，start为本次处理的第一条记录在从新到旧列表中的偏移量
+// cursorOffset不小于0时从该偏移量开始，取到其所在页的末尾
+func (l *AddressLogic) getPagedHistory(ctx context.Context, address, scriptHash string, asPage bool, page int, fromHeight int64,
+	cursorOffset int) (
 	historyCount int,
+	start int,
 	neededItems electrumx.ElectrumXHistoryResponse,
 	err error,
 ) {
-	// 获取交易历史记录
-	historyResponse, err := rpcex.GetScriptHashHistory(ctx, scriptHash)
+	// 分页从新到旧取记录，只需保留最新的end条，不在内存中保留完整历史
+	keep := historyTailSize
+	if asPage {
+		keep = (page + 1) * historyPageSize
+	}
+	historyResponse, historyCount, err := rpcex.GetScriptHashHistoryTail(ctx, scriptHash, fromHeight, keep)
 	if err != nil {
 		log.ErrorWithContext(ctx, "获取交易历史失败",
 			"address:", address,
 			"scriptHash:", scriptHash,
 			"错误:", err)
-		return 0, nil, fmt.Errorf("获取交易历史失败: %w", err)
+		return 0, 0, nil, fmt.Errorf("获取交易历史失败: %w", err)
 	}
 
 	// 交易数量
-	historyCount = len(historyResponse)
 	log.InfoWithContext(ctx, "获取到交易历史记录数",
 		"address:", address,
 		"count:", historyCount)
 
-	// 反转历史记录顺序（从新到旧）
+	// 反转历史记录顺序（从新到旧），反转后的下标即在完整历史中从新到旧的偏移量
 	for i, j := 0, len(historyResponse)-1; i < j; i, j = i+1, j-1 {
 		historyResponse[i], historyResponse[j] = historyResponse[j], historyResponse[i]
 	}
 
 	// 根据分页参数获取需要处理的记录
 	if asPage {
-		start := page * 10
-		end := start + 10
+		start = page * historyPageSize
+		end := start + historyPageSize
+		if cursorOffset >= 0 {
+			start = cursorOffset
+		}
 		// 确保不会越界
 		if start < len(historyResponse) {
 			if end > len(historyResponse) {
@@ -200,25 +286,28 @@ func (l *AddressLogic) getPagedHistory(ctx context.Context, address, scriptHash
 			log.InfoWithContext(ctx, "请求的页码超出历史记录范围",
 				"address:", address,
 				"page:", page,
-				"total_records:", len(historyResponse))
+				"total_records:", historyCount)
 			neededItems = make(electrumx.ElectrumXHistoryResponse, 0)
 		}
 	} else {
-		if len(historyResponse) > 30 {
-			neededItems = historyResponse[:30]
+		start = max(cursorOffset, 0)
+		end := len(historyResponse)
+		if start < end {
+			neededItems = historyResponse[start:end]
 		} else {
-			neededItems = historyResponse
+			neededItems = make(electrumx.ElectrumXHistoryResponse, 0)
 		}
 	}
 
-	return historyCount, neededItems, nil
+	return historyCount, start, neededItems, nil
 }
 
 // processHistoryItems 处理历史交易记录（使用并发工作池）
-func (l *AddressLogic) processHistoryItems(ctx context.Context, address string, neededItems electrumx.ElectrumXHistoryResponse) []electrumx.HistoryItem {
+// 请求带有截止时间时只处理到截止时间之前，返回按原顺序连续完成的记录数，调用方据此判断是否截断
+func (l *AddressLogic) processHistoryItems(ctx context.Context, address string, neededItems electrumx.ElectrumXHistoryResponse) ([]electrumx.HistoryItem, int) {
 	// 如果没有需要处理的项，则返回空结果
 	if len(neededItems) == 0 {
-		return []electrumx.HistoryItem{}
+		return []electrumx.HistoryItem{}, 0
 	}
 
 	// 记录开始处理时间，用于性能监控
@@ -228,33 +317,31 @@ func (l *AddressLogic) processHistoryItems(ctx context.Context, address string,
 		"items_count:", len(neededItems))
 
 	// 创建历史交易处理器函数
-	processor := func(ctx context.Context, item electrumx.ElectrumXHistoryItem) (electrumx.HistoryItem, error) {
+	processor := func(ctx context.Context, item electrumx.ElectrumXHistoryItem) (electrumx.HistoryItem, bool) {
 		log.InfoWithContext(ctx, "处理交易项", "txid:", item.TxHash)
-		historyItem, ok := l.processTransactionItem(ctx, address, item)
-		if !ok {
-			return electrumx.HistoryItem{}, fmt.Errorf("处理交易 %s 失败", item.TxHash)
-		}
-		return historyItem, nil
+		return l.processTransactionItem(ctx, address, item)
 	}
 
-	// 使用工作池处理所有交易记录，并发数为10
-	results, errors := utility.WorkerPoolWithContext(ctx, neededItems, 10, processor)
+	// 使用工作池处理交易记录，并发数为10
+	results, completed := processUntilDeadline(ctx, neededItems, 10, processor)
 
 	// 记录处理结果统计
+	failed := completed - len(results)
 	log.InfoWithContext(ctx, "历史交易处理统计",
 		"address:", address,
 		"successful:", len(results),
-		"errors:", len(errors),
+		"errors:", failed,
+		"unfinished:", len(neededItems)-completed,
 		"total_duration_ms:", time.Since(startTime).Milliseconds())
 
-	if len(errors) > 0 {
+	if failed > 0 {
 		// 记录处理失败的交易信息，但继续返回成功的结果
 		log.WarnWithContext(ctx, "部分交易处理失败",
 			"address:", address,
-			"error_count:", len(errors))
+			"error_count:", failed)
 	}
 
-	return results
+	return results, completed
 }
 
 // processTransactionItem 处理单个交易记录
@@ -285,11 +372,9 @@ func (l *AddressLogic) processTransactionItem(ctx context.Context, address strin
 	// 创建发送方和接收方集合
 	senders := make(map[string]bool)
 	receivers := make(map[string]bool)
-	ifTypeDetected := false
-	txType := "P2PKH"
 
 	// 获取总接收量和接收方
-	l.processTransactionOutputs(ctx, address, decodedInfo, &totalReceive, &balanceChange, receivers, &ifTypeDetected, &txType)
+	l.processTransactionOutputs(ctx, address, decodedInfo, &totalReceive, &balanceChange, receivers)
 
 	// 获取总支出和发送方
 	l.processTransactionInputs(ctx, address, decodedInfo, &totalSpend, &balanceChange, senders)
@@ -312,12 +397,32 @@ func (l *AddressLogic) processTransactionItem(ctx context.Context, address strin
 		Fee:                feeStr,
 		TimeStamp:          timeStamp,
 		UtcTime:            utcTime,
-		TxType:             txType,
+		TxType:             classifyTransaction(decodedInfo),
 	}
 
 	return historyItem, true
 }
 
+// classifyTransaction 判断交易类型，与数据库路径中的tx_type使用同一套分类规则
+func classifyTransaction(decodedInfo *blockchain.TransactionResponse) transaction.TxType {
+	isCoinbase := false
+	for _, vin := range decodedInfo.Vin {
+		if vin.Txid == "" {
+			isCoinbase = true
+			break
+		}
+	}
+
+	outputs := make([]transaction.TxOutputScript, 0, len(decodedInfo.Vout))
+	for _, output := range decodedInfo.Vout {
+		outputs = append(outputs, transaction.TxOutputScript{
+			Type: output.ScriptPubKey.Type,
+			Asm:  output.ScriptPubKey.Asm,
+		})
+	}
+	return transaction.ClassifyTx(isCoinbase, outputs)
+}
+
 // processTransactionOutputs 处理交易输出
 func (l *AddressLogic) processTransactionOutputs(
 	ctx context.Context,
@@ -326,41 +431,37 @@ func (l *AddressLogic) processTransactionOutputs(
 	totalReceive *int64,
 	balanceChange *int64,
 	receivers map[string]bool,
-	ifTypeDetected *bool,
-	txType *string,
 ) {
 	log.InfoWithContext(ctx, "开始处理交易输出", "address:", address)
+	contractDetected := false
 	for _, output := range decodedInfo.Vout {
 		// 将BTC转换为聪（1 BTC = 1,000,000 聪）
 		valueGet := int64(math.Round(output.Value * 1000000))
 		*totalReceive += valueGet
 
 		// 处理不同类型的输出脚本
-		if output.ScriptPubKey.Type == "pubkeyhash" {
+		asm := output.ScriptPubKey.Asm
+		switch transaction.ClassifyOutput(transaction.TxOutputScript{Type: output.ScriptPubKey.Type, Asm: asm}) {
+		case transaction.TxTypeP2PKH:
 			for _, addr := range output.ScriptPubKey.Addresses {
 				receivers[addr] = true
 				if addr == address {
 					*balanceChange += valueGet
 				}
 			}
-		} else if strings.HasPrefix(output.ScriptPubKey.Asm, "9 OP_PICK OP_TOALTSTACK") {
-			if !*ifTypeDetected {
-				*ifTypeDetected = true
-				*txType = "TBC20"
-			}
-			if strings.HasSuffix(output.ScriptPubKey.Asm, "01 32436f6465") {
-				poolContractID := "Pool_" + output.ScriptPubKey.Asm[len(output.ScriptPubKey.Asm)-53:len(output.ScriptPubKey.Asm)-11]
-				receivers[poolContractID] = true
+		case transaction.TxTypePool:
+			contractDetected = true
+			poolContractID := "Pool_" + asm[len(asm)-53:len(asm)-11]
+			receivers[poolContractID] = true
+		case transaction.TxTypeTBC20, transaction.TxTypeTBC721:
+			contractDetected = true
+		case transaction.TxTypeP2MS:
+			// 只有第一个合约类输出是多签时才计入接收方
+			if contractDetected {
+				continue
 			}
-		} else if (strings.HasPrefix(output.ScriptPubKey.Asm, "OP_RETURN") ||
-			strings.HasPrefix(output.ScriptPubKey.Asm, "0 OP_RETURN") ||
-			strings.HasPrefix(output.ScriptPubKey.Asm, "1 OP_PICK")) && !*ifTypeDetected {
-			*ifTypeDetected = true
-			*txType = "TBC721"
-		} else if strings.HasSuffix(output.ScriptPubKey.Asm, "OP_CHECKMULTISIG") && !*ifTypeDetected {
-			*ifTypeDetected = true
-			*txType = "P2MS"
-			msAddress, err := utility.ConvertP2msScriptToMsAddress(output.ScriptPubKey.Asm)
+			contractDetected = true
+			msAddress, err := utility.ConvertP2msScriptToMsAddress(asm)
 			if err == nil {
 				receivers[msAddress] = true
 				if msAddress == address {
@@ -380,6 +481,23 @@ func (l *AddressLogic) processTransactionInputs(
 	balanceChange *int64,
 	senders map[string]bool,
 ) {
+	// 批量获取全部输入引用的前序交易，避免逐个输入往返节点
+	vinTxids := make([]string, 0, len(decodedInfo.Vin))
+	for _, vin := range decodedInfo.Vin {
+		if vin.Txid != "" {
+			vinTxids = append(vinTxids, vin.Txid)
+		}
+	}
+	var vinTxs map[string]*blockchain.TransactionResponse
+	if len(vinTxids) > 0 {
+		result := <-rpcbchain.DecodeTxs(ctx, vinTxids)
+		if result.Error != nil {
+			log.WarnWithContext(ctx, "批量获取输入交易详情失败", "txid:", decodedInfo.Txid, "错误:", result.Error)
+			return
+		}
+		vinTxs, _ = result.Result.(map[string]*blockchain.TransactionResponse)
+	}
+
 	for _, vin := range decodedInfo.Vin {
 		if vin.Txid == "" {
 			// coinbase交易
@@ -390,19 +508,9 @@ func (l *AddressLogic) processTransactionInputs(
 			vinVout := vin.Vout
 
 			// 获取前一个交易的输出信息
-			ResultChan := rpcbchain.DecodeTx(ctx, vinTxid)
-			result := <-ResultChan
-			if result.Error != nil {
-				log.WarnWithContext(ctx, "获取输入交易详情失败",
-					"vin_txid:", vinTxid,
-					"错误:", result.Error)
-				continue
-			}
-
-			// 类型断言获取交易详情
-			vinDecoded, ok := result.Result.(*blockchain.TransactionResponse)
+			vinDecoded, ok := vinTxs[vinTxid]
 			if !ok {
-				log.WarnWithContext(ctx, "输入交易详情类型转换失败", "vin_txid:", vinTxid)
+				log.WarnWithContext(ctx, "获取输入交易详情失败", "vin_txid:", vinTxid)
 				continue
 			}
 
@@ -458,8 +566,7 @@ func (l *AddressLogic) buildAddressesAndFee(
 	senderAddresses = make([]string, 0)
 
 	// 计算交易手续费
-	fee := float64(totalSpend-totalReceive) / 1000000
-	feeStr = strconv.FormatFloat(fee, 'f', -1, 64)
+	feeStr = utility.FormatUnits(totalSpend-totalReceive, 6, false)
 
 	// 确定发送方和接收方
 	if balanceChange < 0 {
@@ -529,18 +636,7 @@ func (l *AddressLogic) getTransactionTimestamp(ctx context.Context, item electru
 
 // formatBalanceChange 格式化余额变化
 func (l *AddressLogic) formatBalanceChange(balanceChange int64) string {
-	balanceFloat := new(big.Float).Quo(
-		new(big.Float).SetInt64(balanceChange),
-		new(big.Float).SetInt64(1000000))
-	balanceFloatStr := fmt.Sprintf("%+.6f", balanceFloat)
-	balanceFloatStr = strings.TrimRight(balanceFloatStr, "0")
-	balanceFloatStr = strings.TrimRight(balanceFloatStr, ".")
-
-	if balanceFloatStr == "+" || balanceFloatStr == "" {
-		balanceFloatStr = "0"
-	}
-
-	return balanceFloatStr
+	return utility.FormatUnits(balanceChange, 6, true)
 }
 
 // sortHistoryByTimestamp 按时间戳排序历史记录
@@ -636,16 +732,14 @@ func (l *AddressLogic) GetAddressFrozenBalance(ctx context.Context, address stri
 }
 
 // GetAddressHistoryPageFromDB 获取地址历史交易信息，从数据库查询（支持分页）
-func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address string, asPage bool, page int) (*electrumx.AddressHistoryResponse, error) {
+// fromHeight大于0时以ElectrumX返回的增量历史为准，再按交易哈希查询数据库
+func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address string, asPage bool, page int, fromHeight int64) (*electrumx.AddressHistoryResponse, error) {
 	// 记录开始处理的日志
 	log.InfoWithContext(ctx, "开始获取地址的交易历史(数据库异步模式)",
 		"address:", address,
 		"asPage:", asPage,
-		"page:", page)
-
-	// 创建上下文，允许取消操作
-	ctxWithCancel, cancel := context.WithCancel(ctx)
-	defer cancel()
+		"page:", page,
+		"fromHeight:", fromHeight)
 
 	// 验证地址并获取脚本哈希
 	scriptHash, err := l.validateAddressAndGetScriptHash(ctx, address)
@@ -653,70 +747,44 @@ func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address
 		return nil, err
 	}
 
+	return l.getHistoryFromDB(ctx, defaultHistorySource, address, scriptHash, asPage, page, fromHeight, dbQueryTimeout())
+}
+
+// getHistoryFromDB 并发查询ElectrumX历史和数据库并组装结果
+// 每个查询使用独立的超时，任何一个查询失败、超时或请求取消时立即返回，不再等待其余查询
+func (l *AddressLogic) getHistoryFromDB(ctx context.Context, src historySource, address, scriptHash string, asPage bool, page int, fromHeight int64,
+	timeout time.Duration) (*electrumx.AddressHistoryResponse, error) {
+	// 创建上下文，返回时取消仍在执行的查询
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// 设置分页参数
-	limit := 10
+	limit := historyPageSize
 	if !asPage {
 		limit = 30
 	}
 	offset := page * limit
 
-	// 定义结果通道
-	type queryResult struct {
-		historyResponse electrumx.ElectrumXHistoryResponse
-		addrTxs         []*dbtable.AddressTransaction
-		txDetails       []*dbtable.Transaction
-		participants    []*dbtable.TransactionParticipant
-		err             error
-		queryType       string
-	}
-
-	resultChan := make(chan queryResult, 4)
-
 	// 异步查询交易历史总数
-	go func() {
-		historyResponse, err := rpcex.GetScriptHashHistory(ctxWithCancel, scriptHash)
-		if err != nil {
-			log.ErrorWithContext(ctx, "获取交易历史失败",
-				"address:", address,
-				"scriptHash:", scriptHash,
-				"错误:", err)
-			resultChan <- queryResult{err: fmt.Errorf("获取交易历史失败: %w", err), queryType: "historyResponse"}
-			return
-		}
-		resultChan <- queryResult{historyResponse: historyResponse, queryType: "historyResponse"}
-	}()
-
-	// 异步查询地址交易列表
-	go func() {
-		addrTxs, err := address_transactions_dao.GetAddressTransactions(ctxWithCancel, address, offset, limit)
-		if err != nil {
-			log.ErrorWithContext(ctx, "查询地址交易记录失败",
-				"address:", address,
-				"offset:", offset,
-				"limit:", limit,
-				"错误:", err)
-			resultChan <- queryResult{err: fmt.Errorf("查询地址交易记录失败: %w", err), queryType: "addrTxs"}
-			return
-		}
-		resultChan <- queryResult{addrTxs: addrTxs, queryType: "addrTxs"}
-	}()
-
-	// 等待前两个查询结果完成
-	var historyResponse electrumx.ElectrumXHistoryResponse
-	var addrTxs []*dbtable.AddressTransaction
+	historyQuery := startQuery(ctxWithCancel, timeout, "获取交易历史", func(ctx context.Context) (electrumx.ElectrumXHistoryResponse, error) {
+		return src.history(ctx, scriptHash, fromHeight, 0)
+	})
 
-	for i := 0; i < 2; i++ {
-		result := <-resultChan
-		if result.err != nil {
-			return nil, result.err
-		}
+	// 异步查询地址交易列表，增量模式下需等待历史记录返回后再查询
+	var addrTxsQuery *pendingQuery[[]*dbtable.AddressTransaction]
+	if fromHeight == 0 {
+		addrTxsQuery = startQuery(ctxWithCancel, timeout, "查询地址交易记录", func(ctx context.Context) ([]*dbtable.AddressTransaction, error) {
+			return src.addressTransactions(ctx, address, offset, limit)
+		})
+	}
 
-		switch result.queryType {
-		case "historyResponse":
-			historyResponse = result.historyResponse
-		case "addrTxs":
-			addrTxs = result.addrTxs
-		}
+	historyResponse, err := historyQuery.wait()
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取交易历史失败",
+			"address:", address,
+			"scriptHash:", scriptHash,
+			"错误:", err)
+		return nil, err
 	}
 
 	// 交易数量
@@ -725,6 +793,22 @@ func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address
 		"address:", address,
 		"count:", historyCount)
 
+	// 增量模式：按ElectrumX历史分页后，再根据交易哈希查询数据库
+	if fromHeight > 0 {
+		addrTxsQuery = startQuery(ctxWithCancel, timeout, "按交易哈希查询地址交易记录", func(ctx context.Context) ([]*dbtable.AddressTransaction, error) {
+			return l.getAddressTransactionsFromHistory(ctx, src, address, historyResponse, offset, limit)
+		})
+	}
+	addrTxs, err := addrTxsQuery.wait()
+	if err != nil {
+		log.ErrorWithContext(ctx, "查询地址交易记录失败",
+			"address:", address,
+			"offset:", offset,
+			"limit:", limit,
+			"错误:", err)
+		return nil, err
+	}
+
 	// 如果没有交易记录，返回空结果
 	if len(addrTxs) == 0 {
 		log.InfoWithContext(ctx, "地址没有交易记录",
@@ -736,6 +820,7 @@ func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address
 			Script:       scriptHash,
 			HistoryCount: int(historyCount),
 			Result:       []electrumx.HistoryItem{},
+			Meta:         historyPageMeta(asPage, page, historyCount),
 		}, nil
 	}
 
@@ -745,50 +830,29 @@ func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address
 		txHashes = append(txHashes, tx.TxHash)
 	}
 
-	// 异步查询交易详情
-	go func() {
-		txDetails, err := transactions_dao.GetTransactionsByTxHashes(ctxWithCancel, txHashes)
-		if err != nil {
-			log.ErrorWithContext(ctx, "查询交易详情失败",
-				"address:", address,
-				"txHashes:", txHashes,
-				"错误:", err)
-			resultChan <- queryResult{err: fmt.Errorf("查询交易详情失败: %w", err), queryType: "txDetails"}
-			return
-		}
-		resultChan <- queryResult{txDetails: txDetails, queryType: "txDetails"}
-	}()
-
-	// 异步查询交易参与方
-	go func() {
-		participants, err := transaction_participants_dao.GetParticipantsByTxHashes(ctxWithCancel, txHashes)
-		if err != nil {
-			log.ErrorWithContext(ctx, "查询交易参与方失败",
-				"address:", address,
-				"txHashes:", txHashes,
-				"错误:", err)
-			resultChan <- queryResult{err: fmt.Errorf("查询交易参与方失败: %w", err), queryType: "participants"}
-			return
-		}
-		resultChan <- queryResult{participants: participants, queryType: "participants"}
-	}()
-
-	// 等待后两个查询结果
-	var txDetails []*dbtable.Transaction
-	var participants []*dbtable.TransactionParticipant
-
-	for i := 0; i < 2; i++ {
-		result := <-resultChan
-		if result.err != nil {
-			return nil, result.err
-		}
+	// 异步查询交易详情和交易参与方
+	txDetailsQuery := startQuery(ctxWithCancel, timeout, "查询交易详情", func(ctx context.Context) ([]*dbtable.Transaction, error) {
+		return src.transactions(ctx, txHashes)
+	})
+	participantsQuery := startQuery(ctxWithCancel, timeout, "查询交易参与方", func(ctx context.Context) ([]*dbtable.TransactionParticipant, error) {
+		return src.participants(ctx, txHashes)
+	})
 
-		switch result.queryType {
-		case "txDetails":
-			txDetails = result.txDetails
-		case "participants":
-			participants = result.participants
-		}
+	txDetails, err := txDetailsQuery.wait()
+	if err != nil {
+		log.ErrorWithContext(ctx, "查询交易详情失败",
+			"address:", address,
+			"txHashes:", txHashes,
+			"错误:", err)
+		return nil, err
+	}
+	participants, err := participantsQuery.wait()
+	if err != nil {
+		log.ErrorWithContext(ctx, "查询交易参与方失败",
+			"address:", address,
+			"txHashes:", txHashes,
+			"错误:", err)
+		return nil, err
 	}
 
 	log.InfoWithContext(ctx, "异步查询完成",
@@ -808,6 +872,7 @@ func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address
 		Script:       scriptHash,
 		HistoryCount: int(historyCount),
 		Result:       result,
+		Meta:         historyPageMeta(asPage, page, historyCount),
 	}
 
 	log.InfoWithContext(ctx, "成功获取地址交易历史(数据库异步模式)",
@@ -820,6 +885,33 @@ func (l *AddressLogic) GetAddressHistoryPageFromDB(ctx context.Context, address
 	return response, nil
 }
 
+// getAddressTransactionsFromHistory 对ElectrumX历史记录（从新到旧）分页后，按交易哈希查询地址交易记录
+func (l *AddressLogic) getAddressTransactionsFromHistory(
+	ctx context.Context,
+	src historySource,
+	address string,
+	historyResponse electrumx.ElectrumXHistoryResponse,
+	offset, limit int,
+) ([]*dbtable.AddressTransaction, error) {
+	txHashes := make([]string, 0, limit)
+	for i := len(historyResponse) - 1 - offset; i >= 0 && len(txHashes) < limit; i-- {
+		txHashes = append(txHashes, historyResponse[i].TxHash)
+	}
+	if len(txHashes) == 0 {
+		return []*dbtable.AddressTransaction{}, nil
+	}
+
+	addrTxs, err := src.addressTransactionsByTxHashes(ctx, address, txHashes)
+	if err != nil {
+		log.ErrorWithContext(ctx, "按交易哈希查询地址交易记录失败",
+			"address:", address,
+			"错误:", err)
+		return nil, fmt.Errorf("查询地址交易记录失败: %w", err)
+	}
+
+	return addrTxs, nil
+}
+
 // buildHistoryItemsFromDB 从数据库查询结果构建历史记录项
 func (l *AddressLogic) buildHistoryItemsFromDB(
 	ctx context.Context,
@@ -875,17 +967,10 @@ func (l *AddressLogic) buildHistoryItemsFromDB(
 		}
 
 		// 格式化余额变化
-		balanceChange := fmt.Sprintf("%+.6f", addrTx.BalanceChange)
-		balanceChange = strings.TrimRight(balanceChange, "0")
-		balanceChange = strings.TrimRight(balanceChange, ".")
-		if balanceChange == "+" || balanceChange == "" {
-			balanceChange = "0"
-		}
+		balanceChange := utility.FormatDecimal(addrTx.BalanceChange, 6, true)
 
 		// 格式化手续费
-		feeStr := fmt.Sprintf("%.8f", tx.Fee)
-		feeStr = strings.TrimRight(feeStr, "0")
-		feeStr = strings.TrimRight(feeStr, ".")
+		feeStr := utility.FormatDecimal(tx.Fee, 8, false)
 
 		// 创建历史记录项
 		historyItem := electrumx.HistoryItem{
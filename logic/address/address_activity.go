@@ -0,0 +1,123 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/electrumx"
+	"ginproject/entity/event"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/address_activity_dao"
+	"ginproject/repo/eventbus"
+)
+
+// 地址活跃度索引在事件总线上的消费者名称
+const activityConsumer = "address_activity"
+
+// StartActivityIndexer 以address_activity消费者的身份订阅交易事件，维护地址首次出现和最近活动的区块，ctx取消时退出
+// 写入失败时事件总线会重新投递同一批事件，合并规则保证重复写入结果不变；未连接数据库时不做任何事
+func StartActivityIndexer(ctx context.Context) {
+	if db.GetDB() == nil {
+		return
+	}
+	if !config.GetConfig().GetEventBusConfig().Publish {
+		log.WarnWithContext(ctx, "事件发布未开启，地址活跃度不会更新")
+	}
+
+	if err := eventbus.Default().Subscribe(ctx, activityConsumer, event.TopicTx, indexActivity); err != nil {
+		log.WarnWithContext(ctx, "订阅交易事件失败", "错误:", err)
+	}
+}
+
+// indexActivity 将一批交易事件按地址合并后写入索引表，无法解析的事件记录日志后跳过
+func indexActivity(ctx context.Context, events []eventbus.Event) error {
+	txs := make([]event.TxEvent, 0, len(events))
+	for _, e := range events {
+		var tx event.TxEvent
+		if err := json.Unmarshal(e.Payload, &tx); err != nil {
+			log.WarnWithContext(ctx, "跳过无法解析的交易事件", "offset:", e.Offset, "错误:", err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return address_activity_dao.UpsertAddressActivity(ctx, mergeActivity(txs))
+}
+
+// mergeActivity 按地址合并交易事件，同一地址只保留最早和最晚出现的交易，同一区块内按交易位置比较
+func mergeActivity(txs []event.TxEvent) []*dbtable.AddressActivity {
+	type position struct {
+		height int64
+		index  int
+	}
+	var (
+		activities []*dbtable.AddressActivity
+		byAddress  = make(map[string]*dbtable.AddressActivity)
+		first      = make(map[string]position)
+		last       = make(map[string]position)
+	)
+	for _, tx := range txs {
+		pos := position{height: tx.Height, index: tx.Index}
+		for _, address := range tx.Addresses {
+			a, ok := byAddress[address]
+			if !ok {
+				a = &dbtable.AddressActivity{
+					Address:         address,
+					FirstSeenHeight: tx.Height, FirstSeenTime: tx.BlockTime, FirstSeenTx: tx.Txid,
+					LastActiveHeight: tx.Height, LastActiveTime: tx.BlockTime, LastActiveTx: tx.Txid,
+				}
+				byAddress[address] = a
+				first[address], last[address] = pos, pos
+				activities = append(activities, a)
+				continue
+			}
+			if f := first[address]; pos.height < f.height || (pos.height == f.height && pos.index < f.index) {
+				a.FirstSeenHeight, a.FirstSeenTime, a.FirstSeenTx = tx.Height, tx.BlockTime, tx.Txid
+				first[address] = pos
+			}
+			if l := last[address]; pos.height > l.height || (pos.height == l.height && pos.index > l.index) {
+				a.LastActiveHeight, a.LastActiveTime, a.LastActiveTx = tx.Height, tx.BlockTime, tx.Txid
+				last[address] = pos
+			}
+		}
+	}
+	return activities
+}
+
+// GetAddressSummary 获取地址余额和活跃区间，活跃区间从索引表读取，不需要拉取完整历史
+func (l *AddressLogic) GetAddressSummary(ctx context.Context, address string) (*electrumx.AddressSummaryResponse, error) {
+	balance, err := l.GetAddressBalance(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &electrumx.AddressSummaryResponse{
+		Address:     address,
+		Balance:     balance.Balance,
+		Confirmed:   balance.Confirmed,
+		Unconfirmed: balance.Unconfirmed,
+	}
+	if db.GetDB() == nil {
+		return response, nil
+	}
+
+	activity, err := address_activity_dao.GetAddressActivity(ctx, address)
+	if errors.Is(err, db.ErrAddressActivityNotFound) {
+		return response, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取地址活跃度失败: %w", err)
+	}
+	response.Indexed = true
+	response.FirstSeenHeight = activity.FirstSeenHeight
+	response.FirstSeenTime = activity.FirstSeenTime
+	response.FirstSeenTx = activity.FirstSeenTx
+	response.LastActiveHeight = activity.LastActiveHeight
+	response.LastActiveTime = activity.LastActiveTime
+	response.LastActiveTx = activity.LastActiveTx
+	return response, nil
+}
@@ -0,0 +1,32 @@
+package address
+
+import (
+	"testing"
+
+	"ginproject/entity/event"
+)
+
+func TestMergeActivity(t *testing.T) {
+	txs := []event.TxEvent{
+		{Height: 101, Index: 2, BlockTime: 1100, Txid: "c", Addresses: []string{"1a"}},
+		{Height: 100, Index: 5, BlockTime: 1000, Txid: "b", Addresses: []string{"1a", "1b"}},
+		{Height: 100, Index: 1, BlockTime: 1000, Txid: "a", Addresses: []string{"1a"}},
+		{Height: 101, Index: 0, BlockTime: 1100, Txid: "d", Addresses: []string{"1a"}},
+	}
+
+	activities := mergeActivity(txs)
+	if len(activities) != 2 {
+		t.Fatalf("地址数 = %d, 期望 2", len(activities))
+	}
+	a := activities[0]
+	if a.Address != "1a" || a.FirstSeenHeight != 100 || a.FirstSeenTx != "a" || a.FirstSeenTime != 1000 {
+		t.Errorf("首次出现 = %+v, 期望高度100的交易a", a)
+	}
+	if a.LastActiveHeight != 101 || a.LastActiveTx != "c" || a.LastActiveTime != 1100 {
+		t.Errorf("最近活动 = %+v, 期望高度101的交易c", a)
+	}
+	b := activities[1]
+	if b.FirstSeenTx != "b" || b.LastActiveTx != "b" {
+		t.Errorf("只出现一次的地址 = %+v, 期望首次和最近都是交易b", b)
+	}
+}
@@ -0,0 +1,272 @@
+package address
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/electrumx"
+	"ginproject/logic/job"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	rpcex "ginproject/repo/rpc/electrumx"
+	"ginproject/repo/storage"
+)
+
+const (
+	// 导出任务的类型名称
+	historyExportKind = "address_history_export"
+	// 每批并发解析的交易数
+	historyExportChunkSize = 100
+	// 未配置时的默认值
+	defaultExportDir       = "./exports"
+	defaultExportURLTTL    = time.Hour
+	defaultExportRetention = 24 * time.Hour
+	defaultExportMaxJobs   = 4
+)
+
+// ExportDownload 导出文件的签名下载参数
+type ExportDownload struct {
+	Expires   int64
+	Signature string
+}
+
+var (
+	exportOnce    sync.Once
+	exportJobs    *job.Manager
+	exportStore   storage.ObjectStore
+	exportSigner  *storage.Signer
+	exportURLTTL  time.Duration
+	exportInitErr error
+)
+
+// initExport 按配置初始化导出任务管理器、存储和签名器
+func initExport() error {
+	exportOnce.Do(func() {
+		cfg := config.GetConfig().GetExportConfig()
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultExportDir
+		}
+		retention := time.Duration(cfg.Retention) * time.Second
+		if retention <= 0 {
+			retention = defaultExportRetention
+		}
+		exportURLTTL = time.Duration(cfg.URLTTL) * time.Second
+		if exportURLTTL <= 0 {
+			exportURLTTL = defaultExportURLTTL
+		}
+		maxJobs := cfg.MaxJobs
+		if maxJobs <= 0 {
+			maxJobs = defaultExportMaxJobs
+		}
+
+		if s3 := cfg.S3; s3.Bucket != "" {
+			exportStore, exportInitErr = storage.NewS3Store(storage.S3Options{
+				Endpoint:  s3.Endpoint,
+				Region:    s3.Region,
+				Bucket:    s3.Bucket,
+				AccessKey: s3.AccessKey,
+				SecretKey: s3.SecretKey,
+				Prefix:    s3.Prefix,
+				PathStyle: s3.PathStyle,
+			})
+		} else {
+			if cache.Shared() != nil {
+				log.Warn("集群模式下导出文件写入本地目录，只有该目录在各实例间共享时才能从任意实例下载:", dir)
+			}
+			exportStore, exportInitErr = storage.NewLocalStore(dir)
+		}
+		exportJobs = job.NewManager(maxJobs, retention)
+		exportSigner = storage.NewSigner(cfg.SigningKey)
+		// 集群模式下任务状态写入Redis，任意实例都可以查询
+		if shared := cache.Shared(); shared != nil {
+			exportJobs.SetStore(job.NewRedisStore(shared, decodeHistoryExportResult))
+		}
+	})
+	return exportInitErr
+}
+
+// decodeHistoryExportResult 解码共享存储中的导出任务结果
+func decodeHistoryExportResult(data []byte) (any, error) {
+	var result electrumx.HistoryExportResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// exportObjectKey 返回任务导出文件的对象键
+func exportObjectKey(jobId, format string) string {
+	return jobId + "." + format
+}
+
+// StartHistoryExport 提交地址历史导出任务
+func (l *AddressLogic) StartHistoryExport(ctx context.Context, address, format string) (job.Snapshot, error) {
+	if err := initExport(); err != nil {
+		return job.Snapshot{}, err
+	}
+	format, err := electrumx.ValidateExportFormat(format)
+	if err != nil {
+		return job.Snapshot{}, err
+	}
+	scriptHash, err := l.validateAddressAndGetScriptHash(ctx, address)
+	if err != nil {
+		return job.Snapshot{}, err
+	}
+
+	run := func(ctx context.Context, progress *job.Progress) (any, error) {
+		return l.exportHistory(ctx, progress, address, scriptHash, format)
+	}
+	cleanupCtx := context.WithoutCancel(ctx)
+	cleanup := func(jobId string) {
+		if err := exportStore.Delete(cleanupCtx, exportObjectKey(jobId, format)); err != nil {
+			log.WarnWithContext(cleanupCtx, "删除过期导出文件失败", "jobId:", jobId, "错误:", err)
+		}
+	}
+
+	snapshot, err := exportJobs.Submit(ctx, historyExportKind, run, cleanup)
+	if err != nil {
+		return job.Snapshot{}, err
+	}
+	log.InfoWithContext(ctx, "已提交地址历史导出任务", "address:", address, "jobId:", snapshot.Id, "format:", format)
+	return snapshot, nil
+}
+
+// GetHistoryExport 查询导出任务状态，任务完成时返回签名下载参数
+func (l *AddressLogic) GetHistoryExport(ctx context.Context, jobId string) (job.Snapshot, *ExportDownload, error) {
+	if err := initExport(); err != nil {
+		return job.Snapshot{}, nil, err
+	}
+	snapshot, err := exportJobs.Lookup(ctx, jobId)
+	if err != nil || snapshot.Kind != historyExportKind {
+		return job.Snapshot{}, nil, job.ErrJobNotFound
+	}
+	if snapshot.Status != job.StatusSucceeded {
+		return snapshot, nil, nil
+	}
+
+	result := snapshot.Result.(*electrumx.HistoryExportResult)
+	expires := time.Now().Add(exportURLTTL)
+	return snapshot, &ExportDownload{
+		Expires:   expires.Unix(),
+		Signature: exportSigner.Sign(exportObjectKey(jobId, result.Format), expires),
+	}, nil
+}
+
+// OpenHistoryExport 校验下载签名并打开导出文件，同时返回文件格式
+func (l *AddressLogic) OpenHistoryExport(ctx context.Context, jobId string, expires int64, signature string) (storage.Object, string, error) {
+	if err := initExport(); err != nil {
+		return nil, "", err
+	}
+	snapshot, err := exportJobs.Lookup(ctx, jobId)
+	if err != nil || snapshot.Kind != historyExportKind || snapshot.Status != job.StatusSucceeded {
+		return nil, "", job.ErrJobNotFound
+	}
+
+	format := snapshot.Result.(*electrumx.HistoryExportResult).Format
+	key := exportObjectKey(jobId, format)
+	if err := exportSigner.Verify(key, expires, signature, time.Now()); err != nil {
+		return nil, "", err
+	}
+	object, err := exportStore.Open(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return object, format, nil
+}
+
+// exportHistory 分批解析地址的完整历史并写入存储，记录按时间升序，内存池交易在最后
+func (l *AddressLogic) exportHistory(ctx context.Context, progress *job.Progress, address, scriptHash, format string) (*electrumx.HistoryExportResult, error) {
+	history, err := rpcex.GetScriptHashHistoryFrom(ctx, scriptHash, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易历史失败: %w", err)
+	}
+	progress.SetTotal(int64(len(history)))
+
+	object, err := exportStore.Create(ctx, exportObjectKey(progress.JobId(), format))
+	if err != nil {
+		return nil, err
+	}
+	result, err := l.writeHistoryRows(ctx, progress, object, address, format, history)
+	if err != nil {
+		object.Abort()
+		return nil, err
+	}
+	if err := object.Close(); err != nil {
+		return nil, err
+	}
+
+	if result.Skipped > 0 {
+		log.WarnWithContext(ctx, "导出时部分交易解析失败", "address:", address, "skipped:", result.Skipped)
+	}
+	return result, nil
+}
+
+// writeHistoryRows 按批解析交易并逐行写出
+func (l *AddressLogic) writeHistoryRows(ctx context.Context, progress *job.Progress, w io.Writer, address, format string,
+	history electrumx.ElectrumXHistoryResponse) (*electrumx.HistoryExportResult, error) {
+	buffered := bufio.NewWriter(w)
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	if format == electrumx.ExportFormatCSV {
+		csvWriter = csv.NewWriter(buffered)
+		if err := csvWriter.Write(electrumx.HistoryCSVHeader); err != nil {
+			return nil, fmt.Errorf("写入导出文件失败: %w", err)
+		}
+	} else {
+		jsonEncoder = json.NewEncoder(buffered)
+	}
+
+	result := &electrumx.HistoryExportResult{Address: address, Format: format}
+	for start := 0; start < len(history); start += historyExportChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk := history[start:min(start+historyExportChunkSize, len(history))]
+
+		items, completed := l.processHistoryItems(ctx, address, chunk)
+		if completed < len(chunk) {
+			return nil, fmt.Errorf("导出任务在截止时间前未完成: %w", context.DeadlineExceeded)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			if (items[i].TimeStamp == 0) != (items[j].TimeStamp == 0) {
+				return items[j].TimeStamp == 0
+			}
+			return items[i].TimeStamp < items[j].TimeStamp
+		})
+		for _, item := range items {
+			var err error
+			if csvWriter != nil {
+				err = csvWriter.Write(item.CSVRecord())
+			} else {
+				err = jsonEncoder.Encode(item)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("写入导出文件失败: %w", err)
+			}
+		}
+
+		result.Rows += int64(len(items))
+		result.Skipped += int64(len(chunk) - len(items))
+		progress.Add(int64(len(chunk)))
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return nil, fmt.Errorf("写入导出文件失败: %w", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return nil, fmt.Errorf("写入导出文件失败: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package address
+
+import (
+	"context"
+	"time"
+
+	utility "ginproject/entity/utility"
+)
+
+// 请求截止时间前为排序、组装和写出响应预留的时间
+var partialResponseReserve = 300 * time.Millisecond
+
+// partialOutcome 单条记录的处理结果
+type partialOutcome[R any] struct {
+	index int
+	value R
+	ok    bool // 处理成功
+	done  bool // 已得出结论，截止时间到达后的失败视为未完成
+}
+
+// processUntilDeadline 使用工作池并发处理items，在请求截止时间前预留partialResponseReserve后停止
+// 返回按原顺序连续完成的前缀中处理成功的结果，以及该前缀的长度；请求没有截止时间时等待全部处理完毕
+func processUntilDeadline[T any, R any](ctx context.Context, items []T, maxWorkers int,
+	fn func(context.Context, T) (R, bool)) ([]R, int) {
+	workCtx, cancel := context.WithCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		workCtx, cancel = context.WithDeadline(ctx, deadline.Add(-partialResponseReserve))
+	}
+	defer cancel()
+
+	indices := make([]int, len(items))
+	for i := range indices {
+		indices[i] = i
+	}
+	outcomes, _ := utility.WorkerPoolWithContext(workCtx, indices, maxWorkers, func(ctx context.Context, i int) (partialOutcome[R], error) {
+		value, ok := fn(ctx, items[i])
+		return partialOutcome[R]{index: i, value: value, ok: ok, done: ok || ctx.Err() == nil}, nil
+	})
+
+	byIndex := make([]*partialOutcome[R], len(items))
+	for i := range outcomes {
+		byIndex[outcomes[i].index] = &outcomes[i]
+	}
+
+	results := make([]R, 0, len(items))
+	completed := 0
+	for ; completed < len(items); completed++ {
+		outcome := byIndex[completed]
+		if outcome == nil || !outcome.done {
+			break
+		}
+		if outcome.ok {
+			results = append(results, outcome.value)
+		}
+	}
+	return results, completed
+}
@@ -0,0 +1,43 @@
+package address
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProcessUntilDeadline(t *testing.T) {
+	reserve := partialResponseReserve
+	partialResponseReserve = 50 * time.Millisecond
+	defer func() { partialResponseReserve = reserve }()
+
+	// 第3条记录卡住直到截止时间，第2条记录处理失败
+	fn := func(ctx context.Context, item int) (int, bool) {
+		switch item {
+		case 1:
+			return 0, false
+		case 2:
+			<-ctx.Done()
+			return 0, false
+		}
+		return item * 10, true
+	}
+	items := []int{0, 1, 2, 3}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results, completed := processUntilDeadline(ctx, items, 4, fn)
+	if elapsed := time.Since(start); elapsed > 180*time.Millisecond {
+		t.Fatalf("应在预留时间之前返回，实际耗时: %v", elapsed)
+	}
+	if completed != 2 || len(results) != 1 || results[0] != 0 {
+		t.Fatalf("部分结果不一致: completed=%d, results=%v", completed, results)
+	}
+
+	// 没有截止时间时等待全部处理完毕
+	results, completed = processUntilDeadline(context.Background(), []int{0, 1, 3}, 4, fn)
+	if completed != 3 || len(results) != 2 {
+		t.Fatalf("完整结果不一致: completed=%d, results=%v", completed, results)
+	}
+}
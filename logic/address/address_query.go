@@ -0,0 +1,85 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/electrumx"
+	"ginproject/repo/db/address_transactions_dao"
+	"ginproject/repo/db/transaction_participants_dao"
+	"ginproject/repo/db/transactions_dao"
+	rpcex "ginproject/repo/rpc/electrumx"
+)
+
+// 未配置时数据库路径单个查询的超时时间
+const defaultDBQueryTimeout = 10 * time.Second
+
+// historySource 数据库路径依赖的查询，测试中替换以模拟查询卡住
+type historySource struct {
+	history                       func(ctx context.Context, scriptHash string, fromHeight int64, maxCount int) (electrumx.ElectrumXHistoryResponse, error)
+	addressTransactions           func(ctx context.Context, address string, offset, limit int) ([]*dbtable.AddressTransaction, error)
+	addressTransactionsByTxHashes func(ctx context.Context, address string, txHashes []string) ([]*dbtable.AddressTransaction, error)
+	transactions                  func(ctx context.Context, txHashes []string) ([]*dbtable.Transaction, error)
+	participants                  func(ctx context.Context, txHashes []string) ([]*dbtable.TransactionParticipant, error)
+}
+
+// defaultHistorySource 使用ElectrumX和数据库的查询
+var defaultHistorySource = historySource{
+	history:                       rpcex.GetScriptHashHistoryFrom,
+	addressTransactions:           address_transactions_dao.GetAddressTransactions,
+	addressTransactionsByTxHashes: address_transactions_dao.GetAddressTransactionsByTxHashes,
+	transactions:                  transactions_dao.GetTransactionsByTxHashes,
+	participants:                  transaction_participants_dao.GetParticipantsByTxHashes,
+}
+
+// dbQueryTimeout 返回配置的单个查询超时时间
+func dbQueryTimeout() time.Duration {
+	if seconds := config.GetConfig().GetDBConfig().QueryTimeout; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultDBQueryTimeout
+}
+
+// queryOutcome 查询结果
+type queryOutcome[T any] struct {
+	value T
+	err   error
+}
+
+// pendingQuery 在后台执行中的查询
+type pendingQuery[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	name   string
+	done   chan queryOutcome[T]
+}
+
+// startQuery 在带超时的子上下文中后台执行查询
+// 结果通道带缓冲，调用方提前返回后查询协程也能正常退出
+func startQuery[T any](ctx context.Context, timeout time.Duration, name string, query func(context.Context) (T, error)) *pendingQuery[T] {
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	q := &pendingQuery[T]{ctx: queryCtx, cancel: cancel, name: name, done: make(chan queryOutcome[T], 1)}
+	go func() {
+		value, err := query(queryCtx)
+		q.done <- queryOutcome[T]{value: value, err: err}
+	}()
+	return q
+}
+
+// wait 等待查询结束，超时或上游取消时不再等待查询返回
+func (q *pendingQuery[T]) wait() (T, error) {
+	defer q.cancel()
+	select {
+	case outcome := <-q.done:
+		if outcome.err != nil {
+			return outcome.value, fmt.Errorf("%s失败: %w", q.name, outcome.err)
+		}
+		return outcome.value, nil
+	case <-q.ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("%s超时或已取消: %w", q.name, q.ctx.Err())
+	}
+}
@@ -0,0 +1,116 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/electrumx"
+)
+
+// stubSource 返回所有查询都立即成功的数据源
+func stubSource() historySource {
+	return historySource{
+		history: func(context.Context, string, int64, int) (electrumx.ElectrumXHistoryResponse, error) {
+			return electrumx.ElectrumXHistoryResponse{{TxHash: "aa", Height: 10}}, nil
+		},
+		addressTransactions: func(context.Context, string, int, int) ([]*dbtable.AddressTransaction, error) {
+			return []*dbtable.AddressTransaction{{TxHash: "aa"}}, nil
+		},
+		addressTransactionsByTxHashes: func(context.Context, string, []string) ([]*dbtable.AddressTransaction, error) {
+			return []*dbtable.AddressTransaction{{TxHash: "aa"}}, nil
+		},
+		transactions: func(context.Context, []string) ([]*dbtable.Transaction, error) {
+			return []*dbtable.Transaction{{TxHash: "aa", TimeStamp: 1}}, nil
+		},
+		participants: func(context.Context, []string) ([]*dbtable.TransactionParticipant, error) {
+			return nil, nil
+		},
+	}
+}
+
+func TestGetHistoryFromDB(t *testing.T) {
+	response, err := NewAddressLogic().getHistoryFromDB(context.Background(), stubSource(), "addr", "script", true, 0, 0, time.Second)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if response.HistoryCount != 1 || len(response.Result) != 1 || response.Result[0].TxHash != "aa" {
+		t.Fatalf("查询结果不一致: %+v", response)
+	}
+}
+
+func TestGetHistoryFromDBStuckQueries(t *testing.T) {
+	// 模拟不响应上下文取消的查询，测试结束后才放行
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name       string
+		fromHeight int64
+		stub       func(src *historySource)
+	}{
+		{"交易历史卡住", 0, func(src *historySource) {
+			src.history = func(context.Context, string, int64, int) (electrumx.ElectrumXHistoryResponse, error) {
+				<-release
+				return nil, nil
+			}
+		}},
+		{"地址交易记录卡住", 0, func(src *historySource) {
+			src.addressTransactions = func(context.Context, string, int, int) ([]*dbtable.AddressTransaction, error) {
+				<-release
+				return nil, nil
+			}
+		}},
+		{"增量模式地址交易记录卡住", 5, func(src *historySource) {
+			src.addressTransactionsByTxHashes = func(context.Context, string, []string) ([]*dbtable.AddressTransaction, error) {
+				<-release
+				return nil, nil
+			}
+		}},
+		{"交易详情卡住", 0, func(src *historySource) {
+			src.transactions = func(context.Context, []string) ([]*dbtable.Transaction, error) {
+				<-release
+				return nil, nil
+			}
+		}},
+		{"交易参与方卡住", 0, func(src *historySource) {
+			src.participants = func(context.Context, []string) ([]*dbtable.TransactionParticipant, error) {
+				<-release
+				return nil, nil
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := stubSource()
+			tt.stub(&src)
+
+			start := time.Now()
+			_, err := NewAddressLogic().getHistoryFromDB(context.Background(), src, "addr", "script", true, 0, tt.fromHeight, 50*time.Millisecond)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("期望超时错误，实际: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("超时后未及时返回: %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestGetHistoryFromDBCanceled(t *testing.T) {
+	src := stubSource()
+	src.transactions = func(ctx context.Context, _ []string) ([]*dbtable.Transaction, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := NewAddressLogic().getHistoryFromDB(ctx, src, "addr", "script", true, 0, 0, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望请求取消错误，实际: %v", err)
+	}
+}
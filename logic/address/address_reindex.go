@@ -0,0 +1,312 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/electrumx"
+	utility "ginproject/entity/utility"
+	"ginproject/logic/job"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/db"
+	"ginproject/repo/db/address_transactions_dao"
+	"ginproject/repo/db/ft_txo_dao"
+	"ginproject/repo/db/nft_utxo_set_dao"
+	rpcbchain "ginproject/repo/rpc/blockchain"
+	rpcex "ginproject/repo/rpc/electrumx"
+	"ginproject/repo/scripthash"
+)
+
+const (
+	// 重建索引任务的类型名称
+	reindexKind = "scripthash_reindex"
+	// 同时运行的重建索引任务数上限，运维接口只用于修复个别脚本哈希
+	reindexMaxJobs = 2
+	// 任务结束后保留的时间
+	reindexRetention = 24 * time.Hour
+	// 每批核对的交易或输出数
+	reindexChunkSize = 100
+)
+
+var (
+	// ErrReindexUnavailable 未配置数据库，没有可以重建的索引
+	ErrReindexUnavailable = errors.New("未配置数据库，无法重建索引")
+	// ErrReindexUnsupported 脚本哈希的类型没有对应的索引
+	ErrReindexUnsupported = errors.New("该类型的脚本哈希不支持重建索引")
+)
+
+var (
+	reindexOnce sync.Once
+	reindexJobs *job.Manager
+)
+
+// getReindexJobs 返回重建索引任务管理器，集群模式下任务状态写入Redis
+func getReindexJobs() *job.Manager {
+	reindexOnce.Do(func() {
+		reindexJobs = job.NewManager(reindexMaxJobs, reindexRetention)
+		if shared := cache.Shared(); shared != nil {
+			reindexJobs.SetStore(job.NewRedisStore(shared, decodeReindexResult))
+		}
+	})
+	return reindexJobs
+}
+
+// decodeReindexResult 解码共享存储中的重建索引任务结果
+func decodeReindexResult(data []byte) (any, error) {
+	var result electrumx.ScriptHashReindexResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// reindexSteps 返回脚本类型需要执行的重建步骤
+func reindexSteps(kind scripthash.Kind) []string {
+	switch kind {
+	case scripthash.KindP2PKH:
+		return []string{electrumx.ReindexStepHistory, electrumx.ReindexStepFt}
+	case scripthash.KindFtHolder:
+		return []string{electrumx.ReindexStepFt}
+	case scripthash.KindNftHolder:
+		return []string{electrumx.ReindexStepNft}
+	default:
+		return nil
+	}
+}
+
+// StartScriptHashReindex 提交单个脚本哈希的重建索引任务
+// 脚本哈希需由调用方校验格式，且需要有已记录的地址映射，按映射的脚本类型补写交易历史、核对代币输出或NFT持有记录
+func (l *AddressLogic) StartScriptHashReindex(ctx context.Context, scriptHash string) (job.Snapshot, error) {
+	if db.GetDB() == nil {
+		return job.Snapshot{}, ErrReindexUnavailable
+	}
+	scriptHash = strings.ToLower(scriptHash)
+
+	mapping, err := scripthash.AddressOf(ctx, scriptHash)
+	if err != nil {
+		return job.Snapshot{}, err
+	}
+	steps := reindexSteps(mapping.Kind)
+	if len(steps) == 0 {
+		return job.Snapshot{}, fmt.Errorf("%w: %s", ErrReindexUnsupported, mapping.Kind)
+	}
+
+	run := func(ctx context.Context, progress *job.Progress) (any, error) {
+		return l.reindexScriptHash(ctx, progress, scriptHash, mapping, steps)
+	}
+	snapshot, err := getReindexJobs().Submit(ctx, reindexKind, run, nil)
+	if err != nil {
+		return job.Snapshot{}, err
+	}
+	log.InfoWithContext(ctx, "已提交脚本哈希重建索引任务", "scriptHash:", scriptHash, "address:", mapping.Address,
+		"kind:", mapping.Kind, "jobId:", snapshot.Id)
+	return snapshot, nil
+}
+
+// GetScriptHashReindex 查询重建索引任务状态
+func (l *AddressLogic) GetScriptHashReindex(ctx context.Context, jobId string) (job.Snapshot, error) {
+	snapshot, err := getReindexJobs().Lookup(ctx, jobId)
+	if err != nil || snapshot.Kind != reindexKind {
+		return job.Snapshot{}, job.ErrJobNotFound
+	}
+	return snapshot, nil
+}
+
+// reindexScriptHash 依次执行重建步骤，进度按已核对的交易、代币输出和NFT累计
+func (l *AddressLogic) reindexScriptHash(ctx context.Context, progress *job.Progress, scriptHash string,
+	mapping scripthash.Mapping, steps []string) (*electrumx.ScriptHashReindexResult, error) {
+	result := &electrumx.ScriptHashReindexResult{
+		ScriptHash: scriptHash,
+		Address:    mapping.Address,
+		Kind:       string(mapping.Kind),
+		Steps:      steps,
+	}
+	var total int64
+	addTotal := func(n int) {
+		total += int64(n)
+		progress.SetTotal(total)
+	}
+
+	for _, step := range steps {
+		var err error
+		switch step {
+		case electrumx.ReindexStepHistory:
+			err = l.reindexHistory(ctx, progress, addTotal, scriptHash, mapping.Address, result)
+		case electrumx.ReindexStepFt:
+			err = reindexFtTxos(ctx, progress, addTotal, mapping, result)
+		case electrumx.ReindexStepNft:
+			err = reindexNfts(ctx, progress, addTotal, scriptHash, result)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("重建步骤%s失败: %w", step, err)
+		}
+	}
+
+	log.InfoWithContext(ctx, "脚本哈希重建索引完成", "scriptHash:", scriptHash, "backfilled:", result.Backfilled,
+		"ftSpent:", result.FtSpent, "nftStale:", len(result.NftStale))
+	return result, nil
+}
+
+// reindexHistory 重新获取完整的交易历史，解析数据库中缺失的交易并补写地址交易记录
+func (l *AddressLogic) reindexHistory(ctx context.Context, progress *job.Progress, addTotal func(int), scriptHash, address string,
+	result *electrumx.ScriptHashReindexResult) error {
+	history, err := rpcex.GetScriptHashHistoryFrom(ctx, scriptHash, 0, 0)
+	if err != nil {
+		return fmt.Errorf("获取交易历史失败: %w", err)
+	}
+	result.HistoryTxs = int64(len(history))
+	addTotal(len(history))
+
+	for start := 0; start < len(history); start += reindexChunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		chunk := history[start:min(start+reindexChunkSize, len(history))]
+
+		hashes := make([]string, len(chunk))
+		for i, item := range chunk {
+			hashes[i] = item.TxHash
+		}
+		existing, err := address_transactions_dao.GetAddressTransactionsByTxHashes(ctx, address, hashes)
+		if err != nil {
+			return fmt.Errorf("查询地址交易记录失败: %w", err)
+		}
+		indexed := make(map[string]bool, len(existing))
+		for _, record := range existing {
+			indexed[record.TxHash] = true
+		}
+		var missing electrumx.ElectrumXHistoryResponse
+		for _, item := range chunk {
+			if !indexed[item.TxHash] {
+				missing = append(missing, item)
+			}
+		}
+
+		items, completed := l.processHistoryItems(ctx, address, missing)
+		if completed < len(missing) {
+			return fmt.Errorf("重建任务在截止时间前未完成: %w", context.DeadlineExceeded)
+		}
+		records := make([]*dbtable.AddressTransaction, 0, len(items))
+		for _, item := range items {
+			records = append(records, historyItemRecord(address, item))
+		}
+		if err := address_transactions_dao.UpsertAddressTransactions(ctx, records); err != nil {
+			return fmt.Errorf("写入地址交易记录失败: %w", err)
+		}
+
+		result.Backfilled += int64(len(records))
+		result.Skipped += int64(len(missing) - len(items))
+		progress.Add(int64(len(chunk)))
+	}
+	return nil
+}
+
+// historyItemRecord 将解析后的历史交易转换为地址交易记录
+func historyItemRecord(address string, item electrumx.HistoryItem) *dbtable.AddressTransaction {
+	balanceChange, _ := strconv.ParseFloat(item.BalanceChange, 64)
+	return &dbtable.AddressTransaction{
+		Address:       address,
+		TxHash:        item.TxHash,
+		IsSender:      slices.Contains(item.SenderAddresses, address),
+		IsRecipient:   slices.Contains(item.RecipientAddresses, address),
+		BalanceChange: balanceChange,
+	}
+}
+
+// reindexFtTxos 核对数据库中未花费的代币输出，链上已花费的按当前链顶高度标记为已花费
+// 链上存在而数据库缺失的输出需要解析代币脚本才能写入，不在该任务中处理
+func reindexFtTxos(ctx context.Context, progress *job.Progress, addTotal func(int), mapping scripthash.Mapping,
+	result *electrumx.ScriptHashReindexResult) error {
+	pubKeyHash, err := utility.ConvertAddressToPublicKeyHash(mapping.Address)
+	if err != nil {
+		return fmt.Errorf("获取组合脚本失败: %w", err)
+	}
+	combineScript := pubKeyHash + "00"
+
+	dao := ft_txo_dao.NewFtTxoDAO()
+	var txos []*dbtable.FtTxoSet
+	if mapping.Kind == scripthash.KindFtHolder {
+		txos, err = dao.GetUnspentFtTxosByHolderAndContract(ctx, combineScript, mapping.Qualifier, chaintip.NoHeightLimit)
+	} else {
+		txos, err = dao.GetUnspentFtTxosByHolder(ctx, combineScript)
+	}
+	if err != nil {
+		return fmt.Errorf("查询未花费代币输出失败: %w", err)
+	}
+	addTotal(len(txos))
+	if len(txos) == 0 {
+		return nil
+	}
+
+	tip, err := chaintip.Current(ctx)
+	if err != nil {
+		return fmt.Errorf("获取链顶失败: %w", err)
+	}
+	for start := 0; start < len(txos); start += reindexChunkSize {
+		chunk := txos[start:min(start+reindexChunkSize, len(txos))]
+		outpoints := make([]rpcbchain.Outpoint, len(chunk))
+		for i, txo := range chunk {
+			outpoints[i] = rpcbchain.Outpoint{Txid: txo.UtxoTxid, Vout: txo.UtxoVout}
+		}
+		unspent, err := rpcbchain.FetchOutpointsUnspent(ctx, outpoints)
+		if err != nil {
+			return err
+		}
+
+		var spent []dbtable.FtTxoKey
+		for i, txo := range chunk {
+			if !unspent[i] {
+				spent = append(spent, dbtable.FtTxoKey{UtxoTxid: txo.UtxoTxid, UtxoVout: txo.UtxoVout})
+			}
+		}
+		if err := dao.MarkFtTxosSpent(ctx, spent, tip.Height); err != nil {
+			return fmt.Errorf("标记已花费代币输出失败: %w", err)
+		}
+
+		result.FtChecked += int64(len(chunk))
+		result.FtSpent += int64(len(spent))
+		progress.Add(int64(len(chunk)))
+	}
+	return nil
+}
+
+// reindexNfts 核对数据库中该脚本哈希持有的NFT，所在UTXO已不在链上未花费输出中的列为过期记录
+// 新的持有者需要解析转移交易才能确定，不在该任务中修改
+func reindexNfts(ctx context.Context, progress *job.Progress, addTotal func(int), scriptHash string,
+	result *electrumx.ScriptHashReindexResult) error {
+	nfts, err := nft_utxo_set_dao.NewNftUtxoSetDAO().GetNftUtxosByHolder(ctx, scriptHash)
+	if err != nil {
+		return fmt.Errorf("查询持有的NFT失败: %w", err)
+	}
+	addTotal(len(nfts))
+	if len(nfts) == 0 {
+		return nil
+	}
+
+	utxos, err := rpcex.GetScriptHashUnspent(ctx, scriptHash)
+	if err != nil {
+		return fmt.Errorf("获取未花费输出失败: %w", err)
+	}
+	live := make(map[string]bool, len(utxos))
+	for _, utxo := range utxos {
+		live[utxo.TxHash] = true
+	}
+	for _, nft := range nfts {
+		if !live[nft.NftUtxoId] {
+			result.NftStale = append(result.NftStale, nft.NftContractId)
+		}
+	}
+	result.NftChecked = int64(len(nfts))
+	progress.Add(int64(len(nfts)))
+	return nil
+}
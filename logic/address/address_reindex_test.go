@@ -0,0 +1,36 @@
+package address
+
+import (
+	"testing"
+
+	"ginproject/entity/electrumx"
+	"ginproject/repo/scripthash"
+)
+
+func TestReindexSteps(t *testing.T) {
+	cases := map[scripthash.Kind]int{
+		scripthash.KindP2PKH:         2,
+		scripthash.KindFtHolder:      1,
+		scripthash.KindNftHolder:     1,
+		scripthash.KindNftCollection: 0,
+		scripthash.KindMultiSig:      0,
+	}
+	for kind, want := range cases {
+		if got := reindexSteps(kind); len(got) != want {
+			t.Errorf("reindexSteps(%s) = %v, 期望%d个步骤", kind, got, want)
+		}
+	}
+}
+
+func TestHistoryItemRecord(t *testing.T) {
+	item := electrumx.HistoryItem{
+		TxHash:             "aa",
+		BalanceChange:      "-1.5",
+		SenderAddresses:    []string{"addr"},
+		RecipientAddresses: []string{"other"},
+	}
+	record := historyItemRecord("addr", item)
+	if record.TxHash != "aa" || record.BalanceChange != -1.5 || !record.IsSender || record.IsRecipient {
+		t.Fatalf("historyItemRecord = %+v", record)
+	}
+}
@@ -0,0 +1,156 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/electrumx"
+	"ginproject/middleware/log"
+)
+
+// 支持影子流量的路由名，对应配置中 shadow.routes 的键
+const (
+	ShadowRouteAddressHistory     = "address_history"      // 最新历史记录
+	ShadowRouteAddressHistoryPage = "address_history_page" // 分页历史记录
+)
+
+const (
+	defaultShadowTimeout = 30 * time.Second
+	// 单次比对最多记录的差异条数
+	maxShadowDiffs = 10
+)
+
+// ShadowStats 影子请求统计
+type ShadowStats struct {
+	Compared   uint64 `json:"compared"`   // 完成比对的次数
+	Mismatched uint64 `json:"mismatched"` // 结果不一致的次数
+	Failed     uint64 `json:"failed"`     // 影子请求失败的次数
+}
+
+type shadowCounter struct {
+	compared   atomic.Uint64
+	mismatched atomic.Uint64
+	failed     atomic.Uint64
+}
+
+// 按路由记录的影子请求统计
+var shadowCounters sync.Map
+
+func getShadowCounter(route string) *shadowCounter {
+	counter, _ := shadowCounters.LoadOrStore(route, &shadowCounter{})
+	return counter.(*shadowCounter)
+}
+
+// GetShadowStats 获取各路由的影子请求统计
+func GetShadowStats() map[string]ShadowStats {
+	stats := make(map[string]ShadowStats)
+	shadowCounters.Range(func(key, value any) bool {
+		counter := value.(*shadowCounter)
+		stats[key.(string)] = ShadowStats{
+			Compared:   counter.compared.Load(),
+			Mismatched: counter.mismatched.Load(),
+			Failed:     counter.failed.Load(),
+		}
+		return true
+	})
+	return stats
+}
+
+// shouldShadow 按配置的采样比例判断本次请求是否需要发起影子请求
+func shouldShadow(route string) bool {
+	percent := config.GetConfig().GetShadowConfig().Routes[route]
+	if percent <= 0 {
+		return false
+	}
+	return percent >= 100 || rand.Intn(100) < percent
+}
+
+// ShadowHistoryFromDB 将ElectrumX路径已处理的历史查询在数据库路径上异步重放，并记录两者的差异
+// 影子请求不影响主请求的响应，主请求结束后仍会在独立的超时时间内执行完毕
+func (l *AddressLogic) ShadowHistoryFromDB(ctx context.Context, primary *electrumx.AddressHistoryResponse,
+	address string, asPage bool, page int, fromHeight int64) {
+	route := ShadowRouteAddressHistory
+	if asPage {
+		route = ShadowRouteAddressHistoryPage
+	}
+	if primary == nil || !shouldShadow(route) {
+		return
+	}
+
+	timeout := defaultShadowTimeout
+	if seconds := config.GetConfig().GetShadowConfig().Timeout; seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	go func() {
+		shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		counter := getShadowCounter(route)
+		shadow, err := l.GetAddressHistoryPageFromDB(shadowCtx, address, asPage, page, fromHeight)
+		if err != nil {
+			counter.failed.Add(1)
+			log.WarnWithContext(shadowCtx, "影子请求失败", "route:", route, "address:", address, "错误:", err)
+			return
+		}
+
+		counter.compared.Add(1)
+		diffs := diffHistoryResponses(primary, shadow)
+		if len(diffs) == 0 {
+			return
+		}
+		counter.mismatched.Add(1)
+		log.WarnWithContext(shadowCtx, "影子请求结果与主路径不一致",
+			"route:", route,
+			"address:", address,
+			"page:", page,
+			"fromHeight:", fromHeight,
+			"差异:", diffs)
+	}()
+}
+
+// diffHistoryResponses 比较两份地址历史响应，返回差异描述
+func diffHistoryResponses(primary, shadow *electrumx.AddressHistoryResponse) []string {
+	var diffs []string
+	add := func(format string, args ...any) {
+		if len(diffs) < maxShadowDiffs {
+			diffs = append(diffs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if primary.HistoryCount != shadow.HistoryCount {
+		add("history_count: %d != %d", primary.HistoryCount, shadow.HistoryCount)
+	}
+
+	shadowItems := make(map[string]electrumx.HistoryItem, len(shadow.Result))
+	for _, item := range shadow.Result {
+		shadowItems[item.TxHash] = item
+	}
+
+	for _, item := range primary.Result {
+		other, ok := shadowItems[item.TxHash]
+		if !ok {
+			add("%s: 数据库路径缺失", item.TxHash)
+			continue
+		}
+		delete(shadowItems, item.TxHash)
+
+		if item.BalanceChange != other.BalanceChange {
+			add("%s balance_change: %s != %s", item.TxHash, item.BalanceChange, other.BalanceChange)
+		}
+		if item.Fee != other.Fee {
+			add("%s fee: %s != %s", item.TxHash, item.Fee, other.Fee)
+		}
+	}
+
+	for txHash := range shadowItems {
+		add("%s: 数据库路径多出", txHash)
+	}
+
+	return diffs
+}
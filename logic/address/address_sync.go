@@ -0,0 +1,164 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"ginproject/entity/blockchain"
+	"ginproject/entity/electrumx"
+	utility "ginproject/entity/utility"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	rpcbchain "ginproject/repo/rpc/blockchain"
+	rpcex "ginproject/repo/rpc/electrumx"
+)
+
+// 单次增量同步最多处理的新交易数，超过时要求客户端重新全量同步
+const maxSyncTxs = 500
+
+// SyncAddress 返回地址自sinceHeight之后的变化：新交易、被花费的输出、新UTXO和最新余额
+// 链顶在查询前获取，之后出块产生的变化会在下次同步时再次返回
+func (l *AddressLogic) SyncAddress(ctx context.Context, address string, sinceHeight int64) (*electrumx.AddressSyncResponse, error) {
+	log.InfoWithContext(ctx, "开始地址增量同步", "address:", address, "sinceHeight:", sinceHeight)
+
+	scriptHash, err := l.validateAddressAndGetScriptHash(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	tip, err := chaintip.Current(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链顶失败: %w", err)
+	}
+
+	history, err := rpcex.GetScriptHashHistoryFrom(ctx, scriptHash, sinceHeight+1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易历史失败: %w", err)
+	}
+	utxos, err := rpcex.GetListUnspent(ctx, scriptHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取UTXO失败: %w", err)
+	}
+	balance, err := rpcex.GetBalance(ctx, scriptHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取地址余额失败: %w", err)
+	}
+
+	response := &electrumx.AddressSyncResponse{
+		Address:        address,
+		SinceHeight:    sinceHeight,
+		TipHeight:      tip.Height,
+		NewTxs:         make([]electrumx.SyncTx, 0, len(history)),
+		SpentOutpoints: make([]electrumx.SpentOutpoint, 0),
+		NewUtxos:       electrumx.FilterNewUtxos(utxos, sinceHeight),
+		Balance: electrumx.AddressBalanceResponse{
+			Balance:     balance.Confirmed + balance.Unconfirmed,
+			Confirmed:   balance.Confirmed,
+			Unconfirmed: balance.Unconfirmed,
+		},
+	}
+	if len(history) > maxSyncTxs {
+		log.WarnWithContext(ctx, "增量同步的新交易过多，要求全量同步", "address:", address, "count:", len(history))
+		response.ResyncRequired = true
+		response.NewUtxos = make(electrumx.UtxoResponse, 0)
+		return response, nil
+	}
+
+	for _, item := range history {
+		response.NewTxs = append(response.NewTxs, electrumx.SyncTx{TxHash: item.TxHash, Height: item.Height})
+	}
+	response.SpentOutpoints, err = l.findSpentOutpoints(ctx, address, history)
+	if err != nil {
+		return nil, err
+	}
+
+	log.InfoWithContext(ctx, "地址增量同步完成",
+		"address:", address,
+		"newTxs:", len(response.NewTxs),
+		"spent:", len(response.SpentOutpoints),
+		"newUtxos:", len(response.NewUtxos))
+	return response, nil
+}
+
+// findSpentOutpoints 找出新交易花费的本地址输出
+// 结果必须完整，任何一笔交易解码失败都返回错误，避免客户端漏掉已花费的UTXO
+func (l *AddressLogic) findSpentOutpoints(ctx context.Context, address string, history electrumx.ElectrumXHistoryResponse) ([]electrumx.SpentOutpoint, error) {
+	txids := make([]string, 0, len(history))
+	for _, item := range history {
+		txids = append(txids, item.TxHash)
+	}
+	decoded, err := decodeTxs(ctx, txids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 收集需要查询的前序交易，已在新交易中的直接复用
+	var prevTxids []string
+	for _, txid := range txids {
+		for _, vin := range decoded[txid].Vin {
+			if _, ok := decoded[vin.Txid]; vin.Txid != "" && !ok {
+				prevTxids = append(prevTxids, vin.Txid)
+				decoded[vin.Txid] = nil
+			}
+		}
+	}
+	prevDecoded, err := decodeTxs(ctx, prevTxids)
+	if err != nil {
+		return nil, err
+	}
+	for txid, tx := range prevDecoded {
+		decoded[txid] = tx
+	}
+
+	spent := make([]electrumx.SpentOutpoint, 0)
+	for _, txid := range txids {
+		for _, vin := range decoded[txid].Vin {
+			prev := decoded[vin.Txid]
+			if prev == nil || vin.Vout >= len(prev.Vout) {
+				continue
+			}
+			output := prev.Vout[vin.Vout]
+			for _, addr := range output.ScriptPubKey.Addresses {
+				if addr == address {
+					spent = append(spent, electrumx.SpentOutpoint{
+						TxHash:  vin.Txid,
+						TxPos:   vin.Vout,
+						Value:   int64(math.Round(output.Value * 1000000)),
+						SpentBy: txid,
+					})
+					break
+				}
+			}
+		}
+	}
+	return spent, nil
+}
+
+// decodeTxs 并发解码交易，任何一笔失败都返回错误
+func decodeTxs(ctx context.Context, txids []string) (map[string]*blockchain.TransactionResponse, error) {
+	processor := func(ctx context.Context, txid string) (*blockchain.TransactionResponse, error) {
+		result := <-rpcbchain.DecodeTx(ctx, txid)
+		if result.Error != nil {
+			return nil, fmt.Errorf("解码交易%s失败: %w", txid, result.Error)
+		}
+		tx, ok := result.Result.(*blockchain.TransactionResponse)
+		if !ok {
+			return nil, fmt.Errorf("交易%s详情类型转换失败", txid)
+		}
+		return tx, nil
+	}
+
+	results, errs := utility.WorkerPoolWithContext(ctx, txids, 10, processor)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	if len(results) < len(txids) {
+		return nil, fmt.Errorf("交易解码未完成: %w", ctx.Err())
+	}
+	decoded := make(map[string]*blockchain.TransactionResponse, len(results))
+	for _, tx := range results {
+		decoded[tx.Txid] = tx
+	}
+	return decoded, nil
+}
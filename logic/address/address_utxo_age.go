@@ -0,0 +1,35 @@
+package address
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/electrumx"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	rpcex "ginproject/repo/rpc/electrumx"
+)
+
+// GetUtxoAgeDistribution 按确认数和金额统计地址UTXO的分布
+func (l *AddressLogic) GetUtxoAgeDistribution(ctx context.Context, address string) (*electrumx.UtxoAgeDistributionResponse, error) {
+	scriptHash, err := l.validateAddressAndGetScriptHash(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// 链顶在查询UTXO前获取，之后确认的UTXO按未确认统计
+	tip, err := chaintip.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链顶失败: %w", err)
+	}
+
+	utxos, err := rpcex.GetListUnspent(ctx, scriptHash)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取UTXO失败", "address:", address, "scriptHash:", scriptHash, "错误:", err)
+		return nil, fmt.Errorf("获取UTXO失败: %w", err)
+	}
+
+	response := electrumx.NewUtxoAgeDistribution(address, tip.Height, utxos)
+	log.InfoWithContext(ctx, "UTXO年龄分布统计完成", "address:", address, "count:", response.TotalCount)
+	return response, nil
+}
@@ -0,0 +1,323 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	alertEntity "ginproject/entity/alert"
+	"ginproject/entity/config"
+	"ginproject/entity/mempool"
+	"ginproject/middleware/healthscore"
+	"ginproject/middleware/log"
+	"ginproject/repo/notify"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// 健康评分之外的内置指标
+const (
+	MetricHealthScore  = "health_score"
+	MetricMempoolSize  = "mempool_size"
+	MetricMempoolBytes = "mempool_bytes"
+)
+
+// 未配置比较方式时使用大于
+const defaultOp = ">"
+
+// sample 指标的一次采样，用于计算变化率规则的变化量
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// ruleState 单条规则在本实例上的评估状态
+type ruleState struct {
+	matches     int
+	firingSince time.Time
+	notifiedAt  time.Time
+	status      alertEntity.RuleStatus
+}
+
+// evaluator 告警规则评估器，规则每次评估时从配置重新读取，支持热更新
+// 指标取自本实例的健康评分，多实例部署时每个实例按各自观察到的指标告警
+type evaluator struct {
+	mu      sync.Mutex
+	states  map[string]*ruleState
+	history map[string][]sample
+	collect func(ctx context.Context, metrics map[string]bool) (map[string]float64, error)
+	send    func(ctx context.Context, channel string, msg notify.Message) error
+}
+
+var defaultEvaluator = newEvaluator(collectMetrics, sendNotification)
+
+// newEvaluator 创建评估器，collect返回规则使用的指标当前值，send通过指定渠道发送通知
+func newEvaluator(collect func(ctx context.Context, metrics map[string]bool) (map[string]float64, error),
+	send func(ctx context.Context, channel string, msg notify.Message) error) *evaluator {
+	return &evaluator{
+		states:  make(map[string]*ruleState),
+		history: make(map[string][]sample),
+		collect: collect,
+		send:    send,
+	}
+}
+
+// Evaluate 评估全部告警规则，状态变化时发送通知，由周期任务调用
+// 部分指标获取失败或通知发送失败时其余规则照常评估，错误合并后返回
+func Evaluate(ctx context.Context) error {
+	return defaultEvaluator.evaluate(ctx, time.Now(), config.GetConfig().GetAlertConfig())
+}
+
+// Statuses 返回各告警规则在本实例上的最近一次评估结果，顺序与配置一致
+func Statuses() []alertEntity.RuleStatus {
+	return defaultEvaluator.statuses(config.GetConfig().GetAlertConfig())
+}
+
+func (e *evaluator) evaluate(ctx context.Context, now time.Time, cfg *config.AlertConfig) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// 变化率规则需要保留的最长采样时长，按指标计算
+	names := make(map[string]bool, len(cfg.Rules))
+	windows := make(map[string]time.Duration)
+	active := make(map[string]bool, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		names[rule.Metric] = true
+		active[rule.Name] = true
+		windows[rule.Metric] = max(windows[rule.Metric], time.Duration(rule.Change)*time.Second)
+	}
+	// 已从配置中删除的规则不再保留状态
+	for name := range e.states {
+		if !active[name] {
+			delete(e.states, name)
+		}
+	}
+	for metric := range e.history {
+		if !names[metric] {
+			delete(e.history, metric)
+		}
+	}
+	if len(cfg.Rules) == 0 {
+		return nil
+	}
+
+	var errs []error
+	values, err := e.collect(ctx, names)
+	if err != nil {
+		log.WarnWithContext(ctx, "获取告警指标失败，相关规则本次不评估", "错误:", err)
+		errs = append(errs, err)
+	}
+	for metric, value := range values {
+		if names[metric] {
+			e.history[metric] = appendSample(e.history[metric], sample{at: now, value: value}, now.Add(-windows[metric]))
+		}
+	}
+
+	for _, rule := range cfg.Rules {
+		if err := e.evaluateRule(ctx, now, cfg, rule, values); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// evaluateRule 评估单条规则并按状态变化发送告警、重复告警或恢复通知
+func (e *evaluator) evaluateRule(ctx context.Context, now time.Time, cfg *config.AlertConfig, rule config.AlertRule,
+	values map[string]float64) error {
+	state, ok := e.states[rule.Name]
+	if !ok {
+		state = &ruleState{}
+		e.states[rule.Name] = state
+	}
+	state.status.Name = rule.Name
+	state.status.Metric = rule.Metric
+	state.status.Condition = condition(rule)
+	state.status.EvaluatedAt = now.Unix()
+
+	value, ok := e.ruleValue(now, rule, values)
+	if !ok {
+		state.status.State = alertEntity.StateNoData
+		return nil
+	}
+	matched, err := compare(rule.Op, value, rule.Threshold)
+	if err != nil {
+		return fmt.Errorf("告警规则%s: %w", rule.Name, err)
+	}
+	state.status.Value = value
+
+	var subject string
+	if matched {
+		state.matches++
+		switch {
+		case state.matches < max(rule.For, 1):
+		case state.firingSince.IsZero():
+			state.firingSince = now
+			subject = "[告警] " + rule.Name
+		case rule.Repeat > 0 && now.Sub(state.notifiedAt) >= time.Duration(rule.Repeat)*time.Second:
+			subject = "[持续告警] " + rule.Name
+		}
+	} else {
+		state.matches = 0
+		if !state.firingSince.IsZero() {
+			state.firingSince = time.Time{}
+			subject = "[恢复] " + rule.Name
+		}
+	}
+
+	state.status.Matches = state.matches
+	state.status.State = alertEntity.StateOk
+	state.status.FiringSince = 0
+	if !state.firingSince.IsZero() {
+		state.status.State = alertEntity.StateFiring
+		state.status.FiringSince = state.firingSince.Unix()
+	} else if state.matches > 0 {
+		state.status.State = alertEntity.StatePending
+	}
+
+	if subject == "" || cfg.Target == "" {
+		return nil
+	}
+	channel := cfg.Channel
+	if channel == "" {
+		channel = notify.ChannelEmail
+	}
+	msg := notify.Message{To: cfg.Target, Subject: subject, Body: messageBody(now, rule, value)}
+	if err := e.send(ctx, channel, msg); err != nil {
+		state.status.Error = err.Error()
+		return fmt.Errorf("发送告警规则%s的通知失败: %w", rule.Name, err)
+	}
+	state.notifiedAt = now
+	state.status.NotifiedAt = now.Unix()
+	state.status.Error = ""
+	log.InfoWithContext(ctx, "已发送告警通知", "规则:", rule.Name, "主题:", subject, "值:", value)
+	return nil
+}
+
+// ruleValue 返回规则参与比较的值，变化率规则为窗口起点到当前的变化量；采样不足时返回false
+func (e *evaluator) ruleValue(now time.Time, rule config.AlertRule, values map[string]float64) (float64, bool) {
+	value, ok := values[rule.Metric]
+	if !ok {
+		return 0, false
+	}
+	if rule.Change <= 0 {
+		return value, true
+	}
+	// 取窗口起点之前最近的一次采样作为基准
+	cutoff := now.Add(-time.Duration(rule.Change) * time.Second)
+	var base *sample
+	for i, s := range e.history[rule.Metric] {
+		if s.at.After(cutoff) {
+			break
+		}
+		base = &e.history[rule.Metric][i]
+	}
+	if base == nil {
+		return 0, false
+	}
+	return value - base.value, true
+}
+
+// appendSample 追加采样并丢弃早于cutoff的采样，保留cutoff之前最近的一次作为变化量的基准
+func appendSample(samples []sample, s sample, cutoff time.Time) []sample {
+	samples = append(samples, s)
+	keep := 0
+	for i := range samples {
+		if samples[i].at.After(cutoff) {
+			break
+		}
+		keep = i
+	}
+	return samples[keep:]
+}
+
+// compare 按比较方式判断值是否满足告警条件
+func compare(op string, value, threshold float64) (bool, error) {
+	switch op {
+	case "", ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	default:
+		return false, fmt.Errorf("不支持的比较方式%q", op)
+	}
+}
+
+// condition 返回规则条件的可读描述
+func condition(rule config.AlertRule) string {
+	op := rule.Op
+	if op == "" {
+		op = defaultOp
+	}
+	if rule.Change > 0 {
+		return fmt.Sprintf("%s在%d秒内的变化 %s %g", rule.Metric, rule.Change, op, rule.Threshold)
+	}
+	return fmt.Sprintf("%s %s %g", rule.Metric, op, rule.Threshold)
+}
+
+// messageBody 生成通知正文
+func messageBody(now time.Time, rule config.AlertRule, value float64) string {
+	host, _ := os.Hostname()
+	var body strings.Builder
+	fmt.Fprintf(&body, "规则: %s\n", rule.Name)
+	fmt.Fprintf(&body, "条件: %s\n", condition(rule))
+	fmt.Fprintf(&body, "当前值: %g\n", value)
+	fmt.Fprintf(&body, "实例: %s\n", host)
+	fmt.Fprintf(&body, "时间: %s\n", now.Format(time.RFC3339))
+	return body.String()
+}
+
+// statuses 按配置顺序返回规则状态，尚未评估的规则返回空状态
+func (e *evaluator) statuses(cfg *config.AlertConfig) []alertEntity.RuleStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := make([]alertEntity.RuleStatus, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		if state, ok := e.states[rule.Name]; ok {
+			result = append(result, state.status)
+			continue
+		}
+		result = append(result, alertEntity.RuleStatus{Name: rule.Name, Metric: rule.Metric, Condition: condition(rule), State: alertEntity.StateNoData})
+	}
+	return result
+}
+
+// collectMetrics 获取健康评分的各评分项，规则使用内存池指标时再查询节点
+func collectMetrics(ctx context.Context, metrics map[string]bool) (map[string]float64, error) {
+	score := healthscore.Current()
+	values := map[string]float64{MetricHealthScore: float64(score.Score)}
+	for _, component := range score.Components {
+		values[component.Name] = component.Value
+	}
+	if !metrics[MetricMempoolSize] && !metrics[MetricMempoolBytes] {
+		return values, nil
+	}
+
+	result := <-blockchain.FetchMemPoolInfo(ctx)
+	if result.Error != nil {
+		return values, fmt.Errorf("获取内存池概况失败: %w", result.Error)
+	}
+	info, ok := result.Result.(*mempool.NodeMempoolInfo)
+	if !ok {
+		return values, fmt.Errorf("内存池概况结果类型错误: %T", result.Result)
+	}
+	values[MetricMempoolSize] = float64(info.Size)
+	values[MetricMempoolBytes] = float64(info.Bytes)
+	return values, nil
+}
+
+// sendNotification 通过已启用的通知渠道发送
+func sendNotification(ctx context.Context, channel string, msg notify.Message) error {
+	notifier, ok := notify.Get(channel)
+	if !ok {
+		return fmt.Errorf("通知渠道%s未启用", channel)
+	}
+	return notifier.Send(ctx, msg)
+}
@@ -0,0 +1,98 @@
+package alert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	alertEntity "ginproject/entity/alert"
+	"ginproject/entity/config"
+	"ginproject/repo/notify"
+)
+
+func TestEvaluateThreshold(t *testing.T) {
+	values := map[string]float64{}
+	var sent []string
+	e := newEvaluator(func(ctx context.Context, metrics map[string]bool) (map[string]float64, error) {
+		return values, nil
+	}, func(ctx context.Context, channel string, msg notify.Message) error {
+		sent = append(sent, msg.Subject)
+		return nil
+	})
+	cfg := &config.AlertConfig{Target: "ops@example.com", Rules: []config.AlertRule{
+		{Name: "lag", Metric: "indexer_lag", Threshold: 3, For: 2, Repeat: 120},
+	}}
+
+	ctx := context.Background()
+	now := time.Now()
+	step := func(lag float64, wantState string, wantSent int) {
+		t.Helper()
+		values["indexer_lag"] = lag
+		if err := e.evaluate(ctx, now, cfg); err != nil {
+			t.Fatalf("评估失败: %v", err)
+		}
+		if status := e.statuses(cfg)[0]; status.State != wantState || len(sent) != wantSent {
+			t.Fatalf("滞后%g: 状态 %s, 已发送 %v, 期望 %s 和 %d条通知", lag, status.State, sent, wantState, wantSent)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	step(1, alertEntity.StateOk, 0)
+	step(5, alertEntity.StatePending, 0)
+	step(5, alertEntity.StateFiring, 1)
+	step(6, alertEntity.StateFiring, 1)
+	// 持续告警达到重复间隔后再次通知
+	step(6, alertEntity.StateFiring, 2)
+	step(0, alertEntity.StateOk, 3)
+	if sent[0] != "[告警] lag" || sent[1] != "[持续告警] lag" || sent[2] != "[恢复] lag" {
+		t.Fatalf("通知主题 = %v", sent)
+	}
+}
+
+func TestEvaluateChange(t *testing.T) {
+	values := map[string]float64{}
+	var sent []string
+	e := newEvaluator(func(ctx context.Context, metrics map[string]bool) (map[string]float64, error) {
+		return values, nil
+	}, func(ctx context.Context, channel string, msg notify.Message) error {
+		sent = append(sent, msg.Subject)
+		return nil
+	})
+	cfg := &config.AlertConfig{Target: "ops@example.com", Rules: []config.AlertRule{
+		{Name: "mempool_growth", Metric: MetricMempoolSize, Threshold: 1000, Change: 120},
+	}}
+
+	ctx := context.Background()
+	now := time.Now()
+	for i, size := range []float64{100, 500, 900, 2000} {
+		values[MetricMempoolSize] = size
+		if err := e.evaluate(ctx, now.Add(time.Duration(i)*time.Minute), cfg); err != nil {
+			t.Fatalf("评估失败: %v", err)
+		}
+		status := e.statuses(cfg)[0]
+		switch i {
+		case 0, 1:
+			// 采样不足两分钟时没有变化量
+			if status.State != alertEntity.StateNoData {
+				t.Fatalf("第%d次评估状态 = %s, 期望nodata", i, status.State)
+			}
+		case 2:
+			if status.State != alertEntity.StateOk || status.Value != 800 {
+				t.Fatalf("第%d次评估 = %+v, 期望变化量800", i, status)
+			}
+		case 3:
+			if status.State != alertEntity.StateFiring || status.Value != 1500 {
+				t.Fatalf("第%d次评估 = %+v, 期望变化量1500并告警", i, status)
+			}
+		}
+	}
+	if len(sent) != 1 {
+		t.Fatalf("已发送 %v, 期望1条通知", sent)
+	}
+
+	// 不支持的比较方式返回错误
+	cfg.Rules[0].Op = "!="
+	if err := e.evaluate(ctx, now.Add(4*time.Minute), cfg); err == nil {
+		t.Fatal("不支持的比较方式应返回错误")
+	}
+}
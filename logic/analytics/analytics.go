@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+
+	"ginproject/entity/analytics"
+	"ginproject/entity/config"
+	"ginproject/entity/event"
+	"ginproject/middleware/log"
+	analyticsrepo "ginproject/repo/analytics"
+	"ginproject/repo/db/transactions_dao"
+	"ginproject/repo/eventbus"
+)
+
+// 未启用分析库时统计接口的数据来源
+const sourceMySQL = "mysql"
+
+// 分析库在事件总线上的消费者名称
+const ingestConsumer = "analytics"
+
+// Activity 查询链上活跃度时间序列，启用分析库时优先查询分析库，失败时退回MySQL
+func Activity(ctx context.Context, query analytics.ActivityQuery) (*analytics.ActivityResponse, error) {
+	response := &analytics.ActivityResponse{From: query.From, To: query.To, Interval: query.Interval}
+
+	if sink := analyticsrepo.Default(); sink != nil {
+		points, err := sink.Activity(ctx, query)
+		if err == nil {
+			response.Source = sink.Driver()
+			response.Points = points
+			return response, nil
+		}
+		log.WarnWithContext(ctx, "分析库查询失败，改为查询MySQL", "driver:", sink.Driver(), "错误:", err)
+	}
+
+	rows, err := transactions_dao.GetTransactionActivity(ctx, query.From, query.To, query.Interval)
+	if err != nil {
+		return nil, err
+	}
+	response.Source = sourceMySQL
+	response.Points = make([]analytics.ActivityPoint, 0, len(rows))
+	for _, row := range rows {
+		response.Points = append(response.Points, analytics.ActivityPoint{Time: row.Bucket, Txs: row.Txs})
+	}
+	return response, nil
+}
+
+// StartIngester 以analytics消费者的身份订阅交易事件并写入分析库，ctx取消时退出
+// 写入失败时事件总线会重新投递同一批事件，未启用分析库时不做任何事
+func StartIngester(ctx context.Context) {
+	sink := analyticsrepo.Default()
+	if sink == nil {
+		return
+	}
+	if !config.GetConfig().GetEventBusConfig().Publish {
+		log.WarnWithContext(ctx, "事件发布未开启，分析库不会写入新交易", "driver:", sink.Driver())
+	}
+
+	handler := func(ctx context.Context, events []eventbus.Event) error {
+		return ingest(ctx, sink, events)
+	}
+	if err := eventbus.Default().Subscribe(ctx, ingestConsumer, event.TopicTx, handler); err != nil {
+		log.WarnWithContext(ctx, "订阅交易事件失败", "错误:", err)
+	}
+}
+
+// ingest 将一批交易事件写入分析库，无法解析的事件记录日志后跳过
+func ingest(ctx context.Context, sink analyticsrepo.Sink, events []eventbus.Event) error {
+	records := make([]analytics.TxRecord, 0, len(events))
+	for _, e := range events {
+		var tx event.TxEvent
+		if err := json.Unmarshal(e.Payload, &tx); err != nil {
+			log.WarnWithContext(ctx, "跳过无法解析的交易事件", "offset:", e.Offset, "错误:", err)
+			continue
+		}
+		records = append(records, analytics.NewTxRecord(tx))
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return sink.WriteTxs(ctx, records)
+}
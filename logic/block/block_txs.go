@@ -0,0 +1,50 @@
+package block
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/block"
+	entityBlockchain "ginproject/entity/blockchain"
+	"ginproject/entity/utility"
+	"ginproject/middleware/schemawatch"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// GetBlockTxsPage 分页获取区块中的交易摘要
+// 先按verbosity=1获取区块的交易ID列表，只批量解码当前页的交易，避免大区块一次返回全部交易
+func GetBlockTxsPage(ctx context.Context, req *block.BlockTxsRequest) (*block.BlockTxsResponse, error) {
+	result := <-blockchain.FetchBlockByHash(ctx, req.Hash)
+	if result.Error != nil {
+		return nil, fmt.Errorf("获取区块数据失败: %w", result.Error)
+	}
+	var detail block.BlockDetail
+	if err := schemawatch.Convert(ctx, schemawatch.SourceNode, blockchain.RpcMethodGetBlock, result.Result, &detail); err != nil {
+		return nil, fmt.Errorf("解析区块数据失败: %w", err)
+	}
+
+	txids := block.PageTxids(detail.Tx, req.Page, req.Size)
+	decoded := <-blockchain.DecodeTxs(ctx, txids)
+	if decoded.Error != nil {
+		return nil, decoded.Error
+	}
+	txs := decoded.Result.(map[string]*entityBlockchain.TransactionResponse)
+
+	summaries := make([]block.TxScanSummary, 0, len(txids))
+	for i, txid := range txids {
+		tx, ok := txs[txid]
+		if !ok {
+			return nil, fmt.Errorf("解码区块交易%s失败", txid)
+		}
+		summaries = append(summaries, block.SummarizeTx(detail.Height, req.Page*req.Size+i, tx))
+	}
+
+	return &block.BlockTxsResponse{
+		Hash:          detail.Hash,
+		Height:        detail.Height,
+		Confirmations: detail.Confirmations,
+		TxCount:       len(detail.Tx),
+		Result:        summaries,
+		Meta:          utility.NewPageMeta(req.Page, req.Size, int64(len(detail.Tx))),
+	}, nil
+}
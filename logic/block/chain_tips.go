@@ -0,0 +1,51 @@
+package block
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ginproject/entity/block"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// 查询累计工作量的链顶数，长期运行的节点会积累大量过期分叉，只查询高度最高的部分
+const maxChainWorkTips = 20
+
+// GetChainTips 获取节点已知的链顶和分叉，高度最高的若干个链顶附带累计工作量
+// 累计工作量查询失败时对应字段留空，不影响整体结果
+func GetChainTips(ctx context.Context) (*block.ChainTipsResponse, error) {
+	result := <-blockchain.FetchChainTips(ctx)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	tips, ok := result.Result.([]block.ChainTip)
+	if !ok {
+		return nil, fmt.Errorf("链顶列表格式错误")
+	}
+
+	response := block.NewChainTipsResponse(tips)
+	var wg sync.WaitGroup
+	for i := range response.Tips[:min(len(response.Tips), maxChainWorkTips)] {
+		wg.Add(1)
+		go func(tip *block.ChainTipInfo) {
+			defer wg.Done()
+			header := <-blockchain.FetchBlockHeaderByHash(ctx, tip.Hash)
+			if header.Error != nil {
+				log.WarnWithContext(ctx, "获取链顶累计工作量失败", "hash:", tip.Hash, "错误:", header.Error)
+				return
+			}
+			fields, _ := header.Result.(map[string]interface{})
+			tip.ChainWork, _ = fields["chainwork"].(string)
+		}(&response.Tips[i])
+	}
+	wg.Wait()
+
+	for _, tip := range response.Tips {
+		if tip.Active {
+			response.ActiveWork = tip.ChainWork
+		}
+	}
+	return response, nil
+}
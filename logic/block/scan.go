@@ -0,0 +1,75 @@
+package block
+
+import (
+	"context"
+
+	"ginproject/entity/block"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// 扫描时最多预取的区块数，消费方写出变慢时预取随之暂停
+const scanPrefetch = 2
+
+// fetchFunc 按高度获取包含完整交易的区块
+type fetchFunc func(ctx context.Context, height int64) (*block.BlockWithTxs, error)
+
+// scanItem 预取结果
+type scanItem struct {
+	height int64
+	block  *block.BlockWithTxs
+	err    error
+}
+
+// ScanRange 按高度顺序获取[from, to]范围内的区块并逐个交给emit处理
+// emit返回错误或ctx取消时停止扫描；返回值中的高度为出错的区块高度
+func ScanRange(ctx context.Context, from, to int64, emit func(*block.BlockWithTxs) error) (int64, error) {
+	return scanRange(ctx, from, to, fetchBlockWithTxs, emit)
+}
+
+// fetchBlockWithTxs 通过节点RPC获取区块
+func fetchBlockWithTxs(ctx context.Context, height int64) (*block.BlockWithTxs, error) {
+	result := <-blockchain.FetchBlockWithTxsByHeight(ctx, height)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Result.(*block.BlockWithTxs), nil
+}
+
+func scanRange(ctx context.Context, from, to int64, fetch fetchFunc, emit func(*block.BlockWithTxs) error) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	items := make(chan scanItem, scanPrefetch)
+	go func() {
+		defer close(items)
+		for height := from; height <= to; height++ {
+			b, err := fetch(ctx, height)
+			select {
+			case items <- scanItem{height: height, block: b, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for height := from; height <= to; height++ {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				return height, ctx.Err()
+			}
+			if item.err != nil {
+				return item.height, item.err
+			}
+			if err := emit(item.block); err != nil {
+				return item.height, err
+			}
+		case <-ctx.Done():
+			return height, ctx.Err()
+		}
+	}
+	return to, nil
+}
@@ -0,0 +1,45 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ginproject/entity/block"
+)
+
+func TestScanRange(t *testing.T) {
+	fetch := func(ctx context.Context, height int64) (*block.BlockWithTxs, error) {
+		if height == 13 {
+			return nil, errors.New("节点错误")
+		}
+		b := &block.BlockWithTxs{}
+		b.Height = height
+		return b, nil
+	}
+
+	var heights []int64
+	emit := func(b *block.BlockWithTxs) error {
+		heights = append(heights, b.Height)
+		return nil
+	}
+	if _, err := scanRange(context.Background(), 10, 12, fetch, emit); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if len(heights) != 3 || heights[0] != 10 || heights[2] != 12 {
+		t.Fatalf("扫描顺序不一致: %v", heights)
+	}
+
+	heights = nil
+	height, err := scanRange(context.Background(), 11, 15, fetch, emit)
+	if err == nil || height != 13 || len(heights) != 2 {
+		t.Fatalf("期望在高度13处失败，实际: %d, %v, %v", height, err, heights)
+	}
+
+	// emit出错时停止扫描
+	stop := errors.New("写出失败")
+	height, err = scanRange(context.Background(), 0, 5, fetch, func(*block.BlockWithTxs) error { return stop })
+	if !errors.Is(err, stop) || height != 0 {
+		t.Fatalf("期望在首个区块停止，实际: %d, %v", height, err)
+	}
+}
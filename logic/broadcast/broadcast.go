@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"ginproject/entity/broadcast"
+	"ginproject/middleware/consistency"
 	"ginproject/middleware/log"
 	"ginproject/repo/rpc/blockchain"
 )
@@ -29,6 +31,7 @@ func BroadcastTxRaw(ctx context.Context, req *broadcast.TxBroadcastRequest) (*br
 	// 处理错误
 	if result.Error != nil {
 		log.ErrorWithContext(ctx, "交易广播服务错误", "error", result.Error)
+		journalFailures(ctx, []string{req.TxHex}, result.Error.Error())
 		return nil, http.StatusInternalServerError, result.Error
 	}
 
@@ -51,6 +54,10 @@ func BroadcastTxRaw(ctx context.Context, req *broadcast.TxBroadcastRequest) (*br
 		}
 	}
 
+	if resp.Error == nil && resp.Result != "" {
+		resp.ConsistencyToken = consistency.Issue(resp.Result)
+	}
+
 	// 返回结果
 	log.InfoWithContext(ctx, "交易广播请求处理完成",
 		"success", true,
@@ -59,116 +66,94 @@ func BroadcastTxRaw(ctx context.Context, req *broadcast.TxBroadcastRequest) (*br
 	return resp, http.StatusOK, nil
 }
 
-// BroadcastTxsRaw 批量广播原始交易的业务逻辑
-// 处理请求参数的验证、调用底层RPC接口以及处理响应
-func BroadcastTxsRaw(ctx context.Context, req broadcast.TxsBroadcastRequest) (*broadcast.TxsBroadcastResponse, int, error) {
-	// 验证请求参数
+// DryRunTxRaw 预检单个原始交易而不广播，返回结构化的拒绝原因
+// 节点拒绝交易时仍返回200，拒绝原因在DryRun中说明
+func DryRunTxRaw(ctx context.Context, req *broadcast.TxBroadcastRequest) (*broadcast.BroadcastResponse, int, error) {
 	if err := req.Validate(); err != nil {
-		log.ErrorWithContext(ctx, "批量交易参数无效", "error", err)
+		log.ErrorWithContext(ctx, "交易参数无效", "error", err)
 		return nil, http.StatusBadRequest, err
 	}
 
-	// 记录API调用
-	log.InfoWithContext(ctx, "开始批量广播原始交易", "count", len(req))
-
-	// 准备发送的交易列表
-	txList := make([]map[string]interface{}, 0, len(req))
-	for _, txReq := range req {
-		txList = append(txList, map[string]interface{}{
-			"hex":           txReq.TxHex,
-			"allowhighfees": false,
-			"dontcheckfee":  false,
-		})
+	txid, err := broadcast.TxIdFromHex(req.TxHex)
+	if err != nil {
+		// 奇数长度等本地即可判断的语法错误不再请求节点
+		return &broadcast.BroadcastResponse{DryRun: &broadcast.DryRunResult{
+			Rejections: []broadcast.TxRejection{{Reason: broadcast.RejectSyntax, Message: err.Error()}},
+		}}, http.StatusOK, nil
 	}
 
-	// 调用RPC批量广播交易
-	resultChan := blockchain.SendRawTransactions(ctx, txList)
-	result := <-resultChan
+	log.InfoWithContext(ctx, "开始预检原始交易", "txid", txid, "txHexLength", len(req.TxHex))
+	check, err := blockchain.PrecheckRawTransaction(ctx, strings.TrimSpace(req.TxHex), txid)
+	if err != nil {
+		log.ErrorWithContext(ctx, "交易预检失败", "txid", txid, "error", err)
+		return nil, http.StatusInternalServerError, err
+	}
 
-	// 处理错误
-	if result.Error != nil {
-		log.ErrorWithContext(ctx, "批量交易广播服务错误", "error", result.Error)
-		return nil, http.StatusInternalServerError, result.Error
+	result := broadcast.NewDryRunResult(txid, check)
+	log.InfoWithContext(ctx, "交易预检完成", "txid", txid, "allowed", result.Allowed, "rejections", len(result.Rejections))
+	return &broadcast.BroadcastResponse{DryRun: result}, http.StatusOK, nil
+}
+
+// BroadcastTxsRaw 批量广播原始交易的业务逻辑
+// 按批内依赖关系分层广播，父交易未被接受时跳过其子交易，返回每笔交易的结果而不因个别交易失败整体失败
+func BroadcastTxsRaw(ctx context.Context, req broadcast.TxsBroadcastRequest) (*broadcast.TxsBroadcastResponse, int, error) {
+	// 验证请求参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContext(ctx, "批量交易参数无效", "error", err)
+		return nil, http.StatusBadRequest, err
 	}
 
-	// 转换结果
-	resp, ok := result.Result.(*broadcast.TxsBroadcastResponse)
-	if !ok {
-		// 尝试处理不同类型的结果
-		if mapResult, ok := result.Result.(map[string]interface{}); ok {
-			// 创建响应对象
-			resp = &broadcast.TxsBroadcastResponse{
-				Result: &broadcast.TxsBroadcastResult{
-					Invalid: []broadcast.InvalidTx{},
-				},
-			}
+	batch := broadcast.NewBatchBroadcast(req)
+	log.InfoWithContext(ctx, "开始批量广播原始交易", "count", len(req), "levels", len(batch.Levels()))
 
-			// 处理可能的错误信息
-			if errMap, ok := mapResult["error"].(map[string]interface{}); ok {
-				code, _ := errMap["code"].(float64)
-				message, _ := errMap["message"].(string)
-				resp.Error = &broadcast.BroadcastError{
-					Code:    int(code),
-					Message: message,
-				}
-			}
+	var requestErr error
+	for _, level := range batch.Levels() {
+		ready := batch.Ready(level)
+		if len(ready) == 0 {
+			continue
+		}
+		txHexes := make([]string, len(ready))
+		for k, i := range ready {
+			txHexes[k] = batch.Hex(i)
+		}
 
-			// 处理结果数据
-			if resultMap, ok := mapResult["result"].(map[string]interface{}); ok {
-				if invalidTxs, ok := resultMap["invalid"].([]interface{}); ok {
-					invalid := make([]broadcast.InvalidTx, 0, len(invalidTxs))
-					for _, inv := range invalidTxs {
-						if invMap, ok := inv.(map[string]interface{}); ok {
-							invalidTx := broadcast.InvalidTx{}
-							if txid, ok := invMap["txid"].(string); ok {
-								invalidTx.TxID = txid
-							}
-							if rejectCode, ok := invMap["reject_code"].(float64); ok {
-								invalidTx.RejectCode = int(rejectCode)
-							}
-							if rejectReason, ok := invMap["reject_reason"].(string); ok {
-								invalidTx.RejectReason = rejectReason
-							}
-							invalid = append(invalid, invalidTx)
-						}
-					}
-					resp.Result.Invalid = invalid
-				}
-			}
-		} else if _, ok := result.Result.(string); ok {
-			// 如果是字符串，创建响应对象
-			resp = &broadcast.TxsBroadcastResponse{
-				Result: &broadcast.TxsBroadcastResult{
-					Invalid: []broadcast.InvalidTx{},
-				},
-			}
-		} else {
-			err := ctx.Err()
-			if err == nil {
-				err = fmt.Errorf("结果类型转换失败: 期望 map、string 或 *broadcast.TxsBroadcastResponse 类型")
+		responses, err := blockchain.SendRawTransactionBatch(ctx, txHexes)
+		if err != nil {
+			log.ErrorWithContext(ctx, "批量交易广播服务错误", "count", len(ready), "error", err)
+			requestErr = err
+			for _, i := range ready {
+				batch.Fail(i, err)
 			}
-			log.ErrorWithContext(ctx, "结果类型转换失败", "type", fmt.Sprintf("%T", result.Result))
-			return nil, http.StatusInternalServerError, err
+			continue
+		}
+		for k, i := range ready {
+			batch.Record(i, responses[k])
 		}
 	}
 
-	// 如果有错误，返回对应的错误状态码
-	statusCode := http.StatusOK
-	if resp.Error != nil {
-		log.WarnWithContext(ctx, "批量交易广播返回错误",
-			"code", resp.Error.Code,
-			"message", resp.Error.Message)
-		statusCode = http.StatusBadRequest
-	} else {
-		invalidCount := 0
-		if resp.Result != nil {
-			invalidCount = len(resp.Result.Invalid)
+	result := batch.Result()
+	journalBatchFailures(ctx, req, result.Txs)
+	if requestErr != nil && allFailed(result.Txs) {
+		return nil, http.StatusInternalServerError, requestErr
+	}
+
+	resp := &broadcast.TxsBroadcastResponse{Result: result}
+	if len(result.TxIDs) > 0 {
+		resp.ConsistencyToken = consistency.Issue(result.TxIDs...)
+	}
+	log.InfoWithContext(ctx, "批量交易广播完成",
+		"totalCount", len(req),
+		"acceptedCount", len(result.TxIDs),
+		"invalidCount", len(result.Invalid))
+	return resp, http.StatusOK, nil
+}
+
+// allFailed 所有交易都因广播请求失败而状态未知
+func allFailed(statuses []broadcast.TxBroadcastStatus) bool {
+	for _, status := range statuses {
+		if status.Status != broadcast.TxStatusError {
+			return false
 		}
-		log.InfoWithContext(ctx, "批量交易广播完成",
-			"totalCount", len(req),
-			"invalidCount", invalidCount)
 	}
-	// fmt.Println(resp.Result.TxID)
-	// 返回结果
-	return resp, statusCode, nil
+	return true
 }
@@ -0,0 +1,146 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"ginproject/entity/broadcast"
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/broadcast_failure_dao"
+	"ginproject/repo/rpc/blockchain"
+)
+
+const (
+	// 记录广播失败的超时时间，请求本身超时后仍需完成记录
+	journalTimeout = 5 * time.Second
+	// 未配置时已重放记录的保留天数
+	defaultFailureRetentionDays = 30
+	// 清理时每批删除的记录数，避免长时间锁表
+	pruneBatchSize = 1000
+)
+
+// journalFailures 将广播失败的原始交易写入失败记录表，记录失败只打印日志，不影响广播响应
+func journalFailures(ctx context.Context, txHexes []string, errMsg string) {
+	if db.GetDB() == nil || len(txHexes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), journalTimeout)
+	defer cancel()
+
+	for _, txHex := range txHexes {
+		txid, err := broadcast.TxIdFromHex(txHex)
+		if err != nil {
+			log.WarnWithContext(ctx, "计算广播失败交易ID失败", "error", err)
+			continue
+		}
+		failure := &dbtable.BroadcastFailure{
+			TxId:  txid,
+			TxHex: txHex,
+			Error: errMsg,
+		}
+		if err := broadcast_failure_dao.RecordBroadcastFailure(ctx, failure); err != nil {
+			continue
+		}
+		log.InfoWithContext(ctx, "已记录广播失败交易", "txid", txid)
+	}
+}
+
+// journalBatchFailures 记录批量广播中被节点拒绝或请求失败的交易，跳过的交易未广播，不记录
+func journalBatchFailures(ctx context.Context, req broadcast.TxsBroadcastRequest, statuses []broadcast.TxBroadcastStatus) {
+	for _, status := range statuses {
+		if status.Status == broadcast.TxStatusRejected || status.Status == broadcast.TxStatusError {
+			journalFailures(ctx, []string{req[status.Index].TxHex}, status.RejectReason)
+		}
+	}
+}
+
+// ListBroadcastFailures 分页获取广播失败记录
+func ListBroadcastFailures(ctx context.Context, req *broadcast.BroadcastFailuresRequest) (*broadcast.BroadcastFailuresResponse, error) {
+	failures, total, err := broadcast_failure_dao.ListBroadcastFailures(ctx, req.Status, req.Page*req.Size, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]broadcast.BroadcastFailure, 0, len(failures))
+	for _, f := range failures {
+		result = append(result, broadcast.BroadcastFailure{
+			Id:        f.Id,
+			TxId:      f.TxId,
+			TxHex:     f.TxHex,
+			Error:     f.Error,
+			Attempts:  f.Attempts,
+			Status:    f.Status,
+			CreatedAt: f.CreatedAt.Unix(),
+			UpdatedAt: f.UpdatedAt.Unix(),
+		})
+	}
+	return &broadcast.BroadcastFailuresResponse{
+		Result: result,
+		Meta:   utility.NewPageMeta(req.Page, req.Size, total),
+	}, nil
+}
+
+// ReplayBroadcastFailure 重新向节点广播失败记录中的交易
+// 节点再次拒绝时累加失败次数并在响应中返回错误，只有读写数据库失败才返回error
+func ReplayBroadcastFailure(ctx context.Context, id int64) (*broadcast.ReplayFailureResponse, error) {
+	failure, err := broadcast_failure_dao.GetBroadcastFailure(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &broadcast.ReplayFailureResponse{
+		Id:     failure.Id,
+		TxId:   failure.TxId,
+		Status: failure.Status,
+	}
+	if failure.Status == broadcast.FailureStatusReplayed {
+		return resp, nil
+	}
+
+	log.InfoWithContext(ctx, "重放广播失败交易", "id", id, "txid", failure.TxId, "attempts", failure.Attempts)
+	result := <-blockchain.SendRawTransaction(ctx, failure.TxHex, false, false)
+	if result.Error != nil {
+		log.WarnWithContext(ctx, "重放广播失败交易未成功", "id", id, "error", result.Error)
+		if err := broadcast_failure_dao.RecordReplayFailure(ctx, id, result.Error.Error()); err != nil {
+			return nil, err
+		}
+		resp.Error = result.Error.Error()
+		return resp, nil
+	}
+
+	if err := broadcast_failure_dao.MarkBroadcastFailureReplayed(ctx, id); err != nil {
+		return nil, err
+	}
+	resp.Status = broadcast.FailureStatusReplayed
+	return resp, nil
+}
+
+// PruneBroadcastFailures 删除超过保留天数的已重放记录，待处理的记录不会被删除，由周期任务调用
+func PruneBroadcastFailures(ctx context.Context) error {
+	days := config.GetConfig().GetSchedulerConfig().FailureRetention
+	if days <= 0 {
+		days = defaultFailureRetentionDays
+	}
+	before := time.Now().AddDate(0, 0, -days)
+
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := broadcast_failure_dao.DeleteReplayedBroadcastFailures(ctx, before, pruneBatchSize)
+		if err != nil {
+			return err
+		}
+		total += deleted
+		if deleted < pruneBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.InfoWithContext(ctx, "清理已重放的广播失败记录", "条数:", total, "保留天数:", days)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,98 @@
+package capability
+
+import (
+	"context"
+	"path"
+	"sync/atomic"
+
+	"ginproject/entity/capability"
+	"ginproject/entity/config"
+	"ginproject/logic/subscription"
+	"ginproject/middleware/auth"
+	"ginproject/middleware/log"
+	"ginproject/repo/analytics"
+	"ginproject/repo/db"
+	"ginproject/repo/rpc/blockchain"
+	"ginproject/repo/rpc/electrumx"
+)
+
+// 各功能当前的接口版本
+const (
+	dbHistoryVersion = "1"
+	analyticsVersion = "1"
+	faucetVersion    = "1"
+	websocketVersion = "1"
+)
+
+// nodeChain 缓存节点报告的链名称，节点所在网络在运行期间不会变化，成功获取一次后不再请求
+var nodeChain atomic.Value
+
+// GetCapabilities 汇总当前部署启用的可选功能，prefix为接口路径前缀
+func GetCapabilities(ctx context.Context, prefix string) *capability.CapabilitiesResponse {
+	chain := getNodeChain(ctx)
+	server := electrumx.GetServerInfo()
+
+	features := map[string]capability.Feature{
+		// Webhook推送尚未在此部署中提供
+		capability.FeatureWebhooks: {},
+	}
+	if subscription.Default() != nil {
+		features[capability.FeatureWebSocket] = capability.Feature{Enabled: true, Version: websocketVersion, Backend: "electrumx"}
+	} else {
+		features[capability.FeatureWebSocket] = capability.Feature{}
+	}
+	if db.GetDB() != nil {
+		features[capability.FeatureDBHistory] = capability.Feature{Enabled: true, Version: dbHistoryVersion, Backend: "mysql"}
+	} else {
+		features[capability.FeatureDBHistory] = capability.Feature{}
+	}
+	if sink := analytics.Default(); sink != nil {
+		features[capability.FeatureAnalytics] = capability.Feature{Enabled: true, Version: analyticsVersion, Backend: sink.Driver()}
+	} else {
+		features[capability.FeatureAnalytics] = capability.Feature{}
+	}
+	features[capability.FeatureFaucet] = faucetFeature(chain)
+
+	return &capability.CapabilitiesResponse{
+		APIVersion: capability.APIVersion,
+		ReadOnly:   auth.IsReadOnly(),
+		Features:   features,
+		Networks: []capability.Network{{
+			Name:   path.Base(prefix),
+			Prefix: prefix,
+			Chain:  chain,
+		}},
+		Upstream: capability.Upstream{
+			ElectrumXServer:   server.Software,
+			ElectrumXProtocol: server.Protocol,
+		},
+	}
+}
+
+// faucetFeature 水龙头需要在配置中启用且节点不在主网上，节点网络未知时按配置判断
+func faucetFeature(chain string) capability.Feature {
+	cfg := config.GetConfig().GetFaucetConfig()
+	if !cfg.Enabled || cfg.Amount <= 0 || chain == "main" || auth.IsReadOnly() {
+		return capability.Feature{}
+	}
+	return capability.Feature{Enabled: true, Version: faucetVersion}
+}
+
+// getNodeChain 获取节点报告的链名称，失败时返回空字符串，下次请求时重试
+func getNodeChain(ctx context.Context) string {
+	if chain, ok := nodeChain.Load().(string); ok {
+		return chain
+	}
+
+	result := <-blockchain.FetchChainInfo(ctx)
+	if result.Error != nil {
+		log.WarnWithContext(ctx, "获取节点网络信息失败", "error", result.Error)
+		return ""
+	}
+	chainInfo, _ := result.Result.(map[string]interface{})
+	chain, _ := chainInfo["chain"].(string)
+	if chain != "" {
+		nodeChain.Store(chain)
+	}
+	return chain
+}
@@ -0,0 +1,353 @@
+package consistency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"ginproject/entity/blockchain"
+	"ginproject/entity/consistency"
+	"ginproject/entity/electrumx"
+	"ginproject/entity/utility"
+	"ginproject/logic/job"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/db"
+	"ginproject/repo/db/address_transactions_dao"
+	"ginproject/repo/db/ft_tokens_dao"
+	"ginproject/repo/db/ft_txo_dao"
+	rpcbchain "ginproject/repo/rpc/blockchain"
+	rpcex "ginproject/repo/rpc/electrumx"
+	"ginproject/repo/scripthash"
+)
+
+const (
+	// 一致性检查任务的类型名称
+	checkKind = "consistency_check"
+	// 同一时间只运行一个检查，避免抽样请求叠加到上游
+	checkMaxJobs = 1
+	// 任务结束后保留的时间
+	checkRetention = 24 * time.Hour
+	// 历史交易超过该数量的地址不比较，避免单个地址拖慢整个检查
+	maxHistoryTxs = 10000
+	// 未花费输出超过该数量的代币持有不比较，余额比较需要逐笔解码交易
+	maxFtUtxos = 200
+	// 每批按交易哈希查询地址交易记录的数量
+	historyChunkSize = 500
+)
+
+var (
+	// ErrCheckUnavailable 未配置数据库，没有可以比较的数据
+	ErrCheckUnavailable = errors.New("未配置数据库，无法执行一致性检查")
+	// errTooLarge 抽样对象的数据量超过上限，跳过比较
+	errTooLarge = errors.New("数据量超过上限")
+)
+
+var (
+	checkOnce sync.Once
+	checkJobs *job.Manager
+)
+
+// getCheckJobs 返回一致性检查任务管理器，集群模式下任务状态写入Redis
+func getCheckJobs() *job.Manager {
+	checkOnce.Do(func() {
+		checkJobs = job.NewManager(checkMaxJobs, checkRetention)
+		if shared := cache.Shared(); shared != nil {
+			checkJobs.SetStore(job.NewRedisStore(shared, decodeReport))
+		}
+	})
+	return checkJobs
+}
+
+// decodeReport 解码共享存储中的检查报告
+func decodeReport(data []byte) (any, error) {
+	var report consistency.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// StartCheck 提交一致性检查任务，参数需由调用方校验
+func StartCheck(ctx context.Context, req *consistency.CheckRequest) (job.Snapshot, error) {
+	if db.GetDB() == nil {
+		return job.Snapshot{}, ErrCheckUnavailable
+	}
+	run := func(ctx context.Context, progress *job.Progress) (any, error) {
+		return Run(ctx, req, progress)
+	}
+	snapshot, err := getCheckJobs().Submit(ctx, checkKind, run, nil)
+	if err != nil {
+		return job.Snapshot{}, err
+	}
+	log.InfoWithContext(ctx, "已提交一致性检查任务", "addresses:", req.Addresses, "ftHoldings:", req.FtHoldings, "jobId:", snapshot.Id)
+	return snapshot, nil
+}
+
+// GetCheck 查询一致性检查任务状态
+func GetCheck(ctx context.Context, jobId string) (job.Snapshot, error) {
+	snapshot, err := getCheckJobs().Lookup(ctx, jobId)
+	if err != nil || snapshot.Kind != checkKind {
+		return job.Snapshot{}, job.ErrJobNotFound
+	}
+	return snapshot, nil
+}
+
+// Run 抽样地址和代币持有，逐个比较ElectrumX路径和数据库路径的结果
+// 单个对象请求失败或数据量超限时计入跳过数并继续，只有抽样失败时返回错误
+func Run(ctx context.Context, req *consistency.CheckRequest, progress *job.Progress) (*consistency.Report, error) {
+	if db.GetDB() == nil {
+		return nil, ErrCheckUnavailable
+	}
+	tip, err := chaintip.Current(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链顶失败: %w", err)
+	}
+	report := &consistency.Report{
+		StartedAt:     time.Now().Unix(),
+		SettleHeight:  chaintip.ConfirmedHeightLimit(tip, req.SettleDepth),
+		Discrepancies: []consistency.Discrepancy{},
+	}
+
+	addresses, err := address_transactions_dao.SampleAddresses(ctx, req.Addresses)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := ft_txo_dao.NewFtTxoDAO().SampleUnspentFtHoldings(ctx, req.FtHoldings)
+	if err != nil {
+		return nil, err
+	}
+	report.Addresses, report.FtHoldings = len(addresses), len(holdings)
+	progress.SetTotal(int64(len(addresses) + len(holdings)))
+
+	c := &checker{settleHeight: report.SettleHeight, ftTokensDAO: ft_tokens_dao.NewFtTokensDAO(), ftTxoDAO: ft_txo_dao.NewFtTxoDAO()}
+	for _, address := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		found, err := c.checkHistory(ctx, address)
+		record(ctx, report, address, 1, found, err)
+		progress.Add(1)
+	}
+	for _, holding := range holdings {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		subject := holding.FtHolderCombineScript + ":" + holding.FtContractId
+		found, err := c.checkFtHolding(ctx, holding.FtHolderCombineScript, holding.FtContractId)
+		record(ctx, report, subject, 2, found, err)
+		progress.Add(1)
+	}
+
+	report.FinishedAt = time.Now().Unix()
+	log.InfoWithContext(ctx, "一致性检查完成", "checks:", report.Checks, "skipped:", report.Skipped,
+		"discrepancies:", len(report.Discrepancies))
+	return report, nil
+}
+
+// record 汇总单个抽样对象的比较结果，checks为该对象对应的检查项数，出错时全部计为跳过
+func record(ctx context.Context, report *consistency.Report, subject string, checks int, found []consistency.Discrepancy, err error) {
+	if err != nil {
+		log.WarnWithContext(ctx, "一致性检查跳过", "subject:", subject, "错误:", err)
+		report.Skipped += checks
+		return
+	}
+	report.Checks += checks
+	report.Discrepancies = append(report.Discrepancies, found...)
+}
+
+// checker 比较单个抽样对象，只比较确认高度不超过settleHeight的数据
+type checker struct {
+	settleHeight int64
+	ftTokensDAO  *ft_tokens_dao.FtTokensDAO
+	ftTxoDAO     *ft_txo_dao.FtTxoDAO
+}
+
+// settled 判断所在高度的数据是否参与比较，未确认的数据不参与
+func (c *checker) settled(height int64) bool {
+	return height > 0 && (c.settleHeight < 0 || height <= c.settleHeight)
+}
+
+// checkHistory 比较地址的交易历史，ElectrumX中已确认的交易应都在地址交易记录中，
+// 地址交易记录中也不应有ElectrumX历史之外的交易
+func (c *checker) checkHistory(ctx context.Context, address string) ([]consistency.Discrepancy, error) {
+	scriptHash, err := scripthash.Resolve(ctx, scripthash.KindP2PKH, address)
+	if err != nil {
+		return nil, err
+	}
+	history, err := rpcex.GetScriptHashHistoryFrom(ctx, scriptHash, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易历史失败: %w", err)
+	}
+	if len(history) > maxHistoryTxs {
+		return nil, fmt.Errorf("%w: 历史交易%d笔", errTooLarge, len(history))
+	}
+	dbCount, err := address_transactions_dao.CountAddressTransactions(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(history))
+	var settled []string
+	for _, item := range history {
+		hashes = append(hashes, item.TxHash)
+		if c.settled(item.Height) {
+			settled = append(settled, item.TxHash)
+		}
+	}
+	indexed := make(map[string]bool, len(hashes))
+	for start := 0; start < len(hashes); start += historyChunkSize {
+		records, err := address_transactions_dao.GetAddressTransactionsByTxHashes(ctx, address, hashes[start:min(start+historyChunkSize, len(hashes))])
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			indexed[record.TxHash] = true
+		}
+	}
+
+	missing := diffKeys(settled, indexed)
+	extra := dbCount - int64(len(indexed))
+	if len(missing) == 0 && extra <= 0 {
+		return nil, nil
+	}
+	return []consistency.Discrepancy{{
+		Check:   consistency.CheckHistory,
+		Subject: address,
+		Live:    strconv.Itoa(len(history)),
+		DB:      strconv.FormatInt(dbCount, 10),
+		Missing: limitList(missing),
+	}}, nil
+}
+
+// checkFtHolding 比较持有者在某个代币上的未花费输出集合和余额
+// 数据库中未回填创建高度的输出按已确认处理
+func (c *checker) checkFtHolding(ctx context.Context, combineScript, contractId string) ([]consistency.Discrepancy, error) {
+	codeScript, err := c.ftTokensDAO.GetFtCodeScript(ctx, contractId)
+	if err != nil {
+		return nil, err
+	}
+	scriptHash, err := utility.FtHolderScriptHash(codeScript, combineScript)
+	if err != nil {
+		return nil, err
+	}
+	live, err := rpcex.GetUnspent(ctx, scriptHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取未花费输出失败: %w", err)
+	}
+	if len(live) > maxFtUtxos {
+		return nil, fmt.Errorf("%w: 未花费输出%d个", errTooLarge, len(live))
+	}
+	txos, err := c.ftTxoDAO.GetUnspentFtTxosByHolderAndContract(ctx, combineScript, contractId, chaintip.NoHeightLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	liveAll := make(map[string]bool, len(live))
+	var liveSettled []string
+	var txids []string
+	for _, utxo := range live {
+		key := outpointKey(utxo.TxHash, utxo.TxPos)
+		liveAll[key] = true
+		if c.settled(int64(utxo.Height)) {
+			liveSettled = append(liveSettled, key)
+			txids = append(txids, utxo.TxHash)
+		}
+	}
+	dbAll := make(map[string]bool, len(txos))
+	var dbSettled []string
+	var dbBalance uint64
+	for _, txo := range txos {
+		key := outpointKey(txo.UtxoTxid, txo.UtxoVout)
+		dbAll[key] = true
+		if txo.CreateHeight == nil || c.settled(*txo.CreateHeight) {
+			dbSettled = append(dbSettled, key)
+			dbBalance += txo.FtBalance
+		}
+	}
+
+	subject := combineScript + ":" + contractId
+	var found []consistency.Discrepancy
+	missing, extra := diffKeys(liveSettled, dbAll), diffKeys(dbSettled, liveAll)
+	if len(missing) > 0 || len(extra) > 0 {
+		found = append(found, consistency.Discrepancy{
+			Check:   consistency.CheckFtUtxo,
+			Subject: subject,
+			Live:    strconv.Itoa(len(live)),
+			DB:      strconv.Itoa(len(txos)),
+			Missing: limitList(missing),
+			Extra:   limitList(extra),
+		})
+	}
+
+	liveBalance, err := liveFtBalance(ctx, live, txids, c.settled)
+	if err != nil {
+		return nil, err
+	}
+	if liveBalance != dbBalance {
+		found = append(found, consistency.Discrepancy{
+			Check:   consistency.CheckFtBalance,
+			Subject: subject,
+			Live:    strconv.FormatUint(liveBalance, 10),
+			DB:      strconv.FormatUint(dbBalance, 10),
+		})
+	}
+	return found, nil
+}
+
+// liveFtBalance 解码已确认的未花费代码输出所在交易，累加其后磁带输出中记录的代币数量
+func liveFtBalance(ctx context.Context, live electrumx.UtxoResponse, txids []string, settled func(int64) bool) (uint64, error) {
+	if len(txids) == 0 {
+		return 0, nil
+	}
+	result := <-rpcbchain.DecodeTxs(ctx, txids)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	txs := result.Result.(map[string]*blockchain.TransactionResponse)
+
+	var balance uint64
+	for _, utxo := range live {
+		if !settled(int64(utxo.Height)) {
+			continue
+		}
+		tx, ok := txs[utxo.TxHash]
+		// 磁带输出紧跟在代码输出之后
+		if !ok || len(tx.Vout) <= utxo.TxPos+1 {
+			return 0, fmt.Errorf("无法解析交易%s的磁带输出", utxo.TxHash)
+		}
+		balance += utility.FtTapeAmount(tx.Vout[utxo.TxPos+1].ScriptPubKey.Hex)
+	}
+	return balance, nil
+}
+
+// outpointKey 返回输出的"交易ID:序号"表示
+func outpointKey(txid string, vout int) string {
+	return txid + ":" + strconv.Itoa(vout)
+}
+
+// diffKeys 返回keys中不在set里的键，按字典序排列
+func diffKeys(keys []string, set map[string]bool) []string {
+	var diff []string
+	for _, key := range keys {
+		if !set[key] {
+			diff = append(diff, key)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// limitList 截取报告中列出的条目
+func limitList(items []string) []string {
+	if len(items) > consistency.MaxListed {
+		return items[:consistency.MaxListed]
+	}
+	return items
+}
@@ -0,0 +1,42 @@
+package consistency
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"ginproject/entity/consistency"
+)
+
+func TestSettled(t *testing.T) {
+	c := &checker{settleHeight: 100}
+	cases := map[int64]bool{-1: false, 0: false, 1: true, 100: true, 101: false}
+	for height, want := range cases {
+		if got := c.settled(height); got != want {
+			t.Errorf("settled(%d) = %v, 期望 %v", height, got, want)
+		}
+	}
+	// 链顶高度不足时不限制高度
+	if !(&checker{settleHeight: -1}).settled(5) {
+		t.Error("不限制高度时已确认的数据应参与比较")
+	}
+}
+
+func TestDiffKeys(t *testing.T) {
+	set := map[string]bool{"a:0": true, "b:1": true}
+	got := diffKeys([]string{"c:0", "a:0", "b:0"}, set)
+	if want := []string{"b:0", "c:0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffKeys = %v, 期望 %v", got, want)
+	}
+	if got := diffKeys([]string{"a:0"}, set); got != nil {
+		t.Fatalf("没有差异时 = %v, 期望nil", got)
+	}
+
+	var many []string
+	for i := 0; i < consistency.MaxListed+5; i++ {
+		many = append(many, fmt.Sprintf("tx%02d", i))
+	}
+	if got := limitList(many); len(got) != consistency.MaxListed || got[0] != "tx00" {
+		t.Fatalf("limitList = %v", got)
+	}
+}
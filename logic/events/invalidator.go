@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+
+	"ginproject/entity/event"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/eventbus"
+)
+
+// 缓存失效消费者名称
+const invalidatorConsumer = "cache_invalidator"
+
+// StartInvalidator 从事件总线接收区块事件，出块后清空内容随新区块失效的进程内缓存，ctx取消时退出
+// 每个实例各自清空本实例的缓存，只接收启动之后的事件
+func StartInvalidator(ctx context.Context) {
+	if err := eventbus.Default().SubscribeLive(ctx, invalidatorConsumer, event.TopicBlock, invalidate); err != nil {
+		log.Warnf("订阅区块事件失败，缓存只按有效期过期: %v", err)
+	}
+}
+
+// invalidate 处理一批区块事件，同一批事件只需要清空一次
+func invalidate(ctx context.Context, events []eventbus.Event) error {
+	names := cache.PurgeBlockScoped()
+	log.DebugWithContext(ctx, "新区块事件，已清空缓存", "blocks:", len(events), "caches:", names)
+	return nil
+}
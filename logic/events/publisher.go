@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"ginproject/entity/block"
+	"ginproject/entity/config"
+	"ginproject/entity/event"
+	"ginproject/entity/transaction"
+	"ginproject/entity/utility"
+	blocklogic "ginproject/logic/block"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/healthscore"
+	"ginproject/middleware/log"
+	"ginproject/middleware/scriptwatch"
+	"ginproject/repo/eventbus"
+)
+
+// 检查新区块的周期
+const publishInterval = 5 * time.Second
+
+// StartPublisher 启动后台任务，跟随链顶将新区块的交易、转账和区块事件发布到事件总线，ctx取消时退出
+// 从事件日志中最后一个区块之后继续发布，日志为空时从启动时的链顶开始；未开启发布时不做任何事
+func StartPublisher(ctx context.Context) {
+	if !config.GetConfig().GetEventBusConfig().Publish {
+		return
+	}
+
+	bus := eventbus.Default()
+	go func() {
+		ticker := time.NewTicker(publishInterval)
+		defer ticker.Stop()
+
+		next := resumeHeight(ctx, bus)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			tip, err := chaintip.Current(ctx)
+			if err != nil {
+				continue
+			}
+			if next < 0 {
+				next = tip.Height
+			}
+			next = publishRange(ctx, bus, next, tip.Height)
+			healthscore.ReportIndexerLag(tip.Height - next + 1)
+		}
+	}()
+}
+
+// resumeHeight 根据最后一个区块事件确定下一个需要发布的高度，无法确定时返回-1
+func resumeHeight(ctx context.Context, bus *eventbus.Bus) int64 {
+	last, err := bus.Last(ctx, event.TopicBlock)
+	if err != nil {
+		log.WarnWithContext(ctx, "读取最后一个区块事件失败，从链顶开始发布", "错误:", err)
+		return -1
+	}
+	if last == nil {
+		return -1
+	}
+
+	var blockEvent event.BlockEvent
+	if err := json.Unmarshal(last.Payload, &blockEvent); err != nil {
+		log.WarnWithContext(ctx, "解析最后一个区块事件失败，从链顶开始发布", "错误:", err)
+		return -1
+	}
+	return blockEvent.Height + 1
+}
+
+// publishRange 发布[from, tip]范围内的区块，单轮最多发布一个扫描范围，返回下一次需要发布的高度
+func publishRange(ctx context.Context, bus *eventbus.Bus, from, tip int64) int64 {
+	if from > tip {
+		return from
+	}
+	to := min(tip, from+block.MaxTxScanRange-1)
+
+	next := from
+	height, err := blocklogic.ScanRange(ctx, from, to, func(b *block.BlockWithTxs) error {
+		if err := publishBlock(ctx, bus, b); err != nil {
+			return err
+		}
+		next = b.Height + 1
+		return nil
+	})
+	if err != nil {
+		log.WarnWithContext(ctx, "发布区块事件失败，下一轮重试", "height:", height, "错误:", err)
+	}
+	return next
+}
+
+// publishBlock 发布区块内的交易和转账事件，最后发布区块事件
+// 区块事件作为该区块发布完成的标记，中途失败时整个区块会重新发布，消费者需要按txid去重
+func publishBlock(ctx context.Context, bus *eventbus.Bus, b *block.BlockWithTxs) error {
+	reportUnknownScripts(ctx, b)
+	for _, tx := range event.NewTxEvents(b) {
+		if err := bus.Publish(ctx, event.TopicTx, tx.Txid, tx); err != nil {
+			return err
+		}
+		if topic, ok := event.TransferTopic(tx.TxType); ok {
+			if err := bus.Publish(ctx, topic, tx.Txid, tx); err != nil {
+				return err
+			}
+		}
+	}
+	return bus.Publish(ctx, event.TopicBlock, b.Hash, event.NewBlockEvent(b))
+}
+
+// reportUnknownScripts 上报区块中无法识别的输出脚本，跟随链顶发布时可以及时发现新的脚本模板
+func reportUnknownScripts(ctx context.Context, b *block.BlockWithTxs) {
+	for i := range b.Tx {
+		tx := &b.Tx[i]
+		for _, vout := range tx.Vout {
+			out := transaction.TxOutputScript{Type: vout.ScriptPubKey.Type, Asm: vout.ScriptPubKey.Asm}
+			if transaction.ScriptKind(out) != transaction.ScriptKindUnknown {
+				continue
+			}
+			if utility.ClassifyScript(vout.ScriptPubKey.Hex).Class != utility.ScriptClassUnknown {
+				continue
+			}
+			scriptwatch.ReportUnknown(ctx, tx.Txid, vout.N, vout.ScriptPubKey.Type, vout.ScriptPubKey.Hex)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package exchange
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"ginproject/entity/exchange"
@@ -13,10 +15,37 @@ import (
 const (
 	defaultSymbol  = "TBCUSDT"
 	requestTimeout = 5 * time.Second
+	// 汇率快照的有效期，超过后按请求实时获取
+	snapshotMaxAge = 2 * time.Minute
 )
 
-// GetExchangeRate 使用上下文获取TBC交易所汇率信息
+// 周期任务刷新的最近一次汇率快照
+var snapshot atomic.Pointer[exchange.ExchangeRateResponse]
+
+// RefreshExchangeRate 获取汇率并保存为快照，获取失败时保留之前的快照
+func RefreshExchangeRate(ctx context.Context) error {
+	rate, err := fetchExchangeRate(ctx)
+	if err != nil {
+		return err
+	}
+	if rate.Rate == 0 {
+		return fmt.Errorf("获取交易所汇率失败")
+	}
+	snapshot.Store(rate)
+	return nil
+}
+
+// GetExchangeRate 使用上下文获取TBC交易所汇率信息，汇率快照未过期时直接返回快照
 func GetExchangeRate(ctx context.Context) (*exchange.ExchangeRateResponse, error) {
+	if cached := snapshot.Load(); cached != nil && time.Since(time.Unix(cached.Time, 0)) < snapshotMaxAge {
+		rate := *cached
+		return &rate, nil
+	}
+	return fetchExchangeRate(ctx)
+}
+
+// fetchExchangeRate 从交易所获取汇率，获取失败时返回汇率为0的默认响应
+func fetchExchangeRate(ctx context.Context) (*exchange.ExchangeRateResponse, error) {
 	log.InfoWithContext(ctx, "开始获取TBC交易所汇率信息")
 
 	// 创建带超时的子上下文
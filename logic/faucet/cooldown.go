@@ -0,0 +1,87 @@
+package faucet
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cooldownStore 按地址和IP记录领取的冷却期
+type cooldownStore interface {
+	// reserve 检查并占用地址和IP的冷却位，未冷却结束时返回还需等待的时长
+	reserve(ctx context.Context, address, ip string, addressPeriod, ipPeriod time.Duration, now time.Time) (time.Duration, bool)
+	// release 节点明确拒绝发放时释放占用的冷却位
+	release(ctx context.Context, address, ip string, reservedAt time.Time)
+}
+
+// cooldown 进程内的冷却记录，按地址和IP记录最近一次领取时间
+// 发放前先占用两个冷却位，节点明确拒绝时释放，避免并发请求同时通过检查
+type cooldown struct {
+	mu        sync.Mutex
+	addresses map[string]time.Time
+	ips       map[string]time.Time
+	lastPrune time.Time
+}
+
+func newCooldown() *cooldown {
+	return &cooldown{
+		addresses: make(map[string]time.Time),
+		ips:       make(map[string]time.Time),
+	}
+}
+
+// reserve 检查并占用地址和IP的冷却位，未冷却结束时返回还需等待的时长
+func (c *cooldown) reserve(_ context.Context, address, ip string, addressPeriod, ipPeriod time.Duration, now time.Time) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneLocked(now, max(addressPeriod, ipPeriod))
+
+	wait := max(remaining(c.addresses[address], addressPeriod, now), remaining(c.ips[ip], ipPeriod, now))
+	if wait > 0 {
+		return wait, false
+	}
+
+	c.addresses[address] = now
+	c.ips[ip] = now
+	return 0, true
+}
+
+// release 发放失败时释放占用的冷却位
+func (c *cooldown) release(_ context.Context, address, ip string, reservedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.addresses[address].Equal(reservedAt) {
+		delete(c.addresses, address)
+	}
+	if c.ips[ip].Equal(reservedAt) {
+		delete(c.ips, ip)
+	}
+}
+
+// pruneLocked 定期清理已过冷却期的记录，调用方需持有锁
+func (c *cooldown) pruneLocked(now time.Time, period time.Duration) {
+	if now.Sub(c.lastPrune) < time.Minute {
+		return
+	}
+	c.lastPrune = now
+	for key, at := range c.addresses {
+		if now.Sub(at) >= period {
+			delete(c.addresses, key)
+		}
+	}
+	for key, at := range c.ips {
+		if now.Sub(at) >= period {
+			delete(c.ips, key)
+		}
+	}
+}
+
+// remaining 计算距离冷却结束的剩余时长
+func remaining(last time.Time, period time.Duration, now time.Time) time.Duration {
+	if last.IsZero() {
+		return 0
+	}
+	return max(last.Add(period).Sub(now), 0)
+}
@@ -0,0 +1,90 @@
+package faucet
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+)
+
+// 只删除仍由本次领取占用的冷却位，避免误删其它请求之后占用的记录
+var releaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// redisCooldown 集群模式下保存在Redis中的冷却记录，多个实例共享同一冷却期
+// 冷却位通过SET NX占用并以冷却期作为过期时间，Redis出错时退回进程内的冷却记录
+type redisCooldown struct {
+	redis    *cache.Redis
+	fallback *cooldown
+}
+
+// cooldownKey 冷却位的键和冷却期
+type cooldownKey struct {
+	key    string
+	period time.Duration
+}
+
+func (c *redisCooldown) keys(address, ip string, addressPeriod, ipPeriod time.Duration) []cooldownKey {
+	return []cooldownKey{
+		{key: c.redis.Key("faucet:address:" + address), period: addressPeriod},
+		{key: c.redis.Key("faucet:ip:" + ip), period: ipPeriod},
+	}
+}
+
+// reserve 依次占用地址和IP的冷却位，任一已被占用时释放本次已占用的冷却位
+func (c *redisCooldown) reserve(ctx context.Context, address, ip string, addressPeriod, ipPeriod time.Duration, now time.Time) (time.Duration, bool) {
+	value := strconv.FormatInt(now.UnixNano(), 10)
+	keys := c.keys(address, ip, addressPeriod, ipPeriod)
+	acquired := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k.period <= 0 {
+			continue
+		}
+		ok, err := c.redis.Client().SetNX(ctx, k.key, value, k.period).Result()
+		if err != nil {
+			log.WarnWithContext(ctx, "Redis占用水龙头冷却位失败，使用本实例的冷却记录", "错误:", err)
+			c.unlock(ctx, acquired, value)
+			return c.fallback.reserve(ctx, address, ip, addressPeriod, ipPeriod, now)
+		}
+		if !ok {
+			c.unlock(ctx, acquired, value)
+			return c.wait(ctx, keys), false
+		}
+		acquired = append(acquired, k.key)
+	}
+	return 0, true
+}
+
+// release 释放本次领取占用的冷却位，请求已取消时仍需释放
+func (c *redisCooldown) release(ctx context.Context, address, ip string, reservedAt time.Time) {
+	ctx = context.WithoutCancel(ctx)
+	keys := c.keys(address, ip, 0, 0)
+	c.unlock(ctx, []string{keys[0].key, keys[1].key}, strconv.FormatInt(reservedAt.UnixNano(), 10))
+	c.fallback.release(ctx, address, ip, reservedAt)
+}
+
+// unlock 删除仍为value的冷却位，失败时等待过期
+func (c *redisCooldown) unlock(ctx context.Context, keys []string, value string) {
+	for _, key := range keys {
+		if err := releaseScript.Run(ctx, c.redis.Client(), []string{key}, value).Err(); err != nil {
+			log.WarnWithContext(ctx, "Redis释放水龙头冷却位失败", "key:", key, "错误:", err)
+		}
+	}
+}
+
+// wait 返回被占用的冷却位中最长的剩余时间，至少1秒
+func (c *redisCooldown) wait(ctx context.Context, keys []cooldownKey) time.Duration {
+	wait := time.Second
+	for _, k := range keys {
+		if k.period <= 0 {
+			continue
+		}
+		if ttl, err := c.redis.Client().PTTL(ctx, k.key).Result(); err == nil && ttl > wait {
+			wait = ttl
+		}
+	}
+	return wait
+}
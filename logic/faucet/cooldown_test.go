@@ -0,0 +1,53 @@
+package faucet
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCooldownReserve(t *testing.T) {
+	c := newCooldown()
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	hour := time.Hour
+
+	if _, ok := c.reserve(ctx, "addr1", "1.1.1.1", 24*hour, hour, now); !ok {
+		t.Fatal("首次领取应当成功")
+	}
+
+	// 同一地址换IP仍受地址冷却限制
+	wait, ok := c.reserve(ctx, "addr1", "2.2.2.2", 24*hour, hour, now.Add(2*hour))
+	if ok || wait != 22*hour {
+		t.Fatalf("地址冷却期内应拒绝，实际: %v, %v", wait, ok)
+	}
+
+	// 同一IP换地址受IP冷却限制
+	wait, ok = c.reserve(ctx, "addr2", "1.1.1.1", 24*hour, hour, now.Add(30*time.Minute))
+	if ok || wait != 30*time.Minute {
+		t.Fatalf("IP冷却期内应拒绝，实际: %v, %v", wait, ok)
+	}
+
+	// IP冷却结束后可以为其他地址领取
+	if _, ok := c.reserve(ctx, "addr2", "1.1.1.1", 24*hour, hour, now.Add(hour)); !ok {
+		t.Fatal("IP冷却结束后应当成功")
+	}
+}
+
+func TestCooldownRelease(t *testing.T) {
+	c := newCooldown()
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	c.reserve(ctx, "addr1", "1.1.1.1", time.Hour, time.Hour, now)
+	c.release(ctx, "addr1", "1.1.1.1", now)
+	if _, ok := c.reserve(ctx, "addr1", "1.1.1.1", time.Hour, time.Hour, now.Add(time.Second)); !ok {
+		t.Fatal("发放失败释放后应允许重新领取")
+	}
+
+	// 旧的释放不能清除之后的占用
+	c.release(ctx, "addr1", "1.1.1.1", now)
+	if _, ok := c.reserve(ctx, "addr1", "1.1.1.1", time.Hour, time.Hour, now.Add(2*time.Second)); ok {
+		t.Fatal("过期的释放不应影响新的占用")
+	}
+}
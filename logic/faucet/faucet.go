@@ -0,0 +1,102 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/faucet"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/rpc/blockchain"
+)
+
+var (
+	// ErrFaucetDisabled 水龙头未启用
+	ErrFaucetDisabled = errors.New("水龙头未启用")
+	// ErrMainnet 节点连接的是主网
+	ErrMainnet = errors.New("水龙头仅在测试网或回归测试网上可用")
+)
+
+// CooldownError 地址或IP仍在冷却期内
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("领取过于频繁，请在%d秒后重试", int64(e.RetryAfter.Seconds()+0.5))
+}
+
+// 全局冷却记录
+var limiter = newCooldown()
+
+// cooldowns 返回当前使用的冷却记录，集群模式下使用Redis在实例之间共享
+func cooldowns() cooldownStore {
+	if shared := cache.Shared(); shared != nil {
+		return &redisCooldown{redis: shared, fallback: limiter}
+	}
+	return limiter
+}
+
+// RequestFunds 由节点钱包向指定地址发放测试币
+func RequestFunds(ctx context.Context, req *faucet.FaucetRequest, clientIP string) (*faucet.FaucetResponse, int, error) {
+	cfg := config.GetConfig().GetFaucetConfig()
+	if !cfg.Enabled || cfg.Amount <= 0 {
+		return nil, http.StatusNotFound, ErrFaucetDisabled
+	}
+	if err := req.Validate(); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// 每次发放前确认节点所在网络，防止配置误用于主网
+	chainResult := <-blockchain.FetchChainInfo(ctx)
+	if chainResult.Error != nil {
+		log.ErrorWithContext(ctx, "获取节点网络信息失败", "error", chainResult.Error)
+		return nil, http.StatusServiceUnavailable, fmt.Errorf("获取节点网络信息失败: %w", chainResult.Error)
+	}
+	chainInfo, _ := chainResult.Result.(map[string]interface{})
+	if chain, _ := chainInfo["chain"].(string); chain == "" || chain == "main" {
+		log.WarnWithContext(ctx, "拒绝在非测试网络上发放测试币", "chain", chain)
+		return nil, http.StatusForbidden, ErrMainnet
+	}
+
+	addressPeriod := time.Duration(cfg.AddressCooldown) * time.Second
+	ipPeriod := time.Duration(cfg.IPCooldown) * time.Second
+	now := time.Now()
+	store := cooldowns()
+	if wait, ok := store.reserve(ctx, req.Address, clientIP, addressPeriod, ipPeriod, now); !ok {
+		log.InfoWithContext(ctx, "水龙头领取仍在冷却期", "address", req.Address, "ip", clientIP, "wait", wait)
+		return nil, http.StatusTooManyRequests, &CooldownError{RetryAfter: wait}
+	}
+
+	result := <-blockchain.SendToAddress(ctx, req.Address, cfg.Amount)
+	if result.Error != nil {
+		// 超时或连接错误时转账可能已经发出，只有节点明确拒绝时才释放冷却位
+		if rejectedByNode(result.Error) {
+			store.release(ctx, req.Address, clientIP, now)
+		}
+		return nil, http.StatusInternalServerError, result.Error
+	}
+	txid, ok := result.Result.(string)
+	if !ok {
+		log.ErrorWithContext(ctx, "节点钱包返回的交易ID无效", "result", result.Result)
+		return nil, http.StatusInternalServerError, fmt.Errorf("节点钱包返回的交易ID无效")
+	}
+
+	log.InfoWithContext(ctx, "水龙头发放成功", "address", req.Address, "ip", clientIP, "txid", txid)
+	return &faucet.FaucetResponse{
+		TxId:          txid,
+		Address:       req.Address,
+		Amount:        cfg.Amount,
+		NextRequestAt: now.Add(addressPeriod).Unix(),
+	}, http.StatusOK, nil
+}
+
+// rejectedByNode 判断转账错误是否为节点返回的RPC错误，此时节点确定没有发出转账
+func rejectedByNode(err error) bool {
+	var rpcErr *blockchain.RPCError
+	return errors.As(err, &rpcErr)
+}
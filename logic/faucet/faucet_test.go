@@ -0,0 +1,27 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ginproject/repo/rpc/blockchain"
+)
+
+func TestRejectedByNode(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"余额不足", fmt.Errorf("节点钱包转账失败: %w", &blockchain.RPCError{Code: -6, Message: "Insufficient funds"}), true},
+		{"超时", fmt.Errorf("节点钱包转账失败: %w", context.DeadlineExceeded), false},
+		{"连接失败", errors.New("HTTP请求失败: connection reset by peer"), false},
+	}
+	for _, c := range cases {
+		if got := rejectedByNode(c.err); got != c.want {
+			t.Errorf("%s: rejectedByNode = %v, 期望 %v", c.name, got, c.want)
+		}
+	}
+}
@@ -7,10 +7,8 @@ import (
 
 	"ginproject/entity/blockchain"
 	"ginproject/entity/ft"
-	"ginproject/entity/utility"
 	"ginproject/middleware/log"
 	repoBlockchain "ginproject/repo/rpc/blockchain"
-	"ginproject/repo/rpc/electrumx"
 )
 
 // GetFtBalance 获取FT余额
@@ -31,11 +29,11 @@ func (l *FtLogic) GetFtBalance(ctx context.Context, req *ft.FtBalanceAddressRequ
 	// 添加00作为校验
 	combineScript += "00"
 
-	// 获取代币小数位数
-	ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, req.ContractId)
+	// 获取代币单位信息
+	units, err := l.getTokenUnits(ctx, req.ContractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币小数位数失败: %v", err)
-		return nil, fmt.Errorf("获取代币小数位数失败: %v", err)
+		return nil, fmt.Errorf("获取代币小数位数失败: %w", err)
 	}
 
 	// 调用DAO层获取余额
@@ -51,8 +49,9 @@ func (l *FtLogic) GetFtBalance(ctx context.Context, req *ft.FtBalanceAddressRequ
 	response := &ft.FtBalanceAddressResponse{
 		CombineScript: combineScript,
 		FtContractId:  req.ContractId,
-		FtDecimal:     int(ftDecimal),
+		FtDecimal:     units.Decimals,
 		FtBalance:     ftBalance,
+		TokenInfo:     units,
 	}
 
 	return response, nil
@@ -79,10 +78,13 @@ func (l *FtLogic) GetMultiFtBalanceByAddress(ctx context.Context, req *ft.FtBala
 	// 初始化响应结果切片
 	responseList := make([]ft.TBC20FTBalanceResponse, 0, len(req.FtContractId))
 
+	// 一次性预取所有合约的单位信息
+	l.prefetchTokenUnits(ctx, req.FtContractId)
+
 	// 遍历每个合约ID，查询余额
 	for _, contractId := range req.FtContractId {
-		// 获取代币小数位数
-		ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, contractId)
+		// 获取代币单位信息
+		units, err := l.getTokenUnits(ctx, contractId)
 		if err != nil {
 			log.ErrorWithContextf(ctx, "获取代币小数位数失败，合约ID=%s: %v", contractId, err)
 			// 跳过错误的合约，继续处理其他合约
@@ -101,8 +103,9 @@ func (l *FtLogic) GetMultiFtBalanceByAddress(ctx context.Context, req *ft.FtBala
 		response := ft.TBC20FTBalanceResponse{
 			CombineScript: combineScript,
 			FtContractId:  contractId,
-			FtDecimal:     int(ftDecimal),
+			FtDecimal:     units.Decimals,
 			FtBalance:     ftBalance,
+			TokenInfo:     units,
 		}
 
 		// 添加到响应列表
@@ -115,6 +118,26 @@ func (l *FtLogic) GetMultiFtBalanceByAddress(ctx context.Context, req *ft.FtBala
 
 // getTokenListByCombineScript 获取代币列表的通用逻辑
 func (l *FtLogic) getTokenListByCombineScript(ctx context.Context, combineScript string) ([]ft.TokenInfo, error) {
+	heldTokens, err := l.getHeldTokensByCombineScript(ctx, combineScript)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenList := make([]ft.TokenInfo, 0, len(heldTokens))
+	for _, held := range heldTokens {
+		tokenList = append(tokenList, held.TokenInfo)
+	}
+	return tokenList, nil
+}
+
+// heldToken 地址持有的代币及其价格
+type heldToken struct {
+	ft.TokenInfo
+	Price float64 // 以TBC计价的代币价格
+}
+
+// getHeldTokensByCombineScript 获取合并脚本持有的非LP代币及余额，余额为0的代币不返回
+func (l *FtLogic) getHeldTokensByCombineScript(ctx context.Context, combineScript string) ([]heldToken, error) {
 	
 
 	log.InfoWithContextf(ctx, "通过合并脚本获取代币列表请求: 合并脚本=%s", combineScript)
@@ -128,18 +151,18 @@ func (l *FtLogic) getTokenListByCombineScript(ctx context.Context, combineScript
 
 	if len(contractIds) == 0 {
 		log.InfoWithContextf(ctx, "地址[%s]未持有任何代币", combineScript)
-		return []ft.TokenInfo{}, nil
+		return []heldToken{}, nil
 	}
 
 	log.InfoWithContextf(ctx, "地址[%s]持有的代币数量: %d", combineScript, len(contractIds))
 
 	// 初始化代币列表
-	tokenList := make([]ft.TokenInfo, 0, len(contractIds))
+	tokenList := make([]heldToken, 0, len(contractIds))
 
 	// 遍历所有合约ID，查询详细信息
 	for _, contractId := range contractIds {
 		// 查询代币详细信息
-		token, err := l.ftTokensDAO.GetFtTokenById(contractId)
+		token, err := l.ftTokensDAO.GetFtTokenById(ctx, contractId)
 		if err != nil {
 			log.WarnWithContextf(ctx, "获取代币[%s]详细信息失败: %v，跳过此代币", contractId, err)
 			continue
@@ -172,7 +195,7 @@ func (l *FtLogic) getTokenListByCombineScript(ctx context.Context, combineScript
 			FtName:       token.FtName,
 			FtSymbol:     token.FtSymbol,
 		}
-		tokenList = append(tokenList, tokenInfo)
+		tokenList = append(tokenList, heldToken{TokenInfo: tokenInfo, Price: token.FtTokenPrice})
 	}
 
 	return tokenList, nil
@@ -248,11 +271,11 @@ func (l *FtLogic) GetFtBalanceByCombineScript(ctx context.Context, req *ft.FtBal
 	}
 
 	log.InfoWithContextf(ctx, "根据合并脚本获取FT余额: 合并脚本=%s, 合约哈希=%s", combineScript, req.ContractHash)
-	// 获取代币小数位数
-	ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, req.ContractHash)
+	// 获取代币单位信息
+	units, err := l.getTokenUnits(ctx, req.ContractHash)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币小数位数失败: %v", err)
-		return nil, fmt.Errorf("获取代币小数位数失败: %v", err)
+		return nil, fmt.Errorf("获取代币小数位数失败: %w", err)
 	}
 	// 从数据库获取FT余额
 	dbBalance, err := l.getFtBalanceFromDB(ctx, combineScript, req.ContractHash)
@@ -262,50 +285,26 @@ func (l *FtLogic) GetFtBalanceByCombineScript(ctx context.Context, req *ft.FtBal
 		return &ft.FtBalanceCombineScriptResponse{
 			CombineScript: combineScript,
 			ContractHash:  req.ContractHash,
-			FtDecimal:     int(ftDecimal),
+			FtDecimal:     units.Decimals,
 			FtBalance:     dbBalance,
+			TokenInfo:     units,
 		}, nil
 	}
 
 	log.WarnWithContextf(ctx, "从数据库获取FT余额失败: %v, 尝试通过RPC获取", err)
 
-	// 方法2: 通过RPC获取数据（备用方案）
-	// 1. 解码合约交易，获取合约脚本
-	decodeContractResult := <-repoBlockchain.DecodeTxHash(ctx, req.ContractHash)
-	if decodeContractResult.Error != nil {
-		log.ErrorWithContextf(ctx, "解码合约交易失败: %v", decodeContractResult.Error)
-		return nil, fmt.Errorf("解码合约交易失败: %v", decodeContractResult.Error)
-	}
-
-	contractTx, ok := decodeContractResult.Result.(*blockchain.TransactionResponse)
-	if !ok {
-		return nil, fmt.Errorf("解码合约交易响应格式错误")
-	}
-
-	if len(contractTx.Vout) == 0 {
-		return nil, fmt.Errorf("合约交易输出为空")
-	}
-
-	// 获取合约脚本
-	codeScriptHex := contractTx.Vout[0].ScriptPubKey.Hex
-	contractTrait := codeScriptHex[0 : len(codeScriptHex)-54]
-	completeScript := contractTrait + combineScript + "0502436f6465" // "0502436f6465"是"Code"的十六进制表示
-
-	// 2. 计算脚本哈希
-	scriptHash, err := utility.ConvertStrToSha256(completeScript)
+	// 方法2: 通过RPC获取数据（备用方案），获取持有者代码脚本的未花费UTXO列表
+	unspentResults, err := getFtCodeUnspents(ctx, req.ContractHash, []string{combineScript})
 	if err != nil {
-		log.ErrorWithContextf(ctx, "计算脚本哈希失败: %v", err)
-		return nil, fmt.Errorf("计算脚本哈希失败: %v", err)
+		return nil, err
 	}
-
-	// 3. 获取未花费的UTXO列表
-	unspentUtxos, err := electrumx.GetUnspent(ctx, scriptHash)
-	if err != nil {
-		log.ErrorWithContextf(ctx, "获取未花费UTXO失败: %v", err)
-		return nil, fmt.Errorf("获取未花费UTXO失败: %v", err)
+	if unspentResults[0].Error != nil {
+		log.ErrorWithContextf(ctx, "获取未花费UTXO失败: %v", unspentResults[0].Error)
+		return nil, fmt.Errorf("获取未花费UTXO失败: %v", unspentResults[0].Error)
 	}
+	unspentUtxos := unspentResults[0].Utxos
 
-	// 4. 计算总余额
+	// 计算总余额
 	var contractBalance uint64 = 0
 
 	for _, utxo := range unspentUtxos {
@@ -365,8 +364,9 @@ func (l *FtLogic) GetFtBalanceByCombineScript(ctx context.Context, req *ft.FtBal
 	response := &ft.FtBalanceCombineScriptResponse{
 		CombineScript: combineScript,
 		ContractHash:  req.ContractHash,
-		FtDecimal:     int(ftDecimal),
+		FtDecimal:     units.Decimals,
 		FtBalance:     contractBalance,
+		TokenInfo:     units,
 	}
 
 	return response, nil
@@ -0,0 +1,154 @@
+package ft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/event"
+	"ginproject/entity/ft"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/ft_burn_dao"
+	"ginproject/repo/eventbus"
+)
+
+// 代币销毁索引在事件总线上的消费者名称
+const burnConsumer = "ft_burns"
+
+// 代币输出由索引器写入ft_txo_set，区块时间在该时长内的交易尚未写入时重新投递等待，超过后视为没有代币输出
+const burnIndexGrace = 10 * time.Minute
+
+// 销毁地址的组合脚本，即公钥哈希加类型00
+var burnCombineScript = func() string {
+	publicKeyHash, err := utility.ConvertAddressToPublicKeyHash(ft.BurnAddress)
+	if err != nil {
+		panic(fmt.Sprintf("解析销毁地址失败: %v", err))
+	}
+	return publicKeyHash + "00"
+}()
+
+// StartBurnIndexer 以ft_burns消费者的身份订阅代币转账事件，将转入销毁地址的代币输出写入销毁记录表，ctx取消时退出
+// 事件消费者启动前的销毁记录由迁移脚本从ft_txo_set回填；未连接数据库时不做任何事
+func StartBurnIndexer(ctx context.Context) {
+	if db.GetDB() == nil {
+		return
+	}
+	if !config.GetConfig().GetEventBusConfig().Publish {
+		log.WarnWithContext(ctx, "事件发布未开启，代币销毁记录不会更新")
+	}
+
+	l := NewFtLogic()
+	if err := eventbus.Default().Subscribe(ctx, burnConsumer, event.TopicTokenTransfer, l.indexBurns); err != nil {
+		log.WarnWithContext(ctx, "订阅代币转账事件失败", "错误:", err)
+	}
+}
+
+// indexBurns 查询一批转账交易创建的代币输出并写入其中的销毁记录，无法解析的事件记录日志后跳过
+func (l *FtLogic) indexBurns(ctx context.Context, events []eventbus.Event) error {
+	txs := make([]event.TxEvent, 0, len(events))
+	txids := make([]string, 0, len(events))
+	for _, e := range events {
+		var tx event.TxEvent
+		if err := json.Unmarshal(e.Payload, &tx); err != nil {
+			log.WarnWithContext(ctx, "跳过无法解析的代币转账事件", "offset:", e.Offset, "错误:", err)
+			continue
+		}
+		txs = append(txs, tx)
+		txids = append(txids, tx.Txid)
+	}
+
+	txos, err := l.ftTxoDAO.GetFtTxosByTxids(ctx, txids)
+	if err != nil {
+		return fmt.Errorf("查询代币交易输出失败: %w", err)
+	}
+	burns, err := collectBurns(txs, txos, time.Now())
+	if err != nil {
+		return err
+	}
+	return ft_burn_dao.UpsertFtBurns(ctx, burns)
+}
+
+// collectBurns 从交易创建的代币输出中找出转入销毁地址的输出
+// 区块时间在等待时长内的交易还没有任何代币输出时返回错误，由事件总线稍后重新投递
+func collectBurns(txs []event.TxEvent, txos []*dbtable.FtTxoSet, now time.Time) ([]*dbtable.FtBurn, error) {
+	byTxid := make(map[string][]*dbtable.FtTxoSet, len(txs))
+	for _, txo := range txos {
+		byTxid[txo.UtxoTxid] = append(byTxid[txo.UtxoTxid], txo)
+	}
+
+	var burns []*dbtable.FtBurn
+	for _, tx := range txs {
+		outputs, ok := byTxid[tx.Txid]
+		if !ok {
+			if now.Sub(time.Unix(tx.BlockTime, 0)) < burnIndexGrace {
+				return nil, fmt.Errorf("交易%s的代币输出尚未索引", tx.Txid)
+			}
+			continue
+		}
+		for _, txo := range outputs {
+			if txo.FtHolderCombineScript != burnCombineScript {
+				continue
+			}
+			burns = append(burns, &dbtable.FtBurn{
+				Txid:         txo.UtxoTxid,
+				Vout:         txo.UtxoVout,
+				FtContractId: txo.FtContractId,
+				Amount:       txo.FtBalance,
+				Height:       tx.Height,
+				BlockTime:    tx.BlockTime,
+			})
+		}
+	}
+	return burns, nil
+}
+
+// GetFtBurns 分页获取代币的销毁记录和累计销毁数量
+func (l *FtLogic) GetFtBurns(ctx context.Context, req *ft.FtBurnsRequest) (*ft.FtBurnsResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		return nil, err
+	}
+
+	// 精度查询同时确认代币存在
+	decimal, err := l.getFtDecimal(ctx, req.ContractId)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取代币精度失败: contractId=%s, %v", req.ContractId, err)
+		return nil, fmt.Errorf("获取代币信息失败: %w", err)
+	}
+
+	totals, err := ft_burn_dao.GetFtBurnTotals(ctx, req.ContractId)
+	if err != nil {
+		return nil, err
+	}
+	burns, err := ft_burn_dao.GetFtBurnsByContract(ctx, req.ContractId, req.Page*req.Size, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ft.FtBurnsResponse{
+		FtContractId: req.ContractId,
+		FtDecimal:    int(decimal),
+		BurnCount:    totals.BurnCount,
+		TotalBurned:  totals.TotalBurned,
+		BurnedSupply: utility.FormatUnits(int64(totals.TotalBurned), int(decimal), false),
+		Result:       make([]ft.FtBurnItem, 0, len(burns)),
+		Meta:         utility.NewPageMeta(req.Page, req.Size, totals.BurnCount),
+	}
+	for _, burn := range burns {
+		response.Result = append(response.Result, ft.FtBurnItem{
+			Txid:      burn.Txid,
+			Vout:      burn.Vout,
+			Amount:    burn.Amount,
+			Height:    burn.Height,
+			BlockTime: burn.BlockTime,
+		})
+	}
+
+	log.InfoWithContextf(ctx, "获取代币销毁记录成功: contractId=%s, 销毁次数=%d", req.ContractId, totals.BurnCount)
+	return response, nil
+}
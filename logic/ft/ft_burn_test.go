@@ -0,0 +1,43 @@
+package ft
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/event"
+)
+
+func TestCollectBurns(t *testing.T) {
+	// 迁移脚本按该组合脚本回填历史销毁记录
+	if burnCombineScript != "759d6677091e973b9e9d99f19c68fbf43e3f05f900" {
+		t.Fatalf("销毁地址组合脚本 = %s", burnCombineScript)
+	}
+
+	now := time.Now()
+	burnTx := strings.Repeat("a", 64)
+	oldTx := strings.Repeat("b", 64)
+	txs := []event.TxEvent{
+		{Txid: burnTx, Height: 100, BlockTime: now.Unix()},
+		{Txid: oldTx, Height: 99, BlockTime: now.Add(-time.Hour).Unix()},
+	}
+	txos := []*dbtable.FtTxoSet{
+		{UtxoTxid: burnTx, UtxoVout: 0, FtHolderCombineScript: burnCombineScript, FtContractId: "c", FtBalance: 500},
+		{UtxoTxid: burnTx, UtxoVout: 2, FtHolderCombineScript: strings.Repeat("1", 40) + "00", FtContractId: "c", FtBalance: 100},
+	}
+
+	// 超过等待时长仍没有代币输出的交易跳过
+	burns, err := collectBurns(txs, txos, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(burns) != 1 || burns[0].Vout != 0 || burns[0].Amount != 500 || burns[0].Height != 100 {
+		t.Fatalf("销毁记录不正确: %+v", burns)
+	}
+
+	// 新区块中的交易尚未索引时等待重新投递
+	if _, err := collectBurns(txs, txos[:0], now); err == nil {
+		t.Fatal("代币输出尚未索引时应返回错误")
+	}
+}
@@ -0,0 +1,240 @@
+package ft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	blockchianEntity "ginproject/entity/blockchain"
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/event"
+	"ginproject/entity/ft"
+	"ginproject/entity/transaction"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/ft_candle_dao"
+	"ginproject/repo/eventbus"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// 代币K线索引在事件总线上的消费者名称
+const candleConsumer = "ft_candles"
+
+// StartCandleIndexer 以ft_candles消费者的身份订阅代币转账事件，记录池兑换并重建受影响周期的K线，ctx取消时退出
+// 消费者从订阅时的最新位置开始，之前的兑换不会回填；未连接数据库时不做任何事
+func StartCandleIndexer(ctx context.Context) {
+	if db.GetDB() == nil {
+		return
+	}
+	if !config.GetConfig().GetEventBusConfig().Publish {
+		log.WarnWithContext(ctx, "事件发布未开启，代币K线不会更新")
+	}
+
+	l := NewFtLogic()
+	if err := eventbus.Default().Subscribe(ctx, candleConsumer, event.TopicTokenTransfer, l.indexCandles); err != nil {
+		log.WarnWithContext(ctx, "订阅代币转账事件失败", "错误:", err)
+	}
+}
+
+// candleKey 需要重建的K线
+type candleKey struct {
+	contractId string
+	interval   string
+	bucket     int64
+}
+
+// indexCandles 从一批池交易中找出兑换写入兑换记录，再按兑换记录重建受影响周期的K线
+// 节点解码失败和写库失败时返回错误由事件总线重新投递，无法解析的事件和非兑换的池操作记录日志后跳过
+func (l *FtLogic) indexCandles(ctx context.Context, events []eventbus.Event) error {
+	var swaps []*dbtable.FtPoolSwap
+	for _, e := range events {
+		var tx event.TxEvent
+		if err := json.Unmarshal(e.Payload, &tx); err != nil {
+			log.WarnWithContext(ctx, "跳过无法解析的代币转账事件", "offset:", e.Offset, "错误:", err)
+			continue
+		}
+		if tx.TxType != transaction.TxTypePool || tx.BlockTime == 0 {
+			continue
+		}
+		swap, err := l.poolSwapOfTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if swap != nil {
+			swaps = append(swaps, swap)
+		}
+	}
+	if len(swaps) == 0 {
+		return nil
+	}
+	if err := ft_candle_dao.UpsertPoolSwaps(ctx, swaps); err != nil {
+		return err
+	}
+
+	keys := make(map[candleKey]struct{})
+	for _, swap := range swaps {
+		for interval, seconds := range ft.CandleIntervals {
+			keys[candleKey{swap.FtContractId, interval, ft.CandleBucket(swap.BlockTime, seconds)}] = struct{}{}
+		}
+	}
+	candles := make([]*dbtable.FtPriceCandle, 0, len(keys))
+	for key := range keys {
+		candle, err := rebuildCandle(ctx, key)
+		if err != nil {
+			return err
+		}
+		if candle != nil {
+			candles = append(candles, candle)
+		}
+	}
+	return ft_candle_dao.UpsertCandles(ctx, candles)
+}
+
+// poolSwapOfTx 比较池交易与其花费的上一个池NFT交易中的储备，是兑换时返回兑换记录，否则返回nil
+func (l *FtLogic) poolSwapOfTx(ctx context.Context, tx event.TxEvent) (*dbtable.FtPoolSwap, error) {
+	decodeTxResult := <-blockchain.DecodeTxHash(ctx, tx.Txid)
+	if decodeTxResult.Error != nil {
+		return nil, fmt.Errorf("解码池交易%s失败: %w", tx.Txid, decodeTxResult.Error)
+	}
+	decodeTx, ok := decodeTxResult.Result.(*blockchianEntity.TransactionResponse)
+	if !ok {
+		return nil, fmt.Errorf("解码交易结果类型错误: txid=%s", tx.Txid)
+	}
+	// 与池历史相同，第一个输入花费上一个池NFT时其解锁脚本以签名开头且较长
+	if len(decodeTx.Vin) == 0 || len(decodeTx.Vin[0].ScriptSig.Asm) <= 500 || decodeTx.Vin[0].ScriptSig.Asm[0:2] != "30" {
+		return nil, nil
+	}
+
+	current, err := l.getPoolReserves(ctx, tx.Txid)
+	if err != nil {
+		log.WarnWithContext(ctx, "跳过无法解析储备的池交易", "txid:", tx.Txid, "错误:", err)
+		return nil, nil
+	}
+	prev, err := l.getPoolReserves(ctx, decodeTx.Vin[0].Txid)
+	if err != nil {
+		log.WarnWithContext(ctx, "跳过上一个池NFT无法解析储备的池交易", "txid:", tx.Txid, "错误:", err)
+		return nil, nil
+	}
+	contractId := current.ftAContractTxid
+	if contractId == "" || contractId != prev.ftAContractTxid {
+		return nil, nil
+	}
+
+	decimal, err := l.getFtDecimal(ctx, contractId)
+	if err != nil {
+		log.WarnWithContext(ctx, "跳过无法获取代币精度的池交易", "txid:", tx.Txid, "contractId:", contractId, "错误:", err)
+		return nil, nil
+	}
+	swap, ok := ft.DetectPoolSwap(prev.ftLpBalance, prev.ftABalance, prev.tbcBalance,
+		current.ftLpBalance, current.ftABalance, current.tbcBalance, decimal)
+	if !ok {
+		return nil, nil
+	}
+	return &dbtable.FtPoolSwap{
+		Txid:         tx.Txid,
+		FtContractId: contractId,
+		Height:       tx.Height,
+		BlockTime:    tx.BlockTime,
+		TxIndex:      tx.Index,
+		TbcChange:    swap.TbcChange,
+		FtChange:     swap.FtChange,
+		TbcReserve:   current.tbcBalance,
+		FtReserve:    current.ftABalance,
+		Price:        swap.Price,
+	}, nil
+}
+
+// rebuildCandle 按周期内的全部兑换记录重建一根K线，周期内没有兑换时返回nil
+func rebuildCandle(ctx context.Context, key candleKey) (*dbtable.FtPriceCandle, error) {
+	seconds := ft.CandleIntervals[key.interval]
+	swaps, err := ft_candle_dao.GetPoolSwapsBetween(ctx, key.contractId, key.bucket, key.bucket+seconds)
+	if err != nil {
+		return nil, err
+	}
+	candles := ft.BuildCandles(seconds, candleTrades(swaps))
+	if len(candles) == 0 {
+		return nil, nil
+	}
+	candle := candles[0]
+	return &dbtable.FtPriceCandle{
+		FtContractId: key.contractId,
+		IntervalName: key.interval,
+		BucketStart:  candle.Time,
+		Open:         candle.Open,
+		High:         candle.High,
+		Low:          candle.Low,
+		Close:        candle.Close,
+		VolumeTbc:    candle.VolumeTbc,
+		VolumeFt:     candle.VolumeFt,
+		Trades:       candle.Trades,
+	}, nil
+}
+
+// candleTrades 将兑换记录转换为参与K线聚合的成交，成交量取变化量的绝对值
+func candleTrades(swaps []*dbtable.FtPoolSwap) []ft.CandleTrade {
+	trades := make([]ft.CandleTrade, 0, len(swaps))
+	for _, swap := range swaps {
+		trades = append(trades, ft.CandleTrade{
+			BlockTime: swap.BlockTime,
+			Price:     swap.Price,
+			VolumeTbc: absUint64(swap.TbcChange),
+			VolumeFt:  absUint64(swap.FtChange),
+		})
+	}
+	return trades
+}
+
+// absUint64 返回绝对值
+func absUint64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
+
+// GetFtCandles 获取代币在时间范围内的价格K线
+func (l *FtLogic) GetFtCandles(ctx context.Context, req *ft.FtCandlesRequest) (*ft.FtCandlesResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		return nil, err
+	}
+
+	// 精度查询同时确认代币存在
+	decimal, err := l.getFtDecimal(ctx, req.ContractId)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取代币精度失败: contractId=%s, %v", req.ContractId, err)
+		return nil, fmt.Errorf("获取代币信息失败: %w", err)
+	}
+
+	// 包含起始时间所在的周期
+	from := ft.CandleBucket(req.From, ft.CandleIntervals[req.Interval])
+	candles, err := ft_candle_dao.GetCandles(ctx, req.ContractId, req.Interval, from, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ft.FtCandlesResponse{
+		FtContractId: req.ContractId,
+		FtDecimal:    int(decimal),
+		Interval:     req.Interval,
+		From:         req.From,
+		To:           req.To,
+		Result:       make([]ft.FtCandleItem, 0, len(candles)),
+	}
+	for _, candle := range candles {
+		response.Result = append(response.Result, ft.FtCandleItem{
+			Time:      candle.BucketStart,
+			Open:      candle.Open,
+			High:      candle.High,
+			Low:       candle.Low,
+			Close:     candle.Close,
+			VolumeTbc: candle.VolumeTbc,
+			VolumeFt:  candle.VolumeFt,
+			Trades:    candle.Trades,
+		})
+	}
+
+	log.InfoWithContextf(ctx, "获取代币K线成功: contractId=%s, interval=%s, 数量=%d", req.ContractId, req.Interval, len(candles))
+	return response, nil
+}
@@ -8,8 +8,10 @@ import (
 	"ginproject/entity/ft"
 	"ginproject/entity/utility"
 	"ginproject/middleware/log"
+	"ginproject/repo/db"
 	rpcblockchain "ginproject/repo/rpc/blockchain"
 	"ginproject/repo/rpc/electrumx"
+	"ginproject/repo/scripthash"
 )
 
 // GetFtHistory 获取地址的代币交易历史
@@ -19,7 +21,7 @@ func (l *FtLogic) GetFtHistory(ctx context.Context, req *ft.FtHistoryRequest) (*
 	// 参数校验
 	if err := req.Validate(); err != nil {
 		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
-		return nil, fmt.Errorf("参数验证失败: %v", err)
+		return nil, fmt.Errorf("参数验证失败: %w", err)
 	}
 
 	// 获取FT代币脚本信息
@@ -29,7 +31,7 @@ func (l *FtLogic) GetFtHistory(ctx context.Context, req *ft.FtHistoryRequest) (*
 	}
 
 	// 获取交易历史
-	historyResult, err := l.fetchTransactionHistory(ctx, ftLockingScript)
+	historyResult, err := l.fetchTransactionHistory(ctx, ftLockingScript, req.FromHeight)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +52,7 @@ func (l *FtLogic) GetFtHistory(ctx context.Context, req *ft.FtHistoryRequest) (*
 		ScriptHash:   ftLockingScript,
 		HistoryCount: totalCount,
 		Result:       historyList,
+		Meta:         utility.NewPageMeta(req.Page, req.Size, int64(totalCount)),
 	}
 
 	log.InfoWithContextf(ctx, "获取FT交易历史成功，合约ID=%s，地址=%s，历史数量=%d",
@@ -58,15 +61,22 @@ func (l *FtLogic) GetFtHistory(ctx context.Context, req *ft.FtHistoryRequest) (*
 	return response, nil
 }
 
-// getFtLockingScript 获取FT代币锁定脚本
+// getFtLockingScript 获取FT代币锁定脚本，结果按地址和合约ID缓存
 func (l *FtLogic) getFtLockingScript(ctx context.Context, contractId, address string) (string, error) {
+	return scripthash.ResolveFt(ctx, address, contractId, func() (string, error) {
+		return l.computeFtLockingScript(ctx, contractId, address)
+	})
+}
+
+// computeFtLockingScript 根据代币代码脚本和地址计算FT代币锁定脚本
+func (l *FtLogic) computeFtLockingScript(ctx context.Context, contractId, address string) (string, error) {
 	log.InfoWithContextf(ctx, "开始获取FT代币锁定脚本，合约ID=%s，地址=%s", contractId, address)
 
 	// 获取代币代码脚本和精度
 	ftCodeScript, _, err := l.ftTokensDAO.GetFtCodeScriptAndDecimal(ctx, contractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币信息失败，合约ID=%s: %v", contractId, err)
-		return "", fmt.Errorf("获取代币信息失败: %v", err)
+		return "", fmt.Errorf("获取代币信息失败: %w", err)
 	}
 
 	if ftCodeScript == "" {
@@ -105,10 +115,10 @@ func (l *FtLogic) getFtLockingScript(ctx context.Context, contractId, address st
 	return ftLockingScript, nil
 }
 
-// fetchTransactionHistory 获取交易历史记录
-func (l *FtLogic) fetchTransactionHistory(ctx context.Context, scriptHash string) ([]interface{}, error) {
-	log.InfoWithContextf(ctx, "开始从ElectrumX获取脚本哈希历史: %s", scriptHash)
-	historyResult, err := electrumx.GetTransactionHistory(ctx, scriptHash)
+// fetchTransactionHistory 获取交易历史记录，fromHeight大于0时只获取该高度之后的记录
+func (l *FtLogic) fetchTransactionHistory(ctx context.Context, scriptHash string, fromHeight int64) ([]interface{}, error) {
+	log.InfoWithContextf(ctx, "开始从ElectrumX获取脚本哈希历史: %s, 起始高度=%d", scriptHash, fromHeight)
+	historyResult, err := electrumx.GetTransactionHistoryFrom(ctx, scriptHash, fromHeight)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取交易历史失败: %v", err)
 		return nil, fmt.Errorf("获取交易历史失败: %v", err)
@@ -149,7 +159,7 @@ func (l *FtLogic) processFtHistoryDetails(ctx context.Context, historyItems []in
 	_, ftDecimal, err := l.ftTokensDAO.GetFtCodeScriptAndDecimal(ctx, contractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币精度失败，合约ID=%s: %v", contractId, err)
-		return nil, fmt.Errorf("获取代币精度失败: %v", err)
+		return nil, fmt.Errorf("获取代币精度失败: %w", err)
 	}
 	log.DebugWithContextf(ctx, "代币精度: %d", ftDecimal)
 
@@ -320,8 +330,8 @@ func (l *FtLogic) processTxDetails(ctx context.Context, decodeTxMap map[string]i
 		return nil, err
 	}
 
-	// 移除1BitcoinEaterAddressDontSendf59kuE地址
-	delete(txInfo.recipientAddresses, "1BitcoinEaterAddressDontSendf59kuE")
+	// 移除销毁地址，销毁记录通过/ft/burns接口查询
+	delete(txInfo.recipientAddresses, ft.BurnAddress)
 
 	log.DebugWithContextf(ctx, "交易处理完成: 总花费=%d, 总接收=%d, FT余额变化=%d",
 		txInfo.txTotalSpend, txInfo.txTotalReceive, txInfo.ftBalanceChange)
@@ -416,6 +426,10 @@ func (l *FtLogic) processInputValue(ctx context.Context, vinDecodeTxMap map[stri
 
 	// 获取FT UTXO信息
 	ftBalance, ftHolderScript, ftContractId, err := l.ftTxoDAO.GetFtUtxoInfo(ctx, vinTxid, vinVout)
+	if db.IsNotFound(err) {
+		log.DebugWithContextf(ctx, "非FT相关UTXO: txid=%s, vout=%d", vinTxid, vinVout)
+		return
+	}
 	if err != nil {
 		log.WarnWithContextf(ctx, "获取FT UTXO信息失败: txid=%s, vout=%d, 错误=%v",
 			vinTxid, vinVout, err)
@@ -476,6 +490,10 @@ func (l *FtLogic) processTxOutputs(ctx context.Context, decodeTxMap map[string]i
 
 		// 获取FT UTXO信息
 		ftBalance, ftHolderScript, ftContractId, err := l.ftTxoDAO.GetFtUtxoInfo(ctx, txHash, int(n))
+		if db.IsNotFound(err) {
+			log.DebugWithContextf(ctx, "非FT相关UTXO: txid=%s, n=%d", txHash, int(n))
+			continue
+		}
 		if err != nil {
 			log.WarnWithContextf(ctx, "获取FT UTXO信息失败: txid=%s, n=%d, 错误=%v",
 				txHash, int(n), err)
@@ -497,7 +515,7 @@ func (l *FtLogic) processTxOutputs(ctx context.Context, decodeTxMap map[string]i
 			log.DebugWithContextf(ctx, "检测到FT收入: +%d", ftBalance)
 		}
 
-		l.processOutputAddress(ftHolderScript, txInfo)
+		l.processOutputAddress(ctx, ftHolderScript, txInfo)
 	}
 
 	return nil
@@ -558,35 +576,35 @@ func (l *FtLogic) processAddressFromScript(ctx context.Context, ftHolderScript s
 }
 
 // processOutputAddress 处理输出地址
-func (l *FtLogic) processOutputAddress(ftHolderScript string, txInfo *transactionInfo) {
+func (l *FtLogic) processOutputAddress(ctx context.Context, ftHolderScript string, txInfo *transactionInfo) {
 	if ftHolderScript == "" {
 		return
 	}
 
-	log.DebugWithContextf(context.Background(), "处理输出脚本地址: %s", ftHolderScript)
+	log.DebugWithContextf(ctx, "处理输出脚本地址: %s", ftHolderScript)
 
 	if ftHolderScript[len(ftHolderScript)-2:] == "00" {
 		// 普通地址
 		address, err := utility.ConvertCombineScriptToAddress(ftHolderScript)
 		if err != nil {
-			log.WarnWithContextf(context.Background(), "转换组合脚本为地址失败: %v", err)
+			log.WarnWithContextf(ctx, "转换组合脚本为地址失败: %v", err)
 			return
 		}
 
 		txInfo.recipientAddresses[address] = struct{}{}
-		log.DebugWithContextf(context.Background(), "识别接收方普通地址: %s", address)
+		log.DebugWithContextf(ctx, "识别接收方普通地址: %s", address)
 	} else if ftHolderScript[len(ftHolderScript)-2:] == "01" {
 		// 池控制或多签地址
 		if _, ok := txInfo.senderAddresses["Pool_"+ftHolderScript]; ok {
 			// 已知的池控制地址
 			poolAddress := "Pool_" + ftHolderScript
 			txInfo.recipientAddresses[poolAddress] = struct{}{}
-			log.DebugWithContextf(context.Background(), "识别接收方池控制地址: %s", poolAddress)
+			log.DebugWithContextf(ctx, "识别接收方池控制地址: %s", poolAddress)
 		} else {
 			// 未知的池控制或多签地址
 			msAddress := "Pool_or_MS_" + ftHolderScript
 			txInfo.recipientAddresses[msAddress] = struct{}{}
-			log.DebugWithContextf(context.Background(), "识别接收方池控制或多签地址: %s", msAddress)
+			log.DebugWithContextf(ctx, "识别接收方池控制或多签地址: %s", msAddress)
 		}
 	}
 }
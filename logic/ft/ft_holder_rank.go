@@ -16,7 +16,7 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 	// 参数合法性校验
 	if err := req.Validate(); err != nil {
 		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
-		return nil, fmt.Errorf("参数验证失败: %v", err)
+		return nil, fmt.Errorf("参数验证失败: %w", err)
 	}
 
 	// 解析分页参数
@@ -35,9 +35,9 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 	log.InfoWithContextf(ctx, "查询代币持有者排名, 合约ID: %s, 页码: %d, 每页记录数: %d",
 		req.ContractId, page, size)
 
-	// 使用协程并发执行三个数据库查询操作
+	// 使用协程并发执行四个数据库查询操作
 	var wg sync.WaitGroup
-	wg.Add(3)
+	wg.Add(4)
 
 	// 用于存储查询结果和错误
 	var token *dbtable.FtTokens
@@ -49,10 +49,13 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 	var balances []*dbtable.FtBalance
 	var balancesErr error
 
+	var circulatingSupply uint64
+	var circulatingErr error
+
 	// 查询代币基本信息
 	go func() {
 		defer wg.Done()
-		token, tokenErr = l.ftTokensDAO.GetFtTokenById(req.ContractId)
+		token, tokenErr = l.ftTokensDAO.GetFtTokenById(ctx, req.ContractId)
 		if tokenErr != nil {
 			log.ErrorWithContextf(ctx, "获取代币信息失败: %v", tokenErr)
 		}
@@ -61,7 +64,7 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 	// 查询代币持有者数量
 	go func() {
 		defer wg.Done()
-		holdersCount, holdersCountErr = l.ftBalanceDAO.GetHoldersCountByContractId(req.ContractId)
+		holdersCount, holdersCountErr = l.ftBalanceDAO.GetHoldersCountByContractId(ctx, req.ContractId)
 		if holdersCountErr != nil {
 			log.ErrorWithContextf(ctx, "获取代币持有者数量失败: %v", holdersCountErr)
 		}
@@ -76,12 +79,21 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 		}
 	}()
 
+	// 查询流通供应量
+	go func() {
+		defer wg.Done()
+		circulatingSupply, circulatingErr = l.ftBalanceDAO.GetTotalBalanceByContractId(ctx, req.ContractId)
+		if circulatingErr != nil {
+			log.ErrorWithContextf(ctx, "获取代币流通供应量失败: %v", circulatingErr)
+		}
+	}()
+
 	// 等待所有协程完成
 	wg.Wait()
 
 	// 检查错误
 	if tokenErr != nil {
-		return nil, fmt.Errorf("获取代币信息失败: %v", tokenErr)
+		return nil, fmt.Errorf("获取代币信息失败: %w", tokenErr)
 	}
 
 	if holdersCountErr != nil {
@@ -92,6 +104,10 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 		return nil, fmt.Errorf("获取代币持有者排名失败: %v", balancesErr)
 	}
 
+	if circulatingErr != nil {
+		return nil, fmt.Errorf("获取代币流通供应量失败: %v", circulatingErr)
+	}
+
 	// 查询代币总供应量
 	totalSupply := token.FtSupply
 
@@ -103,43 +119,36 @@ func (l *FtLogic) GetFtHolderRank(ctx context.Context, req *ft.FtHolderRankReque
 		// 计算排名序号（起始页码*每页大小+当前索引+1）
 		rank := page*size + i + 1
 
-		// 计算持有比例
-		holdRatio := float64(0)
-		if totalSupply > 0 {
-			holdRatio = float64(balance.FtBalance) / float64(totalSupply)
-		}
-
 		// 转换组合脚本为地址
-		address := "未知地址"
-		if len(balance.FtHolderCombineScript) > 2 && balance.FtHolderCombineScript[len(balance.FtHolderCombineScript)-2:] == "00" {
-			// 普通地址
-			addr, err := utility.ConvertCombineScriptToAddress(balance.FtHolderCombineScript)
-			if err == nil {
-				address = addr
-			} else {
-				log.WarnWithContextf(ctx, "转换地址失败: %s, %v", balance.FtHolderCombineScript, err)
-			}
-		} else {
-			// 其他类型地址（可能是合约或多签）
-			address = "Contract_" + balance.FtHolderCombineScript
+		address, err := utility.ConvertCombineScriptToAddress(balance.FtHolderCombineScript)
+		if err != nil {
+			log.WarnWithContextf(ctx, "转换地址失败: %s, %v", balance.FtHolderCombineScript, err)
+			address = "未知地址"
 		}
 
 		// 添加到列表
 		holderRankInfo := ft.HolderRankInfo{
-			Address:   address,
-			Balance:   balance.FtBalance,
-			Rank:      rank,
-			HoldRatio: holdRatio,
+			Address:       address,
+			CombineScript: balance.FtHolderCombineScript,
+			Balance:       balance.FtBalance,
+			Rank:          rank,
 		}
+		holderRankInfo.SetShares(totalSupply, circulatingSupply)
 		holderRankList = append(holderRankList, holderRankInfo)
 	}
 
+	// 与最近一次快照比较名次，快照查询失败不影响排名本身
+	snapshotAt := l.compareHolderRankSnapshot(ctx, req.ContractId, holderRankList)
+
 	// 构造返回响应
 	response := &ft.FtHolderRankResponse{
-		FtContractId:   req.ContractId,
-		FtDecimal:      int(token.FtDecimal),
-		FtHoldersCount: int(holdersCount),
-		HolderRank:     holderRankList,
+		FtContractId:      req.ContractId,
+		FtDecimal:         int(token.FtDecimal),
+		FtHoldersCount:    int(holdersCount),
+		CirculatingSupply: circulatingSupply,
+		SnapshotAt:        snapshotAt,
+		HolderRank:        holderRankList,
+		Meta:              utility.NewPageMeta(page, size, holdersCount),
 	}
 
 	log.InfoWithContextf(ctx, "获取代币持有者排名成功, 合约ID: %s, 返回记录数: %d",
@@ -0,0 +1,122 @@
+package ft
+
+import (
+	"context"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/ft"
+	"ginproject/middleware/log"
+	"ginproject/repo/db/ft_holder_snapshot_dao"
+)
+
+// 未配置时每个代币保存的持有者数量
+const defaultHolderSnapshotTopN = 1000
+
+// SnapshotHolderRanks 为快照到期的代币保存前N名持有者的名次，由周期任务调用
+// 快照时间记录在表中，重启后不会提前重新生成；未配置快照周期时不做任何事
+func SnapshotHolderRanks(ctx context.Context) error {
+	cfg := config.GetConfig().GetHolderSnapshotConfig()
+	if cfg.Interval <= 0 {
+		return nil
+	}
+	return NewFtLogic().snapshotDueHolderRanks(ctx, time.Now().Add(-time.Duration(cfg.Interval)*time.Hour), holderSnapshotTopN(cfg))
+}
+
+// holderSnapshotTopN 返回配置的快照持有者数量
+func holderSnapshotTopN(cfg *config.HolderSnapshotConfig) int {
+	if cfg.TopN > 0 {
+		return cfg.TopN
+	}
+	return defaultHolderSnapshotTopN
+}
+
+// snapshotDueHolderRanks 为没有快照或上次快照早于before的代币生成快照，单个代币失败时记录日志后继续
+func (l *FtLogic) snapshotDueHolderRanks(ctx context.Context, before time.Time, topN int) error {
+	contractIds, err := l.ftTokensDAO.GetFtContractIds(ctx)
+	if err != nil {
+		return err
+	}
+	snapshotTimes, err := ft_holder_snapshot_dao.GetHolderSnapshotTimes(ctx)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, contractId := range contractIds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if last, ok := snapshotTimes[contractId]; ok && last.After(before) {
+			continue
+		}
+		if err := l.snapshotHolderRank(ctx, contractId, topN); err != nil {
+			log.WarnWithContext(ctx, "生成持有者排名快照失败", "contractId:", contractId, "错误:", err)
+			continue
+		}
+		count++
+	}
+	if count > 0 {
+		log.InfoWithContext(ctx, "持有者排名快照完成", "代币数:", count)
+	}
+	return nil
+}
+
+// snapshotHolderRank 保存代币当前前topN名持有者的名次，替换该代币之前的快照
+func (l *FtLogic) snapshotHolderRank(ctx context.Context, contractId string, topN int) error {
+	balances, err := l.ftBalanceDAO.GetFtBalanceRankByContractId(ctx, contractId, 0, topN)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	snapshots := make([]*dbtable.FtHolderRankSnapshot, 0, len(balances))
+	for i, balance := range balances {
+		snapshots = append(snapshots, &dbtable.FtHolderRankSnapshot{
+			FtContractId:          contractId,
+			FtHolderCombineScript: balance.FtHolderCombineScript,
+			HolderRank:            i + 1,
+			FtBalance:             balance.FtBalance,
+			SnapshotAt:            now,
+		})
+	}
+	return ft_holder_snapshot_dao.ReplaceHolderRankSnapshot(ctx, contractId, snapshots)
+}
+
+// compareHolderRankSnapshot 按代币最近一次快照填充名次变化，返回快照时间戳，没有快照或查询失败时返回0
+// 快照只保存前N名，当前名次在N名之后且不在快照中的持有者无法判断变化，保持省略
+func (l *FtLogic) compareHolderRankSnapshot(ctx context.Context, contractId string, holders []ft.HolderRankInfo) int64 {
+	cfg := config.GetConfig().GetHolderSnapshotConfig()
+	if cfg.Interval <= 0 || len(holders) == 0 {
+		return 0
+	}
+
+	snapshotAt, err := ft_holder_snapshot_dao.GetHolderSnapshotTime(ctx, contractId)
+	if err != nil || snapshotAt.IsZero() {
+		return 0
+	}
+
+	scripts := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		scripts = append(scripts, holder.CombineScript)
+	}
+	snapshots, err := ft_holder_snapshot_dao.GetHolderRankSnapshots(ctx, contractId, scripts)
+	if err != nil {
+		return 0
+	}
+	previous := make(map[string]int, len(snapshots))
+	for _, snapshot := range snapshots {
+		previous[snapshot.FtHolderCombineScript] = snapshot.HolderRank
+	}
+
+	topN := holderSnapshotTopN(cfg)
+	for i := range holders {
+		rank, ok := previous[holders[i].CombineScript]
+		if !ok && holders[i].Rank > topN {
+			continue
+		}
+		holders[i].CompareSnapshot(rank)
+	}
+	return snapshotAt.Unix()
+}
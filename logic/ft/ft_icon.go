@@ -0,0 +1,156 @@
+package ft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/db"
+	"ginproject/repo/db/token_registry_dao"
+	"ginproject/repo/http"
+	"ginproject/repo/storage"
+)
+
+// 图标缓存默认参数
+const (
+	defaultIconDir        = "./icons"
+	defaultIconTTL        = 24 * time.Hour
+	defaultIconFailureTTL = 10 * time.Minute
+	iconFailureCacheSize  = 1024
+)
+
+// ErrTokenIconNotFound 代币未登记图标
+var ErrTokenIconNotFound = fmt.Errorf("代币图标%w", db.ErrNotFound)
+
+var (
+	iconOnce     sync.Once
+	iconStore    storage.ObjectStore
+	iconFetcher  *http.IconFetcher
+	iconTTL      time.Duration
+	iconFailures *cache.LRU[string, error]
+	iconInitErr  error
+	// iconFetchLocks 按对象键分段加锁，同一图标同时只抓取一次
+	iconFetchLocks [16]sync.Mutex
+)
+
+// initIcons 按配置初始化图标存储、抓取客户端和失败缓存
+func initIcons() error {
+	iconOnce.Do(func() {
+		cfg := config.GetConfig().GetIconConfig()
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultIconDir
+		}
+		iconTTL = time.Duration(cfg.TTL) * time.Second
+		if iconTTL <= 0 {
+			iconTTL = defaultIconTTL
+		}
+		failureTTL := time.Duration(cfg.FailureTTL) * time.Second
+		if failureTTL <= 0 {
+			failureTTL = defaultIconFailureTTL
+		}
+
+		iconStore, iconInitErr = storage.NewLocalStore(dir)
+		iconFetcher = http.NewIconFetcher(http.IconOptions{
+			MaxBytes:     cfg.MaxBytes,
+			MaxDimension: cfg.MaxDimension,
+			Timeout:      time.Duration(cfg.Timeout) * time.Second,
+		})
+		iconFailures = cache.NewLRU[string, error](iconFailureCacheSize, failureTTL)
+	})
+	return iconInitErr
+}
+
+// iconObjectKey 返回图标URL对应的对象键，URL变化后重新抓取
+func iconObjectKey(logoUrl string) string {
+	sum := sha256.Sum256([]byte(logoUrl))
+	return hex.EncodeToString(sum[:]) + ".png"
+}
+
+// GetTokenIcon 返回代币元数据中登记的图标，内容为校验后重新编码的PNG
+// 缓存未过期时直接返回缓存；重新抓取失败时仍返回过期的缓存，没有缓存时返回错误，
+// 未通过校验的图标返回包装http.ErrIconRejected的错误
+func (l *FtLogic) GetTokenIcon(ctx context.Context, contractId string) (storage.Object, error) {
+	if err := initIcons(); err != nil {
+		return nil, err
+	}
+
+	row, err := token_registry_dao.GetTokenRegistryByContractId(ctx, contractId)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return nil, ErrTokenIconNotFound
+		}
+		return nil, err
+	}
+	if row.LogoUrl == "" {
+		return nil, ErrTokenIconNotFound
+	}
+
+	key := iconObjectKey(row.LogoUrl)
+	if object := openFreshIcon(ctx, key); object != nil {
+		return object, nil
+	}
+
+	lock := &iconFetchLocks[key[0]%byte(len(iconFetchLocks))]
+	lock.Lock()
+	defer lock.Unlock()
+
+	// 等待期间其它请求可能已经抓取完成
+	if object := openFreshIcon(ctx, key); object != nil {
+		return object, nil
+	}
+
+	err, failed := iconFailures.Get(key)
+	if !failed {
+		if err = fetchIcon(ctx, key, row.LogoUrl); err == nil {
+			return iconStore.Open(ctx, key)
+		}
+		if ctx.Err() == nil {
+			iconFailures.Set(key, err)
+		}
+		log.WarnWithContextf(ctx, "抓取代币图标失败: 合约ID=%s, URL=%s, %v", contractId, row.LogoUrl, err)
+	}
+
+	// 过期的缓存仍是校验过的内容，抓取失败时继续使用
+	if object, openErr := iconStore.Open(ctx, key); openErr == nil {
+		return object, nil
+	}
+	return nil, err
+}
+
+// openFreshIcon 打开未过期的缓存图标，不存在或已过期时返回nil
+func openFreshIcon(ctx context.Context, key string) storage.Object {
+	object, err := iconStore.Open(ctx, key)
+	if err != nil {
+		return nil
+	}
+	if time.Since(object.Info().ModTime) > iconTTL {
+		object.Close()
+		return nil
+	}
+	return object
+}
+
+// fetchIcon 抓取并校验图标后写入存储
+func fetchIcon(ctx context.Context, key, logoUrl string) error {
+	icon, err := iconFetcher.Fetch(ctx, logoUrl)
+	if err != nil {
+		return err
+	}
+
+	writer, err := iconStore.Create(ctx, key)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(icon.Data); err != nil {
+		writer.Abort()
+		return fmt.Errorf("写入图标失败: %w", err)
+	}
+	return writer.Close()
+}
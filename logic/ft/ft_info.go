@@ -18,10 +18,10 @@ func (l *FtLogic) GetFtInfoByContractId(ctx context.Context, req *ft.FtInfoContr
 	}
 
 	// 获取代币信息
-	ftToken, err := l.ftTokensDAO.GetFtTokenById(req.ContractId)
+	ftToken, err := l.ftTokensDAO.GetFtTokenById(ctx, req.ContractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币信息失败: %v", err)
-		return nil, fmt.Errorf("获取代币信息失败: %v", err)
+		return nil, fmt.Errorf("获取代币信息失败: %w", err)
 	}
 
 	// 计算考虑小数位后的供应量
@@ -44,6 +44,7 @@ func (l *FtLogic) GetFtInfoByContractId(ctx context.Context, req *ft.FtInfoContr
 		FtCreateTimestamp:      ftToken.FtCreateTimestamp,
 		FtTokenPrice:           fmt.Sprintf("%f", ftToken.FtTokenPrice),
 	}
+	applyTokenMetadata(ctx, response)
 
 	log.InfoWithContextf(ctx, "FT信息查询成功: 合约ID=%s", req.ContractId)
 
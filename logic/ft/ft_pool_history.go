@@ -175,7 +175,7 @@ func (l *FtLogic) GetPoolHistory(ctx context.Context, req *ft.TBC20PoolHistoryRe
 
 				// 获取代币信息
 				ftTokensDAO := ft_tokens_dao.NewFtTokensDAO()
-				tokenInfo, err := ftTokensDAO.GetFtTokenById(ftContractId)
+				tokenInfo, err := ftTokensDAO.GetFtTokenById(ctx, ftContractId)
 				if err != nil {
 					log.WarnWithContextf(ctx, "获取代币信息失败: %v", err)
 				} else if tokenInfo != nil {
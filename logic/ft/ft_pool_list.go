@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"ginproject/entity/ft"
+	"ginproject/entity/utility"
 	"ginproject/middleware/log"
 )
 
@@ -39,7 +40,7 @@ func (l *FtLogic) GetPoolListByFtContractId(ctx context.Context, req *ft.TBC20Po
 	}
 
 	// 获取当前代币的信息
-	tokenInfo, err := l.ftTokensDAO.GetFtTokenById(req.FtContractId)
+	tokenInfo, err := l.ftTokensDAO.GetFtTokenById(ctx, req.FtContractId)
 	if err != nil {
 		log.WarnWithContextf(ctx, "获取代币信息失败: %v", err)
 		// 继续处理，使用默认值
@@ -105,6 +106,7 @@ func (l *FtLogic) GetAllPoolList(ctx context.Context, req *ft.TBC20PoolPageReque
 	response := &ft.TBC20PoolPageResponse{
 		PoolList:       make([]ft.TBC20PoolInfo, len(result.Results)),
 		TotalPoolCount: result.TotalCount,
+		Meta:           utility.NewPageMeta(req.Page, req.Size, result.TotalCount),
 	}
 
 	// 如果没有找到任何池，返回空列表
@@ -156,7 +158,7 @@ func (l *FtLogic) GetAllPoolList(ctx context.Context, req *ft.TBC20PoolPageReque
 				log.WarnWithContextf(ctx, "代币信息查询超时: 代币ID=%s", id)
 			default:
 				// 查询代币信息
-				tokenInfo, err := l.ftTokensDAO.GetFtTokenById(id)
+				tokenInfo, err := l.ftTokensDAO.GetFtTokenById(ctx, id)
 				if err != nil {
 					tokenInfoChan <- tokenInfoResult{
 						TokenId:   id,
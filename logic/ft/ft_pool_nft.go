@@ -7,14 +7,18 @@ import (
 	"strconv"
 	"strings"
 
+	"ginproject/entity/dbtable"
 	"ginproject/entity/ft"
 	"ginproject/entity/utility"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
+	"ginproject/repo/db"
 	"ginproject/repo/db/ft_txo_dao"
 	"ginproject/repo/db/nft_utxo_set_dao"
 	"ginproject/repo/rpc/blockchain"
 	"ginproject/repo/rpc/electrumx"
 	blockchianEntity "ginproject/entity/blockchain"
+	electrumxEntity "ginproject/entity/electrumx"
 )
 
 // GetNFTPoolInfoByContractId 根据合约ID获取NFT池信息
@@ -32,7 +36,7 @@ func (l *FtLogic) GetNFTPoolInfoByContractId(ctx context.Context, req *ft.TBC20P
 	// 2. 如果找不到NFT池，返回错误
 	if currentPoolNftTxid == "" {
 		log.ErrorWithContextf(ctx, "未找到NFT池: ftContractId=%s", req.FtContractId)
-		return nil, fmt.Errorf("未找到NFT池")
+		return nil, fmt.Errorf("未找到NFT池: %w", db.ErrNftNotFound)
 	}
 
 	// 3. 获取交易详情
@@ -196,51 +200,173 @@ func parsePoolBalance(complexBalance string) (int64, int64, int64, error) {
 	return ftLpBalance, ftABalance, tbcBalance, nil
 }
 
-// GetLPUnspentByScriptHash 根据脚本哈希获取LP未花费交易输出
-func (l *FtLogic) GetLPUnspentByScriptHash(ctx context.Context, req *ft.LPUnspentByScriptHashRequest) (*ft.TBC20FTLPUnspentResponse, error) {
+// StreamLPUnspentByScriptHash 根据脚本哈希逐条输出LP未花费交易输出，支持最小余额过滤和分页
+// 返回符合过滤条件的总数，emit返回错误时停止查询
+func (l *FtLogic) StreamLPUnspentByScriptHash(ctx context.Context, req *ft.LPUnspentByScriptHashRequest,
+	emit func(item *ft.TBC20FTLPUnspentItem) error) (int, error) {
 	log.InfoWithContext(ctx, "开始获取LP未花费交易输出", "scriptHash", req.ScriptHash)
 
 	// 1. 从ElectrumX获取未花费的交易输出
 	unspents, err := electrumx.GetScriptHashUnspent(ctx, req.ScriptHash)
 	if err != nil {
 		log.ErrorWithContext(ctx, "获取未花费交易输出失败", "scriptHash", req.ScriptHash, "error", err)
-		return nil, err
+		return 0, err
 	}
-
-	// 2. 如果没有未花费的交易输出，返回空列表
 	if len(unspents) == 0 {
 		log.InfoWithContext(ctx, "未找到未花费交易输出", "scriptHash", req.ScriptHash)
-		return &ft.TBC20FTLPUnspentResponse{FtUtxoList: []*ft.TBC20FTLPUnspentItem{}}, nil
+		return 0, nil
+	}
+
+	// 2. 批量查询代币交易输出，分页时由数据库统计总数并只查询当前页的记录
+	txids, vouts := splitOutpoints(unspents)
+	ftTxoDAO := ft_txo_dao.NewFtTxoDAO()
+	total := 0
+	if req.Size > 0 {
+		var count int64
+		count, err = ftTxoDAO.ScanLPUnspentPageByIds(ctx, txids, vouts, req.MinBalance, req.Page*req.Size, req.Size, func(txo *dbtable.FtTxoSet) error {
+			return emit(toLPUnspentItem(txo))
+		})
+		total = int(count)
+	} else {
+		err = ftTxoDAO.ScanLPUnspentByIds(ctx, txids, vouts, req.MinBalance, func(txo *dbtable.FtTxoSet) error {
+			total++
+			return emit(toLPUnspentItem(txo))
+		})
+	}
+	if err != nil {
+		log.ErrorWithContext(ctx, "查询代币交易输出失败", "scriptHash", req.ScriptHash, "error", err)
+		return 0, err
+	}
+
+	log.InfoWithContextf(ctx, "成功获取LP未花费交易输出: scriptHash=%s, total=%d", req.ScriptHash, total)
+	return total, nil
+}
+
+// GetLPUnspentByScriptHashes 批量获取多个脚本哈希的LP未花费交易输出
+// ElectrumX请求并发发出，单个脚本哈希失败不影响其他结果
+func (l *FtLogic) GetLPUnspentByScriptHashes(ctx context.Context, req *ft.LPUnspentByScriptHashesRequest) (*ft.TBC20FTLPUnspentMultiResponse, error) {
+	log.InfoWithContext(ctx, "开始批量获取LP未花费交易输出", "count", len(req.ScriptHashes))
+
+	unspentResults := electrumx.GetScriptHashUnspentMulti(ctx, req.ScriptHashes)
+
+	results := make([]*ft.TBC20FTLPUnspentScriptResult, 0, len(unspentResults))
+	for _, unspentResult := range unspentResults {
+		result := &ft.TBC20FTLPUnspentScriptResult{
+			ScriptHash: unspentResult.ScriptHash,
+			FtUtxoList: []*ft.TBC20FTLPUnspentItem{},
+		}
+		results = append(results, result)
+
+		if unspentResult.Error != nil {
+			log.ErrorWithContext(ctx, "获取未花费交易输出失败", "scriptHash", unspentResult.ScriptHash, "error", unspentResult.Error)
+			result.Error = unspentResult.Error.Error()
+			continue
+		}
+
+		ftUtxoList, err := l.buildLPUnspentItems(ctx, unspentResult.ScriptHash, unspentResult.Utxos)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.FtUtxoList = ftUtxoList
+	}
+
+	return &ft.TBC20FTLPUnspentMultiResponse{Results: results}, nil
+}
+
+// buildLPUnspentItems 根据ElectrumX返回的UTXO查询LP代币交易输出
+func (l *FtLogic) buildLPUnspentItems(ctx context.Context, scriptHash string, unspents electrumxEntity.UtxoResponse) ([]*ft.TBC20FTLPUnspentItem, error) {
+	// 1. 如果没有未花费的交易输出，返回空列表
+	if len(unspents) == 0 {
+		log.InfoWithContext(ctx, "未找到未花费交易输出", "scriptHash", scriptHash)
+		return []*ft.TBC20FTLPUnspentItem{}, nil
+	}
+
+	// 2. 获取代币交易输出信息
+	txids, vouts := splitOutpoints(unspents)
+	ftTxoDAO := ft_txo_dao.NewFtTxoDAO()
+	ftTxos, err := ftTxoDAO.GetLPUnspentByIds(ctx, txids, vouts)
+	if err != nil {
+		log.ErrorWithContext(ctx, "查询代币交易输出失败", "scriptHash", scriptHash, "error", err)
+		return nil, err
+	}
+
+	// 3. 构建结果
+	ftUtxoList := make([]*ft.TBC20FTLPUnspentItem, 0, len(ftTxos))
+	for _, ftTxo := range ftTxos {
+		ftUtxoList = append(ftUtxoList, toLPUnspentItem(ftTxo))
 	}
 
-	// 3. 准备UTXO ID和输出索引列表
+	return ftUtxoList, nil
+}
+
+// splitOutpoints 拆分UTXO的交易ID和输出索引列表
+func splitOutpoints(unspents electrumxEntity.UtxoResponse) ([]string, []int) {
 	txids := make([]string, 0, len(unspents))
 	vouts := make([]int, 0, len(unspents))
 	for _, utxo := range unspents {
 		txids = append(txids, utxo.TxHash)
 		vouts = append(vouts, utxo.TxPos)
 	}
+	return txids, vouts
+}
 
-	// 4. 获取代币交易输出信息
-	ftTxoDAO := ft_txo_dao.NewFtTxoDAO()
-	ftTxos, err := ftTxoDAO.GetLPUnspentByIds(ctx, txids, vouts)
+// toLPUnspentItem 将代币交易输出记录转换为LP未花费交易输出
+func toLPUnspentItem(ftTxo *dbtable.FtTxoSet) *ft.TBC20FTLPUnspentItem {
+	return &ft.TBC20FTLPUnspentItem{
+		UtxoId:       ftTxo.UtxoTxid,
+		UtxoVout:     ftTxo.UtxoVout,
+		UtxoBalance:  ftTxo.UtxoBalance,
+		FtContractId: ftTxo.FtContractId,
+		FtBalance:    int64(ftTxo.FtBalance),
+	}
+}
+
+// GetPoolReserves 获取池子当前储备
+// 只解析当前池NFT交易的tape输出，比完整的池信息查询少了脚本和服务商等字段的解析
+func (l *FtLogic) GetPoolReserves(ctx context.Context, req *ft.TBC20PoolReservesRequest) (*ft.TBC20PoolReservesResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		return nil, err
+	}
+
+	// 1. 定位当前池NFT所在的交易
+	currentPoolNftTxid, _, err := l.ftPoolNftDAO.GetPoolNftInfoByContractId(ctx, req.PoolId)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取池NFT失败: poolId=%s, %v", req.PoolId, err)
+		return nil, fmt.Errorf("获取池NFT失败: %w", err)
+	}
+	if currentPoolNftTxid == "" {
+		return nil, fmt.Errorf("未找到池NFT: %w", db.ErrNftNotFound)
+	}
+
+	// 2. 解析交易tape中的储备，已确认的池NFT交易优先使用缓存
+	reserves, err := l.getPoolReserves(ctx, currentPoolNftTxid)
 	if err != nil {
-		log.ErrorWithContext(ctx, "查询代币交易输出失败", "scriptHash", req.ScriptHash, "error", err)
 		return nil, err
 	}
 
-	// 5. 构建响应
-	ftUtxoList := make([]*ft.TBC20FTLPUnspentItem, 0, len(ftTxos))
-	for _, ftTxo := range ftTxos {
-		ftUtxoList = append(ftUtxoList, &ft.TBC20FTLPUnspentItem{
-			UtxoId:       ftTxo.UtxoTxid,
-			UtxoVout:     ftTxo.UtxoVout,
-			UtxoBalance:  ftTxo.UtxoBalance,
-			FtContractId: ftTxo.FtContractId,
-			FtBalance:    int64(ftTxo.FtBalance),
-		})
+	response := &ft.TBC20PoolReservesResponse{
+		PoolId:          req.PoolId,
+		FtLpBalance:     reserves.ftLpBalance,
+		FtABalance:      reserves.ftABalance,
+		TbcBalance:      reserves.tbcBalance,
+		FtAContractTxid: reserves.ftAContractTxid,
+		SourceTxid:      currentPoolNftTxid,
+		Confirmations:   reserves.confirmations,
+	}
+
+	// 3. 按本次请求的链顶计算高度和确认数
+	if tip, err := chaintip.Snapshot(ctx); err != nil {
+		log.WarnWithContextf(ctx, "获取链顶失败，使用节点返回的确认数: %v", err)
+	} else if height, confirmations, err := chaintip.BlockConfirmations(ctx, tip, reserves.blockhash); err != nil {
+		log.WarnWithContextf(ctx, "获取池NFT所在区块高度失败: %v", err)
+	} else {
+		response.SourceHeight = height
+		response.Confirmations = int(confirmations)
+		response.TipHeight = tip.Height
 	}
 
-	log.InfoWithContextf(ctx, "成功获取LP未花费交易输出: scriptHash=%s, count=%d", req.ScriptHash, len(ftUtxoList))
-	return &ft.TBC20FTLPUnspentResponse{FtUtxoList: ftUtxoList}, nil
+	log.InfoWithContextf(ctx, "成功获取池子储备: poolId=%s, txid=%s", req.PoolId, currentPoolNftTxid)
+	return response, nil
 }
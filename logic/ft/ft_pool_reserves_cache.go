@@ -0,0 +1,111 @@
+package ft
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	blockchianEntity "ginproject/entity/blockchain"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/rpc/blockchain"
+)
+
+const (
+	// 缓存的池NFT交易数
+	poolReservesCacheSize = 5000
+	// 池NFT交易的tape不会变化，过期时间只用于淘汰已被花费的交易
+	poolReservesCacheTTL = time.Hour
+)
+
+// poolReserves 从池NFT交易tape中解析的储备，不可修改
+type poolReserves struct {
+	ftLpBalance     int64
+	ftABalance      int64
+	tbcBalance      int64
+	ftAContractTxid string
+	blockhash       string
+	confirmations   int // 解析时节点返回的确认数，只在无法按链顶计算时使用
+}
+
+// 池NFT交易ID到储备的缓存，只缓存已确认的交易，重组时区块哈希可能失效，依赖过期时间淘汰
+var poolReservesCache = cache.NewLRU[string, *poolReserves](poolReservesCacheSize, poolReservesCacheTTL)
+
+func init() {
+	cache.Register("ft_pool_reserves", poolReservesCache)
+}
+
+// getPoolReserves 获取池NFT交易中的储备，优先读取缓存
+func (l *FtLogic) getPoolReserves(ctx context.Context, txid string) (*poolReserves, error) {
+	if reserves, ok := poolReservesCache.Get(txid); ok {
+		return reserves, nil
+	}
+
+	decodeTxResult := <-blockchain.DecodeTxHash(ctx, txid)
+	if decodeTxResult.Error != nil {
+		log.ErrorWithContextf(ctx, "解码交易失败: %v", decodeTxResult.Error)
+		return nil, fmt.Errorf("解码交易失败: %w", decodeTxResult.Error)
+	}
+	decodeTx, ok := decodeTxResult.Result.(*blockchianEntity.TransactionResponse)
+	if !ok {
+		log.ErrorWithContextf(ctx, "解码交易结果类型错误: txid=%s", txid)
+		return nil, fmt.Errorf("解码交易结果类型错误")
+	}
+	if len(decodeTx.Vout) < 2 {
+		log.ErrorWithContextf(ctx, "解码交易输出错误: txid=%s", txid)
+		return nil, fmt.Errorf("解码交易输出错误")
+	}
+
+	tapeAsm := decodeTx.Vout[1].ScriptPubKey.Asm
+	ftLpBalance, ftABalance, tbcBalance, err := utility.GetPoolBalanceFromTapeASM(tapeAsm)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "解析池余额失败: txid=%s, %v", txid, err)
+		return nil, fmt.Errorf("解析池余额失败: %w", err)
+	}
+
+	reserves := &poolReserves{
+		ftLpBalance:   ftLpBalance,
+		ftABalance:    ftABalance,
+		tbcBalance:    tbcBalance,
+		blockhash:     decodeTx.Blockhash,
+		confirmations: decodeTx.Confirmations,
+	}
+	if tapeAsmList := strings.Split(tapeAsm, " "); len(tapeAsmList) >= 5 {
+		reserves.ftAContractTxid = tapeAsmList[4]
+	}
+	if reserves.blockhash != "" {
+		poolReservesCache.Set(txid, reserves)
+	}
+	return reserves, nil
+}
+
+// RefreshPoolReserves 预先解析各流动池当前池NFT交易中的储备，由周期任务调用
+// 池NFT被花费后新交易在确认前不缓存，确认后的首次检查即写入缓存
+func RefreshPoolReserves(ctx context.Context) error {
+	l := NewFtLogic()
+	poolIds, err := l.ftPoolNftDAO.GetPoolContractIds(ctx, poolReservesCacheSize)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, poolId := range poolIds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		txid, _, err := l.ftPoolNftDAO.GetPoolNftInfoByContractId(ctx, poolId)
+		if err != nil || txid == "" {
+			failed++
+			continue
+		}
+		if _, err := l.getPoolReserves(ctx, txid); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.WarnWithContext(ctx, "部分流动池储备刷新失败", "池数:", len(poolIds), "失败数:", failed)
+	}
+	return nil
+}
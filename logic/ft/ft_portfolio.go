@@ -0,0 +1,121 @@
+package ft
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"ginproject/entity/exchange"
+	"ginproject/entity/ft"
+	"ginproject/entity/utility"
+	exchangelogic "ginproject/logic/exchange"
+	"ginproject/middleware/log"
+)
+
+const (
+	// TBC价值保留的小数位数
+	portfolioTbcDecimals = 6
+	// 法币价值保留的小数位数
+	portfolioFiatDecimals = 4
+)
+
+// GetFtPortfolioByAddress 获取地址持有的全部代币及其TBC和法币估值
+// 代币价格取自代币表，TBC汇率取自交易所汇率服务，汇率不可用时只返回TBC估值
+func (l *FtLogic) GetFtPortfolioByAddress(ctx context.Context, req *ft.FtPortfolioAddressRequest) (*ft.FtPortfolioResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		return nil, fmt.Errorf("参数验证失败: %w", err)
+	}
+
+	pubKeyHash, err := utility.ConvertAddressToPublicKeyHash(req.Address)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取组合脚本失败: %v", err)
+		return nil, fmt.Errorf("获取组合脚本失败: %w", err)
+	}
+	// 添加00作为校验
+	combineScript := pubKeyHash + "00"
+
+	// 汇率查询走外部交易所，与数据库查询并行
+	rateChan := make(chan *exchange.ExchangeRateResponse, 1)
+	go func() {
+		rate, err := exchangelogic.GetExchangeRate(ctx)
+		if err != nil {
+			log.WarnWithContextf(ctx, "获取TBC汇率失败: %v", err)
+		}
+		rateChan <- rate
+	}()
+
+	heldTokens, err := l.getHeldTokensByCombineScript(ctx, combineScript)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ft.FtPortfolioResponse{
+		Address:      req.Address,
+		TokenCount:   len(heldTokens),
+		Tokens:       make([]ft.FtPortfolioItem, 0, len(heldTokens)),
+		FiatCurrency: "USD",
+	}
+
+	var tbcRate *big.Rat
+	if rate := <-rateChan; rate != nil {
+		response.TbcRate = rate.Rate
+		if rate.Currency != "" {
+			response.FiatCurrency = rate.Currency
+		}
+		if rate.Rate > 0 {
+			tbcRate = new(big.Rat).SetFloat64(rate.Rate)
+		}
+	}
+
+	type valuedItem struct {
+		item  ft.FtPortfolioItem
+		value *big.Rat
+	}
+	items := make([]valuedItem, 0, len(heldTokens))
+	total := new(big.Rat)
+	for _, held := range heldTokens {
+		balance := new(big.Rat).SetFrac(
+			new(big.Int).SetUint64(held.FtBalance),
+			new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(held.FtDecimal)), nil),
+		)
+
+		value := new(big.Rat)
+		if price := new(big.Rat).SetFloat64(held.Price); price != nil {
+			value.Mul(balance, price)
+		}
+		total.Add(total, value)
+
+		item := ft.FtPortfolioItem{
+			TokenInfo:        held.TokenInfo,
+			FtBalanceDisplay: utility.FormatRat(balance, held.FtDecimal, false),
+			FtPrice:          utility.FormatDecimal(held.Price, 18, false),
+			ValueTbc:         utility.FormatRat(value, portfolioTbcDecimals, false),
+		}
+		if tbcRate != nil {
+			item.ValueFiat = utility.FormatRat(new(big.Rat).Mul(value, tbcRate), portfolioFiatDecimals, false)
+		}
+		items = append(items, valuedItem{item: item, value: value})
+	}
+
+	// 按TBC价值从高到低排序，价值相同时按合约ID排序保证结果稳定
+	sort.Slice(items, func(i, j int) bool {
+		if cmp := items[i].value.Cmp(items[j].value); cmp != 0 {
+			return cmp > 0
+		}
+		return items[i].item.FtContractId < items[j].item.FtContractId
+	})
+	for _, valued := range items {
+		response.Tokens = append(response.Tokens, valued.item)
+	}
+
+	response.TotalValueTbc = utility.FormatRat(total, portfolioTbcDecimals, false)
+	if tbcRate != nil {
+		response.TotalValueFiat = utility.FormatRat(new(big.Rat).Mul(total, tbcRate), portfolioFiatDecimals, false)
+	}
+
+	log.InfoWithContextf(ctx, "成功获取地址[%s]的FT资产估值，代币数量: %d，总价值: %s TBC",
+		req.Address, response.TokenCount, response.TotalValueTbc)
+	return response, nil
+}
@@ -18,14 +18,14 @@ func (l *FtLogic) GetTokenHistory(ctx context.Context, req *ft.FtTokenHistoryReq
 	// 参数验证
 	if err := ft.ValidateFtTokenHistoryRequest(req); err != nil {
 		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
-		return nil, fmt.Errorf("参数验证失败: %v", err)
+		return nil, fmt.Errorf("参数验证失败: %w", err)
 	}
 
 	// 获取代币代码脚本
 	ftCodeScript, err := l.ftTokensDAO.GetFtCodeScript(ctx, req.FtContractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币代码脚本失败: %v", err)
-		return nil, fmt.Errorf("获取代币代码脚本失败: %v", err)
+		return nil, fmt.Errorf("获取代币代码脚本失败: %w", err)
 	}
 
 	// 检查代码脚本是否为空
@@ -169,7 +169,7 @@ func (l *FtLogic) decodeTxHistory(ctx context.Context, txid string) (*ft.FtTxDec
 		}
 
 		// 获取代币小数位数
-		ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, ftContractId)
+		ftDecimal, err := l.getFtDecimal(ctx, ftContractId)
 		if err != nil {
 			log.WarnWithContextf(ctx, "获取代币小数位数失败: %v", err)
 			ftDecimal = 0
@@ -224,7 +224,7 @@ func (l *FtLogic) decodeTxHistory(ctx context.Context, txid string) (*ft.FtTxDec
 		}
 
 		// 获取代币小数位数
-		ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, ftContractId)
+		ftDecimal, err := l.getFtDecimal(ctx, ftContractId)
 		if err != nil {
 			log.WarnWithContextf(ctx, "获取代币小数位数失败: %v", err)
 			ftDecimal = 0
@@ -29,11 +29,13 @@ func (l *FtLogic) GetFtTokenList(ctx context.Context, req *ft.FtTokenListRequest
 
 	// 转换为响应格式
 	tokenInfoList := l.convertTokensToInfoList(ctx, tokens)
+	enrichTokenList(ctx, tokenInfoList)
 
 	// 创建响应
 	response := &ft.FtTokenListData{
 		FtTokenCount: int(total),
 		FtTokenList:  tokenInfoList,
+		Meta:         utility.NewPageMeta(req.Page, req.Size, total),
 	}
 	log.InfoWithContextf(ctx, "成功获取代币列表，总数: %d, 当前页: %d, 每页大小: %d",
 		total, req.Page, req.Size)
@@ -97,3 +99,26 @@ func (l *FtLogic) convertTokensToInfoList(ctx context.Context, tokens []*dbtable
 
 	return tokenInfoList
 }
+
+// SearchFtTokens 按名称或符号搜索代币，符号完全匹配的代币排在最前
+func (l *FtLogic) SearchFtTokens(ctx context.Context, req *ft.FtTokenSearchRequest) (*ft.FtTokenListData, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "搜索代币参数验证失败: %v", err)
+		return nil, err
+	}
+
+	tokens, total, err := l.ftTokensDAO.SearchTokens(ctx, req.Keyword, req.Page, req.Size)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "搜索代币失败: %v", err)
+		return nil, fmt.Errorf("搜索代币失败: %w", err)
+	}
+
+	tokenInfoList := l.convertTokensToInfoList(ctx, tokens)
+	enrichTokenList(ctx, tokenInfoList)
+
+	return &ft.FtTokenListData{
+		FtTokenCount: int(total),
+		FtTokenList:  tokenInfoList,
+		Meta:         utility.NewPageMeta(req.Page, req.Size, total),
+	}, nil
+}
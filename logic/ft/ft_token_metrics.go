@@ -0,0 +1,57 @@
+package ft
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/ft"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+)
+
+// GetFtTokenMetrics 获取代币的流通速度和休眠比例
+// 统计基于ft_txo_set的创建和花费高度，尚未回填高度的输出单独计入unindexed_supply
+func (l *FtLogic) GetFtTokenMetrics(ctx context.Context, req *ft.FtTokenMetricsRequest) (*ft.FtTokenMetricsResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		return nil, err
+	}
+
+	// 精度查询同时确认代币存在
+	decimal, err := l.getFtDecimal(ctx, req.ContractId)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取代币精度失败: contractId=%s, %v", req.ContractId, err)
+		return nil, fmt.Errorf("获取代币信息失败: %w", err)
+	}
+
+	tip, err := chaintip.Snapshot(ctx)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取链顶失败: %v", err)
+		return nil, fmt.Errorf("获取链顶失败: %w", err)
+	}
+	periodStart, dormancyHeight := req.MetricsHeights(tip.Height)
+
+	metrics, err := l.ftTxoDAO.GetSupplyMetricsByContract(ctx, req.ContractId, dormancyHeight, periodStart)
+	if err != nil {
+		return nil, fmt.Errorf("统计代币供应量失败: %w", err)
+	}
+
+	response := &ft.FtTokenMetricsResponse{
+		FtContractId:      req.ContractId,
+		FtDecimal:         int(decimal),
+		TipHeight:         tip.Height,
+		PeriodDays:        req.PeriodDays,
+		PeriodStartHeight: periodStart,
+		DormancyDays:      req.DormancyDays,
+		DormancyHeight:    dormancyHeight,
+		CirculatingSupply: metrics.CirculatingSupply,
+		TransferredSupply: metrics.TransferredSupply,
+		DormantSupply:     metrics.DormantSupply,
+		UnindexedSupply:   metrics.UnindexedSupply,
+	}
+	response.ComputeRatios()
+
+	log.InfoWithContextf(ctx, "获取代币流通指标成功: contractId=%s, velocity=%.4f, dormancy=%.4f",
+		req.ContractId, response.Velocity, response.Dormancy)
+	return response, nil
+}
@@ -0,0 +1,118 @@
+package ft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/ft"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/token_registry_dao"
+)
+
+// ImportTokenRegistry 导入人工维护的代币元数据，已存在的记录会被覆盖
+func (l *FtLogic) ImportTokenRegistry(ctx context.Context, req *ft.TokenRegistryImportRequest) (*ft.TokenRegistryImportResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "代币元数据导入参数验证失败: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	rows := make([]*dbtable.TokenRegistry, 0, len(req.Tokens))
+	for _, item := range req.Tokens {
+		socials := ""
+		if len(item.Socials) > 0 {
+			data, err := json.Marshal(item.Socials)
+			if err != nil {
+				return nil, fmt.Errorf("序列化社交媒体链接失败: %w", err)
+			}
+			socials = string(data)
+		}
+
+		row := &dbtable.TokenRegistry{
+			FtContractId: item.FtContractId,
+			LogoUrl:      item.LogoUrl,
+			Website:      item.Website,
+			Description:  item.Description,
+			Tags:         strings.Join(item.Tags, ","),
+			Socials:      socials,
+			Verified:     item.Verified,
+		}
+		// 导入时标记的核验为人工核验，会覆盖此前的创建者核验
+		if item.Verified {
+			row.VerificationMethod = ft.VerificationMethodRegistry
+			row.VerifiedAt = &now
+		}
+		rows = append(rows, row)
+	}
+
+	if err := token_registry_dao.UpsertTokenRegistries(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	log.InfoWithContextf(ctx, "代币元数据导入完成: %d条", len(rows))
+	return &ft.TokenRegistryImportResponse{Imported: len(rows)}, nil
+}
+
+// applyTokenMetadata 将单个代币的元数据和核验状态合并到代币信息中，未登记时保持不变
+// 元数据只是补充信息，查询失败时记录日志并按未登记处理
+func applyTokenMetadata(ctx context.Context, response *ft.TBC20FTInfoResponse) {
+	row, err := token_registry_dao.GetTokenRegistryByContractId(ctx, response.FtContractId)
+	if err != nil {
+		if !db.IsNotFound(err) {
+			log.WarnWithContextf(ctx, "查询代币元数据失败: %v", err)
+		}
+		return
+	}
+	response.FtMetadata = toTokenMetadata(ctx, row)
+	response.FtVerified = row.Verified
+	response.FtVerificationMethod = row.VerificationMethod
+}
+
+// enrichTokenList 为代币列表批量合并元数据
+func enrichTokenList(ctx context.Context, tokens []*ft.FtTokenInfo) {
+	if len(tokens) == 0 {
+		return
+	}
+
+	contractIds := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		contractIds = append(contractIds, token.FtContractId)
+	}
+
+	rows, err := token_registry_dao.GetTokenRegistriesByContractIds(ctx, contractIds)
+	if err != nil {
+		log.WarnWithContextf(ctx, "批量查询代币元数据失败: %v", err)
+		return
+	}
+
+	for _, token := range tokens {
+		if row, ok := rows[token.FtContractId]; ok {
+			token.FtMetadata = toTokenMetadata(ctx, row)
+			token.FtVerified = row.Verified
+			token.FtVerificationMethod = row.VerificationMethod
+		}
+	}
+}
+
+// toTokenMetadata 将数据库记录转换为响应中的元数据
+func toTokenMetadata(ctx context.Context, row *dbtable.TokenRegistry) *ft.TokenMetadata {
+	metadata := &ft.TokenMetadata{
+		LogoUrl:     row.LogoUrl,
+		Website:     row.Website,
+		Description: row.Description,
+	}
+	if row.Tags != "" {
+		metadata.Tags = strings.Split(row.Tags, ",")
+	}
+	if row.Socials != "" {
+		if err := json.Unmarshal([]byte(row.Socials), &metadata.Socials); err != nil {
+			log.WarnWithContextf(ctx, "解析代币社交媒体链接失败: 合约ID=%s, %v", row.FtContractId, err)
+		}
+	}
+	return metadata
+}
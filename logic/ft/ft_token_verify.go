@@ -0,0 +1,150 @@
+package ft
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ginproject/entity/ft"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/db/token_registry_dao"
+)
+
+const (
+	// 核验挑战的有效期
+	verificationChallengeTTL = 10 * time.Minute
+	// 进程内最多保留的未完成挑战数
+	verificationChallengeCapacity = 10000
+)
+
+// ErrVerificationFailed 挑战不存在、已过期或签名与创建者地址不匹配
+var ErrVerificationFailed = errors.New("代币核验失败")
+
+// verificationChallenges 已下发的核验挑战，按合约ID和随机数保存，同一代币可同时存在多个未完成的挑战，每个挑战只能使用一次
+var verificationChallenges = cache.NewLayered("token_verification_challenge", verificationChallengeCapacity, verificationChallengeTTL)
+
+// CreateTokenVerificationChallenge 为代币生成核验挑战，创建者用创建代币时使用的地址对挑战签名
+func (l *FtLogic) CreateTokenVerificationChallenge(ctx context.Context, req *ft.TokenVerificationChallengeRequest) (*ft.TokenVerificationChallengeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	creatorAddress, err := l.tokenCreatorAddress(ctx, req.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成挑战随机数失败: %w", err)
+	}
+	expiresAt := time.Now().Add(verificationChallengeTTL).Unix()
+	nonceHex := hex.EncodeToString(nonce)
+	challenge := buildVerificationChallenge(req.ContractId, nonceHex, expiresAt)
+	verificationChallenges.Set(ctx, verificationChallengeKey(req.ContractId, nonceHex), []byte(challenge))
+
+	return &ft.TokenVerificationChallengeResponse{
+		FtContractId:   req.ContractId,
+		CreatorAddress: creatorAddress,
+		Challenge:      challenge,
+		ExpiresAt:      expiresAt,
+	}, nil
+}
+
+// VerifyTokenCreator 校验创建者对挑战的签名，通过后将代币标记为创建者核验
+func (l *FtLogic) VerifyTokenCreator(ctx context.Context, req *ft.TokenVerificationRequest) (*ft.TokenVerificationResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	nonce, err := parseVerificationChallengeNonce(req.Challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVerificationFailed, err)
+	}
+	key := verificationChallengeKey(req.ContractId, nonce)
+	stored, ok := verificationChallenges.Get(ctx, key)
+	if !ok || string(stored) != req.Challenge {
+		return nil, fmt.Errorf("%w: 挑战不存在或已使用，请重新获取", ErrVerificationFailed)
+	}
+	// 挑战取出后立即删除，签名校验失败也需重新获取，避免同一挑战被反复尝试或重放
+	verificationChallenges.Delete(ctx, key)
+	expiresAt, err := parseVerificationChallengeExpiry(req.Challenge)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, fmt.Errorf("%w: 挑战已过期，请重新获取", ErrVerificationFailed)
+	}
+
+	creatorAddress, err := l.tokenCreatorAddress(ctx, req.ContractId)
+	if err != nil {
+		return nil, err
+	}
+	if err := utility.VerifyMessage(creatorAddress, req.Signature, req.Challenge); err != nil {
+		log.WarnWithContextf(ctx, "代币创建者签名校验失败: 合约ID=%s, %v", req.ContractId, err)
+		return nil, fmt.Errorf("%w: %v", ErrVerificationFailed, err)
+	}
+
+	verifiedAt := time.Now()
+	if err := token_registry_dao.MarkTokenVerified(ctx, req.ContractId, ft.VerificationMethodCreator, verifiedAt); err != nil {
+		return nil, err
+	}
+	log.InfoWithContextf(ctx, "代币创建者核验通过: 合约ID=%s, 创建者=%s", req.ContractId, creatorAddress)
+
+	return &ft.TokenVerificationResponse{
+		FtContractId:         req.ContractId,
+		FtVerified:           true,
+		FtVerificationMethod: ft.VerificationMethodCreator,
+		FtVerifiedAt:         verifiedAt.Unix(),
+	}, nil
+}
+
+// tokenCreatorAddress 返回代币创建者的地址，创建者为池控制或多签脚本时无法用签名核验
+func (l *FtLogic) tokenCreatorAddress(ctx context.Context, contractId string) (string, error) {
+	token, err := l.ftTokensDAO.GetFtTokenById(ctx, contractId)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(token.FtCreatorCombineScript, "00") {
+		return "", fmt.Errorf("%w: 创建者不是普通地址，不支持签名核验", ErrVerificationFailed)
+	}
+	return utility.ConvertCombineScriptToAddress(token.FtCreatorCombineScript)
+}
+
+// buildVerificationChallenge 构造待签名的挑战原文，只含ASCII字符以便各类钱包直接签名
+func buildVerificationChallenge(contractId, nonce string, expiresAt int64) string {
+	return fmt.Sprintf("Verify token %s on TBC API\nNonce: %s\nExpires: %d", contractId, nonce, expiresAt)
+}
+
+// verificationChallengeKey 返回挑战在缓存中的键
+func verificationChallengeKey(contractId, nonce string) string {
+	return contractId + ":" + nonce
+}
+
+// parseVerificationChallengeNonce 从挑战原文中解析随机数
+func parseVerificationChallengeNonce(challenge string) (string, error) {
+	const prefix = "\nNonce: "
+	i := strings.Index(challenge, prefix)
+	if i < 0 {
+		return "", fmt.Errorf("挑战缺少随机数")
+	}
+	nonce, _, _ := strings.Cut(challenge[i+len(prefix):], "\n")
+	if nonce == "" {
+		return "", fmt.Errorf("挑战缺少随机数")
+	}
+	return nonce, nil
+}
+
+// parseVerificationChallengeExpiry 从挑战原文中解析过期时间
+func parseVerificationChallengeExpiry(challenge string) (int64, error) {
+	const prefix = "\nExpires: "
+	i := strings.LastIndex(challenge, prefix)
+	if i < 0 {
+		return 0, fmt.Errorf("挑战缺少过期时间")
+	}
+	return strconv.ParseInt(challenge[i+len(prefix):], 10, 64)
+}
@@ -0,0 +1,24 @@
+package ft
+
+import "testing"
+
+func TestVerificationChallengeExpiry(t *testing.T) {
+	challenge := buildVerificationChallenge("ab", "0011", 1700000000)
+	if challenge != "Verify token ab on TBC API\nNonce: 0011\nExpires: 1700000000" {
+		t.Fatalf("挑战原文 = %q", challenge)
+	}
+	expiresAt, err := parseVerificationChallengeExpiry(challenge)
+	if err != nil || expiresAt != 1700000000 {
+		t.Fatalf("过期时间 = %d, %v", expiresAt, err)
+	}
+	if _, err := parseVerificationChallengeExpiry("Verify token ab"); err == nil {
+		t.Fatal("缺少过期时间时应返回错误")
+	}
+	nonce, err := parseVerificationChallengeNonce(challenge)
+	if err != nil || nonce != "0011" {
+		t.Fatalf("随机数 = %q, %v", nonce, err)
+	}
+	if _, err := parseVerificationChallengeNonce("Verify token ab\nExpires: 1"); err == nil {
+		t.Fatal("缺少随机数时应返回错误")
+	}
+}
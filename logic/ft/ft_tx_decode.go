@@ -7,6 +7,7 @@ import (
 	"ginproject/entity/ft"
 	"ginproject/entity/utility"
 	"ginproject/middleware/log"
+	"ginproject/repo/db"
 	rpcblockchain "ginproject/repo/rpc/blockchain"
 )
 
@@ -58,6 +59,10 @@ func (l *FtLogic) DecodeFtTransactionHistory(ctx context.Context, req *ft.FtTxDe
 
 			// 查询ft_txo_set表获取FT代币信息
 			ftBalance, ftHolderScript, ftContractId, err := l.ftTxoDAO.GetFtUtxoInfo(ctx, inputTxid, int(vout))
+			if db.IsNotFound(err) {
+				// 非FT代币相关的UTXO，跳过
+				continue
+			}
 			if err != nil {
 				log.WarnWithContextf(ctx, "获取FT UTXO信息失败: txid=%s, vout=%d, 错误=%v",
 					inputTxid, int(vout), err)
@@ -70,7 +75,7 @@ func (l *FtLogic) DecodeFtTransactionHistory(ctx context.Context, req *ft.FtTxDe
 			}
 
 			// 获取代币小数位数
-			ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, ftContractId)
+			ftDecimal, err := l.getFtDecimal(ctx, ftContractId)
 			if err != nil {
 				log.WarnWithContextf(ctx, "获取代币小数位数失败: 合约ID=%s, 错误=%v",
 					ftContractId, err)
@@ -133,6 +138,10 @@ func (l *FtLogic) DecodeFtTransactionHistory(ctx context.Context, req *ft.FtTxDe
 
 			// 查询ft_txo_set表获取FT代币信息
 			ftBalance, ftHolderScript, ftContractId, err := l.ftTxoDAO.GetFtUtxoInfo(ctx, req.Txid, int(n))
+			if db.IsNotFound(err) {
+				// 非FT代币相关的UTXO，跳过
+				continue
+			}
 			if err != nil {
 				log.WarnWithContextf(ctx, "获取FT UTXO信息失败: txid=%s, vout=%d, 错误=%v",
 					req.Txid, int(n), err)
@@ -145,7 +154,7 @@ func (l *FtLogic) DecodeFtTransactionHistory(ctx context.Context, req *ft.FtTxDe
 			}
 
 			// 获取代币小数位数
-			ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, ftContractId)
+			ftDecimal, err := l.getFtDecimal(ctx, ftContractId)
 			if err != nil {
 				log.WarnWithContextf(ctx, "获取代币小数位数失败: 合约ID=%s, 错误=%v",
 					ftContractId, err)
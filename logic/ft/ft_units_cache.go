@@ -0,0 +1,95 @@
+package ft
+
+import (
+	"context"
+	"time"
+
+	"ginproject/entity/ft"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+)
+
+const (
+	// 代币单位信息缓存的最大合约数
+	ftUnitsCacheSize = 10000
+	// 代币精度、名称和符号部署后不会变化，过期时间只用于兜底
+	ftUnitsCacheTTL = 30 * time.Minute
+	// 预热时每批查询的合约数
+	ftUnitsWarmBatchSize = 500
+)
+
+// 合约ID到代币单位信息的缓存，所有FtLogic实例共享，缓存的值不可修改
+var ftUnitsCache = cache.NewLRU[string, *ft.TokenUnits](ftUnitsCacheSize, ftUnitsCacheTTL)
+
+func init() {
+	cache.Register("ft_token_units", ftUnitsCache)
+}
+
+// getTokenUnits 获取代币单位信息，优先读取缓存；未找到的合约不缓存，错误语义与DAO一致
+func (l *FtLogic) getTokenUnits(ctx context.Context, contractId string) (*ft.TokenUnits, error) {
+	if units, ok := ftUnitsCache.Get(contractId); ok {
+		return units, nil
+	}
+
+	token, err := l.ftTokensDAO.GetFtUnitsByContractId(ctx, contractId)
+	if err != nil {
+		return nil, err
+	}
+	units := ft.NewTokenUnits(token.FtDecimal, token.FtName, token.FtSymbol)
+	ftUnitsCache.Set(contractId, units)
+	return units, nil
+}
+
+// getFtDecimal 获取代币精度
+func (l *FtLogic) getFtDecimal(ctx context.Context, contractId string) (uint8, error) {
+	units, err := l.getTokenUnits(ctx, contractId)
+	if err != nil {
+		return 0, err
+	}
+	return uint8(units.Decimals), nil
+}
+
+// prefetchTokenUnits 批量预取缓存中缺失的代币单位信息，随后的getTokenUnits调用直接命中缓存
+// 预取失败只记录日志，后续按单个合约查询
+func (l *FtLogic) prefetchTokenUnits(ctx context.Context, contractIds []string) {
+	missing := make([]string, 0, len(contractIds))
+	seen := make(map[string]struct{}, len(contractIds))
+	for _, contractId := range contractIds {
+		if _, ok := seen[contractId]; ok {
+			continue
+		}
+		seen[contractId] = struct{}{}
+		if _, ok := ftUnitsCache.Get(contractId); !ok {
+			missing = append(missing, contractId)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	tokens, err := l.ftTokensDAO.GetFtUnitsByContractIds(ctx, missing)
+	if err != nil {
+		log.WarnWithContextf(ctx, "批量预取代币单位信息失败: %v", err)
+		return
+	}
+	for contractId, token := range tokens {
+		ftUnitsCache.Set(contractId, ft.NewTokenUnits(token.FtDecimal, token.FtName, token.FtSymbol))
+	}
+}
+
+// WarmTokenUnits 预热全部代币的单位信息缓存，由周期任务调用，代币数超过缓存容量时只预热前面的代币
+func WarmTokenUnits(ctx context.Context) error {
+	l := NewFtLogic()
+	contractIds, err := l.ftTokensDAO.GetFtContractIds(ctx)
+	if err != nil {
+		return err
+	}
+	if len(contractIds) > ftUnitsCacheSize {
+		contractIds = contractIds[:ftUnitsCacheSize]
+	}
+	for start := 0; start < len(contractIds) && ctx.Err() == nil; start += ftUnitsWarmBatchSize {
+		end := min(start+ftUnitsWarmBatchSize, len(contractIds))
+		l.prefetchTokenUnits(ctx, contractIds[start:end])
+	}
+	return ctx.Err()
+}
@@ -6,8 +6,10 @@ import (
 	"strconv"
 
 	"ginproject/entity/blockchain"
+	electrumxEntity "ginproject/entity/electrumx"
 	"ginproject/entity/ft"
 	"ginproject/entity/utility"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
 	repoBlockchain "ginproject/repo/rpc/blockchain"
 	"ginproject/repo/rpc/electrumx"
@@ -31,15 +33,22 @@ func (l *FtLogic) GetFtUtxosByAddress(ctx context.Context, req *ft.FtUtxoAddress
 	// 添加00作为校验
 	combineScript += "00"
 
-	// 获取代币小数位数
-	ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, req.ContractId)
+	// 获取代币单位信息
+	units, err := l.getTokenUnits(ctx, req.ContractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币小数位数失败: %v", err)
-		return nil, fmt.Errorf("获取代币小数位数失败: %v", err)
+		return nil, fmt.Errorf("获取代币小数位数失败: %w", err)
+	}
+
+	// 按最少确认数计算高度上限
+	maxHeight, err := chaintip.HeightLimit(ctx, req.MinConfirmations)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "计算确认高度上限失败: %v", err)
+		return nil, err
 	}
 
 	// 调用DAO层获取未花费的UTXO列表
-	utxos, err := l.ftTxoDAO.GetUnspentFtTxosByHolderAndContract(combineScript, req.ContractId)
+	utxos, err := l.ftTxoDAO.GetUnspentFtTxosByHolderAndContract(ctx, combineScript, req.ContractId, maxHeight)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "查询FT UTXO列表失败: %v", err)
 		return nil, fmt.Errorf("查询FT UTXO列表失败: %v", err)
@@ -48,6 +57,7 @@ func (l *FtLogic) GetFtUtxosByAddress(ctx context.Context, req *ft.FtUtxoAddress
 	// 构造响应
 	response := &ft.FtUtxoAddressResponse{
 		FtUtxoList: make([]*ft.FtUtxoItem, 0, len(utxos)),
+		TokenInfo:  units,
 	}
 
 	// 遍历UTXO列表，构造UTXO项
@@ -57,7 +67,7 @@ func (l *FtLogic) GetFtUtxosByAddress(ctx context.Context, req *ft.FtUtxoAddress
 			UtxoVout:     utxo.UtxoVout,
 			UtxoBalance:  utxo.UtxoBalance,
 			FtContractId: utxo.FtContractId,
-			FtDecimal:    int(ftDecimal),
+			FtDecimal:    units.Decimals,
 			FtBalance:    utxo.FtBalance,
 		}
 		response.FtUtxoList = append(response.FtUtxoList, utxoItem)
@@ -84,8 +94,14 @@ func (l *FtLogic) GetFtUtxosByCombineScript(ctx context.Context, req *ft.FtUtxoC
 
 	log.InfoWithContextf(ctx, "根据合并脚本获取FT UTXO: 合并脚本=%s, 合约ID=%s", combineScript, req.ContractId)
 
-	
-	dbResponse, err := l.getFtUtxoFromDB(ctx, combineScript, req.ContractId)
+	// 数据库和RPC两条路径使用同一个链顶计算的高度上限
+	maxHeight, err := chaintip.HeightLimit(ctx, req.MinConfirmations)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "计算确认高度上限失败: %v", err)
+		return nil, err
+	}
+
+	dbResponse, err := l.getFtUtxoFromDB(ctx, combineScript, req.ContractId, maxHeight)
 	if err == nil && len(dbResponse.FtUtxoList) > 0 {
 		log.InfoWithContextf(ctx, "从数据库成功获取FT UTXO: 共%d条记录", len(dbResponse.FtUtxoList))
 		return dbResponse, nil
@@ -97,43 +113,18 @@ func (l *FtLogic) GetFtUtxosByCombineScript(ctx context.Context, req *ft.FtUtxoC
 		log.WarnWithContextf(ctx, "数据库中未找到任何记录，尝试通过RPC获取")
 	}
 
-	// 方法2: 通过RPC获取数据（备用方案）
-	// 1. 解码合约交易，获取合约脚本
-	decodeContractResult := <-repoBlockchain.DecodeTxHash(ctx, req.ContractId)
-	if decodeContractResult.Error != nil {
-		log.ErrorWithContextf(ctx, "解码合约交易失败: %v", decodeContractResult.Error)
-		return nil, fmt.Errorf("解码合约交易失败: %v", decodeContractResult.Error)
-	}
-
-	contractTx, ok := decodeContractResult.Result.(*blockchain.TransactionResponse)
-	if !ok {
-		return nil, fmt.Errorf("解码合约交易响应格式错误")
-	}
-
-	if len(contractTx.Vout) == 0 {
-		return nil, fmt.Errorf("合约交易输出为空")
-	}
-
-	// 获取合约脚本特征
-	codeScriptHex := contractTx.Vout[0].ScriptPubKey.Hex
-	contractTrait := codeScriptHex[0 : len(codeScriptHex)-54]
-	completeScript := contractTrait + combineScript + "0502436f6465" // "0502436f6465"是"Code"的十六进制表示
-
-	// 2. 计算脚本哈希
-	scriptHash, err := utility.ConvertStrToSha256(completeScript)
+	// 方法2: 通过RPC获取数据（备用方案），获取持有者代码脚本的未花费UTXO列表，按高度上限过滤
+	unspentResults, err := getFtCodeUnspents(ctx, req.ContractId, []string{combineScript})
 	if err != nil {
-		log.ErrorWithContextf(ctx, "计算脚本哈希失败: %v", err)
-		return nil, fmt.Errorf("计算脚本哈希失败: %v", err)
+		return nil, err
 	}
-
-	// 3. 获取未花费的UTXO列表
-	unspentUtxos, err := electrumx.GetUnspent(ctx, scriptHash)
-	if err != nil {
-		log.ErrorWithContextf(ctx, "获取未花费UTXO失败: %v", err)
-		return nil, fmt.Errorf("获取未花费UTXO失败: %v", err)
+	if unspentResults[0].Error != nil {
+		log.ErrorWithContextf(ctx, "获取未花费UTXO失败: %v", unspentResults[0].Error)
+		return nil, fmt.Errorf("获取未花费UTXO失败: %v", unspentResults[0].Error)
 	}
+	unspentUtxos := electrumxEntity.FilterUtxosByHeight(unspentResults[0].Utxos, maxHeight)
 
-	// 4. 构建响应
+	// 构建响应
 	response := &ft.TBC20FTUtxoResponse{
 		FtUtxoList: make([]*ft.TBC20FTUtxoItem, 0, len(unspentUtxos)),
 	}
@@ -187,12 +178,13 @@ func (l *FtLogic) GetFtUtxosByCombineScript(ctx context.Context, req *ft.FtUtxoC
 			}
 		}
 
-		// 获取代币小数位数
-		ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, req.ContractId)
+		// 获取代币单位信息
+		units, err := l.getTokenUnits(ctx, req.ContractId)
 		if err != nil {
 			log.ErrorWithContextf(ctx, "获取代币小数位数失败: %v", err)
-			return nil, fmt.Errorf("获取代币小数位数失败: %v", err)
+			return nil, fmt.Errorf("获取代币小数位数失败: %w", err)
 		}
+		response.TokenInfo = units
 
 		// 构建UTXO项
 		utxoItem := &ft.TBC20FTUtxoItem{
@@ -200,7 +192,7 @@ func (l *FtLogic) GetFtUtxosByCombineScript(ctx context.Context, req *ft.FtUtxoC
 			UtxoVout:     int(utxo.TxPos),
 			UtxoBalance:  satoshiValue,
 			FtContractId: req.ContractId,
-			FtDecimal:    int(ftDecimal),
+			FtDecimal:    units.Decimals,
 			FtBalance:    ftAmount,
 		}
 
@@ -211,19 +203,67 @@ func (l *FtLogic) GetFtUtxosByCombineScript(ctx context.Context, req *ft.FtUtxoC
 	return response, nil
 }
 
-// getFtUtxoFromDB 从数据库获取FT UTXO列表
-func (l *FtLogic) getFtUtxoFromDB(ctx context.Context, combineScript string, contractId string) (*ft.TBC20FTUtxoResponse, error) {
+// getFtCodeUnspents 通过ElectrumX获取合约下各持有者的代币代码脚本的未花费交易输出
+// 解码一次合约交易后为每个持有者拼接代码脚本并计算脚本哈希，经GetScriptHashUnspentMulti并发查询
+// 结果与combineScripts的顺序一致，单个持有者查询失败记录在对应结果的Error中
+func getFtCodeUnspents(ctx context.Context, contractId string, combineScripts []string) ([]electrumx.ScriptHashUnspentResult, error) {
+	// 1. 解码合约交易，获取合约脚本特征
+	decodeContractResult := <-repoBlockchain.DecodeTxHash(ctx, contractId)
+	if decodeContractResult.Error != nil {
+		log.ErrorWithContextf(ctx, "解码合约交易失败: %v", decodeContractResult.Error)
+		return nil, fmt.Errorf("解码合约交易失败: %v", decodeContractResult.Error)
+	}
+
+	contractTx, ok := decodeContractResult.Result.(*blockchain.TransactionResponse)
+	if !ok {
+		return nil, fmt.Errorf("解码合约交易响应格式错误")
+	}
+
+	if len(contractTx.Vout) == 0 {
+		return nil, fmt.Errorf("合约交易输出为空")
+	}
+
+	codeScriptHex := contractTx.Vout[0].ScriptPubKey.Hex
+	contractTrait := codeScriptHex[0 : len(codeScriptHex)-54]
+
+	// 2. 计算各持有者代码脚本的脚本哈希
+	scriptHashes := make([]string, len(combineScripts))
+	for i, combineScript := range combineScripts {
+		completeScript := contractTrait + combineScript + "0502436f6465" // "0502436f6465"是"Code"的十六进制表示
+		scriptHash, err := utility.ConvertStrToSha256(completeScript)
+		if err != nil {
+			log.ErrorWithContextf(ctx, "计算脚本哈希失败: %v", err)
+			return nil, fmt.Errorf("计算脚本哈希失败: %v", err)
+		}
+		scriptHashes[i] = scriptHash
+	}
+
+	// 3. 并发获取未花费的UTXO列表，重复的脚本哈希只查询一次
+	results := electrumx.GetScriptHashUnspentMulti(ctx, scriptHashes)
+	byHash := make(map[string]electrumx.ScriptHashUnspentResult, len(results))
+	for _, result := range results {
+		byHash[result.ScriptHash] = result
+	}
+	ordered := make([]electrumx.ScriptHashUnspentResult, len(scriptHashes))
+	for i, scriptHash := range scriptHashes {
+		ordered[i] = byHash[scriptHash]
+	}
+	return ordered, nil
+}
+
+// getFtUtxoFromDB 从数据库获取FT UTXO列表，maxHeight小于0时不按高度过滤
+func (l *FtLogic) getFtUtxoFromDB(ctx context.Context, combineScript string, contractId string, maxHeight int64) (*ft.TBC20FTUtxoResponse, error) {
 	log.InfoWithContextf(ctx, "从数据库获取FT UTXO数据: 合并脚本=%s, 合约ID=%s", combineScript, contractId)
 
-	// 获取代币小数位数 (虽然这里不直接使用，但响应中可能需要)
-	_, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, contractId)
+	// 获取代币单位信息
+	units, err := l.getTokenUnits(ctx, contractId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取代币小数位数失败: %v", err)
-		return nil, fmt.Errorf("获取代币小数位数失败: %v", err)
+		return nil, fmt.Errorf("获取代币小数位数失败: %w", err)
 	}
 
 	// 获取未花费的UTXO列表
-	utxos, err := l.ftTxoDAO.GetUnspentFtTxosByHolderAndContract(combineScript, contractId)
+	utxos, err := l.ftTxoDAO.GetUnspentFtTxosByHolderAndContract(ctx, combineScript, contractId, maxHeight)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "从数据库查询FT UTXO列表失败: %v", err)
 		return nil, fmt.Errorf("查询FT UTXO列表失败: %v", err)
@@ -232,22 +272,17 @@ func (l *FtLogic) getFtUtxoFromDB(ctx context.Context, combineScript string, con
 	// 构造响应
 	response := &ft.TBC20FTUtxoResponse{
 		FtUtxoList: make([]*ft.TBC20FTUtxoItem, 0, len(utxos)),
+		TokenInfo:  units,
 	}
 
-	// 将DAO返回的数据转换为API响应格式
+	// 将DAO返回的数据转换为API响应格式，查询条件限定了合约，所有UTXO使用同一份单位信息
 	for _, utxo := range utxos {
-		// 获取代币小数位数
-		ftDecimal, err := l.ftTokensDAO.GetFtDecimalByContractId(ctx, utxo.FtContractId)
-		if err != nil {
-			log.ErrorWithContextf(ctx, "获取代币小数位数失败: %v", err)
-			return nil, fmt.Errorf("获取代币小数位数失败: %v", err)
-		}
 		utxoItem := &ft.TBC20FTUtxoItem{
 			UtxoId:       utxo.UtxoTxid,
 			UtxoVout:     utxo.UtxoVout,
 			UtxoBalance:  utxo.UtxoBalance,
 			FtContractId: utxo.FtContractId,
-			FtDecimal:    int(ftDecimal),
+			FtDecimal:    units.Decimals,
 			FtBalance:    utxo.FtBalance,
 		}
 		response.FtUtxoList = append(response.FtUtxoList, utxoItem)
@@ -0,0 +1,235 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusRunning   Status = "running"   // 运行中
+	StatusSucceeded Status = "succeeded" // 已完成
+	StatusFailed    Status = "failed"    // 失败
+)
+
+var (
+	// ErrTooManyJobs 运行中的任务数达到上限
+	ErrTooManyJobs = errors.New("运行中的任务过多，请稍后重试")
+	// ErrJobNotFound 任务不存在或已过期
+	ErrJobNotFound = errors.New("任务不存在或已过期")
+)
+
+// Snapshot 任务状态快照
+type Snapshot struct {
+	Id         string `json:"job_id"`
+	Kind       string `json:"kind"`
+	Status     Status `json:"status"`
+	Progress   int64  `json:"progress"`        // 已处理的条目数
+	Total      int64  `json:"total"`           // 需要处理的条目总数，未知时为0
+	Error      string `json:"error,omitempty"` // 失败原因
+	Result     any    `json:"result,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	FinishedAt int64  `json:"finished_at,omitempty"`
+}
+
+// Progress 任务执行过程中用于汇报进度
+type Progress struct {
+	id    string
+	done  atomic.Int64
+	total atomic.Int64
+}
+
+// JobId 返回当前任务的ID
+func (p *Progress) JobId() string {
+	return p.id
+}
+
+// SetTotal 设置需要处理的条目总数
+func (p *Progress) SetTotal(total int64) {
+	p.total.Store(total)
+}
+
+// Add 增加已处理的条目数
+func (p *Progress) Add(n int64) {
+	p.done.Add(n)
+}
+
+// Func 任务函数，返回值作为任务结果
+type Func func(ctx context.Context, progress *Progress) (any, error)
+
+type job struct {
+	snapshot Snapshot
+	progress Progress
+	cleanup  func(id string)
+}
+
+// Store 任务快照的共享存储，多个实例部署时任意实例都可以查询任务状态
+// 共享存储中只有提交和结束时的快照，运行中的进度只在执行任务的实例上更新
+type Store interface {
+	// Save 保存快照，ttl后过期
+	Save(ctx context.Context, snapshot Snapshot, ttl time.Duration) error
+	// Load 读取快照，不存在时返回false
+	Load(ctx context.Context, id string) (Snapshot, bool, error)
+}
+
+// Manager 进程内的异步任务管理器，任务结束后保留一段时间供查询
+type Manager struct {
+	mu         sync.Mutex
+	jobs       map[string]*job
+	running    int
+	maxRunning int
+	retention  time.Duration
+	store      Store
+}
+
+// NewManager 创建任务管理器，maxRunning为同时运行的任务数上限，retention为任务结束后的保留时间
+func NewManager(maxRunning int, retention time.Duration) *Manager {
+	if maxRunning <= 0 {
+		maxRunning = 1
+	}
+	return &Manager{
+		jobs:       make(map[string]*job),
+		maxRunning: maxRunning,
+		retention:  retention,
+	}
+}
+
+// SetStore 设置任务快照的共享存储，需在提交任务之前调用
+func (m *Manager) SetStore(store Store) {
+	m.store = store
+}
+
+// Submit 提交任务并立即在后台执行，任务不随请求取消；cleanup在任务过期移除时以任务ID调用，可以为空
+func (m *Manager) Submit(ctx context.Context, kind string, fn Func, cleanup func(id string)) (Snapshot, error) {
+	m.mu.Lock()
+	m.pruneLocked(time.Now())
+	if m.running >= m.maxRunning {
+		m.mu.Unlock()
+		return Snapshot{}, ErrTooManyJobs
+	}
+
+	id := newJobId()
+	j := &job{
+		snapshot: Snapshot{Id: id, Kind: kind, Status: StatusRunning, CreatedAt: time.Now().Unix()},
+		progress: Progress{id: id},
+		cleanup:  cleanup,
+	}
+	m.jobs[j.snapshot.Id] = j
+	m.running++
+	snapshot := m.snapshotLocked(j)
+	m.mu.Unlock()
+
+	m.save(ctx, snapshot)
+	go m.run(context.WithoutCancel(ctx), j, fn)
+	return snapshot, nil
+}
+
+// Get 获取任务状态
+func (m *Manager) Get(id string) (Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked(time.Now())
+	j, ok := m.jobs[id]
+	if !ok {
+		return Snapshot{}, ErrJobNotFound
+	}
+	return m.snapshotLocked(j), nil
+}
+
+// Lookup 获取任务状态，本实例中没有该任务时查询共享存储
+func (m *Manager) Lookup(ctx context.Context, id string) (Snapshot, error) {
+	snapshot, err := m.Get(id)
+	if err == nil || m.store == nil {
+		return snapshot, err
+	}
+
+	snapshot, ok, err := m.store.Load(ctx, id)
+	if err != nil {
+		log.WarnWithContext(ctx, "读取共享任务状态失败", "jobId:", id, "错误:", err)
+		return Snapshot{}, ErrJobNotFound
+	}
+	if !ok {
+		return Snapshot{}, ErrJobNotFound
+	}
+	return snapshot, nil
+}
+
+// save 将快照写入共享存储，写入失败只记录日志
+func (m *Manager) save(ctx context.Context, snapshot Snapshot) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.Save(ctx, snapshot, m.retention); err != nil {
+		log.WarnWithContext(ctx, "写入共享任务状态失败", "jobId:", snapshot.Id, "错误:", err)
+	}
+}
+
+// run 执行任务并记录结果，任务panic时按失败处理
+func (m *Manager) run(ctx context.Context, j *job, fn Func) {
+	var result any
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("任务异常: %v", r)
+			}
+		}()
+		result, err = fn(ctx, &j.progress)
+	}()
+
+	m.mu.Lock()
+	m.running--
+	j.snapshot.FinishedAt = time.Now().Unix()
+	if err != nil {
+		log.ErrorWithContext(ctx, "异步任务失败", "jobId:", j.snapshot.Id, "kind:", j.snapshot.Kind, "错误:", err)
+		j.snapshot.Status = StatusFailed
+		j.snapshot.Error = err.Error()
+	} else {
+		log.InfoWithContext(ctx, "异步任务完成", "jobId:", j.snapshot.Id, "kind:", j.snapshot.Kind)
+		j.snapshot.Status = StatusSucceeded
+		j.snapshot.Result = result
+	}
+	snapshot := m.snapshotLocked(j)
+	m.mu.Unlock()
+
+	m.save(ctx, snapshot)
+}
+
+// snapshotLocked 返回包含最新进度的快照，调用方需持有锁
+func (m *Manager) snapshotLocked(j *job) Snapshot {
+	snapshot := j.snapshot
+	snapshot.Progress = j.progress.done.Load()
+	snapshot.Total = j.progress.total.Load()
+	return snapshot
+}
+
+// pruneLocked 移除结束时间超过保留期的任务，调用方需持有锁
+func (m *Manager) pruneLocked(now time.Time) {
+	for id, j := range m.jobs {
+		if j.snapshot.FinishedAt == 0 || now.Sub(time.Unix(j.snapshot.FinishedAt, 0)) < m.retention {
+			continue
+		}
+		delete(m.jobs, id)
+		if j.cleanup != nil {
+			go j.cleanup(id)
+		}
+	}
+}
+
+// newJobId 生成随机的任务ID
+func newJobId() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
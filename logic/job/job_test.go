@@ -0,0 +1,157 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// wait 等待任务结束并返回最终快照
+func wait(t *testing.T, m *Manager, id string) Snapshot {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		snapshot, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("查询任务失败: %v", err)
+		}
+		if snapshot.Status != StatusRunning {
+			return snapshot
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("任务%s未在期限内结束", id)
+	return Snapshot{}
+}
+
+func TestManagerSubmit(t *testing.T) {
+	m := NewManager(2, time.Minute)
+	ctx := context.Background()
+
+	ok, err := m.Submit(ctx, "test", func(ctx context.Context, p *Progress) (any, error) {
+		p.SetTotal(3)
+		p.Add(3)
+		return p.JobId(), nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+	snapshot := wait(t, m, ok.Id)
+	if snapshot.Status != StatusSucceeded || snapshot.Result != ok.Id || snapshot.Progress != 3 || snapshot.Total != 3 {
+		t.Fatalf("成功任务快照不一致: %+v", snapshot)
+	}
+
+	failed, _ := m.Submit(ctx, "test", func(context.Context, *Progress) (any, error) {
+		panic("boom")
+	}, nil)
+	if snapshot := wait(t, m, failed.Id); snapshot.Status != StatusFailed || snapshot.Error == "" {
+		t.Fatalf("panic任务应标记为失败: %+v", snapshot)
+	}
+
+	if _, err := m.Get("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("期望任务不存在错误，实际: %v", err)
+	}
+}
+
+func TestManagerLimitAndPrune(t *testing.T) {
+	m := NewManager(1, 0)
+	ctx := context.Background()
+	release := make(chan struct{})
+
+	blocked, _ := m.Submit(ctx, "test", func(context.Context, *Progress) (any, error) {
+		<-release
+		return nil, nil
+	}, nil)
+	if _, err := m.Submit(ctx, "test", nil, nil); !errors.Is(err, ErrTooManyJobs) {
+		t.Fatalf("期望任务数超限错误，实际: %v", err)
+	}
+	close(release)
+
+	// 等待任务结束后保留期为0，下一次查询即被移除并触发清理
+	deadline := time.Now().Add(time.Second)
+	cleaned := make(chan string, 1)
+	for {
+		m.mu.Lock()
+		j := m.jobs[blocked.Id]
+		if j != nil && j.snapshot.FinishedAt != 0 {
+			j.cleanup = func(id string) { cleaned <- id }
+			j.snapshot.FinishedAt = time.Now().Add(-time.Second).Unix()
+			m.mu.Unlock()
+			break
+		}
+		m.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatal("任务未在期限内结束")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := m.Get(blocked.Id); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("过期任务应被移除，实际: %v", err)
+	}
+	select {
+	case id := <-cleaned:
+		if id != blocked.Id {
+			t.Fatalf("清理的任务ID不一致: %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("过期任务未触发清理")
+	}
+}
+
+// mapStore 测试用的内存共享存储
+type mapStore struct {
+	mu        sync.Mutex
+	snapshots map[string]Snapshot
+}
+
+func (s *mapStore) Save(_ context.Context, snapshot Snapshot, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[snapshot.Id] = snapshot
+	return nil
+}
+
+func (s *mapStore) Load(_ context.Context, id string) (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, ok := s.snapshots[id]
+	return snapshot, ok, nil
+}
+
+func TestManagerLookupSharedStore(t *testing.T) {
+	store := &mapStore{snapshots: make(map[string]Snapshot)}
+	owner, other := NewManager(1, time.Minute), NewManager(1, time.Minute)
+	owner.SetStore(store)
+	other.SetStore(store)
+	ctx := context.Background()
+
+	submitted, err := owner.Submit(ctx, "test", func(context.Context, *Progress) (any, error) {
+		return "done", nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+
+	// 其它实例从共享存储读取到任务结束时的快照
+	deadline := time.Now().Add(time.Second)
+	for {
+		snapshot, err := other.Lookup(ctx, submitted.Id)
+		if err != nil {
+			t.Fatalf("查询共享任务失败: %v", err)
+		}
+		if snapshot.Status == StatusSucceeded && snapshot.Result == "done" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("共享存储中的任务未结束: %+v", snapshot)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := other.Lookup(ctx, "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("不存在的任务应返回ErrJobNotFound: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"ginproject/repo/cache"
+)
+
+// RedisStore 基于Redis的任务快照存储
+type RedisStore struct {
+	redis        *cache.Redis
+	decodeResult func(data []byte) (any, error)
+}
+
+// NewRedisStore 创建Redis任务快照存储，decodeResult将任务结果的JSON解码为提交任务时的结果类型
+func NewRedisStore(redis *cache.Redis, decodeResult func(data []byte) (any, error)) *RedisStore {
+	return &RedisStore{redis: redis, decodeResult: decodeResult}
+}
+
+// Save 保存快照，ttl后过期
+func (s *RedisStore) Save(ctx context.Context, snapshot Snapshot, ttl time.Duration) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(ctx, s.key(snapshot.Id), data, ttl)
+}
+
+// Load 读取快照，不存在时返回false
+func (s *RedisStore) Load(ctx context.Context, id string) (Snapshot, bool, error) {
+	data, ok, err := s.redis.Get(ctx, s.key(id))
+	if err != nil || !ok {
+		return Snapshot{}, false, err
+	}
+
+	var stored struct {
+		Snapshot
+		Result json.RawMessage `json:"result,omitempty"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Snapshot{}, false, err
+	}
+	snapshot := stored.Snapshot
+	if len(stored.Result) > 0 && s.decodeResult != nil {
+		if snapshot.Result, err = s.decodeResult(stored.Result); err != nil {
+			return Snapshot{}, false, err
+		}
+	}
+	return snapshot, true, nil
+}
+
+// key 返回快照在Redis中的键名
+func (s *RedisStore) key(id string) string {
+	return "job:" + id
+}
@@ -0,0 +1,102 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"ginproject/entity/mempool"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/rpc/blockchain"
+	"ginproject/repo/rpc/electrumx"
+)
+
+// 费率估算只在出块或内存池明显变化时改变，短时间缓存以合并钱包发送交易前的查询
+const feeEstimateCacheTTL = 30 * time.Second
+
+var feeEstimateCache = cache.NewLRU[int, *mempool.FeeEstimateResponse](64, feeEstimateCacheTTL)
+
+func init() {
+	cache.Register("mempool_fee_estimate", feeEstimateCache)
+	// 出块后内存池随之变化
+	cache.RegisterBlockScoped("mempool_fee_estimate", feeEstimateCache)
+}
+
+// EstimateFee 估算固定几个确认目标和请求目标的费率，节点无法估算的目标改用ElectrumX，都无法估算时使用内存池最低费率
+// 推荐费率按内存池积压调整，积压超过目标区块数的容量时提高到能在目标内被打包的费率，请求需已通过验证
+func EstimateFee(ctx context.Context, req *mempool.FeeEstimateRequest) (*mempool.FeeEstimateResponse, error) {
+	if resp, ok := feeEstimateCache.Get(req.Blocks); ok {
+		return resp, nil
+	}
+
+	targets := slices.Clone(mempool.FeeEstimateHorizons)
+	if !slices.Contains(targets, req.Blocks) {
+		targets = append(targets, req.Blocks)
+		slices.Sort(targets)
+	}
+
+	infoChan := blockchain.FetchMemPoolInfo(ctx)
+	nodeChan := blockchain.FetchSmartFees(ctx, targets)
+	histogram, err := GetFeeHistogram(ctx)
+	if err != nil {
+		log.WarnWithContext(ctx, "获取费率直方图失败，推荐费率不按积压调整", "error", err)
+	}
+
+	var minFeeRate float64
+	if result := <-infoChan; result.Error != nil {
+		log.WarnWithContext(ctx, "获取内存池最低费率失败", "error", result.Error)
+	} else if info, ok := result.Result.(*mempool.NodeMempoolInfo); ok {
+		minFeeRate = mempool.FeeRateFromCoinPerKB(info.MempoolMinFee)
+	}
+
+	nodeFees := map[int]float64{}
+	if result := <-nodeChan; result.Error != nil {
+		log.WarnWithContext(ctx, "节点估算费率失败，改用ElectrumX", "error", result.Error)
+	} else {
+		nodeFees, _ = result.Result.(map[int]float64)
+	}
+
+	// 节点无法估算的目标并发向ElectrumX查询
+	electrumXFees := make(map[int]float64)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, blocks := range targets {
+		if nodeFees[blocks] > 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(blocks int) {
+			defer wg.Done()
+			fee, err := electrumx.EstimateFee(ctx, blocks)
+			if err != nil {
+				log.WarnWithContext(ctx, "ElectrumX估算费率失败", "blocks", blocks, "error", err)
+				return
+			}
+			mu.Lock()
+			electrumXFees[blocks] = fee
+			mu.Unlock()
+		}(blocks)
+	}
+	wg.Wait()
+
+	feeTargets := make([]mempool.FeeTarget, 0, len(targets))
+	estimated := false
+	for _, blocks := range targets {
+		target := mempool.PickFeeTarget(blocks,
+			mempool.FeeRateFromCoinPerKB(nodeFees[blocks]),
+			mempool.FeeRateFromCoinPerKB(electrumXFees[blocks]),
+			minFeeRate)
+		estimated = estimated || target.FeeRate > 0
+		feeTargets = append(feeTargets, target)
+	}
+	if !estimated {
+		return nil, fmt.Errorf("节点和ElectrumX均无法估算费率")
+	}
+
+	resp := mempool.NewFeeEstimateResponse(req.Blocks, feeTargets, minFeeRate, histogram)
+	feeEstimateCache.Set(req.Blocks, resp)
+	return resp, nil
+}
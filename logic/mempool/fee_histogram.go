@@ -0,0 +1,78 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"ginproject/entity/mempool"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/rpc/blockchain"
+	"ginproject/repo/rpc/electrumx"
+)
+
+// 直方图随内存池变化，只做短时间缓存以合并钱包的高频轮询
+const feeHistogramCacheTTL = 10 * time.Second
+
+// 节点返回的手续费单位为TBC，1 TBC = 1000000 聪
+const satoshisPerCoin = 1000000
+
+var feeHistogramCache = cache.NewLRU[string, *mempool.FeeHistogramResponse](1, feeHistogramCacheTTL)
+
+func init() {
+	cache.Register("mempool_fee_histogram", feeHistogramCache)
+	// 出块后内存池随之变化
+	cache.RegisterBlockScoped("mempool_fee_histogram", feeHistogramCache)
+}
+
+// GetFeeHistogram 获取内存池费率直方图，优先使用ElectrumX，失败时由节点的详细内存池计算
+func GetFeeHistogram(ctx context.Context) (*mempool.FeeHistogramResponse, error) {
+	if resp, ok := feeHistogramCache.Get("histogram"); ok {
+		return resp, nil
+	}
+
+	var resp *mempool.FeeHistogramResponse
+	histogram, err := electrumx.GetFeeHistogram(ctx)
+	if err == nil {
+		resp = mempool.NewFeeHistogramResponse(mempool.FeeHistogramSourceElectrumX, histogram)
+	} else {
+		log.WarnWithContext(ctx, "从ElectrumX获取费率直方图失败，改由节点内存池计算", "error", err)
+		entries, nodeErr := fetchNodeFeeEntries(ctx)
+		if nodeErr != nil {
+			return nil, fmt.Errorf("获取费率直方图失败: %w", nodeErr)
+		}
+		resp = mempool.NewFeeHistogramResponse(mempool.FeeHistogramSourceNode, mempool.BuildFeeHistogram(entries))
+	}
+
+	feeHistogramCache.Set("histogram", resp)
+	return resp, nil
+}
+
+// fetchNodeFeeEntries 从节点的详细内存池中提取每笔交易的费率和大小
+func fetchNodeFeeEntries(ctx context.Context) ([]mempool.MempoolFeeEntry, error) {
+	result := <-blockchain.FetchVerboseMemPool(ctx)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	txs, _ := result.Result.(map[string]interface{})
+	entries := make([]mempool.MempoolFeeEntry, 0, len(txs))
+	for _, raw := range txs {
+		tx, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		size, _ := tx["size"].(float64)
+		fee, _ := tx["fee"].(float64)
+		if size <= 0 {
+			continue
+		}
+		entries = append(entries, mempool.MempoolFeeEntry{
+			FeeRate: math.Round(fee*satoshisPerCoin) / size,
+			VSize:   int64(size),
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,67 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/mempool"
+	txlogic "ginproject/logic/transaction"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// GetMempoolInfo 获取内存池交易数量、大小和费率直方图，直方图获取失败时只返回概况
+func GetMempoolInfo(ctx context.Context) (*mempool.MempoolInfoResponse, error) {
+	result := <-blockchain.FetchMemPoolInfo(ctx)
+	if result.Error != nil {
+		return nil, fmt.Errorf("获取内存池概况失败: %w", result.Error)
+	}
+	info, ok := result.Result.(*mempool.NodeMempoolInfo)
+	if !ok {
+		return nil, fmt.Errorf("内存池概况结果类型错误: %T", result.Result)
+	}
+
+	histogram, err := GetFeeHistogram(ctx)
+	if err != nil {
+		log.WarnWithContext(ctx, "获取费率直方图失败，内存池概况中省略", "error", err)
+	}
+	return mempool.NewMempoolInfoResponse(info, histogram), nil
+}
+
+// GetMempoolTx 获取内存池中未确认交易的解码结果、手续费和祖先交易
+// 交易不在内存池中（未广播或已确认）时返回包装db.ErrTxNotFound的错误
+func GetMempoolTx(ctx context.Context, txid string) (*mempool.MempoolTxResponse, error) {
+	entryChan := blockchain.FetchMemPoolEntry(ctx, txid)
+	ancestorsChan := blockchain.FetchMemPoolAncestors(ctx, txid)
+
+	entryResult := <-entryChan
+	if entryResult.Error != nil {
+		<-ancestorsChan
+		if db.IsNotFound(entryResult.Error) {
+			return nil, fmt.Errorf("内存池中%w: %s", db.ErrTxNotFound, txid)
+		}
+		return nil, fmt.Errorf("获取内存池条目失败: %w", entryResult.Error)
+	}
+	entry, ok := entryResult.Result.(*mempool.NodeMempoolEntry)
+	if !ok {
+		<-ancestorsChan
+		return nil, fmt.Errorf("内存池条目结果类型错误: %T", entryResult.Result)
+	}
+
+	tx, _, err := txlogic.DecodeTxByHash(ctx, txid)
+	if err != nil {
+		<-ancestorsChan
+		return nil, err
+	}
+
+	// 祖先列表只是补充信息，查询失败时由depends和ancestor_count说明依赖关系
+	var ancestors []string
+	if ancestorsResult := <-ancestorsChan; ancestorsResult.Error != nil {
+		log.WarnWithContext(ctx, "获取内存池祖先交易失败", "txid", txid, "error", ancestorsResult.Error)
+	} else {
+		ancestors, _ = ancestorsResult.Result.([]string)
+	}
+
+	return mempool.NewMempoolTxResponse(tx, entry, ancestors), nil
+}
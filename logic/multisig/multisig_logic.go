@@ -11,6 +11,7 @@ import (
 	"ginproject/middleware/log"
 	"ginproject/repo/rpc/blockchain"
 	"ginproject/repo/rpc/electrumx"
+	"ginproject/repo/scripthash"
 )
 
 // 错误定义
@@ -32,7 +33,7 @@ func QueryMultiWalletByAddress(ctx context.Context, address string) (*multisig.M
 	log.InfoWithContext(ctx, "开始查询多签名地址", "address", address)
 
 	// 将地址转换为多签名脚本哈希
-	scriptHash, err := utility.ConvertAddressToMultiSigScriptHash(address)
+	scriptHash, err := scripthash.Resolve(ctx, scripthash.KindMultiSig, address)
 	if err != nil {
 		log.ErrorWithContext(ctx, "地址转换为多签名脚本哈希失败", "address", address, "error", err)
 		return nil, fmt.Errorf("地址转换为多签名脚本哈希失败: %w", err)
@@ -8,13 +8,16 @@ import (
 	"time"
 
 	entityblockchain "ginproject/entity/blockchain"
+	electrumxEntity "ginproject/entity/electrumx"
 	"ginproject/entity/nft"
 	"ginproject/entity/utility"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
 	nft_collections_dao "ginproject/repo/db/nft_collections_dao"
 	nft_utxo_set_dao "ginproject/repo/db/nft_utxo_set_dao"
 	rpcblockchain "ginproject/repo/rpc/blockchain"
 	"ginproject/repo/rpc/electrumx"
+	"ginproject/repo/scripthash"
 )
 
 // NFTLogic NFT业务逻辑结构体
@@ -52,6 +55,7 @@ func (logic *NFTLogic) GetCollectionByAddressPageSize(ctx context.Context, addre
 	response := &nft.CollectionListResponse{
 		CollectionCount: int(total),
 		CollectionList:  make([]nft.CollectionItem, 0, len(collections)),
+		Meta:            utility.NewPageMeta(page, size, total),
 	}
 
 	// 转换数据格式
@@ -74,8 +78,9 @@ func (logic *NFTLogic) GetCollectionByAddressPageSize(ctx context.Context, addre
 	return response, nil
 }
 
-// GetNftByAddressPageSize 根据地址、页码和每页大小获取NFT列表
-func (logic *NFTLogic) GetNftByAddressPageSize(ctx context.Context, address string, page, size int, ifExtraCollectionInfo bool) (*nft.NftListResponse, error) {
+// GetNftByAddressPageSize 根据地址、页码和每页大小获取NFT列表，minConfirmations大于0时只返回确认数足够的NFT
+func (logic *NFTLogic) GetNftByAddressPageSize(ctx context.Context, address string, page, size int, ifExtraCollectionInfo bool,
+	minConfirmations int64) (*nft.NftListResponse, error) {
 	// 参数校验
 	if err := nft.ValidateGetNftByAddressPageSize(address, page, size, ifExtraCollectionInfo); err != nil {
 		log.ErrorWithContext(ctx, "参数校验失败:", err)
@@ -92,8 +97,14 @@ func (logic *NFTLogic) GetNftByAddressPageSize(ctx context.Context, address stri
 		return nil, fmt.Errorf("地址转换失败: %v", err)
 	}
 
+	maxHeight, err := chaintip.HeightLimit(ctx, minConfirmations)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "计算确认高度上限失败: %v", err)
+		return nil, err
+	}
+
 	// 从数据库获取NFT数据
-	nfts, total, err := logic.utxoSetDAO.GetNftsByHolderWithPagination(ctx, nftScriptHash, page, size)
+	nfts, total, err := logic.utxoSetDAO.GetNftsByHolderWithPagination(ctx, nftScriptHash, page, size, maxHeight)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取地址[%s]的NFT列表失败: %v", address, err)
 		return nil, fmt.Errorf("获取NFT列表失败: %v", err)
@@ -103,6 +114,7 @@ func (logic *NFTLogic) GetNftByAddressPageSize(ctx context.Context, address stri
 	response := &nft.NftListResponse{
 		NftTotalCount: int(total),
 		NftList:       make([]nft.NftItem, 0, len(nfts)),
+		Meta:          utility.NewPageMeta(page, size, total),
 	}
 
 	// 转换数据格式
@@ -146,8 +158,9 @@ func (logic *NFTLogic) GetNftByAddressPageSize(ctx context.Context, address stri
 	return response, nil
 }
 
-// GetNftByScriptHashPageSize 根据脚本哈希、页码和每页大小获取NFT列表
-func (logic *NFTLogic) GetNftByScriptHashPageSize(ctx context.Context, scriptHash string, page, size int) (*nft.NftListResponse, error) {
+// GetNftByScriptHashPageSize 根据脚本哈希、页码和每页大小获取NFT列表，minConfirmations大于0时只返回确认数足够的NFT
+func (logic *NFTLogic) GetNftByScriptHashPageSize(ctx context.Context, scriptHash string, page, size int,
+	minConfirmations int64) (*nft.NftListResponse, error) {
 	// 参数校验
 	if err := nft.ValidateGetNftByScriptHashPageSize(scriptHash, page, size); err != nil {
 		log.ErrorWithContext(ctx, "参数校验失败:", err)
@@ -156,12 +169,19 @@ func (logic *NFTLogic) GetNftByScriptHashPageSize(ctx context.Context, scriptHas
 
 	log.InfoWithContextf(ctx, "开始获取脚本哈希[%s]的NFT列表，页码: %d, 每页大小: %d", scriptHash, page, size)
 
-	// 获取未花费交易输出
+	maxHeight, err := chaintip.HeightLimit(ctx, minConfirmations)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "计算确认高度上限失败: %v", err)
+		return nil, err
+	}
+
+	// 获取未花费交易输出，分页前按高度上限过滤
 	unspents, err := electrumx.GetUnspent(ctx, scriptHash)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取脚本哈希[%s]的未花费交易失败: %v", scriptHash, err)
 		return nil, fmt.Errorf("获取未花费交易失败: %v", err)
 	}
+	unspents = electrumxEntity.FilterUtxosByHeight(unspents, maxHeight)
 
 	// 计算总数和分页范围
 	nftTotalCount := len(unspents)
@@ -174,6 +194,7 @@ func (logic *NFTLogic) GetNftByScriptHashPageSize(ctx context.Context, scriptHas
 		return &nft.NftListResponse{
 			NftTotalCount: nftTotalCount,
 			NftList:       []nft.NftItem{},
+			Meta:          utility.NewPageMeta(page, size, int64(nftTotalCount)),
 		}, nil
 	}
 
@@ -189,6 +210,7 @@ func (logic *NFTLogic) GetNftByScriptHashPageSize(ctx context.Context, scriptHas
 	response := &nft.NftListResponse{
 		NftTotalCount: nftTotalCount,
 		NftList:       make([]nft.NftItem, 0, len(pageUnspents)),
+		Meta:          utility.NewPageMeta(page, size, int64(nftTotalCount)),
 	}
 
 	// 遍历并获取详细信息
@@ -233,18 +255,18 @@ func (logic *NFTLogic) GetNftByScriptHashPageSize(ctx context.Context, scriptHas
 	return response, nil
 }
 
-// GetNftByCollectionIdPageSize 根据集合ID、页码和每页大小获取NFT列表
-func (logic *NFTLogic) GetNftByCollectionIdPageSize(ctx context.Context, collectionId string, page, size int) (*nft.NftListResponse, error) {
+// GetNftByCollectionIdPageSize 根据集合ID、页码和每页大小获取NFT列表，按sort指定的字段和方向排序
+func (logic *NFTLogic) GetNftByCollectionIdPageSize(ctx context.Context, collectionId string, sort nft.NftSort, page, size int) (*nft.NftListResponse, error) {
 	// 参数校验
 	if err := nft.ValidateGetNftByCollectionIdPageSize(collectionId, page, size); err != nil {
 		log.ErrorWithContext(ctx, "参数校验失败:", err)
 		return nil, err
 	}
 
-	log.InfoWithContextf(ctx, "开始获取集合[%s]的NFT列表，页码: %d, 每页大小: %d", collectionId, page, size)
+	log.InfoWithContextf(ctx, "开始获取集合[%s]的NFT列表，页码: %d, 每页大小: %d, 排序: %s, 降序: %v", collectionId, page, size, sort.Field, sort.Desc)
 
 	// 使用DAO层方法获取数据
-	nfts, total, err := logic.utxoSetDAO.GetNftsByCollectionIdWithPagination(ctx, collectionId, page, size)
+	nfts, total, err := logic.utxoSetDAO.GetNftsByCollectionIdWithPagination(ctx, collectionId, sort, page, size)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取集合[%s]的NFT列表失败: %v", collectionId, err)
 		return nil, fmt.Errorf("获取NFT列表失败: %v", err)
@@ -254,6 +276,7 @@ func (logic *NFTLogic) GetNftByCollectionIdPageSize(ctx context.Context, collect
 	response := &nft.NftListResponse{
 		NftTotalCount: int(total),
 		NftList:       make([]nft.NftItem, 0, len(nfts)),
+		Meta:          utility.NewPageMeta(page, size, total),
 	}
 
 	// 转换数据格式
@@ -304,6 +327,7 @@ func (logic *NFTLogic) GetCollectionsByPageSize(ctx context.Context, page, size
 	response := &nft.CollectionListResponse{
 		CollectionCount: int(total),
 		CollectionList:  make([]nft.CollectionItem, 0, len(collections)),
+		Meta:            utility.NewPageMeta(page, size, total),
 	}
 
 	// 转换数据格式
@@ -396,7 +420,7 @@ func (logic *NFTLogic) GetDetailCollectionInfo(ctx context.Context, collectionId
 	collection, err := logic.collectionsDAO.GetDetailCollectionInfo(ctx, collectionId)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取集合[%s]的详细信息失败: %v", collectionId, err)
-		return nil, fmt.Errorf("获取集合详情失败: %v", err)
+		return nil, fmt.Errorf("获取集合详情失败: %w", err)
 	}
 
 	// 如果集合不存在，返回空结果
@@ -425,10 +449,13 @@ func (logic *NFTLogic) GetDetailCollectionInfo(ctx context.Context, collectionId
 
 // convertAddressToNftScriptHash 将地址转换为NFT脚本哈希
 func convertAddressToNftScriptHash(ctx context.Context, address string, isCollection bool) (string, error) {
-	// 使用utility包中已定义的函数进行转换
 	log.DebugWithContextf(ctx, "开始将地址[%s]转换为NFT脚本哈希，isCollection=%v", address, isCollection)
 
-	scriptHash, err := utility.ConvertAddressToNftScriptHash(address, isCollection)
+	kind := scripthash.KindNftHolder
+	if isCollection {
+		kind = scripthash.KindNftCollection
+	}
+	scriptHash, err := scripthash.Resolve(ctx, kind, address)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "将地址[%s]转换为NFT脚本哈希失败: %v", address, err)
 		return "", err
@@ -439,14 +466,19 @@ func convertAddressToNftScriptHash(ctx context.Context, address string, isCollec
 }
 
 // GetNftHistoryByAddress 获取地址的NFT交易历史记录
-func (logic *NFTLogic) GetNftHistoryByAddress(ctx context.Context, address string, page, size int) (*nft.NftHistoryResponse, error) {
+// fromHeight大于0时只返回该高度及之后的交易
+func (logic *NFTLogic) GetNftHistoryByAddress(ctx context.Context, address string, page, size int, fromHeight int64) (*nft.NftHistoryResponse, error) {
 	// 参数校验
 	if err := nft.ValidateNftHistory(address, page, size); err != nil {
 		log.ErrorWithContext(ctx, "参数校验失败:", err)
 		return nil, err
 	}
+	if fromHeight < 0 {
+		log.ErrorWithContext(ctx, "参数校验失败:", nft.ErrInvalidFromHeight)
+		return nil, nft.ErrInvalidFromHeight
+	}
 
-	log.InfoWithContextf(ctx, "开始获取地址[%s]的NFT历史记录，页码: %d, 每页大小: %d", address, page, size)
+	log.InfoWithContextf(ctx, "开始获取地址[%s]的NFT历史记录，页码: %d, 每页大小: %d, 起始高度: %d", address, page, size, fromHeight)
 
 	// 将地址转换为NFT脚本哈希
 	nftScriptHash, err := convertAddressToNftScriptHash(ctx, address, false)
@@ -456,7 +488,7 @@ func (logic *NFTLogic) GetNftHistoryByAddress(ctx context.Context, address strin
 	}
 
 	// 获取交易历史记录
-	history, err := electrumx.GetScriptHashHistory(ctx, nftScriptHash)
+	history, err := electrumx.GetScriptHashHistoryFrom(ctx, nftScriptHash, fromHeight, 0)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "获取地址[%s]的交易历史记录失败: %v", address, err)
 		return nil, fmt.Errorf("获取交易历史失败: %v", err)
@@ -482,6 +514,7 @@ func (logic *NFTLogic) GetNftHistoryByAddress(ctx context.Context, address strin
 			ScriptHash:   nftScriptHash,
 			HistoryCount: historyCount,
 			Result:       []nft.NftHistoryItem{},
+			Meta:         utility.NewPageMeta(page, size, int64(historyCount)),
 		}, nil
 	}
 
@@ -649,8 +682,58 @@ func (logic *NFTLogic) GetNftHistoryByAddress(ctx context.Context, address strin
 		ScriptHash:   nftScriptHash,
 		HistoryCount: historyCount,
 		Result:       historyItems,
+		Meta:         utility.NewPageMeta(page, size, int64(historyCount)),
 	}
 
 	log.InfoWithContextf(ctx, "成功获取地址[%s]的NFT历史记录，共%d条记录", address, historyCount)
 	return response, nil
 }
+
+// GetNftPortfolioByAddress 获取地址按集合分组的NFT持仓汇总
+func (logic *NFTLogic) GetNftPortfolioByAddress(ctx context.Context, address string) (*nft.NftPortfolioResponse, error) {
+	// 参数校验
+	if err := nft.ValidateNftPortfolio(address); err != nil {
+		log.ErrorWithContext(ctx, "参数校验失败:", err)
+		return nil, err
+	}
+
+	log.InfoWithContextf(ctx, "开始获取地址[%s]的NFT持仓汇总", address)
+
+	// 将地址转换为NFT脚本哈希
+	nftScriptHash, err := convertAddressToNftScriptHash(ctx, address, false)
+	if err != nil {
+		return nil, fmt.Errorf("地址转换失败: %w", err)
+	}
+
+	// 按集合分组聚合，避免分页拉取完整NFT列表
+	holdings, err := logic.utxoSetDAO.GetCollectionHoldingsByHolder(ctx, nftScriptHash)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取地址[%s]的NFT持仓汇总失败: %v", address, err)
+		return nil, fmt.Errorf("获取NFT持仓汇总失败: %w", err)
+	}
+
+	response := &nft.NftPortfolioResponse{
+		Address:         address,
+		CollectionCount: len(holdings),
+		Collections:     make([]nft.NftCollectionHolding, 0, len(holdings)),
+	}
+
+	for _, holding := range holdings {
+		response.NftTotalCount += holding.NftCount
+		if holding.LastTransferTimestamp > response.LastActivityTimestamp {
+			response.LastActivityTimestamp = holding.LastTransferTimestamp
+		}
+		response.Collections = append(response.Collections, nft.NftCollectionHolding{
+			CollectionId:          holding.CollectionId,
+			CollectionName:        holding.CollectionName,
+			NftCount:              holding.NftCount,
+			LastActivityTimestamp: holding.LastTransferTimestamp,
+			FirstCreateTimestamp:  holding.FirstCreateTimestamp,
+			TotalTransferCount:    holding.TotalTransferCount,
+		})
+	}
+
+	log.InfoWithContextf(ctx, "成功获取地址[%s]的NFT持仓汇总，集合数: %d, NFT总数: %d",
+		address, response.CollectionCount, response.NftTotalCount)
+	return response, nil
+}
@@ -0,0 +1,142 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/nft"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/db/nft_attribute_dao"
+)
+
+const (
+	// 按集合名称或创建者匹配时最多使用的集合数量，避免IN列表过长
+	maxSearchCollections = 1000
+	// 属性索引任务每批读取的NFT数量
+	attributeIndexBatchSize = 500
+)
+
+// SearchNfts 按集合名称、NFT名称、集合创建者和属性键值搜索NFT，多个条件同时满足
+func (logic *NFTLogic) SearchNfts(ctx context.Context, req *nft.NftSearchRequest) (*nft.NftListResponse, error) {
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContext(ctx, "参数校验失败:", err)
+		return nil, err
+	}
+
+	filter := nft.NftSearchFilter{Name: req.Name, Attributes: req.Attributes}
+	if req.Collection != "" || req.Creator != "" {
+		collectionIds, err := logic.collectionsDAO.SearchCollectionIds(ctx, req.Collection, req.Creator, maxSearchCollections)
+		if err != nil {
+			log.ErrorWithContextf(ctx, "搜索NFT集合失败: %v", err)
+			return nil, fmt.Errorf("搜索NFT集合失败: %v", err)
+		}
+		filter.CollectionIds = collectionIds
+	}
+
+	nfts, total, err := logic.utxoSetDAO.SearchNfts(ctx, filter, req.Page, req.Size)
+	if err != nil {
+		return nil, fmt.Errorf("搜索NFT失败: %v", err)
+	}
+
+	response := &nft.NftListResponse{
+		NftTotalCount: int(total),
+		NftList:       make([]nft.NftItem, 0, len(nfts)),
+		Meta:          utility.NewPageMeta(req.Page, req.Size, total),
+	}
+	for _, nftInfo := range nfts {
+		response.NftList = append(response.NftList, nft.NftItem{
+			CollectionId:         nftInfo.CollectionId,
+			CollectionIndex:      nftInfo.CollectionIndex,
+			CollectionName:       nftInfo.CollectionName,
+			NftContractId:        nftInfo.NftContractId,
+			NftUtxoId:            nftInfo.NftUtxoId,
+			NftCodeBalance:       nftInfo.NftCodeBalance,
+			NftP2pkhBalance:      nftInfo.NftP2pkhBalance,
+			NftName:              nftInfo.NftName,
+			NftSymbol:            nftInfo.NftSymbol,
+			NftAttributes:        nftInfo.NftAttributes,
+			NftDescription:       nftInfo.NftDescription,
+			NftTransferTimeCount: nftInfo.NftTransferTimeCount,
+			NftHolder:            nftInfo.NftHolderAddress,
+			NftCreateTimestamp:   nftInfo.NftCreateTimestamp,
+			NftIcon:              nftInfo.NftIcon,
+		})
+	}
+
+	log.InfoWithContextf(ctx, "搜索NFT完成，总数: %d, 当前页: %d, 每页大小: %d", total, req.Page, req.Size)
+	return response, nil
+}
+
+// 属性索引的断点，没有属性的NFT不写入索引表，断点保存在内存中避免重复扫描
+var attributeIndexCursor struct {
+	sync.Mutex
+	loaded     bool
+	timestamp  int
+	contractId string
+}
+
+// IndexNftAttributes 按创建时间增量解析NFT的JSON属性写入属性索引表，由周期任务调用
+// 进程内首次运行时从索引表中最大的创建时间继续，同一时间戳的NFT可能只索引了一部分，从该时间戳开始重新扫描，重复记录写入时忽略
+func IndexNftAttributes(ctx context.Context) error {
+	attributeIndexCursor.Lock()
+	defer attributeIndexCursor.Unlock()
+
+	if !attributeIndexCursor.loaded {
+		timestamp, err := nft_attribute_dao.GetAttributeIndexCursor(ctx)
+		if err != nil {
+			return err
+		}
+		attributeIndexCursor.timestamp, attributeIndexCursor.contractId, attributeIndexCursor.loaded = timestamp, "", true
+	}
+
+	var err error
+	attributeIndexCursor.timestamp, attributeIndexCursor.contractId, err = NewNFTLogic().indexNftAttributes(ctx,
+		attributeIndexCursor.timestamp, attributeIndexCursor.contractId)
+	return err
+}
+
+// indexNftAttributes 从断点开始分批索引NFT属性直到没有新的NFT，返回新的断点；出错时返回出错前的断点和错误，下次运行时重试
+func (logic *NFTLogic) indexNftAttributes(ctx context.Context, timestamp int, contractId string) (int, string, error) {
+	count := 0
+	defer func() {
+		if count > 0 {
+			log.InfoWithContext(ctx, "NFT属性索引完成", "NFT数:", count)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		nfts, err := logic.utxoSetDAO.GetNftAttributesAfter(ctx, timestamp, contractId, attributeIndexBatchSize)
+		if err != nil {
+			return timestamp, contractId, err
+		}
+		if len(nfts) == 0 {
+			return timestamp, contractId, nil
+		}
+
+		rows := make([]*dbtable.NftAttributeIndex, 0, len(nfts))
+		for _, item := range nfts {
+			for _, attr := range nft.ParseNftAttributes(item.NftAttributes) {
+				rows = append(rows, &dbtable.NftAttributeIndex{
+					NftContractId:      item.NftContractId,
+					AttrKey:            attr.Key,
+					AttrValue:          attr.Value,
+					NftCreateTimestamp: item.NftCreateTimestamp,
+				})
+			}
+		}
+		if err := nft_attribute_dao.InsertNftAttributes(ctx, rows); err != nil {
+			return timestamp, contractId, err
+		}
+
+		last := nfts[len(nfts)-1]
+		timestamp, contractId = last.NftCreateTimestamp, last.NftContractId
+		count += len(nfts)
+		if len(nfts) < attributeIndexBatchSize {
+			return timestamp, contractId, nil
+		}
+	}
+	return timestamp, contractId, ctx.Err()
+}
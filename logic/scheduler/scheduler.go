@@ -0,0 +1,249 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	schedulerEntity "ginproject/entity/scheduler"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/scheduler_dao"
+)
+
+const (
+	// 检查任务是否到期的周期，任务的实际运行周期不会短于该值
+	checkInterval = 15 * time.Second
+	// 未配置时单次运行的最长时间
+	defaultLockTimeout = 10 * time.Minute
+)
+
+// Job 周期任务
+type Job struct {
+	Name     string                          // 任务名称，也是配置中覆盖周期使用的键
+	Interval time.Duration                   // 默认运行周期
+	Local    bool                            // 每个实例各自运行，用于刷新进程内缓存，不抢占数据库锁
+	Run      func(ctx context.Context) error // 任务函数，ctx在超过单次运行的最长时间后取消
+}
+
+type entry struct {
+	job     Job
+	ensured bool                      // 共享任务的数据库记录已创建
+	started time.Time                 // 本实例最近一次开始运行的时间
+	status  schedulerEntity.JobStatus // 本实例的运行状态
+}
+
+// scheduler 周期任务调度器，共享任务通过数据库中的租约保证多个实例中同一时间只有一个在运行，
+// 未连接数据库时所有任务按本地任务调度
+type scheduler struct {
+	mu       sync.Mutex
+	entries  []*entry
+	owner    string
+	interval func(job Job) time.Duration
+	lease    func() time.Duration
+}
+
+var defaultScheduler = newScheduler(instanceOwner(), configuredInterval, configuredLease)
+
+// newScheduler 创建调度器，interval返回任务当前的运行周期，lease返回单次运行的最长时间
+func newScheduler(owner string, interval func(job Job) time.Duration, lease func() time.Duration) *scheduler {
+	return &scheduler{owner: owner, interval: interval, lease: lease}
+}
+
+// instanceOwner 返回标识当前实例的名称
+func instanceOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// configuredInterval 返回配置中覆盖的运行周期，未配置时使用任务的默认周期；每次检查时重新读取，支持热更新
+func configuredInterval(job Job) time.Duration {
+	if seconds, ok := config.GetConfig().GetSchedulerConfig().Jobs[job.Name]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return job.Interval
+}
+
+// configuredLease 返回配置的单次运行最长时间
+func configuredLease() time.Duration {
+	if seconds := config.GetConfig().GetSchedulerConfig().LockTimeout; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultLockTimeout
+}
+
+// Register 注册周期任务，需在Start之前调用
+func Register(job Job) {
+	defaultScheduler.register(job)
+}
+
+// Start 启动调度器，按检查周期运行到期的任务，ctx取消时退出
+func Start(ctx context.Context) {
+	defaultScheduler.start(ctx)
+}
+
+// Statuses 返回全部任务的状态
+func Statuses(ctx context.Context) []schedulerEntity.JobStatus {
+	return defaultScheduler.statuses(ctx)
+}
+
+func (s *scheduler) register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{job: job, status: schedulerEntity.JobStatus{Name: job.Name, Local: job.Local}})
+}
+
+func (s *scheduler) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			s.tick(ctx, time.Now())
+		}
+	}()
+}
+
+// tick 启动所有到期且未在运行的任务
+func (s *scheduler) tick(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	entries := append([]*entry(nil), s.entries...)
+	s.mu.Unlock()
+
+	for _, e := range entries {
+		interval := s.interval(e.job)
+		if interval <= 0 {
+			continue
+		}
+
+		s.mu.Lock()
+		due := !e.status.Running && (e.started.IsZero() || now.Sub(e.started) >= interval)
+		s.mu.Unlock()
+		if !due {
+			continue
+		}
+
+		shared := !e.job.Local && db.GetDB() != nil
+		if shared && !s.acquire(ctx, e, now, interval) {
+			continue
+		}
+		s.run(ctx, e, now, shared)
+	}
+}
+
+// acquire 抢占共享任务的数据库租约，其它实例正在运行或近期已运行时返回false
+func (s *scheduler) acquire(ctx context.Context, e *entry, now time.Time, interval time.Duration) bool {
+	if !e.ensured {
+		if err := scheduler_dao.EnsureJob(ctx, e.job.Name); err != nil {
+			return false
+		}
+		e.ensured = true
+	}
+	acquired, err := scheduler_dao.AcquireJob(ctx, e.job.Name, s.owner, now, interval, s.lease())
+	return err == nil && acquired
+}
+
+// run 在后台运行任务并记录结果，任务panic时按失败处理
+func (s *scheduler) run(ctx context.Context, e *entry, now time.Time, shared bool) {
+	s.mu.Lock()
+	e.started = now
+	e.status.Running = true
+	e.status.Owner = s.owner
+	e.status.LastStartedAt = now.Unix()
+	s.mu.Unlock()
+
+	go func() {
+		jobCtx, cancel := context.WithTimeout(ctx, s.lease())
+		defer cancel()
+
+		err := func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("任务panic: %v", r)
+				}
+			}()
+			return e.job.Run(jobCtx)
+		}()
+
+		finished := time.Now()
+		status, errMsg := schedulerEntity.StatusSucceeded, ""
+		if err != nil {
+			status, errMsg = schedulerEntity.StatusFailed, err.Error()
+			log.WarnWithContext(ctx, "周期任务失败", "任务:", e.job.Name, "错误:", err)
+		}
+
+		s.mu.Lock()
+		e.status.Running = false
+		e.status.LastFinishedAt = finished.Unix()
+		e.status.LastStatus = status
+		e.status.LastError = errMsg
+		e.status.LastDurationMs = finished.Sub(now).Milliseconds()
+		e.status.RunCount++
+		s.mu.Unlock()
+
+		if shared {
+			_ = scheduler_dao.FinishJob(ctx, e.job.Name, s.owner, finished, status, errMsg, finished.Sub(now).Milliseconds())
+		}
+	}()
+}
+
+// statuses 返回全部任务的状态，共享任务使用数据库中的记录，读取失败时退回本实例的状态
+func (s *scheduler) statuses(ctx context.Context) []schedulerEntity.JobStatus {
+	s.mu.Lock()
+	result := make([]schedulerEntity.JobStatus, 0, len(s.entries))
+	for _, e := range s.entries {
+		status := e.status
+		status.Interval = int64(s.interval(e.job) / time.Second)
+		result = append(result, status)
+	}
+	s.mu.Unlock()
+
+	if db.GetDB() == nil {
+		return result
+	}
+	rows, err := scheduler_dao.ListJobs(ctx)
+	if err != nil {
+		log.WarnWithContext(ctx, "读取周期任务记录失败，返回本实例的状态", "错误:", err)
+		return result
+	}
+
+	now := time.Now()
+	for i := range result {
+		if result[i].Local {
+			continue
+		}
+		for _, row := range rows {
+			if row.JobName != result[i].Name {
+				continue
+			}
+			result[i].Owner = row.Owner
+			result[i].Running = row.LockedUntil != nil && row.LockedUntil.After(now)
+			result[i].LastStartedAt = unixOrZero(row.LastStartedAt)
+			result[i].LastFinishedAt = unixOrZero(row.LastFinishedAt)
+			result[i].LastStatus = row.LastStatus
+			result[i].LastError = row.LastError
+			result[i].LastDurationMs = row.LastDurationMs
+			result[i].RunCount = row.RunCount
+		}
+	}
+	return result
+}
+
+// unixOrZero 返回时间戳，时间为空时返回0
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	schedulerEntity "ginproject/entity/scheduler"
+)
+
+func TestTickRunsDueJobs(t *testing.T) {
+	intervals := map[string]time.Duration{}
+	s := newScheduler("test", func(job Job) time.Duration {
+		if interval, ok := intervals[job.Name]; ok {
+			return interval
+		}
+		return job.Interval
+	}, func() time.Duration { return time.Minute })
+
+	runs := make(chan string, 10)
+	s.register(Job{Name: "ok", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs <- "ok"
+		return nil
+	}})
+	s.register(Job{Name: "fail", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs <- "fail"
+		return errors.New("boom")
+	}})
+
+	ctx := context.Background()
+	now := time.Now()
+	s.tick(ctx, now)
+	waitRuns(t, runs, 2)
+
+	// 未到周期时不再运行
+	s.tick(ctx, now.Add(30*time.Second))
+	waitRuns(t, runs, 0)
+
+	// 周期覆盖为0时停用
+	intervals["fail"] = 0
+	s.tick(ctx, now.Add(time.Minute))
+	waitRuns(t, runs, 1)
+
+	statuses := s.statuses(ctx)
+	if len(statuses) != 2 {
+		t.Fatalf("任务数 = %d, 期望 2", len(statuses))
+	}
+	if statuses[0].RunCount != 2 || statuses[0].LastStatus != schedulerEntity.StatusSucceeded || statuses[0].Interval != 60 {
+		t.Errorf("ok任务状态不正确: %+v", statuses[0])
+	}
+	if statuses[1].RunCount != 1 || statuses[1].LastStatus != schedulerEntity.StatusFailed || statuses[1].LastError != "boom" || statuses[1].Interval != 0 {
+		t.Errorf("fail任务状态不正确: %+v", statuses[1])
+	}
+}
+
+// waitRuns 等待n次任务运行并等待运行状态记录完成，随后确认没有多余的运行
+func waitRuns(t *testing.T, runs chan string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("等待第%d次任务运行超时", i+1)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(runs) != 0 {
+		t.Fatalf("多运行了%d次任务", len(runs))
+	}
+}
@@ -0,0 +1,66 @@
+package script
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/blockchain"
+	"ginproject/entity/script"
+	"ginproject/entity/transaction"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	rpcblockchain "ginproject/repo/rpc/blockchain"
+)
+
+// DecodeScript 解码任意脚本，标准类型和地址由节点解析，代币、多签和OP_RETURN数据使用本地分类器识别
+// 请求需已通过Validate，此时Hex为待解码的脚本
+func DecodeScript(ctx context.Context, req *script.DecodeScriptRequest) (*script.DecodeScriptResponse, error) {
+	result := <-rpcblockchain.DecodeScript(ctx, req.Hex)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	decoded, ok := result.Result.(*blockchain.DecodedScript)
+	if !ok {
+		return nil, fmt.Errorf("脚本解码结果类型错误")
+	}
+
+	scriptHash, err := utility.ConvertHexToSha256Reversed(req.Hex)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &script.DecodeScriptResponse{
+		Hex:        req.Hex,
+		Asm:        decoded.Asm,
+		Type:       decoded.Type,
+		Class:      transaction.ClassifyOutput(transaction.TxOutputScript{Type: decoded.Type, Asm: decoded.Asm}),
+		Addresses:  decoded.Addresses,
+		ScriptHash: scriptHash,
+	}
+	if resp.Addresses == nil {
+		resp.Addresses = []string{}
+	}
+
+	// 节点无法解析地址的代币脚本由分类器提取持有者
+	if class := utility.ClassifyScript(req.Hex); class.Class != utility.ScriptClassUnknown {
+		resp.TokenClass = class.Class
+		resp.Holder = class.Address
+	}
+
+	// 推送长度不完整的脚本节点仍可解码，此时只跳过多签和OP_RETURN的解析
+	multisig, err := script.ParseMultisig(req.Hex)
+	if err != nil {
+		log.WarnWithContext(ctx, "解析脚本操作失败", "error", err)
+		return resp, nil
+	}
+	if multisig != nil {
+		if address, err := utility.ConvertP2msScriptToMsAddress(decoded.Asm); err == nil {
+			multisig.Address = address
+		}
+		resp.Multisig = multisig
+	}
+	if resp.OpReturn, err = script.OpReturnData(req.Hex); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,124 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"ginproject/entity/subscription"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+)
+
+// 客户端发送队列长度，队列写满说明客户端消费过慢，直接断开连接
+const clientSendBuffer = 64
+
+// Client 一个WebSocket连接的订阅状态，发送给客户端的消息统一经过发送队列，由连接的写协程按顺序写出
+type Client struct {
+	hub       *Hub
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+
+	closed  bool               // 已从hub移除，由hub.mu保护
+	watches map[watcher]string // 订阅到脚本哈希的映射，由hub.mu保护
+}
+
+// newClient 创建客户端
+func newClient(hub *Hub) *Client {
+	return &Client{
+		hub:     hub,
+		send:    make(chan []byte, clientSendBuffer),
+		done:    make(chan struct{}),
+		watches: make(map[watcher]string),
+	}
+}
+
+// Messages 返回待发送给客户端的消息
+func (c *Client) Messages() <-chan []byte {
+	return c.send
+}
+
+// Done 返回客户端关闭时关闭的通道
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close 取消客户端的全部订阅，可以重复调用
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		c.hub.remove(c)
+		close(c.done)
+	})
+}
+
+// Send 将消息放入发送队列，队列已满时断开客户端
+func (c *Client) Send(msg subscription.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Error("序列化订阅消息失败:", err)
+		return
+	}
+
+	select {
+	case <-c.done:
+	case c.send <- data:
+	default:
+		log.Warn("WebSocket客户端消费过慢，断开连接")
+		c.Close()
+	}
+}
+
+// Handle 处理客户端请求，返回需要回复的ack或error消息
+func (c *Client) Handle(ctx context.Context, req *subscription.Request) subscription.Message {
+	if err := req.Validate(); err != nil {
+		return subscription.NewErrorMessage(req.ID, err)
+	}
+
+	ack := subscription.Message{ID: req.ID, Type: subscription.MessageAck, Topic: req.Topic, Value: req.Value}
+	if req.Topic == subscription.TopicHeaders || req.Topic == subscription.TopicBlocks {
+		c.hub.mu.Lock()
+		if c.closed {
+			c.hub.mu.Unlock()
+			return subscription.NewErrorMessage(req.ID, ErrClientClosed)
+		}
+		set := c.hub.headers
+		if req.Topic == subscription.TopicBlocks {
+			set = c.hub.blocks
+		}
+		if req.Op == subscription.OpSubscribe {
+			set[c] = struct{}{}
+			if req.Topic == subscription.TopicHeaders {
+				ack.Header = c.hub.header
+			}
+		} else {
+			delete(set, c)
+		}
+		c.hub.mu.Unlock()
+		return ack
+	}
+
+	hash := req.Value
+	if req.Topic == subscription.TopicAddress {
+		var err error
+		if hash, err = utility.AddressToScriptHash(req.Value); err != nil {
+			return subscription.NewErrorMessage(req.ID, fmt.Errorf("%w: %v", subscription.ErrInvalidRequest, err))
+		}
+	}
+	ack.ScriptHash = hash
+
+	w := watcher{client: c, topic: req.Topic, value: req.Value}
+	if req.Op == subscription.OpUnsubscribe {
+		c.hub.unwatch(w)
+		return ack
+	}
+
+	status, err := c.hub.watch(w, hash)
+	if err != nil {
+		log.WarnWithContext(ctx, "订阅脚本哈希失败", "scriptHash", hash, "error", err)
+		return subscription.NewErrorMessage(req.ID, err)
+	}
+	ack.Status = status
+	return ack
+}
@@ -0,0 +1,454 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/event"
+	"ginproject/entity/subscription"
+	"ginproject/middleware/log"
+	"ginproject/repo/eventbus"
+	"ginproject/repo/rpc/electrumx"
+)
+
+// ElectrumX订阅相关的方法
+const (
+	methodHeadersSubscribe      = "blockchain.headers.subscribe"
+	methodScriptHashSubscribe   = "blockchain.scripthash.subscribe"
+	methodScriptHashUnsubscribe = "blockchain.scripthash.unsubscribe"
+	methodPing                  = "server.ping"
+)
+
+const (
+	// 上游断开后重连的初始和最长等待时间
+	initialReconnectDelay = time.Second
+	maxReconnectDelay     = 30 * time.Second
+	// 向ElectrumX发送心跳的周期，避免会话因空闲被服务端关闭
+	pingInterval = time.Minute
+	// 单次上游调用的超时时间
+	upstreamTimeout = 10 * time.Second
+	// 未配置时单个连接最多订阅的地址和脚本哈希数量
+	defaultMaxSubscriptions = 100
+	// 接收区块事件的消费者名称
+	hubConsumer = "websocket_hub"
+)
+
+var (
+	// ErrTooManyConnections WebSocket连接数已达上限
+	ErrTooManyConnections = errors.New("WebSocket连接数已达上限")
+	// ErrTooManySubscriptions 单个连接的订阅数已达上限
+	ErrTooManySubscriptions = errors.New("订阅数量已达上限")
+	// ErrClientClosed 客户端连接已关闭
+	ErrClientClosed = errors.New("WebSocket连接已关闭")
+)
+
+// defaultHub 全局订阅中心，未启用WebSocket订阅时为nil
+var defaultHub atomic.Pointer[Hub]
+
+// watcher 客户端的一条地址或脚本哈希订阅
+type watcher struct {
+	client *Client
+	topic  string
+	value  string
+}
+
+// Hub 维护与ElectrumX之间的订阅连接，将上游通知分发给订阅的WebSocket客户端
+// 同一个脚本哈希无论被多少客户端订阅，在上游只订阅一次
+// 集群模式下每个实例各自维护：客户端连接只存在于所在实例，上游通知由各实例自己的订阅连接收到，
+// 新区块摘要经共享的事件总线送达每个实例，因此不需要在实例之间共享订阅表
+type Hub struct {
+	ctx context.Context
+
+	mu       sync.Mutex
+	conn     *electrumx.SubscriptionConn     // 上游断开期间为nil
+	header   *subscription.Header            // 最近一次收到的链顶
+	headers  map[*Client]struct{}            // 订阅区块头的客户端
+	blocks   map[*Client]struct{}            // 订阅区块摘要的客户端
+	watchers map[string]map[watcher]struct{} // 脚本哈希到订阅者
+	statuses map[string]*string              // 脚本哈希最近一次的状态
+	clients  int
+}
+
+// StartHub 启用WebSocket订阅时建立到ElectrumX的订阅连接，断开后自动重连并恢复订阅，ctx取消时退出
+// 同时从事件总线接收区块事件推送给订阅区块摘要的客户端，每个实例只推送给本实例的连接
+func StartHub(ctx context.Context) {
+	if !config.GetConfig().GetWebSocketConfig().Enabled {
+		return
+	}
+
+	hub := newHub(ctx)
+	defaultHub.Store(hub)
+	if err := eventbus.Default().SubscribeLive(ctx, hubConsumer, event.TopicBlock, hub.publishBlocks); err != nil {
+		log.WarnWithContext(ctx, "订阅区块事件失败，不推送区块摘要", "error", err)
+	}
+	go hub.run()
+}
+
+// Default 返回全局订阅中心，未启用WebSocket订阅时返回nil
+func Default() *Hub {
+	return defaultHub.Load()
+}
+
+// newHub 创建订阅中心
+func newHub(ctx context.Context) *Hub {
+	return &Hub{
+		ctx:      ctx,
+		headers:  make(map[*Client]struct{}),
+		blocks:   make(map[*Client]struct{}),
+		watchers: make(map[string]map[watcher]struct{}),
+		statuses: make(map[string]*string),
+	}
+}
+
+// Connect 为新的WebSocket连接创建客户端，连接数超过上限时返回ErrTooManyConnections
+func (h *Hub) Connect() (*Client, error) {
+	maxConns := config.GetConfig().GetWebSocketConfig().MaxConnections
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if maxConns > 0 && h.clients >= maxConns {
+		return nil, ErrTooManyConnections
+	}
+	h.clients++
+	return newClient(h), nil
+}
+
+// run 保持上游订阅连接，断开后按指数退避重连
+func (h *Hub) run() {
+	delay := initialReconnectDelay
+	for {
+		conn, err := electrumx.DialSubscription()
+		if err != nil {
+			log.WarnWithContext(h.ctx, "建立ElectrumX订阅连接失败", "error", err, "retryIn", delay)
+		} else {
+			delay = initialReconnectDelay
+			h.serve(conn)
+		}
+
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// serve 在一条上游连接上恢复订阅并分发通知，连接断开或ctx取消时返回
+func (h *Hub) serve(conn *electrumx.SubscriptionConn) {
+	defer conn.Close()
+	defer func() {
+		h.mu.Lock()
+		h.conn = nil
+		h.mu.Unlock()
+	}()
+
+	// 通知单独消费，避免等待订阅响应期间通知堆积阻塞上游连接的读循环
+	go func() {
+		for {
+			select {
+			case n := <-conn.Notifications():
+				h.dispatch(n)
+			case <-conn.Done():
+				return
+			}
+		}
+	}()
+
+	if err := h.resubscribe(conn); err != nil {
+		log.WarnWithContext(h.ctx, "恢复ElectrumX订阅失败", "error", err)
+		return
+	}
+	log.InfoWithContext(h.ctx, "ElectrumX订阅连接已就绪")
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-conn.Done():
+			log.WarnWithContext(h.ctx, "ElectrumX订阅连接已断开", "error", conn.Err())
+			return
+		case <-ticker.C:
+			go h.call(conn, methodPing)
+		}
+	}
+}
+
+// resubscribe 在新连接上订阅区块头和所有仍有订阅者的脚本哈希，断开期间发生的变化会作为通知补发
+func (h *Hub) resubscribe(conn *electrumx.SubscriptionConn) error {
+	raw, err := h.call(conn, methodHeadersSubscribe)
+	if err != nil {
+		return err
+	}
+	var header subscription.Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return fmt.Errorf("解析区块头订阅结果失败: %w", err)
+	}
+	h.updateHeader(header)
+
+	h.mu.Lock()
+	h.conn = conn
+	hashes := make([]string, 0, len(h.watchers))
+	for hash := range h.watchers {
+		hashes = append(hashes, hash)
+	}
+	h.mu.Unlock()
+
+	for _, hash := range hashes {
+		status, err := h.subscribeScriptHash(conn, hash)
+		if err != nil {
+			return err
+		}
+		h.updateStatus(hash, status)
+	}
+	return nil
+}
+
+// dispatch 处理上游推送的通知
+func (h *Hub) dispatch(n electrumx.Notification) {
+	switch n.Method {
+	case methodHeadersSubscribe:
+		var params []subscription.Header
+		if err := json.Unmarshal(n.Params, &params); err != nil || len(params) == 0 {
+			log.WarnWithContext(h.ctx, "解析区块头通知失败", "params", string(n.Params))
+			return
+		}
+		h.updateHeader(params[0])
+	case methodScriptHashSubscribe:
+		var hash string
+		var status *string
+		params := []interface{}{&hash, &status}
+		if err := json.Unmarshal(n.Params, &params); err != nil || hash == "" {
+			log.WarnWithContext(h.ctx, "解析脚本哈希通知失败", "params", string(n.Params))
+			return
+		}
+		h.updateStatus(hash, status)
+	}
+}
+
+// updateHeader 记录新的链顶并通知订阅区块头的客户端，链顶未变化时不通知
+func (h *Hub) updateHeader(header subscription.Header) {
+	h.mu.Lock()
+	if h.header != nil && *h.header == header {
+		h.mu.Unlock()
+		return
+	}
+	h.header = &header
+	clients := make([]*Client, 0, len(h.headers))
+	for c := range h.headers {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	msg := subscription.Message{Type: subscription.MessageNotification, Topic: subscription.TopicHeaders, Header: &header}
+	for _, c := range clients {
+		c.Send(msg)
+	}
+}
+
+// publishBlocks 将事件总线上的区块事件推送给订阅区块摘要的客户端，无法解析的事件跳过
+func (h *Hub) publishBlocks(ctx context.Context, events []eventbus.Event) error {
+	h.mu.Lock()
+	clients := make([]*Client, 0, len(h.blocks))
+	for c := range h.blocks {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+	if len(clients) == 0 {
+		return nil
+	}
+
+	for _, e := range events {
+		var block event.BlockEvent
+		if err := json.Unmarshal(e.Payload, &block); err != nil {
+			log.WarnWithContext(ctx, "解析区块事件失败", "offset", e.Offset, "error", err)
+			continue
+		}
+		msg := subscription.Message{Type: subscription.MessageNotification, Topic: subscription.TopicBlocks, Block: &block}
+		for _, c := range clients {
+			c.Send(msg)
+		}
+	}
+	return nil
+}
+
+// updateStatus 记录脚本哈希的新状态并通知订阅者，状态未变化时不通知
+func (h *Hub) updateStatus(hash string, status *string) {
+	h.mu.Lock()
+	set, ok := h.watchers[hash]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	if old, known := h.statuses[hash]; known && equalStatus(old, status) {
+		h.mu.Unlock()
+		return
+	}
+	h.statuses[hash] = status
+	watchers := make([]watcher, 0, len(set))
+	for w := range set {
+		watchers = append(watchers, w)
+	}
+	h.mu.Unlock()
+
+	for _, w := range watchers {
+		w.client.Send(subscription.Message{
+			Type:       subscription.MessageNotification,
+			Topic:      w.topic,
+			Value:      w.value,
+			ScriptHash: hash,
+			Status:     status,
+		})
+	}
+}
+
+// watch 添加一条脚本哈希订阅，返回脚本哈希的当前状态
+// 该脚本哈希首次被订阅时向上游订阅；上游断开期间只登记订阅，重连后统一恢复
+func (h *Hub) watch(w watcher, hash string) (*string, error) {
+	maxSubs := config.GetConfig().GetWebSocketConfig().MaxSubscriptions
+	if maxSubs <= 0 {
+		maxSubs = defaultMaxSubscriptions
+	}
+
+	h.mu.Lock()
+	if w.client.closed {
+		h.mu.Unlock()
+		return nil, ErrClientClosed
+	}
+	if _, ok := w.client.watches[w]; ok {
+		status := h.statuses[hash]
+		h.mu.Unlock()
+		return status, nil
+	}
+	if len(w.client.watches) >= maxSubs {
+		h.mu.Unlock()
+		return nil, fmt.Errorf("%w: 单个连接最多%d个", ErrTooManySubscriptions, maxSubs)
+	}
+	set, ok := h.watchers[hash]
+	if !ok {
+		set = make(map[watcher]struct{})
+		h.watchers[hash] = set
+	}
+	set[w] = struct{}{}
+	w.client.watches[w] = hash
+	status, known := h.statuses[hash]
+	conn := h.conn
+	h.mu.Unlock()
+
+	if known || conn == nil {
+		return status, nil
+	}
+
+	status, err := h.subscribeScriptHash(conn, hash)
+	if err != nil {
+		h.unwatch(w)
+		return nil, err
+	}
+	h.mu.Lock()
+	if _, ok := h.watchers[hash]; ok {
+		h.statuses[hash] = status
+	}
+	h.mu.Unlock()
+	return status, nil
+}
+
+// unwatch 移除一条脚本哈希订阅，最后一个订阅者离开时取消上游订阅
+func (h *Hub) unwatch(w watcher) {
+	h.mu.Lock()
+	hash, ok := w.client.watches[w]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(w.client.watches, w)
+	last := h.removeWatcherLocked(w, hash)
+	conn := h.conn
+	h.mu.Unlock()
+
+	if last && conn != nil {
+		go h.call(conn, methodScriptHashUnsubscribe, hash)
+	}
+}
+
+// remove 移除客户端的全部订阅
+func (h *Hub) remove(c *Client) {
+	h.mu.Lock()
+	h.clients--
+	delete(h.headers, c)
+	delete(h.blocks, c)
+	var released []string
+	for w, hash := range c.watches {
+		if h.removeWatcherLocked(w, hash) {
+			released = append(released, hash)
+		}
+	}
+	c.watches = nil
+	c.closed = true
+	conn := h.conn
+	h.mu.Unlock()
+
+	if conn == nil || len(released) == 0 {
+		return
+	}
+	go func() {
+		for _, hash := range released {
+			h.call(conn, methodScriptHashUnsubscribe, hash)
+		}
+	}()
+}
+
+// removeWatcherLocked 从脚本哈希的订阅者中移除w，返回该脚本哈希是否已没有订阅者，调用方需持有h.mu
+func (h *Hub) removeWatcherLocked(w watcher, hash string) bool {
+	set := h.watchers[hash]
+	delete(set, w)
+	if len(set) > 0 {
+		return false
+	}
+	delete(h.watchers, hash)
+	delete(h.statuses, hash)
+	return true
+}
+
+// subscribeScriptHash 向上游订阅脚本哈希并返回当前状态
+func (h *Hub) subscribeScriptHash(conn *electrumx.SubscriptionConn, hash string) (*string, error) {
+	raw, err := h.call(conn, methodScriptHashSubscribe, hash)
+	if err != nil {
+		return nil, err
+	}
+	var status *string
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return nil, fmt.Errorf("解析脚本哈希订阅结果失败: %w", err)
+	}
+	return status, nil
+}
+
+// call 在订阅连接上调用上游方法，心跳和取消订阅失败只记录日志
+func (h *Hub) call(conn *electrumx.SubscriptionConn, method string, params ...interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, upstreamTimeout)
+	defer cancel()
+
+	if params == nil {
+		params = []interface{}{}
+	}
+	raw, err := conn.Call(ctx, method, params)
+	if err != nil {
+		log.WarnWithContext(ctx, "ElectrumX订阅连接调用失败", "method", method, "error", err)
+	}
+	return raw, err
+}
+
+// equalStatus 比较两个脚本哈希状态，nil表示没有历史
+func equalStatus(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,132 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"ginproject/entity/event"
+	"ginproject/entity/subscription"
+	"ginproject/repo/eventbus"
+	"ginproject/repo/rpc/electrumx"
+)
+
+const testScriptHash = "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161"
+
+// receive 读取客户端发送队列中的下一条消息
+func receive(t *testing.T, c *Client) subscription.Message {
+	t.Helper()
+	select {
+	case data := <-c.Messages():
+		var msg subscription.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("解析消息失败: %v", err)
+		}
+		return msg
+	default:
+		t.Fatal("发送队列中没有消息")
+		return subscription.Message{}
+	}
+}
+
+func TestHubDispatch(t *testing.T) {
+	hub := newHub(context.Background())
+	c, err := hub.Connect()
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	// 上游未连接时只登记订阅
+	ack := c.Handle(context.Background(), &subscription.Request{ID: 1, Op: subscription.OpSubscribe, Topic: subscription.TopicScriptHash, Value: testScriptHash})
+	if ack.Type != subscription.MessageAck || ack.ScriptHash != testScriptHash {
+		t.Fatalf("subscribe ack = %+v", ack)
+	}
+	c.Handle(context.Background(), &subscription.Request{ID: 2, Op: subscription.OpSubscribe, Topic: subscription.TopicHeaders})
+
+	hub.dispatch(electrumx.Notification{Method: methodHeadersSubscribe, Params: json.RawMessage(`[{"height":100,"hex":"00"}]`)})
+	if msg := receive(t, c); msg.Topic != subscription.TopicHeaders || msg.Header == nil || msg.Header.Height != 100 {
+		t.Errorf("header notification = %+v", msg)
+	}
+	// 链顶未变化时不重复通知
+	hub.dispatch(electrumx.Notification{Method: methodHeadersSubscribe, Params: json.RawMessage(`[{"height":100,"hex":"00"}]`)})
+
+	hub.dispatch(electrumx.Notification{Method: methodScriptHashSubscribe, Params: json.RawMessage(`["` + testScriptHash + `","abcd"]`)})
+	msg := receive(t, c)
+	if msg.Topic != subscription.TopicScriptHash || msg.Status == nil || *msg.Status != "abcd" {
+		t.Errorf("scripthash notification = %+v", msg)
+	}
+
+	c.Handle(context.Background(), &subscription.Request{ID: 3, Op: subscription.OpUnsubscribe, Topic: subscription.TopicScriptHash, Value: testScriptHash})
+	hub.dispatch(electrumx.Notification{Method: methodScriptHashSubscribe, Params: json.RawMessage(`["` + testScriptHash + `","ef01"]`)})
+	select {
+	case data := <-c.Messages():
+		t.Errorf("取消订阅后仍收到消息: %s", data)
+	default:
+	}
+	if len(hub.watchers) != 0 {
+		t.Errorf("取消订阅后仍有%d个脚本哈希", len(hub.watchers))
+	}
+}
+
+func TestHubPublishBlocks(t *testing.T) {
+	hub := newHub(context.Background())
+	c, _ := hub.Connect()
+	defer c.Close()
+
+	if ack := c.Handle(context.Background(), &subscription.Request{ID: 1, Op: subscription.OpSubscribe, Topic: subscription.TopicBlocks}); ack.Type != subscription.MessageAck {
+		t.Fatalf("subscribe ack = %+v", ack)
+	}
+	hub.publishBlocks(context.Background(), []eventbus.Event{
+		{Topic: event.TopicBlock, Payload: json.RawMessage(`{"height":101,"hash":"ab","tx_count":3}`)},
+		{Topic: event.TopicBlock, Payload: json.RawMessage(`not json`)},
+	})
+	if msg := receive(t, c); msg.Topic != subscription.TopicBlocks || msg.Block == nil || msg.Block.Height != 101 || msg.Block.TxCount != 3 {
+		t.Errorf("block notification = %+v", msg)
+	}
+	select {
+	case data := <-c.Messages():
+		t.Errorf("无法解析的事件不应推送: %s", data)
+	default:
+	}
+
+	// 区块摘要和区块头分别订阅
+	hub.dispatch(electrumx.Notification{Method: methodHeadersSubscribe, Params: json.RawMessage(`[{"height":101,"hex":"00"}]`)})
+	c.Handle(context.Background(), &subscription.Request{ID: 2, Op: subscription.OpUnsubscribe, Topic: subscription.TopicBlocks})
+	hub.publishBlocks(context.Background(), []eventbus.Event{{Topic: event.TopicBlock, Payload: json.RawMessage(`{"height":102}`)}})
+	select {
+	case data := <-c.Messages():
+		t.Errorf("取消订阅后仍收到消息: %s", data)
+	default:
+	}
+}
+
+func TestClientLimits(t *testing.T) {
+	hub := newHub(context.Background())
+	c, _ := hub.Connect()
+
+	for i := 0; i < defaultMaxSubscriptions; i++ {
+		hash := strings.Repeat("0", 62) + string("0123456789abcdef"[i/16]) + string("0123456789abcdef"[i%16])
+		if ack := c.Handle(context.Background(), &subscription.Request{Op: subscription.OpSubscribe, Topic: subscription.TopicScriptHash, Value: hash}); ack.Type != subscription.MessageAck {
+			t.Fatalf("第%d个订阅失败: %+v", i, ack)
+		}
+	}
+	if _, err := hub.watch(watcher{client: c, topic: subscription.TopicScriptHash, value: testScriptHash}, testScriptHash); !errors.Is(err, ErrTooManySubscriptions) {
+		t.Errorf("超过上限时 error = %v", err)
+	}
+
+	// 发送队列写满后断开客户端并释放全部订阅
+	for i := 0; i <= clientSendBuffer; i++ {
+		c.Send(subscription.Message{Type: subscription.MessageNotification})
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("发送队列写满后客户端未关闭")
+	}
+	if len(hub.watchers) != 0 || hub.clients != 0 {
+		t.Errorf("客户端关闭后 watchers = %d, clients = %d", len(hub.watchers), hub.clients)
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"net/http"
 
 	"ginproject/entity/transaction"
+	"ginproject/entity/utility"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
+	"ginproject/middleware/scriptwatch"
+	"ginproject/repo/db"
 	"ginproject/repo/rpc/blockchain"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -78,6 +82,8 @@ func DecodeRawTx(ctx context.Context, req *transaction.TxDecodeRawRequest) (*tra
 	// 尝试直接类型转换
 	if decodedTx, ok := result.Result.(transaction.TxDecodeResponse); ok {
 		log.InfoWithContext(ctx, "直接类型转换成功", "txid", decodedTx.TxID)
+		classifyVouts(ctx, &decodedTx)
+		recountConfirmations(ctx, &decodedTx)
 		return &decodedTx, http.StatusOK, nil
 	}
 
@@ -98,6 +104,9 @@ func DecodeRawTx(ctx context.Context, req *transaction.TxDecodeRawRequest) (*tra
 		return nil, http.StatusInternalServerError, fmt.Errorf("解码交易结果映射失败: %w", err)
 	}
 
+	classifyVouts(ctx, &resp)
+	recountConfirmations(ctx, &resp)
+
 	// 返回结果
 	log.InfoWithContext(ctx, "解码原始交易完成", "txid", resp.TxID)
 	return &resp, http.StatusOK, nil
@@ -121,6 +130,9 @@ func GetTxRawHex(ctx context.Context, txid string) (string, int, error) {
 	// 处理错误
 	if result.Error != nil {
 		log.ErrorWithContext(ctx, "获取交易原始数据服务错误", "error", result.Error)
+		if db.IsNotFound(result.Error) {
+			return "", http.StatusNotFound, fmt.Errorf("%w: %v", db.ErrTxNotFound, result.Error)
+		}
 		return "", http.StatusInternalServerError, result.Error
 	}
 
@@ -154,12 +166,17 @@ func DecodeTxByHash(ctx context.Context, txid string) (*transaction.TxDecodeResp
 	// 处理错误
 	if result.Error != nil {
 		log.ErrorWithContext(ctx, "解码交易服务错误", "error", result.Error)
+		if db.IsNotFound(result.Error) {
+			return nil, http.StatusNotFound, fmt.Errorf("%w: %v", db.ErrTxNotFound, result.Error)
+		}
 		return nil, http.StatusInternalServerError, result.Error
 	}
 
 	// 尝试直接类型转换
 	if decodedTx, ok := result.Result.(transaction.TxDecodeResponse); ok {
 		log.InfoWithContext(ctx, "直接类型转换成功", "txid", decodedTx.TxID)
+		classifyVouts(ctx, &decodedTx)
+		recountConfirmations(ctx, &decodedTx)
 		return &decodedTx, http.StatusOK, nil
 	}
 
@@ -170,11 +187,59 @@ func DecodeTxByHash(ctx context.Context, txid string) (*transaction.TxDecodeResp
 		return nil, http.StatusInternalServerError, fmt.Errorf("解码交易结果映射失败: %w", err)
 	}
 
+	classifyVouts(ctx, &resp)
+	recountConfirmations(ctx, &resp)
+
 	// 返回结果
 	log.InfoWithContext(ctx, "通过交易ID解码交易完成", "txid", txid)
 	return &resp, http.StatusOK, nil
 }
 
+// classifyVouts 标注各输出的脚本种类，并为节点未给出地址的代币输出补充从脚本中解析出的持有者地址
+// 两种规则都无法识别的输出标记为unknown并上报，不按普通地址输出处理
+func classifyVouts(ctx context.Context, tx *transaction.TxDecodeResponse) {
+	for i := range tx.Vout {
+		scriptPubKey := tx.Vout[i].ScriptPubKey
+		if scriptPubKey == nil {
+			continue
+		}
+		tx.Vout[i].ScriptKind = transaction.ScriptKind(transaction.TxOutputScript{Type: scriptPubKey.Type, Asm: scriptPubKey.Asm})
+
+		class := utility.ClassifyScript(scriptPubKey.Hex)
+		if class.Class == utility.ScriptClassUnknown {
+			if tx.Vout[i].ScriptKind == transaction.ScriptKindUnknown {
+				scriptwatch.ReportUnknown(ctx, tx.TxID, tx.Vout[i].N, scriptPubKey.Type, scriptPubKey.Hex)
+			}
+			continue
+		}
+		if len(scriptPubKey.Addresses) > 0 {
+			continue
+		}
+		tx.Vout[i].ScriptClass = class.Class
+		tx.Vout[i].DerivedAddresses = []string{class.Address}
+	}
+}
+
+// recountConfirmations 按本次请求的链顶重新计算确认数，区块已不在主链上时确认数为0
+// 链顶或区块高度不可用时保留节点返回的值
+func recountConfirmations(ctx context.Context, tx *transaction.TxDecodeResponse) {
+	tip, err := chaintip.Snapshot(ctx)
+	if err != nil {
+		log.WarnWithContext(ctx, "获取链顶失败，使用节点返回的确认数", "error", err)
+		return
+	}
+	height, confirmations, err := chaintip.BlockConfirmations(ctx, tip, tx.BlockHash)
+	if err != nil {
+		log.WarnWithContext(ctx, "获取交易所在区块高度失败，使用节点返回的确认数", "blockhash", tx.BlockHash, "error", err)
+		return
+	}
+	if height > 0 {
+		tx.BlockHeight = int(height)
+	}
+	tx.Confirmations = int(confirmations)
+	tx.TipHeight = tip.Height
+}
+
 // GetTxVins 获取交易输入数据的业务逻辑
 func GetTxVins(ctx context.Context, txids []string) ([]transaction.TxVinsRawResponse, int, error) {
 	// 验证参数
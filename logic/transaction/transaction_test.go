@@ -0,0 +1,80 @@
+package transaction
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"ginproject/entity/transaction"
+)
+
+func TestClassifyVouts(t *testing.T) {
+	const (
+		address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+		pkHash  = "62e907b15cbf27d5425399ebf6f0fb50ebb88f18"
+		pubKey  = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	)
+	ftCode := strings.Repeat("51", 64) + pkHash + "00" + "0502436f6465"
+	nftHold := "76a914" + pkHash + "88ac6a0d56302043757272204e486f6c64"
+
+	cases := []struct {
+		name      string
+		script    *transaction.ScriptPubKey
+		wantKind  string
+		wantClass string
+		wantAddrs []string
+	}{
+		{
+			name:     "P2PKH",
+			script:   &transaction.ScriptPubKey{Type: "pubkeyhash", Hex: "76a914" + pkHash + "88ac", Addresses: []string{address}},
+			wantKind: "p2pkh",
+		},
+		{
+			name:      "TBC20代码脚本",
+			script:    &transaction.ScriptPubKey{Type: "nonstandard", Asm: "9 OP_PICK OP_TOALTSTACK", Hex: ftCode},
+			wantKind:  "tbc20",
+			wantClass: "tbc20_code",
+			wantAddrs: []string{address},
+		},
+		{
+			name:      "TBC721持有脚本",
+			script:    &transaction.ScriptPubKey{Type: "nonstandard", Asm: "1 OP_PICK", Hex: nftHold},
+			wantKind:  "tbc721",
+			wantClass: "tbc721_hold",
+			wantAddrs: []string{address},
+		},
+		{
+			// 节点已经给出地址时不再补充
+			name:     "节点已解析地址的代币脚本",
+			script:   &transaction.ScriptPubKey{Type: "nonstandard", Asm: "1 OP_PICK", Hex: nftHold, Addresses: []string{"1other"}},
+			wantKind: "tbc721",
+		},
+		{
+			name:     "多签",
+			script:   &transaction.ScriptPubKey{Type: "multisig", Asm: "2 " + pubKey + " " + pubKey + " 2 OP_CHECKMULTISIG", Hex: "5221" + pubKey + "21" + pubKey + "52ae"},
+			wantKind: "p2ms",
+		},
+		{
+			name:     "无法识别的脚本",
+			script:   &transaction.ScriptPubKey{Type: "nonstandard", Asm: "OP_TRUE", Hex: "51"},
+			wantKind: transaction.ScriptKindUnknown,
+		},
+		{
+			name: "缺少脚本",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tx := &transaction.TxDecodeResponse{TxID: "tx", Vout: []transaction.Vout{{N: 0, ScriptPubKey: c.script}}}
+			classifyVouts(context.Background(), tx)
+			vout := tx.Vout[0]
+			if vout.ScriptKind != c.wantKind {
+				t.Errorf("ScriptKind = %q, 期望 %q", vout.ScriptKind, c.wantKind)
+			}
+			if vout.ScriptClass != c.wantClass || !slices.Equal(vout.DerivedAddresses, c.wantAddrs) {
+				t.Errorf("ScriptClass = %q, DerivedAddresses = %v, 期望 %q %v", vout.ScriptClass, vout.DerivedAddresses, c.wantClass, c.wantAddrs)
+			}
+		})
+	}
+}
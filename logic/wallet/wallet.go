@@ -0,0 +1,264 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/utility"
+	"ginproject/entity/wallet"
+	"ginproject/middleware/log"
+	"ginproject/repo/db/tracked_wallet_dao"
+	"ginproject/repo/scripthash"
+)
+
+// 未配置时单个钱包最多监听的地址数量
+const defaultMaxAddresses = 1000
+
+// 未配置时扩展公钥每条链派生的地址数量
+const defaultLookahead = 20
+
+// ErrInvalidWallet 钱包的地址或扩展公钥无效
+var ErrInvalidWallet = errors.New("钱包参数无效")
+
+// CreateWallet 创建跟踪钱包并请求后台任务同步汇总数据，不等待同步完成
+// 返回的汇总数据synced_at为0，客户端稍后查询钱包获取同步结果
+func CreateWallet(ctx context.Context, req *wallet.CreateWalletRequest) (*wallet.WalletSummaryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+
+	walletId, err := newWalletId()
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := resolveAddresses(ctx, walletId, req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+
+	record := &dbtable.TrackedWallet{WalletId: walletId, Name: req.Name}
+	if err := tracked_wallet_dao.CreateTrackedWallet(ctx, record, addresses); err != nil {
+		return nil, err
+	}
+	log.InfoWithContext(ctx, "创建跟踪钱包成功", "walletId:", walletId, "地址数量:", len(addresses))
+
+	enqueueSync(walletId)
+	return GetWalletSummary(ctx, walletId)
+}
+
+// resolveAddresses 合并直接导入的地址、扩展公钥派生的地址和描述符展开的地址，按地址去重
+func resolveAddresses(ctx context.Context, walletId string, req *wallet.CreateWalletRequest) ([]*dbtable.TrackedWalletAddress, error) {
+	maxAddresses := maxWalletAddresses()
+	lookahead := configuredLookahead(req.Lookahead)
+
+	seen := make(map[string]bool)
+	var addresses []*dbtable.TrackedWalletAddress
+	// scriptHash为空时按P2PKH地址计算，描述符展开的P2SH地址直接使用展开得到的脚本哈希
+	add := func(address, scriptHash, source, path string) error {
+		if seen[address] {
+			return nil
+		}
+		if len(addresses) >= maxAddresses {
+			return fmt.Errorf("钱包地址数量超过上限%d", maxAddresses)
+		}
+		if scriptHash == "" {
+			var err error
+			if scriptHash, err = scripthash.Resolve(ctx, scripthash.KindP2PKH, address); err != nil {
+				return fmt.Errorf("无效的地址%s: %w", address, err)
+			}
+		}
+		seen[address] = true
+		addresses = append(addresses, &dbtable.TrackedWalletAddress{
+			WalletId:       walletId,
+			Address:        address,
+			ScriptHash:     scriptHash,
+			Source:         source,
+			DerivationPath: path,
+		})
+		return nil
+	}
+
+	for _, address := range req.Addresses {
+		if err := add(strings.TrimSpace(address), "", "", ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, xpub := range req.Xpubs {
+		xpub = strings.TrimSpace(xpub)
+		for _, chain := range []uint32{0, 1} {
+			derived, err := utility.DeriveXpubAddresses(xpub, chain, lookahead)
+			if err != nil {
+				return nil, err
+			}
+			for i, address := range derived {
+				if err := add(address, "", xpub, fmt.Sprintf("%d/%d", chain, i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, item := range req.Descriptors {
+		outputs, err := ExpandDescriptor(item, lookahead)
+		if err != nil {
+			return nil, err
+		}
+		desc := strings.TrimSpace(item.Desc)
+		for _, output := range outputs {
+			if err := add(output.Address, output.ScriptHash, desc, strconv.FormatUint(uint64(output.Index), 10)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// configuredLookahead 返回每条链派生的地址数量，请求未指定时使用配置的默认值
+func configuredLookahead(lookahead int) int {
+	if lookahead == 0 {
+		lookahead = config.GetConfig().GetWalletConfig().Lookahead
+	}
+	if lookahead <= 0 {
+		lookahead = defaultLookahead
+	}
+	return lookahead
+}
+
+// ExpandDescriptor 按导入的范围展开描述符，含通配符且未指定范围时展开0到lookahead-1，不含通配符时只有一个输出
+func ExpandDescriptor(item wallet.DescriptorImport, lookahead int) ([]*utility.DescriptorOutput, error) {
+	d, err := utility.ParseDescriptor(item.Desc)
+	if err != nil {
+		return nil, err
+	}
+	begin, end := uint32(0), uint32(0)
+	if d.IsRange() {
+		end = uint32(lookahead) - 1
+		if len(item.Range) == 2 {
+			begin, end = item.Range[0], item.Range[1]
+		}
+	}
+
+	outputs := make([]*utility.DescriptorOutput, 0, end-begin+1)
+	for index := begin; index <= end; index++ {
+		output, err := d.Expand(index)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// PreviewDescriptor 展开描述符但不创建钱包，用于导入前核对派生的地址
+func PreviewDescriptor(req *wallet.DescriptorImport) (*wallet.DescriptorExpandResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+	d, err := utility.ParseDescriptor(req.Desc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+	outputs, err := ExpandDescriptor(*req, configuredLookahead(0))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+	return &wallet.DescriptorExpandResponse{
+		Desc:    strings.TrimSpace(req.Desc),
+		IsRange: d.IsRange(),
+		Outputs: outputs,
+	}, nil
+}
+
+// GetWalletSummary 获取钱包汇总数据，直接读取同步结果
+func GetWalletSummary(ctx context.Context, walletId string) (*wallet.WalletSummaryResponse, error) {
+	record, err := tracked_wallet_dao.GetTrackedWallet(ctx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	addressCount, err := tracked_wallet_dao.CountTrackedWalletAddresses(ctx, walletId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet.WalletSummaryResponse{
+		WalletId:           record.WalletId,
+		Name:               record.Name,
+		AddressCount:       int(addressCount),
+		Balance:            record.Confirmed + record.Unconfirmed,
+		Confirmed:          record.Confirmed,
+		Unconfirmed:        record.Unconfirmed,
+		TxCount:            record.TxCount,
+		LastActivityHeight: record.LastActivityHeight,
+		LastActivityTime:   record.LastActivityTime,
+		SyncedAt:           syncedAtUnix(record),
+	}, nil
+}
+
+// GetWalletHistory 分页获取钱包交易历史
+func GetWalletHistory(ctx context.Context, req *wallet.WalletHistoryRequest) (*wallet.WalletHistoryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	record, err := tracked_wallet_dao.GetTrackedWallet(ctx, req.WalletId)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := tracked_wallet_dao.GetTrackedWalletTxs(ctx, req.WalletId, req.Page*req.Size, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]wallet.WalletHistoryItem, 0, len(txs))
+	for _, tx := range txs {
+		items = append(items, wallet.WalletHistoryItem{
+			TxHash:    tx.TxHash,
+			Height:    tx.Height,
+			Addresses: splitAddresses(tx.Addresses),
+		})
+	}
+
+	return &wallet.WalletHistoryResponse{
+		WalletId: record.WalletId,
+		TxCount:  record.TxCount,
+		SyncedAt: syncedAtUnix(record),
+		Result:   items,
+		Meta:     utility.NewPageMeta(req.Page, req.Size, int64(record.TxCount)),
+	}, nil
+}
+
+// DeleteWallet 删除跟踪钱包
+func DeleteWallet(ctx context.Context, walletId string) error {
+	return tracked_wallet_dao.DeleteTrackedWallet(ctx, walletId)
+}
+
+// newWalletId 生成随机的钱包ID
+func newWalletId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成钱包ID失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// syncedAtUnix 返回同步时间的Unix秒，尚未同步时为0
+func syncedAtUnix(record *dbtable.TrackedWallet) int64 {
+	if record.SyncedAt == nil {
+		return 0
+	}
+	return record.SyncedAt.Unix()
+}
+
+// splitAddresses 拆分逗号分隔的地址列表
+func splitAddresses(addresses string) []string {
+	if addresses == "" {
+		return []string{}
+	}
+	return strings.Split(addresses, ",")
+}
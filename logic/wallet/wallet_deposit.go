@@ -0,0 +1,219 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/utility"
+	"ginproject/entity/wallet"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	"ginproject/repo/db/tracked_wallet_dao"
+	"ginproject/repo/scripthash"
+)
+
+// RegisterDepositAddresses 向钱包登记充值地址，地址到过期时间或首笔充值达到确认数后自动停止监听
+// 只有登记后确认的交易视为充值；重复登记已停止监听的地址时按新策略恢复监听
+func RegisterDepositAddresses(ctx context.Context, walletId string, req *wallet.RegisterDepositAddressesRequest) (*wallet.WalletAddressesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+	if _, err := tracked_wallet_dao.GetTrackedWallet(ctx, walletId); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.Addresses))
+	addresses := make([]string, 0, len(req.Addresses))
+	for _, address := range req.Addresses {
+		address = strings.TrimSpace(address)
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	existing, err := tracked_wallet_dao.GetWalletAddressesIn(ctx, walletId, addresses)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]*dbtable.TrackedWalletAddress, len(existing))
+	for _, record := range existing {
+		records[record.Address] = record
+	}
+
+	// 恢复监听的地址计入上限，已在监听中的地址只更新策略
+	watching, err := tracked_wallet_dao.CountTrackedWalletAddresses(ctx, walletId, wallet.WatchStatusWatching)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if record, ok := records[address]; !ok || record.WatchStatus != wallet.WatchStatusWatching {
+			watching++
+		}
+	}
+	if maxAddresses := maxWalletAddresses(); watching > int64(maxAddresses) {
+		return nil, fmt.Errorf("%w: 监听中的地址数量超过上限%d", ErrInvalidWallet, maxAddresses)
+	}
+
+	tip, err := chaintip.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var expiresAt *time.Time
+	if req.ExpiresIn > 0 {
+		t := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+		expiresAt = &t
+	}
+	stopConfirmations := 0
+	if req.StopOnDeposit {
+		stopConfirmations = req.Confirmations
+	}
+
+	rows := make([]*dbtable.TrackedWalletAddress, 0, len(addresses))
+	for _, address := range addresses {
+		row := &dbtable.TrackedWalletAddress{WalletId: walletId, Address: address}
+		if record, ok := records[address]; ok {
+			row.ScriptHash, row.Source, row.DerivationPath, row.CreatedAt = record.ScriptHash, record.Source, record.DerivationPath, record.CreatedAt
+		} else {
+			scriptHash, err := scripthash.Resolve(ctx, scripthash.KindP2PKH, address)
+			if err != nil {
+				return nil, fmt.Errorf("%w: 无效的地址%s: %v", ErrInvalidWallet, address, err)
+			}
+			row.ScriptHash = scriptHash
+		}
+		row.WatchStatus = wallet.WatchStatusWatching
+		row.ExpiresAt = expiresAt
+		row.StopConfirmations = stopConfirmations
+		row.RegisteredHeight = tip.Height
+		rows = append(rows, row)
+	}
+	if err := tracked_wallet_dao.UpsertWalletAddresses(ctx, rows); err != nil {
+		return nil, err
+	}
+	log.InfoWithContext(ctx, "登记充值地址成功", "walletId:", walletId, "地址数量:", len(rows), "监听中:", watching)
+
+	result := make([]wallet.WalletAddressStatus, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, addressStatus(row))
+	}
+	return &wallet.WalletAddressesResponse{WalletId: walletId, Watching: watching, Result: result}, nil
+}
+
+// GetWalletAddresses 分页获取钱包地址的监听状态
+func GetWalletAddresses(ctx context.Context, req *wallet.WalletAddressesRequest) (*wallet.WalletAddressesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := tracked_wallet_dao.GetTrackedWallet(ctx, req.WalletId); err != nil {
+		return nil, err
+	}
+
+	total, err := tracked_wallet_dao.CountTrackedWalletAddresses(ctx, req.WalletId, req.Status)
+	if err != nil {
+		return nil, err
+	}
+	watching := total
+	if req.Status != wallet.WatchStatusWatching {
+		if watching, err = tracked_wallet_dao.CountTrackedWalletAddresses(ctx, req.WalletId, wallet.WatchStatusWatching); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := tracked_wallet_dao.ListTrackedWalletAddresses(ctx, req.WalletId, req.Status, req.Page*req.Size, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]wallet.WalletAddressStatus, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, addressStatus(row))
+	}
+	return &wallet.WalletAddressesResponse{
+		WalletId: req.WalletId,
+		Watching: watching,
+		Result:   result,
+		Meta:     utility.NewPageMeta(req.Page, req.Size, total),
+	}, nil
+}
+
+// maxWalletAddresses 返回单个钱包最多监听的地址数量
+func maxWalletAddresses() int {
+	if maxAddresses := config.GetConfig().GetWalletConfig().MaxAddresses; maxAddresses > 0 {
+		return maxAddresses
+	}
+	return defaultMaxAddresses
+}
+
+// recordDeposits 记录设置了充值停止策略的地址在登记后的首笔已确认交易，达到确认数时停止监听
+// 按同步时的链顶计算确认数，记录失败只影响本次停止，下次同步时重试
+func recordDeposits(ctx context.Context, walletId string, addresses []*dbtable.TrackedWalletAddress, states []addressState) {
+	var tip chaintip.Tip
+	for i, addr := range addresses {
+		if addr.StopConfirmations <= 0 {
+			continue
+		}
+		txid, height := firstDeposit(states[i].history, addr.RegisteredHeight)
+		if txid == "" {
+			continue
+		}
+		if tip.Height == 0 {
+			var err error
+			if tip, err = chaintip.Snapshot(ctx); err != nil {
+				log.WarnWithContext(ctx, "获取链顶失败，跳过充值地址检查", "walletId:", walletId, "错误:", err)
+				return
+			}
+		}
+		stop := chaintip.Confirmations(tip, height) >= int64(addr.StopConfirmations)
+		if !stop && txid == addr.FirstDepositTxid && height == addr.FirstDepositHeight {
+			continue
+		}
+		if err := tracked_wallet_dao.UpdateWalletAddressDeposit(ctx, walletId, addr.Address, txid, height, stop, time.Now()); err != nil {
+			continue
+		}
+		if stop {
+			log.InfoWithContext(ctx, "充值地址收到充值，停止监听", "walletId:", walletId, "address:", addr.Address, "txid:", txid)
+		}
+	}
+}
+
+// firstDeposit 返回登记高度之后最早确认的交易，没有时返回空
+func firstDeposit(history []historyEntry, registeredHeight int64) (string, int64) {
+	var txid string
+	var height int64
+	for _, entry := range history {
+		if entry.height <= registeredHeight {
+			continue
+		}
+		if txid == "" || entry.height < height || (entry.height == height && entry.txHash < txid) {
+			txid, height = entry.txHash, entry.height
+		}
+	}
+	return txid, height
+}
+
+// addressStatus 转换地址的监听状态
+func addressStatus(row *dbtable.TrackedWalletAddress) wallet.WalletAddressStatus {
+	return wallet.WalletAddressStatus{
+		Address:            row.Address,
+		Status:             row.WatchStatus,
+		Source:             row.Source,
+		DerivationPath:     row.DerivationPath,
+		ExpiresAt:          unixOrZero(row.ExpiresAt),
+		StopConfirmations:  row.StopConfirmations,
+		FirstDepositTxid:   row.FirstDepositTxid,
+		FirstDepositHeight: row.FirstDepositHeight,
+		StoppedAt:          unixOrZero(row.StoppedAt),
+		CreatedAt:          row.CreatedAt.Unix(),
+	}
+}
+
+// unixOrZero 返回时间戳，时间为空时返回0
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,21 @@
+package wallet
+
+import "testing"
+
+func TestFirstDeposit(t *testing.T) {
+	history := []historyEntry{
+		{txHash: "old", height: 100},
+		{txHash: "mempool", height: 0},
+		{txHash: "b", height: 105},
+		{txHash: "a", height: 105},
+		{txHash: "late", height: 110},
+	}
+
+	// 登记高度及之前的交易和未确认交易不视为充值，同一高度按交易哈希取最小
+	if txid, height := firstDeposit(history, 100); txid != "a" || height != 105 {
+		t.Errorf("firstDeposit = %s@%d, 期望 a@105", txid, height)
+	}
+	if txid, _ := firstDeposit(history, 110); txid != "" {
+		t.Errorf("firstDeposit = %s, 期望空", txid)
+	}
+}
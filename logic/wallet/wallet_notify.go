@@ -0,0 +1,187 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/wallet"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/tracked_wallet_dao"
+	"ginproject/repo/notify"
+)
+
+const (
+	// 每日汇总的发送周期
+	digestInterval = 24 * time.Hour
+	// 后台任务每轮最多处理的每日汇总数量
+	digestBatchSize = 50
+	// 单条通知中列出的最大交易数量，其余只给出数量
+	maxNotifyTxs = 50
+)
+
+// ErrNotifierUnavailable 通知渠道未启用
+var ErrNotifierUnavailable = errors.New("通知渠道未启用")
+
+// SetWalletNotification 设置钱包的通知方式，首次设置时从钱包当前的最近活动高度开始通知，不补发历史交易
+func SetWalletNotification(ctx context.Context, walletId string, req *wallet.NotificationRequest) (*wallet.NotificationResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+	if _, ok := notify.Get(req.Channel); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotifierUnavailable, req.Channel)
+	}
+
+	record, err := tracked_wallet_dao.GetTrackedWallet(ctx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	notification := &dbtable.TrackedWalletNotification{
+		WalletId:       walletId,
+		Channel:        req.Channel,
+		Target:         req.Target,
+		Mode:           req.Mode,
+		NotifiedHeight: record.LastActivityHeight,
+	}
+	if err := tracked_wallet_dao.SaveWalletNotification(ctx, notification); err != nil {
+		return nil, err
+	}
+	log.InfoWithContext(ctx, "设置钱包通知成功", "walletId:", walletId, "channel:", req.Channel, "mode:", req.Mode)
+	return GetWalletNotification(ctx, walletId)
+}
+
+// GetWalletNotification 获取钱包的通知设置
+func GetWalletNotification(ctx context.Context, walletId string) (*wallet.NotificationResponse, error) {
+	notification, err := tracked_wallet_dao.GetWalletNotification(ctx, walletId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &wallet.NotificationResponse{
+		WalletId:       notification.WalletId,
+		Channel:        notification.Channel,
+		Target:         notification.Target,
+		Mode:           notification.Mode,
+		NotifiedHeight: notification.NotifiedHeight,
+	}
+	if notification.LastSentAt != nil {
+		response.LastSentAt = notification.LastSentAt.Unix()
+	}
+	return response, nil
+}
+
+// DeleteWalletNotification 删除钱包的通知设置
+func DeleteWalletNotification(ctx context.Context, walletId string) error {
+	return tracked_wallet_dao.DeleteWalletNotification(ctx, walletId)
+}
+
+// notifyInstant 同步完成后为即时通知的钱包发送新交易通知，没有通知设置时不做任何事
+func notifyInstant(ctx context.Context, walletId string) {
+	notification, err := tracked_wallet_dao.GetWalletNotification(ctx, walletId)
+	if err != nil {
+		if !db.IsNotFound(err) {
+			log.WarnWithContext(ctx, "获取钱包通知设置失败", "walletId:", walletId, "错误:", err)
+		}
+		return
+	}
+	if notification.Mode != wallet.NotifyModeInstant {
+		return
+	}
+	if err := sendNotification(ctx, notification); err != nil {
+		log.WarnWithContext(ctx, "发送钱包即时通知失败", "walletId:", walletId, "错误:", err)
+	}
+}
+
+// StartDigest 启动每日汇总任务，每分钟检查一次距上次汇总已满一天的钱包，ctx取消时退出
+func StartDigest(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if len(notify.Channels()) == 0 {
+				continue
+			}
+			sendDueDigests(ctx, time.Now().Add(-digestInterval))
+		}
+	}()
+}
+
+// sendDueDigests 为上次汇总早于before的钱包发送每日汇总
+func sendDueDigests(ctx context.Context, before time.Time) {
+	notifications, err := tracked_wallet_dao.GetWalletNotificationsDue(ctx, wallet.NotifyModeDaily, before, digestBatchSize)
+	if err != nil {
+		return
+	}
+	for _, notification := range notifications {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := sendNotification(ctx, notification); err != nil {
+			log.WarnWithContext(ctx, "发送钱包每日汇总失败", "walletId:", notification.WalletId, "错误:", err)
+		}
+	}
+}
+
+// sendNotification 将已通知高度之后的新交易发送给接收方，发送成功后推进已通知高度
+// 没有新交易时不发送，只记录检查时间，每日汇总据此等待下一个周期
+func sendNotification(ctx context.Context, notification *dbtable.TrackedWalletNotification) error {
+	notifier, ok := notify.Get(notification.Channel)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotifierUnavailable, notification.Channel)
+	}
+
+	txs, err := tracked_wallet_dao.GetTrackedWalletTxsAbove(ctx, notification.WalletId, notification.NotifiedHeight)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if len(txs) == 0 {
+		return tracked_wallet_dao.MarkWalletNotified(ctx, notification.WalletId, notification.NotifiedHeight, now)
+	}
+
+	record, err := tracked_wallet_dao.GetTrackedWallet(ctx, notification.WalletId)
+	if err != nil {
+		return err
+	}
+	msg := buildNotification(record, notification, txs)
+	if err := notifier.Send(ctx, msg); err != nil {
+		return err
+	}
+	log.InfoWithContext(ctx, "发送钱包通知成功", "walletId:", notification.WalletId, "mode:", notification.Mode, "交易数量:", len(txs))
+	return tracked_wallet_dao.MarkWalletNotified(ctx, notification.WalletId, txs[len(txs)-1].Height, now)
+}
+
+// buildNotification 生成钱包新交易通知，txs按区块高度升序
+func buildNotification(record *dbtable.TrackedWallet, notification *dbtable.TrackedWalletNotification, txs []*dbtable.TrackedWalletTx) notify.Message {
+	name := record.Name
+	if name == "" {
+		name = record.WalletId
+	}
+	subject := fmt.Sprintf("钱包%s有%d笔新交易", name, len(txs))
+	if notification.Mode == wallet.NotifyModeDaily {
+		subject = fmt.Sprintf("钱包%s每日汇总：%d笔新交易", name, len(txs))
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "钱包: %s\n", name)
+	fmt.Fprintf(&body, "当前余额: %d聪 (已确认 %d, 未确认 %d)\n\n", record.Confirmed+record.Unconfirmed, record.Confirmed, record.Unconfirmed)
+	for i, tx := range txs {
+		if i == maxNotifyTxs {
+			fmt.Fprintf(&body, "另有%d笔交易未列出\n", len(txs)-maxNotifyTxs)
+			break
+		}
+		fmt.Fprintf(&body, "区块 %d  %s  %s\n", tx.Height, tx.TxHash, tx.Addresses)
+	}
+	return notify.Message{To: notification.Target, Subject: subject, Body: body.String()}
+}
@@ -0,0 +1,187 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ginproject/entity/utility"
+	"ginproject/entity/wallet"
+	"ginproject/middleware/log"
+	rpcex "ginproject/repo/rpc/electrumx"
+)
+
+// 恢复扫描时并发查询的地址数量，候选地址大多没有历史，查询很轻
+const recoveryConcurrency = 32
+
+// recoveryCandidate 待查询的候选地址
+type recoveryCandidate struct {
+	index      int // 扩展公钥扫描时的派生序号
+	address    string
+	scriptHash string
+	path       string
+}
+
+// recoveryCheck 候选地址的查询结果
+type recoveryCheck struct {
+	used        bool
+	firstTxHash string
+	firstHeight int64
+	err         error
+}
+
+// ScanRecovery 扫描候选地址或扩展公钥派生的地址，返回有链上历史的地址
+// 扩展公钥在收款和找零链上扫描，直到最后一个有历史的地址之后连续gap_limit个地址都没有历史为止
+// 候选地址不写入脚本哈希映射，避免大量空地址占用缓存
+func ScanRecovery(ctx context.Context, req *wallet.RecoveryScanRequest) (*wallet.RecoveryScanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+
+	resp := &wallet.RecoveryScanResponse{Used: []wallet.RecoveryAddress{}}
+	if req.Xpub == "" {
+		seen := make(map[string]bool, len(req.Addresses))
+		candidates := make([]recoveryCandidate, 0, len(req.Addresses))
+		for _, address := range req.Addresses {
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+			scriptHash, err := utility.AddressToScriptHash(address)
+			if err != nil {
+				return nil, fmt.Errorf("%w: 无效的地址%s: %v", ErrInvalidWallet, address, err)
+			}
+			candidates = append(candidates, recoveryCandidate{address: address, scriptHash: scriptHash})
+		}
+		collectRecoveryChecks(resp, candidates, checkRecoveryCandidates(ctx, candidates))
+	} else {
+		key, err := utility.ParseExtendedPubKey(req.Xpub)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+		}
+		for _, chain := range []uint32{0, 1} {
+			result, err := scanRecoveryChain(ctx, key, chain, req.GapLimit, resp)
+			if err != nil {
+				return nil, err
+			}
+			resp.Chains = append(resp.Chains, result)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if resp.Scanned > 0 && len(resp.Failed) == resp.Scanned {
+		return nil, fmt.Errorf("查询地址历史全部失败: %s", resp.Failed[0].Error)
+	}
+	log.InfoWithContext(ctx, "钱包恢复扫描完成", "scanned", resp.Scanned, "used", len(resp.Used), "failed", len(resp.Failed))
+	return resp, nil
+}
+
+// scanRecoveryChain 按地址间隔逐轮扫描扩展公钥的一条链，每轮并发查询到当前间隔的末尾
+// 本轮发现的历史会延长下一轮的范围，查询失败的地址按没有历史计算间隔
+func scanRecoveryChain(ctx context.Context, key *utility.ExtendedPubKey, chain uint32, gapLimit int, resp *wallet.RecoveryScanResponse) (wallet.RecoveryChain, error) {
+	result := wallet.RecoveryChain{Chain: chain, LastUsedIndex: -1}
+	chainKey, err := key.Child(chain)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+	}
+
+	for end := nextRecoveryWindow(result.Scanned, result.LastUsedIndex, gapLimit); end > result.Scanned; end = nextRecoveryWindow(result.Scanned, result.LastUsedIndex, gapLimit) {
+		candidates := make([]recoveryCandidate, 0, end-result.Scanned)
+		for index := result.Scanned; index < end; index++ {
+			child, err := chainKey.Child(uint32(index))
+			if err != nil {
+				// 按BIP32跳过无法派生的序号
+				continue
+			}
+			address := child.Address()
+			scriptHash, err := utility.AddressToScriptHash(address)
+			if err != nil {
+				return result, err
+			}
+			candidates = append(candidates, recoveryCandidate{
+				index:      index,
+				address:    address,
+				scriptHash: scriptHash,
+				path:       fmt.Sprintf("%d/%d", chain, index),
+			})
+		}
+
+		checks := checkRecoveryCandidates(ctx, candidates)
+		for i, check := range checks {
+			if check.used && candidates[i].index > result.LastUsedIndex {
+				result.LastUsedIndex = candidates[i].index
+			}
+		}
+		collectRecoveryChecks(resp, candidates, checks)
+		result.Scanned = end
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	result.Truncated = result.Scanned < result.LastUsedIndex+1+gapLimit
+	return result, nil
+}
+
+// nextRecoveryWindow 返回下一轮扫描的结束序号(不含)，等于已扫描数量时表示该链扫描完成
+func nextRecoveryWindow(scanned, lastUsedIndex, gapLimit int) int {
+	return max(scanned, min(lastUsedIndex+1+gapLimit, wallet.MaxRecoveryIndex))
+}
+
+// checkRecoveryCandidates 并发查询候选地址最早的一笔历史，结果顺序与候选顺序一致
+// 只需判断是否有历史，每个地址只保留第一条记录，上下文取消后不再发起新的查询
+func checkRecoveryCandidates(ctx context.Context, candidates []recoveryCandidate) []recoveryCheck {
+	checks := make([]recoveryCheck, len(candidates))
+	sem := make(chan struct{}, recoveryConcurrency)
+	var wg sync.WaitGroup
+
+	for i, candidate := range candidates {
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			checks[i].err = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int, candidate recoveryCandidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			history, err := rpcex.GetScriptHashHistoryFrom(ctx, candidate.scriptHash, 0, 1)
+			if err != nil {
+				checks[i].err = err
+				return
+			}
+			if len(history) > 0 {
+				checks[i] = recoveryCheck{used: true, firstTxHash: history[0].TxHash, firstHeight: history[0].Height}
+			}
+		}(i, candidate)
+	}
+	wg.Wait()
+	return checks
+}
+
+// collectRecoveryChecks 将查询结果合并到响应中
+func collectRecoveryChecks(resp *wallet.RecoveryScanResponse, candidates []recoveryCandidate, checks []recoveryCheck) {
+	resp.Scanned += len(candidates)
+	for i, check := range checks {
+		candidate := candidates[i]
+		switch {
+		case check.err != nil:
+			resp.Failed = append(resp.Failed, wallet.RecoveryFailure{
+				Address:        candidate.address,
+				DerivationPath: candidate.path,
+				Error:          check.err.Error(),
+			})
+		case check.used:
+			resp.Used = append(resp.Used, wallet.RecoveryAddress{
+				Address:        candidate.address,
+				DerivationPath: candidate.path,
+				FirstTxHash:    check.firstTxHash,
+				FirstHeight:    check.firstHeight,
+			})
+		}
+	}
+}
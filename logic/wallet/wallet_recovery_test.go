@@ -0,0 +1,29 @@
+package wallet
+
+import (
+	"testing"
+
+	"ginproject/entity/wallet"
+)
+
+func TestNextRecoveryWindow(t *testing.T) {
+	// 首轮扫描0到gap-1
+	if end := nextRecoveryWindow(0, -1, 20); end != 20 {
+		t.Errorf("首轮结束序号 = %d, 期望 20", end)
+	}
+	// 首轮没有发现历史时扫描完成
+	if end := nextRecoveryWindow(20, -1, 20); end != 20 {
+		t.Errorf("没有历史时应停止扫描: %d", end)
+	}
+	// 序号15有历史时扫描到序号35
+	if end := nextRecoveryWindow(20, 15, 20); end != 36 {
+		t.Errorf("发现历史后结束序号 = %d, 期望 36", end)
+	}
+	// 不超过最大扫描序号
+	if end := nextRecoveryWindow(wallet.MaxRecoveryIndex-5, wallet.MaxRecoveryIndex-10, 20); end != wallet.MaxRecoveryIndex {
+		t.Errorf("结束序号 = %d, 期望 %d", end, wallet.MaxRecoveryIndex)
+	}
+	if end := nextRecoveryWindow(wallet.MaxRecoveryIndex, wallet.MaxRecoveryIndex-1, 20); end != wallet.MaxRecoveryIndex {
+		t.Errorf("达到最大扫描序号后应停止: %d", end)
+	}
+}
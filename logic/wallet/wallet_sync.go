@@ -0,0 +1,236 @@
+package wallet
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/repo/db/tracked_wallet_dao"
+	"ginproject/repo/rpc/blockchain"
+	rpcex "ginproject/repo/rpc/electrumx"
+)
+
+const (
+	// 同步单个钱包时并发查询的地址数量
+	syncConcurrency = 8
+	// 后台任务每轮最多同步的钱包数量
+	syncBatchSize = 50
+	// 同步失败后的首次退避时间，之后每次失败翻倍
+	syncRetryBase = time.Minute
+	// 同步失败后的最长退避时间
+	syncRetryMax = 6 * time.Hour
+	// 等待后台任务同步的新建钱包数量，队列满时由下一轮周期同步处理
+	syncQueueSize = 100
+)
+
+// syncQueue 等待后台任务立即同步的钱包ID
+var syncQueue = make(chan string, syncQueueSize)
+
+// addressState 单个地址的同步结果
+type addressState struct {
+	address     string
+	confirmed   int64
+	unconfirmed int64
+	history     []historyEntry
+	err         error
+}
+
+type historyEntry struct {
+	txHash string
+	height int64
+}
+
+// SyncWallet 查询钱包内监听中地址的余额和历史，合并后保存为钱包汇总数据
+// 同步前先停止监听已过期的地址，已停止监听的地址不再计入余额和交易历史
+// 任一地址查询失败时放弃本次同步，保留上一次的结果，并按连续失败次数推迟下一次重试
+func SyncWallet(ctx context.Context, walletId string) error {
+	err := syncWallet(ctx, walletId)
+	if err == nil || db.IsNotFound(err) || ctx.Err() != nil {
+		return err
+	}
+	if recordErr := tracked_wallet_dao.RecordWalletSyncFailure(ctx, walletId, time.Now(), syncBackoff); recordErr != nil && !db.IsNotFound(recordErr) {
+		log.WarnWithContext(ctx, "记录跟踪钱包同步失败出错", "walletId:", walletId, "错误:", recordErr)
+	}
+	return err
+}
+
+// syncBackoff 返回连续失败failures次后的退避时间
+func syncBackoff(failures int) time.Duration {
+	backoff := syncRetryBase
+	for i := 1; i < failures && backoff < syncRetryMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, syncRetryMax)
+}
+
+// enqueueSync 请求后台任务尽快同步钱包，不等待同步完成
+// 队列已满或后台任务未运行时不做处理，从未同步的钱包在下一轮周期同步中排在最前
+func enqueueSync(walletId string) {
+	select {
+	case syncQueue <- walletId:
+	default:
+	}
+}
+
+func syncWallet(ctx context.Context, walletId string) error {
+	if expired, err := tracked_wallet_dao.ExpireWalletAddresses(ctx, walletId, time.Now()); err != nil {
+		return err
+	} else if expired > 0 {
+		log.InfoWithContext(ctx, "跟踪钱包地址已过期，停止监听", "walletId:", walletId, "地址数量:", expired)
+	}
+	addresses, err := tracked_wallet_dao.GetWatchingWalletAddresses(ctx, walletId)
+	if err != nil {
+		return err
+	}
+
+	states := fetchAddressStates(ctx, addresses)
+	record := &dbtable.TrackedWallet{WalletId: walletId}
+	txs := make(map[string]*dbtable.TrackedWalletTx)
+	for _, state := range states {
+		if state.err != nil {
+			log.WarnWithContext(ctx, "同步钱包地址失败", "walletId:", walletId, "address:", state.address, "错误:", state.err)
+			return state.err
+		}
+		record.Confirmed += state.confirmed
+		record.Unconfirmed += state.unconfirmed
+		for _, entry := range state.history {
+			tx, ok := txs[entry.txHash]
+			if !ok {
+				tx = &dbtable.TrackedWalletTx{WalletId: walletId, TxHash: entry.txHash, Height: entry.height}
+				txs[entry.txHash] = tx
+			} else {
+				tx.Addresses += ","
+			}
+			tx.Addresses += state.address
+			if entry.height > record.LastActivityHeight {
+				record.LastActivityHeight = entry.height
+			}
+		}
+	}
+
+	rows := make([]*dbtable.TrackedWalletTx, 0, len(txs))
+	for _, tx := range txs {
+		rows = append(rows, tx)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].TxHash < rows[j].TxHash })
+	record.TxCount = len(rows)
+	record.LastActivityTime = blockTime(ctx, record.LastActivityHeight)
+	now := time.Now()
+	record.SyncedAt = &now
+
+	if err := tracked_wallet_dao.SaveTrackedWalletRollup(ctx, record, rows); err != nil {
+		return err
+	}
+	log.InfoWithContext(ctx, "同步跟踪钱包完成", "walletId:", walletId, "地址数量:", len(addresses), "交易数量:", record.TxCount)
+	recordDeposits(ctx, walletId, addresses, states)
+
+	notifyInstant(ctx, walletId)
+	return nil
+}
+
+// fetchAddressStates 并发查询各地址的余额和历史，结果顺序与地址顺序一致
+func fetchAddressStates(ctx context.Context, addresses []*dbtable.TrackedWalletAddress) []addressState {
+	states := make([]addressState, len(addresses))
+	sem := make(chan struct{}, syncConcurrency)
+	var wg sync.WaitGroup
+
+	for i, addr := range addresses {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, addr *dbtable.TrackedWalletAddress) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			state := addressState{address: addr.Address}
+			balance, err := rpcex.GetBalance(ctx, addr.ScriptHash)
+			if err != nil {
+				state.err = err
+				states[i] = state
+				return
+			}
+			state.confirmed = balance.Confirmed
+			state.unconfirmed = balance.Unconfirmed
+
+			history, err := rpcex.GetScriptHashHistory(ctx, addr.ScriptHash)
+			if err != nil {
+				state.err = err
+				states[i] = state
+				return
+			}
+			for _, item := range history {
+				state.history = append(state.history, historyEntry{txHash: item.TxHash, height: item.Height})
+			}
+			states[i] = state
+		}(i, addr)
+	}
+	wg.Wait()
+	return states
+}
+
+// blockTime 获取区块时间戳，失败时返回0
+func blockTime(ctx context.Context, height int64) int64 {
+	if height <= 0 {
+		return 0
+	}
+	result := <-blockchain.FetchBlockHeaderByHeight(ctx, height)
+	if result.Error != nil {
+		log.WarnWithContext(ctx, "获取区块时间失败", "height:", height, "错误:", result.Error)
+		return 0
+	}
+	header, _ := result.Result.(map[string]interface{})
+	if t, ok := header["time"].(float64); ok {
+		return int64(t)
+	}
+	return 0
+}
+
+// StartSyncer 启动后台同步任务，按配置周期刷新到期的钱包，ctx取消时退出
+func StartSyncer(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+
+		var lastRun time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case walletId := <-syncQueue:
+				if err := SyncWallet(ctx, walletId); err != nil {
+					log.WarnWithContext(ctx, "同步新建的跟踪钱包失败，等待后台重试", "walletId:", walletId, "错误:", err)
+				}
+				continue
+			case <-ticker.C:
+			}
+
+			// 每次检查时重新读取配置，支持热更新周期
+			interval := time.Duration(config.GetConfig().GetWalletConfig().SyncInterval) * time.Second
+			if interval <= 0 || time.Since(lastRun) < interval {
+				continue
+			}
+			lastRun = time.Now()
+			syncDueWallets(ctx, lastRun.Add(-interval), lastRun)
+		}
+	}()
+}
+
+// syncDueWallets 同步上次尝试早于before且已过失败退避期的钱包
+func syncDueWallets(ctx context.Context, before, now time.Time) {
+	walletIds, err := tracked_wallet_dao.GetWalletIdsDueForSync(ctx, before, now, syncBatchSize)
+	if err != nil {
+		return
+	}
+	for _, walletId := range walletIds {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := SyncWallet(ctx, walletId); err != nil {
+			log.WarnWithContext(ctx, "后台同步跟踪钱包失败", "walletId:", walletId, "错误:", err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncBackoff(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, syncRetryMax},
+		{100, syncRetryMax},
+	}
+	for _, tc := range cases {
+		if got := syncBackoff(tc.failures); got != tc.want {
+			t.Errorf("syncBackoff(%d) = %v, 期望 %v", tc.failures, got, tc.want)
+		}
+	}
+}
+
+func TestEnqueueSyncDoesNotBlock(t *testing.T) {
+	defer func() {
+		for len(syncQueue) > 0 {
+			<-syncQueue
+		}
+	}()
+	// 队列满时直接丢弃，等待周期同步处理
+	for i := 0; i < syncQueueSize+10; i++ {
+		enqueueSync("wallet")
+	}
+	if len(syncQueue) != syncQueueSize {
+		t.Errorf("队列长度 = %d", len(syncQueue))
+	}
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"ginproject/entity/config"
+	"ginproject/entity/constant"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader 管理接口的令牌请求头
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminToken 返回校验管理令牌的中间件
+// 未配置令牌时拒绝所有管理请求，避免管理接口在默认配置下对外暴露
+func AdminToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := config.GetConfig().GetAdminConfig().Token
+		provided := c.GetHeader(AdminTokenHeader)
+
+		if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(provided)) != 1 {
+			log.WarnWithContext(c.Request.Context(), "管理接口鉴权失败", "path:", c.FullPath(), "ip:", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, utility.NewErrorResponse(constant.CodeUnauthorized, "管理令牌无效"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/constant"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 集群模式下只读开关在Redis中的键
+	readOnlyKey = "readonly"
+	// 集群模式下从Redis同步只读开关的周期
+	readOnlySyncInterval = time.Second
+)
+
+// readOnlyOverride 运行时设置的只读状态，为空时以配置文件为准
+var readOnlyOverride atomic.Pointer[bool]
+
+// IsReadOnly 判断当前是否处于只读模式
+func IsReadOnly() bool {
+	if override := readOnlyOverride.Load(); override != nil {
+		return *override
+	}
+	return config.GetConfig().GetServerConfig().ReadOnly
+}
+
+// SetReadOnly 在运行时切换只读模式，优先于配置文件，重启后恢复为配置文件的设置
+func SetReadOnly(enabled bool) {
+	readOnlyOverride.Store(&enabled)
+}
+
+// SwitchReadOnly 在运行时切换只读模式，集群模式下同时写入Redis，其它实例在一个同步周期内生效
+// 集群模式下的设置保存在Redis中，重启后仍然有效
+func SwitchReadOnly(ctx context.Context, enabled bool) error {
+	if shared := cache.Shared(); shared != nil {
+		if err := shared.Client().Set(ctx, shared.Key(readOnlyKey), strconv.FormatBool(enabled), 0).Err(); err != nil {
+			return fmt.Errorf("写入共享只读开关失败: %w", err)
+		}
+	}
+	SetReadOnly(enabled)
+	return nil
+}
+
+// StartReadOnlySync 集群模式下定期从Redis同步只读开关，ctx取消时退出；未开启集群模式时不做任何事
+func StartReadOnlySync(ctx context.Context) {
+	if cache.Shared() == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(readOnlySyncInterval)
+		defer ticker.Stop()
+
+		for {
+			syncReadOnly(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// syncReadOnly 从Redis读取只读开关，Redis中没有设置时以配置文件为准，读取失败时保持当前状态
+func syncReadOnly(ctx context.Context) {
+	shared := cache.Shared()
+	if shared == nil {
+		return
+	}
+	value, err := shared.Client().Get(ctx, shared.Key(readOnlyKey)).Result()
+	if errors.Is(err, redis.Nil) {
+		readOnlyOverride.Store(nil)
+		return
+	}
+	if err != nil {
+		log.WarnWithContext(ctx, "同步共享只读开关失败", "错误:", err)
+		return
+	}
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		SetReadOnly(enabled)
+	}
+}
+
+// ReadOnly 返回只读模式下拒绝写入请求的中间件
+func ReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsReadOnly() {
+			log.InfoWithContext(c.Request.Context(), "只读模式拒绝写入请求", "path:", c.FullPath(), "ip:", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusForbidden, utility.NewErrorResponse(constant.CodeForbidden, "当前服务为只读模式，不支持该操作"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/broadcast", ReadOnly(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	t.Cleanup(func() { readOnlyOverride.Store(nil) })
+
+	tests := []struct {
+		readOnly bool
+		want     int
+	}{
+		{readOnly: true, want: http.StatusForbidden},
+		{readOnly: false, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		SetReadOnly(tt.readOnly)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/broadcast", nil))
+		if w.Code != tt.want {
+			t.Errorf("readOnly=%v 状态码: %d, 期望 %d", tt.readOnly, w.Code, tt.want)
+		}
+	}
+}
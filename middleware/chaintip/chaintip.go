@@ -0,0 +1,215 @@
+package chaintip
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/blockchain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 链顶信息响应头
+const (
+	HeaderTipHeight = "X-Chain-Tip-Height"
+	HeaderTipHash   = "X-Chain-Tip-Hash"
+)
+
+const (
+	// 链顶缓存时间，出块间隔远大于该值，足以让同一时刻的请求共享一次节点查询
+	tipCacheTTL = 2 * time.Second
+	// 区块监听器运行时链顶的最长有效期，监听器停止更新后退回按需查询
+	listenerTipTTL = 30 * time.Second
+	// 查询链顶的超时时间，超时后本次响应不带链顶信息
+	tipFetchTimeout = time.Second
+	// 查询链顶失败后直接返回该错误的时间，节点不可用时不让每个请求都等待一次查询超时
+	tipFailureTTL = time.Second
+)
+
+// Tip 链顶信息
+type Tip struct {
+	Height int64
+	Hash   string
+}
+
+var (
+	mu        sync.Mutex
+	cached    Tip
+	fetchedAt time.Time
+	listening bool
+	// 链顶变化时关闭并替换为新的通道
+	changed = make(chan struct{})
+	// 正在进行的节点查询，同一时刻只有一个，其它请求等待它的结果
+	fetching *tipFetch
+	// 最近一次查询失败的错误和时间
+	fetchErr error
+	failedAt time.Time
+)
+
+// 查询链顶的函数，便于替换
+var fetchTip = fetchNodeTip
+
+// tipFetch 一次节点查询，完成时关闭done
+type tipFetch struct {
+	done chan struct{}
+	tip  Tip
+	err  error
+}
+
+// run 查询节点并更新缓存的链顶，查询不随发起请求的取消而中断，由tipFetchTimeout限制时长
+func (f *tipFetch) run(ctx context.Context) {
+	tip, err := fetchTip(ctx)
+
+	mu.Lock()
+	if err != nil {
+		fetchErr, failedAt = err, time.Now()
+	} else {
+		fetchErr = nil
+		updateLocked(tip)
+	}
+	f.tip, f.err = cached, err
+	fetching = nil
+	mu.Unlock()
+	close(f.done)
+}
+
+// Current 获取当前链顶
+// 区块监听器运行时直接返回其维护的链顶，否则按需查询节点并短时间缓存
+// 查询在锁外进行，并发的请求共享同一次查询；查询失败后短时间内直接返回失败
+func Current(ctx context.Context) (Tip, error) {
+	mu.Lock()
+	ttl := tipCacheTTL
+	if listening {
+		ttl = listenerTipTTL
+	}
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) < ttl {
+		tip := cached
+		mu.Unlock()
+		return tip, nil
+	}
+	if fetchErr != nil && time.Since(failedAt) < tipFailureTTL {
+		err := fetchErr
+		mu.Unlock()
+		return Tip{}, err
+	}
+	f := fetching
+	if f == nil {
+		f = &tipFetch{done: make(chan struct{})}
+		fetching = f
+		go f.run(context.WithoutCancel(ctx))
+	}
+	mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return Tip{}, f.err
+		}
+		return f.tip, nil
+	case <-ctx.Done():
+		return Tip{}, ctx.Err()
+	}
+}
+
+// fetchNodeTip 从节点查询当前链顶
+func fetchNodeTip(ctx context.Context) (Tip, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, tipFetchTimeout)
+	defer cancel()
+
+	result := <-blockchain.FetchChainInfo(fetchCtx)
+	if result.Error != nil {
+		return Tip{}, result.Error
+	}
+	info, ok := result.Result.(map[string]interface{})
+	if !ok {
+		return Tip{}, fmt.Errorf("区块链信息格式错误")
+	}
+	height, ok := info["blocks"].(float64)
+	if !ok {
+		return Tip{}, fmt.Errorf("区块链信息缺少blocks字段")
+	}
+	hash, ok := info["bestblockhash"].(string)
+	if !ok {
+		return Tip{}, fmt.Errorf("区块链信息缺少bestblockhash字段")
+	}
+	return Tip{Height: int64(height), Hash: hash}, nil
+}
+
+// updateLocked 更新缓存的链顶，链顶回退或同高度换块时视为重组，清空区块高度和解码交易缓存，调用方需持有锁
+func updateLocked(tip Tip) {
+	if cached.Hash != "" && tip.Hash != cached.Hash && tip.Height <= cached.Height {
+		log.Warnf("检测到链重组: 高度%d(%s) -> 高度%d(%s)", cached.Height, cached.Hash, tip.Height, tip.Hash)
+		blockHeights.Purge()
+		blockchain.PurgeDecodedTxs()
+	}
+	if tip.Hash != cached.Hash {
+		close(changed)
+		changed = make(chan struct{})
+	}
+	cached = tip
+	fetchedAt = time.Now()
+}
+
+// Changed 返回链顶下一次变化时关闭的通道
+// 调用方应先获取通道再读取链顶，避免错过两者之间出现的新块
+func Changed() <-chan struct{} {
+	mu.Lock()
+	defer mu.Unlock()
+	return changed
+}
+
+// Next 等待链顶变为after以外的区块，链顶已经不是after时立即返回
+// 区块监听器未运行时按缓存周期主动查询链顶；ctx结束时返回ctx的错误
+func Next(ctx context.Context, after string) (Tip, error) {
+	ticker := time.NewTicker(tipCacheTTL)
+	defer ticker.Stop()
+
+	for {
+		ch := Changed()
+		tip, err := Current(ctx)
+		if err == nil && tip.Hash != after {
+			return tip, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return Tip{}, ctx.Err()
+		case <-ch:
+		case <-ticker.C:
+		}
+	}
+}
+
+type tipContextKey struct{}
+
+// Snapshot 获取本次请求使用的链顶，同一请求内多次调用返回同一个值
+// 经过Headers中间件的请求直接使用中间件记录的链顶，保证响应头与响应体一致
+func Snapshot(ctx context.Context) (Tip, error) {
+	if tip, ok := ctx.Value(tipContextKey{}).(Tip); ok {
+		return tip, nil
+	}
+	return Current(ctx)
+}
+
+// Headers 返回在响应中附带链顶信息的中间件
+// 链顶在处理请求前获取并记录到请求上下文，客户端比较两次相关请求的链顶即可判断中间是否出了新块；获取失败时不影响请求本身
+func Headers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		tip, err := Current(ctx)
+		if err != nil {
+			log.WarnWithContextf(ctx, "获取链顶信息失败: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(context.WithValue(ctx, tipContextKey{}, tip))
+		c.Header(HeaderTipHeight, strconv.FormatInt(tip.Height, 10))
+		c.Header(HeaderTipHash, tip.Hash)
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package chaintip
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/repo/cache"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// 区块哈希到高度的缓存，检测到重组时整体清空
+var blockHeights = cache.NewLRU[string, int64](10000, time.Hour)
+
+func init() {
+	cache.Register("block_heights", blockHeights)
+}
+
+// Confirmations 按给定链顶计算确认数，未确认(height<=0)返回0
+// 高度超过链顶说明区块比本次使用的链顶更新，仍按1个确认计算
+func Confirmations(tip Tip, height int64) int64 {
+	if height <= 0 {
+		return 0
+	}
+	if height > tip.Height {
+		return 1
+	}
+	return tip.Height - height + 1
+}
+
+// BlockHeight 获取主链上区块的高度，区块已不在主链上时返回false
+func BlockHeight(ctx context.Context, blockHash string) (int64, bool, error) {
+	if height, ok := blockHeights.Get(blockHash); ok {
+		return height, true, nil
+	}
+
+	result := <-blockchain.FetchBlockHeaderByHash(ctx, blockHash)
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	header, ok := result.Result.(map[string]interface{})
+	if !ok {
+		return 0, false, fmt.Errorf("区块头格式错误")
+	}
+	height, ok := header["height"].(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("区块头缺少height字段")
+	}
+	// 节点对不在主链上的区块返回-1个确认
+	if confirmations, _ := header["confirmations"].(float64); confirmations < 0 {
+		return int64(height), false, nil
+	}
+
+	blockHeights.Set(blockHash, int64(height))
+	return int64(height), true, nil
+}
+
+// BlockConfirmations 按给定链顶计算区块的确认数，区块已不在主链上时返回0
+func BlockConfirmations(ctx context.Context, tip Tip, blockHash string) (int64, int64, error) {
+	if blockHash == "" {
+		return 0, 0, nil
+	}
+	height, onMainChain, err := BlockHeight(ctx, blockHash)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !onMainChain {
+		return height, 0, nil
+	}
+	return height, Confirmations(tip, height), nil
+}
+
+// RecountBlock 按给定链顶重新计算节点返回的区块或区块头中的确认数
+// 节点对不在主链上的区块返回负数，保持为0
+func RecountBlock(tip Tip, block map[string]interface{}) {
+	height, ok := block["height"].(float64)
+	if !ok {
+		return
+	}
+	if confirmations, _ := block["confirmations"].(float64); confirmations < 0 {
+		block["confirmations"] = 0
+		return
+	}
+	block["confirmations"] = Confirmations(tip, int64(height))
+}
+
+// NoHeightLimit 不按确认数过滤时使用的高度上限
+const NoHeightLimit int64 = -1
+
+// ConfirmedHeightLimit 返回至少有minConfirmations个确认的最高区块高度，minConfirmations不大于0时返回NoHeightLimit
+// 链顶高度不足时返回0，任何已确认的区块都不满足
+func ConfirmedHeightLimit(tip Tip, minConfirmations int64) int64 {
+	if minConfirmations <= 0 {
+		return NoHeightLimit
+	}
+	return max(tip.Height-minConfirmations+1, 0)
+}
+
+// HeightLimit 按本次请求的链顶计算ConfirmedHeightLimit，不需要过滤时不查询链顶
+func HeightLimit(ctx context.Context, minConfirmations int64) (int64, error) {
+	if minConfirmations <= 0 {
+		return NoHeightLimit, nil
+	}
+	tip, err := Snapshot(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取链顶失败: %w", err)
+	}
+	return ConfirmedHeightLimit(tip, minConfirmations), nil
+}
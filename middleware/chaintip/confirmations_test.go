@@ -0,0 +1,176 @@
+package chaintip
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfirmations(t *testing.T) {
+	tip := Tip{Height: 100, Hash: "tip"}
+	tests := []struct {
+		height int64
+		want   int64
+	}{
+		{height: 0, want: 0},
+		{height: -1, want: 0},
+		{height: 100, want: 1},
+		{height: 91, want: 10},
+		{height: 1, want: 100},
+		{height: 101, want: 1},
+	}
+	for _, tt := range tests {
+		if got := Confirmations(tip, tt.height); got != tt.want {
+			t.Errorf("Confirmations(%d) = %d, 期望 %d", tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestConfirmedHeightLimit(t *testing.T) {
+	tip := Tip{Height: 100, Hash: "tip"}
+	tests := []struct {
+		minConf int64
+		want    int64
+	}{
+		{minConf: 0, want: NoHeightLimit},
+		{minConf: 1, want: 100},
+		{minConf: 6, want: 95},
+		{minConf: 200, want: 0},
+	}
+	for _, tt := range tests {
+		got := ConfirmedHeightLimit(tip, tt.minConf)
+		if got != tt.want {
+			t.Errorf("ConfirmedHeightLimit(%d) = %d, 期望 %d", tt.minConf, got, tt.want)
+		}
+		// 高度上限处的交易恰好满足最小确认数
+		if got > 0 && Confirmations(tip, got) != tt.minConf {
+			t.Errorf("高度 %d 的确认数 = %d, 期望 %d", got, Confirmations(tip, got), tt.minConf)
+		}
+	}
+}
+
+func TestUpdateDetectsReorg(t *testing.T) {
+	mu.Lock()
+	defer mu.Unlock()
+	defer func() { cached, fetchedAt = Tip{}, fetchedAt.AddDate(-1, 0, 0) }()
+
+	cached = Tip{Height: 100, Hash: "a"}
+	blockHeights.Set("orphan", 100)
+
+	// 出新块不清空缓存
+	updateLocked(Tip{Height: 101, Hash: "b"})
+	if _, ok := blockHeights.Get("orphan"); !ok {
+		t.Fatal("正常出块不应清空区块高度缓存")
+	}
+
+	// 同高度换块视为重组
+	updateLocked(Tip{Height: 101, Hash: "c"})
+	if _, ok := blockHeights.Get("orphan"); ok {
+		t.Fatal("重组后应清空区块高度缓存")
+	}
+}
+
+func TestNext(t *testing.T) {
+	mu.Lock()
+	listening = true
+	cached, fetchedAt = Tip{Height: 100, Hash: "a"}, time.Now()
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		listening = false
+		cached, fetchedAt = Tip{}, time.Time{}
+		mu.Unlock()
+	}()
+
+	// 链顶已经变化时立即返回
+	if tip, err := Next(context.Background(), "old"); err != nil || tip.Hash != "a" {
+		t.Fatalf("Next = %+v, %v", tip, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Next(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("没有新块时应等到超时，实际: %v", err)
+	}
+
+	done := make(chan Tip)
+	go func() {
+		tip, _ := Next(context.Background(), "a")
+		done <- tip
+	}()
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	updateLocked(Tip{Height: 101, Hash: "b"})
+	mu.Unlock()
+
+	select {
+	case tip := <-done:
+		if tip.Height != 101 {
+			t.Fatalf("应返回新链顶，实际: %+v", tip)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("出新块后未返回")
+	}
+}
+
+func TestCurrentSharesFetch(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fail := errors.New("node down")
+	var failing atomic.Bool
+	saved := fetchTip
+	fetchTip = func(ctx context.Context) (Tip, error) {
+		calls.Add(1)
+		if failing.Load() {
+			return Tip{}, fail
+		}
+		<-release
+		return Tip{Height: 100, Hash: "a"}, nil
+	}
+	reset := func() {
+		mu.Lock()
+		cached, fetchedAt, fetchErr, failedAt = Tip{}, time.Time{}, nil, time.Time{}
+		mu.Unlock()
+	}
+	reset()
+	defer func() {
+		fetchTip = saved
+		reset()
+	}()
+
+	// 并发的请求共享一次查询，查询期间不持有锁
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tip, err := Current(context.Background()); err != nil || tip.Hash != "a" {
+				t.Errorf("Current = %+v, %v", tip, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if ch := Changed(); ch == nil {
+		t.Fatal("查询期间应能获取锁")
+	}
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("节点查询次数 = %d, 期望 1", n)
+	}
+
+	// 查询失败后短时间内直接返回失败，不再查询节点
+	reset()
+	failing.Store(true)
+	for i := 0; i < 3; i++ {
+		if _, err := Current(context.Background()); !errors.Is(err, fail) {
+			t.Fatalf("第%d次查询错误 = %v", i+1, err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("失败后节点查询次数 = %d, 期望 2", n)
+	}
+}
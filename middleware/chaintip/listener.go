@@ -0,0 +1,79 @@
+package chaintip
+
+import (
+	"context"
+	"time"
+
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/blockchain"
+)
+
+// 区块监听器轮询节点的周期
+const listenInterval = 2 * time.Second
+
+// StartListener 启动区块监听器，在后台持续刷新链顶，ctx取消时退出
+// 出现新块时校验其前一区块是否为当前链顶，不连续时按重组处理
+func StartListener(ctx context.Context) {
+	mu.Lock()
+	listening = true
+	mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(listenInterval)
+		defer ticker.Stop()
+		defer func() {
+			mu.Lock()
+			listening = false
+			mu.Unlock()
+		}()
+
+		for {
+			poll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// poll 查询一次链顶并更新缓存
+func poll(ctx context.Context) {
+	tip, err := fetchTip(ctx)
+	if err != nil {
+		log.Warnf("区块监听器获取链顶失败: %v", err)
+		return
+	}
+
+	mu.Lock()
+	previous := cached
+	mu.Unlock()
+
+	if previous.Hash != "" && tip.Height > previous.Height && !extendsTip(ctx, tip, previous) {
+		log.Warnf("新链顶%d(%s)不是在原链顶%d(%s)之上延伸，按重组处理", tip.Height, tip.Hash, previous.Height, previous.Hash)
+		blockHeights.Purge()
+		blockchain.PurgeDecodedTxs()
+	}
+
+	mu.Lock()
+	updateLocked(tip)
+	mu.Unlock()
+}
+
+// extendsTip 判断新链顶是否直接延伸自原链顶，一次出现多个块时无法廉价校验，保守地返回false
+func extendsTip(ctx context.Context, tip, previous Tip) bool {
+	if tip.Height != previous.Height+1 {
+		return false
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, tipFetchTimeout)
+	defer cancel()
+	result := <-blockchain.FetchBlockHeaderByHash(fetchCtx, tip.Hash)
+	if result.Error != nil {
+		return false
+	}
+	header, _ := result.Result.(map[string]interface{})
+	prevHash, _ := header["previousblockhash"].(string)
+	return prevHash == previous.Hash
+}
@@ -0,0 +1,124 @@
+package consistency
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/repo/storage"
+)
+
+// 一致性令牌的查询参数和响应头
+const (
+	QueryParam        = "after"
+	HeaderConsistency = "X-Consistency"
+)
+
+const (
+	// 令牌格式版本前缀，v2起令牌带有签名
+	tokenPrefix = "v2."
+	// 单个令牌最多携带的交易数量，批量广播超过时只保留最后的交易
+	maxTokenTxids = 20
+	// 令牌有效期，超过后交易早已可见或已被丢弃，读取接口不再等待
+	tokenTTL = 10 * time.Minute
+	// 允许的签发时间超前量，容忍多实例之间的时钟误差
+	maxClockSkew = time.Minute
+)
+
+// 令牌签名器，按配置的密钥创建，未配置时每次启动随机生成
+var (
+	signerOnce  sync.Once
+	tokenSigner *storage.Signer
+)
+
+func signer() *storage.Signer {
+	signerOnce.Do(func() {
+		tokenSigner = storage.NewSigner(config.GetConfig().GetServerConfig().ConsistencyKey)
+	})
+	return tokenSigner
+}
+
+// ErrInvalidToken 令牌格式无效
+var ErrInvalidToken = errors.New("一致性令牌无效")
+
+// Token 广播成功后返回的一致性令牌，读取接口据此等待交易进入内存池
+type Token struct {
+	TxIDs    []string `json:"txids"`
+	IssuedAt int64    `json:"iat"`
+}
+
+// Issue 为广播成功的交易生成一致性令牌，没有交易时返回空字符串
+func Issue(txids ...string) string {
+	return issueAt(time.Now(), txids)
+}
+
+func issueAt(now time.Time, txids []string) string {
+	if len(txids) == 0 {
+		return ""
+	}
+	if len(txids) > maxTokenTxids {
+		// 批量广播中后面的交易通常依赖前面的交易，后面的可见时前面的必然可见
+		txids = txids[len(txids)-maxTokenTxids:]
+	}
+	data, err := json.Marshal(Token{TxIDs: txids, IssuedAt: now.Unix()})
+	if err != nil {
+		return ""
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return tokenPrefix + payload + "." + signer().Sign(payload, now.Add(tokenTTL))
+}
+
+// Parse 解析一致性令牌，校验签名、签发时间和其中的交易ID
+// 签名覆盖令牌内容和到期时间，客户端无法伪造令牌或延长有效期；已过期的令牌仍正常返回，由Expired判断
+func Parse(token string) (*Token, error) {
+	return parseAt(time.Now(), token)
+}
+
+func parseAt(now time.Time, token string) (*Token, error) {
+	body, ok := strings.CutPrefix(token, tokenPrefix)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	payload, signature, ok := strings.Cut(body, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var t Token
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, ErrInvalidToken
+	}
+	expires := time.Unix(t.IssuedAt, 0).Add(tokenTTL).Unix()
+	if err := signer().Verify(payload, expires, signature, now); errors.Is(err, storage.ErrSignatureInvalid) {
+		return nil, ErrInvalidToken
+	}
+	if time.Unix(t.IssuedAt, 0).After(now.Add(maxClockSkew)) {
+		return nil, ErrInvalidToken
+	}
+	if len(t.TxIDs) == 0 || len(t.TxIDs) > maxTokenTxids {
+		return nil, ErrInvalidToken
+	}
+	for _, txid := range t.TxIDs {
+		if len(txid) != 64 {
+			return nil, ErrInvalidToken
+		}
+		if _, err := hex.DecodeString(txid); err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+	return &t, nil
+}
+
+// Expired 判断令牌是否已超过有效期
+func (t *Token) Expired(now time.Time) bool {
+	return now.Sub(time.Unix(t.IssuedAt, 0)) > tokenTTL
+}
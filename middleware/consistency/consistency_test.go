@@ -0,0 +1,93 @@
+package consistency
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ginproject/repo/storage"
+)
+
+func TestIssueAndParse(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	now := time.Now()
+
+	token, err := Parse(issueAt(now, []string{txid}))
+	if err != nil {
+		t.Fatalf("解析令牌失败: %v", err)
+	}
+	if len(token.TxIDs) != 1 || token.TxIDs[0] != txid || token.IssuedAt != now.Unix() {
+		t.Fatalf("令牌内容不正确: %+v", token)
+	}
+	if token.Expired(now) || !token.Expired(now.Add(tokenTTL+time.Second)) {
+		t.Fatal("令牌有效期判断不正确")
+	}
+
+	// 超过上限时只保留最后的交易
+	txids := make([]string, maxTokenTxids+5)
+	for i := range txids {
+		txids[i] = txid
+	}
+	txids[len(txids)-1] = strings.Repeat("cd", 32)
+	token, err = Parse(Issue(txids...))
+	if err != nil || len(token.TxIDs) != maxTokenTxids || token.TxIDs[maxTokenTxids-1] != txids[len(txids)-1] {
+		t.Fatalf("截断交易不正确: %+v, %v", token, err)
+	}
+
+	if Issue() != "" {
+		t.Fatal("没有交易时不应生成令牌")
+	}
+	for _, bad := range []string{"", "v1.abc", "v2.!!!", tokenPrefix, issueAt(now, []string{"xyz"})} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Parse(%q) 错误 = %v, 期望 ErrInvalidToken", bad, err)
+		}
+	}
+}
+
+func TestParseRejectsForgedTokens(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	now := time.Now()
+
+	// 客户端自行构造的未签名令牌
+	data, _ := json.Marshal(Token{TxIDs: []string{txid}, IssuedAt: now.Unix()})
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	if _, err := Parse(tokenPrefix + payload); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("未签名令牌错误 = %v", err)
+	}
+
+	// 修改内容后签名不再匹配
+	token := issueAt(now, []string{txid})
+	_, signature, _ := strings.Cut(strings.TrimPrefix(token, tokenPrefix), ".")
+	future, _ := json.Marshal(Token{TxIDs: []string{txid}, IssuedAt: now.Add(24 * time.Hour).Unix()})
+	if _, err := Parse(tokenPrefix + base64.RawURLEncoding.EncodeToString(future) + "." + signature); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("篡改后的令牌错误 = %v", err)
+	}
+
+	// 其它密钥签发的令牌
+	signer()
+	saved := tokenSigner
+	tokenSigner = storage.NewSigner("other")
+	other := issueAt(now, []string{txid})
+	tokenSigner = saved
+	if _, err := Parse(other); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("其它密钥签发的令牌错误 = %v", err)
+	}
+
+	// 签发时间超前过多的令牌即使签名有效也拒绝，避免永不过期
+	if _, err := parseAt(now, issueAt(now.Add(maxClockSkew+time.Minute), []string{txid})); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("签发时间在未来的令牌错误 = %v", err)
+	}
+	if _, err := parseAt(now, issueAt(now.Add(maxClockSkew/2), []string{txid})); err != nil {
+		t.Errorf("时钟误差范围内的令牌应有效: %v", err)
+	}
+
+	// 过期令牌仍可解析，由Expired判断
+	token = issueAt(now.Add(-2*tokenTTL), []string{txid})
+	parsed, err := parseAt(now, token)
+	if err != nil || !parsed.Expired(now) {
+		t.Errorf("过期令牌 = %+v, %v", parsed, err)
+	}
+}
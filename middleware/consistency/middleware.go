@@ -0,0 +1,121 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/db"
+	"ginproject/repo/rpc/blockchain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// X-Consistency响应头的取值
+const (
+	StatusVisible = "visible" // 令牌中的交易均已可见
+	StatusExpired = "expired" // 令牌已过期，未等待
+	StatusUnknown = "unknown" // 节点查询失败，未等待
+)
+
+const (
+	// 等待交易可见的最长时间，超过后返回503让客户端稍后重试
+	waitTimeout = 3 * time.Second
+	// 查询交易是否可见的间隔
+	pollInterval = 200 * time.Millisecond
+)
+
+// 已确认可见的交易，同一令牌的重复读取不再查询节点
+var visibleTxs = cache.NewLRU[string, struct{}](10000, tokenTTL)
+
+// After 读取接口的一致性中间件
+// 请求带有after令牌时，等待令牌中的交易进入节点内存池或区块后再执行处理函数，
+// 超时返回503和Retry-After；没有令牌、令牌过期或节点不可用时直接放行
+func After() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.Query(QueryParam)
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		token, err := Parse(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if token.Expired(time.Now()) {
+			c.Header(HeaderConsistency, StatusExpired)
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		visible, err := waitVisible(ctx, token.TxIDs)
+		switch {
+		case err != nil:
+			log.WarnWithContext(ctx, "检查一致性令牌交易失败，直接返回当前数据", "error", err)
+			c.Header(HeaderConsistency, StatusUnknown)
+		case !visible:
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "交易尚未可见，请稍后重试"})
+			return
+		default:
+			c.Header(HeaderConsistency, StatusVisible)
+		}
+		c.Next()
+	}
+}
+
+// waitVisible 轮询节点直到所有交易可见或超时，超时返回false，节点返回其它错误时返回该错误
+func waitVisible(ctx context.Context, txids []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	pending := txids
+	for {
+		var err error
+		pending, err = filterInvisible(ctx, pending)
+		if len(pending) == 0 {
+			return true, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// filterInvisible 返回节点中仍查询不到的交易
+func filterInvisible(ctx context.Context, txids []string) ([]string, error) {
+	pending := make([]string, 0, len(txids))
+	for i, txid := range txids {
+		if _, ok := visibleTxs.Get(txid); ok {
+			continue
+		}
+		result := <-blockchain.GetRawTransaction(ctx, txid, false)
+		if result.Error == nil {
+			visibleTxs.Set(txid, struct{}{})
+			continue
+		}
+		if !errors.Is(result.Error, db.ErrNotFound) {
+			return append(pending, txids[i:]...), result.Error
+		}
+		pending = append(pending, txid)
+	}
+	return pending, nil
+}
@@ -0,0 +1,178 @@
+package costclass
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ginproject/entity/apperror"
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutHeader 客户端指定请求超时(秒)的请求头，不超过所在开销等级的maxtimeout
+const TimeoutHeader = "X-Request-Timeout"
+
+// 并发名额不足返回503时建议客户端等待的秒数
+const busyRetryAfter = 1
+
+// 上下文中标记请求不受开销等级超时和并发上限约束的键
+const exemptContextKey = "cost_exempt"
+
+var (
+	// rejectedRequests 按开销等级统计因并发数达到上限被拒绝的请求数，通过/metrics发布
+	rejectedRequests = expvar.NewMap("cost_rejected_requests")
+	// inFlightRequests 按开销等级统计正在处理的请求数，通过/metrics发布
+	inFlightRequests = expvar.NewMap("cost_inflight_requests")
+)
+
+// semaphore 可在运行时调整上限的并发名额，上限在每次获取时传入，配置热更新后立即生效
+type semaphore struct {
+	mu       sync.Mutex
+	inFlight int
+	// released 有名额归还时关闭并替换，唤醒所有排队的请求重新尝试
+	released chan struct{}
+}
+
+func newSemaphore() *semaphore {
+	return &semaphore{released: make(chan struct{})}
+}
+
+// acquire 获取一个名额，名额已满时最多等待wait，超时或ctx结束时返回false
+func (s *semaphore) acquire(ctx context.Context, limit int, wait time.Duration) bool {
+	var timeout <-chan time.Time
+	for {
+		s.mu.Lock()
+		if s.inFlight < limit {
+			s.inFlight++
+			s.mu.Unlock()
+			return true
+		}
+		released := s.released
+		s.mu.Unlock()
+
+		if wait <= 0 {
+			return false
+		}
+		if timeout == nil {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-released:
+		case <-timeout:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// release 归还一个名额
+func (s *semaphore) release() {
+	s.mu.Lock()
+	s.inFlight--
+	close(s.released)
+	s.released = make(chan struct{})
+	s.mu.Unlock()
+}
+
+var (
+	semaphoresMu sync.Mutex
+	semaphores   = make(map[string]*semaphore)
+)
+
+// semaphoreFor 返回开销等级的并发名额，各等级分别计数
+func semaphoreFor(class string) *semaphore {
+	semaphoresMu.Lock()
+	defer semaphoresMu.Unlock()
+
+	s, ok := semaphores[class]
+	if !ok {
+		s = newSemaphore()
+		semaphores[class] = s
+	}
+	return s
+}
+
+// Exempt 标记当前请求为长连接或长轮询，不设超时也不占用并发名额，需在Limit之前调用
+func Exempt(c *gin.Context) {
+	c.Set(exemptContextKey, true)
+}
+
+// Limit 返回指定开销等级的请求处理中间件，等级名对应配置cost.classes中的键
+// 按配置为请求上下文设置超时，并限制同时处理的请求数；未配置的等级和标记为Exempt的请求直接放行
+func Limit(class string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := config.GetConfig().GetCostConfig().Classes[class]
+		if !ok || c.GetBool(exemptContextKey) {
+			c.Next()
+			return
+		}
+
+		timeout, err := requestTimeout(c.GetHeader(TimeoutHeader), rule)
+		if err != "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err})
+			return
+		}
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+			defer respondTimeout(c, class, timeout)
+		}
+
+		if rule.MaxConcurrent > 0 {
+			s := semaphoreFor(class)
+			if !s.acquire(c.Request.Context(), rule.MaxConcurrent, time.Duration(rule.QueueWait)*time.Millisecond) {
+				rejectedRequests.Add(class, 1)
+				log.WarnWithContext(c.Request.Context(), "并发请求数已达上限，拒绝请求", "cost", class, "limit", rule.MaxConcurrent)
+				c.Header("Retry-After", strconv.Itoa(busyRetryAfter))
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "服务繁忙，请稍后重试"})
+				return
+			}
+			inFlightRequests.Add(class, 1)
+			defer func() {
+				inFlightRequests.Add(class, -1)
+				s.release()
+			}()
+		}
+
+		c.Next()
+	}
+}
+
+// respondTimeout 请求上下文到期且处理函数没有写入响应时返回504
+// 处理函数记录的错误由errmap转换，这里只兜底未记录错误就返回的处理函数
+func respondTimeout(c *gin.Context, class string, timeout time.Duration) {
+	if c.Writer.Written() || !errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		return
+	}
+	log.WarnWithContext(c.Request.Context(), "请求处理超时", "cost", class, "timeout", timeout)
+	c.Header("Retry-After", strconv.Itoa(busyRetryAfter))
+	e := apperror.RequestTimeout("请求处理超时，请缩小查询范围或稍后重试", c.Request.Context().Err())
+	c.AbortWithStatusJSON(e.Status(), apperror.NewResponse(e))
+}
+
+// requestTimeout 返回请求的超时时间，客户端指定的超时不超过maxtimeout，未允许调整时忽略请求头
+// 请求头不是正数时返回错误说明
+func requestTimeout(header string, rule config.CostClassRule) (time.Duration, string) {
+	timeout := time.Duration(rule.Timeout) * time.Second
+	if header == "" || rule.MaxTimeout <= 0 {
+		return timeout, ""
+	}
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0, TimeoutHeader + "必须为正数(秒)"
+	}
+	requested := time.Duration(math.Min(seconds, float64(rule.MaxTimeout)) * float64(time.Second))
+	return requested, ""
+}
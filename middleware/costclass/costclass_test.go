@@ -0,0 +1,145 @@
+package costclass
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	rule := config.CostClassRule{Timeout: 5, MaxTimeout: 30}
+	cases := []struct {
+		header string
+		want   time.Duration
+		bad    bool
+	}{
+		{"", 5 * time.Second, false},
+		{"10", 10 * time.Second, false},
+		{"0.5", 500 * time.Millisecond, false},
+		{"120", 30 * time.Second, false},
+		{"0", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tc := range cases {
+		got, msg := requestTimeout(tc.header, rule)
+		if (msg != "") != tc.bad || got != tc.want {
+			t.Errorf("requestTimeout(%q) = %v, %q", tc.header, got, msg)
+		}
+	}
+
+	// 未配置maxtimeout时忽略客户端指定的超时
+	if got, msg := requestTimeout("abc", config.CostClassRule{Timeout: 5}); got != 5*time.Second || msg != "" {
+		t.Errorf("未允许调整时 = %v, %q", got, msg)
+	}
+}
+
+func TestSemaphoreQueue(t *testing.T) {
+	s := newSemaphore()
+	if !s.acquire(context.Background(), 1, 0) {
+		t.Fatal("首个请求应获得名额")
+	}
+	if s.acquire(context.Background(), 1, 0) {
+		t.Fatal("名额已满且不排队时应拒绝")
+	}
+	if s.acquire(context.Background(), 1, 10*time.Millisecond) {
+		t.Fatal("排队超时后应拒绝")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.release()
+	}()
+	if !s.acquire(context.Background(), 1, time.Second) {
+		t.Fatal("名额归还后排队的请求应获得名额")
+	}
+	// 上限调大后立即生效
+	if !s.acquire(context.Background(), 2, 0) {
+		t.Fatal("上限调大后应获得名额")
+	}
+}
+
+func TestLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.GetConfig()
+	saved := cfg.Cost
+	t.Cleanup(func() { cfg.Cost = saved })
+	cfg.Cost = config.CostConfig{Classes: map[string]config.CostClassRule{
+		"heavy": {Timeout: 2, MaxTimeout: 10, MaxConcurrent: 1},
+		"stall": {Timeout: 2, MaxTimeout: 10},
+	}}
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		if c.Query("block") != "" {
+			started <- struct{}{}
+			<-release
+		}
+		if !ok {
+			c.String(http.StatusOK, "none")
+			return
+		}
+		c.String(http.StatusOK, time.Until(deadline).Round(time.Second).String())
+	}
+	router.GET("/heavy", Limit("heavy"), handler)
+	router.GET("/light", Limit("light"), handler)
+	router.GET("/exempt", Exempt, Limit("heavy"), handler)
+	router.GET("/stall", Limit("stall"), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	request := func(path, timeout string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if timeout != "" {
+			req.Header.Set(TimeoutHeader, timeout)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("/heavy", ""); w.Body.String() != "2s" {
+		t.Errorf("默认超时 = %s", w.Body.String())
+	}
+	if w := request("/heavy", "60"); w.Body.String() != "10s" {
+		t.Errorf("客户端指定的超时应不超过maxtimeout: %s", w.Body.String())
+	}
+	if w := request("/heavy", "-1"); w.Code != http.StatusBadRequest {
+		t.Errorf("无效的超时状态码 = %d", w.Code)
+	}
+	if w := request("/light", ""); w.Body.String() != "none" {
+		t.Errorf("未配置的等级不应设超时: %s", w.Body.String())
+	}
+
+	// 处理函数未写入响应就因超时返回时兜底返回504
+	if w := request("/stall", "0.05"); w.Code != http.StatusGatewayTimeout || !strings.Contains(w.Body.String(), "REQUEST_TIMEOUT") {
+		t.Errorf("超时响应 = %d %s", w.Code, w.Body.String())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		request("/heavy?block=1", "")
+		close(done)
+	}()
+	<-started
+	if w := request("/heavy", ""); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("并发数达到上限时状态码 = %d", w.Code)
+	}
+	if w := request("/exempt", ""); w.Code != http.StatusOK || w.Body.String() != "none" {
+		t.Errorf("长连接接口不应受并发上限和超时约束: %d %s", w.Code, w.Body.String())
+	}
+	close(release)
+	<-done
+	if w := request("/heavy", ""); w.Code != http.StatusOK {
+		t.Errorf("名额归还后状态码 = %d", w.Code)
+	}
+}
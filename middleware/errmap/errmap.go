@@ -0,0 +1,95 @@
+package errmap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+
+	"ginproject/entity/apperror"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	rpcbchain "ginproject/repo/rpc/blockchain"
+	"ginproject/repo/rpc/breaker"
+	rpcex "ginproject/repo/rpc/electrumx"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 未分类的错误返回给客户端的说明，原始错误只记录日志
+	internalMessage = "服务器内部错误"
+	// 请求超过处理时限时返回给客户端的说明
+	requestTimeoutMessage = "请求处理超时，请缩小查询范围或稍后重试"
+)
+
+// Handler 将处理函数通过c.Error记录的错误转换为带错误码的响应，需紧挨着处理函数注册
+// 处理函数已经写入响应时不再处理；上游超时和不可用的错误附带Retry-After响应头，
+// 请求上下文到期引起的超时返回REQUEST_TIMEOUT
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		e := Classify(c.Errors.Last().Err)
+		if e.Code == apperror.CodeUpstreamTimeout && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+			// 请求上下文已到期，超时由请求的处理时限而不是单次上游调用引起
+			e = apperror.RequestTimeout(requestTimeoutMessage, e.Err)
+		}
+		log.ErrorWithContext(c.Request.Context(), "请求处理失败", "path:", c.FullPath(), "code:", e.Code, "错误:", c.Errors.Last().Err)
+		if e.Retryable() {
+			c.Header("Retry-After", "1")
+		}
+		c.JSON(e.Status(), apperror.NewResponse(e))
+	}
+}
+
+// Classify 将错误归类为应用错误，已带有错误码的按其错误码，服务端错误按原因细分为记录不存在、上游超时或不可用
+func Classify(err error) *apperror.Error {
+	e, typed := apperror.As(err)
+	if typed && e.Code != apperror.CodeInternal {
+		return e
+	}
+	message := internalMessage
+	if typed {
+		message = e.Message
+	}
+
+	switch {
+	case db.IsNotFound(err):
+		return apperror.NotFound(err.Error())
+	case errors.Is(err, rpcex.ErrHistoryTooLarge):
+		return apperror.InvalidParam("历史记录过多，请使用from_height缩小查询范围")
+	case isTimeout(err):
+		return apperror.UpstreamTimeout("上游服务响应超时", err)
+	case isUnavailable(err):
+		return apperror.NodeUnavailable("上游服务暂不可用", err)
+	case typed:
+		return e
+	default:
+		return apperror.Internal(message, err)
+	}
+}
+
+// isTimeout 判断是否为等待节点或ElectrumX超时
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, rpcbchain.ErrConnTimeout) || errors.Is(err, rpcex.ErrConnTimeout) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isUnavailable 判断是否为节点或ElectrumX连接不可用
+func isUnavailable(err error) bool {
+	for _, target := range []error{rpcbchain.ErrNoFreeConn, rpcbchain.ErrPoolClosed, rpcex.ErrNoFreeConn, rpcex.ErrPoolClosed, rpcex.ErrNoPool,
+		breaker.ErrOpen, breaker.ErrTooManyInFlight, syscall.ECONNREFUSED} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,88 @@
+package errmap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ginproject/entity/apperror"
+	"ginproject/repo/db"
+	"ginproject/repo/rpc/breaker"
+	rpcex "ginproject/repo/rpc/electrumx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err  error
+		want apperror.Code
+	}{
+		{apperror.InvalidParam("页码无效"), apperror.CodeInvalidParam},
+		{fmt.Errorf("查询代币: %w", db.ErrTokenNotFound), apperror.CodeNotFound},
+		{apperror.Internal("查询代币失败", db.ErrTokenNotFound), apperror.CodeNotFound},
+		{fmt.Errorf("获取历史: %w", context.DeadlineExceeded), apperror.CodeUpstreamTimeout},
+		{fmt.Errorf("从连接池获取连接失败: %w", rpcex.ErrNoFreeConn), apperror.CodeNodeUnavailable},
+		{fmt.Errorf("%w: ElectrumX blockchain.scripthash.get_history", breaker.ErrOpen), apperror.CodeNodeUnavailable},
+		{fmt.Errorf("获取交易历史失败: %w", rpcex.ErrHistoryTooLarge), apperror.CodeInvalidParam},
+		{errors.New("未知错误"), apperror.CodeInternal},
+	}
+	for _, tc := range cases {
+		if got := Classify(tc.err); got.Code != tc.want {
+			t.Errorf("Classify(%v) = %s, 期望 %s", tc.err, got.Code, tc.want)
+		}
+	}
+
+	// 服务端错误保留处理函数给出的说明，未分类的错误不向客户端暴露原始信息
+	if got := Classify(apperror.Internal("查询代币失败", errors.New("dial tcp: i/o"))); got.Message != "查询代币失败" {
+		t.Errorf("说明 = %q", got.Message)
+	}
+	if got := Classify(errors.New("Error 1045: Access denied")); got.Message != internalMessage {
+		t.Errorf("未分类错误的说明 = %q", got.Message)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/timeout", Handler(), func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("获取余额: %w", context.DeadlineExceeded))
+	})
+	router.GET("/written", Handler(), func(c *gin.Context) {
+		_ = c.Error(errors.New("已处理"))
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/timeout", nil))
+	var resp apperror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusGatewayTimeout || resp.ErrorCode != apperror.CodeUpstreamTimeout || !resp.Retryable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("超时响应 = %d %s, Retry-After %q", w.Code, w.Body.String(), w.Header().Get("Retry-After"))
+	}
+
+	// 请求上下文到期时按请求处理超时返回
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/timeout", nil).WithContext(ctx))
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusGatewayTimeout || resp.ErrorCode != apperror.CodeRequestTimeout || !resp.Retryable {
+		t.Fatalf("请求超时响应 = %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"ok":true}` {
+		t.Fatalf("已写入的响应 = %d %s, 期望保持不变", w.Code, w.Body.String())
+	}
+}
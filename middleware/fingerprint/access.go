@@ -0,0 +1,155 @@
+package fingerprint
+
+import (
+	"expvar"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 请求统计的窗口长度，窗口结束后重新计数
+	statsWindow = 10 * time.Minute
+	// 单个窗口最多统计的指纹数，超出后新指纹只计入丢弃数
+	maxTrackedFingerprints = 10000
+	// 单个指纹最多记录的客户端数
+	maxTrackedClients = 1000
+	// 通过/metrics发布的热点指纹数
+	metricsTopFingerprints = 20
+)
+
+// Stats 单个指纹在当前窗口内的请求统计
+type Stats struct {
+	Fingerprint
+	Count     uint64 `json:"count"`
+	Errors    uint64 `json:"errors"`  // 状态码不低于400的请求数
+	Clients   int    `json:"clients"` // 不同客户端IP数，最多统计1000个
+	FirstSeen int64  `json:"first_seen"`
+	LastSeen  int64  `json:"last_seen"`
+}
+
+// Summary 当前窗口的请求统计汇总
+type Summary struct {
+	WindowStart  int64   `json:"window_start"`
+	Fingerprints int     `json:"fingerprints"` // 窗口内出现的不同指纹数
+	Dropped      uint64  `json:"dropped"`      // 超过统计上限未计入的请求数
+	Top          []Stats `json:"top"`          // 请求数最多的指纹
+}
+
+type entry struct {
+	stats   Stats
+	clients map[string]struct{}
+}
+
+// recorder 按窗口统计各指纹的请求数，用于发现抓取等异常访问模式
+type recorder struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	entries     map[string]*entry
+	dropped     uint64
+}
+
+var defaultRecorder = newRecorder()
+
+func init() {
+	// 通过/metrics发布请求数最多的指纹
+	expvar.Publish("request_fingerprints", expvar.Func(func() any { return Top(metricsTopFingerprints) }))
+}
+
+func newRecorder() *recorder {
+	return &recorder{windowStart: time.Now(), entries: make(map[string]*entry)}
+}
+
+// record 记录一次请求
+func (r *recorder) record(fp Fingerprint, client string, status int, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.windowStart) >= statsWindow {
+		r.windowStart = now
+		r.entries = make(map[string]*entry)
+		r.dropped = 0
+	}
+
+	e, ok := r.entries[fp.Hash]
+	if !ok {
+		if len(r.entries) >= maxTrackedFingerprints {
+			r.dropped++
+			return
+		}
+		e = &entry{
+			stats:   Stats{Fingerprint: fp, FirstSeen: now.Unix()},
+			clients: make(map[string]struct{}),
+		}
+		r.entries[fp.Hash] = e
+	}
+
+	e.stats.Count++
+	if status >= http.StatusBadRequest {
+		e.stats.Errors++
+	}
+	e.stats.LastSeen = now.Unix()
+	if len(e.clients) < maxTrackedClients {
+		e.clients[client] = struct{}{}
+	}
+}
+
+// top 按请求数降序返回前n个指纹的统计
+func (r *recorder) top(n int) Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make([]Stats, 0, len(r.entries))
+	for _, e := range r.entries {
+		stats := e.stats
+		stats.Clients = len(e.clients)
+		all = append(all, stats)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Count != all[j].Count {
+			return all[i].Count > all[j].Count
+		}
+		return all[i].Hash < all[j].Hash
+	})
+	if len(all) > n {
+		all = all[:n]
+	}
+	return Summary{
+		WindowStart:  r.windowStart.Unix(),
+		Fingerprints: len(r.entries),
+		Dropped:      r.dropped,
+		Top:          all,
+	}
+}
+
+// Top 返回当前窗口内请求数最多的n个指纹
+func Top(n int) Summary {
+	return defaultRecorder.top(n)
+}
+
+// AccessLog 返回访问日志中间件，记录每个请求的状态码、耗时和指纹，并计入指纹统计
+// 需要在路由之前注册，指纹由路由注册表挂载的指纹中间件计算，未匹配路由的请求按实际路径计算
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		fp := FromContext(c)
+		status := c.Writer.Status()
+		defaultRecorder.record(fp, c.ClientIP(), status, time.Now())
+		log.InfoWithContext(c.Request.Context(), "访问日志",
+			"method:", c.Request.Method,
+			"path:", c.Request.URL.Path,
+			"status:", status,
+			"latency_ms:", time.Since(start).Milliseconds(),
+			"ip:", c.ClientIP(),
+			"route:", fp.Route,
+			"fingerprint:", fp.Hash,
+		)
+	}
+}
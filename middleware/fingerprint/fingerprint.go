@@ -0,0 +1,121 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 上下文中保存请求指纹的键
+const contextKey = "request_fingerprint"
+
+// 摘要保留的十六进制字符数
+const hashLength = 16
+
+// Fingerprint 请求指纹，参数相同的请求得到相同的指纹，与参数顺序、十六进制大小写和未声明的参数无关
+type Fingerprint struct {
+	Route     string `json:"route"`     // 方法和路由模板，如 GET /address/:address/history
+	Canonical string `json:"canonical"` // 规范化后的请求
+	Hash      string `json:"hash"`      // Canonical的摘要，用作访问日志字段以及响应缓存和合并请求的键
+}
+
+// Compute 根据路由模板和参数计算请求指纹
+// query只保留declared中声明的参数，路由没有声明查询参数时保留全部参数；空值被忽略，多值按字典序排列
+func Compute(method, route string, params gin.Params, query url.Values, declared []string) Fingerprint {
+	values := url.Values{}
+	for _, param := range params {
+		if value := normalize(param.Value); value != "" {
+			values.Add(param.Key, value)
+		}
+	}
+
+	keep := func(string) bool { return true }
+	if len(declared) > 0 {
+		allowed := make(map[string]struct{}, len(declared))
+		for _, name := range declared {
+			allowed[name] = struct{}{}
+		}
+		keep = func(name string) bool {
+			_, ok := allowed[name]
+			return ok
+		}
+	}
+	for name, list := range query {
+		if !keep(name) {
+			continue
+		}
+		normalized := make([]string, 0, len(list))
+		for _, value := range list {
+			if value = normalize(value); value != "" {
+				normalized = append(normalized, value)
+			}
+		}
+		sort.Strings(normalized)
+		for _, value := range normalized {
+			values.Add(name, value)
+		}
+	}
+
+	fp := Fingerprint{Route: strings.ToUpper(method) + " " + route}
+	fp.Canonical = fp.Route
+	// Encode按参数名排序
+	if encoded := values.Encode(); encoded != "" {
+		fp.Canonical += "?" + encoded
+	}
+	sum := sha256.Sum256([]byte(fp.Canonical))
+	fp.Hash = hex.EncodeToString(sum[:])[:hashLength]
+	return fp
+}
+
+// normalize 去除首尾空白，十六进制取值(交易哈希、脚本哈希等)统一为小写
+// 地址使用Base58编码，大小写敏感，不做转换
+func normalize(value string) string {
+	value = strings.TrimSpace(value)
+	if isHex(value) {
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+func isHex(value string) bool {
+	if value == "" || len(value)%2 != 0 {
+		return false
+	}
+	for _, c := range value {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// Middleware 返回计算请求指纹的中间件，route为路由模板，declared为路由声明的查询参数
+func Middleware(method, route string, declared []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, Compute(method, route, c.Params, c.Request.URL.Query(), declared))
+		c.Next()
+	}
+}
+
+// FromContext 获取当前请求的指纹，未经过指纹中间件的请求按实际路径和全部查询参数计算
+func FromContext(c *gin.Context) Fingerprint {
+	if value, ok := c.Get(contextKey); ok {
+		if fp, ok := value.(Fingerprint); ok {
+			return fp
+		}
+	}
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	return Compute(c.Request.Method, route, c.Params, c.Request.URL.Query(), nil)
+}
+
+// Key 返回当前请求的指纹摘要，响应缓存和合并相同请求时使用该值作为键
+func Key(c *gin.Context) string {
+	return FromContext(c).Hash
+}
@@ -0,0 +1,75 @@
+package fingerprint
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompute(t *testing.T) {
+	const route = "/address/:address/history"
+	params := gin.Params{{Key: "address", Value: "1ABCdef"}}
+	declared := []string{"page", "type"}
+	base := Compute(http.MethodGet, route, params, url.Values{"page": {"2"}, "type": {"tbc20", "p2pkh"}}, declared)
+
+	same := []url.Values{
+		{"type": {"p2pkh", "tbc20"}, "page": {" 2 "}},
+		{"page": {"2"}, "type": {"tbc20", "p2pkh", ""}, "_": {"1700000000"}},
+	}
+	for _, query := range same {
+		if fp := Compute("get", route, params, query, declared); fp != base {
+			t.Errorf("%v 的指纹应相同: %s != %s", query, fp.Canonical, base.Canonical)
+		}
+	}
+	if base.Canonical != "GET /address/:address/history?address=1ABCdef&page=2&type=p2pkh&type=tbc20" {
+		t.Errorf("规范化结果不一致: %s", base.Canonical)
+	}
+	if len(base.Hash) != hashLength {
+		t.Errorf("摘要长度不一致: %s", base.Hash)
+	}
+
+	// 地址大小写敏感，十六进制取值不区分大小写
+	if fp := Compute(http.MethodGet, route, gin.Params{{Key: "address", Value: "1abcdef"}}, nil, declared); fp.Hash == base.Hash {
+		t.Error("地址大小写不同的请求指纹应不同")
+	}
+	txRoute := "/tx/:txid"
+	lower := Compute(http.MethodGet, txRoute, gin.Params{{Key: "txid", Value: "abcd01"}}, nil, nil)
+	upper := Compute(http.MethodGet, txRoute, gin.Params{{Key: "txid", Value: "ABCD01"}}, nil, nil)
+	if lower != upper {
+		t.Errorf("交易哈希大小写不同的请求指纹应相同: %s != %s", lower.Canonical, upper.Canonical)
+	}
+
+	// 没有声明查询参数时保留全部参数
+	if a, b := Compute(http.MethodGet, txRoute, nil, url.Values{"x": {"1"}}, nil), Compute(http.MethodGet, txRoute, nil, nil, nil); a == b {
+		t.Error("未声明查询参数的路由应保留全部参数")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := newRecorder()
+	now := time.Now()
+	hot := Fingerprint{Route: "GET /a", Canonical: "GET /a", Hash: "a"}
+	cold := Fingerprint{Route: "GET /b", Canonical: "GET /b", Hash: "b"}
+	for i := 0; i < 3; i++ {
+		r.record(hot, "1.1.1.1", http.StatusOK, now)
+	}
+	r.record(hot, "2.2.2.2", http.StatusTooManyRequests, now)
+	r.record(cold, "1.1.1.1", http.StatusOK, now)
+
+	summary := r.top(1)
+	if summary.Fingerprints != 2 || len(summary.Top) != 1 {
+		t.Fatalf("统计汇总不一致: %+v", summary)
+	}
+	if got := summary.Top[0]; got.Hash != "a" || got.Count != 4 || got.Errors != 1 || got.Clients != 2 {
+		t.Fatalf("热点指纹统计不一致: %+v", got)
+	}
+
+	// 窗口结束后重新计数
+	r.record(cold, "1.1.1.1", http.StatusOK, now.Add(statsWindow))
+	if summary := r.top(10); summary.Fingerprints != 1 || summary.Top[0].Hash != "b" {
+		t.Fatalf("新窗口的统计不一致: %+v", summary)
+	}
+}
@@ -0,0 +1,202 @@
+package healthscore
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultLatencyTarget = 200 * time.Millisecond
+	defaultMaxErrorRate  = 0.5
+	defaultMaxLag        = 6
+	defaultShedHeavy     = 50
+	defaultShedNormal    = 25
+	defaultHedgeMin      = 50
+)
+
+const (
+	// 评分的缓存时长，限流和对冲在每个请求上读取评分
+	computeInterval = time.Second
+	// 上游请求数少于该值时不计算延迟和错误项
+	minSamples = 10
+	// 索引进度超过该时长未更新时视为完全滞后
+	lagStaleAfter = time.Minute
+	// 连接池占用低于该比例时占用项满分
+	saturationFree = 0.7
+	// 单项评分下限，避免单项为0时总分无法区分其它项的好坏
+	componentFloor = 0.01
+)
+
+// 各类评分项的权重，同类的多个上游平分该权重
+var weights = map[string]float64{
+	"latency":    0.3,
+	"errors":     0.3,
+	"saturation": 0.2,
+	"lag":        0.2,
+}
+
+// Component 一个评分项
+type Component struct {
+	Name   string  `json:"name"`   // 如node_latency、indexer_lag
+	Value  float64 `json:"value"`  // 原始值：延迟为毫秒，错误率和占用为比例，滞后为区块数
+	Score  float64 `json:"score"`  // 0到1，1为健康
+	Weight float64 `json:"weight"` // 在总分中的权重
+}
+
+// Score 综合健康评分
+type Score struct {
+	Score      int         `json:"score"` // 0到100，100为完全健康，可直接作为负载均衡权重
+	Components []Component `json:"components"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// lagReport 索引进度的最近一次上报
+type lagReport struct {
+	blocks int64
+	at     time.Time
+}
+
+var (
+	lagMu   sync.Mutex
+	lastLag *lagReport
+
+	scoreMu    sync.Mutex
+	lastScore  Score
+	computedAt time.Time
+)
+
+// ReportIndexerLag 上报索引落后链顶的区块数，由跟随链顶的后台任务在每轮处理后调用
+func ReportIndexerLag(blocks int64) {
+	if blocks < 0 {
+		blocks = 0
+	}
+	lagMu.Lock()
+	lastLag = &lagReport{blocks: blocks, at: time.Now()}
+	lagMu.Unlock()
+}
+
+// Current 返回综合健康评分，结果缓存一秒
+func Current() Score {
+	scoreMu.Lock()
+	defer scoreMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(computedAt) < computeInterval {
+		return lastScore
+	}
+	lastScore = compute(now)
+	computedAt = now
+	return lastScore
+}
+
+// compute 汇总各上游和索引进度的评分项，总分为各项的加权几何平均，任一项接近0时总分明显下降
+func compute(now time.Time) Score {
+	cfg := config.GetConfig().GetHealthConfig()
+
+	trackersMu.Lock()
+	names := make([]string, 0, len(trackers))
+	for name := range trackers {
+		names = append(names, name)
+	}
+	trackersMu.Unlock()
+	sort.Strings(names)
+
+	var components []Component
+	for _, name := range names {
+		components = append(components, upstreamComponents(name, Upstream(name).statsAt(now), cfg)...)
+	}
+
+	lagMu.Lock()
+	lag := lastLag
+	lagMu.Unlock()
+	if lag != nil {
+		score := linear(float64(lag.blocks), 1, float64(maxLag(cfg)))
+		if now.Sub(lag.at) > lagStaleAfter {
+			score = 0
+		}
+		components = append(components, Component{Name: "indexer_lag", Value: float64(lag.blocks), Score: score, Weight: weights["lag"]})
+	}
+
+	return Score{Score: combine(components), Components: components, UpdatedAt: now}
+}
+
+// upstreamComponents 计算单个上游的延迟、错误率和连接池占用评分项
+func upstreamComponents(name string, stats UpstreamStats, cfg *config.HealthConfig) []Component {
+	var components []Component
+	if stats.Samples >= minSamples {
+		target := latencyTarget(cfg)
+		components = append(components,
+			Component{
+				Name:   name + "_latency",
+				Value:  float64(stats.P95) / float64(time.Millisecond),
+				Score:  linear(float64(stats.P95), float64(target), float64(10*target)),
+				Weight: weights["latency"],
+			},
+			Component{
+				Name:   name + "_errors",
+				Value:  stats.ErrorRate,
+				Score:  linear(stats.ErrorRate, 0, maxErrorRate(cfg)),
+				Weight: weights["errors"],
+			})
+	}
+	if stats.Capacity > 0 {
+		usage := float64(stats.InUse) / float64(stats.Capacity)
+		components = append(components, Component{
+			Name:   name + "_saturation",
+			Value:  usage,
+			Score:  linear(usage, saturationFree, 1),
+			Weight: weights["saturation"],
+		})
+	}
+	return components
+}
+
+// combine 按权重计算几何平均并换算为0到100，没有任何评分项时视为健康
+func combine(components []Component) int {
+	var sum, total float64
+	for _, c := range components {
+		sum += c.Weight * math.Log(math.Max(c.Score, componentFloor))
+		total += c.Weight
+	}
+	if total == 0 {
+		return 100
+	}
+	return int(math.Round(100 * math.Exp(sum/total)))
+}
+
+// linear 值不超过good时为1，达到bad时为0，中间线性下降
+func linear(value, good, bad float64) float64 {
+	switch {
+	case value <= good:
+		return 1
+	case value >= bad:
+		return 0
+	}
+	return (bad - value) / (bad - good)
+}
+
+func latencyTarget(cfg *config.HealthConfig) time.Duration {
+	if cfg.LatencyTarget > 0 {
+		return time.Duration(cfg.LatencyTarget) * time.Millisecond
+	}
+	return defaultLatencyTarget
+}
+
+func maxErrorRate(cfg *config.HealthConfig) float64 {
+	if cfg.MaxErrorRate > 0 {
+		return cfg.MaxErrorRate
+	}
+	return defaultMaxErrorRate
+}
+
+func maxLag(cfg *config.HealthConfig) int64 {
+	if cfg.MaxLag > 0 {
+		return cfg.MaxLag
+	}
+	return defaultMaxLag
+}
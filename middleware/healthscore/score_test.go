@@ -0,0 +1,62 @@
+package healthscore
+
+import (
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+)
+
+func TestTrackerStats(t *testing.T) {
+	tr := &Tracker{obs: make([]observation, 0, trackerWindow)}
+	now := time.Now()
+
+	// 过期的请求不计入统计
+	tr.observeAt(now.Add(-2*trackerMaxAge), time.Hour, true)
+	for i := 1; i <= 20; i++ {
+		tr.observeAt(now, time.Duration(i)*time.Millisecond, i%4 == 0)
+	}
+	tr.SetPool(func() (int, int) { return 3, 10 })
+
+	stats := tr.statsAt(now)
+	if stats.Samples != 20 || stats.P95 != 20*time.Millisecond || stats.ErrorRate != 0.25 {
+		t.Fatalf("统计不正确: %+v", stats)
+	}
+	if stats.InUse != 3 || stats.Capacity != 10 {
+		t.Fatalf("连接池占用不正确: %+v", stats)
+	}
+
+	// 超过窗口后覆盖最早的请求
+	for i := 0; i < trackerWindow; i++ {
+		tr.observeAt(now, time.Millisecond, false)
+	}
+	if stats := tr.statsAt(now); stats.Samples != trackerWindow || stats.ErrorRate != 0 {
+		t.Fatalf("窗口覆盖不正确: %+v", stats)
+	}
+}
+
+func TestScore(t *testing.T) {
+	cfg := &config.HealthConfig{}
+
+	// 样本不足时只计算连接池占用
+	components := upstreamComponents("node", UpstreamStats{Samples: 5, InUse: 7, Capacity: 10}, cfg)
+	if len(components) != 1 || components[0].Score != 1 {
+		t.Fatalf("评分项不正确: %+v", components)
+	}
+	if combine(nil) != 100 || combine(components) != 100 {
+		t.Fatal("没有问题时应为满分")
+	}
+
+	slow := upstreamComponents("node", UpstreamStats{Samples: 100, P95: 2 * time.Second, ErrorRate: 0.25}, cfg)
+	if len(slow) != 2 || slow[0].Score != 0 || slow[1].Score != 0.5 {
+		t.Fatalf("评分项不正确: %+v", slow)
+	}
+	// 延迟项为0时总分接近0，不会被其它健康项拉高
+	if score := combine(slow); score > 10 {
+		t.Fatalf("上游超时时评分过高: %d", score)
+	}
+
+	if got := linear(150, 100, 200); got != 0.5 {
+		t.Fatalf("linear计算不正确: %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package healthscore
+
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 被拒绝的请求建议的重试等待时间(秒)，与评分的统计窗口相比足够短，恢复后能尽快回到正常流量
+const shedRetryAfter = 5
+
+// shedRequests 按开销等级统计因评分过低被拒绝的请求数，通过/metrics发布
+var shedRequests = expvar.NewMap("health_shed_requests")
+
+// ShedHeavy 返回heavy接口的限流中间件，开启限流且评分低于shedheavy时拒绝请求
+func ShedHeavy() gin.HandlerFunc {
+	return shed("heavy", func(cfg *config.HealthConfig) int {
+		if cfg.ShedHeavy > 0 {
+			return cfg.ShedHeavy
+		}
+		return defaultShedHeavy
+	})
+}
+
+// ShedNormal 返回normal接口的限流中间件，开启限流且评分低于shednormal时拒绝请求
+func ShedNormal() gin.HandlerFunc {
+	return shed("normal", func(cfg *config.HealthConfig) int {
+		if cfg.ShedNormal > 0 {
+			return cfg.ShedNormal
+		}
+		return defaultShedNormal
+	})
+}
+
+func shed(class string, threshold func(cfg *config.HealthConfig) int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.GetConfig().GetHealthConfig()
+		if !cfg.Shed {
+			c.Next()
+			return
+		}
+		score := Current().Score
+		if score >= threshold(cfg) {
+			c.Next()
+			return
+		}
+
+		shedRequests.Add(class, 1)
+		log.WarnWithContext(c.Request.Context(), "健康评分过低，拒绝请求", "cost", class, "score", score)
+		c.Header("Retry-After", strconv.Itoa(shedRetryAfter))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "服务繁忙，请稍后重试"})
+	}
+}
+
+// AllowHedge 判断当前是否允许发出对冲请求，上游已经变慢或出错时对冲只会加重其负载
+func AllowHedge() bool {
+	threshold := config.GetConfig().GetHealthConfig().HedgeMin
+	if threshold <= 0 {
+		threshold = defaultHedgeMin
+	}
+	return Current().Score >= threshold
+}
@@ -0,0 +1,121 @@
+package healthscore
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// 每个上游保留的最近请求数
+	trackerWindow = 512
+	// 只统计该时长内的请求，长时间没有请求时视为没有数据
+	trackerMaxAge = time.Minute
+)
+
+// 上游名称
+const (
+	UpstreamNode      = "node"
+	UpstreamElectrumX = "electrumx"
+)
+
+type observation struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// Tracker 记录单个上游最近请求的延迟和失败情况，以及连接池占用
+type Tracker struct {
+	mu    sync.Mutex
+	obs   []observation
+	next  int
+	usage func() (inUse, capacity int)
+}
+
+// UpstreamStats 上游在统计窗口内的状态
+type UpstreamStats struct {
+	Samples   int           `json:"samples"`
+	P95       time.Duration `json:"p95"`
+	ErrorRate float64       `json:"error_rate"`
+	InUse     int           `json:"in_use"`
+	Capacity  int           `json:"capacity"` // 连接池上限，0表示未使用连接池
+}
+
+var (
+	trackersMu sync.Mutex
+	trackers   = make(map[string]*Tracker)
+)
+
+// Upstream 返回指定上游的跟踪器，不存在时创建
+func Upstream(name string) *Tracker {
+	trackersMu.Lock()
+	defer trackersMu.Unlock()
+
+	t, ok := trackers[name]
+	if !ok {
+		t = &Tracker{obs: make([]observation, 0, trackerWindow)}
+		trackers[name] = t
+	}
+	return t
+}
+
+// Observe 记录一次请求的耗时和是否因上游故障失败，业务错误（如交易不存在）不应计为失败
+func (t *Tracker) Observe(latency time.Duration, failed bool) {
+	t.observeAt(time.Now(), latency, failed)
+}
+
+func (t *Tracker) observeAt(now time.Time, latency time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o := observation{at: now, latency: latency, failed: failed}
+	if len(t.obs) < trackerWindow {
+		t.obs = append(t.obs, o)
+		return
+	}
+	t.obs[t.next] = o
+	t.next = (t.next + 1) % trackerWindow
+}
+
+// SetPool 设置读取连接池占用的函数，连接池重建时重新设置
+func (t *Tracker) SetPool(usage func() (inUse, capacity int)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage = usage
+}
+
+// Stats 返回统计窗口内的延迟P95、失败率和连接池占用
+func (t *Tracker) Stats() UpstreamStats {
+	return t.statsAt(time.Now())
+}
+
+func (t *Tracker) statsAt(now time.Time) UpstreamStats {
+	t.mu.Lock()
+	latencies := make([]time.Duration, 0, len(t.obs))
+	failed := 0
+	for _, o := range t.obs {
+		if now.Sub(o.at) > trackerMaxAge {
+			continue
+		}
+		latencies = append(latencies, o.latency)
+		if o.failed {
+			failed++
+		}
+	}
+	usage := t.usage
+	t.mu.Unlock()
+
+	var stats UpstreamStats
+	if usage != nil {
+		stats.InUse, stats.Capacity = usage()
+	}
+	if len(latencies) == 0 {
+		return stats
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.Samples = len(latencies)
+	stats.P95 = latencies[len(latencies)*95/100]
+	stats.ErrorRate = float64(failed) / float64(len(latencies))
+	return stats
+}
@@ -0,0 +1,125 @@
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"expvar"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notModified 按路由统计返回304的请求数，通过/metrics发布
+var notModified = expvar.NewMap("http_not_modified_responses")
+
+// ETag 返回ETag中间件，按响应内容生成ETag，If-None-Match命中时返回304且不带响应体
+// 响应体在gzip压缩之前计算，同一内容的压缩和未压缩表示共用一个ETag，因此使用弱ETag
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+		if w.passthrough {
+			return
+		}
+		// 内层中间件已经设置ETag时(如按哈希寻址的强ETag)以其为准
+		if w.Status() != http.StatusOK || w.buf.Len() == 0 || w.Header().Get("ETag") != "" {
+			w.flushBuffer()
+			return
+		}
+		w.Header().Set("ETag", weakTag(w.buf.Bytes()))
+		if matchesETag(c.GetHeader("If-None-Match"), w.Header().Get("ETag")) {
+			writeNotModified(c, w.ResponseWriter)
+			return
+		}
+		w.flushBuffer()
+	}
+}
+
+// weakTag 以响应体的SHA-256摘要生成弱ETag
+func weakTag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// matchesETag 按弱比较判断If-None-Match是否包含etag
+func matchesETag(header, etag string) bool {
+	if header == "" || etag == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified 输出304响应，只保留缓存相关的响应头
+func writeNotModified(c *gin.Context, w gin.ResponseWriter) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	w.WriteHeaderNow()
+	notModified.Add(c.FullPath(), 1)
+}
+
+// bufferedWriter 缓存完整的响应体，处理函数返回后再决定输出原响应还是304
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buf         bytes.Buffer
+	passthrough bool // 处理函数主动刷新后不再缓存，按流式响应直接输出
+}
+
+// Write 缓存响应体
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.buf.Write(p)
+}
+
+// WriteString 缓存响应体
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 推迟到处理函数返回后再发出响应头，便于添加ETag或改为304
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush 流式响应无法计算ETag，输出已缓存的内容后转为直接输出
+func (w *bufferedWriter) Flush() {
+	if !w.passthrough {
+		w.flushBuffer()
+		w.passthrough = true
+	}
+	w.ResponseWriter.Flush()
+}
+
+// flushBuffer 输出已缓存的响应，没有响应体时只发出响应头
+func (w *bufferedWriter) flushBuffer() {
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
@@ -0,0 +1,162 @@
+package httpcache
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ginproject/entity/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 未配置时响应体达到该字节数才压缩，更小的响应压缩后收益不明显
+	defaultGzipMinSize = 1024
+)
+
+// Gzip 返回gzip压缩中间件，客户端支持gzip且响应体达到最小长度时压缩响应
+// 响应先缓存到最小长度再决定是否压缩，流式接口在首次Flush时即开始压缩
+func Gzip() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.GetConfig().GetServerConfig().Gzip
+		if !cfg.Enabled || c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) ||
+			strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			c.Next()
+			return
+		}
+
+		level := cfg.Level
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		minSize := cfg.MinSize
+		if minSize <= 0 {
+			minSize = defaultGzipMinSize
+		}
+
+		w := &gzipWriter{ResponseWriter: c.Writer, level: level, minSize: minSize}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+		}()
+		c.Header("Vary", "Accept-Encoding")
+
+		c.Next()
+		w.finish()
+	}
+}
+
+// acceptsGzip 判断Accept-Encoding是否接受gzip，q=0表示明确拒绝
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		params = strings.ReplaceAll(params, " ", "")
+		if q, ok := strings.CutPrefix(params, "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible 判断内容类型是否值得压缩，二进制和已压缩的内容直接输出
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/x-ndjson" ||
+		mediaType == "application/javascript"
+}
+
+// gzipWriter 缓存响应体开头的部分，达到最小长度后按内容类型决定是否压缩
+type gzipWriter struct {
+	gin.ResponseWriter
+	level   int
+	minSize int
+	buf     []byte
+	gz      *gzip.Writer
+	decided bool
+}
+
+// Write 未决定是否压缩时先缓存，达到最小长度后决定并输出缓存内容
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) < w.minSize {
+		return len(p), nil
+	}
+	if err := w.decide(true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString 与Write相同，避免gin绕过缓存直接写入底层连接
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式接口刷新时立即决定是否压缩，之后每次刷新都输出已压缩的数据
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 决定是否压缩并输出已缓存的内容，响应头已经发出或状态码不带响应体时不压缩
+func (w *gzipWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if compress && !w.ResponseWriter.Written() && header.Get("Content-Encoding") == "" &&
+		bodyAllowed(w.Status()) && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish 处理函数返回后输出剩余内容，未达到最小长度的响应原样输出
+func (w *gzipWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+// bodyAllowed 判断状态码是否允许携带响应体
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
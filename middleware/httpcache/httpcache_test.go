@@ -0,0 +1,149 @@
+package httpcache
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ginproject/entity/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	cases := map[string]bool{
+		"":                      false,
+		"gzip":                  true,
+		"deflate, gzip;q=0.8":   true,
+		"br, gzip; q=0":         false,
+		"*":                     true,
+		"identity":              false,
+		"gzip;q=0.000, deflate": false,
+	}
+	for header, want := range cases {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, 期望 %v", header, got, want)
+		}
+	}
+}
+
+func TestGzip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.GetConfig()
+	saved := cfg.Server.Gzip
+	t.Cleanup(func() { cfg.Server.Gzip = saved })
+	cfg.Server.Gzip = config.GzipConfig{Enabled: true, MinSize: 64}
+
+	large := strings.Repeat("a", 200)
+	router := gin.New()
+	router.Use(Gzip())
+	router.GET("/large", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": large}) })
+	router.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": "a"}) })
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/large")
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("大响应未压缩, 响应头 %v", w.Header())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("解压失败: %v", err)
+	}
+	body, _ := io.ReadAll(reader)
+	if !strings.Contains(string(body), large) {
+		t.Fatalf("解压后内容 = %q", body)
+	}
+
+	w = request("/small")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != `{"data":"a"}` {
+		t.Fatalf("小响应 = %q, 响应头 %v, 期望原样输出", w.Body.String(), w.Header())
+	}
+}
+
+func TestETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/info", ETag(), func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"height": 1}) })
+	router.GET("/missing", ETag(), func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "not found"}) })
+
+	request := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := request("/info", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) || first.Body.String() != `{"height":1}` {
+		t.Fatalf("首次请求 = %d %q, ETag %q", first.Code, first.Body.String(), etag)
+	}
+
+	second := request("/info", `"other", `+etag)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 || second.Header().Get("ETag") != etag {
+		t.Fatalf("条件请求 = %d %q, 期望304且不带响应体", second.Code, second.Body.String())
+	}
+
+	if w := request("/info", `W/"other"`); w.Code != http.StatusOK {
+		t.Fatalf("ETag不匹配时 = %d, 期望200", w.Code)
+	}
+	if w := request("/missing", "*"); w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" {
+		t.Fatalf("错误响应 = %d, ETag %q, 期望原样输出且不带ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestImmutable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	router := gin.New()
+	router.GET("/tx/:txid", ETag(), Immutable("txid"), func(c *gin.Context) {
+		calls++
+		if c.Param("txid") == "pending" {
+			Revalidate(c)
+		}
+		c.JSON(http.StatusOK, gin.H{"txid": c.Param("txid")})
+	})
+
+	request := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/tx/ABCD", "")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"abcd"` || w.Header().Get("Cache-Control") != immutableCacheControl {
+		t.Fatalf("首次请求 = %d, ETag %q, Cache-Control %q", w.Code, w.Header().Get("ETag"), w.Header().Get("Cache-Control"))
+	}
+
+	// 命中时不调用处理函数
+	w = request("/tx/abcd", `"abcd"`)
+	if w.Code != http.StatusNotModified || calls != 1 || w.Header().Get("ETag") != `"abcd"` {
+		t.Fatalf("条件请求 = %d, 处理函数调用%d次, 期望304且不调用处理函数", w.Code, calls)
+	}
+	if w = request("/tx/abcd", "*"); w.Code != http.StatusOK {
+		t.Fatalf("If-None-Match为*时 = %d, 期望200", w.Code)
+	}
+
+	// 标记为可变的响应使用按内容生成的弱ETag
+	w = request("/tx/pending", "")
+	if w.Header().Get("Cache-Control") != "" || !strings.HasPrefix(w.Header().Get("ETag"), `W/"`) {
+		t.Fatalf("可变响应 ETag %q, Cache-Control %q, 期望弱ETag且不带长缓存头", w.Header().Get("ETag"), w.Header().Get("Cache-Control"))
+	}
+}
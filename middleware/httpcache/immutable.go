@@ -0,0 +1,58 @@
+package httpcache
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 不可变资源的缓存头，客户端和CDN在一年内直接使用缓存，不再回源校验
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// 上下文中标记本次响应不是最终内容的键
+	revalidateContextKey = "httpcache_revalidate"
+)
+
+// Immutable 返回按哈希寻址的不可变资源中间件，param为路径中哈希参数的名称
+// 哈希本身即为强ETag，If-None-Match命中时直接返回304，不调用处理函数也不访问上游
+// 成功响应附带强ETag和长缓存头；处理函数调用Revalidate时本次响应不附带，如尚未确认的交易
+// 响应中的确认数按首次获取时的链顶计算，客户端应以X-Chain-Tip-Height响应头计算当前确认数
+func Immutable(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// If-None-Match为*时无法确认资源存在，交给处理函数按普通请求处理
+		etag := strongTag(c.Param(param))
+		if header := c.GetHeader("If-None-Match"); header != "*" && matchesETag(header, etag) {
+			c.Header("ETag", etag)
+			c.Header("Cache-Control", immutableCacheControl)
+			writeNotModified(c, c.Writer)
+			c.Abort()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+		if !w.passthrough && w.Status() == http.StatusOK && !c.GetBool(revalidateContextKey) {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", immutableCacheControl)
+		}
+		if !w.passthrough {
+			w.flushBuffer()
+		}
+	}
+}
+
+// Revalidate 标记本次响应内容还可能变化，Immutable不为其附带强ETag和长缓存头
+func Revalidate(c *gin.Context) {
+	c.Set(revalidateContextKey, true)
+}
+
+// strongTag 以小写哈希生成强ETag，同一哈希的大小写写法共用缓存
+func strongTag(hash string) string {
+	return `"` + strings.ToLower(hash) + `"`
+}
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"expvar"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 未配置时携带API密钥的请求头
+	defaultKeyHeader = "X-API-Key"
+	// 未配置时同时跟踪的客户端数量，超出后淘汰最久未请求的客户端
+	defaultMaxClients = 100000
+)
+
+// rejectedRequests 按路由分组统计被限流拒绝的请求数，通过/metrics发布
+var rejectedRequests = expvar.NewMap("ratelimit_rejected_requests")
+
+// bucket 单个客户端在单个分组上的令牌桶
+type bucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take 按当前配额补充令牌后取出一个，令牌不足时返回需要等待的时长
+// 配额在每次请求时传入，配置热更新后已有的令牌桶立即按新配额计算
+func (b *bucket) take(now time.Time, rate float64, burst int) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// limiter 按客户端和分组保存令牌桶，只在当前实例内计数，集群模式下Redis出错时也使用它
+type limiter struct {
+	mu      sync.Mutex
+	buckets *cache.LRU[string, *bucket]
+}
+
+var (
+	defaultLimiter     *limiter
+	defaultLimiterOnce sync.Once
+)
+
+// getLimiter 返回全局限流器，首次使用时按配置的客户端数量创建
+func getLimiter() *limiter {
+	defaultLimiterOnce.Do(func() {
+		maxClients := config.GetConfig().GetRateLimitConfig().MaxClients
+		if maxClients <= 0 {
+			maxClients = defaultMaxClients
+		}
+		defaultLimiter = newLimiter(maxClients)
+	})
+	return defaultLimiter
+}
+
+func newLimiter(maxClients int) *limiter {
+	return &limiter{buckets: cache.NewLRU[string, *bucket](maxClients, 0)}
+}
+
+// bucketFor 返回客户端在分组上的令牌桶，不存在时创建
+func (l *limiter) bucketFor(key string) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets.Get(key)
+	if !ok {
+		b = &bucket{}
+		l.buckets.Set(key, b)
+	}
+	return b
+}
+
+// Group 返回指定路由分组的限流中间件，分组名对应配置ratelimit.groups中的键
+// 未开启限流或分组未配置配额时放行
+func Group(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.GetConfig().GetRateLimitConfig()
+		rule, ok := cfg.Groups[name]
+		if !cfg.Enabled || !ok || rule.Rate <= 0 {
+			c.Next()
+			return
+		}
+
+		client, label, multiplier := clientKey(c, cfg)
+		burst := int(math.Max(1, float64(rule.Burst)*multiplier))
+		allowed, wait := take(c.Request.Context(), name+"|"+client, rule.Rate*multiplier, burst)
+		if allowed {
+			c.Next()
+			return
+		}
+
+		rejectedRequests.Add(name, 1)
+		log.WarnWithContext(c.Request.Context(), "请求过于频繁，拒绝请求", "group", name, "client", label)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "请求过于频繁，请稍后重试"})
+	}
+}
+
+// take 从客户端在分组上的令牌桶中取出一个令牌，集群模式下使用Redis中的令牌桶，各实例共享配额
+// Redis出错时退回本实例的令牌桶
+func take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration) {
+	if shared := cache.Shared(); shared != nil {
+		allowed, wait, err := takeShared(ctx, shared, key, rate, burst)
+		if err == nil {
+			return allowed, wait
+		}
+		log.WarnWithContext(ctx, "Redis限流失败，使用本实例的令牌桶", "错误:", err)
+	}
+	return getLimiter().bucketFor(key).take(time.Now(), rate, burst)
+}
+
+// clientKey 返回限流使用的客户端标识、日志中使用的名称和配额倍数
+// 已登记的API密钥按密钥计数，其余按客户端IP计数；未登记的密钥不作为标识，避免客户端更换密钥绕过限流
+func clientKey(c *gin.Context, cfg *config.RateLimitConfig) (string, string, float64) {
+	header := cfg.KeyHeader
+	if header == "" {
+		header = defaultKeyHeader
+	}
+	if key := c.GetHeader(header); key != "" {
+		for _, apiKey := range cfg.APIKeys {
+			if apiKey.Key != key {
+				continue
+			}
+			multiplier := apiKey.Multiplier
+			if multiplier <= 0 {
+				multiplier = 1
+			}
+			return "key:" + key, "key:" + apiKey.Name, multiplier
+		}
+	}
+	ip := "ip:" + c.ClientIP()
+	return ip, ip, 1
+}
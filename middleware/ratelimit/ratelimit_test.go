@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBucketTake(t *testing.T) {
+	var b bucket
+	now := time.Now()
+
+	// 初始为满桶，允许burst个突发请求
+	for i := 0; i < 3; i++ {
+		if ok, _ := b.take(now, 2, 3); !ok {
+			t.Fatalf("第%d个请求被拒绝", i+1)
+		}
+	}
+	ok, wait := b.take(now, 2, 3)
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("令牌耗尽后 = %v, %v, 期望拒绝并等待500ms", ok, wait)
+	}
+
+	// 按速率补充，且不超过桶容量
+	if ok, _ := b.take(now.Add(500*time.Millisecond), 2, 3); !ok {
+		t.Fatal("补充一个令牌后仍被拒绝")
+	}
+	b.take(now.Add(time.Hour), 2, 3)
+	if b.tokens != 2 {
+		t.Fatalf("长时间空闲后令牌数 = %v, 期望 2", b.tokens)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.GetConfig()
+	saved := cfg.RateLimit
+	t.Cleanup(func() { cfg.RateLimit = saved })
+	cfg.RateLimit = config.RateLimitConfig{
+		Enabled: true,
+		APIKeys: []config.RateLimitAPIKey{{Name: "partner", Key: "secret", Multiplier: 3}},
+		Groups:  map[string]config.RateLimitRule{"heavy": {Rate: 0.001, Burst: 1}},
+	}
+
+	router := gin.New()
+	// 与cmd中按默认配置一样不信任任何代理
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	router.GET("/heavy", Group("heavy"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/light", Group("light"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	forwarded := 0
+	request := func(path, key string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if key != "" {
+			req.Header.Set(defaultKeyHeader, key)
+		}
+		// 伪造的转发头不应改变客户端IP
+		forwarded++
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("192.0.2.%d", forwarded))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("/heavy", ""); code != http.StatusOK {
+		t.Fatalf("首个请求状态码 = %d", code)
+	}
+	// 未登记的密钥按IP计数，不能绕过限流
+	if code := request("/heavy", "unknown"); code != http.StatusTooManyRequests {
+		t.Fatalf("超出配额状态码 = %d, 期望 429", code)
+	}
+	// 已登记的密钥单独计数并按倍数放大配额
+	for i := 0; i < 3; i++ {
+		if code := request("/heavy", "secret"); code != http.StatusOK {
+			t.Fatalf("API密钥第%d个请求状态码 = %d", i+1, code)
+		}
+	}
+	// 未配置配额的分组不限流
+	for i := 0; i < 5; i++ {
+		if code := request("/light", ""); code != http.StatusOK {
+			t.Fatalf("light请求状态码 = %d", code)
+		}
+	}
+}
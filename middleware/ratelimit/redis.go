@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ginproject/repo/cache"
+)
+
+// 令牌桶保存在哈希中，时间取Redis服务器时间(毫秒)，避免各实例时钟不一致
+// 返回是否放行和令牌不足时需要等待的毫秒数，桶在补满所需的时间之后过期
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = burst
+elseif now > last then
+	tokens = math.min(burst, tokens + (now - last) / 1000 * rate)
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+// takeShared 从保存在Redis中的令牌桶取出一个令牌，集群模式下多个实例共享同一配额
+func takeShared(ctx context.Context, r *cache.Redis, key string, rate float64, burst int) (bool, time.Duration, error) {
+	result, err := takeScript.Run(ctx, r.Client(), []string{r.Key("ratelimit:" + key)}, rate, burst).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
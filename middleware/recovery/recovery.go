@@ -0,0 +1,23 @@
+package recovery
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery 捕获处理函数的panic并返回500，panic连同堆栈记录到日志
+// 处理函数以http.ErrAbortHandler中断时重新抛出，由net/http直接关闭连接且不记录堆栈，
+// 已经开始写出的流式响应不会以正常结束的形式到达客户端
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		log.ErrorWithContext(c.Request.Context(), "处理请求时发生panic", "path:", c.FullPath(), "错误:", err, "堆栈:", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
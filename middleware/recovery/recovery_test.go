@@ -0,0 +1,36 @@
+package recovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Recovery())
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	router.GET("/abort", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Writer.WriteString(`{"list":[`)
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("panic状态码 = %d", w.Code)
+	}
+
+	// 中断请求的panic应传到net/http
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("recover = %v, 期望 http.ErrAbortHandler", err)
+		}
+	}()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	t.Error("中断请求时应重新抛出panic")
+}
@@ -0,0 +1,256 @@
+package schemawatch
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 结构差异的种类
+const (
+	KindUnmappedField = "unmapped_field" // 响应中存在目标结构没有的字段，解码时会被丢弃
+	KindTypeMismatch  = "type_mismatch"  // 响应中字段的类型与目标结构不一致
+)
+
+// JSON值的类型名称
+const (
+	jsonObject = "object"
+	jsonArray  = "array"
+	jsonString = "string"
+	jsonNumber = "number"
+	jsonInt    = "integer"
+	jsonBool   = "bool"
+	jsonNull   = "null"
+)
+
+// Issue 响应与目标结构之间的一处差异
+type Issue struct {
+	Path     string `json:"path"` // 字段路径，$为根，数组元素记为[]，映射的值记为.*
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual"`
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rawMessage      = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// Compare 将通用JSON值（json.Unmarshal到interface{}的结果）与目标类型比较，返回按路径排序的差异
+// 同一路径和种类的差异只返回一次；实现了自定义解码的类型和interface{}字段不做检查
+func Compare(value interface{}, t reflect.Type) []Issue {
+	c := &comparer{seen: make(map[string]bool)}
+	c.compare(value, t, "$")
+	sort.Slice(c.issues, func(i, j int) bool {
+		if c.issues[i].Path != c.issues[j].Path {
+			return c.issues[i].Path < c.issues[j].Path
+		}
+		return c.issues[i].Kind < c.issues[j].Kind
+	})
+	return c.issues
+}
+
+type comparer struct {
+	issues []Issue
+	seen   map[string]bool
+}
+
+func (c *comparer) add(issue Issue) {
+	key := issue.Path + "|" + issue.Kind
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.issues = append(c.issues, issue)
+}
+
+func (c *comparer) compare(value interface{}, t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// null解码为零值，不视为差异
+	if value == nil || t == rawMessage || t.Kind() == reflect.Interface {
+		return
+	}
+	if t.Implements(jsonUnmarshaler) || reflect.PointerTo(t).Implements(jsonUnmarshaler) ||
+		t.Implements(textUnmarshaler) || reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return
+	}
+
+	expected := expectedKind(t)
+	actual := KindOf(value)
+	if !kindMatches(expected, value) {
+		c.add(Issue{Path: path, Kind: KindTypeMismatch, Expected: expected, Actual: actual})
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := structFields(t)
+		for key, v := range value.(map[string]interface{}) {
+			field, ok := fields.lookup(key)
+			if !ok {
+				c.add(Issue{Path: path + "." + key, Kind: KindUnmappedField, Actual: KindOf(v)})
+				continue
+			}
+			if field.asString {
+				continue
+			}
+			c.compare(v, field.typ, path+"."+key)
+		}
+	case reflect.Map:
+		for _, v := range value.(map[string]interface{}) {
+			c.compare(v, t.Elem(), path+".*")
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return
+		}
+		for _, v := range value.([]interface{}) {
+			c.compare(v, t.Elem(), path+"[]")
+		}
+	}
+}
+
+// expectedKind 返回Go类型对应的JSON值类型
+func expectedKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return jsonObject
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return jsonString
+		}
+		return jsonArray
+	case reflect.Array:
+		return jsonArray
+	case reflect.String:
+		return jsonString
+	case reflect.Bool:
+		return jsonBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonInt
+	case reflect.Float32, reflect.Float64:
+		return jsonNumber
+	}
+	return ""
+}
+
+// kindMatches 判断值是否可以解码为期望的JSON类型，浮点字段接受任意数字，整数字段不接受带小数的数字
+func kindMatches(expected string, value interface{}) bool {
+	switch expected {
+	case "":
+		return true
+	case jsonNumber:
+		kind := KindOf(value)
+		return kind == jsonNumber || kind == jsonInt
+	case jsonInt:
+		switch v := value.(type) {
+		case float64:
+			return v == math.Trunc(v)
+		case json.Number:
+			_, err := v.Int64()
+			return err == nil
+		}
+		return false
+	}
+	return KindOf(value) == expected
+}
+
+// KindOf 返回通用JSON值的类型名称
+func KindOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return jsonNull
+	case map[string]interface{}:
+		return jsonObject
+	case []interface{}:
+		return jsonArray
+	case string:
+		return jsonString
+	case bool:
+		return jsonBool
+	case float64:
+		if v == math.Trunc(v) {
+			return jsonInt
+		}
+		return jsonNumber
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return jsonInt
+		}
+		return jsonNumber
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// fieldInfo 结构体中可被JSON解码的字段
+type fieldInfo struct {
+	typ      reflect.Type
+	asString bool // 带有string选项，数字以字符串形式出现
+}
+
+// fieldSet 结构体的JSON字段，查找规则与encoding/json一致：优先精确匹配，其次忽略大小写
+type fieldSet map[string]fieldInfo
+
+func (f fieldSet) lookup(key string) (fieldInfo, bool) {
+	if field, ok := f[key]; ok {
+		return field, true
+	}
+	for name, field := range f {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+var fieldCache sync.Map // reflect.Type -> fieldSet
+
+// structFields 返回结构体的JSON字段，匿名嵌入的结构体字段提升到外层
+func structFields(t reflect.Type) fieldSet {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(fieldSet)
+	}
+	fields := make(fieldSet)
+	collectFields(t, fields)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, fields fieldSet) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if sf.Anonymous && name == "" {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fields)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if _, exists := fields[name]; exists {
+			continue
+		}
+		fields[name] = fieldInfo{typ: ft, asString: strings.Contains(opts, "string")}
+	}
+}
@@ -0,0 +1,193 @@
+package schemawatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+// 上游数据源
+const (
+	SourceNode      = "node"
+	SourceElectrumX = "electrumx"
+)
+
+const (
+	// 类型不一致时同一字段两次记录日志的最小间隔，期间只计数
+	sampleInterval = time.Minute
+	// 保留样本的最大字段数，超过后不再记录新字段的样本
+	maxSamples = 200
+)
+
+// ErrUnexpectedResponse 上游响应的结构与预期不一致
+var ErrUnexpectedResponse = errors.New("上游响应结构与预期不一致")
+
+// drift 按"数据源.方法.差异种类"统计结构差异次数，通过/metrics发布
+var drift = expvar.NewMap("upstream_schema_drift")
+
+func init() {
+	expvar.Publish("upstream_schema_samples", expvar.Func(func() interface{} { return Samples() }))
+}
+
+// Sample 某个字段最近一次出现的差异
+type Sample struct {
+	Source   string    `json:"source"`
+	Method   string    `json:"method"`
+	Issue    Issue     `json:"issue"`
+	Count    uint64    `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// recorder 保存差异样本并限制日志频率
+type recorder struct {
+	mu      sync.Mutex
+	samples map[string]*Sample
+	logged  map[string]time.Time
+}
+
+var defaultRecorder = &recorder{samples: make(map[string]*Sample), logged: make(map[string]time.Time)}
+
+// record 记录一处差异，返回是否需要输出日志
+// 未映射字段在进程内首次出现时记录一次，类型不一致按字段限频记录
+func (r *recorder) record(source, method string, issue Issue, now time.Time) bool {
+	key := source + "|" + method + "|" + issue.Path + "|" + issue.Kind
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sample, ok := r.samples[key]; ok {
+		sample.Issue = issue
+		sample.Count++
+		sample.LastSeen = now
+	} else if len(r.samples) < maxSamples {
+		r.samples[key] = &Sample{Source: source, Method: method, Issue: issue, Count: 1, LastSeen: now}
+	}
+
+	last, logged := r.logged[key]
+	if logged && (issue.Kind == KindUnmappedField || now.Sub(last) < sampleInterval) {
+		return false
+	}
+	r.logged[key] = now
+	return true
+}
+
+// Samples 返回已记录的差异样本，按数据源、方法和字段路径排序
+func Samples() []Sample {
+	defaultRecorder.mu.Lock()
+	samples := make([]Sample, 0, len(defaultRecorder.samples))
+	for _, sample := range defaultRecorder.samples {
+		samples = append(samples, *sample)
+	}
+	defaultRecorder.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		a, b := samples[i], samples[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Issue.Path < b.Issue.Path
+	})
+	return samples
+}
+
+// report 计数并按频率限制记录差异日志
+func report(ctx context.Context, source, method string, issues []Issue) {
+	now := time.Now()
+	for _, issue := range issues {
+		drift.Add(source+"."+method+"."+issue.Kind, 1)
+		if !defaultRecorder.record(source, method, issue, now) {
+			continue
+		}
+		log.WarnWithContext(ctx, "上游响应结构变化", "event", "schema_drift", "source", source, "method", method,
+			"path", issue.Path, "kind", issue.Kind, "expected", issue.Expected, "actual", issue.Actual)
+	}
+}
+
+// Check 比较通用JSON值与out的类型并上报差异，返回类型不一致的差异
+// 未映射字段只上报不视为错误
+func Check(ctx context.Context, source, method string, value interface{}, out interface{}) []Issue {
+	issues := Compare(value, reflect.TypeOf(out))
+	if len(issues) == 0 {
+		return nil
+	}
+	report(ctx, source, method, issues)
+
+	var mismatches []Issue
+	for _, issue := range issues {
+		if issue.Kind == KindTypeMismatch {
+			mismatches = append(mismatches, issue)
+		}
+	}
+	return mismatches
+}
+
+// Convert 将已解码为通用JSON值的响应检查后解码到out
+func Convert(ctx context.Context, source, method string, value interface{}, out interface{}) error {
+	if mismatches := Check(ctx, source, method, value, out); len(mismatches) > 0 {
+		return mismatchError(method, mismatches)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化%s响应失败: %w", method, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析%s响应失败: %w", method, err)
+	}
+	return nil
+}
+
+// Decode 将原始JSON检查后解码到out
+func Decode(ctx context.Context, source, method string, data []byte, out interface{}) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("解析%s响应失败: %w", method, err)
+	}
+	if mismatches := Check(ctx, source, method, value, out); len(mismatches) > 0 {
+		return mismatchError(method, mismatches)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析%s响应失败: %w", method, err)
+	}
+	return nil
+}
+
+// Expect 断言响应的通用JSON值为类型T，不一致时上报差异并返回说明期望和实际类型的错误
+func Expect[T any](ctx context.Context, source, method string, value interface{}) (T, error) {
+	return expectAt[T](ctx, source, method, "$", value)
+}
+
+// ExpectField 断言响应对象中field字段的值为类型T
+func ExpectField[T any](ctx context.Context, source, method, field string, value interface{}) (T, error) {
+	return expectAt[T](ctx, source, method, "$."+field, value)
+}
+
+func expectAt[T any](ctx context.Context, source, method, path string, value interface{}) (T, error) {
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	var zero T
+	issue := Issue{Path: path, Kind: KindTypeMismatch, Expected: expectedKind(reflect.TypeOf(&zero).Elem()), Actual: KindOf(value)}
+	report(ctx, source, method, []Issue{issue})
+	return zero, mismatchError(method, []Issue{issue})
+}
+
+// mismatchError 以第一处类型不一致构造错误
+func mismatchError(method string, mismatches []Issue) error {
+	first := mismatches[0]
+	if len(mismatches) == 1 {
+		return fmt.Errorf("%w: %s %s期望%s, 实际为%s", ErrUnexpectedResponse, method, first.Path, first.Expected, first.Actual)
+	}
+	return fmt.Errorf("%w: %s %s期望%s, 实际为%s, 另有%d处不一致", ErrUnexpectedResponse, method,
+		first.Path, first.Expected, first.Actual, len(mismatches)-1)
+}
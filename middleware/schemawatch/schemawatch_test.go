@@ -0,0 +1,105 @@
+package schemawatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testOutput struct {
+	Value        float64 `json:"value"`
+	N            int     `json:"n"`
+	ScriptPubKey struct {
+		Hex       string   `json:"hex"`
+		Addresses []string `json:"addresses"`
+	} `json:"scriptPubKey"`
+}
+
+type testTx struct {
+	Txid string       `json:"txid"`
+	Vout []testOutput `json:"vout"`
+}
+
+func decodeGeneric(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestCompare(t *testing.T) {
+	value := decodeGeneric(t, `{
+		"txid": "aa",
+		"size": 100,
+		"vout": [
+			{"value": 1, "n": 0, "scriptPubKey": {"hex": "76", "addresses": ["a"]}},
+			{"value": 0.5, "n": 1.5, "scriptPubKey": {"hex": "6a", "addresses": "b", "tokenData": {}}}
+		]
+	}`)
+
+	got := Compare(value, reflect.TypeOf(&testTx{}))
+	want := []Issue{
+		{Path: "$.size", Kind: KindUnmappedField, Actual: "integer"},
+		{Path: "$.vout[].n", Kind: KindTypeMismatch, Expected: "integer", Actual: "number"},
+		{Path: "$.vout[].scriptPubKey.addresses", Kind: KindTypeMismatch, Expected: "array", Actual: "string"},
+		{Path: "$.vout[].scriptPubKey.tokenData", Kind: KindUnmappedField, Actual: "object"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("差异不正确:\n期望 %+v\n实际 %+v", want, got)
+	}
+
+	// 字段名匹配与encoding/json一致，忽略大小写；null视为零值
+	matching := decodeGeneric(t, `{"TXID": "aa", "vout": null}`)
+	if issues := Compare(matching, reflect.TypeOf(testTx{})); len(issues) != 0 {
+		t.Fatalf("不应有差异: %+v", issues)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ctx := context.Background()
+
+	var tx testTx
+	if err := Decode(ctx, SourceNode, "test_decode", []byte(`{"txid":"aa","extra":1}`), &tx); err != nil || tx.Txid != "aa" {
+		t.Fatalf("未映射字段不应导致失败: %+v, %v", tx, err)
+	}
+	if drift.Get(SourceNode+".test_decode."+KindUnmappedField) == nil {
+		t.Fatal("未映射字段应计入指标")
+	}
+
+	err := Decode(ctx, SourceNode, "test_decode", []byte(`{"txid":1}`), &tx)
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("类型不一致应返回ErrUnexpectedResponse: %v", err)
+	}
+
+	if _, err := Expect[map[string]interface{}](ctx, SourceNode, "test_expect", []interface{}{}); !errors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("断言失败应返回ErrUnexpectedResponse: %v", err)
+	}
+	if got, err := ExpectField[float64](ctx, SourceNode, "test_expect", "blocks", 10.0); err != nil || got != 10 {
+		t.Fatalf("断言成功应返回原值: %v, %v", got, err)
+	}
+}
+
+func TestRecorderThrottlesLogs(t *testing.T) {
+	r := &recorder{samples: make(map[string]*Sample), logged: make(map[string]time.Time)}
+	now := time.Now()
+	unmapped := Issue{Path: "$.extra", Kind: KindUnmappedField}
+	mismatch := Issue{Path: "$.n", Kind: KindTypeMismatch}
+
+	if !r.record("node", "m", unmapped, now) || r.record("node", "m", unmapped, now.Add(time.Hour)) {
+		t.Fatal("未映射字段只应在首次出现时记录日志")
+	}
+	if !r.record("node", "m", mismatch, now) || r.record("node", "m", mismatch, now.Add(time.Second)) {
+		t.Fatal("类型不一致在间隔内只应记录一次日志")
+	}
+	if !r.record("node", "m", mismatch, now.Add(sampleInterval)) {
+		t.Fatal("超过间隔后应再次记录日志")
+	}
+	if sample := r.samples["node|m|$.n|"+KindTypeMismatch]; sample == nil || sample.Count != 3 {
+		t.Fatalf("样本计数不正确: %+v", sample)
+	}
+}
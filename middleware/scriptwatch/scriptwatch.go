@@ -0,0 +1,61 @@
+package scriptwatch
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+const (
+	// 同一节点脚本类型两次记录日志的最小间隔，期间只计数
+	sampleInterval = time.Minute
+	// 日志中脚本十六进制的最大长度
+	maxLoggedHex = 1024
+	// 节点未给出脚本类型时使用的计数键
+	emptyScriptType = "none"
+)
+
+// unknownScripts 按节点报告的脚本类型统计无法识别的输出数，通过/metrics发布
+var unknownScripts = expvar.NewMap("unknown_scripts")
+
+// sampler 按脚本类型限制日志频率
+type sampler struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+var defaultSampler = &sampler{interval: sampleInterval, last: make(map[string]time.Time)}
+
+// allow 判断该脚本类型在now时刻是否需要记录日志
+func (s *sampler) allow(scriptType string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.last[scriptType]; ok && now.Sub(last) < s.interval {
+		return false
+	}
+	s.last[scriptType] = now
+	return true
+}
+
+// ReportUnknown 记录一个无法识别的输出脚本：计入unknown_scripts指标，并按脚本类型抽样记录带脚本十六进制的日志，
+// 用于及时发现链上出现的新脚本模板
+func ReportUnknown(ctx context.Context, txid string, n int, scriptType, scriptHex string) {
+	if scriptType == "" {
+		scriptType = emptyScriptType
+	}
+	unknownScripts.Add(scriptType, 1)
+
+	if !defaultSampler.allow(scriptType, time.Now()) {
+		return
+	}
+	if len(scriptHex) > maxLoggedHex {
+		scriptHex = scriptHex[:maxLoggedHex] + "..."
+	}
+	log.WarnWithContext(ctx, "无法识别的输出脚本", "event", "unknown_script", "txid", txid, "vout", n,
+		"script_type", scriptType, "script_hex", scriptHex)
+}
@@ -0,0 +1,24 @@
+package scriptwatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplerAllow(t *testing.T) {
+	s := &sampler{interval: time.Minute, last: make(map[string]time.Time)}
+	now := time.Unix(1700000000, 0)
+
+	if !s.allow("nonstandard", now) {
+		t.Fatal("首次出现的脚本类型应记录日志")
+	}
+	if s.allow("nonstandard", now.Add(30*time.Second)) {
+		t.Error("间隔内的同类型脚本不应重复记录")
+	}
+	if !s.allow("scripthash", now.Add(30*time.Second)) {
+		t.Error("不同脚本类型应分别抽样")
+	}
+	if !s.allow("nonstandard", now.Add(time.Minute)) {
+		t.Error("超过间隔后应再次记录")
+	}
+}
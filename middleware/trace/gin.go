@@ -1,12 +1,16 @@
 package trace
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
 // GinMiddleware 返回一个Gin中间件，用于处理请求的trace
+// 请求结束时按路由记录耗时和状态码，得到每个路由的请求数、错误数和耗时指标
 func GinMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		start := time.Now()
 		// 检查请求头中是否已有trace ID
 		var traceID, spanID string
 		if existingTraceID := c.GetHeader(TraceIDHeader); existingTraceID != "" {
@@ -30,9 +34,15 @@ func GinMiddleware() gin.HandlerFunc {
 		c.Set("TraceID", traceID)
 		c.Set("SpanID", spanID)
 
+		// 处理函数可能替换请求上下文，指标使用本中间件创建的span关联exemplar
+		ctx := c.Request.Context()
+
 		// 处理请求
 		c.Next()
 
+		// 在span结束前记录指标，使exemplar关联到本次请求的trace
+		recordRequest(ctx, c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+
 		// 结束span
 		EndSpan(c.Request.Context())
 	}
@@ -0,0 +1,198 @@
+package trace
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// 请求耗时指标名称，遵循OTel HTTP语义约定
+	requestDurationMetric = "http.server.request.duration"
+	// 没有匹配路由的请求使用的路由标签，避免按原始路径产生无限多的标签
+	unmatchedRoute = "unmatched"
+)
+
+// 请求耗时的分桶边界（秒）
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	// 全局MeterProvider，与TracerProvider使用相同的资源
+	meterProvider *sdkmetric.MeterProvider
+	// 供/metrics按需读取指标的reader
+	metricReader *sdkmetric.ManualReader
+	// 按路由记录的请求耗时
+	requestDuration metric.Float64Histogram
+)
+
+func init() {
+	expvar.Publish("route_metrics", expvar.Func(func() any {
+		metrics, _ := CollectRouteMetrics(context.Background())
+		return metrics
+	}))
+}
+
+// initMeter 创建MeterProvider并注册请求耗时指标
+// 采样的span中记录的指标会附带exemplar，可以从慢请求直接跳转到对应的trace
+func initMeter(res *resource.Resource) {
+	metricReader = sdkmetric.NewManualReader()
+	meterProvider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(metricReader),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	histogram, err := meterProvider.Meter(serviceName+".service").Float64Histogram(requestDurationMetric,
+		metric.WithDescription("HTTP请求处理耗时"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+	requestDuration = histogram
+}
+
+// recordRequest 记录一次请求的耗时，ctx需包含请求的span以关联exemplar
+func recordRequest(ctx context.Context, method, route string, status int, elapsed time.Duration) {
+	if requestDuration == nil {
+		return
+	}
+	if route == "" {
+		route = unmatchedRoute
+	}
+	requestDuration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("http.route", route),
+		attribute.Int("http.response.status_code", status),
+	))
+}
+
+// RouteMetrics 单个路由的请求数、错误数和耗时（RED指标）
+type RouteMetrics struct {
+	Method    string     `json:"method"`
+	Route     string     `json:"route"`
+	Requests  uint64     `json:"requests"`
+	Errors    uint64     `json:"errors"` // 状态码>=500的请求数
+	MeanMs    float64    `json:"mean_ms"`
+	P95Ms     float64    `json:"p95_ms"` // 按分桶估算的上界，超过最大分桶时为最大分桶边界
+	Exemplars []Exemplar `json:"exemplars,omitempty"`
+}
+
+// Exemplar 关联到具体trace的一次请求耗时
+type Exemplar struct {
+	TraceID    string  `json:"trace_id"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// CollectRouteMetrics 读取当前的请求耗时指标，按请求方法和路由汇总，未初始化时返回nil
+func CollectRouteMetrics(ctx context.Context) ([]RouteMetrics, error) {
+	if metricReader == nil {
+		return nil, nil
+	}
+	var rm metricdata.ResourceMetrics
+	if err := metricReader.Collect(ctx, &rm); err != nil {
+		return nil, err
+	}
+
+	type routeKey struct{ method, route string }
+	type routeAgg struct {
+		count, errors uint64
+		sum           float64
+		buckets       []uint64
+		exemplars     []Exemplar
+	}
+	aggs := make(map[routeKey]*routeAgg)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != requestDurationMetric {
+				continue
+			}
+			hist, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok {
+				continue
+			}
+			for _, dp := range hist.DataPoints {
+				method, _ := dp.Attributes.Value("http.request.method")
+				route, _ := dp.Attributes.Value("http.route")
+				key := routeKey{method.AsString(), route.AsString()}
+				agg, ok := aggs[key]
+				if !ok {
+					agg = &routeAgg{buckets: make([]uint64, len(dp.BucketCounts))}
+					aggs[key] = agg
+				}
+
+				agg.count += dp.Count
+				agg.sum += dp.Sum
+				if status, _ := dp.Attributes.Value("http.response.status_code"); status.AsInt64() >= 500 {
+					agg.errors += dp.Count
+				}
+				for i, n := range dp.BucketCounts {
+					if i < len(agg.buckets) {
+						agg.buckets[i] += n
+					}
+				}
+				for _, ex := range dp.Exemplars {
+					if len(ex.TraceID) != len(trace.TraceID{}) {
+						continue
+					}
+					agg.exemplars = append(agg.exemplars, Exemplar{
+						TraceID:    trace.TraceID(ex.TraceID).String(),
+						DurationMs: ex.Value * 1000,
+					})
+				}
+			}
+		}
+	}
+
+	result := make([]RouteMetrics, 0, len(aggs))
+	for key, agg := range aggs {
+		if agg.count == 0 {
+			continue
+		}
+		// 最慢的exemplar最有排查价值
+		sort.Slice(agg.exemplars, func(i, j int) bool { return agg.exemplars[i].DurationMs > agg.exemplars[j].DurationMs })
+		if len(agg.exemplars) > 3 {
+			agg.exemplars = agg.exemplars[:3]
+		}
+		result = append(result, RouteMetrics{
+			Method:    key.method,
+			Route:     key.route,
+			Requests:  agg.count,
+			Errors:    agg.errors,
+			MeanMs:    agg.sum / float64(agg.count) * 1000,
+			P95Ms:     bucketQuantile(agg.buckets, agg.count, 0.95) * 1000,
+			Exemplars: agg.exemplars,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Route != result[j].Route {
+			return result[i].Route < result[j].Route
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result, nil
+}
+
+// bucketQuantile 返回包含指定分位的分桶上界（秒）
+func bucketQuantile(buckets []uint64, count uint64, q float64) float64 {
+	target := uint64(float64(count)*q + 0.5)
+	var seen uint64
+	for i, n := range buckets {
+		seen += n
+		if seen >= target && i < len(durationBuckets) {
+			return durationBuckets[i]
+		}
+	}
+	return durationBuckets[len(durationBuckets)-1]
+}
@@ -0,0 +1,39 @@
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCollectRouteMetrics(t *testing.T) {
+	InitTracer("metrics-test")
+
+	ctx := NewContext(context.Background(), "GET /block/height/:height")
+	traceID, _ := ExtractIDs(ctx)
+	for i := 0; i < 19; i++ {
+		recordRequest(ctx, "GET", "/block/height/:height", 200, 20*time.Millisecond)
+	}
+	recordRequest(ctx, "GET", "/block/height/:height", 502, 3*time.Second)
+	recordRequest(context.Background(), "GET", "", 404, time.Millisecond)
+
+	metrics, err := CollectRouteMetrics(context.Background())
+	if err != nil {
+		t.Fatalf("读取指标失败: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("路由数量 = %d, 期望 2: %+v", len(metrics), metrics)
+	}
+
+	block := metrics[0]
+	if block.Route != "/block/height/:height" || block.Requests != 20 || block.Errors != 1 || block.P95Ms != 25 {
+		t.Fatalf("路由指标不正确: %+v", block)
+	}
+	if len(block.Exemplars) == 0 || block.Exemplars[0].TraceID != traceID || block.Exemplars[0].DurationMs != 3000 {
+		t.Fatalf("exemplar不正确: %+v", block.Exemplars)
+	}
+
+	if unmatched := metrics[1]; unmatched.Route != unmatchedRoute || unmatched.Requests != 1 || len(unmatched.Exemplars) != 0 {
+		t.Fatalf("未匹配路由指标不正确: %+v", unmatched)
+	}
+}
@@ -25,7 +25,7 @@ var (
 	serviceName string
 )
 
-// InitTracer 使用服务名初始化追踪器
+// InitTracer 使用服务名初始化追踪器和指标
 func InitTracer(name string) {
 	serviceName = name
 
@@ -46,6 +46,9 @@ func InitTracer(name string) {
 
 	// 更新tracer
 	tracer = otel.Tracer(serviceName + ".service")
+
+	// 使用相同的资源创建MeterProvider，记录每个路由的请求指标
+	initMeter(res)
 }
 
 // NewContext 创建一个包含新trace和span的context
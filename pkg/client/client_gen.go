@@ -0,0 +1,1823 @@
+// Code generated by clientgen from the route registry. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"ginproject/entity/alert"
+	"ginproject/entity/analytics"
+	"ginproject/entity/block"
+	"ginproject/entity/broadcast"
+	"ginproject/entity/capability"
+	"ginproject/entity/electrumx"
+	"ginproject/entity/ft"
+	"ginproject/entity/mempool"
+	"ginproject/entity/nft"
+	"ginproject/entity/scheduler"
+	"ginproject/entity/script"
+	"ginproject/entity/transaction"
+	"ginproject/entity/wallet"
+	"ginproject/middleware/healthscore"
+)
+
+// APIPrefix 接口路径前缀
+const APIPrefix = "/v1/tbc/main"
+
+// HealthCheck 健康检查
+// GET /health
+func (c *Client) HealthCheck(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/health", nil, nil, &out)
+	return out, err
+}
+
+// GetHealthScore 获取综合健康评分，可作为负载均衡权重
+// GET /health/score
+func (c *Client) GetHealthScore(ctx context.Context) (*healthscore.Score, error) {
+	out := new(healthscore.Score)
+	if err := c.do(ctx, http.MethodGet, "/health/score", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCacheStatsQuery GetCacheStats的查询参数
+type GetCacheStatsQuery struct {
+	Top string // top
+}
+
+func (q *GetCacheStatsQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Top != "" {
+		values.Set("top", q.Top)
+	}
+	return values
+}
+
+// GetCacheStats 获取各缓存的命中统计
+// GET /admin/caches
+func (c *Client) GetCacheStats(ctx context.Context, query *GetCacheStatsQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/caches", query.values(), nil, &out)
+	return out, err
+}
+
+// SetReadOnlyQuery SetReadOnly的查询参数
+type SetReadOnlyQuery struct {
+	Enabled string // enabled
+}
+
+func (q *SetReadOnlyQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Enabled != "" {
+		values.Set("enabled", q.Enabled)
+	}
+	return values
+}
+
+// SetReadOnly 运行时切换只读模式
+// POST /admin/readonly
+func (c *Client) SetReadOnly(ctx context.Context, query *SetReadOnlyQuery, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/admin/readonly", query.values(), body, &out)
+	return out, err
+}
+
+// GetRequestStatsQuery GetRequestStats的查询参数
+type GetRequestStatsQuery struct {
+	Top string // top
+}
+
+func (q *GetRequestStatsQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Top != "" {
+		values.Set("top", q.Top)
+	}
+	return values
+}
+
+// GetRequestStats 获取当前统计窗口内请求数最多的请求指纹
+// GET /admin/requests
+func (c *Client) GetRequestStats(ctx context.Context, query *GetRequestStatsQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/requests", query.values(), nil, &out)
+	return out, err
+}
+
+// GetUpstreamStatus 获取各上游服务器的健康状态和进行中的异步上游调用
+// GET /admin/upstreams
+func (c *Client) GetUpstreamStatus(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/upstreams", nil, nil, &out)
+	return out, err
+}
+
+// GetSlowLogQuery GetSlowLog的查询参数
+type GetSlowLogQuery struct {
+	Top string // top
+}
+
+func (q *GetSlowLogQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Top != "" {
+		values.Set("top", q.Top)
+	}
+	return values
+}
+
+// GetSlowLog 获取最慢的请求及其期间的上游调用
+// GET /admin/slowlog
+func (c *Client) GetSlowLog(ctx context.Context, query *GetSlowLogQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/slowlog", query.values(), nil, &out)
+	return out, err
+}
+
+// GetJobStatuses 获取周期任务的运行状态
+// GET /admin/jobs
+func (c *Client) GetJobStatuses(ctx context.Context) ([]scheduler.JobStatus, error) {
+	var out []scheduler.JobStatus
+	if err := c.do(ctx, http.MethodGet, "/admin/jobs", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAlertStatuses 获取告警规则在本实例上的评估状态
+// GET /admin/alerts
+func (c *Client) GetAlertStatuses(ctx context.Context) ([]alert.RuleStatus, error) {
+	var out []alert.RuleStatus
+	if err := c.do(ctx, http.MethodGet, "/admin/alerts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetEventStats 获取事件总线各主题和订阅的状态
+// GET /admin/events
+func (c *Client) GetEventStats(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/events", nil, nil, &out)
+	return out, err
+}
+
+// ReplayEventsQuery ReplayEvents的查询参数
+type ReplayEventsQuery struct {
+	Consumer string // consumer
+	Topic    string // topic
+	Offset   string // offset
+}
+
+func (q *ReplayEventsQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Consumer != "" {
+		values.Set("consumer", q.Consumer)
+	}
+	if q.Topic != "" {
+		values.Set("topic", q.Topic)
+	}
+	if q.Offset != "" {
+		values.Set("offset", q.Offset)
+	}
+	return values
+}
+
+// ReplayEvents 将消费者移动到指定偏移量重放事件
+// POST /admin/events/replay
+func (c *Client) ReplayEvents(ctx context.Context, query *ReplayEventsQuery, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/admin/events/replay", query.values(), body, &out)
+	return out, err
+}
+
+// StartConsistencyCheckQuery StartConsistencyCheck的查询参数
+type StartConsistencyCheckQuery struct {
+	Addresses   string // addresses
+	FTHoldings  string // ft_holdings
+	SettleDepth string // settle_depth
+}
+
+func (q *StartConsistencyCheckQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Addresses != "" {
+		values.Set("addresses", q.Addresses)
+	}
+	if q.FTHoldings != "" {
+		values.Set("ft_holdings", q.FTHoldings)
+	}
+	if q.SettleDepth != "" {
+		values.Set("settle_depth", q.SettleDepth)
+	}
+	return values
+}
+
+// StartConsistencyCheck 提交ElectrumX与数据库结果的一致性抽样检查任务
+// POST /admin/consistency/check
+func (c *Client) StartConsistencyCheck(ctx context.Context, query *StartConsistencyCheckQuery, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/admin/consistency/check", query.values(), body, &out)
+	return out, err
+}
+
+// GetConsistencyCheck 查询一致性检查任务的进度和报告
+// GET /admin/consistency/check/:job_id
+func (c *Client) GetConsistencyCheck(ctx context.Context, jobID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/consistency/check/"+url.PathEscape(jobID), nil, nil, &out)
+	return out, err
+}
+
+// GetExchangeRate 获取TBC汇率
+// GET /exchangerate
+func (c *Client) GetExchangeRate(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/exchangerate", nil, nil, &out)
+	return out, err
+}
+
+// GetCapabilities 获取当前部署启用的可选功能
+// GET /capabilities
+func (c *Client) GetCapabilities(ctx context.Context) (*capability.CapabilitiesResponse, error) {
+	out := new(capability.CapabilitiesResponse)
+	if err := c.do(ctx, http.MethodGet, "/capabilities", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFtBalanceByAddress 根据地址和合约ID获取FT余额
+// GET /ft/balance/address/:address/contract/:contract_id
+func (c *Client) GetFtBalanceByAddress(ctx context.Context, address string, contractID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/balance/address/"+url.PathEscape(address)+"/contract/"+url.PathEscape(contractID), nil, nil, &out)
+	return out, err
+}
+
+// GetFtUtxoByAddressQuery GetFtUtxoByAddress的查询参数
+type GetFtUtxoByAddressQuery struct {
+	MinConfirmations string // min_confirmations
+}
+
+func (q *GetFtUtxoByAddressQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.MinConfirmations != "" {
+		values.Set("min_confirmations", q.MinConfirmations)
+	}
+	return values
+}
+
+// GetFtUtxoByAddress 根据地址和合约ID获取FT UTXO
+// GET /ft/utxo/address/:address/contract/:contract_id
+func (c *Client) GetFtUtxoByAddress(ctx context.Context, address string, contractID string, query *GetFtUtxoByAddressQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/utxo/address/"+url.PathEscape(address)+"/contract/"+url.PathEscape(contractID), query.values(), nil, &out)
+	return out, err
+}
+
+// GetFtInfoByContractId 根据合约ID获取FT信息
+// GET /ft/info/contract/id/:contract_id
+func (c *Client) GetFtInfoByContractId(ctx context.Context, contractID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/info/contract/id/"+url.PathEscape(contractID), nil, nil, &out)
+	return out, err
+}
+
+// GetMultiFtBalanceByAddress 获取地址持有的多个代币余额
+// POST /ft/balance/address/:address/contract/ids
+func (c *Client) GetMultiFtBalanceByAddress(ctx context.Context, address string, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/ft/balance/address/"+url.PathEscape(address)+"/contract/ids", nil, body, &out)
+	return out, err
+}
+
+// GetPoolNFTInfoByContractId 根据合约ID获取NFT池信息
+// GET /ft/pool/nft/info/contract/id/:ft_contract_id
+func (c *Client) GetPoolNFTInfoByContractId(ctx context.Context, ftContractID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/pool/nft/info/contract/id/"+url.PathEscape(ftContractID), nil, nil, &out)
+	return out, err
+}
+
+// GetPoolReserves 获取池子当前储备
+// GET /ft/pool/:pool_id/reserves
+func (c *Client) GetPoolReserves(ctx context.Context, poolID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/pool/"+url.PathEscape(poolID)+"/reserves", nil, nil, &out)
+	return out, err
+}
+
+// GetLPUnspentByScriptHashQuery GetLPUnspentByScriptHash的查询参数
+type GetLPUnspentByScriptHashQuery struct {
+	MinBalance string // min_balance
+	Page       string // page
+	Size       string // size
+}
+
+func (q *GetLPUnspentByScriptHashQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.MinBalance != "" {
+		values.Set("min_balance", q.MinBalance)
+	}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// GetLPUnspentByScriptHash 根据脚本哈希获取LP未花费交易输出
+// GET /ft/lp/unspent/by/script/hash:script_hash
+func (c *Client) GetLPUnspentByScriptHash(ctx context.Context, scriptHash string, query *GetLPUnspentByScriptHashQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/lp/unspent/by/script/hash"+url.PathEscape(scriptHash), query.values(), nil, &out)
+	return out, err
+}
+
+// GetLPUnspentByScriptHashes 批量获取LP未花费交易输出
+// POST /ft/lp/unspent/by/script/hashes
+func (c *Client) GetLPUnspentByScriptHashes(ctx context.Context, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/ft/lp/unspent/by/script/hashes", nil, body, &out)
+	return out, err
+}
+
+// GetFtHistoryByAddressQuery GetFtHistoryByAddress的查询参数
+type GetFtHistoryByAddressQuery struct {
+	FromHeight string // from_height
+}
+
+func (q *GetFtHistoryByAddressQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.FromHeight != "" {
+		values.Set("from_height", q.FromHeight)
+	}
+	return values
+}
+
+// GetFtHistoryByAddress 获取地址的FT交易历史
+// GET /ft/history/address/:address/contract/:contract_id/page/:page/size/:size
+func (c *Client) GetFtHistoryByAddress(ctx context.Context, address string, contractID string, page string, size string, query *GetFtHistoryByAddressQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/history/address/"+url.PathEscape(address)+"/contract/"+url.PathEscape(contractID)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), query.values(), nil, &out)
+	return out, err
+}
+
+// GetFtTokenList 获取代币列表
+// GET /ft/tokens/page/:page/size/:size/orderby/:order_by
+func (c *Client) GetFtTokenList(ctx context.Context, page string, size string, orderBy string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/tokens/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size)+"/orderby/"+url.PathEscape(orderBy), nil, nil, &out)
+	return out, err
+}
+
+// SearchFtTokensQuery SearchFtTokens的查询参数
+type SearchFtTokensQuery struct {
+	Keyword string // keyword
+	Page    string // page
+	Size    string // size
+}
+
+func (q *SearchFtTokensQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Keyword != "" {
+		values.Set("keyword", q.Keyword)
+	}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// SearchFtTokens 按名称或符号搜索代币
+// GET /ft/tokens/search
+func (c *Client) SearchFtTokens(ctx context.Context, query *SearchFtTokensQuery) (*ft.FtTokenListData, error) {
+	out := new(ft.FtTokenListData)
+	if err := c.do(ctx, http.MethodGet, "/ft/tokens/search", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFtTokenListHeldByCombineScript 通过合并脚本获取持有的代币列表
+// GET /ft/tokens/held/by/combine/script/:combine_script
+func (c *Client) GetFtTokenListHeldByCombineScript(ctx context.Context, combineScript string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/tokens/held/by/combine/script/"+url.PathEscape(combineScript), nil, nil, &out)
+	return out, err
+}
+
+// DecodeFtTransactionHistory 解析FT交易历史
+// GET /ft/decode/tx/history/:txid
+func (c *Client) DecodeFtTransactionHistory(ctx context.Context, txid string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/decode/tx/history/"+url.PathEscape(txid), nil, nil, &out)
+	return out, err
+}
+
+// GetPoolsOfTokenByContractId 获取代币相关流动池列表
+// GET /ft/pools/of/token/contract/id/:ft_contract_id
+func (c *Client) GetPoolsOfTokenByContractId(ctx context.Context, ftContractID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/pools/of/token/contract/id/"+url.PathEscape(ftContractID), nil, nil, &out)
+	return out, err
+}
+
+// GetTokenHistoryByContractId 获取代币历史交易记录
+// GET /ft/token/history/contract/id/:ft_contract_id/page/:page/size/:size
+func (c *Client) GetTokenHistoryByContractId(ctx context.Context, ftContractID string, page string, size string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/token/history/contract/id/"+url.PathEscape(ftContractID)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, &out)
+	return out, err
+}
+
+// GetPoolHistoryByPoolId 获取池子历史记录
+// GET /ft/pool/history/pool/id/:pool_id/page/:page/size/:size
+func (c *Client) GetPoolHistoryByPoolId(ctx context.Context, poolID string, page string, size string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/pool/history/pool/id/"+url.PathEscape(poolID)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, &out)
+	return out, err
+}
+
+// GetPoolList 获取交易池列表
+// GET /ft/pool/list/page/:page/size/:size
+func (c *Client) GetPoolList(ctx context.Context, page string, size string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/pool/list/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, &out)
+	return out, err
+}
+
+// GetTokenListHeldByAddress 获取地址持有的代币列表
+// GET /ft/tokens/held/by/address/:address
+func (c *Client) GetTokenListHeldByAddress(ctx context.Context, address string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/tokens/held/by/address/"+url.PathEscape(address), nil, nil, &out)
+	return out, err
+}
+
+// GetFtPortfolioByAddress 获取地址FT资产估值
+// GET /ft/portfolio/address/:address
+func (c *Client) GetFtPortfolioByAddress(ctx context.Context, address string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/portfolio/address/"+url.PathEscape(address), nil, nil, &out)
+	return out, err
+}
+
+// GetHolderRankByContractId 获取代币持有者排名
+// GET /ft/holder/rank/contract/:contract_id/page/:page/size/:size
+func (c *Client) GetHolderRankByContractId(ctx context.Context, contractID string, page string, size string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/holder/rank/contract/"+url.PathEscape(contractID)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, &out)
+	return out, err
+}
+
+// GetTokenMetricsByContractIdQuery GetTokenMetricsByContractId的查询参数
+type GetTokenMetricsByContractIdQuery struct {
+	PeriodDays   string // period_days
+	DormancyDays string // dormancy_days
+}
+
+func (q *GetTokenMetricsByContractIdQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.PeriodDays != "" {
+		values.Set("period_days", q.PeriodDays)
+	}
+	if q.DormancyDays != "" {
+		values.Set("dormancy_days", q.DormancyDays)
+	}
+	return values
+}
+
+// GetTokenMetricsByContractId 获取代币流通速度和休眠比例
+// GET /ft/token/metrics/contract/:contract_id
+func (c *Client) GetTokenMetricsByContractId(ctx context.Context, contractID string, query *GetTokenMetricsByContractIdQuery) (*ft.FtTokenMetricsResponse, error) {
+	out := new(ft.FtTokenMetricsResponse)
+	if err := c.do(ctx, http.MethodGet, "/ft/token/metrics/contract/"+url.PathEscape(contractID), query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFtBurnsByContractIdQuery GetFtBurnsByContractId的查询参数
+type GetFtBurnsByContractIdQuery struct {
+	Page string // page
+	Size string // size
+}
+
+func (q *GetFtBurnsByContractIdQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// GetFtBurnsByContractId 获取代币销毁记录和累计销毁数量
+// GET /ft/burns/contract/:contract_id
+func (c *Client) GetFtBurnsByContractId(ctx context.Context, contractID string, query *GetFtBurnsByContractIdQuery) (*ft.FtBurnsResponse, error) {
+	out := new(ft.FtBurnsResponse)
+	if err := c.do(ctx, http.MethodGet, "/ft/burns/contract/"+url.PathEscape(contractID), query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFtCandlesByContractIdQuery GetFtCandlesByContractId的查询参数
+type GetFtCandlesByContractIdQuery struct {
+	Interval string // interval
+	From     string // from
+	To       string // to
+}
+
+func (q *GetFtCandlesByContractIdQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Interval != "" {
+		values.Set("interval", q.Interval)
+	}
+	if q.From != "" {
+		values.Set("from", q.From)
+	}
+	if q.To != "" {
+		values.Set("to", q.To)
+	}
+	return values
+}
+
+// GetFtCandlesByContractId 获取代币池兑换价格K线
+// GET /ft/candles/contract/:contract_id
+func (c *Client) GetFtCandlesByContractId(ctx context.Context, contractID string, query *GetFtCandlesByContractIdQuery) (*ft.FtCandlesResponse, error) {
+	out := new(ft.FtCandlesResponse)
+	if err := c.do(ctx, http.MethodGet, "/ft/candles/contract/"+url.PathEscape(contractID), query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFtIconByContractId 获取代币元数据中登记的图标，内容为校验后重新编码的PNG
+// GET /ft/icon/contract/:contract_id
+func (c *Client) GetFtIconByContractId(ctx context.Context, contractID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/icon/contract/"+url.PathEscape(contractID), nil, nil, &out)
+	return out, err
+}
+
+// GetFtUtxoByCombineScriptQuery GetFtUtxoByCombineScript的查询参数
+type GetFtUtxoByCombineScriptQuery struct {
+	MinConfirmations string // min_confirmations
+}
+
+func (q *GetFtUtxoByCombineScriptQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.MinConfirmations != "" {
+		values.Set("min_confirmations", q.MinConfirmations)
+	}
+	return values
+}
+
+// GetFtUtxoByCombineScript 根据合并脚本和合约ID获取FT UTXO
+// GET /ft/utxo/combine/script/:combine_script/contract/:contract_id
+func (c *Client) GetFtUtxoByCombineScript(ctx context.Context, combineScript string, contractID string, query *GetFtUtxoByCombineScriptQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/utxo/combine/script/"+url.PathEscape(combineScript)+"/contract/"+url.PathEscape(contractID), query.values(), nil, &out)
+	return out, err
+}
+
+// GetFtBalanceByCombineScript 根据合并脚本和合约哈希获取FT余额
+// GET /ft/balance/combine/script/:combine_script/contract/:contract_hash
+func (c *Client) GetFtBalanceByCombineScript(ctx context.Context, combineScript string, contractHash string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ft/balance/combine/script/"+url.PathEscape(combineScript)+"/contract/"+url.PathEscape(contractHash), nil, nil, &out)
+	return out, err
+}
+
+// ImportTokenRegistry 导入代币元数据
+// POST /ft/token/registry/import
+func (c *Client) ImportTokenRegistry(ctx context.Context, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/ft/token/registry/import", nil, body, &out)
+	return out, err
+}
+
+// CreateTokenVerificationChallenge 获取代币创建者核验挑战
+// POST /ft/token/:contract_id/verification/challenge
+func (c *Client) CreateTokenVerificationChallenge(ctx context.Context, contractID string, body any) (*ft.TokenVerificationChallengeResponse, error) {
+	out := new(ft.TokenVerificationChallengeResponse)
+	if err := c.do(ctx, http.MethodPost, "/ft/token/"+url.PathEscape(contractID)+"/verification/challenge", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VerifyTokenCreator 提交创建者签名核验代币
+// POST /ft/token/:contract_id/verification
+func (c *Client) VerifyTokenCreator(ctx context.Context, contractID string, body *ft.TokenVerificationRequest) (*ft.TokenVerificationResponse, error) {
+	out := new(ft.TokenVerificationResponse)
+	if err := c.do(ctx, http.MethodPost, "/ft/token/"+url.PathEscape(contractID)+"/verification", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCollectionsByAddress 获取地址的NFT集合
+// GET /nft/collection/address/:address/page/:page/size/:size
+func (c *Client) GetCollectionsByAddress(ctx context.Context, address string, page string, size string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/collection/address/"+url.PathEscape(address)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, &out)
+	return out, err
+}
+
+// GetNftsByAddressQuery GetNftsByAddress的查询参数
+type GetNftsByAddressQuery struct {
+	IfExtraCollectionInfoNeeded string // if_extra_collection_info_needed
+	MinConfirmations            string // min_confirmations
+}
+
+func (q *GetNftsByAddressQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.IfExtraCollectionInfoNeeded != "" {
+		values.Set("if_extra_collection_info_needed", q.IfExtraCollectionInfoNeeded)
+	}
+	if q.MinConfirmations != "" {
+		values.Set("min_confirmations", q.MinConfirmations)
+	}
+	return values
+}
+
+// GetNftsByAddress 获取地址的NFT资产
+// GET /nft/address/:address/page/:page/size/:size
+func (c *Client) GetNftsByAddress(ctx context.Context, address string, page string, size string, query *GetNftsByAddressQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/address/"+url.PathEscape(address)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), query.values(), nil, &out)
+	return out, err
+}
+
+// GetNftsByScriptHashQuery GetNftsByScriptHash的查询参数
+type GetNftsByScriptHashQuery struct {
+	MinConfirmations string // min_confirmations
+}
+
+func (q *GetNftsByScriptHashQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.MinConfirmations != "" {
+		values.Set("min_confirmations", q.MinConfirmations)
+	}
+	return values
+}
+
+// GetNftsByScriptHash 获取脚本哈希的NFT资产
+// GET /nft/script/hash/:script_hash/page/:page/size/:size
+func (c *Client) GetNftsByScriptHash(ctx context.Context, scriptHash string, page string, size string, query *GetNftsByScriptHashQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/script/hash/"+url.PathEscape(scriptHash)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), query.values(), nil, &out)
+	return out, err
+}
+
+// GetNftsByCollectionIdQuery GetNftsByCollectionId的查询参数
+type GetNftsByCollectionIdQuery struct {
+	Sort  string // sort
+	Order string // order
+}
+
+func (q *GetNftsByCollectionIdQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Sort != "" {
+		values.Set("sort", q.Sort)
+	}
+	if q.Order != "" {
+		values.Set("order", q.Order)
+	}
+	return values
+}
+
+// GetNftsByCollectionId 获取集合的NFT资产
+// GET /nft/collection/id/:collection_id/page/:page/size/:size
+func (c *Client) GetNftsByCollectionId(ctx context.Context, collectionID string, page string, size string, query *GetNftsByCollectionIdQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/collection/id/"+url.PathEscape(collectionID)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), query.values(), nil, &out)
+	return out, err
+}
+
+// SearchNftsQuery SearchNfts的查询参数
+type SearchNftsQuery struct {
+	Collection string // collection
+	Name       string // name
+	Creator    string // creator
+	Attr       string // attr
+	Page       string // page
+	Size       string // size
+}
+
+func (q *SearchNftsQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Collection != "" {
+		values.Set("collection", q.Collection)
+	}
+	if q.Name != "" {
+		values.Set("name", q.Name)
+	}
+	if q.Creator != "" {
+		values.Set("creator", q.Creator)
+	}
+	if q.Attr != "" {
+		values.Set("attr", q.Attr)
+	}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// SearchNfts 按集合名称、NFT名称、创建者和属性搜索NFT
+// GET /nft/search
+func (c *Client) SearchNfts(ctx context.Context, query *SearchNftsQuery) (*nft.NftListResponse, error) {
+	out := new(nft.NftListResponse)
+	if err := c.do(ctx, http.MethodGet, "/nft/search", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetNftHistoryQuery GetNftHistory的查询参数
+type GetNftHistoryQuery struct {
+	FromHeight string // from_height
+}
+
+func (q *GetNftHistoryQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.FromHeight != "" {
+		values.Set("from_height", q.FromHeight)
+	}
+	return values
+}
+
+// GetNftHistory 获取地址的NFT交易历史
+// GET /nft/history/address/:address/page/:page/size/:size
+func (c *Client) GetNftHistory(ctx context.Context, address string, page string, size string, query *GetNftHistoryQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/history/address/"+url.PathEscape(address)+"/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), query.values(), nil, &out)
+	return out, err
+}
+
+// GetAllCollections 获取所有NFT集合
+// GET /nft/collections/page/:page/size/:size
+func (c *Client) GetAllCollections(ctx context.Context, page string, size string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/collections/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, &out)
+	return out, err
+}
+
+// GetDetailCollectionInfo 获取集合详细信息
+// GET /nft/collection/info/:collection_id
+func (c *Client) GetDetailCollectionInfo(ctx context.Context, collectionID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/collection/info/"+url.PathEscape(collectionID), nil, nil, &out)
+	return out, err
+}
+
+// GetNftsByContractIds 根据合约ID获取NFT信息
+// POST /nft/infos/contract_ids
+func (c *Client) GetNftsByContractIds(ctx context.Context, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/nft/infos/contract_ids", nil, body, &out)
+	return out, err
+}
+
+// GetNftPortfolio 获取地址的NFT持仓汇总
+// GET /nft/portfolio/address/:address
+func (c *Client) GetNftPortfolio(ctx context.Context, address string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/nft/portfolio/address/"+url.PathEscape(address), nil, nil, &out)
+	return out, err
+}
+
+// GetAddressUnspentUtxosQuery GetAddressUnspentUtxos的查询参数
+type GetAddressUnspentUtxosQuery struct {
+	MinConfirmations string // min_confirmations
+	After            string // after
+}
+
+func (q *GetAddressUnspentUtxosQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.MinConfirmations != "" {
+		values.Set("min_confirmations", q.MinConfirmations)
+	}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressUnspentUtxos 获取地址未花费交易输出
+// GET /address/:address/unspent
+func (c *Client) GetAddressUnspentUtxos(ctx context.Context, address string, query *GetAddressUnspentUtxosQuery) (electrumx.UtxoResponse, error) {
+	var out electrumx.UtxoResponse
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/unspent", query.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUtxoAgeDistribution 获取地址UTXO年龄分布
+// GET /address/:address/utxo/age-distribution
+func (c *Client) GetUtxoAgeDistribution(ctx context.Context, address string) (*electrumx.UtxoAgeDistributionResponse, error) {
+	out := new(electrumx.UtxoAgeDistributionResponse)
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/utxo/age-distribution", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAddressHistoryQuery GetAddressHistory的查询参数
+type GetAddressHistoryQuery struct {
+	FromHeight string // from_height
+	Cursor     string // cursor
+	After      string // after
+}
+
+func (q *GetAddressHistoryQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.FromHeight != "" {
+		values.Set("from_height", q.FromHeight)
+	}
+	if q.Cursor != "" {
+		values.Set("cursor", q.Cursor)
+	}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressHistory 获取地址历史交易
+// GET /address/:address/history
+func (c *Client) GetAddressHistory(ctx context.Context, address string, query *GetAddressHistoryQuery) (*electrumx.AddressHistoryResponse, error) {
+	out := new(electrumx.AddressHistoryResponse)
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/history", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAddressHistoryPagedFromDBQuery GetAddressHistoryPagedFromDB的查询参数
+type GetAddressHistoryPagedFromDBQuery struct {
+	FromHeight string // from_height
+	After      string // after
+}
+
+func (q *GetAddressHistoryPagedFromDBQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.FromHeight != "" {
+		values.Set("from_height", q.FromHeight)
+	}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressHistoryPagedFromDB 分页获取地址历史交易
+// GET /address/:address/history/page/:page
+func (c *Client) GetAddressHistoryPagedFromDB(ctx context.Context, address string, page string, query *GetAddressHistoryPagedFromDBQuery) (*electrumx.AddressHistoryResponse, error) {
+	out := new(electrumx.AddressHistoryResponse)
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/history/page/"+url.PathEscape(page), query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAddressHistoryPagedQuery GetAddressHistoryPaged的查询参数
+type GetAddressHistoryPagedQuery struct {
+	FromHeight string // from_height
+	Cursor     string // cursor
+	After      string // after
+}
+
+func (q *GetAddressHistoryPagedQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.FromHeight != "" {
+		values.Set("from_height", q.FromHeight)
+	}
+	if q.Cursor != "" {
+		values.Set("cursor", q.Cursor)
+	}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressHistoryPaged 使用数据库分页获取地址历史交易
+// GET /address/:address/allhistory/page/:page
+func (c *Client) GetAddressHistoryPaged(ctx context.Context, address string, page string, query *GetAddressHistoryPagedQuery) (*electrumx.AddressHistoryResponse, error) {
+	out := new(electrumx.AddressHistoryResponse)
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/allhistory/page/"+url.PathEscape(page), query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAddressBalanceQuery GetAddressBalance的查询参数
+type GetAddressBalanceQuery struct {
+	After string // after
+}
+
+func (q *GetAddressBalanceQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressBalance 获取地址余额
+// GET /address/:address/get/balance
+func (c *Client) GetAddressBalance(ctx context.Context, address string, query *GetAddressBalanceQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/get/balance", query.values(), nil, &out)
+	return out, err
+}
+
+// GetAddressFrozenBalanceQuery GetAddressFrozenBalance的查询参数
+type GetAddressFrozenBalanceQuery struct {
+	After string // after
+}
+
+func (q *GetAddressFrozenBalanceQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressFrozenBalance 获取地址冻结余额
+// GET /address/:address/get/balance/frozen
+func (c *Client) GetAddressFrozenBalance(ctx context.Context, address string, query *GetAddressFrozenBalanceQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/get/balance/frozen", query.values(), nil, &out)
+	return out, err
+}
+
+// GetAddressSummaryQuery GetAddressSummary的查询参数
+type GetAddressSummaryQuery struct {
+	After string // after
+}
+
+func (q *GetAddressSummaryQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetAddressSummary 获取地址概要和活跃区间
+// GET /address/:address/summary
+func (c *Client) GetAddressSummary(ctx context.Context, address string, query *GetAddressSummaryQuery) (*electrumx.AddressSummaryResponse, error) {
+	out := new(electrumx.AddressSummaryResponse)
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/summary", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SyncAddressQuery SyncAddress的查询参数
+type SyncAddressQuery struct {
+	SinceHeight string // since_height
+}
+
+func (q *SyncAddressQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.SinceHeight != "" {
+		values.Set("since_height", q.SinceHeight)
+	}
+	return values
+}
+
+// SyncAddress 地址增量同步
+// GET /address/:address/sync
+func (c *Client) SyncAddress(ctx context.Context, address string, query *SyncAddressQuery) (*electrumx.AddressSyncResponse, error) {
+	out := new(electrumx.AddressSyncResponse)
+	if err := c.do(ctx, http.MethodGet, "/address/"+url.PathEscape(address)+"/sync", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StartHistoryExportQuery StartHistoryExport的查询参数
+type StartHistoryExportQuery struct {
+	Format string // format
+}
+
+func (q *StartHistoryExportQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Format != "" {
+		values.Set("format", q.Format)
+	}
+	return values
+}
+
+// StartHistoryExport 提交地址历史导出任务
+// POST /address/:address/history/export
+func (c *Client) StartHistoryExport(ctx context.Context, address string, query *StartHistoryExportQuery, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/address/"+url.PathEscape(address)+"/history/export", query.values(), body, &out)
+	return out, err
+}
+
+// GetHistoryExport 查询地址历史导出任务
+// GET /export/history/:job_id
+func (c *Client) GetHistoryExport(ctx context.Context, jobID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/export/history/"+url.PathEscape(jobID), nil, nil, &out)
+	return out, err
+}
+
+// DownloadHistoryExportQuery DownloadHistoryExport的查询参数
+type DownloadHistoryExportQuery struct {
+	Expires   string // expires
+	Signature string // signature
+}
+
+func (q *DownloadHistoryExportQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Expires != "" {
+		values.Set("expires", q.Expires)
+	}
+	if q.Signature != "" {
+		values.Set("signature", q.Signature)
+	}
+	return values
+}
+
+// DownloadHistoryExport 下载地址历史导出文件
+// GET /export/history/:job_id/download
+func (c *Client) DownloadHistoryExport(ctx context.Context, jobID string, query *DownloadHistoryExportQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/export/history/"+url.PathEscape(jobID)+"/download", query.values(), nil, &out)
+	return out, err
+}
+
+// StartScriptHashReindex 提交单个脚本哈希的重建索引任务
+// POST /admin/reindex/scripthash/:hash
+func (c *Client) StartScriptHashReindex(ctx context.Context, hash string, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/admin/reindex/scripthash/"+url.PathEscape(hash), nil, body, &out)
+	return out, err
+}
+
+// GetScriptHashReindex 查询重建索引任务的进度和结果
+// GET /admin/reindex/jobs/:job_id
+func (c *Client) GetScriptHashReindex(ctx context.Context, jobID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/reindex/jobs/"+url.PathEscape(jobID), nil, nil, &out)
+	return out, err
+}
+
+// GetShadowStats 获取影子流量比对统计
+// GET /admin/shadow/stats
+func (c *Client) GetShadowStats(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/admin/shadow/stats", nil, nil, &out)
+	return out, err
+}
+
+// GetScriptUnspentQuery GetScriptUnspent的查询参数
+type GetScriptUnspentQuery struct {
+	After string // after
+}
+
+func (q *GetScriptUnspentQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetScriptUnspent 获取脚本哈希未花费交易输出
+// GET /script/hash/:script_hash/unspent
+func (c *Client) GetScriptUnspent(ctx context.Context, scriptHash string, query *GetScriptUnspentQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/script/hash/"+url.PathEscape(scriptHash)+"/unspent", query.values(), nil, &out)
+	return out, err
+}
+
+// GetScriptHistoryQuery GetScriptHistory的查询参数
+type GetScriptHistoryQuery struct {
+	FromHeight string // from_height
+	Split      string // split
+	After      string // after
+}
+
+func (q *GetScriptHistoryQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.FromHeight != "" {
+		values.Set("from_height", q.FromHeight)
+	}
+	if q.Split != "" {
+		values.Set("split", q.Split)
+	}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetScriptHistory 获取脚本哈希历史交易
+// GET /script/hash/:script_hash/history
+func (c *Client) GetScriptHistory(ctx context.Context, scriptHash string, query *GetScriptHistoryQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/script/hash/"+url.PathEscape(scriptHash)+"/history", query.values(), nil, &out)
+	return out, err
+}
+
+// DecodeScript 解码十六进制或ASM脚本
+// POST /script/decode
+func (c *Client) DecodeScript(ctx context.Context, body *script.DecodeScriptRequest) (*script.DecodeScriptResponse, error) {
+	out := new(script.DecodeScriptResponse)
+	if err := c.do(ctx, http.MethodPost, "/script/decode", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMultiWalletByAddress 根据地址获取多签名地址及其公钥列表
+// GET /multisig/pubkeys/address/:address
+func (c *Client) GetMultiWalletByAddress(ctx context.Context, address string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/multisig/pubkeys/address/"+url.PathEscape(address), nil, nil, &out)
+	return out, err
+}
+
+// GetBlockByHeight 通过高度获取区块详情
+// GET /block/height/:height
+func (c *Client) GetBlockByHeight(ctx context.Context, height string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/height/"+url.PathEscape(height), nil, nil, &out)
+	return out, err
+}
+
+// GetBlockByHash 通过哈希获取区块详情
+// GET /block/hash/:hash
+func (c *Client) GetBlockByHash(ctx context.Context, hash string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/hash/"+url.PathEscape(hash), nil, nil, &out)
+	return out, err
+}
+
+// GetRawBlockByHash 通过哈希获取序列化区块的十六进制
+// GET /block/hash/:hash/raw
+func (c *Client) GetRawBlockByHash(ctx context.Context, hash string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/hash/"+url.PathEscape(hash)+"/raw", nil, nil, &out)
+	return out, err
+}
+
+// GetBlockTxsByHash 分页获取区块中的交易摘要
+// GET /block/hash/:hash/txs/page/:page/size/:size
+func (c *Client) GetBlockTxsByHash(ctx context.Context, hash string, page string, size string) (*block.BlockTxsResponse, error) {
+	out := new(block.BlockTxsResponse)
+	if err := c.do(ctx, http.MethodGet, "/block/hash/"+url.PathEscape(hash)+"/txs/page/"+url.PathEscape(page)+"/size/"+url.PathEscape(size), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetBlockHeaderByHeight 通过高度获取区块头信息
+// GET /block/height/:height/header
+func (c *Client) GetBlockHeaderByHeight(ctx context.Context, height string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/height/"+url.PathEscape(height)+"/header", nil, nil, &out)
+	return out, err
+}
+
+// GetBlockHeaderByHash 通过哈希获取区块头信息
+// GET /block/hash/:hash/header
+func (c *Client) GetBlockHeaderByHash(ctx context.Context, hash string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/hash/"+url.PathEscape(hash)+"/header", nil, nil, &out)
+	return out, err
+}
+
+// GetNearby10HeadersQuery GetNearby10Headers的查询参数
+type GetNearby10HeadersQuery struct {
+	Count string // count
+}
+
+func (q *GetNearby10HeadersQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Count != "" {
+		values.Set("count", q.Count)
+	}
+	return values
+}
+
+// GetNearby10Headers 获取链顶附近的区块头信息
+// GET /block/headers
+func (c *Client) GetNearby10Headers(ctx context.Context, query *GetNearby10HeadersQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/headers", query.values(), nil, &out)
+	return out, err
+}
+
+// GetNextBlockQuery GetNextBlock的查询参数
+type GetNextBlockQuery struct {
+	Timeout string // timeout
+	After   string // after
+}
+
+func (q *GetNextBlockQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Timeout != "" {
+		values.Set("timeout", q.Timeout)
+	}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetNextBlock 长轮询等待下一个区块
+// GET /block/next
+func (c *Client) GetNextBlock(ctx context.Context, query *GetNextBlockQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/block/next", query.values(), nil, &out)
+	return out, err
+}
+
+// GetBlockRangeTxs 流式获取区块范围内的交易摘要
+// GET /blocks/:from/:to/txs
+func (c *Client) GetBlockRangeTxs(ctx context.Context, from string, to string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/blocks/"+url.PathEscape(from)+"/"+url.PathEscape(to)+"/txs", nil, nil, &out)
+	return out, err
+}
+
+// GetChainInfo 获取区块链信息
+// GET /chain/info
+func (c *Client) GetChainInfo(ctx context.Context) (*block.ChainInfo, error) {
+	out := new(block.ChainInfo)
+	if err := c.do(ctx, http.MethodGet, "/chain/info", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetChainTips 获取节点已知的链顶和分叉
+// GET /chain/tips
+func (c *Client) GetChainTips(ctx context.Context) (*block.ChainTipsResponse, error) {
+	out := new(block.ChainTipsResponse)
+	if err := c.do(ctx, http.MethodGet, "/chain/tips", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMemPoolTxs 获取内存池交易列表
+// GET /mempool/mempool/txs
+func (c *Client) GetMemPoolTxs(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/mempool/mempool/txs", nil, nil, &out)
+	return out, err
+}
+
+// GetFeeHistogram 获取内存池费率直方图
+// GET /mempool/fee-histogram
+func (c *Client) GetFeeHistogram(ctx context.Context) (*mempool.FeeHistogramResponse, error) {
+	out := new(mempool.FeeHistogramResponse)
+	if err := c.do(ctx, http.MethodGet, "/mempool/fee-histogram", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMempoolInfo 获取内存池交易数量、大小和费率直方图
+// GET /mempool/info
+func (c *Client) GetMempoolInfo(ctx context.Context) (*mempool.MempoolInfoResponse, error) {
+	out := new(mempool.MempoolInfoResponse)
+	if err := c.do(ctx, http.MethodGet, "/mempool/info", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMempoolTx 获取内存池中未确认交易的详情、手续费和祖先交易
+// GET /mempool/tx/:txid
+func (c *Client) GetMempoolTx(ctx context.Context, txid string) (*mempool.MempoolTxResponse, error) {
+	out := new(mempool.MempoolTxResponse)
+	if err := c.do(ctx, http.MethodGet, "/mempool/tx/"+url.PathEscape(txid), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetActivityQuery GetActivity的查询参数
+type GetActivityQuery struct {
+	From     string // from
+	To       string // to
+	Interval string // interval
+}
+
+func (q *GetActivityQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.From != "" {
+		values.Set("from", q.From)
+	}
+	if q.To != "" {
+		values.Set("to", q.To)
+	}
+	if q.Interval != "" {
+		values.Set("interval", q.Interval)
+	}
+	return values
+}
+
+// GetActivity 获取链上活跃度时间序列
+// GET /stats/activity
+func (c *Client) GetActivity(ctx context.Context, query *GetActivityQuery) (*analytics.ActivityResponse, error) {
+	out := new(analytics.ActivityResponse)
+	if err := c.do(ctx, http.MethodGet, "/stats/activity", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Subscribe 通过WebSocket订阅新区块头和地址活动
+// GET /ws
+func (c *Client) Subscribe(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/ws", nil, nil, &out)
+	return out, err
+}
+
+// BroadcastTxRawQuery BroadcastTxRaw的查询参数
+type BroadcastTxRawQuery struct {
+	DryRun string // dry_run
+}
+
+func (q *BroadcastTxRawQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.DryRun != "" {
+		values.Set("dry_run", q.DryRun)
+	}
+	return values
+}
+
+// BroadcastTxRaw 广播单笔原始交易
+// POST /broadcast/tx/raw
+func (c *Client) BroadcastTxRaw(ctx context.Context, query *BroadcastTxRawQuery, body *broadcast.TxBroadcastRequest) (*broadcast.BroadcastResponse, error) {
+	out := new(broadcast.BroadcastResponse)
+	if err := c.do(ctx, http.MethodPost, "/broadcast/tx/raw", query.values(), body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BroadcastTxsRaw 批量广播原始交易
+// POST /broadcast/txs/raw
+func (c *Client) BroadcastTxsRaw(ctx context.Context, body broadcast.TxsBroadcastRequest) (*broadcast.TxsBroadcastResponse, error) {
+	out := new(broadcast.TxsBroadcastResponse)
+	if err := c.do(ctx, http.MethodPost, "/broadcast/txs/raw", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BroadcastTxRawLegacyQuery BroadcastTxRawLegacy的查询参数
+type BroadcastTxRawLegacyQuery struct {
+	DryRun string // dry_run
+}
+
+func (q *BroadcastTxRawLegacyQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.DryRun != "" {
+		values.Set("dry_run", q.DryRun)
+	}
+	return values
+}
+
+// BroadcastTxRawLegacy 广播单笔原始交易
+// POST /tx/raw
+func (c *Client) BroadcastTxRawLegacy(ctx context.Context, query *BroadcastTxRawLegacyQuery, body *broadcast.TxBroadcastRequest) (*broadcast.BroadcastResponse, error) {
+	out := new(broadcast.BroadcastResponse)
+	if err := c.do(ctx, http.MethodPost, "/tx/raw", query.values(), body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListBroadcastFailuresQuery ListBroadcastFailures的查询参数
+type ListBroadcastFailuresQuery struct {
+	Status string // status
+	Page   string // page
+	Size   string // size
+}
+
+func (q *ListBroadcastFailuresQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Status != "" {
+		values.Set("status", q.Status)
+	}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// ListBroadcastFailures 获取广播失败的交易记录
+// GET /admin/broadcast/failures
+func (c *Client) ListBroadcastFailures(ctx context.Context, query *ListBroadcastFailuresQuery) (*broadcast.BroadcastFailuresResponse, error) {
+	out := new(broadcast.BroadcastFailuresResponse)
+	if err := c.do(ctx, http.MethodGet, "/admin/broadcast/failures", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReplayBroadcastFailure 重新广播失败记录中的交易
+// POST /admin/broadcast/failures/:id/replay
+func (c *Client) ReplayBroadcastFailure(ctx context.Context, id string, body any) (*broadcast.ReplayFailureResponse, error) {
+	out := new(broadcast.ReplayFailureResponse)
+	if err := c.do(ctx, http.MethodPost, "/admin/broadcast/failures/"+url.PathEscape(id)+"/replay", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeTxRaw 解码原始交易
+// POST /tx/raw/decode
+func (c *Client) DecodeTxRaw(ctx context.Context, body *transaction.TxDecodeRawRequest) (*transaction.TxDecodeResponse, error) {
+	out := new(transaction.TxDecodeResponse)
+	if err := c.do(ctx, http.MethodPost, "/tx/raw/decode", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTxRawHexQuery GetTxRawHex的查询参数
+type GetTxRawHexQuery struct {
+	After string // after
+}
+
+func (q *GetTxRawHexQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// GetTxRawHex 获取交易原始十六进制数据
+// GET /tx/hex/:txid
+func (c *Client) GetTxRawHex(ctx context.Context, txid string, query *GetTxRawHexQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/tx/hex/"+url.PathEscape(txid), query.values(), nil, &out)
+	return out, err
+}
+
+// DecodeTxByHashQuery DecodeTxByHash的查询参数
+type DecodeTxByHashQuery struct {
+	After string // after
+}
+
+func (q *DecodeTxByHashQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.After != "" {
+		values.Set("after", q.After)
+	}
+	return values
+}
+
+// DecodeTxByHash 通过交易ID解码交易
+// GET /tx/hex/:txid/decode
+func (c *Client) DecodeTxByHash(ctx context.Context, txid string, query *DecodeTxByHashQuery) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodGet, "/tx/hex/"+url.PathEscape(txid)+"/decode", query.values(), nil, &out)
+	return out, err
+}
+
+// GetTxVins 获取交易输入数据
+// POST /tx/vins
+func (c *Client) GetTxVins(ctx context.Context, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/tx/vins", nil, body, &out)
+	return out, err
+}
+
+// EstimateFeeQuery EstimateFee的查询参数
+type EstimateFeeQuery struct {
+	Blocks string // blocks
+}
+
+func (q *EstimateFeeQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Blocks != "" {
+		values.Set("blocks", q.Blocks)
+	}
+	return values
+}
+
+// EstimateFee 估算多个确认目标的费率和按内存池积压调整的推荐费率
+// GET /tx/fee/estimate
+func (c *Client) EstimateFee(ctx context.Context, query *EstimateFeeQuery) (*mempool.FeeEstimateResponse, error) {
+	out := new(mempool.FeeEstimateResponse)
+	if err := c.do(ctx, http.MethodGet, "/tx/fee/estimate", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateWallet 创建跟踪钱包
+// POST /wallet
+func (c *Client) CreateWallet(ctx context.Context, body *wallet.CreateWalletRequest) (*wallet.WalletSummaryResponse, error) {
+	out := new(wallet.WalletSummaryResponse)
+	if err := c.do(ctx, http.MethodPost, "/wallet", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PreviewDescriptor 预览输出描述符展开的地址
+// POST /wallet/descriptor/expand
+func (c *Client) PreviewDescriptor(ctx context.Context, body *wallet.DescriptorImport) (*wallet.DescriptorExpandResponse, error) {
+	out := new(wallet.DescriptorExpandResponse)
+	if err := c.do(ctx, http.MethodPost, "/wallet/descriptor/expand", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ScanRecovery 扫描候选地址或扩展公钥中有链上历史的地址
+// POST /recovery/scan
+func (c *Client) ScanRecovery(ctx context.Context, body *wallet.RecoveryScanRequest) (*wallet.RecoveryScanResponse, error) {
+	out := new(wallet.RecoveryScanResponse)
+	if err := c.do(ctx, http.MethodPost, "/recovery/scan", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWalletSummary 获取跟踪钱包汇总数据
+// GET /wallet/:wallet_id/summary
+func (c *Client) GetWalletSummary(ctx context.Context, walletID string) (*wallet.WalletSummaryResponse, error) {
+	out := new(wallet.WalletSummaryResponse)
+	if err := c.do(ctx, http.MethodGet, "/wallet/"+url.PathEscape(walletID)+"/summary", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWalletHistoryQuery GetWalletHistory的查询参数
+type GetWalletHistoryQuery struct {
+	Page string // page
+	Size string // size
+}
+
+func (q *GetWalletHistoryQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// GetWalletHistory 获取跟踪钱包交易历史
+// GET /wallet/:wallet_id/history
+func (c *Client) GetWalletHistory(ctx context.Context, walletID string, query *GetWalletHistoryQuery) (*wallet.WalletHistoryResponse, error) {
+	out := new(wallet.WalletHistoryResponse)
+	if err := c.do(ctx, http.MethodGet, "/wallet/"+url.PathEscape(walletID)+"/history", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWalletAddressesQuery GetWalletAddresses的查询参数
+type GetWalletAddressesQuery struct {
+	Status string // status
+	Page   string // page
+	Size   string // size
+}
+
+func (q *GetWalletAddressesQuery) values() url.Values {
+	if q == nil {
+		return nil
+	}
+	values := url.Values{}
+	if q.Status != "" {
+		values.Set("status", q.Status)
+	}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values
+}
+
+// GetWalletAddresses 获取跟踪钱包地址的监听状态
+// GET /wallet/:wallet_id/addresses
+func (c *Client) GetWalletAddresses(ctx context.Context, walletID string, query *GetWalletAddressesQuery) (*wallet.WalletAddressesResponse, error) {
+	out := new(wallet.WalletAddressesResponse)
+	if err := c.do(ctx, http.MethodGet, "/wallet/"+url.PathEscape(walletID)+"/addresses", query.values(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegisterDepositAddresses 登记带过期策略的充值地址
+// POST /wallet/:wallet_id/deposit-addresses
+func (c *Client) RegisterDepositAddresses(ctx context.Context, walletID string, body *wallet.RegisterDepositAddressesRequest) (*wallet.WalletAddressesResponse, error) {
+	out := new(wallet.WalletAddressesResponse)
+	if err := c.do(ctx, http.MethodPost, "/wallet/"+url.PathEscape(walletID)+"/deposit-addresses", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteWallet 删除跟踪钱包
+// DELETE /wallet/:wallet_id
+func (c *Client) DeleteWallet(ctx context.Context, walletID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodDelete, "/wallet/"+url.PathEscape(walletID), nil, nil, &out)
+	return out, err
+}
+
+// SetWalletNotification 设置跟踪钱包通知
+// POST /wallet/:wallet_id/notification
+func (c *Client) SetWalletNotification(ctx context.Context, walletID string, body *wallet.NotificationRequest) (*wallet.NotificationResponse, error) {
+	out := new(wallet.NotificationResponse)
+	if err := c.do(ctx, http.MethodPost, "/wallet/"+url.PathEscape(walletID)+"/notification", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWalletNotification 获取跟踪钱包通知设置
+// GET /wallet/:wallet_id/notification
+func (c *Client) GetWalletNotification(ctx context.Context, walletID string) (*wallet.NotificationResponse, error) {
+	out := new(wallet.NotificationResponse)
+	if err := c.do(ctx, http.MethodGet, "/wallet/"+url.PathEscape(walletID)+"/notification", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteWalletNotification 删除跟踪钱包通知设置
+// DELETE /wallet/:wallet_id/notification
+func (c *Client) DeleteWalletNotification(ctx context.Context, walletID string) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodDelete, "/wallet/"+url.PathEscape(walletID)+"/notification", nil, nil, &out)
+	return out, err
+}
+
+// RequestFunds 向地址发放测试币
+// POST /faucet/request/:address
+func (c *Client) RequestFunds(ctx context.Context, address string, body any) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, http.MethodPost, "/faucet/request/"+url.PathEscape(address), nil, body, &out)
+	return out, err
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ginproject/entity/block"
+	"ginproject/entity/electrumx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestServer 启动挂载在接口路径前缀下的测试服务
+func newTestServer(t *testing.T, register func(api *gin.RouterGroup)) *Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	register(router.Group(APIPrefix))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return New(server.URL, WithAdminToken("secret"))
+}
+
+func TestClientTypedResponse(t *testing.T) {
+	client := newTestServer(t, func(api *gin.RouterGroup) {
+		api.GET("/chain/info", func(c *gin.Context) {
+			c.JSON(http.StatusOK, block.ChainInfo{Chain: "main", Blocks: 100})
+		})
+		api.GET("/address/:address/history", func(c *gin.Context) {
+			c.JSON(http.StatusOK, electrumx.AddressHistoryResponse{
+				Address: c.Param("address"),
+				Script:  c.Query("from_height") + "|" + c.GetHeader(adminTokenHeader),
+			})
+		})
+	})
+	ctx := context.Background()
+
+	info, err := client.GetChainInfo(ctx)
+	if err != nil || info.Chain != "main" || info.Blocks != 100 {
+		t.Fatalf("GetChainInfo = %+v, %v", info, err)
+	}
+
+	history, err := client.GetAddressHistory(ctx, "1A B", &GetAddressHistoryQuery{FromHeight: "10"})
+	if err != nil {
+		t.Fatalf("GetAddressHistory失败: %v", err)
+	}
+	if history.Address != "1A B" || history.Script != "10|secret" {
+		t.Fatalf("路径参数、查询参数或令牌未正确发送: %+v", history)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	client := newTestServer(t, func(api *gin.RouterGroup) {
+		api.GET("/wallet/:wallet_id/summary", func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "钱包不存在"})
+		})
+		api.GET("/health", func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+	})
+	ctx := context.Background()
+
+	_, err := client.GetWalletSummary(ctx, "w1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("期望404错误，实际: %v", err)
+	}
+
+	// 未声明响应类型的接口返回原始响应体
+	body, err := client.HealthCheck(ctx)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("HealthCheck = %q, %v", body, err)
+	}
+}
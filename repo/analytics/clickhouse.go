@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ginproject/entity/analytics"
+	"ginproject/middleware/log"
+)
+
+const (
+	// 交易摘要表
+	clickHouseTxTable = "tx_summaries"
+	// 未配置时的请求超时时间
+	defaultClickHouseTimeout = 10 * time.Second
+	// 错误响应中最多保留的字节数
+	maxClickHouseErrorSize = 1024
+)
+
+// ClickHouse 通过HTTP接口访问的ClickHouse分析库
+type ClickHouse struct {
+	endpoint string
+	database string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewClickHouse 创建ClickHouse分析存储
+func NewClickHouse(endpoint, database, user, password string, timeout time.Duration) (*ClickHouse, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("ClickHouse地址无效: %w", err)
+	}
+	if database == "" {
+		return nil, errors.New("ClickHouse数据库名不能为空")
+	}
+	if timeout <= 0 {
+		timeout = defaultClickHouseTimeout
+	}
+	return &ClickHouse{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		database: database,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Driver 返回分析库类型
+func (c *ClickHouse) Driver() string {
+	return DriverClickHouse
+}
+
+// WriteTxs 以JSONEachRow格式批量写入交易摘要，表按(height, txid)去重
+func (c *ClickHouse) WriteTxs(ctx context.Context, records []analytics.TxRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for i := range records {
+		if err := encoder.Encode(&records[i]); err != nil {
+			return fmt.Errorf("序列化交易摘要失败: %w", err)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", clickHouseTxTable)
+	resp, err := c.do(ctx, query, &body)
+	if err != nil {
+		return fmt.Errorf("写入ClickHouse失败: %w", err)
+	}
+	resp.Close()
+	return nil
+}
+
+// Activity 按统计周期聚合交易数和输出总额
+func (c *ClickHouse) Activity(ctx context.Context, query analytics.ActivityQuery) ([]analytics.ActivityPoint, error) {
+	sql := fmt.Sprintf(
+		"SELECT intDiv(toUnixTimestamp(block_time), %d) * %d AS time, count() AS txs, sum(total_out) AS volume "+
+			"FROM %s FINAL WHERE block_time >= toDateTime(%d) AND block_time < toDateTime(%d) "+
+			"GROUP BY time ORDER BY time FORMAT JSONEachRow",
+		query.Interval, query.Interval, clickHouseTxTable, query.From, query.To)
+
+	resp, err := c.do(ctx, sql, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询ClickHouse失败: %w", err)
+	}
+	defer resp.Close()
+
+	// ClickHouse默认将64位整数输出为字符串
+	var row struct {
+		Time   json.Number `json:"time"`
+		Txs    json.Number `json:"txs"`
+		Volume float64     `json:"volume"`
+	}
+	points := make([]analytics.ActivityPoint, 0)
+	scanner := bufio.NewScanner(resp)
+	for scanner.Scan() {
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("解析ClickHouse结果失败: %w", err)
+		}
+		t, _ := row.Time.Int64()
+		txs, _ := row.Txs.Int64()
+		volume := row.Volume
+		points = append(points, analytics.ActivityPoint{Time: t, Txs: txs, Volume: &volume})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取ClickHouse结果失败: %w", err)
+	}
+	return points, nil
+}
+
+// do 执行一条语句，body不为空时作为语句的数据部分发送，调用方负责关闭返回的响应体
+func (c *ClickHouse) do(ctx context.Context, query string, body io.Reader) (io.ReadCloser, error) {
+	params := url.Values{}
+	params.Set("database", c.database)
+	params.Set("query", query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/?"+params.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxClickHouseErrorSize))
+		log.WarnWithContext(ctx, "ClickHouse请求失败", "status:", resp.StatusCode, "message:", string(message))
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp.Body, nil
+}
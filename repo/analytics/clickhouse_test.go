@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ginproject/entity/analytics"
+)
+
+func TestClickHouse(t *testing.T) {
+	var gotQuery, gotBody, gotUser string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("query")
+		gotUser = r.Header.Get("X-ClickHouse-User")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		if r.URL.Query().Get("database") != "tbc" {
+			http.Error(w, "Unknown database", http.StatusNotFound)
+			return
+		}
+		if strings.HasPrefix(gotQuery, "SELECT") {
+			io.WriteString(w, `{"time":"120","txs":"3","volume":1.5}`+"\n"+`{"time":"180","txs":"1","volume":0}`+"\n")
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewClickHouse(server.URL, "tbc", "reader", "secret", time.Second)
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	ctx := context.Background()
+
+	records := []analytics.TxRecord{{Height: 1, Txid: "aa", TxType: "P2PKH"}, {Height: 1, Txid: "bb", TxIndex: 1}}
+	if err := sink.WriteTxs(ctx, records); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if !strings.HasPrefix(gotQuery, "INSERT INTO tx_summaries") || strings.Count(gotBody, "\n") != 2 || gotUser != "reader" {
+		t.Fatalf("写入请求不一致: %q %q %q", gotQuery, gotBody, gotUser)
+	}
+
+	points, err := sink.Activity(ctx, analytics.ActivityQuery{From: 100, To: 240, Interval: 60})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(points) != 2 || points[0].Time != 120 || points[0].Txs != 3 || *points[0].Volume != 1.5 {
+		t.Fatalf("查询结果不一致: %+v", points)
+	}
+
+	wrongDB, _ := NewClickHouse(server.URL, "other", "", "", time.Second)
+	if err := wrongDB.WriteTxs(ctx, records); err == nil || !strings.Contains(err.Error(), "Unknown database") {
+		t.Fatalf("期望返回服务端错误，实际: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ginproject/entity/analytics"
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+)
+
+// 支持的分析库类型
+const DriverClickHouse = "clickhouse"
+
+// Sink 分析存储，接收交易摘要并提供统计查询
+type Sink interface {
+	// Driver 返回分析库类型
+	Driver() string
+	// WriteTxs 写入交易摘要，同一交易重复写入时不产生重复统计
+	WriteTxs(ctx context.Context, records []analytics.TxRecord) error
+	// Activity 查询活跃度时间序列
+	Activity(ctx context.Context, query analytics.ActivityQuery) ([]analytics.ActivityPoint, error)
+}
+
+var (
+	mu      sync.RWMutex
+	current Sink
+)
+
+// Init 按配置创建分析存储，未配置时不启用
+func Init() error {
+	cfg := config.GetConfig().GetAnalyticsConfig()
+	if cfg.Driver == "" {
+		return nil
+	}
+
+	sink, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	SetDefault(sink)
+	log.Info("分析存储已启用", "driver:", cfg.Driver, "url:", cfg.URL)
+	return nil
+}
+
+// New 根据配置创建分析存储
+func New(cfg *config.AnalyticsConfig) (Sink, error) {
+	switch cfg.Driver {
+	case DriverClickHouse:
+		timeout := time.Duration(cfg.Timeout) * time.Second
+		return NewClickHouse(cfg.URL, cfg.Database, cfg.User, cfg.Password, timeout)
+	default:
+		return nil, fmt.Errorf("不支持的分析库类型: %s", cfg.Driver)
+	}
+}
+
+// Default 返回当前启用的分析存储，未启用时返回nil
+func Default() Sink {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// SetDefault 替换当前的分析存储，传入nil时停用
+func SetDefault(sink Sink) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = sink
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"errors"
+
+	"ginproject/entity/config"
+)
+
+// ErrClusterRedisRequired 开启集群模式但未配置Redis
+var ErrClusterRedisRequired = errors.New("集群模式需要配置cache.redis.addr")
+
+// ClusterEnabled 判断是否开启了集群模式
+func ClusterEnabled() bool {
+	return config.GetConfig().GetCacheConfig().Cluster
+}
+
+// Shared 返回集群模式下保存跨实例状态的Redis，未开启集群模式或未连接Redis时返回nil，调用方使用进程内状态
+func Shared() *Redis {
+	if !ClusterEnabled() {
+		return nil
+	}
+	redis, _ := DefaultRemote().(*Redis)
+	return redis
+}
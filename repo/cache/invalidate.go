@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+)
+
+// Purger 可以整体清空的缓存
+type Purger interface {
+	Purge()
+}
+
+var (
+	blockScopedMu sync.RWMutex
+	blockScoped   = make(map[string]Purger)
+)
+
+// RegisterBlockScoped 登记内容随新区块失效的缓存，收到新区块事件时整体清空，未收到时按有效期过期
+func RegisterBlockScoped(name string, c Purger) {
+	blockScopedMu.Lock()
+	defer blockScopedMu.Unlock()
+	blockScoped[name] = c
+}
+
+// PurgeBlockScoped 清空全部随新区块失效的缓存，返回清空的缓存名称
+func PurgeBlockScoped() []string {
+	blockScopedMu.RLock()
+	defer blockScopedMu.RUnlock()
+
+	names := make([]string, 0, len(blockScoped))
+	for name, c := range blockScoped {
+		c.Purge()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
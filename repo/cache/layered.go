@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+// Layered 两级缓存，进程内LRU为第一级，启用共享缓存时以其为第二级
+// 条目以字节保存，调用方负责编解码，每次命中得到的都是独立的副本
+type Layered struct {
+	name  string
+	ttl   time.Duration
+	local *LRU[string, []byte]
+
+	remoteHits   atomic.Uint64
+	remoteMisses atomic.Uint64
+}
+
+// NewLayered 创建两级缓存，name同时作为共享缓存中的键前缀
+func NewLayered(name string, capacity int, ttl time.Duration) *Layered {
+	return &Layered{name: name, ttl: ttl, local: NewLRU[string, []byte](capacity, ttl)}
+}
+
+// Get 先查进程内缓存，未命中时查共享缓存并回填进程内缓存；共享缓存出错按未命中处理
+func (c *Layered) Get(ctx context.Context, key string) ([]byte, bool) {
+	if value, ok := c.local.Get(key); ok {
+		return value, true
+	}
+
+	remote := DefaultRemote()
+	if remote == nil {
+		return nil, false
+	}
+	value, ok, err := remote.Get(ctx, c.remoteKey(key))
+	if err != nil {
+		log.WarnWithContext(ctx, "读取共享缓存失败", "cache:", c.name, "错误:", err)
+		return nil, false
+	}
+	if !ok {
+		c.remoteMisses.Add(1)
+		return nil, false
+	}
+	c.remoteHits.Add(1)
+	c.local.Set(key, value)
+	return value, true
+}
+
+// Set 写入两级缓存，共享缓存写入失败只记录日志
+func (c *Layered) Set(ctx context.Context, key string, value []byte) {
+	c.local.Set(key, value)
+
+	remote := DefaultRemote()
+	if remote == nil {
+		return
+	}
+	if err := remote.Set(ctx, c.remoteKey(key), value, c.ttl); err != nil {
+		log.WarnWithContext(ctx, "写入共享缓存失败", "cache:", c.name, "错误:", err)
+	}
+}
+
+// Delete 从两级缓存中删除条目，共享缓存删除失败只记录日志
+func (c *Layered) Delete(ctx context.Context, key string) {
+	c.local.Delete(key)
+
+	remote := DefaultRemote()
+	if remote == nil {
+		return
+	}
+	if err := remote.Delete(ctx, c.remoteKey(key)); err != nil {
+		log.WarnWithContext(ctx, "删除共享缓存失败", "cache:", c.name, "错误:", err)
+	}
+}
+
+// Purge 清空进程内缓存，共享缓存中的条目按有效期过期
+func (c *Layered) Purge() {
+	c.local.Purge()
+}
+
+// Stats 返回进程内缓存的统计信息，附带共享缓存的命中次数
+func (c *Layered) Stats(topKeys int) Stats {
+	stats := c.local.Stats(topKeys)
+	stats.RemoteHits = c.remoteHits.Load()
+	stats.RemoteMisses = c.remoteMisses.Load()
+	return stats
+}
+
+// remoteKey 返回共享缓存中的键名
+func (c *Layered) remoteKey(key string) string {
+	return c.name + ":" + key
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mapRemote 用于测试的共享缓存
+type mapRemote map[string][]byte
+
+func (m mapRemote) Get(_ context.Context, key string) ([]byte, bool, error) {
+	v, ok := m[key]
+	return v, ok, nil
+}
+
+func (m mapRemote) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+func (m mapRemote) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestLayeredRemoteFill(t *testing.T) {
+	remote := mapRemote{}
+	SetDefaultRemote(remote)
+	t.Cleanup(func() { SetDefaultRemote(nil) })
+	ctx := context.Background()
+
+	c := NewLayered("test", 10, time.Minute)
+	c.Set(ctx, "a", []byte("1"))
+	if string(remote["test:a"]) != "1" {
+		t.Fatalf("共享缓存未写入，实际: %v", remote)
+	}
+
+	// 另一个实例的进程内缓存为空，从共享缓存读取后回填
+	other := NewLayered("test", 10, time.Minute)
+	if v, ok := other.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Fatalf("期望从共享缓存命中a=1，实际: %q %v", v, ok)
+	}
+	delete(remote, "test:a")
+	if v, ok := other.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Fatalf("期望从进程内缓存命中a=1，实际: %q %v", v, ok)
+	}
+
+	stats := other.Stats(0)
+	if stats.RemoteHits != 1 || stats.Hits != 1 {
+		t.Fatalf("统计不符: %+v", stats)
+	}
+}
+
+func TestLayeredDelete(t *testing.T) {
+	remote := mapRemote{}
+	SetDefaultRemote(remote)
+	t.Cleanup(func() { SetDefaultRemote(nil) })
+	ctx := context.Background()
+
+	c := NewLayered("test", 10, time.Minute)
+	c.Set(ctx, "a", []byte("1"))
+	c.Delete(ctx, "a")
+	if _, ok := c.Get(ctx, "a"); ok {
+		t.Fatal("删除后不应命中")
+	}
+	if _, ok := remote["test:a"]; ok {
+		t.Fatalf("共享缓存中的条目未删除: %v", remote)
+	}
+}
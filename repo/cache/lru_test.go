@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// 访问a后b成为最久未使用的条目
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("期望命中a=1，实际: %v %v", v, ok)
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b应该已被淘汰")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("期望命中c=3，实际: %v %v", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("期望2个条目，实际: %d", c.Len())
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	c := NewLRU[string, int](10, 20*time.Millisecond)
+	c.Set("a", 1)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("条目应在有效期内")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("条目应已过期")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("过期条目应被清理，实际: %d", c.Len())
+	}
+}
+
+func TestLRUStats(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Get("missing")
+	c.Set("c", 3) // 写入c时淘汰最久未使用的a
+
+	stats := c.Stats(1)
+	if stats.Hits != 3 || stats.Misses != 1 || stats.Evictions != 1 || stats.Size != 2 {
+		t.Fatalf("统计结果: %+v", stats)
+	}
+	if stats.HitRatio != 0.75 {
+		t.Fatalf("命中率: %v", stats.HitRatio)
+	}
+	if len(stats.TopKeys) != 1 || stats.TopKeys[0].Key != "b" {
+		t.Fatalf("热点键: %+v", stats.TopKeys)
+	}
+
+	expiring := NewLRU[string, int](2, time.Millisecond)
+	expiring.Set("a", 1)
+	time.Sleep(5 * time.Millisecond)
+	expiring.Get("a")
+	if stats := expiring.Stats(0); stats.Stale != 1 || stats.Misses != 1 {
+		t.Fatalf("过期统计: %+v", stats)
+	}
+}
+
+func TestPurgeBlockScoped(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	c.Set("a", 1)
+	RegisterBlockScoped("test_block_scoped", c)
+	t.Cleanup(func() {
+		blockScopedMu.Lock()
+		delete(blockScoped, "test_block_scoped")
+		blockScopedMu.Unlock()
+	})
+
+	if names := PurgeBlockScoped(); !slices.Contains(names, "test_block_scoped") {
+		t.Fatalf("清空的缓存: %v", names)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("出块后缓存应被清空")
+	}
+}
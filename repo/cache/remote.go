@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+)
+
+// 未配置时单次Redis命令的超时时间
+const defaultRedisTimeout = 200 * time.Millisecond
+
+// Remote 进程外的共享缓存，多个实例之间共享条目
+type Remote interface {
+	// Get 读取条目，不存在时返回false
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set 写入条目，ttl为0时不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete 删除条目，条目不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	remoteMu      sync.RWMutex
+	defaultRemote Remote
+)
+
+// InitRemote 按配置连接Redis作为共享缓存，未配置时只使用进程内缓存
+func InitRemote() error {
+	cacheCfg := config.GetConfig().GetCacheConfig()
+	cfg := cacheCfg.Redis
+	if cfg.Addr == "" {
+		if cacheCfg.Cluster {
+			return ErrClusterRedisRequired
+		}
+		return nil
+	}
+
+	remote := NewRedis(&cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := remote.client.Ping(ctx).Err(); err != nil {
+		remote.client.Close()
+		return fmt.Errorf("连接Redis失败: %w", err)
+	}
+	SetDefaultRemote(remote)
+	log.Info("共享缓存已启用", "redis:", cfg.Addr, "db:", cfg.DB)
+	return nil
+}
+
+// DefaultRemote 返回当前的共享缓存，未启用时返回nil
+func DefaultRemote() Remote {
+	remoteMu.RLock()
+	defer remoteMu.RUnlock()
+	return defaultRemote
+}
+
+// SetDefaultRemote 替换当前的共享缓存，传入nil时停用
+func SetDefaultRemote(remote Remote) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	defaultRemote = remote
+}
+
+// Redis 基于Redis的共享缓存
+type Redis struct {
+	client  *redis.Client
+	prefix  string
+	timeout time.Duration
+}
+
+// NewRedis 根据配置创建Redis共享缓存
+func NewRedis(cfg *config.RedisConfig) *Redis {
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultRedisTimeout
+	}
+	return &Redis{
+		client: redis.NewClient(&redis.Options{
+			Addr:         cfg.Addr,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			ReadTimeout:  timeout,
+			WriteTimeout: timeout,
+		}),
+		prefix:  cfg.Prefix,
+		timeout: timeout,
+	}
+}
+
+// Client 返回底层的Redis客户端
+func (r *Redis) Client() *redis.Client {
+	return r.client
+}
+
+// Key 返回带前缀的键名
+func (r *Redis) Key(key string) string {
+	return r.prefix + key
+}
+
+// Get 读取条目，不存在时返回false
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	value, err := r.client.Get(ctx, r.Key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set 写入条目，ttl为0时不过期
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.client.Set(ctx, r.Key(key), value, ttl).Err()
+}
+
+// Delete 删除条目，条目不存在时不报错
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.client.Del(ctx, r.Key(key)).Err()
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// KeyHits 单个缓存键的命中次数
+type KeyHits struct {
+	Key  string `json:"key"`
+	Hits uint64 `json:"hits"`
+}
+
+// Stats 缓存统计信息
+type Stats struct {
+	Name         string    `json:"name"`
+	Size         int       `json:"size"`                    // 当前条目数，包含尚未被清理的过期条目
+	Capacity     int       `json:"capacity"`                // 最大条目数
+	TTLSeconds   float64   `json:"ttl_seconds"`             // 条目有效期，0表示不过期
+	Hits         uint64    `json:"hits"`                    // 命中次数，即节省的上游调用次数
+	Misses       uint64    `json:"misses"`                  // 未命中次数，包含过期
+	Stale        uint64    `json:"stale"`                   // 因过期未命中的次数
+	Evictions    uint64    `json:"evictions"`               // 因容量不足被淘汰的条目数
+	HitRatio     float64   `json:"hit_ratio"`               // 命中率
+	RemoteHits   uint64    `json:"remote_hits,omitempty"`   // 两级缓存在进程内未命中后共享缓存命中的次数
+	RemoteMisses uint64    `json:"remote_misses,omitempty"` // 两级缓存在共享缓存中也未命中的次数
+	TopKeys      []KeyHits `json:"top_keys,omitempty"`
+}
+
+// Reporter 可以汇报统计信息的缓存
+type Reporter interface {
+	Stats(topKeys int) Stats
+}
+
+// Stats 返回缓存统计信息，topKeys大于0时附带命中次数最多的键
+func (c *LRU[K, V]) Stats(topKeys int) Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		Size:       c.order.Len(),
+		Capacity:   c.capacity,
+		TTLSeconds: c.ttl.Seconds(),
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Stale:      c.stale,
+		Evictions:  c.evictions,
+	}
+	if total := c.hits + c.misses; total > 0 {
+		stats.HitRatio = float64(c.hits) / float64(total)
+	}
+	if topKeys > 0 {
+		stats.TopKeys = c.topKeysLocked(topKeys)
+	}
+	return stats
+}
+
+// topKeysLocked 按命中次数降序返回前n个键，调用方需持有锁
+func (c *LRU[K, V]) topKeysLocked(n int) []KeyHits {
+	keys := make([]KeyHits, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*lruEntry[K, V])
+		if entry.hits > 0 {
+			keys = append(keys, KeyHits{Key: fmt.Sprint(entry.key), Hits: entry.hits})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Hits > keys[j].Hits })
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = make(map[string]Reporter)
+)
+
+func init() {
+	// 通过/metrics发布各缓存的统计信息，不包含热点键
+	expvar.Publish("caches", expvar.Func(func() any { return AllStats(0) }))
+}
+
+// Register 以名称登记缓存，登记后出现在统计汇总中，同名缓存后登记的覆盖先登记的
+func Register(name string, reporter Reporter) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	reporters[name] = reporter
+}
+
+// AllStats 返回所有已登记缓存的统计信息，按名称排序
+func AllStats(topKeys int) []Stats {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+
+	all := make([]Stats, 0, len(reporters))
+	for name, reporter := range reporters {
+		stats := reporter.Stats(topKeys)
+		stats.Name = name
+		all = append(all, stats)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
@@ -0,0 +1,52 @@
+package address_activity_dao
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 批量写入的每批记录数
+const upsertBatchSize = 500
+
+// UpsertAddressActivity 合并地址的活跃区间：首次出现取较小的高度，最近活动取较大的高度，重复写入同一区块结果不变
+// MySQL按顺序计算赋值表达式，时间和交易哈希必须在对应的高度之前更新才能与更新前的高度比较，因此使用有序的clause.Set
+func UpsertAddressActivity(ctx context.Context, activities []*dbtable.AddressActivity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "address"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "first_seen_time"}, Value: gorm.Expr("IF(VALUES(first_seen_height) < first_seen_height, VALUES(first_seen_time), first_seen_time)")},
+			{Column: clause.Column{Name: "first_seen_tx"}, Value: gorm.Expr("IF(VALUES(first_seen_height) < first_seen_height, VALUES(first_seen_tx), first_seen_tx)")},
+			{Column: clause.Column{Name: "first_seen_height"}, Value: gorm.Expr("LEAST(first_seen_height, VALUES(first_seen_height))")},
+			{Column: clause.Column{Name: "last_active_time"}, Value: gorm.Expr("IF(VALUES(last_active_height) > last_active_height, VALUES(last_active_time), last_active_time)")},
+			{Column: clause.Column{Name: "last_active_tx"}, Value: gorm.Expr("IF(VALUES(last_active_height) > last_active_height, VALUES(last_active_tx), last_active_tx)")},
+			{Column: clause.Column{Name: "last_active_height"}, Value: gorm.Expr("GREATEST(last_active_height, VALUES(last_active_height))")},
+		},
+	}).CreateInBatches(activities, upsertBatchSize)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "写入地址活跃度失败", "数量:", len(activities), "错误:", result.Error)
+		return fmt.Errorf("写入地址活跃度失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetAddressActivity 获取地址的活跃度记录
+func GetAddressActivity(ctx context.Context, address string) (*dbtable.AddressActivity, error) {
+	var activity dbtable.AddressActivity
+	result := db.GetDB().WithContext(ctx).Where("address = ?", address).First(&activity)
+	if result.Error != nil {
+		return nil, db.WrapNotFound(result.Error, db.ErrAddressActivityNotFound)
+	}
+	return &activity, nil
+}
@@ -6,6 +6,9 @@ import (
 	"ginproject/entity/dbtable"
 	"ginproject/middleware/log"
 	"ginproject/repo/db"
+	"math/rand"
+
+	"gorm.io/gorm/clause"
 )
 
 // GetAddressTransactions 根据地址获取相关交易记录
@@ -77,3 +80,68 @@ func GetAddressTransactionsByTxHashes(ctx context.Context, address string, txHas
 
 	return transactions, nil
 }
+
+// UpsertAddressTransactions 按(address, tx_hash)批量写入地址交易关系，已存在的记录覆盖为本次的值
+func UpsertAddressTransactions(ctx context.Context, records []*dbtable.AddressTransaction) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	log.InfoWithContext(ctx, "执行批量写入地址交易关系", "数量:", len(records))
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}, {Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"is_sender", "is_recipient", "balance_change", "updated_at"}),
+	}).Create(&records)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "批量写入地址交易关系失败", "错误:", result.Error)
+		return fmt.Errorf("批量写入地址交易关系失败: %w", result.Error)
+	}
+
+	return nil
+}
+
+// SampleAddresses 随机抽取最多n个不同的地址，按随机的Fid定位记录，避免对整表排序
+// 交易多的地址被抽中的概率更高，表为空时返回空列表
+func SampleAddresses(ctx context.Context, n int) ([]string, error) {
+	var bounds struct {
+		MinFid int64
+		MaxFid int64
+	}
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.AddressTransaction{}).
+		Select("MIN(Fid) AS min_fid, MAX(Fid) AS max_fid").
+		Scan(&bounds)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询地址交易记录范围失败", "错误:", result.Error)
+		return nil, fmt.Errorf("查询地址交易记录范围失败: %w", result.Error)
+	}
+
+	addresses := make([]string, 0, n)
+	if bounds.MaxFid == 0 {
+		return addresses, nil
+	}
+	seen := make(map[string]bool, n)
+	// 抽中重复地址时重试，最多尝试3n次
+	for attempt := 0; attempt < 3*n && len(addresses) < n; attempt++ {
+		fid := bounds.MinFid + rand.Int63n(bounds.MaxFid-bounds.MinFid+1)
+		var picked []string
+		result := db.GetDB().WithContext(ctx).
+			Model(&dbtable.AddressTransaction{}).
+			Where("Fid >= ?", fid).
+			Order("Fid").
+			Limit(1).
+			Pluck("address", &picked)
+		if result.Error != nil {
+			log.ErrorWithContext(ctx, "抽样地址失败", "错误:", result.Error)
+			return nil, fmt.Errorf("抽样地址失败: %w", result.Error)
+		}
+		if len(picked) == 0 || seen[picked[0]] {
+			continue
+		}
+		seen[picked[0]] = true
+		addresses = append(addresses, picked[0])
+	}
+	return addresses, nil
+}
@@ -0,0 +1,117 @@
+package broadcast_failure_dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/entity/broadcast"
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordBroadcastFailure 记录一次广播失败，同一交易重复失败时累加次数并覆盖错误信息，已重放成功的记录重新置为待处理
+func RecordBroadcastFailure(ctx context.Context, failure *dbtable.BroadcastFailure) error {
+	if failure.Status == "" {
+		failure.Status = broadcast.FailureStatusPending
+	}
+	if failure.Attempts == 0 {
+		failure.Attempts = 1
+	}
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "txid"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"error":    failure.Error,
+			"attempts": gorm.Expr("attempts + 1"),
+			"status":   broadcast.FailureStatusPending,
+		}),
+	}).Create(failure)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "记录广播失败交易失败", "txid:", failure.TxId, "错误:", result.Error)
+		return fmt.Errorf("记录广播失败交易失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetBroadcastFailure 根据记录ID获取广播失败记录
+func GetBroadcastFailure(ctx context.Context, id int64) (*dbtable.BroadcastFailure, error) {
+	var failure dbtable.BroadcastFailure
+	result := db.GetDB().WithContext(ctx).Where("id = ?", id).First(&failure)
+	if result.Error != nil {
+		return nil, db.WrapNotFound(result.Error, db.ErrBroadcastFailureNotFound)
+	}
+	return &failure, nil
+}
+
+// ListBroadcastFailures 分页获取广播失败记录，status为空时不按状态过滤，最近更新的在前
+func ListBroadcastFailures(ctx context.Context, status string, offset, limit int) ([]*dbtable.BroadcastFailure, int64, error) {
+	query := db.GetDB().WithContext(ctx).Model(&dbtable.BroadcastFailure{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.ErrorWithContext(ctx, "统计广播失败记录失败", "错误:", err)
+		return nil, 0, fmt.Errorf("统计广播失败记录失败: %w", err)
+	}
+
+	var failures []*dbtable.BroadcastFailure
+	result := query.Order("updated_at DESC, id DESC").Offset(offset).Limit(limit).Find(&failures)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询广播失败记录失败", "错误:", result.Error)
+		return nil, 0, fmt.Errorf("查询广播失败记录失败: %w", result.Error)
+	}
+	return failures, total, nil
+}
+
+// MarkBroadcastFailureReplayed 将记录标记为已重放成功
+func MarkBroadcastFailureReplayed(ctx context.Context, id int64) error {
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.BroadcastFailure{}).
+		Where("id = ?", id).
+		Update("status", broadcast.FailureStatusReplayed)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "更新广播失败记录状态失败", "id:", id, "错误:", result.Error)
+		return fmt.Errorf("更新广播失败记录状态失败: %w", result.Error)
+	}
+	return nil
+}
+
+// RecordReplayFailure 记录一次重放失败，累加次数并覆盖错误信息
+func RecordReplayFailure(ctx context.Context, id int64, errMsg string) error {
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.BroadcastFailure{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"error":    errMsg,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "更新广播失败记录失败", "id:", id, "错误:", result.Error)
+		return fmt.Errorf("更新广播失败记录失败: %w", result.Error)
+	}
+	return nil
+}
+
+// DeleteReplayedBroadcastFailures 删除最后更新早于before的已重放记录，每次最多删除limit条，返回删除的条数
+func DeleteReplayedBroadcastFailures(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := db.GetDB().WithContext(ctx).
+		Where("status = ? AND updated_at < ?", broadcast.FailureStatusReplayed, before).
+		Limit(limit).
+		Delete(&dbtable.BroadcastFailure{})
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "清理已重放的广播失败记录失败", "错误:", result.Error)
+		return 0, fmt.Errorf("清理已重放的广播失败记录失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// parseGoFiles 解析目录下的非测试Go文件
+func parseGoFiles(t *testing.T, root string, fn func(path string, fset *token.FileSet, file *ast.File)) {
+	t.Helper()
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		fn(path, fset, file)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("遍历目录%s失败: %v", root, err)
+	}
+}
+
+// isContextParam 判断参数类型是否为context.Context
+func isContextParam(field *ast.Field) bool {
+	sel, ok := field.Type.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "context" && sel.Sel.Name == "Context"
+}
+
+// isDBHandle 判断表达式是否为dao.db或db.GetDB()
+func isDBHandle(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		recv, ok := e.X.(*ast.Ident)
+		return ok && recv.Name == "dao" && e.Sel.Name == "db"
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		return ok && pkg.Name == "db" && sel.Sel.Name == "GetDB"
+	}
+	return false
+}
+
+// TestDAOMethodsAcceptContext 所有DAO查询方法的第一个参数必须是ctx，且数据库句柄必须先绑定ctx
+func TestDAOMethodsAcceptContext(t *testing.T) {
+	parseGoFiles(t, ".", func(path string, fset *token.FileSet, file *ast.File) {
+		// 只检查各个DAO子包，dbtest等辅助包不在检查范围内
+		if !strings.HasSuffix(filepath.Dir(path), "_dao") {
+			return
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() || strings.HasPrefix(fn.Name.Name, "New") {
+				continue
+			}
+			params := fn.Type.Params.List
+			if len(params) == 0 || !isContextParam(params[0]) {
+				t.Errorf("%s: %s 的第一个参数必须是 context.Context", fset.Position(fn.Pos()), fn.Name.Name)
+			}
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if ok && isDBHandle(sel.X) && sel.Sel.Name != "WithContext" {
+				t.Errorf("%s: 数据库操作前必须调用 WithContext(ctx)", fset.Position(sel.Pos()))
+			}
+			return true
+		})
+	})
+}
+
+// TestNoBackgroundContextInRequestPath 请求处理路径上不允许创建脱离请求的上下文
+// service目录下的server.go负责进程级的启停，不在检查范围内
+func TestNoBackgroundContextInRequestPath(t *testing.T) {
+	for _, root := range []string{"../../logic", "../../service"} {
+		parseGoFiles(t, root, func(path string, fset *token.FileSet, file *ast.File) {
+			if filepath.Dir(path) == filepath.Clean("../../service") {
+				return
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				pkg, ok := sel.X.(*ast.Ident)
+				if ok && pkg.Name == "context" && (sel.Sel.Name == "Background" || sel.Sel.Name == "TODO") {
+					t.Errorf("%s: 应使用请求的ctx而不是 context.%s()", fset.Position(sel.Pos()), sel.Sel.Name)
+				}
+				return true
+			})
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package dbtest 提供不连接数据库的gorm实例，用于在测试中检查DAO生成的SQL
+package dbtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Recorder 记录DAO执行的SQL语句
+type Recorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+// Statements 返回已记录的SQL语句
+func (r *Recorder) Statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statements...)
+}
+
+// Reset 清空已记录的语句
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+}
+
+func (r *Recorder) record(tx *gorm.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+}
+
+// DryRun 创建使用MySQL方言但不执行语句的gorm实例，所有写入语句都会记录到返回的Recorder中
+func DryRun(t testing.TB) (*gorm.DB, *Recorder) {
+	t.Helper()
+
+	gdb, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "dryrun:dryrun@tcp(127.0.0.1:3306)/dryrun",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("创建DryRun数据库实例失败: %v", err)
+	}
+
+	recorder := &Recorder{}
+	callbacks := gdb.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("dbtest:record", recorder.record); err != nil {
+		t.Fatal(err)
+	}
+	if err := callbacks.Update().After("gorm:update").Register("dbtest:record", recorder.record); err != nil {
+		t.Fatal(err)
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("dbtest:record", recorder.record); err != nil {
+		t.Fatal(err)
+	}
+	return gdb, recorder
+}
+
+// IsIdempotentWrite 判断写入语句在重复执行时是否不会产生新记录：
+// INSERT必须带ON DUPLICATE KEY UPDATE，UPDATE和DELETE按条件执行天然幂等
+func IsIdempotentWrite(statement string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(statement))
+	if strings.HasPrefix(upper, "INSERT") {
+		return strings.Contains(upper, "ON DUPLICATE KEY UPDATE")
+	}
+	return strings.HasPrefix(upper, "UPDATE") || strings.HasPrefix(upper, "DELETE")
+}
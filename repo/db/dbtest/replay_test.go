@@ -0,0 +1,140 @@
+package dbtest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/transaction"
+	"ginproject/repo/db"
+	"ginproject/repo/db/address_activity_dao"
+	"ginproject/repo/db/address_transactions_dao"
+	"ginproject/repo/db/dbtest"
+	"ginproject/repo/db/ft_txo_dao"
+	"ginproject/repo/db/nft_utxo_set_dao"
+	"ginproject/repo/db/transaction_participants_dao"
+	"ginproject/repo/db/transactions_dao"
+)
+
+// applyBlock 按索引器的顺序写入一个区块的全部数据
+func applyBlock(t *testing.T, ctx context.Context, height int64) {
+	t.Helper()
+
+	at := time.Unix(1700000000, 0)
+	txHash := strings.Repeat("a", 64)
+	createHeight := height
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"交易", func() error {
+			return transactions_dao.UpsertTransactions(ctx, []*dbtable.Transaction{{
+				TxHash: txHash, Fee: 0.0001, TimeStamp: at.Unix(), TxType: transaction.TxTypeTBC20, CreatedAt: at, UpdatedAt: at,
+			}})
+		}},
+		{"地址交易关系", func() error {
+			return address_transactions_dao.UpsertAddressTransactions(ctx, []*dbtable.AddressTransaction{{
+				Address: "1addr", TxHash: txHash, IsSender: true, BalanceChange: -1, CreatedAt: at, UpdatedAt: at,
+			}})
+		}},
+		{"地址活跃度", func() error {
+			return address_activity_dao.UpsertAddressActivity(ctx, []*dbtable.AddressActivity{{
+				Address: "1addr", FirstSeenHeight: height, FirstSeenTime: at.Unix(), FirstSeenTx: txHash,
+				LastActiveHeight: height, LastActiveTime: at.Unix(), LastActiveTx: txHash, UpdatedAt: at,
+			}})
+		}},
+		{"参与方", func() error {
+			return transaction_participants_dao.UpsertParticipants(ctx, []*dbtable.TransactionParticipant{{
+				TxHash: txHash, Address: "1addr", Role: dbtable.RoleSender, CreatedAt: at, UpdatedAt: at,
+			}})
+		}},
+		{"FT输出", func() error {
+			return ft_txo_dao.NewFtTxoDAO().UpsertFtTxos(ctx, []*dbtable.FtTxoSet{{
+				UtxoTxid: txHash, UtxoVout: 1, FtContractId: strings.Repeat("c", 64), FtBalance: 100, CreateHeight: &createHeight,
+			}})
+		}},
+		{"FT花费", func() error {
+			return ft_txo_dao.NewFtTxoDAO().MarkFtTxosSpent(ctx, []dbtable.FtTxoKey{{UtxoTxid: strings.Repeat("b", 64), UtxoVout: 0}}, height)
+		}},
+		{"NFT状态", func() error {
+			return nft_utxo_set_dao.NewNftUtxoSetDAO().UpsertNftUtxos(ctx, []*dbtable.NftUtxoSet{{
+				NftContractId: strings.Repeat("d", 64), NftUtxoId: txHash, NftHolderScriptHash: strings.Repeat("e", 64), NftUtxoHeight: &createHeight,
+			}})
+		}},
+	}
+	for _, step := range steps {
+		if err := step.fn(); err != nil {
+			t.Fatalf("写入%s失败: %v", step.name, err)
+		}
+	}
+}
+
+func useDryRun(t *testing.T) *dbtest.Recorder {
+	gdb, recorder := dbtest.DryRun(t)
+	previous := db.DB
+	db.DB = gdb
+	t.Cleanup(func() { db.DB = previous })
+	return recorder
+}
+
+func assertIdempotent(t *testing.T, statements []string) {
+	t.Helper()
+	if len(statements) == 0 {
+		t.Fatal("没有记录到写入语句")
+	}
+	for _, statement := range statements {
+		if !dbtest.IsIdempotentWrite(statement) {
+			t.Errorf("写入语句重复执行会产生重复记录: %s", statement)
+		}
+	}
+}
+
+func TestIndexerWritesReplay(t *testing.T) {
+	recorder := useDryRun(t)
+	ctx := context.Background()
+
+	applyBlock(t, ctx, 100)
+	first := recorder.Statements()
+	assertIdempotent(t, first)
+
+	// 崩溃后重放同一区块，生成的语句必须与首次写入完全一致
+	recorder.Reset()
+	applyBlock(t, ctx, 100)
+	replay := recorder.Statements()
+	if len(replay) != len(first) {
+		t.Fatalf("重放语句数 = %d, 期望 %d", len(replay), len(first))
+	}
+	for i := range first {
+		if replay[i] != first[i] {
+			t.Errorf("第%d条重放语句不一致:\n%s\n%s", i, first[i], replay[i])
+		}
+	}
+
+	// 写入新输出不能覆盖已有的花费状态，否则重放会把已花费的输出恢复为未花费
+	for _, statement := range first {
+		if strings.Contains(statement, "ft_txo_set") && strings.HasPrefix(statement, "INSERT") &&
+			strings.Contains(statement, "`if_spend`=VALUES") {
+			t.Errorf("代币输出写入覆盖了花费状态: %s", statement)
+		}
+	}
+}
+
+func TestIndexerWritesReorg(t *testing.T) {
+	recorder := useDryRun(t)
+	ctx := context.Background()
+
+	applyBlock(t, ctx, 100)
+	recorder.Reset()
+
+	// 回滚高度99之后的区块，再重新应用
+	if err := ft_txo_dao.NewFtTxoDAO().RollbackFtTxosAbove(ctx, 99); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if err := transactions_dao.DeleteTransactionsByTxHashes(ctx, []string{strings.Repeat("a", 64)}); err != nil {
+		t.Fatalf("删除回滚的交易失败: %v", err)
+	}
+	applyBlock(t, ctx, 100)
+	assertIdempotent(t, recorder.Statements())
+}
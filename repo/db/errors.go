@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"ginproject/entity/apperror"
+)
+
+var (
+	// ErrNotFound 记录不存在，具体的未找到错误都包装了该错误
+	ErrNotFound = apperror.ErrNotFound
+	// ErrTokenNotFound 代币不存在
+	ErrTokenNotFound = fmt.Errorf("代币%w", ErrNotFound)
+	// ErrNftNotFound NFT不存在
+	ErrNftNotFound = fmt.Errorf("NFT%w", ErrNotFound)
+	// ErrCollectionNotFound NFT集合不存在
+	ErrCollectionNotFound = fmt.Errorf("NFT集合%w", ErrNotFound)
+	// ErrTxNotFound 交易不存在
+	ErrTxNotFound = fmt.Errorf("交易%w", ErrNotFound)
+	// ErrUtxoNotFound UTXO不存在
+	ErrUtxoNotFound = fmt.Errorf("UTXO%w", ErrNotFound)
+	// ErrWalletNotFound 跟踪钱包不存在
+	ErrWalletNotFound = fmt.Errorf("钱包%w", ErrNotFound)
+	// ErrScriptHashNotFound 脚本哈希没有对应的地址映射
+	ErrScriptHashNotFound = fmt.Errorf("脚本哈希映射%w", ErrNotFound)
+	// ErrBroadcastFailureNotFound 广播失败记录不存在
+	ErrBroadcastFailureNotFound = fmt.Errorf("广播失败记录%w", ErrNotFound)
+	// ErrAddressActivityNotFound 地址活跃度索引中没有该地址
+	ErrAddressActivityNotFound = fmt.Errorf("地址活跃度%w", ErrNotFound)
+	// ErrWalletNotificationNotFound 跟踪钱包没有通知设置
+	ErrWalletNotificationNotFound = fmt.Errorf("钱包通知设置%w", ErrNotFound)
+)
+
+// WrapNotFound 将gorm.ErrRecordNotFound转换为指定的未找到错误，其他错误原样返回
+func WrapNotFound(err error, notFound error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
+
+// IsNotFound 判断错误是否为记录不存在
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+}
@@ -22,57 +22,57 @@ func NewFtBalanceDAO() *FtBalanceDAO {
 }
 
 // InsertFtBalance 插入一条代币余额记录
-func (dao *FtBalanceDAO) InsertFtBalance(balance *dbtable.FtBalance) error {
-	return dao.db.Create(balance).Error
+func (dao *FtBalanceDAO) InsertFtBalance(ctx context.Context, balance *dbtable.FtBalance) error {
+	return dao.db.WithContext(ctx).Create(balance).Error
 }
 
 // GetFtBalance 根据持有者脚本和合约ID获取代币余额
-func (dao *FtBalanceDAO) GetFtBalance(holderScript string, contractId string) (*dbtable.FtBalance, error) {
+func (dao *FtBalanceDAO) GetFtBalance(ctx context.Context, holderScript string, contractId string) (*dbtable.FtBalance, error) {
 	var balance dbtable.FtBalance
-	err := dao.db.Where("ft_holder_combine_script = ? AND ft_contract_id = ?", holderScript, contractId).First(&balance).Error
+	err := dao.db.WithContext(ctx).Where("ft_holder_combine_script = ? AND ft_contract_id = ?", holderScript, contractId).First(&balance).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrNotFound)
 	}
 	return &balance, nil
 }
 
 // UpdateFtBalance 更新代币余额信息
-func (dao *FtBalanceDAO) UpdateFtBalance(balance *dbtable.FtBalance) error {
-	return dao.db.Save(balance).Error
+func (dao *FtBalanceDAO) UpdateFtBalance(ctx context.Context, balance *dbtable.FtBalance) error {
+	return dao.db.WithContext(ctx).Save(balance).Error
 }
 
 // DeleteFtBalance 删除代币余额
-func (dao *FtBalanceDAO) DeleteFtBalance(holderScript string, contractId string) error {
-	return dao.db.Where("ft_holder_combine_script = ? AND ft_contract_id = ?", holderScript, contractId).Delete(&dbtable.FtBalance{}).Error
+func (dao *FtBalanceDAO) DeleteFtBalance(ctx context.Context, holderScript string, contractId string) error {
+	return dao.db.WithContext(ctx).Where("ft_holder_combine_script = ? AND ft_contract_id = ?", holderScript, contractId).Delete(&dbtable.FtBalance{}).Error
 }
 
 // GetFtBalancesByHolder 获取持有者的所有代币余额
-func (dao *FtBalanceDAO) GetFtBalancesByHolder(holderScript string) ([]*dbtable.FtBalance, error) {
+func (dao *FtBalanceDAO) GetFtBalancesByHolder(ctx context.Context, holderScript string) ([]*dbtable.FtBalance, error) {
 	var balances []*dbtable.FtBalance
-	err := dao.db.Where("ft_holder_combine_script = ?", holderScript).Find(&balances).Error
+	err := dao.db.WithContext(ctx).Where("ft_holder_combine_script = ?", holderScript).Find(&balances).Error
 	return balances, err
 }
 
 // GetFtBalancesByContractId 获取某代币的所有持有者余额
-func (dao *FtBalanceDAO) GetFtBalancesByContractId(contractId string) ([]*dbtable.FtBalance, error) {
+func (dao *FtBalanceDAO) GetFtBalancesByContractId(ctx context.Context, contractId string) ([]*dbtable.FtBalance, error) {
 	var balances []*dbtable.FtBalance
-	err := dao.db.Where("ft_contract_id = ?", contractId).Find(&balances).Error
+	err := dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).Find(&balances).Error
 	return balances, err
 }
 
 // GetFtBalancesWithPagination 分页获取代币余额列表
-func (dao *FtBalanceDAO) GetFtBalancesWithPagination(page, pageSize int) ([]*dbtable.FtBalance, int64, error) {
+func (dao *FtBalanceDAO) GetFtBalancesWithPagination(ctx context.Context, page, pageSize int) ([]*dbtable.FtBalance, int64, error) {
 	var balances []*dbtable.FtBalance
 	var total int64
 
 	// 获取总记录数
-	if err := dao.db.Model(&dbtable.FtBalance{}).Count(&total).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Model(&dbtable.FtBalance{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取分页数据
 	offset := (page - 1) * pageSize
-	if err := dao.db.Offset(offset).Limit(pageSize).Find(&balances).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&balances).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -80,23 +80,33 @@ func (dao *FtBalanceDAO) GetFtBalancesWithPagination(page, pageSize int) ([]*dbt
 }
 
 // GetSumBalanceByContractId 获取某代币的总余额
-func (dao *FtBalanceDAO) GetSumBalanceByContractId(contractId string) (uint64, error) {
+func (dao *FtBalanceDAO) GetSumBalanceByContractId(ctx context.Context, contractId string) (uint64, error) {
 	type Result struct {
 		TotalBalance uint64
 	}
 	var result Result
-	err := dao.db.Model(&dbtable.FtBalance{}).Select("SUM(ft_balance) as total_balance").
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtBalance{}).Select("SUM(ft_balance) as total_balance").
 		Where("ft_contract_id = ?", contractId).Scan(&result).Error
 	return result.TotalBalance, err
 }
 
 // GetHoldersCountByContractId 获取某代币的持有者数量
-func (dao *FtBalanceDAO) GetHoldersCountByContractId(contractId string) (int64, error) {
+func (dao *FtBalanceDAO) GetHoldersCountByContractId(ctx context.Context, contractId string) (int64, error) {
 	var count int64
-	err := dao.db.Model(&dbtable.FtBalance{}).Where("ft_contract_id = ?", contractId).Count(&count).Error
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtBalance{}).Where("ft_contract_id = ?", contractId).Count(&count).Error
 	return count, err
 }
 
+// GetTotalBalanceByContractId 获取代币全部持有者的余额之和，即流通供应量
+func (dao *FtBalanceDAO) GetTotalBalanceByContractId(ctx context.Context, contractId string) (uint64, error) {
+	var total uint64
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtBalance{}).
+		Select("COALESCE(SUM(ft_balance), 0)").
+		Where("ft_contract_id = ?", contractId).
+		Scan(&total).Error
+	return total, err
+}
+
 // GetFtBalanceRankByContractId 获取代币持有者排名列表
 func (dao *FtBalanceDAO) GetFtBalanceRankByContractId(ctx context.Context, contractId string, page, size int) ([]*dbtable.FtBalance, error) {
 	var balances []*dbtable.FtBalance
@@ -104,9 +114,10 @@ func (dao *FtBalanceDAO) GetFtBalanceRankByContractId(ctx context.Context, contr
 	// 计算偏移量
 	offset := page * size
 
-	// 查询持有者排名，按持有余额降序排序
-	err := dao.db.Where("ft_contract_id = ?", contractId).
+	// 查询持有者排名，按持有余额降序排序，余额相同时按组合脚本排序，保证名次稳定
+	err := dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).
 		Order("ft_balance DESC").
+		Order("ft_holder_combine_script").
 		Offset(offset).
 		Limit(size).
 		Find(&balances).Error
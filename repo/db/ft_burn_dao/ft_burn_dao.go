@@ -0,0 +1,66 @@
+package ft_burn_dao
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// 批量写入的每批记录数
+const upsertBatchSize = 500
+
+// UpsertFtBurns 写入销毁记录，重复投递时更新区块高度和时间，重组后重新发布的区块以最新的为准
+func UpsertFtBurns(ctx context.Context, burns []*dbtable.FtBurn) error {
+	if len(burns) == 0 {
+		return nil
+	}
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "txid"}, {Name: "vout"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "block_time"}),
+	}).CreateInBatches(burns, upsertBatchSize)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "写入代币销毁记录失败", "数量:", len(burns), "错误:", result.Error)
+		return fmt.Errorf("写入代币销毁记录失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetFtBurnsByContract 分页获取代币的销毁记录，按区块高度降序
+func GetFtBurnsByContract(ctx context.Context, contractId string, offset, limit int) ([]*dbtable.FtBurn, error) {
+	var burns []*dbtable.FtBurn
+	result := db.GetDB().WithContext(ctx).
+		Where("ft_contract_id = ?", contractId).
+		Order("height DESC, txid, vout").
+		Offset(offset).
+		Limit(limit).
+		Find(&burns)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询代币销毁记录失败", "contractId:", contractId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询代币销毁记录失败: %w", result.Error)
+	}
+	return burns, nil
+}
+
+// GetFtBurnTotals 统计代币的销毁次数和累计销毁数量
+func GetFtBurnTotals(ctx context.Context, contractId string) (*dbtable.FtBurnTotals, error) {
+	var totals dbtable.FtBurnTotals
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.FtBurn{}).
+		Select("COUNT(*) AS burn_count, COALESCE(SUM(amount), 0) AS total_burned").
+		Where("ft_contract_id = ?", contractId).
+		Scan(&totals)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "统计代币销毁数量失败", "contractId:", contractId, "错误:", result.Error)
+		return nil, fmt.Errorf("统计代币销毁数量失败: %w", result.Error)
+	}
+	return &totals, nil
+}
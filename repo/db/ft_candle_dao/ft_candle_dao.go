@@ -0,0 +1,83 @@
+package ft_candle_dao
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// 批量写入的每批记录数
+const upsertBatchSize = 500
+
+// UpsertPoolSwaps 写入池兑换记录，重复投递时更新区块高度、时间和位置，重组后重新发布的区块以最新的为准
+func UpsertPoolSwaps(ctx context.Context, swaps []*dbtable.FtPoolSwap) error {
+	if len(swaps) == 0 {
+		return nil
+	}
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "txid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "block_time", "tx_index"}),
+	}).CreateInBatches(swaps, upsertBatchSize)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "写入池兑换记录失败", "数量:", len(swaps), "错误:", result.Error)
+		return fmt.Errorf("写入池兑换记录失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetPoolSwapsBetween 获取代币区块时间在[from, to)内的兑换记录，按成交顺序排列
+func GetPoolSwapsBetween(ctx context.Context, contractId string, from, to int64) ([]*dbtable.FtPoolSwap, error) {
+	var swaps []*dbtable.FtPoolSwap
+	result := db.GetDB().WithContext(ctx).
+		Where("ft_contract_id = ? AND block_time >= ? AND block_time < ?", contractId, from, to).
+		Order("height, tx_index, txid").
+		Find(&swaps)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询池兑换记录失败", "contractId:", contractId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询池兑换记录失败: %w", result.Error)
+	}
+	return swaps, nil
+}
+
+// UpsertCandles 写入重建后的K线，已存在的时间段整体覆盖
+func UpsertCandles(ctx context.Context, candles []*dbtable.FtPriceCandle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ft_contract_id"}, {Name: "interval_name"}, {Name: "bucket_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"open", "high", "low", "close", "volume_tbc", "volume_ft", "trades",
+		}),
+	}).CreateInBatches(candles, upsertBatchSize)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "写入代币K线失败", "数量:", len(candles), "错误:", result.Error)
+		return fmt.Errorf("写入代币K线失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetCandles 获取代币指定周期起始时间在[from, to)内的K线，按时间升序
+func GetCandles(ctx context.Context, contractId, interval string, from, to int64) ([]*dbtable.FtPriceCandle, error) {
+	var candles []*dbtable.FtPriceCandle
+	result := db.GetDB().WithContext(ctx).
+		Where("ft_contract_id = ? AND interval_name = ? AND bucket_start >= ? AND bucket_start < ?", contractId, interval, from, to).
+		Order("bucket_start").
+		Find(&candles)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询代币K线失败", "contractId:", contractId, "interval:", interval, "错误:", result.Error)
+		return nil, fmt.Errorf("查询代币K线失败: %w", result.Error)
+	}
+	return candles, nil
+}
@@ -0,0 +1,91 @@
+package ft_holder_snapshot_dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm"
+)
+
+// 批量写入的每批记录数
+const insertBatchSize = 500
+
+// ReplaceHolderRankSnapshot 在一个事务中用新的快照替换代币的全部快照记录
+func ReplaceHolderRankSnapshot(ctx context.Context, contractId string, snapshots []*dbtable.FtHolderRankSnapshot) error {
+	err := db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ft_contract_id = ?", contractId).Delete(&dbtable.FtHolderRankSnapshot{}).Error; err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(snapshots, insertBatchSize).Error
+	})
+	if err != nil {
+		log.ErrorWithContext(ctx, "写入持有者排名快照失败", "contractId:", contractId, "数量:", len(snapshots), "错误:", err)
+		return fmt.Errorf("写入持有者排名快照失败: %w", err)
+	}
+	return nil
+}
+
+// GetHolderRankSnapshots 获取代币中指定持有者的快照记录，不在快照中的持有者不出现在结果中
+func GetHolderRankSnapshots(ctx context.Context, contractId string, combineScripts []string) ([]*dbtable.FtHolderRankSnapshot, error) {
+	var snapshots []*dbtable.FtHolderRankSnapshot
+	if len(combineScripts) == 0 {
+		return snapshots, nil
+	}
+	result := db.GetDB().WithContext(ctx).
+		Where("ft_contract_id = ? AND ft_holder_combine_script IN ?", contractId, combineScripts).
+		Find(&snapshots)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询持有者排名快照失败", "contractId:", contractId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询持有者排名快照失败: %w", result.Error)
+	}
+	return snapshots, nil
+}
+
+// GetHolderSnapshotTime 获取代币最近一次快照的时间，没有快照时返回零值
+func GetHolderSnapshotTime(ctx context.Context, contractId string) (time.Time, error) {
+	var row struct {
+		SnapshotAt *time.Time `gorm:"column:snapshot_at"`
+	}
+	result := db.GetDB().WithContext(ctx).Model(&dbtable.FtHolderRankSnapshot{}).
+		Select("MAX(snapshot_at) AS snapshot_at").
+		Where("ft_contract_id = ?", contractId).
+		Scan(&row)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询持有者排名快照时间失败", "contractId:", contractId, "错误:", result.Error)
+		return time.Time{}, fmt.Errorf("查询持有者排名快照时间失败: %w", result.Error)
+	}
+	if row.SnapshotAt == nil {
+		return time.Time{}, nil
+	}
+	return *row.SnapshotAt, nil
+}
+
+// GetHolderSnapshotTimes 获取每个已有快照的代币最近一次快照的时间
+func GetHolderSnapshotTimes(ctx context.Context) (map[string]time.Time, error) {
+	var rows []struct {
+		FtContractId string    `gorm:"column:ft_contract_id"`
+		SnapshotAt   time.Time `gorm:"column:snapshot_at"`
+	}
+	result := db.GetDB().WithContext(ctx).Model(&dbtable.FtHolderRankSnapshot{}).
+		Select("ft_contract_id, MAX(snapshot_at) AS snapshot_at").
+		Group("ft_contract_id").
+		Scan(&rows)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询持有者排名快照时间失败", "错误:", result.Error)
+		return nil, fmt.Errorf("查询持有者排名快照时间失败: %w", result.Error)
+	}
+
+	times := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		times[row.FtContractId] = row.SnapshotAt
+	}
+	return times, nil
+}
@@ -2,12 +2,14 @@ package ft_tokens_dao
 
 import (
 	"context"
+	"strings"
 
 	"ginproject/entity/dbtable"
 	"ginproject/middleware/log"
 	"ginproject/repo/db"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FtTokensDAO 用于管理ft_tokens表操作的数据访问对象
@@ -23,88 +25,110 @@ func NewFtTokensDAO() *FtTokensDAO {
 }
 
 // InsertFtToken 插入一条代币记录
-func (dao *FtTokensDAO) InsertFtToken(token *dbtable.FtTokens) error {
-	return dao.db.Create(token).Error
+func (dao *FtTokensDAO) InsertFtToken(ctx context.Context, token *dbtable.FtTokens) error {
+	return dao.db.WithContext(ctx).Create(token).Error
 }
 
 // GetFtTokenById 根据合约ID获取代币
-func (dao *FtTokensDAO) GetFtTokenById(contractId string) (*dbtable.FtTokens, error) {
+func (dao *FtTokensDAO) GetFtTokenById(ctx context.Context, contractId string) (*dbtable.FtTokens, error) {
 	var token dbtable.FtTokens
-	err := dao.db.Where("ft_contract_id = ?", contractId).First(&token).Error
+	err := dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).First(&token).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrTokenNotFound)
 	}
 	return &token, nil
 }
 
 // GetFtTokenByOriginUtxo 根据源UTXO获取代币
-func (dao *FtTokensDAO) GetFtTokenByOriginUtxo(originUtxo string) (*dbtable.FtTokens, error) {
+func (dao *FtTokensDAO) GetFtTokenByOriginUtxo(ctx context.Context, originUtxo string) (*dbtable.FtTokens, error) {
 	var token dbtable.FtTokens
-	err := dao.db.Where("ft_origin_utxo = ?", originUtxo).First(&token).Error
+	err := dao.db.WithContext(ctx).Where("ft_origin_utxo = ?", originUtxo).First(&token).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrTokenNotFound)
 	}
 	return &token, nil
 }
 
 // UpdateFtToken 更新代币信息
-func (dao *FtTokensDAO) UpdateFtToken(token *dbtable.FtTokens) error {
-	return dao.db.Save(token).Error
+func (dao *FtTokensDAO) UpdateFtToken(ctx context.Context, token *dbtable.FtTokens) error {
+	return dao.db.WithContext(ctx).Save(token).Error
 }
 
 // DeleteFtToken 删除代币
-func (dao *FtTokensDAO) DeleteFtToken(contractId string) error {
-	return dao.db.Where("ft_contract_id = ?", contractId).Delete(&dbtable.FtTokens{}).Error
+func (dao *FtTokensDAO) DeleteFtToken(ctx context.Context, contractId string) error {
+	return dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).Delete(&dbtable.FtTokens{}).Error
 }
 
 // GetFtTokensByName 根据名称查询代币列表
-func (dao *FtTokensDAO) GetFtTokensByName(name string) ([]*dbtable.FtTokens, error) {
+func (dao *FtTokensDAO) GetFtTokensByName(ctx context.Context, name string) ([]*dbtable.FtTokens, error) {
 	var tokens []*dbtable.FtTokens
-	err := dao.db.Where("ft_name LIKE ?", "%"+name+"%").Find(&tokens).Error
+	err := dao.db.WithContext(ctx).Where("ft_name LIKE ?", "%"+name+"%").Find(&tokens).Error
 	return tokens, err
 }
 
 // GetFtTokensBySymbol 根据符号查询代币列表
-func (dao *FtTokensDAO) GetFtTokensBySymbol(symbol string) ([]*dbtable.FtTokens, error) {
+func (dao *FtTokensDAO) GetFtTokensBySymbol(ctx context.Context, symbol string) ([]*dbtable.FtTokens, error) {
 	var tokens []*dbtable.FtTokens
-	err := dao.db.Where("ft_symbol LIKE ?", "%"+symbol+"%").Find(&tokens).Error
+	err := dao.db.WithContext(ctx).Where("ft_symbol LIKE ?", "%"+symbol+"%").Find(&tokens).Error
 	return tokens, err
 }
 
 // GetFtTokensByCreator 根据创建者查询代币列表
-func (dao *FtTokensDAO) GetFtTokensByCreator(creatorCombineScript string) ([]*dbtable.FtTokens, error) {
+func (dao *FtTokensDAO) GetFtTokensByCreator(ctx context.Context, creatorCombineScript string) ([]*dbtable.FtTokens, error) {
 	var tokens []*dbtable.FtTokens
-	err := dao.db.Where("ft_creator_combine_script = ?", creatorCombineScript).Find(&tokens).Error
+	err := dao.db.WithContext(ctx).Where("ft_creator_combine_script = ?", creatorCombineScript).Find(&tokens).Error
 	return tokens, err
 }
 
 // GetFtTokensWithPagination 分页获取代币列表
-func (dao *FtTokensDAO) GetFtTokensWithPagination(page, pageSize int) ([]*dbtable.FtTokens, int64, error) {
+func (dao *FtTokensDAO) GetFtTokensWithPagination(ctx context.Context, page, pageSize int) ([]*dbtable.FtTokens, int64, error) {
 	var tokens []*dbtable.FtTokens
 	var total int64
 
 	// 获取总记录数
-	if err := dao.db.Model(&dbtable.FtTokens{}).Count(&total).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Model(&dbtable.FtTokens{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取分页数据
 	offset := (page - 1) * pageSize
-	if err := dao.db.Offset(offset).Limit(pageSize).Find(&tokens).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&tokens).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return tokens, total, nil
 }
 
-// GetFtDecimalByContractId 根据合约ID获取代币小数位数
-func (dao *FtTokensDAO) GetFtDecimalByContractId(ctx context.Context, contractId string) (uint8, error) {
+// GetFtUnitsByContractId 根据合约ID获取代币的精度、名称和符号，其余字段为空
+func (dao *FtTokensDAO) GetFtUnitsByContractId(ctx context.Context, contractId string) (*dbtable.FtTokens, error) {
 	var token dbtable.FtTokens
-	err := dao.db.Where("ft_contract_id = ?", contractId).Select("ft_decimal").First(&token).Error
+	err := dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).
+		Select("ft_contract_id", "ft_decimal", "ft_name", "ft_symbol").First(&token).Error
+	if err != nil {
+		return nil, db.WrapNotFound(err, db.ErrTokenNotFound)
+	}
+	return &token, nil
+}
+
+// GetFtUnitsByContractIds 批量获取代币的精度、名称和符号，未找到的合约不会出现在结果中
+func (dao *FtTokensDAO) GetFtUnitsByContractIds(ctx context.Context, contractIds []string) (map[string]*dbtable.FtTokens, error) {
+	result := make(map[string]*dbtable.FtTokens, len(contractIds))
+	if len(contractIds) == 0 {
+		return result, nil
+	}
+
+	var tokens []*dbtable.FtTokens
+	err := dao.db.WithContext(ctx).Where("ft_contract_id IN ?", contractIds).
+		Select("ft_contract_id", "ft_decimal", "ft_name", "ft_symbol").Find(&tokens).Error
 	if err != nil {
-		return 0, err
+		log.ErrorWithContextf(ctx, "批量查询代币精度和符号失败: %v", err)
+		return nil, err
 	}
-	return token.FtDecimal, nil
+
+	for _, token := range tokens {
+		result[token.FtContractId] = token
+	}
+	return result, nil
 }
 
 // GetFtCodeScript 根据合约ID获取代币代码脚本
@@ -112,11 +136,11 @@ func (dao *FtTokensDAO) GetFtCodeScript(ctx context.Context, contractId string)
 	var token dbtable.FtTokens
 
 	// 查询代币代码脚本
-	err := dao.db.Where("ft_contract_id = ?", contractId).Select("ft_code_script").First(&token).Error
+	err := dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).Select("ft_code_script").First(&token).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.WarnWithContextf(ctx, "未找到合约ID对应的代币信息: %s", contractId)
-			return "", nil
+			return "", db.ErrTokenNotFound
 		}
 		log.ErrorWithContextf(ctx, "查询代币代码脚本失败: %v", err)
 		return "", err
@@ -131,11 +155,11 @@ func (dao *FtTokensDAO) GetFtCodeScriptAndDecimal(ctx context.Context, contractI
 	var token dbtable.FtTokens
 
 	// 查询代币代码脚本和精度
-	err := dao.db.Where("ft_contract_id = ?", contractId).Select("ft_code_script, ft_decimal").First(&token).Error
+	err := dao.db.WithContext(ctx).Where("ft_contract_id = ?", contractId).Select("ft_code_script, ft_decimal").First(&token).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.WarnWithContextf(ctx, "未找到合约ID对应的代币信息: %s", contractId)
-			return "", 0, nil
+			return "", 0, db.ErrTokenNotFound
 		}
 		log.ErrorWithContextf(ctx, "查询代币代码脚本和精度失败: %v", err)
 		return "", 0, err
@@ -145,20 +169,72 @@ func (dao *FtTokensDAO) GetFtCodeScriptAndDecimal(ctx context.Context, contractI
 	return token.FtCodeScript, token.FtDecimal, nil
 }
 
+// GetFtContractIds 获取全部代币的合约ID
+func (dao *FtTokensDAO) GetFtContractIds(ctx context.Context) ([]string, error) {
+	var contractIds []string
+	if err := dao.db.WithContext(ctx).Model(&dbtable.FtTokens{}).Pluck("ft_contract_id", &contractIds).Error; err != nil {
+		log.ErrorWithContextf(ctx, "获取代币合约ID列表失败: %v", err)
+		return nil, err
+	}
+	return contractIds, nil
+}
+
+// SearchTokens 按名称或符号不区分大小写搜索代币并分页
+// 结果按相关度排序：符号完全匹配、名称完全匹配、符号前缀匹配、名称前缀匹配、其余包含关键字的代币，同一档内按持有人数量排序
+func (dao *FtTokensDAO) SearchTokens(ctx context.Context, keyword string, page, size int) ([]*dbtable.FtTokens, int64, error) {
+	var tokens []*dbtable.FtTokens
+	var total int64
+
+	keyword = strings.ToLower(keyword)
+	escaped := db.EscapeLike(keyword)
+	contains := "%" + escaped + "%"
+	prefix := escaped + "%"
+	query := func() *gorm.DB {
+		return dao.db.WithContext(ctx).Model(&dbtable.FtTokens{}).
+			Where("LOWER(ft_name) LIKE ? OR LOWER(ft_symbol) LIKE ?", contains, contains)
+	}
+
+	// 获取匹配的总记录数
+	if err := query().Count(&total).Error; err != nil {
+		log.ErrorWithContextf(ctx, "搜索代币总数失败: %v", err)
+		return nil, 0, err
+	}
+
+	// 获取分页数据，按相关度排序
+	offset := page * size
+	if err := query().
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: `CASE WHEN LOWER(ft_symbol) = ? THEN 0 WHEN LOWER(ft_name) = ? THEN 1
+				WHEN LOWER(ft_symbol) LIKE ? THEN 2 WHEN LOWER(ft_name) LIKE ? THEN 3 ELSE 4 END,
+				ft_holders_count DESC, ft_contract_id`,
+			Vars:               []interface{}{keyword, keyword, prefix, prefix},
+			WithoutParentheses: true,
+		}}).
+		Offset(offset).
+		Limit(size).
+		Find(&tokens).Error; err != nil {
+		log.ErrorWithContextf(ctx, "搜索代币失败: %v", err)
+		return nil, 0, err
+	}
+
+	log.InfoWithContextf(ctx, "搜索代币完成，关键字: %s, 总数: %d, 当前页: %d, 每页大小: %d", keyword, total, page, size)
+	return tokens, total, nil
+}
+
 // GetTokensPageByCreateTime 根据创建时间排序获取代币分页列表
 func (dao *FtTokensDAO) GetTokensPageByCreateTime(ctx context.Context, page, size int) ([]*dbtable.FtTokens, int64, error) {
 	var tokens []*dbtable.FtTokens
 	var total int64
 
 	// 获取总记录数
-	if err := dao.db.Model(&dbtable.FtTokens{}).Count(&total).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Model(&dbtable.FtTokens{}).Count(&total).Error; err != nil {
 		log.ErrorWithContextf(ctx, "获取代币总数失败: %v", err)
 		return nil, 0, err
 	}
 
 	// 获取分页数据，按创建时间排序
 	offset := page * size
-	if err := dao.db.Order("ft_create_timestamp DESC").
+	if err := dao.db.WithContext(ctx).Order("ft_create_timestamp DESC").
 		Offset(offset).
 		Limit(size).
 		Find(&tokens).Error; err != nil {
@@ -177,14 +253,14 @@ func (dao *FtTokensDAO) GetTokensPageByHoldersCount(ctx context.Context, page, s
 	var total int64
 
 	// 获取总记录数
-	if err := dao.db.Model(&dbtable.FtTokens{}).Count(&total).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Model(&dbtable.FtTokens{}).Count(&total).Error; err != nil {
 		log.ErrorWithContextf(ctx, "获取代币总数失败: %v", err)
 		return nil, 0, err
 	}
 
 	// 获取分页数据，按持有人数量排序
 	offset := page * size
-	if err := dao.db.Order("ft_holders_count DESC").
+	if err := dao.db.WithContext(ctx).Order("ft_holders_count DESC").
 		Offset(offset).
 		Limit(size).
 		Find(&tokens).Error; err != nil {
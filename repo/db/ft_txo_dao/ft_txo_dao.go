@@ -2,6 +2,10 @@ package ft_txo_dao
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
 
 	"ginproject/entity/dbtable"
 	"ginproject/entity/ft"
@@ -9,6 +13,7 @@ import (
 	"ginproject/repo/db"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FtTxoDAO 用于管理ft_txo_set表操作的数据访问对象
@@ -24,75 +29,166 @@ func NewFtTxoDAO() *FtTxoDAO {
 }
 
 // InsertFtTxo 插入一条代币交易输出记录
-func (dao *FtTxoDAO) InsertFtTxo(txo *dbtable.FtTxoSet) error {
-	return dao.db.Create(txo).Error
+func (dao *FtTxoDAO) InsertFtTxo(ctx context.Context, txo *dbtable.FtTxoSet) error {
+	return dao.db.WithContext(ctx).Create(txo).Error
 }
 
 // GetFtTxoByTxidVout 根据交易ID和输出索引获取代币交易输出
-func (dao *FtTxoDAO) GetFtTxoByTxidVout(txid string, vout int) (*dbtable.FtTxoSet, error) {
+func (dao *FtTxoDAO) GetFtTxoByTxidVout(ctx context.Context, txid string, vout int) (*dbtable.FtTxoSet, error) {
 	var txo dbtable.FtTxoSet
-	err := dao.db.Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).First(&txo).Error
+	err := dao.db.WithContext(ctx).Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).First(&txo).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrUtxoNotFound)
 	}
 	return &txo, nil
 }
 
 // UpdateFtTxo 更新代币交易输出信息
-func (dao *FtTxoDAO) UpdateFtTxo(txo *dbtable.FtTxoSet) error {
-	return dao.db.Save(txo).Error
+func (dao *FtTxoDAO) UpdateFtTxo(ctx context.Context, txo *dbtable.FtTxoSet) error {
+	return dao.db.WithContext(ctx).Save(txo).Error
 }
 
 // MarkFtTxoAsSpent 标记代币交易输出为已花费
-func (dao *FtTxoDAO) MarkFtTxoAsSpent(txid string, vout int) error {
-	return dao.db.Model(&dbtable.FtTxoSet{}).Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).
+func (dao *FtTxoDAO) MarkFtTxoAsSpent(ctx context.Context, txid string, vout int) error {
+	return dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).
 		Update("if_spend", true).Error
 }
 
 // DeleteFtTxo 删除代币交易输出
-func (dao *FtTxoDAO) DeleteFtTxo(txid string, vout int) error {
-	return dao.db.Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).Delete(&dbtable.FtTxoSet{}).Error
+func (dao *FtTxoDAO) DeleteFtTxo(ctx context.Context, txid string, vout int) error {
+	return dao.db.WithContext(ctx).Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).Delete(&dbtable.FtTxoSet{}).Error
+}
+
+// UpsertFtTxos 按(utxo_txid, utxo_vout)批量写入新创建的代币交易输出，重复写入时覆盖创建信息，不改变花费状态
+func (dao *FtTxoDAO) UpsertFtTxos(ctx context.Context, txos []*dbtable.FtTxoSet) error {
+	if len(txos) == 0 {
+		return nil
+	}
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "utxo_txid"}, {Name: "utxo_vout"}},
+		DoUpdates: clause.AssignmentColumns([]string{"ft_holder_combine_script", "ft_contract_id", "utxo_balance",
+			"ft_balance", "create_height"}),
+	}).CreateInBatches(txos, 100).Error
+}
+
+// MarkFtTxosSpent 将代币交易输出标记为在spendHeight高度被花费，重复执行结果不变
+func (dao *FtTxoDAO) MarkFtTxosSpent(ctx context.Context, outpoints []dbtable.FtTxoKey, spendHeight int64) error {
+	if len(outpoints) == 0 {
+		return nil
+	}
+	keys := make([][]interface{}, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		keys = append(keys, []interface{}{outpoint.UtxoTxid, outpoint.UtxoVout})
+	}
+	return dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).Where("(utxo_txid, utxo_vout) IN ?", keys).
+		Updates(map[string]interface{}{"if_spend": true, "spend_height": spendHeight}).Error
+}
+
+// RollbackFtTxosAbove 回滚高度大于height的区块对代币交易输出的修改：恢复被这些区块花费的输出，删除这些区块创建的输出
+// 两步各自幂等，中途失败时重新执行即可，不需要事务
+func (dao *FtTxoDAO) RollbackFtTxosAbove(ctx context.Context, height int64) error {
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).Where("spend_height > ?", height).
+		Updates(map[string]interface{}{"if_spend": false, "spend_height": nil}).Error
+	if err != nil {
+		return fmt.Errorf("恢复回滚区块花费的代币输出失败: %w", err)
+	}
+	err = dao.db.WithContext(ctx).Where("create_height > ?", height).Delete(&dbtable.FtTxoSet{}).Error
+	if err != nil {
+		return fmt.Errorf("删除回滚区块创建的代币输出失败: %w", err)
+	}
+	return nil
+}
+
+// GetFtTxosByTxids 获取指定交易创建的全部代币交易输出
+func (dao *FtTxoDAO) GetFtTxosByTxids(ctx context.Context, txids []string) ([]*dbtable.FtTxoSet, error) {
+	var txos []*dbtable.FtTxoSet
+	if len(txids) == 0 {
+		return txos, nil
+	}
+	err := dao.db.WithContext(ctx).Where("utxo_txid IN ?", txids).Find(&txos).Error
+	return txos, err
 }
 
 // GetFtTxosByHolderAndContract 根据持有者脚本和合约ID获取代币交易输出列表
-func (dao *FtTxoDAO) GetFtTxosByHolderAndContract(holderScript string, contractId string) ([]*dbtable.FtTxoSet, error) {
+func (dao *FtTxoDAO) GetFtTxosByHolderAndContract(ctx context.Context, holderScript string, contractId string) ([]*dbtable.FtTxoSet, error) {
 	var txos []*dbtable.FtTxoSet
-	err := dao.db.Where("ft_holder_combine_script = ? AND ft_contract_id = ?", holderScript, contractId).
+	err := dao.db.WithContext(ctx).Where("ft_holder_combine_script = ? AND ft_contract_id = ?", holderScript, contractId).
 		Find(&txos).Error
 	return txos, err
 }
 
 // GetUnspentFtTxosByHolder 获取指定持有者的未花费代币交易输出
-func (dao *FtTxoDAO) GetUnspentFtTxosByHolder(holderScript string) ([]*dbtable.FtTxoSet, error) {
+func (dao *FtTxoDAO) GetUnspentFtTxosByHolder(ctx context.Context, holderScript string) ([]*dbtable.FtTxoSet, error) {
 	var txos []*dbtable.FtTxoSet
-	err := dao.db.Where("ft_holder_combine_script = ? AND if_spend = ?", holderScript, false).
+	err := dao.db.WithContext(ctx).Where("ft_holder_combine_script = ? AND if_spend = ?", holderScript, false).
 		Find(&txos).Error
 	return txos, err
 }
 
 // GetUnspentFtTxosByHolderAndContract 获取指定持有者和合约的未花费代币交易输出
-func (dao *FtTxoDAO) GetUnspentFtTxosByHolderAndContract(holderScript string, contractId string) ([]*dbtable.FtTxoSet, error) {
+// maxHeight不小于0时只返回创建高度不超过该高度的输出，尚未回填创建高度的输出不返回
+func (dao *FtTxoDAO) GetUnspentFtTxosByHolderAndContract(ctx context.Context, holderScript string, contractId string, maxHeight int64) ([]*dbtable.FtTxoSet, error) {
 	var txos []*dbtable.FtTxoSet
-	err := dao.db.Where("ft_holder_combine_script = ? AND ft_contract_id = ? AND if_spend = ?",
-		holderScript, contractId, false).Find(&txos).Error
+	tx := dao.db.WithContext(ctx).Where("ft_holder_combine_script = ? AND ft_contract_id = ? AND if_spend = ?",
+		holderScript, contractId, false)
+	if maxHeight >= 0 {
+		tx = tx.Where("create_height >= 1 AND create_height <= ?", maxHeight)
+	}
+	err := tx.Find(&txos).Error
 	return txos, err
 }
 
+// SampleUnspentFtHoldings 随机抽取最多n个不同的(持有者组合脚本, 合约ID)组合
+// 交易ID均匀分布，按随机的交易ID定位下一条未花费输出，避免对整表排序
+func (dao *FtTxoDAO) SampleUnspentFtHoldings(ctx context.Context, n int) ([]*dbtable.FtTxoSet, error) {
+	holdings := make([]*dbtable.FtTxoSet, 0, n)
+	seen := make(map[string]bool, n)
+	buf := make([]byte, 32)
+	// 抽中重复组合时重试，最多尝试3n次
+	for attempt := 0; attempt < 3*n && len(holdings) < n; attempt++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("生成随机交易ID失败: %w", err)
+		}
+		start := hex.EncodeToString(buf)
+
+		var picked []*dbtable.FtTxoSet
+		err := dao.db.WithContext(ctx).Where("utxo_txid >= ? AND if_spend = ?", start, false).
+			Order("utxo_txid").Limit(1).Find(&picked).Error
+		if err == nil && len(picked) == 0 {
+			// 随机位置之后没有未花费输出时从头取第一条
+			err = dao.db.WithContext(ctx).Where("if_spend = ?", false).Order("utxo_txid").Limit(1).Find(&picked).Error
+		}
+		if err != nil {
+			return nil, fmt.Errorf("抽样代币输出失败: %w", err)
+		}
+		if len(picked) == 0 {
+			break
+		}
+		key := picked[0].FtHolderCombineScript + ":" + picked[0].FtContractId
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		holdings = append(holdings, picked[0])
+	}
+	return holdings, nil
+}
+
 // GetTotalBalanceByHolderAndContract 获取指定持有者和合约的未花费代币总余额
-func (dao *FtTxoDAO) GetTotalBalanceByHolderAndContract(holderScript string, contractId string) (uint64, error) {
+func (dao *FtTxoDAO) GetTotalBalanceByHolderAndContract(ctx context.Context, holderScript string, contractId string) (uint64, error) {
 	type Result struct {
 		TotalBalance uint64
 	}
 	var result Result
-	err := dao.db.Model(&dbtable.FtTxoSet{}).Select("SUM(ft_balance) as total_balance").
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).Select("SUM(ft_balance) as total_balance").
 		Where("ft_holder_combine_script = ? AND ft_contract_id = ? AND if_spend = ?",
 			holderScript, contractId, false).Scan(&result).Error
 	return result.TotalBalance, err
 }
 
 // BatchInsertFtTxos 批量插入代币交易输出记录
-func (dao *FtTxoDAO) BatchInsertFtTxos(txos []*dbtable.FtTxoSet) error {
-	return dao.db.CreateInBatches(txos, 100).Error
+func (dao *FtTxoDAO) BatchInsertFtTxos(ctx context.Context, txos []*dbtable.FtTxoSet) error {
+	return dao.db.WithContext(ctx).CreateInBatches(txos, 100).Error
 }
 
 // GetTotalBalanceByHolder 获取指定持有者和合约的未花费代币总余额
@@ -101,7 +197,7 @@ func (dao *FtTxoDAO) GetTotalBalanceByHolder(ctx context.Context, holderScript s
 		TotalBalance uint64
 	}
 	var result Result
-	err := dao.db.Model(&dbtable.FtTxoSet{}).Select("SUM(ft_balance) as total_balance").
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).Select("SUM(ft_balance) as total_balance").
 		Where("ft_holder_combine_script = ? AND ft_contract_id = ? AND if_spend = ?",
 			holderScript, contractId, false).Scan(&result).Error
 
@@ -119,6 +215,7 @@ func (dao *FtTxoDAO) GetTotalBalanceByHolder(ctx context.Context, holderScript s
 }
 
 // GetFtUtxoInfo 根据交易ID和输出索引获取代币余额、持有者组合脚本和合约ID
+// 非FT的UTXO返回db.ErrUtxoNotFound
 func (dao *FtTxoDAO) GetFtUtxoInfo(ctx context.Context, txid string, vout int) (uint64, string, string, error) {
 	var result struct {
 		FtBalance             uint64
@@ -126,14 +223,14 @@ func (dao *FtTxoDAO) GetFtUtxoInfo(ctx context.Context, txid string, vout int) (
 		FtContractId          string
 	}
 
-	err := dao.db.Model(&dbtable.FtTxoSet{}).
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).
 		Select("ft_balance, ft_holder_combine_script, ft_contract_id").
 		Where("utxo_txid = ? AND utxo_vout = ?", txid, vout).
 		First(&result).Error
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return 0, "", "", nil
+			return 0, "", "", db.ErrUtxoNotFound
 		}
 		return 0, "", "", err
 	}
@@ -161,7 +258,7 @@ func (dao *FtTxoDAO) GetPoolHistoryByPoolId(ctx context.Context, poolId string,
 	var queryResults []Result
 
 	// 联表查询ft_txo_set和ft_tokens表，获取代币名称和精度
-	err := dao.db.Table("TBC20721.ft_txo_set as t1").
+	err := dao.db.WithContext(ctx).Table("TBC20721.ft_txo_set as t1").
 		Select("t1.utxo_txid, t1.ft_holder_combine_script, t1.ft_contract_id, t1.ft_balance, t1.utxo_balance, t2.ft_name, t2.ft_decimal").
 		Joins("left join TBC20721.ft_tokens as t2 on t1.ft_contract_id = t2.ft_contract_id").
 		Where("t1.utxo_txid = ? OR t1.ft_holder_combine_script = ?", poolId, poolId).
@@ -218,7 +315,7 @@ func (dao *FtTxoDAO) GetFtContractIdsByHolder(ctx context.Context, holderScript
 	var contractIds []string
 
 	// 查询指定持有者持有的且未花费的所有代币合约ID（去重）
-	err := dao.db.Model(&dbtable.FtTxoSet{}).
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).
 		Distinct("ft_contract_id").
 		Where("ft_holder_combine_script = ? AND if_spend = ? AND ft_balance > 0", holderScript, false).
 		Pluck("ft_contract_id", &contractIds).Error
@@ -231,32 +328,146 @@ func (dao *FtTxoDAO) GetFtContractIdsByHolder(ctx context.Context, holderScript
 	return contractIds, nil
 }
 
+// 单次批量查询的最大输出点数量，避免生成过长的SQL
+const lpOutpointBatchSize = 500
+
 // GetLPUnspentByIds 根据UTXO ID和输出索引列表获取LP未花费交易输出
 func (dao *FtTxoDAO) GetLPUnspentByIds(ctx context.Context, txids []string, vouts []int) ([]*dbtable.FtTxoSet, error) {
+	result := make([]*dbtable.FtTxoSet, 0, len(txids))
+	err := dao.ScanLPUnspentByIds(ctx, txids, vouts, 0, func(txo *dbtable.FtTxoSet) error {
+		result = append(result, txo)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.InfoWithContext(ctx, "成功获取LP未花费交易输出", "记录数", len(result))
+	return result, nil
+}
+
+// ScanLPUnspentByIds 按输出点批量查询LP未花费交易输出，逐行回调fn而不在内存中保留完整结果
+// 输出点按(txid, vout)排序后分批使用元组IN条件查询，结果整体按(txid, vout)有序；minBalance大于0时只返回ft_balance不小于该值的记录
+func (dao *FtTxoDAO) ScanLPUnspentByIds(ctx context.Context, txids []string, vouts []int, minBalance uint64,
+	fn func(txo *dbtable.FtTxoSet) error) error {
+	outpoints, err := sortedOutpoints(ctx, txids, vouts)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(outpoints); start += lpOutpointBatchSize {
+		end := min(start+lpOutpointBatchSize, len(outpoints))
+		if err := dao.scanLPUnspentBatch(ctx, outpoints[start:end], minBalance, 0, 0, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanLPUnspentPageByIds 与ScanLPUnspentByIds相同，只回调按(txid, vout)排序后第offset条起的limit条记录，返回符合条件的总数
+// 每批先用COUNT统计数量，只有与当前页重叠的批次才用LIMIT/OFFSET查询记录
+func (dao *FtTxoDAO) ScanLPUnspentPageByIds(ctx context.Context, txids []string, vouts []int, minBalance uint64,
+	offset, limit int, fn func(txo *dbtable.FtTxoSet) error) (int64, error) {
+	outpoints, err := sortedOutpoints(ctx, txids, vouts)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for start := 0; start < len(outpoints); start += lpOutpointBatchSize {
+		end := min(start+lpOutpointBatchSize, len(outpoints))
+		batch := outpoints[start:end]
+
+		var count int64
+		if err := dao.lpUnspentQuery(ctx, batch, minBalance).Count(&count).Error; err != nil {
+			log.ErrorWithContext(ctx, "统计LP未花费交易输出失败", "count", len(batch), "error", err)
+			return 0, err
+		}
+		// 当前页在本批中的起止位置
+		from := max(int64(offset)-total, 0)
+		to := min(int64(offset+limit)-total, count)
+		total += count
+		if from >= to {
+			continue
+		}
+		if err := dao.scanLPUnspentBatch(ctx, batch, minBalance, int(from), int(to-from), fn); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// sortedOutpoints 将输出点按(txid, vout)排序，用作元组IN条件的参数
+func sortedOutpoints(ctx context.Context, txids []string, vouts []int) ([][]interface{}, error) {
 	if len(txids) != len(vouts) {
 		log.ErrorWithContext(ctx, "获取LP未花费交易输出参数错误: txids和vouts长度不匹配")
-		return nil, nil
+		return nil, fmt.Errorf("txids和vouts长度不匹配: %d != %d", len(txids), len(vouts))
 	}
 
-	if len(txids) == 0 {
-		return []*dbtable.FtTxoSet{}, nil
+	outpoints := make([][]interface{}, 0, len(txids))
+	for i := range txids {
+		outpoints = append(outpoints, []interface{}{txids[i], vouts[i]})
 	}
+	sort.Slice(outpoints, func(i, j int) bool {
+		if outpoints[i][0].(string) != outpoints[j][0].(string) {
+			return outpoints[i][0].(string) < outpoints[j][0].(string)
+		}
+		return outpoints[i][1].(int) < outpoints[j][1].(int)
+	})
+	return outpoints, nil
+}
 
-	// 构建查询条件
-	var result []*dbtable.FtTxoSet
-	tx := dao.db.WithContext(ctx)
+// lpUnspentQuery 返回一批输出点中未花费且满足最小余额的记录的查询
+func (dao *FtTxoDAO) lpUnspentQuery(ctx context.Context, outpoints [][]interface{}, minBalance uint64) *gorm.DB {
+	tx := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).
+		Where("(utxo_txid, utxo_vout) IN ? AND if_spend = ?", outpoints, false)
+	if minBalance > 0 {
+		tx = tx.Where("ft_balance >= ?", minBalance)
+	}
+	return tx
+}
 
-	// 使用事务执行批量查询
-	for i := 0; i < len(txids); i++ {
-		var records []*dbtable.FtTxoSet
-		if err := tx.Where("utxo_txid = ? AND utxo_vout = ? AND if_spend = ?",
-			txids[i], vouts[i], false).Find(&records).Error; err != nil {
-			log.ErrorWithContext(ctx, "查询LP未花费交易输出失败", "txid", txids[i], "vout", vouts[i], "error", err)
-			continue
+// scanLPUnspentBatch 查询一批输出点并逐行回调，limit大于0时只查询第offset条起的limit条记录
+func (dao *FtTxoDAO) scanLPUnspentBatch(ctx context.Context, outpoints [][]interface{}, minBalance uint64,
+	offset, limit int, fn func(txo *dbtable.FtTxoSet) error) error {
+	tx := dao.lpUnspentQuery(ctx, outpoints, minBalance).Order("utxo_txid, utxo_vout")
+	if limit > 0 {
+		tx = tx.Offset(offset).Limit(limit)
+	}
+
+	rows, err := tx.Rows()
+	if err != nil {
+		log.ErrorWithContext(ctx, "批量查询LP未花费交易输出失败", "count", len(outpoints), "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var txo dbtable.FtTxoSet
+		if err := tx.ScanRows(rows, &txo); err != nil {
+			log.ErrorWithContext(ctx, "读取LP未花费交易输出失败", "error", err)
+			return err
+		}
+		if err := fn(&txo); err != nil {
+			return err
 		}
-		result = append(result, records...)
 	}
+	return rows.Err()
+}
 
-	log.InfoWithContext(ctx, "成功获取LP未花费交易输出", "记录数", len(result))
-	return result, nil
+// GetSupplyMetricsByContract 按创建和花费高度聚合合约的代币供应量
+// dormantBefore为休眠截止高度，创建高度不晚于该高度的未花费输出计为休眠；spentAfter为统计周期起始高度
+func (dao *FtTxoDAO) GetSupplyMetricsByContract(ctx context.Context, contractId string, dormantBefore, spentAfter int64) (*dbtable.FtSupplyMetrics, error) {
+	var metrics dbtable.FtSupplyMetrics
+	err := dao.db.WithContext(ctx).Model(&dbtable.FtTxoSet{}).
+		Select(`COALESCE(SUM(CASE WHEN if_spend = 0 THEN ft_balance ELSE 0 END), 0) AS circulating_supply,
+			COALESCE(SUM(CASE WHEN if_spend = 0 AND create_height <= ? THEN ft_balance ELSE 0 END), 0) AS dormant_supply,
+			COALESCE(SUM(CASE WHEN if_spend = 0 AND create_height IS NULL THEN ft_balance ELSE 0 END), 0) AS unindexed_supply,
+			COALESCE(SUM(CASE WHEN if_spend = 1 AND spend_height > ? THEN ft_balance ELSE 0 END), 0) AS transferred_supply`,
+			dormantBefore, spentAfter).
+		Where("ft_contract_id = ?", contractId).
+		Scan(&metrics).Error
+	if err != nil {
+		log.ErrorWithContext(ctx, "聚合代币供应量失败", "contractId", contractId, "error", err)
+		return nil, err
+	}
+	return &metrics, nil
 }
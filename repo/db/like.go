@@ -0,0 +1,8 @@
+package db
+
+import "strings"
+
+// EscapeLike 转义LIKE模式中的通配符，使关键字按字面匹配
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,48 @@
+package nft_attribute_dao
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// 批量写入的每批记录数
+const insertBatchSize = 500
+
+// InsertNftAttributes 批量写入NFT属性索引，已存在的记录保持不变
+func InsertNftAttributes(ctx context.Context, attributes []*dbtable.NftAttributeIndex) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	result := db.GetDB().WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(attributes, insertBatchSize)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "写入NFT属性索引失败", "数量:", len(attributes), "错误:", result.Error)
+		return fmt.Errorf("写入NFT属性索引失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetAttributeIndexCursor 获取已建立索引的NFT中最大的创建时间戳，没有记录时返回0
+func GetAttributeIndexCursor(ctx context.Context) (int, error) {
+	var row struct {
+		NftCreateTimestamp *int `gorm:"column:nft_create_timestamp"`
+	}
+	result := db.GetDB().WithContext(ctx).Model(&dbtable.NftAttributeIndex{}).
+		Select("MAX(nft_create_timestamp) AS nft_create_timestamp").
+		Scan(&row)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询NFT属性索引进度失败", "错误:", result.Error)
+		return 0, fmt.Errorf("查询NFT属性索引进度失败: %w", result.Error)
+	}
+	if row.NftCreateTimestamp == nil {
+		return 0, nil
+	}
+	return *row.NftCreateTimestamp, nil
+}
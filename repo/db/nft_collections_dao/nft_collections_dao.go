@@ -2,6 +2,7 @@ package nft_collections_dao
 
 import (
 	"context"
+	"strings"
 
 	"ginproject/entity/dbtable"
 	"ginproject/repo/db"
@@ -22,50 +23,50 @@ func NewNftCollectionsDAO() *NftCollectionsDAO {
 }
 
 // InsertNftCollection 插入一条NFT集合记录
-func (dao *NftCollectionsDAO) InsertNftCollection(collection *dbtable.NftCollections) error {
-	return dao.db.Create(collection).Error
+func (dao *NftCollectionsDAO) InsertNftCollection(ctx context.Context, collection *dbtable.NftCollections) error {
+	return dao.db.WithContext(ctx).Create(collection).Error
 }
 
 // GetNftCollectionById 根据集合ID获取NFT集合
-func (dao *NftCollectionsDAO) GetNftCollectionById(collectionId string) (*dbtable.NftCollections, error) {
+func (dao *NftCollectionsDAO) GetNftCollectionById(ctx context.Context, collectionId string) (*dbtable.NftCollections, error) {
 	var collection dbtable.NftCollections
-	err := dao.db.Where("collection_id = ?", collectionId).First(&collection).Error
+	err := dao.db.WithContext(ctx).Where("collection_id = ?", collectionId).First(&collection).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrCollectionNotFound)
 	}
 	return &collection, nil
 }
 
 // UpdateNftCollection 更新NFT集合信息
-func (dao *NftCollectionsDAO) UpdateNftCollection(collection *dbtable.NftCollections) error {
-	return dao.db.Save(collection).Error
+func (dao *NftCollectionsDAO) UpdateNftCollection(ctx context.Context, collection *dbtable.NftCollections) error {
+	return dao.db.WithContext(ctx).Save(collection).Error
 }
 
 // DeleteNftCollection 删除NFT集合
-func (dao *NftCollectionsDAO) DeleteNftCollection(collectionId string) error {
-	return dao.db.Where("collection_id = ?", collectionId).Delete(&dbtable.NftCollections{}).Error
+func (dao *NftCollectionsDAO) DeleteNftCollection(ctx context.Context, collectionId string) error {
+	return dao.db.WithContext(ctx).Where("collection_id = ?", collectionId).Delete(&dbtable.NftCollections{}).Error
 }
 
 // GetCollectionsByCreator 根据创建者脚本哈希获取集合列表
-func (dao *NftCollectionsDAO) GetCollectionsByCreator(creatorScriptHash string) ([]*dbtable.NftCollections, error) {
+func (dao *NftCollectionsDAO) GetCollectionsByCreator(ctx context.Context, creatorScriptHash string) ([]*dbtable.NftCollections, error) {
 	var collections []*dbtable.NftCollections
-	err := dao.db.Where("collection_creator_script_hash = ?", creatorScriptHash).Find(&collections).Error
+	err := dao.db.WithContext(ctx).Where("collection_creator_script_hash = ?", creatorScriptHash).Find(&collections).Error
 	return collections, err
 }
 
 // GetCollectionsWithPagination 分页获取NFT集合列表
-func (dao *NftCollectionsDAO) GetCollectionsWithPagination(page, pageSize int) ([]*dbtable.NftCollections, int64, error) {
+func (dao *NftCollectionsDAO) GetCollectionsWithPagination(ctx context.Context, page, pageSize int) ([]*dbtable.NftCollections, int64, error) {
 	var collections []*dbtable.NftCollections
 	var total int64
 
 	// 获取总记录数
-	if err := dao.db.Model(&dbtable.NftCollections{}).Count(&total).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Model(&dbtable.NftCollections{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取分页数据
 	offset := (page - 1) * pageSize
-	if err := dao.db.Offset(offset).Limit(pageSize).Find(&collections).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&collections).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -135,7 +136,7 @@ func (dao *NftCollectionsDAO) GetDetailCollectionInfo(ctx context.Context, colle
 		Where("collection_id = ?", collectionId).
 		First(&collection).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrCollectionNotFound)
 	}
 	return &collection, nil
 }
@@ -153,8 +154,28 @@ func (dao *NftCollectionsDAO) GetCollectionIconAndDescription(ctx context.Contex
 		Where("collection_id = ?", collectionId).
 		First(&result).Error
 	if err != nil {
-		return "", "", err
+		return "", "", db.WrapNotFound(err, db.ErrCollectionNotFound)
 	}
 
 	return result.CollectionIcon, result.CollectionDescription, nil
 }
+
+// SearchCollectionIds 按集合名称部分匹配和创建者地址查找集合ID，条件为空时不参与过滤，最多返回limit个
+func (dao *NftCollectionsDAO) SearchCollectionIds(ctx context.Context, name, creatorAddress string, limit int) ([]string, error) {
+	query := dao.db.WithContext(ctx).Model(&dbtable.NftCollections{})
+	if name != "" {
+		query = query.Where("LOWER(collection_name) LIKE ?", "%"+db.EscapeLike(strings.ToLower(name))+"%")
+	}
+	if creatorAddress != "" {
+		query = query.Where("collection_creator_address = ?", creatorAddress)
+	}
+
+	var collectionIds []string
+	if err := query.
+		Order("collection_create_timestamp DESC").
+		Limit(limit).
+		Pluck("collection_id", &collectionIds).Error; err != nil {
+		return nil, err
+	}
+	return collectionIds, nil
+}
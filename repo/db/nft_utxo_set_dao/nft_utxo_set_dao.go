@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"ginproject/entity/dbtable"
+	"ginproject/entity/nft"
 	"ginproject/middleware/log"
 	"ginproject/repo/db"
+	"strings"
 	"sync"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // NftUtxoSetDAO 用于管理nft_utxo_set表操作的数据访问对象
@@ -24,67 +27,80 @@ func NewNftUtxoSetDAO() *NftUtxoSetDAO {
 }
 
 // InsertNftUtxo 插入一条NFT UTXO记录
-func (dao *NftUtxoSetDAO) InsertNftUtxo(utxo *dbtable.NftUtxoSet) error {
-	return dao.db.Create(utxo).Error
+func (dao *NftUtxoSetDAO) InsertNftUtxo(ctx context.Context, utxo *dbtable.NftUtxoSet) error {
+	return dao.db.WithContext(ctx).Create(utxo).Error
+}
+
+// UpsertNftUtxos 按合约ID批量写入NFT的当前状态，已存在的记录覆盖持有者、UTXO和转移信息，铸造时确定的元数据保持不变
+func (dao *NftUtxoSetDAO) UpsertNftUtxos(ctx context.Context, utxos []*dbtable.NftUtxoSet) error {
+	if len(utxos) == 0 {
+		return nil
+	}
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "nft_contract_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"nft_utxo_id", "nft_code_balance", "nft_p2pkh_balance",
+			"nft_transfer_time_count", "nft_holder_address", "nft_holder_script_hash", "nft_last_transfer_timestamp",
+			"nft_utxo_height"}),
+	}).CreateInBatches(utxos, 100).Error
 }
 
 // GetNftUtxoByContractId 根据合约ID获取NFT UTXO
-func (dao *NftUtxoSetDAO) GetNftUtxoByContractId(contractId string) (*dbtable.NftUtxoSet, error) {
+func (dao *NftUtxoSetDAO) GetNftUtxoByContractId(ctx context.Context, contractId string) (*dbtable.NftUtxoSet, error) {
 	var utxo dbtable.NftUtxoSet
-	err := dao.db.Where("nft_contract_id = ?", contractId).First(&utxo).Error
+	err := dao.db.WithContext(ctx).Where("nft_contract_id = ?", contractId).First(&utxo).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrNftNotFound)
 	}
 	return &utxo, nil
 }
 
 // GetNftUtxoByUtxoId 根据UTXO ID获取NFT UTXO
-func (dao *NftUtxoSetDAO) GetNftUtxoByUtxoId(utxoId string) (*dbtable.NftUtxoSet, error) {
+func (dao *NftUtxoSetDAO) GetNftUtxoByUtxoId(ctx context.Context, utxoId string) (*dbtable.NftUtxoSet, error) {
 	var utxo dbtable.NftUtxoSet
-	err := dao.db.Where("nft_utxo_id = ?", utxoId).First(&utxo).Error
+	err := dao.db.WithContext(ctx).Where("nft_utxo_id = ?", utxoId).First(&utxo).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrNftNotFound)
 	}
 	return &utxo, nil
 }
 
 // UpdateNftUtxo 更新NFT UTXO信息
-func (dao *NftUtxoSetDAO) UpdateNftUtxo(utxo *dbtable.NftUtxoSet) error {
-	return dao.db.Save(utxo).Error
+func (dao *NftUtxoSetDAO) UpdateNftUtxo(ctx context.Context, utxo *dbtable.NftUtxoSet) error {
+	return dao.db.WithContext(ctx).Save(utxo).Error
 }
 
 // DeleteNftUtxo 删除NFT UTXO
-func (dao *NftUtxoSetDAO) DeleteNftUtxo(contractId string) error {
-	return dao.db.Where("nft_contract_id = ?", contractId).Delete(&dbtable.NftUtxoSet{}).Error
+func (dao *NftUtxoSetDAO) DeleteNftUtxo(ctx context.Context, contractId string) error {
+	return dao.db.WithContext(ctx).Where("nft_contract_id = ?", contractId).Delete(&dbtable.NftUtxoSet{}).Error
 }
 
 // GetNftUtxosByCollection 根据集合ID获取NFT UTXO列表
-func (dao *NftUtxoSetDAO) GetNftUtxosByCollection(collectionId string) ([]*dbtable.NftUtxoSet, error) {
+func (dao *NftUtxoSetDAO) GetNftUtxosByCollection(ctx context.Context, collectionId string) ([]*dbtable.NftUtxoSet, error) {
 	var utxos []*dbtable.NftUtxoSet
-	err := dao.db.Where("collection_id = ?", collectionId).Find(&utxos).Error
+	err := dao.db.WithContext(ctx).Where("collection_id = ?", collectionId).Find(&utxos).Error
 	return utxos, err
 }
 
 // GetNftUtxosByHolder 根据持有者脚本哈希获取NFT UTXO列表
-func (dao *NftUtxoSetDAO) GetNftUtxosByHolder(holderScriptHash string) ([]*dbtable.NftUtxoSet, error) {
+func (dao *NftUtxoSetDAO) GetNftUtxosByHolder(ctx context.Context, holderScriptHash string) ([]*dbtable.NftUtxoSet, error) {
 	var utxos []*dbtable.NftUtxoSet
-	err := dao.db.Where("nft_holder_script_hash = ?", holderScriptHash).Find(&utxos).Error
+	err := dao.db.WithContext(ctx).Where("nft_holder_script_hash = ?", holderScriptHash).Find(&utxos).Error
 	return utxos, err
 }
 
 // GetNftUtxosWithPagination 分页获取NFT UTXO列表
-func (dao *NftUtxoSetDAO) GetNftUtxosWithPagination(page, pageSize int) ([]*dbtable.NftUtxoSet, int64, error) {
+func (dao *NftUtxoSetDAO) GetNftUtxosWithPagination(ctx context.Context, page, pageSize int) ([]*dbtable.NftUtxoSet, int64, error) {
 	var utxos []*dbtable.NftUtxoSet
 	var total int64
 
 	// 获取总记录数
-	if err := dao.db.Model(&dbtable.NftUtxoSet{}).Count(&total).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Model(&dbtable.NftUtxoSet{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取分页数据
 	offset := (page - 1) * pageSize
-	if err := dao.db.Offset(offset).Limit(pageSize).Find(&utxos).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&utxos).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -106,7 +122,7 @@ func (dao *NftUtxoSetDAO) GetPoolNftInfoByContractId(ctx context.Context, ftCont
 		First(&result).Error
 
 	if err != nil {
-		return "", 0, err
+		return "", 0, db.WrapNotFound(err, db.ErrNftNotFound)
 	}
 
 	return result.NftUtxoId, result.NftCodeBalance, nil
@@ -133,6 +149,22 @@ func (dao *NftUtxoSetDAO) GetPoolListByFtContractId(ctx context.Context, ftContr
 	return results, err
 }
 
+// GetPoolContractIds 获取最近创建的流动池的池NFT合约ID，最多limit个
+func (dao *NftUtxoSetDAO) GetPoolContractIds(ctx context.Context, limit int) ([]string, error) {
+	var poolIds []string
+	err := dao.db.WithContext(ctx).
+		Table("TBC20721.nft_utxo_set").
+		Where("nft_holder_address = ?", "LP").
+		Order("nft_create_timestamp DESC").
+		Limit(limit).
+		Pluck("nft_contract_id", &poolIds).Error
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取流动池列表失败: %v", err)
+		return nil, err
+	}
+	return poolIds, nil
+}
+
 // GetAllPoolsWithPagination 异步分页获取所有流动池列表，使用并行查询优化性能
 func (dao *NftUtxoSetDAO) GetAllPoolsWithPagination(ctx context.Context, page, size int) (<-chan struct {
 	Results []struct {
@@ -260,24 +292,31 @@ func (dao *NftUtxoSetDAO) GetAllPoolsWithPagination(ctx context.Context, page, s
 }
 
 // GetNftsByHolderWithPagination 根据持有者脚本哈希分页获取NFT列表
-func (dao *NftUtxoSetDAO) GetNftsByHolderWithPagination(ctx context.Context, holderScriptHash string, page, size int) ([]*dbtable.NftUtxoSet, int64, error) {
+// maxHeight不小于0时只返回所在高度不超过该高度的NFT，尚未回填高度的记录不返回
+func (dao *NftUtxoSetDAO) GetNftsByHolderWithPagination(ctx context.Context, holderScriptHash string, page, size int, maxHeight int64) ([]*dbtable.NftUtxoSet, int64, error) {
 	var nfts []*dbtable.NftUtxoSet
 	var total int64
 
 	// 计算起始索引
 	offset := page * size
 
+	// 总数和分页查询使用相同的过滤条件
+	query := func() *gorm.DB {
+		tx := dao.db.WithContext(ctx).Model(&dbtable.NftUtxoSet{}).
+			Where("nft_holder_script_hash = ?", holderScriptHash)
+		if maxHeight >= 0 {
+			tx = tx.Where("nft_utxo_height >= 1 AND nft_utxo_height <= ?", maxHeight)
+		}
+		return tx
+	}
+
 	// 获取总记录数
-	if err := dao.db.WithContext(ctx).
-		Model(&dbtable.NftUtxoSet{}).
-		Where("nft_holder_script_hash = ?", holderScriptHash).
-		Count(&total).Error; err != nil {
+	if err := query().Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取分页数据，按照最后转移时间戳倒序排序
-	if err := dao.db.WithContext(ctx).
-		Where("nft_holder_script_hash = ?", holderScriptHash).
+	if err := query().
 		Order("nft_last_transfer_timestamp DESC").
 		Limit(size).
 		Offset(offset).
@@ -289,7 +328,8 @@ func (dao *NftUtxoSetDAO) GetNftsByHolderWithPagination(ctx context.Context, hol
 }
 
 // GetNftsByCollectionIdWithPagination 根据集合ID分页获取NFT列表
-func (dao *NftUtxoSetDAO) GetNftsByCollectionIdWithPagination(ctx context.Context, collectionId string, page, size int) ([]*dbtable.NftUtxoSet, int64, error) {
+// 排序字段相同时按集合索引同方向排序，保证分页稳定并能反向扫描联合索引
+func (dao *NftUtxoSetDAO) GetNftsByCollectionIdWithPagination(ctx context.Context, collectionId string, sort nft.NftSort, page, size int) ([]*dbtable.NftUtxoSet, int64, error) {
 	var nfts []*dbtable.NftUtxoSet
 	var total int64
 
@@ -304,10 +344,14 @@ func (dao *NftUtxoSetDAO) GetNftsByCollectionIdWithPagination(ctx context.Contex
 		return nil, 0, err
 	}
 
-	// 获取分页数据，按照集合索引排序
-	if err := dao.db.WithContext(ctx).
+	// 获取分页数据，按照指定字段排序
+	query := dao.db.WithContext(ctx).
 		Where("collection_id = ?", collectionId).
-		Order("collection_index").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: sort.Column()}, Desc: sort.Desc})
+	if sort.Column() != "collection_index" {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "collection_index"}, Desc: sort.Desc})
+	}
+	if err := query.
 		Limit(size).
 		Offset(offset).
 		Find(&nfts).Error; err != nil {
@@ -317,6 +361,68 @@ func (dao *NftUtxoSetDAO) GetNftsByCollectionIdWithPagination(ctx context.Contex
 	return nfts, total, nil
 }
 
+// SearchNfts 按过滤条件分页搜索NFT，按创建时间倒序排列
+// 属性条件通过属性索引表过滤，每个条件都需匹配；CollectionIds为空切片时没有NFT满足条件
+func (dao *NftUtxoSetDAO) SearchNfts(ctx context.Context, filter nft.NftSearchFilter, page, size int) ([]*dbtable.NftUtxoSet, int64, error) {
+	var nfts []*dbtable.NftUtxoSet
+	var total int64
+
+	if filter.CollectionIds != nil && len(filter.CollectionIds) == 0 {
+		return nfts, 0, nil
+	}
+
+	query := func() *gorm.DB {
+		q := dao.db.WithContext(ctx).Model(&dbtable.NftUtxoSet{})
+		if filter.CollectionIds != nil {
+			q = q.Where("collection_id IN ?", filter.CollectionIds)
+		}
+		if filter.Name != "" {
+			q = q.Where("LOWER(nft_name) LIKE ?", "%"+db.EscapeLike(strings.ToLower(filter.Name))+"%")
+		}
+		for _, attr := range filter.Attributes {
+			q = q.Where("nft_contract_id IN (?)", dao.db.WithContext(ctx).
+				Model(&dbtable.NftAttributeIndex{}).
+				Select("nft_contract_id").
+				Where("attr_key = ? AND attr_value = ?", attr.Key, attr.Value))
+		}
+		return q
+	}
+
+	// 获取匹配的总记录数
+	if err := query().Count(&total).Error; err != nil {
+		log.ErrorWithContextf(ctx, "搜索NFT总数失败: %v", err)
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	if err := query().
+		Order("nft_create_timestamp DESC").
+		Order("nft_contract_id").
+		Offset(page * size).
+		Limit(size).
+		Find(&nfts).Error; err != nil {
+		log.ErrorWithContextf(ctx, "搜索NFT失败: %v", err)
+		return nil, 0, err
+	}
+
+	return nfts, total, nil
+}
+
+// GetNftAttributesAfter 按创建时间和合约ID顺序获取位于(timestamp, contractId)之后的NFT属性，用于增量建立属性索引
+func (dao *NftUtxoSetDAO) GetNftAttributesAfter(ctx context.Context, timestamp int, contractId string, limit int) ([]*dbtable.NftUtxoSet, error) {
+	var nfts []*dbtable.NftUtxoSet
+	if err := dao.db.WithContext(ctx).
+		Select("nft_contract_id", "nft_create_timestamp", "nft_attributes").
+		Where("nft_create_timestamp > ? OR (nft_create_timestamp = ? AND nft_contract_id > ?)", timestamp, timestamp, contractId).
+		Order("nft_create_timestamp").
+		Order("nft_contract_id").
+		Limit(limit).
+		Find(&nfts).Error; err != nil {
+		return nil, err
+	}
+	return nfts, nil
+}
+
 // GetNftsByContractIds 根据合约ID列表获取NFT信息
 func (dao *NftUtxoSetDAO) GetNftsByContractIds(ctx context.Context, contractIds []string) ([]*dbtable.NftUtxoSet, error) {
 	var nfts []*dbtable.NftUtxoSet
@@ -337,7 +443,7 @@ func (dao *NftUtxoSetDAO) GetNftByUtxoId(ctx context.Context, utxoId string) (*d
 		Where("nft_utxo_id = ?", utxoId).
 		First(&nft).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrNftNotFound)
 	}
 	return &nft, nil
 }
@@ -347,7 +453,7 @@ func (dao *NftUtxoSetDAO) GetNftUtxoByContractIdWithContext(ctx context.Context,
 	var utxo dbtable.NftUtxoSet
 	err := dao.db.WithContext(ctx).Where("nft_contract_id = ?", contractId).First(&utxo).Error
 	if err != nil {
-		return nil, err
+		return nil, db.WrapNotFound(err, db.ErrNftNotFound)
 	}
 	return &utxo, nil
 }
@@ -483,3 +589,34 @@ func (dao *NftUtxoSetDAO) GetAllPoolsWithPaginationParallel(ctx context.Context,
 
 	return resultChan, nil
 }
+
+// NftCollectionHolding 持有者在单个集合下的NFT聚合统计
+type NftCollectionHolding struct {
+	CollectionId          string `gorm:"column:collection_id"`
+	CollectionName        string `gorm:"column:collection_name"`
+	NftCount              int    `gorm:"column:nft_count"`
+	LastTransferTimestamp int    `gorm:"column:last_transfer_timestamp"`
+	FirstCreateTimestamp  int    `gorm:"column:first_create_timestamp"`
+	TotalTransferCount    int    `gorm:"column:total_transfer_count"`
+}
+
+// GetCollectionHoldingsByHolder 按集合分组统计持有者的NFT数量和最近转移时间，按最近转移时间倒序排列
+func (dao *NftUtxoSetDAO) GetCollectionHoldingsByHolder(ctx context.Context, holderScriptHash string) ([]*NftCollectionHolding, error) {
+	var holdings []*NftCollectionHolding
+
+	if err := dao.db.WithContext(ctx).
+		Model(&dbtable.NftUtxoSet{}).
+		Select("collection_id, MAX(collection_name) AS collection_name, COUNT(*) AS nft_count, "+
+			"MAX(nft_last_transfer_timestamp) AS last_transfer_timestamp, "+
+			"MIN(nft_create_timestamp) AS first_create_timestamp, "+
+			"SUM(nft_transfer_time_count) AS total_transfer_count").
+		Where("nft_holder_script_hash = ?", holderScriptHash).
+		Group("collection_id").
+		Order("last_transfer_timestamp DESC").
+		Scan(&holdings).Error; err != nil {
+		log.ErrorWithContextf(ctx, "按集合统计持有者NFT失败: %v", err)
+		return nil, err
+	}
+
+	return holdings, nil
+}
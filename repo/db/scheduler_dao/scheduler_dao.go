@@ -0,0 +1,78 @@
+package scheduler_dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnsureJob 创建任务记录，已存在时保持不变
+func EnsureJob(ctx context.Context, name string) error {
+	result := db.GetDB().WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&dbtable.SchedulerJob{JobName: name})
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "创建周期任务记录失败", "任务:", name, "错误:", result.Error)
+		return fmt.Errorf("创建周期任务记录失败: %w", result.Error)
+	}
+	return nil
+}
+
+// AcquireJob 抢占到期的任务，租约未到期或距上次开始不足interval时返回false
+// 抢占通过单条条件更新完成，多个实例同时抢占时只有一个实例更新成功
+func AcquireJob(ctx context.Context, name, owner string, now time.Time, interval, lease time.Duration) (bool, error) {
+	lockedUntil := now.Add(lease)
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.SchedulerJob{}).
+		Where("job_name = ?", name).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Where("last_started_at IS NULL OR last_started_at <= ?", now.Add(-interval)).
+		Updates(map[string]interface{}{
+			"owner":           owner,
+			"locked_until":    lockedUntil,
+			"last_started_at": now,
+		})
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "抢占周期任务失败", "任务:", name, "错误:", result.Error)
+		return false, fmt.Errorf("抢占周期任务失败: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FinishJob 记录任务的运行结果并释放租约，租约已被其它实例接管时不做修改
+func FinishJob(ctx context.Context, name, owner string, finishedAt time.Time, status, errMsg string, durationMs int64) error {
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.SchedulerJob{}).
+		Where("job_name = ? AND owner = ?", name, owner).
+		Updates(map[string]interface{}{
+			"locked_until":     nil,
+			"last_finished_at": finishedAt,
+			"last_status":      status,
+			"last_error":       errMsg,
+			"last_duration_ms": durationMs,
+			"run_count":        gorm.Expr("run_count + 1"),
+		})
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "记录周期任务结果失败", "任务:", name, "错误:", result.Error)
+		return fmt.Errorf("记录周期任务结果失败: %w", result.Error)
+	}
+	return nil
+}
+
+// ListJobs 获取全部任务记录
+func ListJobs(ctx context.Context) ([]*dbtable.SchedulerJob, error) {
+	var jobs []*dbtable.SchedulerJob
+	result := db.GetDB().WithContext(ctx).Order("job_name").Find(&jobs)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询周期任务记录失败", "错误:", result.Error)
+		return nil, fmt.Errorf("查询周期任务记录失败: %w", result.Error)
+	}
+	return jobs, nil
+}
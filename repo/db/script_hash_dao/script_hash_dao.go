@@ -0,0 +1,40 @@
+package script_hash_dao
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetAddressScriptHash 根据脚本哈希获取地址映射
+func GetAddressScriptHash(ctx context.Context, scriptHash string) (*dbtable.AddressScriptHash, error) {
+	var mapping dbtable.AddressScriptHash
+	result := db.GetDB().WithContext(ctx).Where("script_hash = ?", scriptHash).First(&mapping)
+
+	if result.Error != nil {
+		return nil, db.WrapNotFound(result.Error, db.ErrScriptHashNotFound)
+	}
+
+	return &mapping, nil
+}
+
+// InsertAddressScriptHashes 批量写入地址映射，映射由地址确定性计算得到，已存在的记录直接忽略
+func InsertAddressScriptHashes(ctx context.Context, mappings []*dbtable.AddressScriptHash) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&mappings)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "批量写入地址脚本哈希映射失败", "错误:", result.Error)
+		return fmt.Errorf("批量写入地址脚本哈希映射失败: %w", result.Error)
+	}
+
+	return nil
+}
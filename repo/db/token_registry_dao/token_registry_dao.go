@@ -0,0 +1,87 @@
+package token_registry_dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetTokenRegistryByContractId 根据合约ID获取代币元数据
+func GetTokenRegistryByContractId(ctx context.Context, contractId string) (*dbtable.TokenRegistry, error) {
+	var registry dbtable.TokenRegistry
+	result := db.GetDB().WithContext(ctx).Where("ft_contract_id = ?", contractId).First(&registry)
+
+	if result.Error != nil {
+		return nil, db.WrapNotFound(result.Error, db.ErrTokenNotFound)
+	}
+
+	return &registry, nil
+}
+
+// GetTokenRegistriesByContractIds 根据合约ID列表批量获取代币元数据，返回以合约ID为键的映射
+func GetTokenRegistriesByContractIds(ctx context.Context, contractIds []string) (map[string]*dbtable.TokenRegistry, error) {
+	registries := make(map[string]*dbtable.TokenRegistry, len(contractIds))
+	if len(contractIds) == 0 {
+		return registries, nil
+	}
+
+	var rows []*dbtable.TokenRegistry
+	result := db.GetDB().WithContext(ctx).Where("ft_contract_id IN ?", contractIds).Find(&rows)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "批量查询代币元数据失败", "错误:", result.Error)
+		return nil, fmt.Errorf("批量查询代币元数据失败: %w", result.Error)
+	}
+
+	for _, row := range rows {
+		registries[row.FtContractId] = row
+	}
+	return registries, nil
+}
+
+// UpsertTokenRegistries 批量写入代币元数据，已存在的记录整体覆盖
+func UpsertTokenRegistries(ctx context.Context, registries []*dbtable.TokenRegistry) error {
+	if len(registries) == 0 {
+		return nil
+	}
+
+	log.InfoWithContext(ctx, "执行批量写入代币元数据", "数量:", len(registries))
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ft_contract_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"logo_url", "website", "description", "tags", "socials", "verified", "verification_method", "verified_at"}),
+	}).Create(&registries)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "批量写入代币元数据失败", "错误:", result.Error)
+		return fmt.Errorf("批量写入代币元数据失败: %w", result.Error)
+	}
+
+	return nil
+}
+
+// MarkTokenVerified 将代币标记为已核验，未登记的代币新建只含核验信息的记录，已有的元数据保持不变
+func MarkTokenVerified(ctx context.Context, contractId, method string, verifiedAt time.Time) error {
+	row := &dbtable.TokenRegistry{
+		FtContractId:       contractId,
+		Verified:           true,
+		VerificationMethod: method,
+		VerifiedAt:         &verifiedAt,
+	}
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ft_contract_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"verified", "verification_method", "verified_at"}),
+	}).Create(row)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "写入代币核验状态失败", "合约ID:", contractId, "错误:", result.Error)
+		return fmt.Errorf("写入代币核验状态失败: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package tracked_wallet_dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// SaveWalletNotification 保存钱包的通知设置，已存在时替换渠道、接收方和通知方式，保留已通知的高度
+func SaveWalletNotification(ctx context.Context, notification *dbtable.TrackedWalletNotification) error {
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channel", "target", "mode", "updated_at"}),
+	}).Create(notification)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "保存钱包通知设置失败", "walletId:", notification.WalletId, "错误:", result.Error)
+		return fmt.Errorf("保存钱包通知设置失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetWalletNotification 获取钱包的通知设置
+func GetWalletNotification(ctx context.Context, walletId string) (*dbtable.TrackedWalletNotification, error) {
+	var notification dbtable.TrackedWalletNotification
+	result := db.GetDB().WithContext(ctx).Where("wallet_id = ?", walletId).First(&notification)
+	if result.Error != nil {
+		return nil, db.WrapNotFound(result.Error, db.ErrWalletNotificationNotFound)
+	}
+	return &notification, nil
+}
+
+// DeleteWalletNotification 删除钱包的通知设置
+func DeleteWalletNotification(ctx context.Context, walletId string) error {
+	result := db.GetDB().WithContext(ctx).Where("wallet_id = ?", walletId).Delete(&dbtable.TrackedWalletNotification{})
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "删除钱包通知设置失败", "walletId:", walletId, "错误:", result.Error)
+		return fmt.Errorf("删除钱包通知设置失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return db.ErrWalletNotificationNotFound
+	}
+	return nil
+}
+
+// GetWalletNotificationsDue 获取指定通知方式下从未检查或上次检查早于before的通知设置，最久未检查的在前
+func GetWalletNotificationsDue(ctx context.Context, mode string, before time.Time, limit int) ([]*dbtable.TrackedWalletNotification, error) {
+	var notifications []*dbtable.TrackedWalletNotification
+	result := db.GetDB().WithContext(ctx).
+		Where("mode = ? AND (last_sent_at IS NULL OR last_sent_at < ?)", mode, before).
+		Order("last_sent_at IS NOT NULL, last_sent_at").
+		Limit(limit).
+		Find(&notifications)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询待发送的钱包通知失败", "mode:", mode, "错误:", result.Error)
+		return nil, fmt.Errorf("查询待发送的钱包通知失败: %w", result.Error)
+	}
+	return notifications, nil
+}
+
+// MarkWalletNotified 记录通知已发送到的区块高度和检查时间
+func MarkWalletNotified(ctx context.Context, walletId string, height int64, sentAt time.Time) error {
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.TrackedWalletNotification{}).
+		Where("wallet_id = ?", walletId).
+		Updates(map[string]interface{}{
+			"notified_height": height,
+			"last_sent_at":    sentAt,
+		})
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "更新钱包通知状态失败", "walletId:", walletId, "错误:", result.Error)
+		return fmt.Errorf("更新钱包通知状态失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetTrackedWalletTxsAbove 获取钱包中区块高度大于height的已确认交易，按高度升序
+func GetTrackedWalletTxsAbove(ctx context.Context, walletId string, height int64) ([]*dbtable.TrackedWalletTx, error) {
+	var txs []*dbtable.TrackedWalletTx
+	result := db.GetDB().WithContext(ctx).
+		Where("wallet_id = ? AND height > ?", walletId, max(height, 0)).
+		Order("height, tx_hash").
+		Find(&txs)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询跟踪钱包新交易失败", "walletId:", walletId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询跟踪钱包新交易失败: %w", result.Error)
+	}
+	return txs, nil
+}
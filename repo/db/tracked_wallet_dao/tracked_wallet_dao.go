@@ -0,0 +1,339 @@
+package tracked_wallet_dao
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"ginproject/entity/dbtable"
+	"ginproject/entity/wallet"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 批量写入的每批记录数
+const insertBatchSize = 500
+
+// CreateTrackedWallet 创建跟踪钱包及其地址
+func CreateTrackedWallet(ctx context.Context, wallet *dbtable.TrackedWallet, addresses []*dbtable.TrackedWalletAddress) error {
+	log.InfoWithContext(ctx, "执行创建跟踪钱包", "walletId:", wallet.WalletId, "地址数量:", len(addresses))
+
+	err := db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wallet).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(addresses, insertBatchSize).Error
+	})
+	if err != nil {
+		log.ErrorWithContext(ctx, "创建跟踪钱包失败", "walletId:", wallet.WalletId, "错误:", err)
+		return fmt.Errorf("创建跟踪钱包失败: %w", err)
+	}
+	return nil
+}
+
+// GetTrackedWallet 根据钱包ID获取跟踪钱包
+func GetTrackedWallet(ctx context.Context, walletId string) (*dbtable.TrackedWallet, error) {
+	var wallet dbtable.TrackedWallet
+	result := db.GetDB().WithContext(ctx).Where("wallet_id = ?", walletId).First(&wallet)
+	if result.Error != nil {
+		return nil, db.WrapNotFound(result.Error, db.ErrWalletNotFound)
+	}
+	return &wallet, nil
+}
+
+// DeleteTrackedWallet 删除跟踪钱包及其地址、交易记录和通知设置
+func DeleteTrackedWallet(ctx context.Context, walletId string) error {
+	log.InfoWithContext(ctx, "执行删除跟踪钱包", "walletId:", walletId)
+
+	err := db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("wallet_id = ?", walletId).Delete(&dbtable.TrackedWallet{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return db.ErrWalletNotFound
+		}
+		if err := tx.Where("wallet_id = ?", walletId).Delete(&dbtable.TrackedWalletAddress{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wallet_id = ?", walletId).Delete(&dbtable.TrackedWalletNotification{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("wallet_id = ?", walletId).Delete(&dbtable.TrackedWalletTx{}).Error
+	})
+	if err != nil && !db.IsNotFound(err) {
+		log.ErrorWithContext(ctx, "删除跟踪钱包失败", "walletId:", walletId, "错误:", err)
+		return fmt.Errorf("删除跟踪钱包失败: %w", err)
+	}
+	return err
+}
+
+// GetWatchingWalletAddresses 获取跟踪钱包中监听中的地址
+func GetWatchingWalletAddresses(ctx context.Context, walletId string) ([]*dbtable.TrackedWalletAddress, error) {
+	var addresses []*dbtable.TrackedWalletAddress
+	result := db.GetDB().WithContext(ctx).
+		Where("wallet_id = ? AND watch_status = ?", walletId, wallet.WatchStatusWatching).
+		Order("source, derivation_path, address").
+		Find(&addresses)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询跟踪钱包地址失败", "walletId:", walletId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询跟踪钱包地址失败: %w", result.Error)
+	}
+	return addresses, nil
+}
+
+// GetWalletAddressesIn 获取跟踪钱包中指定地址的记录，不存在的地址不返回
+func GetWalletAddressesIn(ctx context.Context, walletId string, addresses []string) ([]*dbtable.TrackedWalletAddress, error) {
+	var records []*dbtable.TrackedWalletAddress
+	result := db.GetDB().WithContext(ctx).
+		Where("wallet_id = ? AND address IN ?", walletId, addresses).
+		Find(&records)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询跟踪钱包地址失败", "walletId:", walletId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询跟踪钱包地址失败: %w", result.Error)
+	}
+	return records, nil
+}
+
+// ListTrackedWalletAddresses 分页获取跟踪钱包的地址，status为空时不按监听状态过滤
+func ListTrackedWalletAddresses(ctx context.Context, walletId, status string, offset, limit int) ([]*dbtable.TrackedWalletAddress, error) {
+	var addresses []*dbtable.TrackedWalletAddress
+	query := db.GetDB().WithContext(ctx).Where("wallet_id = ?", walletId)
+	if status != "" {
+		query = query.Where("watch_status = ?", status)
+	}
+	result := query.
+		Order("source, derivation_path, address").
+		Offset(offset).
+		Limit(limit).
+		Find(&addresses)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询跟踪钱包地址失败", "walletId:", walletId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询跟踪钱包地址失败: %w", result.Error)
+	}
+	return addresses, nil
+}
+
+// CountTrackedWalletAddresses 统计跟踪钱包的地址数量，status为空时不按监听状态过滤
+func CountTrackedWalletAddresses(ctx context.Context, walletId, status string) (int64, error) {
+	var count int64
+	query := db.GetDB().WithContext(ctx).
+		Model(&dbtable.TrackedWalletAddress{}).
+		Where("wallet_id = ?", walletId)
+	if status != "" {
+		query = query.Where("watch_status = ?", status)
+	}
+	result := query.Count(&count)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "统计跟踪钱包地址数量失败", "walletId:", walletId, "错误:", result.Error)
+		return 0, fmt.Errorf("统计跟踪钱包地址数量失败: %w", result.Error)
+	}
+	return count, nil
+}
+
+// UpsertWalletAddresses 登记跟踪钱包的地址，已存在的地址更新监听策略并恢复监听，来源和派生路径保持不变
+func UpsertWalletAddresses(ctx context.Context, addresses []*dbtable.TrackedWalletAddress) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	result := db.GetDB().WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "wallet_id"}, {Name: "address"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"watch_status", "expires_at", "stop_confirmations", "registered_height",
+				"first_deposit_txid", "first_deposit_height", "stopped_at",
+			}),
+		}).
+		CreateInBatches(addresses, insertBatchSize)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "登记跟踪钱包地址失败", "walletId:", addresses[0].WalletId, "错误:", result.Error)
+		return fmt.Errorf("登记跟踪钱包地址失败: %w", result.Error)
+	}
+	return nil
+}
+
+// ExpireWalletAddresses 将跟踪钱包中已到过期时间的监听中地址标记为已过期，返回标记的数量
+func ExpireWalletAddresses(ctx context.Context, walletId string, now time.Time) (int64, error) {
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.TrackedWalletAddress{}).
+		Where("wallet_id = ? AND watch_status = ?", walletId, wallet.WatchStatusWatching).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Updates(map[string]interface{}{
+			"watch_status": wallet.WatchStatusExpired,
+			"stopped_at":   now,
+		})
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "标记过期的跟踪钱包地址失败", "walletId:", walletId, "错误:", result.Error)
+		return 0, fmt.Errorf("标记过期的跟踪钱包地址失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UpdateWalletAddressDeposit 记录地址的首笔充值，stop为true时同时停止监听；地址已停止监听时不做修改
+func UpdateWalletAddressDeposit(ctx context.Context, walletId, address, txid string, height int64, stop bool, now time.Time) error {
+	updates := map[string]interface{}{
+		"first_deposit_txid":   txid,
+		"first_deposit_height": height,
+	}
+	if stop {
+		updates["watch_status"] = wallet.WatchStatusDeposited
+		updates["stopped_at"] = now
+	}
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.TrackedWalletAddress{}).
+		Where("wallet_id = ? AND address = ? AND watch_status = ?", walletId, address, wallet.WatchStatusWatching).
+		Updates(updates)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "记录跟踪钱包地址充值失败", "walletId:", walletId, "address:", address, "错误:", result.Error)
+		return fmt.Errorf("记录跟踪钱包地址充值失败: %w", result.Error)
+	}
+	return nil
+}
+
+// GetTrackedWalletTxs 分页获取跟踪钱包的交易记录，未确认交易在前，其余按区块高度降序
+func GetTrackedWalletTxs(ctx context.Context, walletId string, offset, limit int) ([]*dbtable.TrackedWalletTx, error) {
+	var txs []*dbtable.TrackedWalletTx
+	result := db.GetDB().WithContext(ctx).
+		Where("wallet_id = ?", walletId).
+		Order("height <= 0 DESC, height DESC, tx_hash").
+		Offset(offset).
+		Limit(limit).
+		Find(&txs)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询跟踪钱包交易失败", "walletId:", walletId, "错误:", result.Error)
+		return nil, fmt.Errorf("查询跟踪钱包交易失败: %w", result.Error)
+	}
+	return txs, nil
+}
+
+// GetWalletIdsDueForSync 获取从未尝试同步或上次尝试早于before、且不在失败退避期内的钱包ID，最久未尝试的在前
+// 按尝试时间而不是成功时间排序，持续失败的钱包不会一直排在队首挤占其它钱包
+func GetWalletIdsDueForSync(ctx context.Context, before, now time.Time, limit int) ([]string, error) {
+	var walletIds []string
+	result := db.GetDB().WithContext(ctx).
+		Model(&dbtable.TrackedWallet{}).
+		Where("last_attempt_at IS NULL OR last_attempt_at < ?", before).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+		Order("last_attempt_at IS NOT NULL, last_attempt_at").
+		Limit(limit).
+		Pluck("wallet_id", &walletIds)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "查询待同步钱包失败", "错误:", result.Error)
+		return nil, fmt.Errorf("查询待同步钱包失败: %w", result.Error)
+	}
+	return walletIds, nil
+}
+
+// RecordWalletSyncFailure 记录一次同步失败，连续失败次数加一，下一次重试时间为now加上backoff按失败次数返回的退避时间
+func RecordWalletSyncFailure(ctx context.Context, walletId string, now time.Time, backoff func(failures int) time.Duration) error {
+	err := db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record dbtable.TrackedWallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("wallet_id", "sync_failures").
+			Where("wallet_id = ?", walletId).
+			First(&record).Error
+		if err != nil {
+			return db.WrapNotFound(err, db.ErrWalletNotFound)
+		}
+		failures := record.SyncFailures + 1
+		return tx.Model(&dbtable.TrackedWallet{}).
+			Where("wallet_id = ?", walletId).
+			Updates(map[string]interface{}{
+				"sync_failures":   failures,
+				"last_attempt_at": now,
+				"next_attempt_at": now.Add(backoff(failures)),
+			}).Error
+	})
+	if err != nil && !db.IsNotFound(err) {
+		log.ErrorWithContext(ctx, "记录跟踪钱包同步失败出错", "walletId:", walletId, "错误:", err)
+		return fmt.Errorf("记录跟踪钱包同步失败出错: %w", err)
+	}
+	return err
+}
+
+// SaveTrackedWalletRollup 保存同步结果并更新汇总字段，清除失败重试状态
+// 交易记录只写入变化的部分：新增或高度、地址变化的交易按主键upsert，不再出现的交易删除
+func SaveTrackedWalletRollup(ctx context.Context, wallet *dbtable.TrackedWallet, txs []*dbtable.TrackedWalletTx) error {
+	err := db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []*dbtable.TrackedWalletTx
+		if err := tx.Where("wallet_id = ?", wallet.WalletId).Find(&existing).Error; err != nil {
+			return err
+		}
+		upserts, deletes := diffWalletTxs(existing, txs)
+		for start := 0; start < len(deletes); start += insertBatchSize {
+			end := min(start+insertBatchSize, len(deletes))
+			if err := tx.Where("wallet_id = ? AND tx_hash IN ?", wallet.WalletId, deletes[start:end]).
+				Delete(&dbtable.TrackedWalletTx{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(upserts) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "wallet_id"}, {Name: "tx_hash"}},
+				DoUpdates: clause.AssignmentColumns([]string{"height", "addresses"}),
+			}).CreateInBatches(upserts, insertBatchSize).Error
+			if err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&dbtable.TrackedWallet{}).
+			Where("wallet_id = ?", wallet.WalletId).
+			Updates(map[string]interface{}{
+				"confirmed":            wallet.Confirmed,
+				"unconfirmed":          wallet.Unconfirmed,
+				"tx_count":             wallet.TxCount,
+				"last_activity_height": wallet.LastActivityHeight,
+				"last_activity_time":   wallet.LastActivityTime,
+				"synced_at":            wallet.SyncedAt,
+				"last_attempt_at":      wallet.SyncedAt,
+				"sync_failures":        0,
+				"next_attempt_at":      nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// 同步期间钱包已被删除，丢弃本次结果
+			return db.ErrWalletNotFound
+		}
+		return nil
+	})
+	if err != nil && !db.IsNotFound(err) {
+		log.ErrorWithContext(ctx, "保存跟踪钱包同步结果失败", "walletId:", wallet.WalletId, "错误:", err)
+		return fmt.Errorf("保存跟踪钱包同步结果失败: %w", err)
+	}
+	return err
+}
+
+// diffWalletTxs 比较已保存的交易记录和本次同步结果，返回需要upsert的记录和需要删除的交易哈希
+func diffWalletTxs(existing, txs []*dbtable.TrackedWalletTx) ([]*dbtable.TrackedWalletTx, []string) {
+	saved := make(map[string]*dbtable.TrackedWalletTx, len(existing))
+	for _, tx := range existing {
+		saved[tx.TxHash] = tx
+	}
+	var upserts []*dbtable.TrackedWalletTx
+	for _, tx := range txs {
+		old, ok := saved[tx.TxHash]
+		delete(saved, tx.TxHash)
+		if ok && old.Height == tx.Height && old.Addresses == tx.Addresses {
+			continue
+		}
+		upserts = append(upserts, tx)
+	}
+	deletes := make([]string, 0, len(saved))
+	for txHash := range saved {
+		deletes = append(deletes, txHash)
+	}
+	sort.Strings(deletes)
+	return upserts, deletes
+}
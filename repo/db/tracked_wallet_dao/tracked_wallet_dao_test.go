@@ -0,0 +1,35 @@
+package tracked_wallet_dao
+
+import (
+	"slices"
+	"testing"
+
+	"ginproject/entity/dbtable"
+)
+
+func TestDiffWalletTxs(t *testing.T) {
+	existing := []*dbtable.TrackedWalletTx{
+		{TxHash: "a", Height: 100, Addresses: "1A"},
+		{TxHash: "b", Height: 0, Addresses: "1A"},
+		{TxHash: "c", Height: 101, Addresses: "1B"},
+		{TxHash: "d", Height: 102, Addresses: "1B"},
+	}
+	txs := []*dbtable.TrackedWalletTx{
+		{TxHash: "a", Height: 100, Addresses: "1A"},    // 未变化
+		{TxHash: "b", Height: 103, Addresses: "1A"},    // 已确认
+		{TxHash: "c", Height: 101, Addresses: "1A,1B"}, // 新增地址
+		{TxHash: "e", Height: 0, Addresses: "1C"},      // 新交易
+	}
+	upserts, deletes := diffWalletTxs(existing, txs)
+
+	var hashes []string
+	for _, tx := range upserts {
+		hashes = append(hashes, tx.TxHash)
+	}
+	if !slices.Equal(hashes, []string{"b", "c", "e"}) {
+		t.Errorf("upsert = %v", hashes)
+	}
+	if !slices.Equal(deletes, []string{"d"}) {
+		t.Errorf("delete = %v", deletes)
+	}
+}
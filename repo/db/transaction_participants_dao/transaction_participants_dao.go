@@ -6,6 +6,8 @@ import (
 	"ginproject/entity/dbtable"
 	"ginproject/middleware/log"
 	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
 )
 
 // GetParticipantsByTxHashes 根据交易哈希列表获取参与方信息
@@ -69,3 +71,21 @@ func GetParticipantsByTxHashAndRole(ctx context.Context, txHash string, role dbt
 
 	return participants, nil
 }
+
+// UpsertParticipants 按(tx_hash, address, role)批量写入交易参与方，已存在的记录保持不变
+func UpsertParticipants(ctx context.Context, participants []*dbtable.TransactionParticipant) error {
+	if len(participants) == 0 {
+		return nil
+	}
+
+	log.InfoWithContext(ctx, "执行批量写入交易参与方", "数量:", len(participants))
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&participants)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "批量写入交易参与方失败", "错误:", result.Error)
+		return fmt.Errorf("批量写入交易参与方失败: %w", result.Error)
+	}
+
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"ginproject/entity/dbtable"
 	"ginproject/middleware/log"
 	"ginproject/repo/db"
+
+	"gorm.io/gorm/clause"
 )
 
 // GetTransactionByTxHash 根据交易哈希获取交易信息
@@ -16,7 +18,7 @@ func GetTransactionByTxHash(ctx context.Context, txHash string) (*dbtable.Transa
 
 	if result.Error != nil {
 		log.ErrorWithContext(ctx, "查询交易信息失败", "txHash:", txHash, "错误:", result.Error)
-		return nil, fmt.Errorf("查询交易信息失败: %w", result.Error)
+		return nil, fmt.Errorf("查询交易信息失败: %w", db.WrapNotFound(result.Error, db.ErrTxNotFound))
 	}
 
 	return &transaction, nil
@@ -55,3 +57,62 @@ func CountTransactions(ctx context.Context) (int64, error) {
 
 	return count, nil
 }
+
+// GetTransactionActivity 按统计周期聚合[from, to)内的交易数，时间为Unix秒
+func GetTransactionActivity(ctx context.Context, from, to, interval int64) ([]*dbtable.TransactionActivity, error) {
+	log.InfoWithContext(ctx, "执行按周期统计交易数", "from:", from, "to:", to, "interval:", interval)
+
+	var rows []*dbtable.TransactionActivity
+	result := db.GetDB().WithContext(ctx).Model(&dbtable.Transaction{}).
+		Select("FLOOR(time_stamp / ?) * ? AS bucket, COUNT(*) AS txs", interval, interval).
+		Where("time_stamp >= ? AND time_stamp < ?", from, to).
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "按周期统计交易数失败", "错误:", result.Error)
+		return nil, fmt.Errorf("按周期统计交易数失败: %w", result.Error)
+	}
+
+	return rows, nil
+}
+
+// UpsertTransactions 按tx_hash批量写入交易，已存在的记录覆盖为本次的值，崩溃重放时重复写入不会产生重复记录
+func UpsertTransactions(ctx context.Context, transactions []*dbtable.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	log.InfoWithContext(ctx, "执行批量写入交易", "数量:", len(transactions))
+
+	result := db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"fee", "time_stamp", "transaction_utc_time", "tx_type", "updated_at"}),
+	}).Create(&transactions)
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "批量写入交易失败", "错误:", result.Error)
+		return fmt.Errorf("批量写入交易失败: %w", result.Error)
+	}
+
+	return nil
+}
+
+// DeleteTransactionsByTxHashes 删除重组中被回滚的交易，地址交易关系和参与方记录通过外键级联删除
+func DeleteTransactionsByTxHashes(ctx context.Context, txHashes []string) error {
+	if len(txHashes) == 0 {
+		return nil
+	}
+
+	log.InfoWithContext(ctx, "执行删除回滚的交易", "数量:", len(txHashes))
+
+	result := db.GetDB().WithContext(ctx).Where("tx_hash IN ?", txHashes).Delete(&dbtable.Transaction{})
+
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "删除回滚的交易失败", "错误:", result.Error)
+		return fmt.Errorf("删除回滚的交易失败: %w", result.Error)
+	}
+
+	return nil
+}
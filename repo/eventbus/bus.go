@@ -0,0 +1,248 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+const (
+	// 每次投递给消费者的最大事件数
+	deliverBatchSize = 100
+	// 没有新事件通知时重新检查日志的周期
+	pollInterval = time.Second
+	// 处理失败后重试的初始和最大等待时间
+	minRetryDelay = 100 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// Handler 事件处理函数，返回错误时同一批事件会在等待后重新投递
+type Handler func(ctx context.Context, events []Event) error
+
+// SubscriptionStats 订阅的投递状态
+type SubscriptionStats struct {
+	Consumer  string `json:"consumer"`
+	Topic     string `json:"topic"`
+	Next      uint64 `json:"next"`       // 下一条待处理的偏移量
+	Failures  int    `json:"failures"`   // 当前批次连续失败的次数
+	LastError string `json:"last_error"` // 最近一次处理失败的原因
+}
+
+// Bus 事件总线，消费者处理成功后才提交偏移量，保证至少投递一次
+type Bus struct {
+	log     Log
+	offsets OffsetStore
+
+	mu     sync.Mutex
+	notify map[string]chan struct{}
+	subs   map[string]*subscription
+}
+
+type subscription struct {
+	mu    sync.Mutex
+	stats SubscriptionStats
+	seek  *uint64
+	wake  chan struct{}
+	// live 只投递订阅之后的事件且不提交偏移量
+	live bool
+}
+
+// New 创建事件总线
+func New(log Log, offsets OffsetStore) *Bus {
+	return &Bus{
+		log:     log,
+		offsets: offsets,
+		notify:  make(map[string]chan struct{}),
+		subs:    make(map[string]*subscription),
+	}
+}
+
+// Publish 发布事件，payload序列化为JSON
+func (b *Bus) Publish(ctx context.Context, topic, key string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %w", err)
+	}
+	if _, err := b.log.Append(ctx, topic, key, data); err != nil {
+		return fmt.Errorf("写入事件失败: %w", err)
+	}
+
+	// 唤醒等待该主题的消费者
+	b.mu.Lock()
+	if ch, ok := b.notify[topic]; ok {
+		close(ch)
+		delete(b.notify, topic)
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+// Subscribe 以consumer的身份订阅主题，在后台按偏移量顺序投递事件，ctx取消时退出
+// 没有提交记录的消费者从订阅时的最新位置开始，之后按提交的偏移量继续，重启后可以重放未处理的事件
+func (b *Bus) Subscribe(ctx context.Context, consumer, topic string, handler Handler) error {
+	next, ok, err := b.offsets.Load(consumer, topic)
+	if err != nil {
+		return fmt.Errorf("读取消费偏移量失败: %w", err)
+	}
+	if !ok {
+		next = b.log.Stats()[topic].Next
+	}
+	return b.subscribe(ctx, &subscription{
+		stats: SubscriptionStats{Consumer: consumer, Topic: topic, Next: next},
+		wake:  make(chan struct{}, 1),
+	}, handler)
+}
+
+// SubscribeLive 以consumer的身份订阅主题，只投递订阅之后发布的事件，不提交偏移量
+// 用于进程内缓存、WebSocket连接等每个实例各自维护且重启后无需重放的状态，事件日志由多个实例共享时每个实例都会收到
+func (b *Bus) SubscribeLive(ctx context.Context, consumer, topic string, handler Handler) error {
+	return b.subscribe(ctx, &subscription{
+		stats: SubscriptionStats{Consumer: consumer, Topic: topic, Next: b.log.Stats()[topic].Next},
+		wake:  make(chan struct{}, 1),
+		live:  true,
+	}, handler)
+}
+
+// subscribe 登记订阅并在后台开始投递
+func (b *Bus) subscribe(ctx context.Context, sub *subscription, handler Handler) error {
+	consumer, topic := sub.stats.Consumer, sub.stats.Topic
+	b.mu.Lock()
+	if _, exists := b.subs[offsetKey(consumer, topic)]; exists {
+		b.mu.Unlock()
+		return fmt.Errorf("消费者%s已订阅主题%s", consumer, topic)
+	}
+	b.subs[offsetKey(consumer, topic)] = sub
+	b.mu.Unlock()
+
+	go b.deliver(ctx, sub, handler)
+	return nil
+}
+
+// Seek 将消费者的位置移动到指定偏移量，用于手动重放
+func (b *Bus) Seek(consumer, topic string, offset uint64) error {
+	b.mu.Lock()
+	sub, ok := b.subs[offsetKey(consumer, topic)]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("消费者%s未订阅主题%s", consumer, topic)
+	}
+
+	sub.mu.Lock()
+	sub.seek = &offset
+	sub.mu.Unlock()
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Last 返回主题的最后一条事件，主题为空时返回nil
+func (b *Bus) Last(ctx context.Context, topic string) (*Event, error) {
+	stats := b.log.Stats()[topic]
+	if stats.Next == stats.First {
+		return nil, nil
+	}
+	events, err := b.log.Read(ctx, topic, stats.Next-1, 1)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+// Stats 返回各主题的偏移量范围和各订阅的投递状态
+func (b *Bus) Stats() (map[string]TopicStats, []SubscriptionStats) {
+	b.mu.Lock()
+	subs := make([]*subscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	stats := make([]SubscriptionStats, 0, len(subs))
+	for _, sub := range subs {
+		sub.mu.Lock()
+		stats = append(stats, sub.stats)
+		sub.mu.Unlock()
+	}
+	return b.log.Stats(), stats
+}
+
+// wait 返回主题出现新事件时关闭的通道
+func (b *Bus) wait(topic string) <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, ok := b.notify[topic]
+	if !ok {
+		ch = make(chan struct{})
+		b.notify[topic] = ch
+	}
+	return ch
+}
+
+// deliver 投递循环，每批事件处理成功后提交偏移量，失败时按指数退避重试同一批
+func (b *Bus) deliver(ctx context.Context, sub *subscription, handler Handler) {
+	consumer, topic := sub.stats.Consumer, sub.stats.Topic
+	next := sub.stats.Next
+	delay := minRetryDelay
+
+	for ctx.Err() == nil {
+		sub.mu.Lock()
+		if sub.seek != nil {
+			next, sub.seek = *sub.seek, nil
+			sub.stats.Next = next
+		}
+		sub.mu.Unlock()
+
+		// 先登记等待再读取，避免错过两者之间发布的事件
+		notified := b.wait(topic)
+		events, err := b.log.Read(ctx, topic, next, deliverBatchSize)
+		if err == nil && len(events) == 0 {
+			select {
+			case <-ctx.Done():
+			case <-notified:
+			case <-sub.wake:
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+		if err == nil {
+			if events[0].Offset > next {
+				log.Warnf("消费者%s在主题%s上落后于保留范围，跳过偏移量%d到%d", consumer, topic, next, events[0].Offset-1)
+			}
+			err = handler(ctx, events)
+		}
+
+		sub.mu.Lock()
+		if err != nil {
+			sub.stats.Failures++
+			sub.stats.LastError = err.Error()
+			sub.mu.Unlock()
+			log.Warnf("消费者%s处理主题%s的事件失败，%v后重试: %v", consumer, topic, delay, err)
+			select {
+			case <-ctx.Done():
+			case <-sub.wake:
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxRetryDelay)
+			continue
+		}
+		next = events[len(events)-1].Offset + 1
+		sub.stats.Next = next
+		sub.stats.Failures = 0
+		sub.stats.LastError = ""
+		sub.mu.Unlock()
+		delay = minRetryDelay
+
+		if sub.live {
+			continue
+		}
+		if err := b.offsets.Commit(consumer, topic, next); err != nil {
+			log.Warnf("提交消费者%s在主题%s上的偏移量失败: %v", consumer, topic, err)
+		}
+	}
+}
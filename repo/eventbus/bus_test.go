@@ -0,0 +1,214 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// collector 记录收到的事件偏移量
+type collector struct {
+	mu      sync.Mutex
+	offsets []uint64
+	got     chan struct{}
+}
+
+func newCollector() *collector {
+	return &collector{got: make(chan struct{}, 100)}
+}
+
+func (c *collector) handle(ctx context.Context, events []Event) error {
+	c.mu.Lock()
+	for _, event := range events {
+		c.offsets = append(c.offsets, event.Offset)
+	}
+	c.mu.Unlock()
+	c.got <- struct{}{}
+	return nil
+}
+
+// waitFor 等待累计收到n条事件
+func (c *collector) waitFor(t *testing.T, n int) []uint64 {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		c.mu.Lock()
+		if len(c.offsets) >= n {
+			offsets := append([]uint64(nil), c.offsets...)
+			c.mu.Unlock()
+			return offsets
+		}
+		c.mu.Unlock()
+		select {
+		case <-c.got:
+		case <-deadline:
+			t.Fatalf("未在期限内收到%d条事件", n)
+		}
+	}
+}
+
+func TestBusDelivery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := New(NewMemoryLog(0), NewMemoryOffsets())
+
+	// 订阅前发布的事件不投递给新消费者
+	bus.Publish(ctx, "block", "", 0)
+	c := newCollector()
+	if err := bus.Subscribe(ctx, "test", "block", c.handle); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if err := bus.Subscribe(ctx, "test", "block", c.handle); err == nil {
+		t.Fatal("重复订阅应返回错误")
+	}
+	for i := 1; i <= 3; i++ {
+		bus.Publish(ctx, "block", "", i)
+	}
+
+	offsets := c.waitFor(t, 3)
+	if len(offsets) != 3 || offsets[0] != 1 || offsets[2] != 3 {
+		t.Fatalf("投递的偏移量不一致: %v", offsets)
+	}
+
+	// 重放
+	if err := bus.Seek("test", "block", 0); err != nil {
+		t.Fatalf("重放失败: %v", err)
+	}
+	if offsets := c.waitFor(t, 7); offsets[3] != 0 {
+		t.Fatalf("重放后应从偏移量0开始: %v", offsets)
+	}
+}
+
+func TestBusRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := New(NewMemoryLog(0), NewMemoryOffsets())
+
+	c := newCollector()
+	failures := 2
+	handler := func(ctx context.Context, events []Event) error {
+		if failures > 0 {
+			failures--
+			return errors.New("暂时失败")
+		}
+		return c.handle(ctx, events)
+	}
+	bus.Subscribe(ctx, "test", "tx", handler)
+	bus.Publish(ctx, "tx", "aa", "payload")
+
+	if offsets := c.waitFor(t, 1); offsets[0] != 0 {
+		t.Fatalf("失败后应重新投递同一事件: %v", offsets)
+	}
+	_, subs := bus.Stats()
+	if len(subs) != 1 || subs[0].Next != 1 || subs[0].Failures != 0 {
+		t.Fatalf("订阅状态不一致: %+v", subs)
+	}
+}
+
+func TestFileBusResume(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *Bus {
+		eventLog, err := NewFileLog(dir, 0)
+		if err != nil {
+			t.Fatalf("打开事件日志失败: %v", err)
+		}
+		offsets, err := NewFileOffsets(filepath.Join(dir, "offsets.json"))
+		if err != nil {
+			t.Fatalf("打开偏移量存储失败: %v", err)
+		}
+		return New(eventLog, offsets)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus := open()
+	c := newCollector()
+	bus.Subscribe(ctx, "test", "block", c.handle)
+	bus.Publish(ctx, "block", "", 1)
+	c.waitFor(t, 1)
+	cancel()
+
+	// 消费者停止期间发布的事件在重启后投递
+	bus.Publish(context.Background(), "block", "", 2)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	bus = open()
+	if stats := bus.log.Stats()["block"]; stats.Next != 2 {
+		t.Fatalf("重启后偏移量应继续: %+v", stats)
+	}
+	c = newCollector()
+	bus.Subscribe(ctx, "test", "block", c.handle)
+	if offsets := c.waitFor(t, 1); offsets[0] != 1 {
+		t.Fatalf("重启后应从提交的偏移量继续: %v", offsets)
+	}
+}
+
+func TestMemoryLogRetention(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLog(2)
+	for i := 0; i < 5; i++ {
+		l.Append(ctx, "tx", "", []byte("1"))
+	}
+
+	events, _ := l.Read(ctx, "tx", 0, 10)
+	if len(events) != 2 || events[0].Offset != 3 {
+		t.Fatalf("超出保留范围的事件应被裁剪: %+v", events)
+	}
+	if stats := l.Stats()["tx"]; stats.First != 3 || stats.Next != 5 {
+		t.Fatalf("偏移量范围不一致: %+v", stats)
+	}
+	if _, err := NewFileLog(t.TempDir(), 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBusSubscribeLive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	offsets := NewMemoryOffsets()
+	bus := New(NewMemoryLog(0), offsets)
+
+	bus.Publish(ctx, "block", "", 0)
+	c := newCollector()
+	if err := bus.SubscribeLive(ctx, "live", "block", c.handle); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	bus.Publish(ctx, "block", "", 1)
+	if got := c.waitFor(t, 1); got[0] != 1 {
+		t.Fatalf("只应投递订阅之后的事件: %v", got)
+	}
+	if _, ok, _ := offsets.Load("live", "block"); ok {
+		t.Fatal("实时订阅不应提交偏移量")
+	}
+}
+
+func TestRedisStreamIDs(t *testing.T) {
+	if id := streamID(0); id != "0-1" {
+		t.Fatalf("偏移量0的事件ID = %s", id)
+	}
+	for _, id := range []string{"0-0", "1-5", "0-x", ""} {
+		if _, err := streamOffset(id); err == nil {
+			t.Errorf("事件ID %q 应无效", id)
+		}
+	}
+
+	event, err := eventFromMessage("tx", redis.XMessage{ID: "0-8", Values: map[string]interface{}{"key": "aa", "time": "1700000000", "payload": `{"n":1}`}})
+	if err != nil || event.Offset != 7 || event.Key != "aa" || event.Time != 1700000000 || string(event.Payload) != `{"n":1}` {
+		t.Fatalf("转换事件 = %+v, %v", event, err)
+	}
+
+	// 已裁剪到只剩最后两条事件
+	stats, err := topicStats(&redis.XInfoStream{Length: 2, LastGeneratedID: "0-10", FirstEntry: redis.XMessage{ID: "0-9"}})
+	if err != nil || stats.First != 8 || stats.Next != 10 {
+		t.Fatalf("偏移量范围 = %+v, %v", stats, err)
+	}
+	stats, err = topicStats(&redis.XInfoStream{LastGeneratedID: "0-10"})
+	if err != nil || stats.First != 10 || stats.Next != 10 {
+		t.Fatalf("空主题的偏移量范围 = %+v, %v", stats, err)
+	}
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+)
+
+// 支持的事件日志类型
+const (
+	DriverMemory = "memory"
+	DriverFile   = "file"
+	DriverRedis  = "redis"
+)
+
+var (
+	defaultMu  sync.Mutex
+	defaultBus *Bus
+)
+
+// Init 按配置创建全局事件总线，未配置时使用内存日志
+func Init() error {
+	cfg := config.GetConfig().GetEventBusConfig()
+	bus, err := NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	defaultMu.Lock()
+	defaultBus = bus
+	defaultMu.Unlock()
+	log.Info("事件总线已初始化", "driver:", cfg.Driver, "dir:", cfg.Dir)
+	return nil
+}
+
+// NewFromConfig 根据配置创建事件总线
+func NewFromConfig(cfg *config.EventBusConfig) (*Bus, error) {
+	switch cfg.Driver {
+	case "", DriverMemory:
+		return New(NewMemoryLog(cfg.Retention), NewMemoryOffsets()), nil
+	case DriverFile:
+		eventLog, err := NewFileLog(cfg.Dir, cfg.Retention)
+		if err != nil {
+			return nil, err
+		}
+		offsets, err := NewFileOffsets(filepath.Join(cfg.Dir, "offsets.json"))
+		if err != nil {
+			return nil, err
+		}
+		return New(eventLog, offsets), nil
+	case DriverRedis:
+		// 使用共享缓存的Redis连接，需要先初始化共享缓存
+		r, ok := cache.DefaultRemote().(*cache.Redis)
+		if !ok {
+			return nil, errors.New("redis事件日志需要配置cache.redis.addr")
+		}
+		return New(NewRedisLog(r, cfg.Retention), NewRedisOffsets(r)), nil
+	default:
+		return nil, fmt.Errorf("不支持的事件日志类型: %s", cfg.Driver)
+	}
+}
+
+// Default 返回全局事件总线，未初始化时创建内存事件总线
+func Default() *Bus {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultBus == nil {
+		defaultBus = New(NewMemoryLog(0), NewMemoryOffsets())
+	}
+	return defaultBus
+}
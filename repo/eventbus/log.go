@@ -0,0 +1,210 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 未配置时每个主题保留的事件数
+const defaultRetention = 100000
+
+// Event 事件，Offset在主题内从0开始单调递增
+type Event struct {
+	Topic   string          `json:"topic"`
+	Offset  uint64          `json:"offset"`
+	Key     string          `json:"key,omitempty"`
+	Time    int64           `json:"time"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// TopicStats 主题的偏移量范围
+type TopicStats struct {
+	First uint64 `json:"first"` // 最早仍保留的事件偏移量
+	Next  uint64 `json:"next"`  // 下一条事件的偏移量
+}
+
+// Log 按主题追加和读取事件的日志
+type Log interface {
+	// Append 追加一条事件并返回分配了偏移量的事件
+	Append(ctx context.Context, topic, key string, payload []byte) (Event, error)
+	// Read 读取偏移量不小于from的事件，最多limit条；from早于保留范围时从最早的事件开始
+	Read(ctx context.Context, topic string, from uint64, limit int) ([]Event, error)
+	// Stats 返回各主题的偏移量范围
+	Stats() map[string]TopicStats
+}
+
+// topicLog 单个主题在内存中保留的事件
+type topicLog struct {
+	events []Event
+	next   uint64
+}
+
+// MemoryLog 内存事件日志，每个主题保留最近retention条事件，重启后丢失
+type MemoryLog struct {
+	mu        sync.RWMutex
+	topics    map[string]*topicLog
+	retention int
+	// 追加前调用，文件日志借此先落盘
+	persist func(Event) error
+}
+
+// NewMemoryLog 创建内存事件日志
+func NewMemoryLog(retention int) *MemoryLog {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	return &MemoryLog{topics: make(map[string]*topicLog), retention: retention}
+}
+
+// Append 追加事件
+func (l *MemoryLog) Append(ctx context.Context, topic, key string, payload []byte) (Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t := l.topic(topic)
+	event := Event{Topic: topic, Offset: t.next, Key: key, Time: time.Now().Unix(), Payload: payload}
+	if l.persist != nil {
+		if err := l.persist(event); err != nil {
+			return Event{}, err
+		}
+	}
+	l.appendLocked(t, event)
+	return event, nil
+}
+
+// appendLocked 保存事件并裁剪超出保留数量的旧事件，调用方需持有写锁
+func (l *MemoryLog) appendLocked(t *topicLog, event Event) {
+	t.events = append(t.events, event)
+	t.next = event.Offset + 1
+	if excess := len(t.events) - l.retention; excess > 0 {
+		t.events = append(t.events[:0:0], t.events[excess:]...)
+	}
+}
+
+// Read 读取事件
+func (l *MemoryLog) Read(ctx context.Context, topic string, from uint64, limit int) ([]Event, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	t, ok := l.topics[topic]
+	if !ok || len(t.events) == 0 || from >= t.next {
+		return nil, nil
+	}
+	start := 0
+	if first := t.events[0].Offset; from > first {
+		start = int(from - first)
+	}
+	end := min(len(t.events), start+limit)
+	return append([]Event(nil), t.events[start:end]...), nil
+}
+
+// Stats 返回各主题的偏移量范围
+func (l *MemoryLog) Stats() map[string]TopicStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := make(map[string]TopicStats, len(l.topics))
+	for name, t := range l.topics {
+		s := TopicStats{First: t.next, Next: t.next}
+		if len(t.events) > 0 {
+			s.First = t.events[0].Offset
+		}
+		stats[name] = s
+	}
+	return stats
+}
+
+func (l *MemoryLog) topic(name string) *topicLog {
+	t, ok := l.topics[name]
+	if !ok {
+		t = &topicLog{}
+		l.topics[name] = t
+	}
+	return t
+}
+
+// NewFileLog 创建文件事件日志，每个主题一个NDJSON文件，启动时加载最近retention条事件
+// 重启后偏移量从文件中最后一条事件继续，消费者可以按提交的偏移量重放
+func NewFileLog(dir string, retention int) (*MemoryLog, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建事件目录失败: %w", err)
+	}
+
+	l := NewMemoryLog(retention)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.ndjson"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		topic := strings.TrimSuffix(filepath.Base(path), ".ndjson")
+		if err := l.load(path, topic); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make(map[string]*os.File)
+	l.persist = func(event Event) error {
+		file, ok := files[event.Topic]
+		if !ok {
+			if err := validateTopic(event.Topic); err != nil {
+				return err
+			}
+			file, err = os.OpenFile(filepath.Join(dir, event.Topic+".ndjson"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return fmt.Errorf("打开事件文件失败: %w", err)
+			}
+			files[event.Topic] = file
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("写入事件文件失败: %w", err)
+		}
+		return nil
+	}
+	return l, nil
+}
+
+// load 从事件文件中加载事件，末尾不完整的一行按写入中断处理并忽略
+func (l *MemoryLog) load(path, topic string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开事件文件失败: %w", err)
+	}
+	defer file.Close()
+
+	t := l.topic(topic)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		l.appendLocked(t, event)
+	}
+	return scanner.Err()
+}
+
+// validateTopic 主题名用作文件名，只允许字母、数字、下划线、连字符和点
+func validateTopic(topic string) error {
+	if topic == "" || strings.Trim(topic, ".") == "" {
+		return errors.New("主题名不能为空")
+	}
+	for _, r := range topic {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.') {
+			return fmt.Errorf("无效的主题名: %q", topic)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// OffsetStore 保存消费者在各主题上已处理到的位置
+type OffsetStore interface {
+	// Load 返回下一条需要处理的偏移量，没有记录时ok为false
+	Load(consumer, topic string) (next uint64, ok bool, err error)
+	// Commit 记录下一条需要处理的偏移量
+	Commit(consumer, topic string, next uint64) error
+	// All 返回全部消费者的偏移量，键为"消费者/主题"
+	All() map[string]uint64
+}
+
+// MemoryOffsets 内存偏移量存储
+type MemoryOffsets struct {
+	mu      sync.Mutex
+	offsets map[string]uint64
+	// 提交后调用，文件存储借此落盘，调用时持有锁
+	persist func(map[string]uint64) error
+}
+
+// NewMemoryOffsets 创建内存偏移量存储
+func NewMemoryOffsets() *MemoryOffsets {
+	return &MemoryOffsets{offsets: make(map[string]uint64)}
+}
+
+func offsetKey(consumer, topic string) string {
+	return consumer + "/" + topic
+}
+
+// Load 读取偏移量
+func (s *MemoryOffsets) Load(consumer, topic string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next, ok := s.offsets[offsetKey(consumer, topic)]
+	return next, ok, nil
+}
+
+// Commit 提交偏移量
+func (s *MemoryOffsets) Commit(consumer, topic string, next uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offsets[offsetKey(consumer, topic)] = next
+	if s.persist != nil {
+		return s.persist(s.offsets)
+	}
+	return nil
+}
+
+// All 返回全部偏移量
+func (s *MemoryOffsets) All() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make(map[string]uint64, len(s.offsets))
+	for key, next := range s.offsets {
+		all[key] = next
+	}
+	return all
+}
+
+// NewFileOffsets 创建文件偏移量存储，每次提交后整体写入临时文件再重命名
+func NewFileOffsets(path string) (*MemoryOffsets, error) {
+	s := NewMemoryOffsets()
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("读取偏移量文件失败: %w", err)
+	default:
+		if err := json.Unmarshal(data, &s.offsets); err != nil {
+			return nil, fmt.Errorf("解析偏移量文件失败: %w", err)
+		}
+	}
+
+	s.persist = func(offsets map[string]uint64) error {
+		data, err := json.Marshal(offsets)
+		if err != nil {
+			return err
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return fmt.Errorf("写入偏移量文件失败: %w", err)
+		}
+		return os.Rename(tmp, filepath.Clean(path))
+	}
+	return s, nil
+}
@@ -0,0 +1,218 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+)
+
+// Redis中事件日志和消费偏移量的键名，前缀之后拼接主题名
+const (
+	redisLogKeyPrefix = "eventbus:log:"
+	redisOffsetsKey   = "eventbus:offsets"
+)
+
+// 不带调用方上下文的Redis命令的超时时间
+const redisCommandTimeout = 3 * time.Second
+
+// RedisLog 基于Redis Stream的事件日志，多个实例共享同一份事件，需要Redis 7.0及以上
+// 每个主题一个Stream，事件ID为"0-序号"，序号由Redis在追加时原子分配，偏移量为序号减一
+// 其它实例发布的事件不会唤醒本实例的消费者，由投递循环按轮询周期读取
+type RedisLog struct {
+	redis     *cache.Redis
+	retention int64
+}
+
+// NewRedisLog 创建Redis事件日志，每个主题大约保留最近retention条事件
+func NewRedisLog(r *cache.Redis, retention int) *RedisLog {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	return &RedisLog{redis: r, retention: int64(retention)}
+}
+
+// Append 追加事件
+func (l *RedisLog) Append(ctx context.Context, topic, key string, payload []byte) (Event, error) {
+	event := Event{Topic: topic, Key: key, Time: time.Now().Unix(), Payload: payload}
+	id, err := l.redis.Client().XAdd(ctx, &redis.XAddArgs{
+		Stream: l.streamKey(topic),
+		MaxLen: l.retention,
+		Approx: true,
+		ID:     "0-*",
+		Values: []interface{}{"key", key, "time", event.Time, "payload", string(payload)},
+	}).Result()
+	if err != nil {
+		return Event{}, err
+	}
+	if event.Offset, err = streamOffset(id); err != nil {
+		return Event{}, err
+	}
+	return event, nil
+}
+
+// Read 读取事件
+func (l *RedisLog) Read(ctx context.Context, topic string, from uint64, limit int) ([]Event, error) {
+	messages, err := l.redis.Client().XRangeN(ctx, l.streamKey(topic), streamID(from), "+", int64(limit)).Result()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(messages))
+	for _, msg := range messages {
+		event, err := eventFromMessage(topic, msg)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Stats 返回各主题的偏移量范围，Redis出错时返回已读取到的部分
+func (l *RedisLog) Stats() map[string]TopicStats {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCommandTimeout)
+	defer cancel()
+
+	client := l.redis.Client()
+	prefix := l.redis.Key(redisLogKeyPrefix)
+	stats := make(map[string]TopicStats)
+	iter := client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		topic := strings.TrimPrefix(iter.Val(), prefix)
+		info, err := client.XInfoStream(ctx, iter.Val()).Result()
+		if err != nil {
+			log.Warnf("读取事件主题%s的状态失败: %v", topic, err)
+			continue
+		}
+		s, err := topicStats(info)
+		if err != nil {
+			log.Warnf("解析事件主题%s的状态失败: %v", topic, err)
+			continue
+		}
+		stats[topic] = s
+	}
+	if err := iter.Err(); err != nil {
+		log.Warnf("列出事件主题失败: %v", err)
+	}
+	return stats
+}
+
+func (l *RedisLog) streamKey(topic string) string {
+	return l.redis.Key(redisLogKeyPrefix + topic)
+}
+
+// streamID 返回偏移量对应的Stream事件ID
+func streamID(offset uint64) string {
+	return "0-" + strconv.FormatUint(offset+1, 10)
+}
+
+// streamOffset 返回Stream事件ID对应的偏移量
+func streamOffset(id string) (uint64, error) {
+	seq, ok := strings.CutPrefix(id, "0-")
+	if !ok {
+		return 0, fmt.Errorf("无效的事件ID: %s", id)
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("无效的事件ID: %s", id)
+	}
+	return n - 1, nil
+}
+
+// eventFromMessage 将Stream中的一条消息转换为事件
+func eventFromMessage(topic string, msg redis.XMessage) (Event, error) {
+	offset, err := streamOffset(msg.ID)
+	if err != nil {
+		return Event{}, err
+	}
+	event := Event{Topic: topic, Offset: offset}
+	event.Key, _ = msg.Values["key"].(string)
+	payload, _ := msg.Values["payload"].(string)
+	event.Payload = []byte(payload)
+	if t, ok := msg.Values["time"].(string); ok {
+		event.Time, _ = strconv.ParseInt(t, 10, 64)
+	}
+	return event, nil
+}
+
+// topicStats 根据Stream的状态计算主题的偏移量范围，最后分配的序号即下一条事件的偏移量
+func topicStats(info *redis.XInfoStream) (TopicStats, error) {
+	next, err := streamOffset(info.LastGeneratedID)
+	if err != nil {
+		if info.LastGeneratedID == "0-0" {
+			return TopicStats{}, nil
+		}
+		return TopicStats{}, err
+	}
+	next++
+	if info.Length == 0 {
+		return TopicStats{First: next, Next: next}, nil
+	}
+	first, err := streamOffset(info.FirstEntry.ID)
+	if err != nil {
+		return TopicStats{}, err
+	}
+	return TopicStats{First: first, Next: next}, nil
+}
+
+// RedisOffsets 保存在Redis哈希中的消费偏移量，多个实例上的同名消费者共享位置
+type RedisOffsets struct {
+	redis *cache.Redis
+}
+
+// NewRedisOffsets 创建Redis偏移量存储
+func NewRedisOffsets(r *cache.Redis) *RedisOffsets {
+	return &RedisOffsets{redis: r}
+}
+
+// Load 读取偏移量
+func (s *RedisOffsets) Load(consumer, topic string) (uint64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCommandTimeout)
+	defer cancel()
+
+	value, err := s.redis.Client().HGet(ctx, s.redis.Key(redisOffsetsKey), offsetKey(consumer, topic)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	next, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("解析偏移量失败: %w", err)
+	}
+	return next, true, nil
+}
+
+// Commit 提交偏移量
+func (s *RedisOffsets) Commit(consumer, topic string, next uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCommandTimeout)
+	defer cancel()
+	return s.redis.Client().HSet(ctx, s.redis.Key(redisOffsetsKey), offsetKey(consumer, topic), next).Err()
+}
+
+// All 返回全部偏移量，Redis出错时返回nil
+func (s *RedisOffsets) All() map[string]uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), redisCommandTimeout)
+	defer cancel()
+
+	values, err := s.redis.Client().HGetAll(ctx, s.redis.Key(redisOffsetsKey)).Result()
+	if err != nil {
+		log.Warnf("读取消费偏移量失败: %v", err)
+		return nil
+	}
+	all := make(map[string]uint64, len(values))
+	for key, value := range values {
+		if next, err := strconv.ParseUint(value, 10, 64); err == nil {
+			all[key] = next
+		}
+	}
+	return all
+}
@@ -1,16 +1,24 @@
 package repo
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"ginproject/entity/config"
 	"ginproject/middleware/log"
 	"ginproject/middleware/trace"
 	"ginproject/middleware/conf"
 
+	"ginproject/repo/analytics"
+	"ginproject/repo/cache"
 	"ginproject/repo/db"
+	"ginproject/repo/eventbus"
+	"ginproject/repo/notify"
 	"ginproject/repo/rpc/blockchain"
 	"ginproject/repo/rpc/electrumx"
+	"ginproject/repo/rpc/rpctrace"
 )
 
 // Global_init 全局初始化
@@ -46,6 +54,11 @@ func Global_init() error {
 	// 初始化追踪
 	trace.InitTracer(serverName)
 
+	// 按配置选择trace上下文带入上游调用的方式
+	if err := rpctrace.Init(config.GetConfig().GetTraceConfig()); err != nil {
+		log.Warnf("上游调用trace关联初始化失败，只记录旁路日志: %v", err)
+	}
+
 	// 初始化数据库连接
 	if err := db.Init(); err != nil {
 		return fmt.Errorf("数据库初始化失败: %w", err)
@@ -61,8 +74,76 @@ func Global_init() error {
 		log.Warnf("ElectrumX客户端初始化失败: %v", err)
 	}
 
+	// 初始化分析存储，失败时统计接口继续使用MySQL
+	if err := analytics.Init(); err != nil {
+		log.Warnf("分析存储初始化失败: %v", err)
+	}
+
+	// 连接共享缓存，失败时只使用进程内缓存；集群模式下跨实例状态依赖Redis，失败时终止启动
+	if err := cache.InitRemote(); err != nil {
+		if cache.ClusterEnabled() {
+			return fmt.Errorf("集群模式共享缓存初始化失败: %w", err)
+		}
+		log.Warnf("共享缓存初始化失败，只使用进程内缓存: %v", err)
+	}
+
+	// 初始化事件总线，redis事件日志使用共享缓存的连接；失败时使用内存事件日志
+	if err := eventbus.Init(); err != nil {
+		log.Warnf("事件总线初始化失败，使用内存事件日志: %v", err)
+	}
+
+	// 初始化钱包通知渠道，失败时不发送通知
+	if err := notify.Init(); err != nil {
+		log.Warnf("通知渠道初始化失败: %v", err)
+	}
+
+	// 预热上游连接池
+	warmUpPools()
+
 	// 记录初始化成功日志
 	log.Info("全局配置和日志初始化成功")
 
 	return nil
 }
+
+// warmUpPools 按配置预热节点和ElectrumX连接池，失败只记录警告
+func warmUpPools() {
+	nodeConfig := config.GetConfig().GetTBCNodeConfig()
+	electrumXConfig := config.GetConfig().GetElectrumXConfig()
+
+	var wg sync.WaitGroup
+
+	if nodeConfig.WarmUpConns > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), warmUpTimeout(nodeConfig.Timeout))
+			defer cancel()
+			if err := blockchain.WarmUp(ctx, nodeConfig.WarmUpConns); err != nil {
+				log.Warnf("区块链节点连接池预热失败: %v", err)
+			}
+		}()
+	}
+
+	if electrumXConfig.WarmUpConns > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), warmUpTimeout(electrumXConfig.Timeout))
+			defer cancel()
+			if err := electrumx.WarmUp(ctx, electrumXConfig.WarmUpConns); err != nil {
+				log.Warnf("ElectrumX连接池预热失败: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// warmUpTimeout 预热超时时间，未配置时默认10秒
+func warmUpTimeout(timeoutSeconds int) time.Duration {
+	if timeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(timeoutSeconds) * time.Second
+}
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+// 图标抓取默认参数
+const (
+	defaultIconMaxBytes     = 512 << 10
+	defaultIconMaxDimension = 1024
+	defaultIconTimeout      = 10 * time.Second
+	maxIconRedirects        = 3
+)
+
+// ErrIconRejected 远程图标未通过校验，包括地址不允许访问、类型不支持、超过大小或尺寸限制
+var ErrIconRejected = errors.New("图标未通过校验")
+
+// iconFormats 允许的响应类型及对应的图片格式
+var iconFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+}
+
+// IconOptions 图标抓取参数，零值字段使用默认值
+type IconOptions struct {
+	MaxBytes     int64         // 响应体大小上限
+	MaxDimension int           // 宽和高的上限(像素)
+	Timeout      time.Duration // 单次抓取的超时时间，包括重定向
+	AllowPrivate bool          // 是否允许访问回环、内网和链路本地地址，仅用于测试
+}
+
+// Icon 校验并重新编码为PNG的图标
+type Icon struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// IconFetcher 抓取外部图标的HTTP客户端
+// 只访问公网http(s)地址，校验响应类型、大小和尺寸后解码并重新编码为PNG，
+// 返回的内容不包含原始文件中的元数据和图片数据之外夹带的内容
+type IconFetcher struct {
+	client *http.Client
+	opts   IconOptions
+}
+
+// NewIconFetcher 创建图标抓取客户端
+func NewIconFetcher(opts IconOptions) *IconFetcher {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultIconMaxBytes
+	}
+	if opts.MaxDimension <= 0 {
+		opts.MaxDimension = defaultIconMaxDimension
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultIconTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		// 在解析出IP之后检查，域名解析到内网地址或重定向到内网地址时同样拒绝
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			return checkIconAddress(address)
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// 不使用环境变量中的代理，否则地址检查作用于代理而不是图标所在的服务器
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &IconFetcher{
+		client: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxIconRedirects {
+					return fmt.Errorf("%w: 重定向次数过多", ErrIconRejected)
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("%w: 不允许重定向到%s地址", ErrIconRejected, req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		opts: opts,
+	}
+}
+
+// specialPrefixes 不允许访问的特殊用途地址段，参照IANA IPv4和IPv6特殊用途地址注册表
+// 包括本网、内网、运营商级NAT、回环、链路本地、协议分配、文档、基准测试、组播和保留地址
+var specialPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("fec0::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// 内嵌IPv4地址的IPv6地址段，按内嵌的IPv4地址检查
+var (
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFour   = netip.MustParsePrefix("2002::/16")
+)
+
+// checkIconAddress 只允许访问全局单播地址，拒绝特殊用途地址段
+// NAT64和6to4地址按内嵌的IPv4地址检查，避免经转换网关访问内网
+func checkIconAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: 无效的地址%s", ErrIconRejected, address)
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: 无效的地址%s", ErrIconRejected, address)
+	}
+	if !isPublicAddr(ip) {
+		return fmt.Errorf("%w: 不允许访问内网地址%s", ErrIconRejected, ip)
+	}
+	return nil
+}
+
+// isPublicAddr 判断地址是否为可以访问的全局单播地址
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.WithZone("").Unmap()
+	if ip.Is6() {
+		b := ip.As16()
+		switch {
+		case nat64Prefix.Contains(ip):
+			ip = netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]})
+		case sixToFour.Contains(ip):
+			ip = netip.AddrFrom4([4]byte{b[2], b[3], b[4], b[5]})
+		}
+	}
+	if !ip.IsGlobalUnicast() {
+		return false
+	}
+	for _, prefix := range specialPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetch 抓取并校验图标，未通过校验时返回包装ErrIconRejected的错误
+func (f *IconFetcher) Fetch(ctx context.Context, rawURL string) (*Icon, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: 仅支持http或https地址", ErrIconRejected)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "image/png, image/jpeg, image/gif")
+
+	log.InfoWithContext(ctx, "抓取代币图标", "URL", rawURL)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP响应状态码%d", ErrIconRejected, resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	format, ok := iconFormats[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的图标类型%q", ErrIconRejected, mediaType)
+	}
+	if resp.ContentLength > f.opts.MaxBytes {
+		return nil, fmt.Errorf("%w: 图标大小超过%d字节", ErrIconRejected, f.opts.MaxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取HTTP响应体失败: %w", err)
+	}
+	if int64(len(data)) > f.opts.MaxBytes {
+		return nil, fmt.Errorf("%w: 图标大小超过%d字节", ErrIconRejected, f.opts.MaxBytes)
+	}
+	return f.reencode(data, format)
+}
+
+// reencode 校验图片的实际格式和尺寸后解码并重新编码为PNG
+// 先只读取图片头检查尺寸，避免解码尺寸巨大的图片占用大量内存
+func (f *IconFetcher) reencode(data []byte, declared string) (*Icon, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无法识别的图片: %v", ErrIconRejected, err)
+	}
+	if format != declared {
+		return nil, fmt.Errorf("%w: 图片格式%s与响应类型不符", ErrIconRejected, format)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > f.opts.MaxDimension || cfg.Height > f.opts.MaxDimension {
+		return nil, fmt.Errorf("%w: 图片尺寸%dx%d超过%d像素", ErrIconRejected, cfg.Width, cfg.Height, f.opts.MaxDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 解码图片失败: %v", ErrIconRejected, err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("编码图标失败: %w", err)
+	}
+	return &Icon{Data: buf.Bytes(), Width: cfg.Width, Height: cfg.Height}, nil
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// encodeTestImage 生成指定尺寸的测试图片
+func encodeTestImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, nil)
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestIconFetcherValidates(t *testing.T) {
+	jpegIcon := encodeTestImage(t, "jpeg", 32, 32)
+	largeIcon := encodeTestImage(t, "png", 64, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/icon.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(jpegIcon)
+		case "/redirect":
+			http.Redirect(w, r, "/icon.jpg", http.StatusFound)
+		case "/icon.svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			w.Write([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`))
+		case "/mismatch.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(jpegIcon)
+		case "/large.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(largeIcon)
+		case "/huge.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(make([]byte, 4096))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewIconFetcher(IconOptions{MaxBytes: 2048, MaxDimension: 48, AllowPrivate: true})
+	defer fetcher.client.CloseIdleConnections()
+
+	for _, path := range []string{"/icon.jpg", "/redirect"} {
+		icon, err := fetcher.Fetch(context.Background(), server.URL+path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if icon.Width != 32 || icon.Height != 32 {
+			t.Errorf("%s: 尺寸 = %dx%d", path, icon.Width, icon.Height)
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(icon.Data)); err != nil || format != "png" {
+			t.Errorf("%s: 应重新编码为PNG: format=%s err=%v", path, format, err)
+		}
+	}
+
+	for _, path := range []string{"/icon.svg", "/mismatch.png", "/large.png", "/huge.png", "/missing.png"} {
+		if _, err := fetcher.Fetch(context.Background(), server.URL+path); !errors.Is(err, ErrIconRejected) {
+			t.Errorf("%s: 应拒绝, err=%v", path, err)
+		}
+	}
+	if _, err := fetcher.Fetch(context.Background(), "file:///etc/passwd"); !errors.Is(err, ErrIconRejected) {
+		t.Errorf("非http地址应拒绝: %v", err)
+	}
+}
+
+func TestIconFetcherRejectsPrivateAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("不应访问内网地址")
+	}))
+	defer server.Close()
+
+	fetcher := NewIconFetcher(IconOptions{})
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/icon.png"); !errors.Is(err, ErrIconRejected) {
+		t.Errorf("内网地址应拒绝: %v", err)
+	}
+
+	cases := []struct {
+		address string
+		allowed bool
+	}{
+		{"8.8.8.8:443", true},
+		{"[2606:4700::1111]:443", true},
+		{"10.0.0.1:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"127.0.0.1:80", false},
+		{"169.254.169.254:80", false},
+		{"100.64.0.1:80", false},
+		{"100.100.100.200:80", false},
+		{"0.0.0.0:80", false},
+		{"0.1.2.3:80", false},
+		{"192.0.0.8:80", false},
+		{"198.18.0.1:80", false},
+		{"198.19.255.255:80", false},
+		{"203.0.113.5:80", false},
+		{"224.0.0.1:80", false},
+		{"255.255.255.255:80", false},
+		{"[::]:80", false},
+		{"[::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[::ffff:100.100.100.200]:80", false},
+		{"[::127.0.0.1]:80", false},
+		{"[fc00::1]:80", false},
+		{"[fe80::1%eth0]:80", false},
+		{"[ff02::1]:80", false},
+		{"[2001:db8::1]:80", false},
+		{"[2001::1]:80", false},
+		// NAT64和6to4地址按内嵌的IPv4地址判断
+		{"[64:ff9b::a00:1]:80", false},
+		{"[64:ff9b::6464:64c8]:80", false},
+		{"[64:ff9b::808:808]:443", true},
+		{"[64:ff9b:1::a00:1]:80", false},
+		{"[2002:a00:1::1]:80", false},
+		{"[2002:7f00:1::1]:80", false},
+		{"[2002:808:808::1]:443", true},
+	}
+	for _, c := range cases {
+		if err := checkIconAddress(c.address); (err == nil) != c.allowed {
+			t.Errorf("checkIconAddress(%s) = %v, 期望允许: %v", c.address, err, c.allowed)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+)
+
+// 支持的通知渠道
+const ChannelEmail = "email"
+
+// Message 一条待发送的通知
+type Message struct {
+	To      string // 接收方，email渠道为邮箱地址
+	Subject string
+	Body    string // 纯文本正文
+}
+
+// Notifier 通知渠道，新的渠道实现该接口后通过Register注册
+type Notifier interface {
+	// Channel 返回渠道名称
+	Channel() string
+	// Send 发送一条通知
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	mu        sync.RWMutex
+	notifiers = make(map[string]Notifier)
+)
+
+// Init 按配置注册通知渠道，未配置的渠道不启用
+func Init() error {
+	cfg := config.GetConfig().GetNotifyConfig()
+	if cfg.SMTP.Host == "" {
+		return nil
+	}
+
+	notifier, err := NewSMTPNotifier(&cfg.SMTP)
+	if err != nil {
+		return err
+	}
+	Register(notifier)
+	log.Info("邮件通知已启用", "host:", cfg.SMTP.Host, "port:", cfg.SMTP.Port)
+	return nil
+}
+
+// Register 注册通知渠道，同名渠道会被替换
+func Register(n Notifier) {
+	mu.Lock()
+	defer mu.Unlock()
+	notifiers[n.Channel()] = n
+}
+
+// Get 返回指定渠道的通知实现，渠道未启用时返回false
+func Get(channel string) (Notifier, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	n, ok := notifiers[channel]
+	return n, ok
+}
+
+// Channels 返回已启用的渠道名称
+func Channels() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	channels := make([]string, 0, len(notifiers))
+	for channel := range notifiers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"ginproject/entity/config"
+)
+
+// 未配置时单封邮件的发送超时时间
+const defaultSMTPTimeout = 10 * time.Second
+
+// 使用TLS直连的SMTP端口
+const smtpsPort = 465
+
+// SMTPNotifier 通过SMTP发送邮件通知
+type SMTPNotifier struct {
+	host     string
+	addr     string
+	from     string
+	username string
+	password string
+	implicit bool // 是否使用TLS直连，否则在服务端支持时升级STARTTLS
+	timeout  time.Duration
+}
+
+// NewSMTPNotifier 根据配置创建邮件通知渠道
+func NewSMTPNotifier(cfg *config.SMTPConfig) (*SMTPNotifier, error) {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("无效的发件人地址%q: %w", cfg.From, err)
+	}
+	port := cfg.Port
+	if port <= 0 {
+		port = 587
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+
+	return &SMTPNotifier{
+		host:     cfg.Host,
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		from:     cfg.From,
+		username: cfg.Username,
+		password: cfg.Password,
+		implicit: port == smtpsPort,
+		timeout:  timeout,
+	}, nil
+}
+
+// Channel 返回渠道名称
+func (n *SMTPNotifier) Channel() string {
+	return ChannelEmail
+}
+
+// Send 发送一封纯文本邮件
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	conn, err := n.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("建立SMTP会话失败: %w", err)
+	}
+	defer client.Close()
+
+	if !n.implicit {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+				return fmt.Errorf("SMTP升级TLS失败: %w", err)
+			}
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(n.from)
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP设置发件人失败: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("SMTP设置收件人失败: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP发送邮件内容失败: %w", err)
+	}
+	if _, err := w.Write(buildMessage(n.from, msg, time.Now())); err != nil {
+		w.Close()
+		return fmt.Errorf("SMTP发送邮件内容失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP发送邮件内容失败: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial 建立到SMTP服务的连接，465端口直接使用TLS
+func (n *SMTPNotifier) dial(ctx context.Context) (net.Conn, error) {
+	if n.implicit {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: n.host}}
+		return dialer.DialContext(ctx, "tcp", n.addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", n.addr)
+}
+
+// buildMessage 生成UTF-8编码的纯文本邮件，主题按RFC 2047编码
+func buildMessage(from string, msg Message, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.Write(bytes.ReplaceAll(bytes.ReplaceAll([]byte(msg.Body), []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n")))
+	return buf.Bytes()
+}
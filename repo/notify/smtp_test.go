@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage(t *testing.T) {
+	msg := Message{To: "user@example.com", Subject: "钱包有新交易", Body: "第一行\n第二行"}
+	raw := string(buildMessage("TBC <noreply@example.com>", msg, time.Unix(1700000000, 0).UTC()))
+
+	if !strings.Contains(raw, "Subject: =?UTF-8?b?") {
+		t.Errorf("主题未按RFC 2047编码:\n%s", raw)
+	}
+	if !strings.Contains(raw, "To: user@example.com\r\n") {
+		t.Errorf("缺少收件人:\n%s", raw)
+	}
+	if !strings.HasSuffix(raw, "\r\n\r\n第一行\r\n第二行") {
+		t.Errorf("正文换行未转换为CRLF:\n%q", raw)
+	}
+}
@@ -0,0 +1,114 @@
+package async
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ProducerStats 同名生产者的运行统计
+type ProducerStats struct {
+	Name     string        `json:"name"`
+	Running  int           `json:"running"`             // 正在运行的生产者数
+	Started  uint64        `json:"started"`             // 累计启动的生产者数
+	Canceled uint64        `json:"canceled"`            // 结束时上下文已取消的生产者数，即调用方已放弃等待
+	Oldest   time.Duration `json:"oldest_ns,omitempty"` // 运行最久的生产者已运行的时长
+}
+
+// producerStats 单个名称的统计，running记录各运行中生产者的启动时间
+type producerStats struct {
+	running  map[uint64]time.Time
+	started  uint64
+	canceled uint64
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = make(map[string]*producerStats)
+	nextID  uint64
+)
+
+// Go 在新协程中执行生产者，通过容量为1的通道返回其结果，结果送出后关闭通道
+// 生产者收到的上下文派生自ctx并在生产者返回时取消，调用方取消ctx时生产者中的上游调用随之中止，
+// 生产者自身发起的子请求也不会在其返回后继续运行；同名生产者的数量和运行时长计入统计
+func Go[T any](ctx context.Context, name string, produce func(ctx context.Context) T) <-chan T {
+	resultChan := make(chan T, 1)
+	id := begin(name)
+
+	go func() {
+		defer close(resultChan)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		result := produce(ctx)
+		end(name, id, ctx.Err() != nil)
+		resultChan <- result
+	}()
+
+	return resultChan
+}
+
+// begin 记录一个生产者启动，返回其编号
+func begin(name string) uint64 {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	s, ok := stats[name]
+	if !ok {
+		s = &producerStats{running: make(map[uint64]time.Time)}
+		stats[name] = s
+	}
+	nextID++
+	s.running[nextID] = time.Now()
+	s.started++
+	return nextID
+}
+
+// end 记录一个生产者结束
+func end(name string, id uint64, canceled bool) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	s := stats[name]
+	delete(s.running, id)
+	if canceled {
+		s.canceled++
+	}
+}
+
+// Running 返回正在运行的生产者总数
+func Running() int {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	total := 0
+	for _, s := range stats {
+		total += len(s.running)
+	}
+	return total
+}
+
+// Stats 返回各名称生产者的统计，正在运行的排在前面，其次按名称排序
+func Stats() []ProducerStats {
+	statsMu.Lock()
+	now := time.Now()
+	result := make([]ProducerStats, 0, len(stats))
+	for name, s := range stats {
+		item := ProducerStats{Name: name, Running: len(s.running), Started: s.started, Canceled: s.canceled}
+		for _, startedAt := range s.running {
+			item.Oldest = max(item.Oldest, now.Sub(startedAt))
+		}
+		result = append(result, item)
+	}
+	statsMu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Running != result[j].Running {
+			return result[i].Running > result[j].Running
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
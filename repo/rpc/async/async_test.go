@@ -0,0 +1,83 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestGoCancelsProducerWithCaller(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	resultChan := Go(ctx, "test.blocked", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	<-started
+	if Running() != 1 {
+		t.Errorf("Running = %d, want 1", Running())
+	}
+
+	// 调用方放弃等待后生产者随之退出，结果仍可读取且通道被关闭
+	cancel()
+	select {
+	case err := <-resultChan:
+		if err != context.Canceled {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("取消后生产者未退出")
+	}
+	if _, ok := <-resultChan; ok {
+		t.Error("结果送出后通道应关闭")
+	}
+
+	stats := findStats(t, "test.blocked")
+	if stats.Running != 0 || stats.Started != 1 || stats.Canceled != 1 {
+		t.Errorf("统计错误: %+v", stats)
+	}
+}
+
+func TestGoCancelsChildWork(t *testing.T) {
+	// 生产者返回后其派生的子请求被取消，不会继续运行
+	childDone := make(chan struct{})
+	result := <-Go(context.Background(), "test.child", func(ctx context.Context) int {
+		go func() {
+			<-ctx.Done()
+			close(childDone)
+		}()
+		return 42
+	})
+	if result != 42 {
+		t.Errorf("result = %d", result)
+	}
+
+	select {
+	case <-childDone:
+	case <-time.After(time.Second):
+		t.Fatal("生产者返回后子请求的上下文未取消")
+	}
+	if stats := findStats(t, "test.child"); stats.Canceled != 0 {
+		t.Errorf("正常结束的生产者不应计为取消: %+v", stats)
+	}
+}
+
+// findStats 返回指定名称的生产者统计
+func findStats(t *testing.T, name string) ProducerStats {
+	t.Helper()
+	for _, s := range Stats() {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("没有%s的统计", name)
+	return ProducerStats{}
+}
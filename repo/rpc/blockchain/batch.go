@@ -0,0 +1,185 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ginproject/entity/apperror"
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/rpctrace"
+)
+
+// 未配置时单个批量请求包含的最大调用数
+const defaultBatchSize = 100
+
+// RPCCall 批量请求中的一次调用
+type RPCCall struct {
+	Method string
+	Params interface{}
+}
+
+// CallRPCBatch 将多次调用合并为JSON-RPC批量请求发送给节点，结果与calls一一对应
+// 超过批量上限时拆分为多个请求依次发送；单个调用失败记录在对应结果的Error中，请求本身失败时返回error
+func CallRPCBatch(ctx context.Context, calls []RPCCall) ([]AsyncResult, error) {
+	results := make([]AsyncResult, 0, len(calls))
+	size := batchSize()
+	for start := 0; start < len(calls); start += size {
+		end := min(start+size, len(calls))
+		chunk, err := callBatch(ctx, calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
+
+// batchSize 返回配置的批量上限
+func batchSize() int {
+	if cfg := config.GetConfig().GetTBCNodeConfig(); cfg != nil && cfg.BatchSize > 0 {
+		return cfg.BatchSize
+	}
+	return defaultBatchSize
+}
+
+// callBatch 发送一个批量请求，连接池未初始化时使用临时客户端
+// 批量请求按首个调用的方法单独计入断路器，不影响同名方法的单个调用
+func callBatch(ctx context.Context, calls []RPCCall) ([]AsyncResult, error) {
+	if globalConnPool != nil {
+		start := time.Now()
+		var results []AsyncResult
+		err := withBreaker(ctx, "batch:"+calls[0].Method, func(ctx context.Context) error {
+			var err error
+			results, err = globalConnPool.CallBatch(ctx, calls)
+			return err
+		})
+		observe(ctx, start, err)
+		return results, err
+	}
+
+	cfg := config.GetConfig().GetTBCNodeConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("RPC配置未初始化")
+	}
+	client := &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second, Transport: nodeTransport}
+	return sendBatch(ctx, client, cfg, nodeURL(cfg), calls)
+}
+
+// CallBatch 使用连接池中的连接发送批量请求，结果计入节点的健康状态
+func (p *ConnPool) CallBatch(ctx context.Context, calls []RPCCall) ([]AsyncResult, error) {
+	conn, err := p.GetConn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取连接失败: %w", err)
+	}
+	defer p.PutConn(conn)
+
+	target := nodeURL(conn.config)
+	results, err := sendBatch(ctx, conn.client, conn.config, target, calls)
+	reportNode(ctx, target, err)
+	if err != nil {
+		conn.isInvalid = true
+	}
+	return results, err
+}
+
+// sendBatch 序列化批量请求发往target并解析响应，请求ID为调用在批次中的下标
+func sendBatch(ctx context.Context, client *http.Client, cfg *config.TBCNodeConfig, target string, calls []RPCCall) ([]AsyncResult, error) {
+	requests := make([]RPCRequest, len(calls))
+	for i, call := range calls {
+		requests[i] = RPCRequest{JSONRPC: "1.0", ID: strconv.Itoa(i), Method: call.Method, Params: call.Params}
+	}
+	reqBody, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("序列化批量RPC请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.User != "" && cfg.Password != "" {
+		req.SetBasicAuth(cfg.User, cfg.Password)
+	}
+	// 批量请求的ID用于还原调用顺序，trace上下文只通过请求头和调用记录携带
+	rpctrace.InjectHeader(ctx, req.Header)
+
+	log.DebugWithContext(ctx, "发送区块链批量RPC请求", "count", len(calls))
+	start := time.Now()
+	results, err := doBatch(client, req, len(calls))
+	rpctrace.Record(ctx, rpctrace.UpstreamNode, batchMethod(calls), "batch", start, err)
+	return results, err
+}
+
+// doBatch 发送批量请求并解析响应
+func doBatch(client *http.Client, req *http.Request, count int) ([]AsyncResult, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送批量RPC请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取批量RPC响应失败: %w", err)
+	}
+	return decodeBatchResponse(respBody, count)
+}
+
+// batchMethod 调用记录中批量请求的方法名，形如getrawtransaction×20
+func batchMethod(calls []RPCCall) string {
+	if len(calls) == 0 {
+		return "batch"
+	}
+	return calls[0].Method + "×" + strconv.Itoa(len(calls))
+}
+
+// decodeBatchResponse 按请求ID将批量响应还原为调用顺序，节点可能乱序返回，缺失的响应记为错误
+func decodeBatchResponse(body []byte, count int) ([]AsyncResult, error) {
+	var responses []RPCResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// 节点拒绝整个批量请求时返回单个错误对象
+		var single RPCResponse
+		if json.Unmarshal(body, &single) == nil && single.Error != nil {
+			return nil, fmt.Errorf("批量RPC请求失败: %w", rpcCallError(single.Error))
+		}
+		log.Errorf("解析批量RPC响应失败: %s", string(body))
+		return nil, fmt.Errorf("解析批量RPC响应失败: %w", err)
+	}
+
+	results := make([]AsyncResult, count)
+	received := make([]bool, count)
+	for _, r := range responses {
+		i, err := strconv.Atoi(r.ID)
+		if err != nil || i < 0 || i >= count {
+			continue
+		}
+		received[i] = true
+		if r.Error != nil {
+			results[i].Error = rpcCallError(r.Error)
+			continue
+		}
+		results[i].Result = r.Result
+	}
+	for i, ok := range received {
+		if !ok {
+			results[i].Error = fmt.Errorf("批量RPC响应缺少第%d个调用的结果", i)
+		}
+	}
+	return results, nil
+}
+
+// rpcCallError 将节点返回的错误转换为error，交易或区块不存在时包装apperror.ErrNotFound
+func rpcCallError(e *RPCError) error {
+	if e.Code == rpcErrCodeNotFound {
+		return fmt.Errorf("RPC调用错误: %w: %w", e, apperror.ErrNotFound)
+	}
+	return fmt.Errorf("RPC调用错误: %w", e)
+}
@@ -0,0 +1,38 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"ginproject/entity/apperror"
+)
+
+func TestDecodeBatchResponse(t *testing.T) {
+	// 节点乱序返回，第2个调用失败，第3个调用没有响应
+	body := []byte(`[
+		{"id":"1","result":null,"error":{"code":-5,"message":"No such mempool or blockchain transaction"}},
+		{"id":"0","result":"00ff","error":null},
+		{"id":"9","result":"ignored","error":null}
+	]`)
+
+	results, err := decodeBatchResponse(body, 3)
+	if err != nil {
+		t.Fatalf("解析批量响应失败: %v", err)
+	}
+	if results[0].Error != nil || results[0].Result != "00ff" {
+		t.Errorf("第0个结果 = %+v, 期望00ff", results[0])
+	}
+	if !errors.Is(results[1].Error, apperror.ErrNotFound) {
+		t.Errorf("第1个结果错误 = %v, 期望包装apperror.ErrNotFound", results[1].Error)
+	}
+	if results[2].Error == nil {
+		t.Error("缺失响应的调用应返回错误")
+	}
+}
+
+func TestDecodeBatchResponseRejected(t *testing.T) {
+	body := []byte(`{"id":null,"result":null,"error":{"code":-32600,"message":"Invalid Request object"}}`)
+	if _, err := decodeBatchResponse(body, 2); err == nil {
+		t.Fatal("节点拒绝整个批量请求时应返回错误")
+	}
+}
@@ -3,33 +3,32 @@ package blockchain
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"ginproject/entity/apperror"
 	"ginproject/entity/broadcast"
 	"ginproject/middleware/log"
+	"ginproject/middleware/schemawatch"
+	"ginproject/repo/rpc/async"
 )
 
 // SendRawTransaction 发送原始交易
 func SendRawTransaction(ctx context.Context, txHex string, allowHighFees bool, bypassLimits bool) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.SendRawTransaction", func(ctx context.Context) AsyncResult {
 		// 记录开始调用日志
 		log.InfoWithContext(ctx, "开始广播原始交易", "txHexLength", len(txHex))
 
 		// 使用异步方式调用RPC
-		asyncChan := CallRPCAsync(ctx, "sendrawtransaction", []interface{}{txHex, allowHighFees, bypassLimits}, false)
+		asyncChan := CallRPCAsync(ctx, RpcMethodSendRawTransaction, []interface{}{txHex, allowHighFees, bypassLimits}, false)
 		asyncResult := <-asyncChan
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "广播原始交易失败", "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("广播原始交易失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
@@ -38,30 +37,82 @@ func SendRawTransaction(ctx context.Context, txHex string, allowHighFees bool, b
 		txid, ok := result.(string)
 		if !ok {
 			log.ErrorWithContext(ctx, "解析交易ID失败", "result", result)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解析交易ID失败"),
 			}
-			return
 		}
 
 		log.InfoWithContext(ctx, "成功广播原始交易", "txid", txid)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: txid,
 			Error:  nil,
 		}
-	}()
+	})
+}
 
-	return resultChan
+// SendRawTransactionBatch 在JSON-RPC批量请求中逐笔发送原始交易，结果与txHexes一一对应
+// 节点拒绝的交易在对应结果的Error中说明，请求本身失败时返回error
+func SendRawTransactionBatch(ctx context.Context, txHexes []string) ([]*broadcast.BroadcastResponse, error) {
+	calls := make([]RPCCall, len(txHexes))
+	for i, txHex := range txHexes {
+		calls[i] = RPCCall{Method: RpcMethodSendRawTransaction, Params: []interface{}{txHex, false, false}}
+	}
+	results, err := CallRPCBatch(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("批量广播原始交易失败: %w", err)
+	}
+
+	responses := make([]*broadcast.BroadcastResponse, len(results))
+	for i, result := range results {
+		if result.Error != nil {
+			responses[i] = &broadcast.BroadcastResponse{Error: broadcastError(result.Error)}
+			continue
+		}
+		txid, ok := result.Result.(string)
+		if !ok {
+			responses[i] = &broadcast.BroadcastResponse{Error: &broadcast.BroadcastError{Code: -1, Message: "解析交易ID失败"}}
+			continue
+		}
+		responses[i] = &broadcast.BroadcastResponse{Result: txid}
+	}
+	return responses, nil
 }
 
-// SendRawTransactions 批量发送原始交易
-func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
+// SendToAddress 由节点钱包向指定地址转账，返回交易ID
+func SendToAddress(ctx context.Context, address string, amount float64) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.SendToAddress", func(ctx context.Context) AsyncResult {
+		log.InfoWithContext(ctx, "开始由节点钱包转账", "address", address, "amount", amount)
+
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodSendToAddress, []interface{}{address, amount}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "节点钱包转账失败", "error", asyncResult.Error)
+			return AsyncResult{
+				Result: nil,
+				Error:  fmt.Errorf("节点钱包转账失败: %w", asyncResult.Error),
+			}
+		}
 
-	go func() {
-		defer close(resultChan)
+		txid, ok := asyncResult.Result.(string)
+		if !ok {
+			log.ErrorWithContext(ctx, "解析交易ID失败", "result", asyncResult.Result)
+			return AsyncResult{
+				Result: nil,
+				Error:  fmt.Errorf("解析交易ID失败"),
+			}
+		}
 
+		log.InfoWithContext(ctx, "节点钱包转账成功", "txid", txid)
+		return AsyncResult{
+			Result: txid,
+			Error:  nil,
+		}
+	})
+}
+
+// SendRawTransactions 批量发送原始交易
+func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.SendRawTransactions", func(ctx context.Context) AsyncResult {
 		// 记录开始调用日志
 		log.InfoWithContext(ctx, "开始批量广播原始交易", "count", len(txList))
 
@@ -76,7 +127,7 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 
 			if singleResult.Error != nil {
 				log.ErrorWithContext(ctx, "单笔交易广播失败", "error", singleResult.Error)
-				resultChan <- AsyncResult{
+				return AsyncResult{
 					Result: &broadcast.TxsBroadcastResponse{
 						Error: &broadcast.BroadcastError{
 							Code:    -1,
@@ -85,7 +136,6 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 					},
 					Error: nil,
 				}
-				return
 			}
 
 			// 处理单笔交易的结果
@@ -95,13 +145,12 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 					Invalid: []broadcast.InvalidTx{},
 				}
 				log.InfoWithContext(ctx, "单笔交易广播成功", "txid", txid)
-				resultChan <- AsyncResult{
+				return AsyncResult{
 					Result: &broadcast.TxsBroadcastResponse{
 						Result: txsBroadcastResult,
 					},
 					Error: nil,
 				}
-				return
 			}
 		}
 
@@ -111,7 +160,7 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "批量广播原始交易失败", "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: &broadcast.TxsBroadcastResponse{
 					Error: &broadcast.BroadcastError{
 						Code:    -1,
@@ -120,7 +169,6 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 				},
 				Error: nil,
 			}
-			return
 		}
 
 		result := asyncResult.Result
@@ -138,7 +186,7 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 			resultBytes, err := json.Marshal(result)
 			if err != nil {
 				log.ErrorWithContext(ctx, "序列化批量广播结果失败", "error", err)
-				resultChan <- AsyncResult{
+				return AsyncResult{
 					Result: &broadcast.TxsBroadcastResponse{
 						Error: &broadcast.BroadcastError{
 							Code:    -1,
@@ -147,12 +195,11 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 					},
 					Error: nil,
 				}
-				return
 			}
 
 			if err := json.Unmarshal(resultBytes, &resultMap); err != nil {
 				log.ErrorWithContext(ctx, "解析批量广播结果失败", "error", err)
-				resultChan <- AsyncResult{
+				return AsyncResult{
 					Result: &broadcast.TxsBroadcastResponse{
 						Error: &broadcast.BroadcastError{
 							Code:    -1,
@@ -161,7 +208,6 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 					},
 					Error: nil,
 				}
-				return
 			}
 			// 打印完整的结果结构
 			log.InfoWithContext(ctx, "批量广播返回结果(JSON解析后)", "result", resultMap)
@@ -247,13 +293,88 @@ func SendRawTransactions(ctx context.Context, txList []map[string]interface{}) <
 			"txids", txsBroadcastResult.TxIDs,
 			"invalid", len(txsBroadcastResult.Invalid))
 
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: &broadcast.TxsBroadcastResponse{
 				Result: txsBroadcastResult,
 			},
 			Error: nil,
 		}
-	}()
+	})
+}
+
+// RpcMethodTestMempoolAccept 检查交易能否被内存池接受而不广播
+const RpcMethodTestMempoolAccept = "testmempoolaccept"
+
+// 节点不支持某个RPC方法时返回的错误码（RPC_METHOD_NOT_FOUND）
+const rpcErrCodeMethodNotFound = -32601
+
+// PrecheckRawTransaction 在一次批量请求中对未广播的交易执行解码、链上查询和内存池接受检查
+// 各项检查的失败记录在结果中，节点不支持testmempoolaccept时Accept为nil
+func PrecheckRawTransaction(ctx context.Context, txHex string, txid string) (*broadcast.NodePrecheck, error) {
+	results, err := CallRPCBatch(ctx, []RPCCall{
+		{Method: RpcMethodDecodeRawTransaction, Params: []interface{}{txHex}},
+		{Method: RpcMethodGetRawTransaction, Params: []interface{}{txid, 1}},
+		{Method: RpcMethodTestMempoolAccept, Params: []interface{}{[]string{txHex}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("交易预检失败: %w", err)
+	}
+	decoded, lookup, accept := results[0], results[1], results[2]
+
+	check := &broadcast.NodePrecheck{}
+	if decoded.Error != nil {
+		check.DecodeError = broadcastError(decoded.Error)
+	} else {
+		var tx struct {
+			Size int `json:"size"`
+		}
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodDecodeRawTransaction, decoded.Result, &tx); err != nil {
+			return nil, fmt.Errorf("解析解码结果失败: %w", err)
+		}
+		check.Size = tx.Size
+	}
+
+	switch {
+	case lookup.Error == nil:
+		var tx struct {
+			Confirmations int64 `json:"confirmations"`
+		}
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetRawTransaction, lookup.Result, &tx); err != nil {
+			return nil, fmt.Errorf("解析交易查询结果失败: %w", err)
+		}
+		check.Known = true
+		check.Confirmations = tx.Confirmations
+	case !errors.Is(lookup.Error, apperror.ErrNotFound):
+		return nil, fmt.Errorf("查询交易%s失败: %w", txid, lookup.Error)
+	}
+
+	var rpcErr *RPCError
+	switch {
+	case accept.Error == nil:
+		var accepts []broadcast.MempoolAccept
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodTestMempoolAccept, accept.Result, &accepts); err != nil {
+			return nil, fmt.Errorf("解析内存池接受检查结果失败: %w", err)
+		}
+		if len(accepts) != 1 {
+			return nil, fmt.Errorf("内存池接受检查返回%d个结果", len(accepts))
+		}
+		check.Accept = &accepts[0]
+	case errors.As(accept.Error, &rpcErr) && rpcErr.Code == rpcErrCodeMethodNotFound:
+		log.WarnWithContext(ctx, "节点不支持testmempoolaccept，跳过内存池接受检查")
+	case rpcErr != nil:
+		// 部分节点直接以RPC错误返回拒绝原因
+		check.Accept = &broadcast.MempoolAccept{TxID: txid, RejectReason: rpcErr.Message}
+	default:
+		return nil, fmt.Errorf("内存池接受检查失败: %w", accept.Error)
+	}
+	return check, nil
+}
 
-	return resultChan
+// broadcastError 将节点错误转换为广播错误信息
+func broadcastError(err error) *broadcast.BroadcastError {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return &broadcast.BroadcastError{Code: rpcErr.Code, Message: rpcErr.Message}
+	}
+	return &broadcast.BroadcastError{Code: -1, Message: err.Error()}
 }
@@ -10,7 +10,10 @@ import (
 	"time"
 
 	"ginproject/entity/config"
+	"ginproject/middleware/healthscore"
 	"ginproject/middleware/log"
+	"ginproject/repo/rpc/async"
+	"ginproject/repo/rpc/rpctrace"
 )
 
 // RPCRequest 表示RPC请求
@@ -29,12 +32,20 @@ type RPCResponse struct {
 	Error   *RPCError   `json:"error,omitempty"`
 }
 
+// 节点查询的交易或区块不存在时返回的错误码（RPC_INVALID_ADDRESS_OR_KEY）
+const rpcErrCodeNotFound = -5
+
 // RPCError 表示RPC错误
 type RPCError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
+// Error 实现error接口
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (代码: %d)", e.Message, e.Code)
+}
+
 // AsyncResult 表示异步结果
 type AsyncResult struct {
 	Result interface{}
@@ -58,12 +69,29 @@ func Init() error {
 		return fmt.Errorf("区块链RPC URL未配置")
 	}
 
+	// 初始化服务发现
+	if err := initDiscovery(config); err != nil {
+		return err
+	}
+
+	// 初始化多节点故障切换
+	if err := initFailover(config); err != nil {
+		return err
+	}
+
+	// 初始化请求对冲
+	initHedge(config)
+
+	// 初始化断路器
+	initBreaker(config)
+
 	// 初始化连接池
 	pool, err := NewConnPool(nil)
 	if err != nil {
 		return fmt.Errorf("初始化区块链节点连接池失败: %w", err)
 	}
 	globalConnPool = pool
+	healthscore.Upstream(healthscore.UpstreamNode).SetPool(pool.Usage)
 
 	log.Infof("区块链RPC客户端初始化完成，服务器: %s", config.URL)
 	return nil
@@ -80,31 +108,38 @@ func CallRPC(ctx context.Context, method string, params interface{}, fullRespons
 		}
 
 		// 创建HTTP请求
-		req, err := createRPCRequest(config, method, params)
+		id := rpctrace.RequestID(ctx, "blockchain_client")
+		req, err := createRPCRequest(ctx, config, id, method, params)
 		if err != nil {
 			return nil, err
 		}
 
 		// 创建带超时的客户端
 		client := &http.Client{
-			Timeout: time.Duration(config.Timeout) * time.Second,
+			Timeout:   time.Duration(config.Timeout) * time.Second,
+			Transport: nodeTransport,
 		}
 
 		// 记录请求日志
 		log.Debugf("发送区块链RPC请求: method=%s, params=%v", method, params)
 
 		// 发送请求
+		start := time.Now()
 		resp, err := client.Do(req.WithContext(ctx))
 		if err != nil {
-			return nil, fmt.Errorf("发送RPC请求失败: %w", err)
+			err = fmt.Errorf("发送RPC请求失败: %w", err)
+			rpctrace.Record(ctx, rpctrace.UpstreamNode, method, id, start, err)
+			return nil, err
 		}
 		defer resp.Body.Close()
 
-		return processRPCResponse(resp, fullResponse)
+		result, err := processRPCResponse(resp, fullResponse)
+		rpctrace.Record(ctx, rpctrace.UpstreamNode, method, id, start, err)
+		return result, err
 	}
 
-	// 使用连接池处理请求
-	result, err := globalConnPool.Call(ctx, method, params)
+	// 使用连接池处理请求，幂等读请求按配置对冲
+	result, err := callWithHedge(ctx, globalConnPool, method, params)
 	if err != nil {
 		return nil, err
 	}
@@ -119,12 +154,12 @@ func CallRPC(ctx context.Context, method string, params interface{}, fullRespons
 	return result, nil
 }
 
-// createRPCRequest 创建RPC请求
-func createRPCRequest(config *config.TBCNodeConfig, method string, params interface{}) (*http.Request, error) {
+// createRPCRequest 创建RPC请求，请求头按配置携带ctx中的trace上下文
+func createRPCRequest(ctx context.Context, config *config.TBCNodeConfig, id string, method string, params interface{}) (*http.Request, error) {
 	// 创建RPC请求
 	rpcReq := RPCRequest{
 		JSONRPC: "1.0",
-		ID:      "blockchain_client",
+		ID:      id,
 		Method:  method,
 		Params:  params,
 	}
@@ -136,7 +171,7 @@ func createRPCRequest(config *config.TBCNodeConfig, method string, params interf
 	}
 
 	// 创建HTTP请求
-	req, err := http.NewRequest("POST", config.URL, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest("POST", nodeURL(config), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
 	}
@@ -148,6 +183,7 @@ func createRPCRequest(config *config.TBCNodeConfig, method string, params interf
 	if config.User != "" && config.Password != "" {
 		req.SetBasicAuth(config.User, config.Password)
 	}
+	rpctrace.InjectHeader(ctx, req.Header)
 
 	return req, nil
 }
@@ -170,7 +206,7 @@ func processRPCResponse(resp *http.Response, fullResponse bool) (interface{}, er
 	// 检查错误
 	if rpcResp.Error != nil {
 		log.Warnf("RPC调用错误: %s (代码: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
-		return nil, fmt.Errorf("RPC调用错误: %s (代码: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return nil, rpcCallError(rpcResp.Error)
 	}
 
 	// 返回结果
@@ -182,19 +218,14 @@ func processRPCResponse(resp *http.Response, fullResponse bool) (interface{}, er
 
 // CallRPCAsync 异步调用节点RPC
 func CallRPCAsync(ctx context.Context, method string, params interface{}, fullResponse bool) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.CallRPCAsync", func(ctx context.Context) AsyncResult {
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
@@ -202,14 +233,12 @@ func CallRPCAsync(ctx context.Context, method string, params interface{}, fullRe
 		// 调用同步版本的RPC方法
 		result, err := CallRPC(ctx, method, params, fullResponse)
 
-		// 将结果发送到通道
-		resultChan <- AsyncResult{
+		// 返回调用结果
+		return AsyncResult{
 			Result: result,
 			Error:  err,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // Close 关闭客户端和连接池
@@ -221,3 +250,11 @@ func Close() error {
 	}
 	return nil
 }
+
+// GetPoolMetrics 获取全局连接池统计指标
+func GetPoolMetrics() (PoolMetrics, error) {
+	if globalConnPool == nil {
+		return PoolMetrics{}, fmt.Errorf("区块链节点连接池未初始化")
+	}
+	return globalConnPool.Metrics(), nil
+}
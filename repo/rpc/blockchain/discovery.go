@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/discovery"
+)
+
+// nodeTransport 不经过连接池的节点请求使用的Transport，按服务发现解析出的地址拨号
+var nodeTransport = discovery.NewTransport(http.DefaultTransport.(*http.Transport))
+
+// 节点地址解析器，未启用服务发现时为nil
+var (
+	nodeResolver   *discovery.Resolver
+	nodeResolverMu sync.RWMutex
+)
+
+// initDiscovery 根据配置初始化节点服务发现
+func initDiscovery(cfg *config.TBCNodeConfig) error {
+	if cfg.Discovery == discovery.ModeStatic {
+		return nil
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("解析节点URL失败: %w", err)
+	}
+
+	name := cfg.DiscoveryName
+	if name == "" {
+		name = u.Hostname()
+	}
+
+	resolver, err := discovery.NewResolver(cfg.Discovery, name, discovery.URLPort(u),
+		time.Duration(cfg.DiscoveryInterval)*time.Second)
+	if err != nil {
+		return fmt.Errorf("创建节点服务发现失败: %w", err)
+	}
+	if err := resolver.Start(); err != nil {
+		// 首次解析失败时回退到静态地址，后台继续刷新
+		log.Warnf("节点服务发现首次解析失败，暂时使用静态地址: %v", err)
+	}
+
+	nodeResolverMu.Lock()
+	if nodeResolver != nil {
+		nodeResolver.Close()
+	}
+	nodeResolver = resolver
+	nodeResolverMu.Unlock()
+
+	log.Infof("节点服务发现已启用, 模式: %s, 名称: %s", cfg.Discovery, name)
+	return nil
+}
+
+// nodeURL 返回本次请求使用的节点URL，启用多节点故障切换时在健康节点间按权重轮询
+// 全部节点都已断开且仍在退避中时使用url
+func nodeURL(cfg *config.TBCNodeConfig) string {
+	if set := currentNodeSet(); set != nil {
+		if target, err := set.Pick(); err == nil {
+			return target
+		}
+	}
+	return cfg.URL
+}
+
+// resolvedURLs 返回发往服务发现解析出的各地址的节点URL，主机名保持为url中的主机名，url无法解析时返回nil
+func resolvedURLs(cfg *config.TBCNodeConfig, addresses []string) []string {
+	urls := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		target, err := discovery.DialURL(cfg.URL, address)
+		if err != nil {
+			return nil
+		}
+		urls = append(urls, target)
+	}
+	return urls
+}
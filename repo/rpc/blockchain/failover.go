@@ -0,0 +1,157 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/failover"
+)
+
+// 节点服务器组，只配置了一个节点且未启用服务发现时为nil，此时所有请求都发往url
+var (
+	nodeSet   *failover.Set
+	nodeSetMu sync.RWMutex
+)
+
+// initFailover 根据配置初始化多节点故障切换，需在服务发现初始化之后调用
+// 静态模式下url和urls按weights加权轮询；启用服务发现时节点列表随解析结果刷新，各节点权重相同
+func initFailover(cfg *config.TBCNodeConfig) error {
+	urls := append([]string{cfg.URL}, cfg.URLs...)
+	endpoints := make([]failover.Endpoint, 0, len(urls))
+	for i, target := range urls {
+		endpoint := failover.Endpoint{Address: target}
+		if i < len(cfg.Weights) {
+			endpoint.Weight = cfg.Weights[i]
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	nodeResolverMu.RLock()
+	resolver := nodeResolver
+	nodeResolverMu.RUnlock()
+
+	opts := failover.Options{
+		FailureThreshold: cfg.FailureThreshold,
+		MaxBackoff:       time.Duration(cfg.MaxBackoff) * time.Second,
+		CheckInterval:    time.Duration(cfg.HealthCheckInterval) * time.Second,
+		CheckTimeout:     time.Duration(cfg.Timeout) * time.Second,
+		Probe: func(ctx context.Context, target string) error {
+			return probeNode(ctx, cfg, target)
+		},
+	}
+	if resolver != nil {
+		opts.Refresh = func() []string {
+			return resolvedURLs(cfg, resolver.Endpoints())
+		}
+		if resolved := opts.Refresh(); len(resolved) > 0 {
+			endpoints = endpoints[:0]
+			for _, target := range resolved {
+				endpoints = append(endpoints, failover.Endpoint{Address: target})
+			}
+		}
+	}
+
+	var set *failover.Set
+	if resolver != nil || len(endpoints) > 1 {
+		var err error
+		set, err = failover.NewWeightedSet("TBC节点", endpoints, opts)
+		if err != nil {
+			return fmt.Errorf("创建节点服务器组失败: %w", err)
+		}
+		set.Start()
+		log.Info("区块链节点多节点故障切换已启用, 节点:", set.Addresses())
+	}
+
+	nodeSetMu.Lock()
+	if nodeSet != nil {
+		nodeSet.Close()
+	}
+	nodeSet = set
+	nodeSetMu.Unlock()
+	return nil
+}
+
+// currentNodeSet 返回当前的节点服务器组，未启用故障切换时为nil
+func currentNodeSet() *failover.Set {
+	nodeSetMu.RLock()
+	defer nodeSetMu.RUnlock()
+	return nodeSet
+}
+
+// NodeStatus 返回各节点的健康状态，未启用故障切换时返回nil
+func NodeStatus() []failover.EndpointStatus {
+	if set := currentNodeSet(); set != nil {
+		return set.Status()
+	}
+	return nil
+}
+
+// isNodeFailure 判断请求错误是否由节点故障引起，节点返回的业务错误和调用方取消不计入
+func isNodeFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var rpcErr *RPCError
+	return !errors.As(err, &rpcErr)
+}
+
+// reportNode 将一次请求的结果计入节点的健康状态，节点返回业务错误说明节点可用，调用方取消的请求不计入
+func reportNode(ctx context.Context, target string, err error) {
+	set := currentNodeSet()
+	if set == nil || ctx.Err() != nil {
+		return
+	}
+	if !isNodeFailure(ctx, err) {
+		err = nil
+	}
+	set.Report(target, err)
+}
+
+// otherNode 返回除failed外可用的节点，用于幂等读请求在节点故障时切换重试
+func otherNode(failed string) (string, bool) {
+	set := currentNodeSet()
+	if set == nil {
+		return "", false
+	}
+	target, err := set.PickExcept(failed)
+	if err != nil || target == failed {
+		return "", false
+	}
+	return target, true
+}
+
+// probeNode 健康检查，向节点发送ping请求，能解析出JSON-RPC响应即视为节点可用
+func probeNode(ctx context.Context, cfg *config.TBCNodeConfig, target string) error {
+	reqBody, err := json.Marshal(RPCRequest{JSONRPC: "1.0", ID: "probe", Method: "ping", Params: []interface{}{}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.User != "" && cfg.Password != "" {
+		req.SetBasicAuth(cfg.User, cfg.Password)
+	}
+
+	resp, err := (&http.Client{Transport: nodeTransport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp RPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("解析节点响应失败(HTTP %d): %w", resp.StatusCode, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/repo/rpc/failover"
+	"ginproject/repo/rpc/hedge"
+)
+
+// newFailoverTestPool 创建发往指定节点的连接池和节点服务器组，测试结束时关闭并清除
+func newFailoverTestPool(t *testing.T, urls ...string) *ConnPool {
+	t.Helper()
+	endpoints := make([]failover.Endpoint, 0, len(urls))
+	for _, target := range urls {
+		endpoints = append(endpoints, failover.Endpoint{Address: target})
+	}
+	set, err := failover.NewWeightedSet("test", endpoints, failover.Options{FailureThreshold: 1, BaseBackoff: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeSetMu.Lock()
+	nodeSet = set
+	nodeSetMu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &ConnPool{
+		config:            &config.TBCNodeConfig{URL: urls[0]},
+		conns:             make(chan *HTTPConnection, 1),
+		maxIdleConns:      1,
+		maxOpenConns:      1,
+		connTimeout:       time.Second,
+		idleTimeout:       time.Minute,
+		maxLifetime:       time.Minute,
+		validateAfterIdle: time.Minute,
+		cleanerCtx:        ctx,
+		cleanerCancel:     cancel,
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		nodeSetMu.Lock()
+		nodeSet = nil
+		nodeSetMu.Unlock()
+	})
+	return pool
+}
+
+func TestCallFailsOverToHealthyNode(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"1","result":"00ff","error":null}`))
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	pool := newFailoverTestPool(t, down.URL, up.URL)
+
+	// 建连验证和幂等读请求都切换到可用节点
+	result, err := pool.call(context.Background(), "1", "", RpcMethodGetBlockHash, []interface{}{1})
+	if err != nil || result != "00ff" {
+		t.Fatalf("幂等读请求应切换到可用节点: result=%v err=%v", result, err)
+	}
+	if status := NodeStatus(); status[0].Healthy || !status[1].Healthy {
+		t.Errorf("不可用节点应被断开: %+v", status)
+	}
+
+	// 断开的节点在退避期间不再分到请求
+	for i := 0; i < 3; i++ {
+		if result, err := pool.call(context.Background(), "1", "", "sendrawtransaction", []interface{}{"00"}); err != nil || result != "00ff" {
+			t.Fatalf("请求应发往可用节点: result=%v err=%v", result, err)
+		}
+	}
+}
+
+func TestReportNodeIgnoresRPCError(t *testing.T) {
+	newFailoverTestPool(t, "http://a", "http://b")
+
+	reportNode(context.Background(), "http://a", rpcCallError(&RPCError{Code: rpcErrCodeNotFound, Message: "not found"}))
+	if !currentNodeSet().Healthy("http://a") {
+		t.Error("节点返回的业务错误不应计为故障")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reportNode(ctx, "http://a", ctx.Err())
+	if !currentNodeSet().Healthy("http://a") {
+		t.Error("调用方取消的请求不应计为故障")
+	}
+}
+
+func TestHedgeRoutesToOtherNode(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	slowNode := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req RPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Method == RpcMethodGetBlockHash {
+				mu.Lock()
+				hits[name]++
+				mu.Unlock()
+				select {
+				case <-time.After(100 * time.Millisecond):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			w.Write([]byte(`{"id":"1","result":"00ff","error":null}`))
+		}))
+	}
+	a, b := slowNode("a"), slowNode("b")
+	defer a.Close()
+	defer b.Close()
+
+	pool := newFailoverTestPool(t, a.URL, b.URL)
+	pool.maxOpenConns = 2
+	pool.conns = make(chan *HTTPConnection, 2)
+	saved := nodeHedger
+	nodeHedger = hedge.NewHedger(10*time.Millisecond, 100)
+	t.Cleanup(func() { nodeHedger = saved })
+
+	if result, err := callWithHedge(context.Background(), pool, RpcMethodGetBlockHash, []interface{}{1}); err != nil || result != "00ff" {
+		t.Fatalf("对冲调用失败: result=%v err=%v", result, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["a"] != 1 || hits["b"] != 1 {
+		t.Errorf("对冲请求应发往主请求以外的节点: %v", hits)
+	}
+}
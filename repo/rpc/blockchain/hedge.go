@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/healthscore"
+	"ginproject/repo/rpc/hedge"
+)
+
+// 节点请求对冲控制器，未启用时为nil
+var nodeHedger *hedge.Hedger
+
+// idempotentMethods 可以安全对冲的只读RPC方法
+var idempotentMethods = map[string]bool{
+	RpcMethodGetBlockByHeight:     true,
+	RpcMethodGetBlock:             true,
+	RpcMethodGetBlockHash:         true,
+	RpcMethodGetBlockHeader:       true,
+	RpcMethodGetInfo:              true,
+	RpcMethodGetBlockchainInfo:    true,
+	RpcMethodGetRawMempool:        true,
+	RpcMethodGetRawTransaction:    true,
+	RpcMethodDecodeRawTransaction: true,
+	RpcMethodDecodeScript:         true,
+}
+
+// initHedge 根据配置初始化对冲控制器
+func initHedge(cfg *config.TBCNodeConfig) {
+	if !cfg.Hedge {
+		nodeHedger = nil
+		return
+	}
+	nodeHedger = hedge.NewHedger(time.Duration(cfg.HedgeDelay)*time.Millisecond, cfg.HedgeBudget)
+}
+
+// callWithHedge 经过断路器通过连接池调用RPC，幂等读请求在启用且健康评分允许时进行对冲，调用结果计入健康评分
+// 启用多节点故障切换时对冲请求发往主请求所用节点以外的节点
+func callWithHedge(ctx context.Context, pool *ConnPool, method string, params interface{}) (interface{}, error) {
+	start := time.Now()
+	var result interface{}
+	err := withBreaker(ctx, method, func(ctx context.Context) error {
+		var err error
+		if nodeHedger == nil || !idempotentMethods[method] || !healthscore.AllowHedge() {
+			result, err = pool.Call(ctx, method, params)
+		} else {
+			primary := nodeURL(pool.config)
+			result, err = hedge.Do(ctx, nodeHedger, func(ctx context.Context, hedged bool) (interface{}, error) {
+				if !hedged {
+					return pool.callOn(ctx, primary, method, params)
+				}
+				// 没有其它可用节点时仍发往主请求所用的节点
+				target, ok := otherNode(primary)
+				if !ok {
+					target = primary
+				}
+				return pool.callOn(ctx, target, method, params)
+			})
+		}
+		return err
+	})
+	observe(ctx, start, err)
+	return result, err
+}
+
+// observe 将一次节点调用计入健康评分，节点返回的业务错误不计为故障，调用方取消的请求不计入
+func observe(ctx context.Context, start time.Time, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	var rpcErr *RPCError
+	failed := err != nil && !errors.As(err, &rpcErr)
+	healthscore.Upstream(healthscore.UpstreamNode).Observe(time.Since(start), failed)
+}
+
+// GetHedgeStats 获取节点请求对冲统计
+func GetHedgeStats() (hedge.Stats, bool) {
+	if nodeHedger == nil {
+		return hedge.Stats{}, false
+	}
+	return nodeHedger.Stats(), true
+}
@@ -0,0 +1,12 @@
+package blockchain
+
+import (
+	"testing"
+
+	"go.uber.org/goleak"
+)
+
+// TestMain 全部测试结束后检查是否有遗留的协程
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
@@ -0,0 +1,108 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+
+	"ginproject/entity/mempool"
+	"ginproject/middleware/log"
+	"ginproject/middleware/schemawatch"
+	"ginproject/repo/rpc/async"
+)
+
+// 内存池相关的节点RPC方法名
+const (
+	RpcMethodGetMempoolInfo      = "getmempoolinfo"
+	RpcMethodGetMempoolEntry     = "getmempoolentry"
+	RpcMethodGetMempoolAncestors = "getmempoolancestors"
+	RpcMethodEstimateSmartFee    = "estimatesmartfee"
+)
+
+// FetchMemPoolInfo 获取内存池概况（异步），结果为*mempool.NodeMempoolInfo
+func FetchMemPoolInfo(ctx context.Context) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchMemPoolInfo", func(ctx context.Context) AsyncResult {
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetMempoolInfo, []interface{}{}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "获取内存池概况失败", "error", asyncResult.Error)
+			return AsyncResult{Error: asyncResult.Error}
+		}
+
+		var info mempool.NodeMempoolInfo
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetMempoolInfo, asyncResult.Result, &info); err != nil {
+			log.ErrorWithContext(ctx, "解析内存池概况失败", "error", err)
+			return AsyncResult{Error: fmt.Errorf("解析内存池概况失败: %w", err)}
+		}
+
+		return AsyncResult{Result: &info}
+	})
+}
+
+// FetchMemPoolEntry 获取内存池中单笔交易的条目（异步），结果为*mempool.NodeMempoolEntry
+// 交易不在内存池中时错误包装apperror.ErrNotFound
+func FetchMemPoolEntry(ctx context.Context, txid string) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchMemPoolEntry", func(ctx context.Context) AsyncResult {
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetMempoolEntry, []interface{}{txid}, false)
+		if asyncResult.Error != nil {
+			return AsyncResult{Error: asyncResult.Error}
+		}
+
+		var entry mempool.NodeMempoolEntry
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetMempoolEntry, asyncResult.Result, &entry); err != nil {
+			log.ErrorWithContext(ctx, "解析内存池条目失败", "txid", txid, "error", err)
+			return AsyncResult{Error: fmt.Errorf("解析内存池条目失败: %w", err)}
+		}
+
+		return AsyncResult{Result: &entry}
+	})
+}
+
+// FetchMemPoolAncestors 获取交易在内存池中的全部祖先交易ID（异步），结果为[]string
+func FetchMemPoolAncestors(ctx context.Context, txid string) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchMemPoolAncestors", func(ctx context.Context) AsyncResult {
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetMempoolAncestors, []interface{}{txid, false}, false)
+		if asyncResult.Error != nil {
+			return AsyncResult{Error: asyncResult.Error}
+		}
+
+		var ancestors []string
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetMempoolAncestors, asyncResult.Result, &ancestors); err != nil {
+			log.ErrorWithContext(ctx, "解析内存池祖先交易失败", "txid", txid, "error", err)
+			return AsyncResult{Error: fmt.Errorf("解析内存池祖先交易失败: %w", err)}
+		}
+
+		return AsyncResult{Result: ancestors}
+	})
+}
+
+// FetchSmartFees 通过一次批量请求估算多个确认目标的费率（异步），结果为确认目标到费率(TBC/kB)的映射
+// 节点数据不足或单个目标查询失败时该目标不出现在结果中
+func FetchSmartFees(ctx context.Context, targets []int) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchSmartFees", func(ctx context.Context) AsyncResult {
+		calls := make([]RPCCall, 0, len(targets))
+		for _, blocks := range targets {
+			calls = append(calls, RPCCall{Method: RpcMethodEstimateSmartFee, Params: []interface{}{blocks}})
+		}
+		results, err := CallRPCBatch(ctx, calls)
+		if err != nil {
+			log.ErrorWithContext(ctx, "批量估算费率失败", "error", err)
+			return AsyncResult{Error: fmt.Errorf("批量估算费率失败: %w", err)}
+		}
+
+		fees := make(map[int]float64, len(targets))
+		for i, r := range results {
+			if r.Error != nil {
+				log.WarnWithContext(ctx, "估算费率失败", "blocks", targets[i], "error", r.Error)
+				continue
+			}
+			var fee mempool.NodeSmartFee
+			if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodEstimateSmartFee, r.Result, &fee); err != nil {
+				log.WarnWithContext(ctx, "解析费率估算失败", "blocks", targets[i], "error", err)
+				continue
+			}
+			if fee.FeeRate > 0 {
+				fees[targets[i]] = fee.FeeRate
+			}
+		}
+		return AsyncResult{Result: fees}
+	})
+}
@@ -2,12 +2,16 @@ package blockchain
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
+	"ginproject/entity/block"
 	"ginproject/entity/blockchain"
+	"ginproject/entity/utility"
 	"ginproject/middleware/log"
+	"ginproject/middleware/schemawatch"
+	"ginproject/repo/rpc/async"
 )
 
 // 节点RPC方法名常量
@@ -18,10 +22,13 @@ const (
 	RpcMethodGetBlockHeader       = "getblockheader"
 	RpcMethodGetInfo              = "getinfo"
 	RpcMethodGetBlockchainInfo    = "getblockchaininfo"
+	RpcMethodGetChainTips         = "getchaintips"
 	RpcMethodGetRawMempool        = "getrawmempool"
 	RpcMethodGetRawTransaction    = "getrawtransaction"
 	RpcMethodDecodeRawTransaction = "decoderawtransaction"
+	RpcMethodDecodeScript         = "decodescript"
 	RpcMethodSendRawTransaction   = "sendrawtransaction"
+	RpcMethodSendToAddress        = "sendtoaddress"
 )
 
 // BlockInfo 表示区块信息
@@ -43,19 +50,14 @@ type BlockInfo struct {
 
 // GetBlockByHeightStructured 根据区块高度获取结构化区块信息（异步）
 func GetBlockByHeightStructured(ctx context.Context, height int) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.GetBlockByHeightStructured", func(ctx context.Context) AsyncResult {
 		// 参数验证
 		if height < 0 {
 			log.ErrorWithContext(ctx, "获取区块信息失败：区块高度不能为负数", "height:", height)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("区块高度不能为负数"),
 			}
-			return
 		}
 
 		// 记录开始调用日志
@@ -67,44 +69,31 @@ func GetBlockByHeightStructured(ctx context.Context, height int) <-chan AsyncRes
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "获取区块信息失败", "height:", height, "错误:", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("获取区块信息失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
 
 		// 将返回结果转换为BlockInfo结构
 		var blockInfo BlockInfo
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
-			log.ErrorWithContext(ctx, "序列化区块结果失败", "错误:", err)
-			resultChan <- AsyncResult{
-				Result: nil,
-				Error:  fmt.Errorf("解析RPC响应失败: %w", err),
-			}
-			return
-		}
-
-		if err := json.Unmarshal(resultBytes, &blockInfo); err != nil {
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetBlockByHeight, result, &blockInfo); err != nil {
 			log.ErrorWithContext(ctx, "解析区块数据失败", "height:", height, "错误:", err)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解析区块数据失败: %w", err),
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
@@ -113,22 +102,16 @@ func GetBlockByHeightStructured(ctx context.Context, height int) <-chan AsyncRes
 			"height:", height,
 			"hash:", blockInfo.Hash,
 			"time:", blockInfo.Time)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: &blockInfo,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // FetchBlockByHeight 根据区块高度获取区块详情(原始接口数据)（异步）
 func FetchBlockByHeight(ctx context.Context, height int64) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.FetchBlockByHeight", func(ctx context.Context) AsyncResult {
 		log.InfoWithContext(ctx, "通过高度获取区块", "height", height)
 
 		// 使用异步RPC调用
@@ -137,55 +120,65 @@ func FetchBlockByHeight(ctx context.Context, height int64) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "通过高度获取区块失败", "height", height, "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  asyncResult.Error,
 			}
-			return
 		}
 
 		response := asyncResult.Result
 
-		responseMap, ok := response.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "通过高度获取区块响应格式错误", "height", height)
-			resultChan <- AsyncResult{
+		responseMap, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetBlockByHeight, response)
+		if err != nil {
+			log.ErrorWithContext(ctx, "通过高度获取区块响应格式错误", "height", height, "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "通过高度获取区块成功", "height", height)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: responseMap,
 			Error:  nil,
 		}
-	}()
+	})
+}
+
+// FetchBlockWithTxsByHeight 根据区块高度获取包含完整交易信息的区块（异步）
+func FetchBlockWithTxsByHeight(ctx context.Context, height int64) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchBlockWithTxsByHeight", func(ctx context.Context) AsyncResult {
+		// verbosity=2时节点在tx字段中返回解码后的交易
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetBlockByHeight, []interface{}{height, 2}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "获取区块交易失败", "height", height, "error", asyncResult.Error)
+			return AsyncResult{Error: asyncResult.Error}
+		}
 
-	return resultChan
+		var result block.BlockWithTxs
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetBlockByHeight, asyncResult.Result, &result); err != nil {
+			log.ErrorWithContext(ctx, "解析区块交易失败", "height", height, "error", err)
+			return AsyncResult{Error: fmt.Errorf("解析区块交易失败: %w", err)}
+		}
+
+		return AsyncResult{Result: &result}
+	})
 }
 
 // FetchBlockByHash 根据区块哈希获取区块详情(原始接口数据)（异步）
 func FetchBlockByHash(ctx context.Context, hash string) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.FetchBlockByHash", func(ctx context.Context) AsyncResult {
 		log.InfoWithContext(ctx, "通过哈希获取区块", "hash", hash)
 
 		// 使用异步RPC调用
@@ -194,55 +187,65 @@ func FetchBlockByHash(ctx context.Context, hash string) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "通过哈希获取区块失败", "hash", hash, "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  asyncResult.Error,
 			}
-			return
 		}
 
 		response := asyncResult.Result
 
-		responseMap, ok := response.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "通过哈希获取区块响应格式错误", "hash", hash)
-			resultChan <- AsyncResult{
+		responseMap, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetBlock, response)
+		if err != nil {
+			log.ErrorWithContext(ctx, "通过哈希获取区块响应格式错误", "hash", hash, "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "通过哈希获取区块成功", "hash", hash)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: responseMap,
 			Error:  nil,
 		}
-	}()
+	})
+}
+
+// FetchRawBlockByHash 根据区块哈希获取序列化区块的十六进制字符串（异步）
+func FetchRawBlockByHash(ctx context.Context, hash string) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchRawBlockByHash", func(ctx context.Context) AsyncResult {
+		// verbosity=0时节点返回序列化区块的十六进制
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetBlock, []interface{}{hash, 0}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "获取原始区块失败", "hash", hash, "error", asyncResult.Error)
+			return AsyncResult{Error: asyncResult.Error}
+		}
 
-	return resultChan
+		raw, err := schemawatch.Expect[string](ctx, schemawatch.SourceNode, RpcMethodGetBlock, asyncResult.Result)
+		if err != nil {
+			log.ErrorWithContext(ctx, "原始区块响应格式错误", "hash", hash, "error", err)
+			return AsyncResult{Error: err}
+		}
+
+		return AsyncResult{Result: raw}
+	})
 }
 
 // FetchBlockHeaderByHeight 根据区块高度获取区块头信息（异步）
 func FetchBlockHeaderByHeight(ctx context.Context, height int64) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.FetchBlockHeaderByHeight", func(ctx context.Context) AsyncResult {
 		log.InfoWithContext(ctx, "通过高度获取区块头", "height", height)
 
 		// 先获取区块哈希（使用异步方式）
@@ -251,22 +254,19 @@ func FetchBlockHeaderByHeight(ctx context.Context, height int64) <-chan AsyncRes
 
 		if hashAsyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "获取区块哈希失败", "height", height, "error", hashAsyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  hashAsyncResult.Error,
 			}
-			return
 		}
 
-		hash, ok := hashAsyncResult.Result.(string)
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "获取区块哈希响应格式错误", "height", height)
-			resultChan <- AsyncResult{
+		hash, err := schemawatch.Expect[string](ctx, schemawatch.SourceNode, RpcMethodGetBlockHash, hashAsyncResult.Result)
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取区块哈希响应格式错误", "height", height, "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 通过哈希获取区块头（使用异步方式）
@@ -275,55 +275,45 @@ func FetchBlockHeaderByHeight(ctx context.Context, height int64) <-chan AsyncRes
 
 		if responseAsyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "通过哈希获取区块头失败", "hash", hash, "error", responseAsyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  responseAsyncResult.Error,
 			}
-			return
 		}
 
 		response := responseAsyncResult.Result
 
-		responseMap, ok := response.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "通过哈希获取区块头响应格式错误", "height", height)
-			resultChan <- AsyncResult{
+		responseMap, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetBlockHeader, response)
+		if err != nil {
+			log.ErrorWithContext(ctx, "通过哈希获取区块头响应格式错误", "height", height, "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "通过高度获取区块头成功", "height", height)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: responseMap,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // FetchBlockHeaderByHash 根据区块哈希获取区块头信息（异步）
 func FetchBlockHeaderByHash(ctx context.Context, hash string) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.FetchBlockHeaderByHash", func(ctx context.Context) AsyncResult {
 		log.InfoWithContext(ctx, "通过哈希获取区块头", "hash", hash)
 
 		// 使用异步方式调用RPC
@@ -332,56 +322,66 @@ func FetchBlockHeaderByHash(ctx context.Context, hash string) <-chan AsyncResult
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "通过哈希获取区块头失败", "hash", hash, "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  asyncResult.Error,
 			}
-			return
 		}
 
 		response := asyncResult.Result
 
-		responseMap, ok := response.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "通过哈希获取区块头响应格式错误", "hash", hash)
-			resultChan <- AsyncResult{
+		responseMap, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetBlockHeader, response)
+		if err != nil {
+			log.ErrorWithContext(ctx, "通过哈希获取区块头响应格式错误", "hash", hash, "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "通过哈希获取区块头成功", "hash", hash)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: responseMap,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
-// FetchNearby10Headers 获取最近的10个区块头信息（异步）
-func FetchNearby10Headers(ctx context.Context) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
+// FetchChainTips 获取节点已知的全部链顶，包括活跃链和各个分叉（异步）
+func FetchChainTips(ctx context.Context) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchChainTips", func(ctx context.Context) AsyncResult {
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetChainTips, []interface{}{}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "获取链顶列表失败", "error", asyncResult.Error)
+			return AsyncResult{Error: asyncResult.Error}
+		}
 
-	go func() {
-		defer close(resultChan)
+		var tips []block.ChainTip
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetChainTips, asyncResult.Result, &tips); err != nil {
+			log.ErrorWithContext(ctx, "解析链顶列表失败", "error", err)
+			return AsyncResult{Error: fmt.Errorf("解析链顶列表失败: %w", err)}
+		}
+
+		return AsyncResult{Result: tips}
+	})
+}
 
-		log.InfoWithContext(ctx, "获取最近10个区块头")
+// FetchNearbyHeaders 从链顶开始向前获取count个区块头信息（异步），按高度从高到低排列
+// 区块头通过工作池并发获取，获取失败的高度会被跳过
+func FetchNearbyHeaders(ctx context.Context, count int) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchNearbyHeaders", func(ctx context.Context) AsyncResult {
+		log.InfoWithContext(ctx, "获取最近的区块头", "count", count)
 
 		// 获取当前区块高度（使用异步方式）
 		infoAsyncChan := CallRPCAsync(ctx, RpcMethodGetInfo, []interface{}{}, false)
@@ -389,107 +389,97 @@ func FetchNearby10Headers(ctx context.Context) <-chan AsyncResult {
 
 		if infoAsyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "获取区块链信息失败", "error", infoAsyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  infoAsyncResult.Error,
 			}
-			return
 		}
 
-		info, ok := infoAsyncResult.Result.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "获取区块链信息响应格式错误")
-			resultChan <- AsyncResult{
+		info, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetInfo, infoAsyncResult.Result)
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取区块链信息响应格式错误", "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
-		height, ok := info["blocks"].(float64)
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "获取区块高度响应格式错误")
-			resultChan <- AsyncResult{
+		height, err := schemawatch.ExpectField[float64](ctx, schemawatch.SourceNode, RpcMethodGetInfo, "blocks", info["blocks"])
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取区块高度响应格式错误", "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
-		// 获取最近10个区块头
-		var response []map[string]interface{}
-		for i := 0; i < 10; i++ {
-			blockHeight := int64(height) - int64(i)
-			if blockHeight < 0 {
-				break
-			}
-
-			// 使用同步方式调用，因为我们需要按顺序获取
-			headerChan := FetchBlockHeaderByHeight(ctx, blockHeight)
-			headerResult := <-headerChan
+		// 需要获取的高度，不低于创世区块
+		heights := make([]int64, 0, count)
+		for i := 0; i < count && int64(height)-int64(i) >= 0; i++ {
+			heights = append(heights, int64(height)-int64(i))
+		}
 
-			if headerResult.Error != nil {
-				log.ErrorWithContext(ctx, "获取区块头失败", "height", blockHeight, "error", headerResult.Error)
-				continue
-			}
+		type heightHeader struct {
+			height int64
+			header map[string]interface{}
+		}
+		results, _ := utility.WorkerPoolWithContext(ctx, heights, block.NearbyHeaderWorkers,
+			func(ctx context.Context, blockHeight int64) (heightHeader, error) {
+				headerResult := <-FetchBlockHeaderByHeight(ctx, blockHeight)
+				if headerResult.Error != nil {
+					log.ErrorWithContext(ctx, "获取区块头失败", "height", blockHeight, "error", headerResult.Error)
+					return heightHeader{}, headerResult.Error
+				}
+				headerMap, ok := headerResult.Result.(map[string]interface{})
+				if !ok {
+					return heightHeader{}, fmt.Errorf("区块头响应格式错误")
+				}
+				return heightHeader{height: blockHeight, header: headerMap}, nil
+			})
 
-			headerMap, ok := headerResult.Result.(map[string]interface{})
-			if ok {
-				response = append(response, headerMap)
+		if ctx.Err() != nil {
+			return AsyncResult{
+				Result: nil,
+				Error:  ctx.Err(),
 			}
+		}
 
-			// 再次检查上下文是否已取消
-			select {
-			case <-ctx.Done():
-				resultChan <- AsyncResult{
-					Result: nil,
-					Error:  ctx.Err(),
-				}
-				return
-			default:
-				// 继续执行
-			}
+		// 工作池返回的结果无序，按高度从高到低排列
+		sort.Slice(results, func(i, j int) bool { return results[i].height > results[j].height })
+		response := make([]map[string]interface{}, 0, len(results))
+		for _, result := range results {
+			response = append(response, result.header)
 		}
 
-		log.InfoWithContext(ctx, "获取最近10个区块头成功", "count", len(response))
-		resultChan <- AsyncResult{
+		log.InfoWithContext(ctx, "获取最近的区块头成功", "count", len(response))
+		return AsyncResult{
 			Result: response,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // GetRawTransaction 获取交易原始数据
 func GetRawTransaction(ctx context.Context, txid string, verbose bool) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.GetRawTransaction", func(ctx context.Context) AsyncResult {
 		// 参数验证
 		if txid == "" {
 			log.ErrorWithContext(ctx, "获取交易原始数据失败：交易ID不能为空")
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("交易ID不能为空"),
 			}
-			return
 		}
 
 		// 记录开始调用日志
@@ -507,11 +497,10 @@ func GetRawTransaction(ctx context.Context, txid string, verbose bool) <-chan As
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "获取交易原始数据失败", "txid", txid, "错误", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("获取交易原始数据失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
@@ -519,32 +508,25 @@ func GetRawTransaction(ctx context.Context, txid string, verbose bool) <-chan As
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "成功获取交易原始数据", "txid", txid)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: result,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // GetBlockByHeight 根据区块高度获取区块信息(简化版)（异步）
 func GetBlockByHeight(ctx context.Context, height int64) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.GetBlockByHeight", func(ctx context.Context) AsyncResult {
 		log.InfoWithContextf(ctx, "开始获取区块信息: height=%d", height)
 
 		// 使用异步RPC调用
@@ -553,11 +535,10 @@ func GetBlockByHeight(ctx context.Context, height int64) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContextf(ctx, "获取区块信息失败: height=%d, 错误=%v", height, asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("获取区块失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
@@ -565,42 +546,34 @@ func GetBlockByHeight(ctx context.Context, height int64) <-chan AsyncResult {
 		resultMap, ok := result.(map[string]interface{})
 		if !ok {
 			log.ErrorWithContextf(ctx, "区块信息格式不正确: height=%d", height)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("区块信息格式不正确"),
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContextf(ctx, "成功获取区块信息: height=%d", height)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: resultMap,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // FetchChainInfo 获取区块链信息（异步）
 func FetchChainInfo(ctx context.Context) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.FetchChainInfo", func(ctx context.Context) AsyncResult {
 		log.InfoWithContext(ctx, "获取区块链信息")
 
 		// 使用异步方式调用RPC
@@ -609,46 +582,40 @@ func FetchChainInfo(ctx context.Context) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "获取区块链信息失败", "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  asyncResult.Error,
 			}
-			return
 		}
 
 		response := asyncResult.Result
 
-		responseMap, ok := response.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "获取区块链信息响应格式错误")
-			resultChan <- AsyncResult{
+		responseMap, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetBlockchainInfo, response)
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取区块链信息响应格式错误", "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "获取区块链信息成功")
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: responseMap,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // DecodeTxHash 根据交易哈希获取交易详情（异步）
@@ -661,19 +628,19 @@ func FetchChainInfo(ctx context.Context) <-chan AsyncResult {
 // 返回:
 //   - <-chan AsyncResult: 包含交易详情的异步结果通道
 func DecodeTxHash(ctx context.Context, txid string) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.DecodeTxHash", func(ctx context.Context) AsyncResult {
 		// 参数验证
 		if txid == "" {
 			log.ErrorWithContextf(ctx, "解析交易失败: 交易ID不能为空")
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  errors.New("交易ID不能为空"),
 			}
-			return
+		}
+
+		// 已确认交易优先使用缓存的解码结果
+		if tx, ok := cachedTx(ctx, txid); ok {
+			return AsyncResult{Result: tx}
 		}
 
 		// 记录开始调用日志
@@ -685,75 +652,61 @@ func DecodeTxHash(ctx context.Context, txid string) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContextf(ctx, "查询交易失败: %s, 错误: %v", txid, asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解析交易失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
 
 		// 将返回结果转换为TransactionResponse结构
 		var tx blockchain.TransactionResponse
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
-			log.ErrorWithContextf(ctx, "序列化交易结果失败: %v", err)
-			resultChan <- AsyncResult{
-				Result: nil,
-				Error:  fmt.Errorf("解析RPC响应失败: %w", err),
-			}
-			return
-		}
-
-		if err := json.Unmarshal(resultBytes, &tx); err != nil {
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetRawTransaction, result, &tx); err != nil {
 			log.ErrorWithContextf(ctx, "解析交易数据失败: %s, 错误: %v", txid, err)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解析交易数据失败: %w", err),
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
+		cacheTx(ctx, txid, &tx)
 		log.InfoWithContextf(ctx, "成功查询交易: %s, 确认数: %d", txid, tx.Confirmations)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: &tx,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // DecodeTx 根据交易哈希获取交易详情（异步）
 // 此方法通过区块链节点RPC接口查询指定交易哈希的详细信息
 // 返回的交易信息包括输入输出、脚本、金额等详细数据
 func DecodeTx(ctx context.Context, txid string) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.DecodeTx", func(ctx context.Context) AsyncResult {
 		// 参数验证
 		if txid == "" {
 			log.ErrorWithContextf(ctx, "解析交易失败: 交易ID不能为空")
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("交易ID不能为空"),
 			}
-			return
+		}
+
+		// 已确认交易优先使用缓存的解码结果
+		if tx, ok := cachedTx(ctx, txid); ok {
+			return AsyncResult{Result: tx}
 		}
 
 		// 记录开始调用日志
@@ -765,73 +718,180 @@ func DecodeTx(ctx context.Context, txid string) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContextf(ctx, "查询交易失败: %s, 错误: %v", txid, asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解析交易失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
 
 		// 将返回结果转换为TransactionResponse结构
 		var tx blockchain.TransactionResponse
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
-			log.ErrorWithContextf(ctx, "序列化交易结果失败: %v", err)
-			resultChan <- AsyncResult{
-				Result: nil,
-				Error:  fmt.Errorf("解析RPC响应失败: %w", err),
-			}
-			return
-		}
-
-		if err := json.Unmarshal(resultBytes, &tx); err != nil {
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetRawTransaction, result, &tx); err != nil {
 			log.ErrorWithContextf(ctx, "解析交易数据失败: %s, 错误: %v", txid, err)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解析交易数据失败: %w", err),
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
+		cacheTx(ctx, txid, &tx)
 		log.InfoWithContextf(ctx, "成功查询交易: %s, 确认数: %d", txid, tx.Confirmations)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: &tx,
 			Error:  nil,
 		}
-	}()
+	})
+}
+
+// DecodeTxs 通过批量请求查询多笔交易详情，Result为交易ID到*blockchain.TransactionResponse的映射
+// 重复的交易ID只查询一次，查询失败的交易记录日志后不出现在结果中
+func DecodeTxs(ctx context.Context, txids []string) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.DecodeTxs", func(ctx context.Context) AsyncResult {
+		// 缓存命中的交易不再请求节点
+		txs := make(map[string]*blockchain.TransactionResponse, len(txids))
+		unique := make([]string, 0, len(txids))
+		seen := make(map[string]bool, len(txids))
+		calls := make([]RPCCall, 0, len(txids))
+		for _, txid := range txids {
+			if txid == "" || seen[txid] {
+				continue
+			}
+			seen[txid] = true
+			if tx, ok := cachedTx(ctx, txid); ok {
+				txs[txid] = tx
+				continue
+			}
+			unique = append(unique, txid)
+			calls = append(calls, RPCCall{Method: RpcMethodGetRawTransaction, Params: []interface{}{txid, 1}})
+		}
 
-	return resultChan
+		if len(calls) == 0 {
+			return AsyncResult{Result: txs}
+		}
+
+		results, err := CallRPCBatch(ctx, calls)
+		if err != nil {
+			log.ErrorWithContext(ctx, "批量查询交易失败", "count", len(calls), "错误", err)
+			return AsyncResult{Error: fmt.Errorf("批量查询交易失败: %w", err)}
+		}
+
+		for i, r := range results {
+			if r.Error != nil {
+				log.WarnWithContext(ctx, "查询交易失败", "txid", unique[i], "错误", r.Error)
+				continue
+			}
+			var tx blockchain.TransactionResponse
+			if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodGetRawTransaction, r.Result, &tx); err != nil {
+				log.WarnWithContext(ctx, "解析交易数据失败", "txid", unique[i], "错误", err)
+				continue
+			}
+			cacheTx(ctx, unique[i], &tx)
+			txs[unique[i]] = &tx
+		}
+
+		log.InfoWithContext(ctx, "批量查询交易完成", "requested", len(unique), "found", len(txs))
+		return AsyncResult{Result: txs}
+	})
 }
 
-// DecodeRawTransaction 解码原始交易
-func DecodeRawTransaction(ctx context.Context, txHex string) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
+// FetchBlockTimes 通过批量请求查询多个高度的区块时间戳，Result为高度到时间戳的映射
+// 重复或未确认的高度只查询一次或不查询，查询失败的高度记录日志后不出现在结果中
+func FetchBlockTimes(ctx context.Context, heights []int64) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchBlockTimes", func(ctx context.Context) AsyncResult {
+		times := make(map[int64]int64, len(heights))
+		unique := make([]int64, 0, len(heights))
+		seen := make(map[int64]bool, len(heights))
+		hashCalls := make([]RPCCall, 0, len(heights))
+		for _, height := range heights {
+			if height <= 0 || seen[height] {
+				continue
+			}
+			seen[height] = true
+			unique = append(unique, height)
+			hashCalls = append(hashCalls, RPCCall{Method: RpcMethodGetBlockHash, Params: []interface{}{height}})
+		}
+
+		if len(hashCalls) == 0 {
+			return AsyncResult{Result: times}
+		}
+
+		// 1. 批量获取区块哈希
+		hashResults, err := CallRPCBatch(ctx, hashCalls)
+		if err != nil {
+			log.ErrorWithContext(ctx, "批量查询区块哈希失败", "count", len(hashCalls), "错误", err)
+			return AsyncResult{Error: fmt.Errorf("批量查询区块哈希失败: %w", err)}
+		}
+
+		headerHeights := make([]int64, 0, len(unique))
+		headerCalls := make([]RPCCall, 0, len(unique))
+		for i, r := range hashResults {
+			if r.Error != nil {
+				log.WarnWithContext(ctx, "查询区块哈希失败", "height", unique[i], "错误", r.Error)
+				continue
+			}
+			hash, err := schemawatch.Expect[string](ctx, schemawatch.SourceNode, RpcMethodGetBlockHash, r.Result)
+			if err != nil {
+				log.WarnWithContext(ctx, "区块哈希响应格式错误", "height", unique[i], "错误", err)
+				continue
+			}
+			headerHeights = append(headerHeights, unique[i])
+			headerCalls = append(headerCalls, RPCCall{Method: RpcMethodGetBlockHeader, Params: []interface{}{hash}})
+		}
 
-	go func() {
-		defer close(resultChan)
+		// 2. 批量获取区块头中的时间戳
+		if len(headerCalls) > 0 {
+			headerResults, err := CallRPCBatch(ctx, headerCalls)
+			if err != nil {
+				log.ErrorWithContext(ctx, "批量查询区块头失败", "count", len(headerCalls), "错误", err)
+				return AsyncResult{Error: fmt.Errorf("批量查询区块头失败: %w", err)}
+			}
+			for i, r := range headerResults {
+				if r.Error != nil {
+					log.WarnWithContext(ctx, "查询区块头失败", "height", headerHeights[i], "错误", r.Error)
+					continue
+				}
+				header, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetBlockHeader, r.Result)
+				if err != nil {
+					log.WarnWithContext(ctx, "区块头响应格式错误", "height", headerHeights[i], "错误", err)
+					continue
+				}
+				blockTime, err := schemawatch.ExpectField[float64](ctx, schemawatch.SourceNode, RpcMethodGetBlockHeader, "time", header["time"])
+				if err != nil {
+					log.WarnWithContext(ctx, "区块头缺少时间戳", "height", headerHeights[i], "错误", err)
+					continue
+				}
+				times[headerHeights[i]] = int64(blockTime)
+			}
+		}
 
+		log.InfoWithContext(ctx, "批量查询区块时间完成", "requested", len(unique), "found", len(times))
+		return AsyncResult{Result: times}
+	})
+}
+
+// DecodeRawTransaction 解码原始交易
+func DecodeRawTransaction(ctx context.Context, txHex string) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.DecodeRawTransaction", func(ctx context.Context) AsyncResult {
 		// 参数验证
 		if txHex == "" {
 			log.ErrorWithContext(ctx, "解码原始交易失败：交易16进制字符串不能为空")
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("交易16进制字符串不能为空"),
 			}
-			return
 		}
 
 		// 记录开始调用日志
@@ -843,11 +903,10 @@ func DecodeRawTransaction(ctx context.Context, txHex string) <-chan AsyncResult
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "解码原始交易失败", "错误", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("解码原始交易失败: %w", asyncResult.Error),
 			}
-			return
 		}
 
 		result := asyncResult.Result
@@ -855,32 +914,70 @@ func DecodeRawTransaction(ctx context.Context, txHex string) <-chan AsyncResult
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "成功解码原始交易")
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: result,
 			Error:  nil,
 		}
-	}()
+	})
+}
 
-	return resultChan
+// DecodeScript 由节点解码脚本十六进制，结果为*blockchain.DecodedScript
+func DecodeScript(ctx context.Context, scriptHex string) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.DecodeScript", func(ctx context.Context) AsyncResult {
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodDecodeScript, []interface{}{scriptHex}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "解码脚本失败", "错误", asyncResult.Error)
+			return AsyncResult{Error: fmt.Errorf("解码脚本失败: %w", asyncResult.Error)}
+		}
+
+		var decoded blockchain.DecodedScript
+		if err := schemawatch.Convert(ctx, schemawatch.SourceNode, RpcMethodDecodeScript, asyncResult.Result, &decoded); err != nil {
+			return AsyncResult{Error: fmt.Errorf("解析脚本解码结果失败: %w", err)}
+		}
+		return AsyncResult{Result: &decoded}
+	})
 }
 
-// FetchMemPoolTxs 获取内存池中的交易列表（异步）
-func FetchMemPoolTxs(ctx context.Context) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
+// FetchVerboseMemPool 获取详细的内存池信息（异步），结果为交易ID到交易详情的映射
+func FetchVerboseMemPool(ctx context.Context) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchVerboseMemPool", func(ctx context.Context) AsyncResult {
+		asyncResult := <-CallRPCAsync(ctx, RpcMethodGetRawMempool, []interface{}{true}, false)
+		if asyncResult.Error != nil {
+			log.ErrorWithContext(ctx, "获取详细内存池信息失败", "error", asyncResult.Error)
+			return AsyncResult{
+				Result: nil,
+				Error:  asyncResult.Error,
+			}
+		}
 
-	go func() {
-		defer close(resultChan)
+		entries, err := schemawatch.Expect[map[string]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetRawMempool, asyncResult.Result)
+		if err != nil {
+			log.ErrorWithContext(ctx, "详细内存池信息响应格式错误", "error", err)
+			return AsyncResult{
+				Result: nil,
+				Error:  err,
+			}
+		}
 
+		return AsyncResult{
+			Result: entries,
+			Error:  nil,
+		}
+	})
+}
+
+// FetchMemPoolTxs 获取内存池中的交易列表（异步）
+func FetchMemPoolTxs(ctx context.Context) <-chan AsyncResult {
+	return async.Go(ctx, "blockchain.FetchMemPoolTxs", func(ctx context.Context) AsyncResult {
 		log.InfoWithContext(ctx, "获取内存池交易列表")
 
 		// 使用异步方式调用RPC
@@ -889,35 +986,31 @@ func FetchMemPoolTxs(ctx context.Context) <-chan AsyncResult {
 
 		if asyncResult.Error != nil {
 			log.ErrorWithContext(ctx, "获取内存池交易列表失败", "error", asyncResult.Error)
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  asyncResult.Error,
 			}
-			return
 		}
 
 		response := asyncResult.Result
 
 		// 验证响应数据类型
-		txids, ok := response.([]interface{})
-		if !ok {
-			err := fmt.Errorf("响应格式错误")
-			log.ErrorWithContext(ctx, "获取内存池交易列表响应格式错误")
-			resultChan <- AsyncResult{
+		txids, err := schemawatch.Expect[[]interface{}](ctx, schemawatch.SourceNode, RpcMethodGetRawMempool, response)
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取内存池交易列表响应格式错误", "error", err)
+			return AsyncResult{
 				Result: nil,
 				Error:  err,
 			}
-			return
 		}
 
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
@@ -937,51 +1030,56 @@ func FetchMemPoolTxs(ctx context.Context) <-chan AsyncResult {
 		}
 
 		log.InfoWithContext(ctx, "获取内存池交易列表成功", "count", len(txidList))
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: result,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // GetTxVins 获取交易的输入数据
 func GetTxVins(ctx context.Context, txids []string) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "blockchain.GetTxVins", func(ctx context.Context) AsyncResult {
 		// 参数验证
 		if len(txids) == 0 {
 			log.ErrorWithContext(ctx, "获取交易输入数据失败：交易ID列表不能为空")
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  fmt.Errorf("交易ID列表不能为空"),
 			}
-			return
 		}
 
 		// 记录开始调用日志
 		log.InfoWithContext(ctx, "开始获取交易输入数据", "txids", txids)
 
-		// 存储处理结果
-		result := make([]interface{}, 0, len(txids))
-
-		for _, txid := range txids {
-			// 1. 获取交易详情（使用异步方式）
-			txAsyncChan := CallRPCAsync(ctx, RpcMethodGetRawTransaction, []interface{}{txid, 1}, false)
-			txAsyncResult := <-txAsyncChan
+		// 1. 批量获取交易详情
+		txCalls := make([]RPCCall, len(txids))
+		for i, txid := range txids {
+			txCalls[i] = RPCCall{Method: RpcMethodGetRawTransaction, Params: []interface{}{txid, 1}}
+		}
+		txResults, err := CallRPCBatch(ctx, txCalls)
+		if err != nil {
+			log.ErrorWithContext(ctx, "批量获取交易详情失败", "错误", err)
+			return AsyncResult{
+				Result: nil,
+				Error:  fmt.Errorf("批量获取交易详情失败: %w", err),
+			}
+		}
 
-			if txAsyncResult.Error != nil {
-				log.ErrorWithContext(ctx, "获取交易详情失败", "txid", txid, "错误", txAsyncResult.Error)
+		type txVins struct {
+			hash string
+			vins []interface{}
+		}
+		txList := make([]txVins, 0, len(txids))
+		var vinTxids []string
+		seenVin := make(map[string]bool)
+		for i, txid := range txids {
+			if txResults[i].Error != nil {
+				log.ErrorWithContext(ctx, "获取交易详情失败", "txid", txid, "错误", txResults[i].Error)
 				continue
 			}
 
-			txResp := txAsyncResult.Result
-
-			txMap, ok := txResp.(map[string]interface{})
+			txMap, ok := txResults[i].Result.(map[string]interface{})
 			if !ok {
 				log.ErrorWithContext(ctx, "交易详情格式错误", "txid", txid)
 				continue
@@ -1001,13 +1099,52 @@ func GetTxVins(ctx context.Context, txids []string) <-chan AsyncResult {
 				continue
 			}
 
-			vinDataList := []interface{}{}
-
-			// 2. 处理每个输入
+			txList = append(txList, txVins{hash: hash, vins: vins})
 			for _, vinInterface := range vins {
+				if vin, ok := vinInterface.(map[string]interface{}); ok {
+					if vinTxid, ok := vin["txid"].(string); ok && !seenVin[vinTxid] {
+						seenVin[vinTxid] = true
+						vinTxids = append(vinTxids, vinTxid)
+					}
+				}
+			}
+		}
+
+		// 2. 批量获取全部输入交易的原始数据，同一笔输入交易只查询一次
+		vinCalls := make([]RPCCall, len(vinTxids))
+		for i, vinTxid := range vinTxids {
+			vinCalls[i] = RPCCall{Method: RpcMethodGetRawTransaction, Params: []interface{}{vinTxid}}
+		}
+		vinResults, err := CallRPCBatch(ctx, vinCalls)
+		if err != nil {
+			log.ErrorWithContext(ctx, "批量获取输入交易原始数据失败", "错误", err)
+			return AsyncResult{
+				Result: nil,
+				Error:  fmt.Errorf("批量获取输入交易原始数据失败: %w", err),
+			}
+		}
+		vinRaws := make(map[string]string, len(vinTxids))
+		for i, vinTxid := range vinTxids {
+			if vinResults[i].Error != nil {
+				log.ErrorWithContext(ctx, "获取输入交易原始数据失败", "vinTxid", vinTxid, "错误", vinResults[i].Error)
+				continue
+			}
+			vinRaw, ok := vinResults[i].Result.(string)
+			if !ok {
+				log.ErrorWithContext(ctx, "输入交易原始数据格式错误", "vinTxid", vinTxid)
+				continue
+			}
+			vinRaws[vinTxid] = vinRaw
+		}
+
+		// 3. 按交易和输入的顺序组装结果
+		result := make([]interface{}, 0, len(txList))
+		for _, tx := range txList {
+			vinDataList := []interface{}{}
+			for _, vinInterface := range tx.vins {
 				vin, ok := vinInterface.(map[string]interface{})
 				if !ok {
-					log.ErrorWithContext(ctx, "交易输入格式错误", "txid", txid)
+					log.ErrorWithContext(ctx, "交易输入格式错误", "txid", tx.hash)
 					continue
 				}
 
@@ -1019,27 +1156,13 @@ func GetTxVins(ctx context.Context, txids []string) <-chan AsyncResult {
 					continue
 				}
 
-				// 获取输入交易的ID
 				vinTxid, ok := vin["txid"].(string)
 				if !ok {
-					log.ErrorWithContext(ctx, "获取输入交易ID失败", "txid", txid)
-					continue
-				}
-
-				// 获取输入交易的原始数据（使用异步方式）
-				vinRawAsyncChan := CallRPCAsync(ctx, RpcMethodGetRawTransaction, []interface{}{vinTxid}, false)
-				vinRawAsyncResult := <-vinRawAsyncChan
-
-				if vinRawAsyncResult.Error != nil {
-					log.ErrorWithContext(ctx, "获取输入交易原始数据失败", "vinTxid", vinTxid, "错误", vinRawAsyncResult.Error)
+					log.ErrorWithContext(ctx, "获取输入交易ID失败", "txid", tx.hash)
 					continue
 				}
-
-				vinRawResp := vinRawAsyncResult.Result
-
-				vinRaw, ok := vinRawResp.(string)
+				vinRaw, ok := vinRaws[vinTxid]
 				if !ok {
-					log.ErrorWithContext(ctx, "输入交易原始数据格式错误", "vinTxid", vinTxid)
 					continue
 				}
 
@@ -1049,9 +1172,8 @@ func GetTxVins(ctx context.Context, txids []string) <-chan AsyncResult {
 				})
 			}
 
-			// 3. 添加到结果
 			result = append(result, map[string]interface{}{
-				"txid":     hash,
+				"txid":     tx.hash,
 				"vin_data": vinDataList,
 			})
 		}
@@ -1059,21 +1181,18 @@ func GetTxVins(ctx context.Context, txids []string) <-chan AsyncResult {
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		log.InfoWithContext(ctx, "成功获取交易输入数据", "count", len(result))
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: result,
 			Error:  nil,
 		}
-	}()
-
-	return resultChan
+	})
 }
@@ -7,52 +7,84 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
 
 	"ginproject/entity/config"
 	"ginproject/middleware/log"
+	"ginproject/repo/rpc/discovery"
+	"ginproject/repo/rpc/rpctrace"
 )
 
 // HTTPConnection 表示一个HTTP连接
+// 每个连接使用独立的Transport，回收时可以真正关闭底层TCP连接
 type HTTPConnection struct {
 	client    *http.Client
+	transport *discovery.Transport
 	config    *config.TBCNodeConfig
+	createdAt time.Time
+	expiresAt time.Time
 	lastUsed  time.Time
 	isInvalid bool
 }
 
+// close 关闭连接持有的底层空闲TCP连接
+func (c *HTTPConnection) close() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
 // ConnPool 区块链节点连接池
 type ConnPool struct {
-	mu            sync.Mutex
-	config        *config.TBCNodeConfig
-	conns         chan *HTTPConnection
-	maxIdleConns  int
-	maxOpenConns  int
-	connTimeout   time.Duration
-	idleTimeout   time.Duration
-	createdConns  int
-	connErr       error
-	lastConnErr   time.Time
-	closed        bool
-	cleanerCtx    context.Context
-	cleanerCancel context.CancelFunc
+	mu                sync.Mutex
+	config            *config.TBCNodeConfig
+	conns             chan *HTTPConnection
+	maxIdleConns      int
+	maxOpenConns      int
+	connTimeout       time.Duration
+	idleTimeout       time.Duration
+	maxLifetime       time.Duration
+	validateAfterIdle time.Duration
+	createdConns      int
+	recycledConns     int64
+	failedConns       int64
+	connErr           error
+	lastConnErr       time.Time
+	closed            bool
+	cleanerCtx        context.Context
+	cleanerCancel     context.CancelFunc
 }
 
 // PoolConfig 连接池配置
 type PoolConfig struct {
-	MaxIdleConns int
-	MaxOpenConns int
-	IdleTimeout  time.Duration
+	MaxIdleConns      int
+	MaxOpenConns      int
+	IdleTimeout       time.Duration
+	MaxLifetime       time.Duration
+	ValidateAfterIdle time.Duration
+}
+
+// PoolMetrics 连接池统计指标
+type PoolMetrics struct {
+	IdleConns     int   `json:"idle_conns"`
+	OpenConns     int   `json:"open_conns"`
+	RecycledConns int64 `json:"recycled_conns"`
+	FailedConns   int64 `json:"failed_conns"`
 }
 
 // 常量定义
 const (
-	defaultIdleTimeout  = 10 * time.Minute
-	defaultMaxIdleConns = 10
-	defaultMaxOpenConns = 20
-	connRetryDelay      = 5 * time.Second
+	defaultIdleTimeout       = 10 * time.Minute
+	defaultMaxIdleConns      = 10
+	defaultMaxOpenConns      = 20
+	defaultMaxLifetime       = 30 * time.Minute
+	defaultValidateAfterIdle = 30 * time.Second
+	connRetryDelay           = 5 * time.Second
+	// lifetimeJitter 连接寿命的随机抖动比例，避免连接集中过期
+	lifetimeJitter = 0.1
 )
 
 var (
@@ -76,6 +108,8 @@ func NewConnPool(poolConfig *PoolConfig) (*ConnPool, error) {
 	maxIdleConns := defaultMaxIdleConns
 	maxOpenConns := defaultMaxOpenConns
 	idleTimeout := defaultIdleTimeout
+	maxLifetime := defaultMaxLifetime
+	validateAfterIdle := defaultValidateAfterIdle
 
 	// 优先使用配置中的值
 	if tbcNodeConfig.MaxIdleConns > 0 {
@@ -84,6 +118,12 @@ func NewConnPool(poolConfig *PoolConfig) (*ConnPool, error) {
 	if tbcNodeConfig.MaxOpenConns > 0 {
 		maxOpenConns = tbcNodeConfig.MaxOpenConns
 	}
+	if tbcNodeConfig.MaxConnLifetime > 0 {
+		maxLifetime = time.Duration(tbcNodeConfig.MaxConnLifetime) * time.Second
+	}
+	if tbcNodeConfig.ValidateAfterIdle > 0 {
+		validateAfterIdle = time.Duration(tbcNodeConfig.ValidateAfterIdle) * time.Second
+	}
 
 	// 如果提供了池配置，则覆盖默认值
 	if poolConfig != nil {
@@ -96,6 +136,12 @@ func NewConnPool(poolConfig *PoolConfig) (*ConnPool, error) {
 		if poolConfig.IdleTimeout > 0 {
 			idleTimeout = poolConfig.IdleTimeout
 		}
+		if poolConfig.MaxLifetime > 0 {
+			maxLifetime = poolConfig.MaxLifetime
+		}
+		if poolConfig.ValidateAfterIdle > 0 {
+			validateAfterIdle = poolConfig.ValidateAfterIdle
+		}
 	}
 
 	// 确保参数合理
@@ -106,14 +152,16 @@ func NewConnPool(poolConfig *PoolConfig) (*ConnPool, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	pool := &ConnPool{
-		config:        tbcNodeConfig,
-		conns:         make(chan *HTTPConnection, maxIdleConns),
-		maxIdleConns:  maxIdleConns,
-		maxOpenConns:  maxOpenConns,
-		connTimeout:   time.Duration(tbcNodeConfig.Timeout) * time.Second,
-		idleTimeout:   idleTimeout,
-		cleanerCtx:    ctx,
-		cleanerCancel: cancel,
+		config:            tbcNodeConfig,
+		conns:             make(chan *HTTPConnection, maxIdleConns),
+		maxIdleConns:      maxIdleConns,
+		maxOpenConns:      maxOpenConns,
+		connTimeout:       time.Duration(tbcNodeConfig.Timeout) * time.Second,
+		idleTimeout:       idleTimeout,
+		maxLifetime:       maxLifetime,
+		validateAfterIdle: validateAfterIdle,
+		cleanerCtx:        ctx,
+		cleanerCancel:     cancel,
 	}
 
 	// 启动空闲连接清理协程
@@ -121,7 +169,8 @@ func NewConnPool(poolConfig *PoolConfig) (*ConnPool, error) {
 
 	log.Info("区块链节点连接池已创建, 最大空闲连接:", maxIdleConns,
 		", 最大打开连接:", maxOpenConns,
-		", 空闲超时:", idleTimeout)
+		", 空闲超时:", idleTimeout,
+		", 最大寿命:", maxLifetime)
 	return pool, nil
 }
 
@@ -138,7 +187,7 @@ func (p *ConnPool) GetConn(ctx context.Context) (*HTTPConnection, error) {
 	case conn := <-p.conns:
 		p.mu.Unlock()
 		// 检查连接是否有效
-		if !p.validateConn(conn) {
+		if !p.checkIdleConn(conn) {
 			// 无效连接，创建新连接
 			return p.createConn(ctx)
 		}
@@ -167,8 +216,11 @@ func (p *ConnPool) GetConn(ctx context.Context) (*HTTPConnection, error) {
 
 	select {
 	case conn := <-p.conns:
-		if !p.validateConn(conn) {
+		if !p.checkIdleConn(conn) {
 			// 无效连接，尝试重新获取
+			p.mu.Lock()
+			p.createdConns--
+			p.mu.Unlock()
 			return p.GetConn(ctx)
 		}
 		return conn, nil
@@ -182,10 +234,12 @@ func (p *ConnPool) GetConn(ctx context.Context) (*HTTPConnection, error) {
 
 // createConn 创建新连接
 func (p *ConnPool) createConn(ctx context.Context) (*HTTPConnection, error) {
-	// 如果最近连接有错误，等待一段时间再重试
+	// 如果最近连接有错误，等待一段时间再重试；启用多节点故障切换时下一个连接会发往其它节点，不等待
+	// 调用方已为新连接占用了名额，放弃创建时归还
 	p.mu.Lock()
-	if p.connErr != nil && time.Since(p.lastConnErr) < connRetryDelay {
+	if p.connErr != nil && time.Since(p.lastConnErr) < connRetryDelay && currentNodeSet() == nil {
 		err := p.connErr
+		p.createdConns--
 		p.mu.Unlock()
 		return nil, err
 	}
@@ -198,25 +252,35 @@ func (p *ConnPool) createConn(ctx context.Context) (*HTTPConnection, error) {
 		defer cancel()
 	}
 
-	// 创建HTTP客户端
+	// 创建HTTP客户端，使用独立的Transport以便按连接回收，启用服务发现时按解析出的地址拨号
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxIdleConnsPerHost = 1
+	transport := discovery.NewTransport(base)
 	client := &http.Client{
-		Timeout: p.connTimeout,
+		Timeout:   p.connTimeout,
+		Transport: transport,
 	}
 
 	// 创建连接对象
+	now := time.Now()
 	conn := &HTTPConnection{
 		client:    client,
+		transport: transport,
 		config:    p.config,
-		lastUsed:  time.Now(),
+		createdAt: now,
+		expiresAt: now.Add(p.jitteredLifetime()),
+		lastUsed:  now,
 		isInvalid: false,
 	}
 
 	// 验证连接是否可用
 	if !p.validateConn(conn) {
+		conn.close()
 		p.mu.Lock()
 		p.connErr = errors.New("无法创建有效的区块链节点连接")
 		p.lastConnErr = time.Now()
 		p.createdConns--
+		p.failedConns++
 		p.mu.Unlock()
 		log.ErrorWithContext(ctx, "创建区块链节点连接失败")
 		return nil, p.connErr
@@ -236,13 +300,25 @@ func (p *ConnPool) PutConn(conn *HTTPConnection) {
 	defer p.mu.Unlock()
 
 	if p.closed || conn.isInvalid {
+		if conn.isInvalid {
+			p.failedConns++
+		}
 		p.createdConns--
+		conn.close()
 		return
 	}
 
 	// 更新最后使用时间
 	conn.lastUsed = time.Now()
 
+	// 超过寿命的连接不再放回，直接回收
+	if conn.lastUsed.After(conn.expiresAt) {
+		p.createdConns--
+		p.recycledConns++
+		conn.close()
+		return
+	}
+
 	// 尝试将连接放入空闲池
 	select {
 	case p.conns <- conn:
@@ -251,10 +327,43 @@ func (p *ConnPool) PutConn(conn *HTTPConnection) {
 	default:
 		// 空闲池已满，关闭连接
 		p.createdConns--
+		conn.close()
 	}
 }
 
-// validateConn 验证连接是否有效
+// jitteredLifetime 返回带随机抖动的连接寿命
+func (p *ConnPool) jitteredLifetime() time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(float64(p.maxLifetime)*lifetimeJitter) + 1))
+	return p.maxLifetime - jitter
+}
+
+// checkIdleConn 检查空闲连接：超过寿命的连接直接回收，空闲过久的连接强制验证
+// 返回false时连接已被关闭，调用方负责调整计数
+func (p *ConnPool) checkIdleConn(conn *HTTPConnection) bool {
+	if time.Now().After(conn.expiresAt) {
+		conn.close()
+		p.mu.Lock()
+		p.recycledConns++
+		p.mu.Unlock()
+		log.Debug("区块链节点连接超过最大寿命，已回收")
+		return false
+	}
+
+	if time.Since(conn.lastUsed) < p.validateAfterIdle {
+		return true
+	}
+
+	if !p.validateConn(conn) {
+		conn.close()
+		p.mu.Lock()
+		p.failedConns++
+		p.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// validateConn 验证连接是否有效，节点不可用时切换到其它节点再验证一次
 func (p *ConnPool) validateConn(conn *HTTPConnection) bool {
 	if conn == nil || conn.isInvalid {
 		return false
@@ -266,6 +375,25 @@ func (p *ConnPool) validateConn(conn *HTTPConnection) bool {
 		return false
 	}
 
+	target := nodeURL(conn.config)
+	err := ping(conn, target)
+	reportNode(context.Background(), target, err)
+	if err != nil {
+		if other, ok := otherNode(target); ok {
+			err = ping(conn, other)
+			reportNode(context.Background(), other, err)
+		}
+	}
+	if err != nil {
+		log.Error("验证区块链节点连接失败:", err)
+		conn.isInvalid = true
+		return false
+	}
+	return true
+}
+
+// ping 通过连接向指定节点发送ping请求
+func ping(conn *HTTPConnection, target string) error {
 	// 构建ping请求
 	pingReq := RPCRequest{
 		JSONRPC: "1.0",
@@ -276,17 +404,13 @@ func (p *ConnPool) validateConn(conn *HTTPConnection) bool {
 
 	pingBytes, err := json.Marshal(pingReq)
 	if err != nil {
-		log.Error("序列化ping请求失败:", err)
-		conn.isInvalid = true
-		return false
+		return fmt.Errorf("序列化ping请求失败: %w", err)
 	}
 
 	// 创建HTTP请求
-	req, err := http.NewRequest("POST", conn.config.URL, bytes.NewBuffer(pingBytes))
+	req, err := http.NewRequest("POST", target, bytes.NewBuffer(pingBytes))
 	if err != nil {
-		log.Error("创建HTTP ping请求失败:", err)
-		conn.isInvalid = true
-		return false
+		return fmt.Errorf("创建HTTP ping请求失败: %w", err)
 	}
 
 	// 设置请求头
@@ -305,25 +429,34 @@ func (p *ConnPool) validateConn(conn *HTTPConnection) bool {
 	// 发送请求
 	resp, err := conn.client.Do(req)
 	if err != nil {
-		log.Error("发送ping请求失败:", err)
-		conn.isInvalid = true
-		return false
+		return fmt.Errorf("发送ping请求失败: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取并丢弃响应体
-	_, err = io.ReadAll(resp.Body)
-	if err != nil {
-		log.Error("读取ping响应失败:", err)
-		conn.isInvalid = true
-		return false
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		return fmt.Errorf("读取ping响应失败: %w", err)
 	}
-
-	return true
+	return nil
 }
 
-// Call 使用连接池中的连接调用RPC方法
+// Call 使用连接池中的连接调用RPC方法，请求ID和请求头按配置携带trace上下文，调用记录到上游调用日志
 func (p *ConnPool) Call(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	return p.callOn(ctx, "", method, params)
+}
+
+// callOn 与Call相同，请求发往指定节点，target为空时按nodeURL选择
+func (p *ConnPool) callOn(ctx context.Context, target string, method string, params interface{}) (interface{}, error) {
+	start := time.Now()
+	id := rpctrace.RequestID(ctx, "blockchain_client")
+	result, err := p.call(ctx, id, target, method, params)
+	rpctrace.Record(ctx, rpctrace.UpstreamNode, method, id, start, err)
+	return result, err
+}
+
+// call 使用连接池中的连接向target发送一次RPC请求，结果计入节点的健康状态，target为空时按nodeURL选择
+// 幂等读请求在节点故障时切换到其它节点重试一次
+func (p *ConnPool) call(ctx context.Context, id string, target string, method string, params interface{}) (interface{}, error) {
 	// 获取连接
 	conn, err := p.GetConn(ctx)
 	if err != nil {
@@ -331,10 +464,31 @@ func (p *ConnPool) Call(ctx context.Context, method string, params interface{})
 	}
 	defer p.PutConn(conn)
 
+	if target == "" {
+		target = nodeURL(conn.config)
+	}
+	result, err := sendRPC(ctx, conn, target, id, method, params)
+	reportNode(ctx, target, err)
+	if !isNodeFailure(ctx, err) || !idempotentMethods[method] {
+		return result, err
+	}
+
+	other, ok := otherNode(target)
+	if !ok {
+		return result, err
+	}
+	log.WarnWithContext(ctx, "节点调用失败，切换节点重试", "method:", method, "from:", target, "to:", other, "error:", err)
+	result, err = sendRPC(ctx, conn, other, id, method, params)
+	reportNode(ctx, other, err)
+	return result, err
+}
+
+// sendRPC 通过连接向指定节点发送一次RPC请求
+func sendRPC(ctx context.Context, conn *HTTPConnection, target string, id string, method string, params interface{}) (interface{}, error) {
 	// 创建RPC请求
 	rpcReq := RPCRequest{
 		JSONRPC: "1.0",
-		ID:      "blockchain_client",
+		ID:      id,
 		Method:  method,
 		Params:  params,
 	}
@@ -346,7 +500,7 @@ func (p *ConnPool) Call(ctx context.Context, method string, params interface{})
 	}
 
 	// 创建HTTP请求
-	req, err := http.NewRequest("POST", conn.config.URL, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest("POST", target, bytes.NewBuffer(reqBody))
 	if err != nil {
 		conn.isInvalid = true
 		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
@@ -359,6 +513,7 @@ func (p *ConnPool) Call(ctx context.Context, method string, params interface{})
 	if conn.config.User != "" && conn.config.Password != "" {
 		req.SetBasicAuth(conn.config.User, conn.config.Password)
 	}
+	rpctrace.InjectHeader(ctx, req.Header)
 
 	// 使用上下文
 	req = req.WithContext(ctx)
@@ -390,7 +545,7 @@ func (p *ConnPool) Call(ctx context.Context, method string, params interface{})
 	// 检查错误
 	if rpcResp.Error != nil {
 		log.Warnf("RPC调用错误: %s (代码: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
-		return nil, fmt.Errorf("RPC调用错误: %s (代码: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return nil, rpcCallError(rpcResp.Error)
 	}
 
 	return rpcResp.Result, nil
@@ -411,7 +566,8 @@ func (p *ConnPool) Close() error {
 
 	// 关闭所有连接
 	close(p.conns)
-	for range p.conns {
+	for conn := range p.conns {
+		conn.close()
 		p.createdConns--
 	}
 
@@ -427,6 +583,27 @@ func (p *ConnPool) Stats() (idleConns, openConns int) {
 	return len(p.conns), p.createdConns
 }
 
+// Metrics 获取连接池统计指标，包括回收和失败的连接数
+func (p *ConnPool) Metrics() PoolMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolMetrics{
+		IdleConns:     len(p.conns),
+		OpenConns:     p.createdConns,
+		RecycledConns: p.recycledConns,
+		FailedConns:   p.failedConns,
+	}
+}
+
+// Usage 返回正在使用的连接数和连接数上限
+func (p *ConnPool) Usage() (inUse, capacity int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.createdConns - len(p.conns), p.maxOpenConns
+}
+
 // connectionCleaner 定期清理空闲连接
 func (p *ConnPool) connectionCleaner() {
 	ticker := time.NewTicker(p.idleTimeout / 2)
@@ -448,15 +625,40 @@ func (p *ConnPool) connectionCleaner() {
 				toClose := connsCount - p.maxIdleConns
 				for i := 0; i < toClose; i++ {
 					select {
-					case <-p.conns:
+					case conn := <-p.conns:
+						conn.close()
 						p.createdConns--
 					default:
 					}
 				}
 			}
+
+			// 回收超过寿命的空闲连接
+			p.recycleExpiredLocked()
 			p.mu.Unlock()
 		case <-p.cleanerCtx.Done():
 			return
 		}
 	}
 }
+
+// recycleExpiredLocked 回收空闲池中超过寿命的连接，调用方需持有锁
+func (p *ConnPool) recycleExpiredLocked() {
+	now := time.Now()
+	count := len(p.conns)
+	for i := 0; i < count; i++ {
+		select {
+		case conn := <-p.conns:
+			if now.After(conn.expiresAt) {
+				conn.close()
+				p.createdConns--
+				p.recycledConns++
+				continue
+			}
+			// 未过期的连接放回空闲池
+			p.conns <- conn
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateConnReleasesSlotWhileBackingOff(t *testing.T) {
+	connErr := errors.New("节点不可用")
+	p := &ConnPool{
+		conns:        make(chan *HTTPConnection, 1),
+		maxOpenConns: 1,
+		connTimeout:  time.Second,
+		connErr:      connErr,
+		lastConnErr:  time.Now(),
+	}
+
+	// 最近创建连接失败时直接返回错误，名额不应泄漏，否则达到上限后GetConn只能等待超时
+	for i := 0; i < 3; i++ {
+		if _, err := p.GetConn(context.Background()); !errors.Is(err, connErr) {
+			t.Fatalf("第%d次获取连接错误 = %v", i, err)
+		}
+	}
+	if idle, open := p.Stats(); idle != 0 || open != 0 {
+		t.Errorf("连接数 = %d/%d, 期望 0/0", idle, open)
+	}
+}
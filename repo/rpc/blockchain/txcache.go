@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"ginproject/entity/blockchain"
+	"ginproject/repo/cache"
+)
+
+const (
+	// 进程内缓存的解码交易数量
+	decodedTxCacheSize = 20000
+	// 解码交易的缓存有效期，重组时进程内缓存整体清空，共享缓存中的条目依赖有效期淘汰
+	decodedTxCacheTTL = time.Hour
+)
+
+// 已确认交易的解码结果缓存，地址、FT和NFT历史会反复解码同一批前序交易
+var decodedTxs = cache.NewLayered("decoded_tx", decodedTxCacheSize, decodedTxCacheTTL)
+
+func init() {
+	cache.Register("decoded_tx", decodedTxs)
+}
+
+// cachedTx 从缓存读取交易的解码结果，返回的确认数是写入缓存时的值，需要准确确认数的调用方应按链顶重新计算
+func cachedTx(ctx context.Context, txid string) (*blockchain.TransactionResponse, bool) {
+	data, ok := decodedTxs.Get(ctx, txid)
+	if !ok {
+		return nil, false
+	}
+	var tx blockchain.TransactionResponse
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, false
+	}
+	return &tx, true
+}
+
+// cacheTx 缓存已确认交易的解码结果，未确认交易的区块信息还会变化，不缓存
+func cacheTx(ctx context.Context, txid string, tx *blockchain.TransactionResponse) {
+	if tx.Blockhash == "" {
+		return
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+	decodedTxs.Set(ctx, txid, data)
+}
+
+// PurgeDecodedTxs 清空进程内的解码交易缓存，检测到链重组时调用
+func PurgeDecodedTxs() {
+	decodedTxs.Purge()
+}
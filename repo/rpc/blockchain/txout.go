@@ -0,0 +1,38 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+)
+
+// RpcMethodGetTxOut 查询交易输出是否未花费
+const RpcMethodGetTxOut = "gettxout"
+
+// Outpoint 交易输出的位置
+type Outpoint struct {
+	Txid string
+	Vout int
+}
+
+// FetchOutpointsUnspent 批量查询交易输出在已确认的链上是否仍未花费，结果与outpoints一一对应
+// 只按区块中的花费判断，仅被内存池交易花费的输出仍视为未花费
+func FetchOutpointsUnspent(ctx context.Context, outpoints []Outpoint) ([]bool, error) {
+	calls := make([]RPCCall, len(outpoints))
+	for i, outpoint := range outpoints {
+		calls[i] = RPCCall{Method: RpcMethodGetTxOut, Params: []interface{}{outpoint.Txid, outpoint.Vout, false}}
+	}
+	results, err := CallRPCBatch(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("批量查询交易输出失败: %w", err)
+	}
+
+	unspent := make([]bool, len(outpoints))
+	for i, result := range results {
+		if result.Error != nil {
+			return nil, fmt.Errorf("查询交易输出%s:%d失败: %w", outpoints[i].Txid, outpoints[i].Vout, result.Error)
+		}
+		// 已花费或不存在的输出返回null
+		unspent[i] = result.Result != nil
+	}
+	return unspent, nil
+}
@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ginproject/middleware/log"
+)
+
+// WarmUp 预先建立n个连接放入空闲池，返回成功建立的连接数
+func (p *ConnPool) WarmUp(ctx context.Context, n int) (int, error) {
+	if n > p.maxIdleConns {
+		n = p.maxIdleConns
+	}
+	if n <= 0 {
+		return 0, nil
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		conns    []*HTTPConnection
+		firstErr error
+	)
+
+	// 并发建立连接，全部建立后再统一归还，避免同一连接被重复取出
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := p.GetConn(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}()
+	}
+	wg.Wait()
+
+	for _, conn := range conns {
+		p.PutConn(conn)
+	}
+
+	if len(conns) == 0 && firstErr != nil {
+		return 0, fmt.Errorf("预热区块链节点连接池失败: %w", firstErr)
+	}
+	return len(conns), nil
+}
+
+// WarmUp 预热全局连接池并预先请求节点信息，确认节点可用
+func WarmUp(ctx context.Context, n int) error {
+	if globalConnPool == nil {
+		return fmt.Errorf("区块链节点连接池未初始化")
+	}
+
+	created, err := globalConnPool.WarmUp(ctx, n)
+	if err != nil {
+		return err
+	}
+	log.InfoWithContextf(ctx, "区块链节点连接池预热完成: connections=%d", created)
+
+	info, err := CallRPC(ctx, RpcMethodGetInfo, []interface{}{}, false)
+	if err != nil {
+		return fmt.Errorf("获取节点信息失败: %w", err)
+	}
+	if infoMap, ok := info.(map[string]interface{}); ok {
+		log.InfoWithContextf(ctx, "区块链节点版本: version=%v, protocolversion=%v",
+			infoMap["version"], infoMap["protocolversion"])
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+)
+
+// 默认参数
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+var (
+	// ErrOpen 断路器已打开，调用被直接拒绝
+	ErrOpen = errors.New("上游断路器已打开")
+	// ErrTooManyInFlight 同时进行的调用数达到上限，调用被直接拒绝
+	ErrTooManyInFlight = errors.New("上游调用并发数已达上限")
+)
+
+// State 断路器状态
+type State int
+
+const (
+	StateClosed   State = iota // 正常放行
+	StateOpen                  // 拒绝全部调用，等待OpenTimeout后进入半开
+	StateHalfOpen              // 放行有限的试探调用，成功则关闭，失败则重新打开
+)
+
+// String 返回状态名
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// MarshalText 序列化为状态名
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Settings 单个方法的断路器参数，零值字段使用默认值
+type Settings struct {
+	FailureThreshold int           // 连续失败多少次后打开
+	OpenTimeout      time.Duration // 打开后等待多久进入半开
+	HalfOpenRequests int           // 半开时同时放行的试探调用数
+	MaxInFlight      int           // 同时进行的调用数上限，0表示不限制
+}
+
+// withDefaults 用base补全未设置的字段
+func (s Settings) withDefaults(base Settings) Settings {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = base.FailureThreshold
+	}
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = base.OpenTimeout
+	}
+	if s.HalfOpenRequests <= 0 {
+		s.HalfOpenRequests = base.HalfOpenRequests
+	}
+	if s.MaxInFlight <= 0 {
+		s.MaxInFlight = base.MaxInFlight
+	}
+	return s
+}
+
+// MethodStats 单个方法的断路器状态和统计
+type MethodStats struct {
+	Method    string     `json:"method"`
+	State     State      `json:"state"`
+	Failures  int        `json:"failures"`             // 连续失败次数
+	InFlight  int        `json:"in_flight"`            // 正在进行的调用数
+	Requests  uint64     `json:"requests"`             // 累计放行的调用数
+	Failed    uint64     `json:"failed"`               // 累计失败的调用数
+	Rejected  uint64     `json:"rejected"`             // 累计被拒绝的调用数
+	Opened    uint64     `json:"opened"`               // 累计打开次数
+	RetryAt   *time.Time `json:"retry_at,omitempty"`   // 打开时进入半开的时间
+	LastError string     `json:"last_error,omitempty"` // 最近一次失败的原因
+}
+
+// circuit 单个方法的断路器
+type circuit struct {
+	method   string
+	settings Settings
+	state    State
+	failures int
+	openedAt time.Time
+	probes   int // 半开时正在进行的试探调用数
+	inFlight int
+	requests uint64
+	failed   uint64
+	rejected uint64
+	opened   uint64
+	lastErr  error
+}
+
+// Breaker 按方法独立计数的断路器，同一上游的不同方法互不影响
+// 调用方取消的调用不计入成败；打开期间和并发数达到上限时调用立即返回错误，不再占用协程等待上游
+type Breaker struct {
+	mu        sync.Mutex
+	name      string
+	defaults  Settings
+	overrides map[string]Settings
+	isFailure func(ctx context.Context, err error) bool
+	circuits  map[string]*circuit
+	now       func() time.Time
+}
+
+// New 创建断路器，overrides按方法覆盖defaults中的参数，isFailure判断错误是否由上游故障引起
+func New(name string, defaults Settings, overrides map[string]Settings, isFailure func(ctx context.Context, err error) bool) *Breaker {
+	defaults = defaults.withDefaults(Settings{
+		FailureThreshold: defaultFailureThreshold,
+		OpenTimeout:      defaultOpenTimeout,
+		HalfOpenRequests: defaultHalfOpenRequests,
+	})
+	resolved := make(map[string]Settings, len(overrides))
+	for method, settings := range overrides {
+		resolved[method] = settings.withDefaults(defaults)
+	}
+	return &Breaker{
+		name:      name,
+		defaults:  defaults,
+		overrides: resolved,
+		isFailure: isFailure,
+		circuits:  make(map[string]*circuit),
+		now:       time.Now,
+	}
+}
+
+// NewFromConfig 按配置创建断路器
+func NewFromConfig(name string, cfg config.BreakerConfig, isFailure func(ctx context.Context, err error) bool) *Breaker {
+	overrides := make(map[string]Settings, len(cfg.Methods))
+	for _, m := range cfg.Methods {
+		overrides[m.Method] = Settings{
+			FailureThreshold: m.FailureThreshold,
+			OpenTimeout:      time.Duration(m.OpenTimeout) * time.Second,
+			HalfOpenRequests: m.HalfOpenRequests,
+			MaxInFlight:      m.MaxInFlight,
+		}
+	}
+	defaults := Settings{
+		FailureThreshold: cfg.FailureThreshold,
+		OpenTimeout:      time.Duration(cfg.OpenTimeout) * time.Second,
+		HalfOpenRequests: cfg.HalfOpenRequests,
+		MaxInFlight:      cfg.MaxInFlight,
+	}
+	return New(name, defaults, overrides, isFailure)
+}
+
+// Do 经过断路器执行一次调用，被拒绝时返回包装ErrOpen或ErrTooManyInFlight的错误，否则返回call的错误
+func (b *Breaker) Do(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	c, probe, err := b.acquire(method)
+	if err != nil {
+		return err
+	}
+	err = call(ctx)
+	b.release(ctx, c, probe, err)
+	return err
+}
+
+// acquire 判断是否放行一次调用，放行时占用一个并发名额，probe表示该调用是半开时的试探
+func (b *Breaker) acquire(method string) (c *circuit, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[method]
+	if !ok {
+		settings, ok := b.overrides[method]
+		if !ok {
+			settings = b.defaults
+		}
+		c = &circuit{method: method, settings: settings}
+		b.circuits[method] = c
+	}
+
+	if c.state == StateOpen && !b.now().Before(c.openedAt.Add(c.settings.OpenTimeout)) {
+		c.state = StateHalfOpen
+		c.probes = 0
+	}
+	switch {
+	case c.state == StateOpen:
+		c.rejected++
+		return nil, false, fmt.Errorf("%w: %s %s", ErrOpen, b.name, method)
+	case c.state == StateHalfOpen && c.probes >= c.settings.HalfOpenRequests:
+		c.rejected++
+		return nil, false, fmt.Errorf("%w: %s %s", ErrOpen, b.name, method)
+	case c.settings.MaxInFlight > 0 && c.inFlight >= c.settings.MaxInFlight:
+		c.rejected++
+		return nil, false, fmt.Errorf("%w: %s %s", ErrTooManyInFlight, b.name, method)
+	}
+
+	if c.state == StateHalfOpen {
+		c.probes++
+		probe = true
+	}
+	c.inFlight++
+	c.requests++
+	return c, probe, nil
+}
+
+// release 归还并发名额并记录调用结果，打开前发出、打开后才返回的调用不改变状态
+func (b *Breaker) release(ctx context.Context, c *circuit, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c.inFlight--
+	if probe {
+		c.probes--
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err == nil || !b.isFailure(ctx, err) {
+		c.failures = 0
+		if probe && c.state == StateHalfOpen {
+			c.state = StateClosed
+			log.Infof("上游断路器已关闭: %s %s", b.name, c.method)
+		}
+		return
+	}
+
+	c.failed++
+	c.failures++
+	c.lastErr = err
+	switch {
+	case probe && c.state == StateHalfOpen:
+		b.open(c)
+	case c.state == StateClosed && c.failures >= c.settings.FailureThreshold:
+		log.Warnf("上游调用连续失败%d次，断路器打开: %s %s, err=%v", c.failures, b.name, c.method, err)
+		b.open(c)
+	}
+}
+
+// open 打开断路器
+func (b *Breaker) open(c *circuit) {
+	c.state = StateOpen
+	c.openedAt = b.now()
+	c.opened++
+}
+
+// Stats 返回各方法的断路器状态，未关闭的排在前面，其次按方法名排序
+func (b *Breaker) Stats() []MethodStats {
+	b.mu.Lock()
+	stats := make([]MethodStats, 0, len(b.circuits))
+	for method, c := range b.circuits {
+		item := MethodStats{
+			Method:   method,
+			State:    c.state,
+			Failures: c.failures,
+			InFlight: c.inFlight,
+			Requests: c.requests,
+			Failed:   c.failed,
+			Rejected: c.rejected,
+			Opened:   c.opened,
+		}
+		if c.state == StateOpen {
+			retryAt := c.openedAt.Add(c.settings.OpenTimeout)
+			item.RetryAt = &retryAt
+		}
+		if c.lastErr != nil {
+			item.LastError = c.lastErr.Error()
+		}
+		stats = append(stats, item)
+	}
+	b.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if (stats[i].State == StateClosed) != (stats[j].State == StateClosed) {
+			return stats[i].State != StateClosed
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
@@ -0,0 +1,143 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errUpstream = errors.New("connection refused")
+
+// errBusiness 上游返回的业务错误，不计为故障
+var errBusiness = errors.New("tx not found")
+
+// newTestBreaker 创建使用可控时钟的断路器
+func newTestBreaker(defaults Settings, overrides map[string]Settings) (*Breaker, *time.Time) {
+	b := New("test", defaults, overrides, func(ctx context.Context, err error) bool {
+		return !errors.Is(err, errBusiness)
+	})
+	now := time.Unix(1700000000, 0)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+// call 经过断路器执行一次返回err的调用
+func call(b *Breaker, method string, err error) error {
+	return b.Do(context.Background(), method, func(ctx context.Context) error { return err })
+}
+
+func TestOpenAndRecover(t *testing.T) {
+	b, now := newTestBreaker(Settings{FailureThreshold: 3, OpenTimeout: 10 * time.Second}, nil)
+
+	call(b, "getblock", errUpstream)
+	call(b, "getblock", errUpstream)
+	call(b, "getblock", errBusiness)
+	call(b, "getblock", errUpstream)
+	if err := call(b, "getblock", nil); err != nil {
+		t.Fatalf("业务错误应清零连续失败次数: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		call(b, "getblock", errUpstream)
+	}
+	if err := call(b, "getblock", nil); !errors.Is(err, ErrOpen) {
+		t.Fatalf("连续失败达到阈值后应拒绝调用: %v", err)
+	}
+	if err := call(b, "getrawtransaction", nil); err != nil {
+		t.Errorf("其它方法不应受影响: %v", err)
+	}
+
+	// 半开时试探失败重新打开
+	*now = now.Add(10 * time.Second)
+	if err := call(b, "getblock", errUpstream); !errors.Is(err, errUpstream) {
+		t.Fatalf("到期后应放行试探调用: %v", err)
+	}
+	if err := call(b, "getblock", nil); !errors.Is(err, ErrOpen) {
+		t.Fatalf("试探失败后应重新打开: %v", err)
+	}
+
+	// 试探成功后关闭
+	*now = now.Add(10 * time.Second)
+	if err := call(b, "getblock", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(b, "getblock", nil); err != nil {
+		t.Errorf("试探成功后应关闭: %v", err)
+	}
+
+	stats := b.Stats()
+	if stats[0].Method != "getblock" || stats[0].State != StateClosed || stats[0].Opened != 2 || stats[0].Rejected != 2 {
+		t.Errorf("统计不正确: %+v", stats[0])
+	}
+}
+
+func TestHalfOpenLimitsProbes(t *testing.T) {
+	b, now := newTestBreaker(Settings{FailureThreshold: 1, OpenTimeout: time.Second}, nil)
+	call(b, "getblock", errUpstream)
+	*now = now.Add(time.Second)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- b.Do(context.Background(), "getblock", func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	if err := call(b, "getblock", nil); !errors.Is(err, ErrOpen) {
+		t.Errorf("试探调用进行中时应拒绝其它调用: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if stats := b.Stats(); stats[0].State != StateClosed || stats[0].InFlight != 0 {
+		t.Errorf("试探成功后应关闭并归还名额: %+v", stats[0])
+	}
+}
+
+func TestMaxInFlightAndOverrides(t *testing.T) {
+	b, _ := newTestBreaker(Settings{MaxInFlight: 5}, map[string]Settings{"sendrawtransaction": {MaxInFlight: 1, FailureThreshold: 1}})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- b.Do(context.Background(), "sendrawtransaction", func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	if err := call(b, "sendrawtransaction", nil); !errors.Is(err, ErrTooManyInFlight) {
+		t.Errorf("并发数达到上限时应拒绝: %v", err)
+	}
+	if err := call(b, "getblock", nil); err != nil {
+		t.Errorf("其它方法使用默认上限: %v", err)
+	}
+	close(release)
+	<-done
+
+	// 覆盖的参数只替换设置了的字段
+	if settings := b.overrides["sendrawtransaction"]; settings.OpenTimeout != defaultOpenTimeout || settings.FailureThreshold != 1 {
+		t.Errorf("未覆盖的字段应沿用默认值: %+v", settings)
+	}
+}
+
+func TestCanceledCallIgnored(t *testing.T) {
+	b, _ := newTestBreaker(Settings{FailureThreshold: 1}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.Do(ctx, "getblock", func(ctx context.Context) error { return ctx.Err() })
+	if err := call(b, "getblock", nil); err != nil {
+		t.Errorf("调用方取消的调用不应计为失败: %v", err)
+	}
+}
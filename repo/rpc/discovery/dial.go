@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// DialURL 返回发往解析出的地址的上游URL，主机名保持不变，TLS校验和Host请求头仍使用配置中的主机名
+// 实际拨号的地址记录在URL片段中，片段不会随请求发出，由Transport按它拨号
+func DialURL(rawURL, address string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	u.Fragment = address
+	return u.String(), nil
+}
+
+// URLPort 返回URL的端口，未指定时按协议取默认端口，https为443，其余为80
+func URLPort(u *url.URL) int {
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	if u.Scheme == "https" {
+		return 443
+	}
+	return 80
+}
+
+// Transport 按DialURL记录的地址拨号的http.RoundTripper，URL没有片段时直接使用base
+// 每个拨号地址使用独立的Transport，连接按地址复用，不会把发往一个地址的请求放到另一个地址的连接上
+type Transport struct {
+	base *http.Transport
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+// NewTransport 创建Transport，各拨号地址的Transport复制base的配置
+func NewTransport(base *http.Transport) *Transport {
+	return &Transport{base: base, transports: make(map[string]*http.Transport)}
+}
+
+// RoundTrip 发送请求
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Fragment == "" {
+		return t.base.RoundTrip(req)
+	}
+	return t.transportFor(req.URL.Fragment).RoundTrip(req)
+}
+
+// CloseIdleConnections 关闭所有拨号地址上的空闲连接
+func (t *Transport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// transportFor 返回拨号地址使用的Transport，不存在时创建
+func (t *Transport) transportFor(address string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transport, ok := t.transports[address]
+	if !ok {
+		transport = t.base.Clone()
+		dialer := &net.Dialer{}
+		dial := t.base.DialContext
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			if dial != nil {
+				return dial(ctx, network, address)
+			}
+			return dialer.DialContext(ctx, network, address)
+		}
+		t.transports[address] = transport
+	}
+	return transport
+}
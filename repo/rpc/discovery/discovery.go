@@ -0,0 +1,217 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+// 服务发现模式
+const (
+	// ModeStatic 静态地址，不做解析
+	ModeStatic = ""
+	// ModeSRV 通过DNS SRV记录解析主机和端口
+	ModeSRV = "srv"
+	// ModeDNS 通过A/AAAA记录解析主机，端口使用配置值
+	ModeDNS = "dns"
+)
+
+// 默认刷新周期
+const defaultRefreshInterval = 30 * time.Second
+
+var (
+	// ErrNoEndpoints 没有可用的上游地址
+	ErrNoEndpoints = errors.New("没有可用的上游地址")
+	// ErrUnknownMode 未知的服务发现模式
+	ErrUnknownMode = errors.New("未知的服务发现模式")
+)
+
+// 解析函数，便于替换
+var (
+	lookupSRV  = net.DefaultResolver.LookupSRV
+	lookupHost = net.DefaultResolver.LookupHost
+)
+
+// Resolver 周期性解析上游服务名，维护当前可用的地址列表
+type Resolver struct {
+	mu        sync.RWMutex
+	mode      string
+	name      string
+	port      int
+	interval  time.Duration
+	endpoints []string
+	lastErr   error
+	next      uint32
+	cancel    context.CancelFunc
+}
+
+// NewResolver 创建解析器
+// 标准库不暴露DNS记录的TTL，refreshInterval应配置为不大于记录TTL的值
+func NewResolver(mode, name string, port int, refreshInterval time.Duration) (*Resolver, error) {
+	if mode != ModeSRV && mode != ModeDNS {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMode, mode)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("服务发现名称不能为空")
+	}
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	return &Resolver{
+		mode:     mode,
+		name:     name,
+		port:     port,
+		interval: refreshInterval,
+	}, nil
+}
+
+// Start 立即解析一次并启动后台刷新协程
+func (r *Resolver) Start() error {
+	err := r.refresh()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	go r.refreshLoop(ctx)
+	return err
+}
+
+// Close 停止后台刷新
+func (r *Resolver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// Next 按轮询方式返回下一个上游地址（host:port）
+func (r *Resolver) Next() (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.endpoints) == 0 {
+		if r.lastErr != nil {
+			return "", fmt.Errorf("%w: %v", ErrNoEndpoints, r.lastErr)
+		}
+		return "", ErrNoEndpoints
+	}
+
+	idx := atomic.AddUint32(&r.next, 1) - 1
+	return r.endpoints[int(idx)%len(r.endpoints)], nil
+}
+
+// Endpoints 返回当前解析到的全部上游地址，供故障切换使用
+func (r *Resolver) Endpoints() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoints := make([]string, len(r.endpoints))
+	copy(endpoints, r.endpoints)
+	return endpoints
+}
+
+// refreshLoop 按周期刷新解析结果
+func (r *Resolver) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.refresh(); err != nil {
+				log.Warnf("刷新上游服务地址失败: name=%s, err=%v", r.name, err)
+			}
+		}
+	}
+}
+
+// refresh 执行一次解析，失败时保留上一次的结果
+func (r *Resolver) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	endpoints, err := r.resolve(ctx)
+	if err == nil && len(endpoints) == 0 {
+		err = ErrNoEndpoints
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	if !equalEndpoints(r.endpoints, endpoints) {
+		log.Infof("上游服务地址已更新: name=%s, endpoints=%v", r.name, endpoints)
+	}
+	r.endpoints = endpoints
+	return nil
+}
+
+// resolve 根据模式解析出host:port列表
+func (r *Resolver) resolve(ctx context.Context) ([]string, error) {
+	var endpoints []string
+
+	switch r.mode {
+	case ModeSRV:
+		_, records, err := lookupSRV(ctx, "", "", r.name)
+		if err != nil {
+			return nil, fmt.Errorf("解析SRV记录失败: %w", err)
+		}
+		for _, srv := range records {
+			host := trimDot(srv.Target)
+			endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(int(srv.Port))))
+		}
+	case ModeDNS:
+		hosts, err := lookupHost(ctx, r.name)
+		if err != nil {
+			return nil, fmt.Errorf("解析DNS记录失败: %w", err)
+		}
+		for _, host := range hosts {
+			endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(r.port)))
+		}
+	default:
+		return nil, ErrUnknownMode
+	}
+
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// trimDot 去掉SRV目标主机名末尾的点
+func trimDot(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host[:len(host)-1]
+	}
+	return host
+}
+
+// equalEndpoints 比较两个已排序的地址列表是否相同
+func equalEndpoints(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
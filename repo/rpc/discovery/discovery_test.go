@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+// stubLookup 替换解析函数，测试结束后恢复
+func stubLookup(t *testing.T, srv func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error), host func(ctx context.Context, host string) ([]string, error)) {
+	savedSRV, savedHost := lookupSRV, lookupHost
+	t.Cleanup(func() { lookupSRV, lookupHost = savedSRV, savedHost })
+	lookupSRV, lookupHost = srv, host
+}
+
+func TestResolve(t *testing.T) {
+	hosts := []string{"10.0.0.2", "10.0.0.1"}
+	var lookupErr error
+	stubLookup(t,
+		func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			return "", []*net.SRV{{Target: "b.node.local.", Port: 8332}, {Target: "a.node.local.", Port: 8333}}, nil
+		},
+		func(ctx context.Context, host string) ([]string, error) {
+			return hosts, lookupErr
+		})
+
+	srv, err := NewResolver(ModeSRV, "_rpc._tcp.node.local", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	// SRV目标去掉末尾的点，使用记录中的端口，结果排序
+	if got := srv.Endpoints(); !slices.Equal(got, []string{"a.node.local:8333", "b.node.local:8332"}) {
+		t.Errorf("SRV解析结果 = %v", got)
+	}
+
+	dns, err := NewResolver(ModeDNS, "node.local", 8332, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dns.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.0.1:8332", "10.0.0.2:8332"}
+	if got := dns.Endpoints(); !slices.Equal(got, want) {
+		t.Errorf("DNS解析结果 = %v", got)
+	}
+
+	// 按轮询返回各地址
+	first, _ := dns.Next()
+	second, _ := dns.Next()
+	third, _ := dns.Next()
+	if first == second || first != third {
+		t.Errorf("轮询结果 = %s %s %s", first, second, third)
+	}
+
+	// 解析失败时保留上一次的结果
+	lookupErr = errors.New("timeout")
+	if err := dns.refresh(); err == nil {
+		t.Fatal("解析失败时应返回错误")
+	}
+	if got := dns.Endpoints(); !slices.Equal(got, want) {
+		t.Errorf("解析失败后地址 = %v", got)
+	}
+
+	if _, err := NewResolver("consul", "node.local", 0, 0); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("未知模式错误 = %v", err)
+	}
+	empty, _ := NewResolver(ModeDNS, "node.local", 8332, 0)
+	if _, err := empty.Next(); !errors.Is(err, ErrNoEndpoints) {
+		t.Errorf("没有地址时错误 = %v", err)
+	}
+}
+
+func TestURLPort(t *testing.T) {
+	cases := map[string]int{
+		"http://node.local:8332":  8332,
+		"http://node.local":       80,
+		"https://node.local":      443,
+		"https://node.local:8443": 8443,
+	}
+	for raw, want := range cases {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := URLPort(u); got != want {
+			t.Errorf("URLPort(%s) = %d, 期望 %d", raw, got, want)
+		}
+	}
+}
+
+func TestTransportDialsAddress(t *testing.T) {
+	var host string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer server.Close()
+
+	// 测试证书签发给example.com，请求URL保留主机名时TLS校验才能通过
+	base := server.Client().Transport.(*http.Transport).Clone()
+	client := &http.Client{Transport: NewTransport(base)}
+
+	target, err := DialURL("https://example.com/rpc", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Post(target, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if host != "example.com" {
+		t.Errorf("Host请求头 = %q, 期望 example.com", host)
+	}
+
+	// 没有拨号地址时按URL中的主机拨号
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if host != server.Listener.Addr().String() {
+		t.Errorf("Host请求头 = %q", host)
+	}
+}
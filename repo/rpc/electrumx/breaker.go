@@ -0,0 +1,32 @@
+package electrumx
+
+import (
+	"context"
+
+	"ginproject/entity/config"
+	"ginproject/repo/rpc/breaker"
+)
+
+// ElectrumX调用断路器，Init时按配置创建，未初始化时调用不经过断路器
+var electrumXBreaker *breaker.Breaker
+
+// initBreaker 根据配置创建断路器，服务端返回的业务错误不计为故障
+func initBreaker(cfg *config.ElectrumXConfig) {
+	electrumXBreaker = breaker.NewFromConfig("ElectrumX", cfg.Breaker, isServerFailure)
+}
+
+// withBreaker 经过断路器执行一次调用，断路器打开或并发数达到上限时直接返回错误
+func withBreaker(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	if electrumXBreaker == nil {
+		return call(ctx)
+	}
+	return electrumXBreaker.Do(ctx, method, call)
+}
+
+// BreakerStats 返回各方法的断路器状态，未初始化时返回nil
+func BreakerStats() []breaker.MethodStats {
+	if electrumXBreaker == nil {
+		return nil
+	}
+	return electrumXBreaker.Stats()
+}
@@ -0,0 +1,314 @@
+package electrumx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"ginproject/middleware/log"
+)
+
+const (
+	// 最大允许的响应大小(10MB)，防止异常大的响应
+	maxResponseSize = 10 * 1024 * 1024
+	// 连接读缓冲区大小
+	readerBufferSize = 64 * 1024
+	// 超过该容量的缓冲区不放回池中，避免个别大响应长期占用内存
+	maxPooledBufferSize = 1024 * 1024
+	// 响应异常时日志中最多记录的字节数
+	maxLoggedResponseSize = 500
+	// 逐项解码的响应不保留原始数据，允许的大小上限(64MB)高于整体读取的响应
+	maxStreamedResponseSize = 64 * 1024 * 1024
+)
+
+var (
+	// ErrResponseTooLarge 响应超过大小限制
+	ErrResponseTooLarge = errors.New("RPC响应数据过大，超过大小限制")
+	// ErrResponseIDMismatch 响应ID与请求ID不一致
+	ErrResponseIDMismatch = errors.New("RPC响应ID与请求不匹配")
+	// ErrIncompleteResponse 连接关闭但响应不完整
+	ErrIncompleteResponse = errors.New("连接关闭但未收到完整响应")
+)
+
+var (
+	readerPool = sync.Pool{New: func() any { return bufio.NewReaderSize(nil, readerBufferSize) }}
+	bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+)
+
+// getReader 从池中获取读缓冲区并绑定到连接
+func getReader(r io.Reader) *bufio.Reader {
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(r)
+	return reader
+}
+
+// putReader 解除读缓冲区与连接的绑定并放回池中
+func putReader(reader *bufio.Reader) {
+	reader.Reset(nil)
+	readerPool.Put(reader)
+}
+
+// getBuffer 从池中获取一个空的字节缓冲区
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer 将字节缓冲区放回池中，过大的缓冲区直接丢弃
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// rpcEnvelope 流式解码时使用的外层结构，Result指向调用方提供的目标对象
+type rpcEnvelope struct {
+	ID     int         `json:"id"`
+	Result interface{} `json:"result"`
+	Error  *RPCError   `json:"error"`
+}
+
+// writeRPCRequest 编码请求并写入连接，ElectrumX要求每个请求以换行符结束
+func writeRPCRequest(conn net.Conn, req RPCRequest) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	// Encode会在末尾追加换行符
+	if err := json.NewEncoder(buf).Encode(req); err != nil {
+		return fmt.Errorf("序列化RPC请求失败: %w", err)
+	}
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		log.Error("发送RPC请求失败:", err)
+		return fmt.Errorf("发送RPC请求失败: %w", err)
+	}
+	return nil
+}
+
+// readRPCResponse 读取一行响应并返回原始结果
+// 读缓冲区和行缓冲区都取自池中，解码到json.RawMessage时会复制数据，缓冲区可以安全复用
+func readRPCResponse(conn net.Conn, id int, method string) (json.RawMessage, error) {
+	reader := getReader(conn)
+	defer putReader(reader)
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := readLine(reader, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("连接关闭但未收到完整响应:", truncateResponse(buf.Bytes()))
+			return nil, ErrIncompleteResponse
+		}
+		if errors.Is(err, ErrResponseTooLarge) {
+			log.Error("RPC响应超过大小限制(", maxResponseSize/1024/1024, "MB)")
+			return nil, err
+		}
+		log.Error("读取RPC响应失败:", err)
+		return nil, fmt.Errorf("读取RPC响应失败: %w", err)
+	}
+
+	var resp RPCResponse
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		log.Error("解析RPC响应失败:", truncateResponse(buf.Bytes()))
+		return nil, fmt.Errorf("解析RPC响应失败: %w", err)
+	}
+	if err := checkResponse(resp.ID, resp.Error, id); err != nil {
+		return nil, err
+	}
+
+	log.Debug("成功接收ElectrumX RPC响应:", "method:", method, "大小:", buf.Len(), "字节")
+	return resp.Result, nil
+}
+
+// decodeRPCResponse 使用json.Decoder直接从连接流式解码响应到out，不再保留整行原始数据
+// 适用于完整历史、大区块等大响应
+func decodeRPCResponse(conn net.Conn, id int, method string, out interface{}) error {
+	reader := getReader(conn)
+	defer putReader(reader)
+
+	envelope := rpcEnvelope{Result: out}
+	decoder := json.NewDecoder(io.LimitReader(reader, maxResponseSize))
+	if err := decoder.Decode(&envelope); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			log.Error("连接关闭但未收到完整响应:", "method:", method)
+			return ErrIncompleteResponse
+		}
+		log.Error("解析RPC响应失败:", "method:", method, "错误:", err)
+		return fmt.Errorf("解析RPC响应失败: %w", err)
+	}
+	if err := checkResponse(envelope.ID, envelope.Error, id); err != nil {
+		return err
+	}
+
+	log.Debug("成功流式解码ElectrumX RPC响应:", "method:", method, "大小:", decoder.InputOffset(), "字节")
+	return nil
+}
+
+// decodeRPCResponseEach 从连接逐个读取token解码响应，结果数组中的每个元素交给each解码处理
+// 原始响应和完整的结果数组都不在内存中保留，each返回错误时立即停止读取，连接不能再复用
+func decodeRPCResponseEach(conn net.Conn, id int, method string, each func(dec *json.Decoder) error) error {
+	reader := getReader(conn)
+	defer putReader(reader)
+
+	limited := &limitedReader{r: reader, remaining: maxStreamedResponseSize}
+	decoder := json.NewDecoder(limited)
+	err := decodeEnvelopeEach(decoder, id, each)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		log.Debug("成功逐项解码ElectrumX RPC响应:", "method:", method, "大小:", decoder.InputOffset(), "字节")
+		return nil
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || (limited.eof && errors.As(err, &syntaxErr)):
+		log.Error("连接关闭但未收到完整响应:", "method:", method)
+		return ErrIncompleteResponse
+	case errors.Is(err, ErrResponseTooLarge):
+		log.Error("RPC响应超过大小限制(", maxStreamedResponseSize/1024/1024, "MB)", "method:", method)
+		return err
+	default:
+		return err
+	}
+}
+
+// decodeEnvelopeEach 按token解码外层结构，result字段逐项交给each处理
+// ElectrumX可能把id放在result之后，因此ID在读完整个响应后才校验，调用方在出错时应丢弃已处理的元素
+func decodeEnvelopeEach(decoder *json.Decoder, id int, each func(dec *json.Decoder) error) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	respID := 0
+	var rpcErr *RPCError
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("解析RPC响应失败: %w", err)
+		}
+		key, _ := token.(string)
+		switch key {
+		case "id":
+			err = decoder.Decode(&respID)
+		case "error":
+			err = decoder.Decode(&rpcErr)
+		case "result":
+			err = decodeEach(decoder, each)
+		default:
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	return checkResponse(respID, rpcErr, id)
+}
+
+// decodeEach 逐项解码JSON数组，null按空数组处理
+func decodeEach(decoder *json.Decoder, each func(dec *json.Decoder) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("解析RPC结果失败: %w", err)
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("解析RPC结果失败: 结果不是数组")
+	}
+	for decoder.More() {
+		if err := each(decoder); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim 读取下一个token并校验是否为指定的分隔符
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("解析RPC响应失败: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("解析RPC响应失败: 期望%s，实际为%v", want, token)
+	}
+	return nil
+}
+
+// limitedReader 读取超过上限时返回ErrResponseTooLarge，区别于连接提前关闭
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	eof       bool // 连接已关闭，此时的语法错误由响应不完整引起
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if errors.Is(err, io.EOF) {
+		l.eof = true
+	}
+	return n, err
+}
+
+// checkResponse 校验响应ID并转换RPC错误
+func checkResponse(respID int, rpcErr *RPCError, id int) error {
+	if respID != id {
+		log.Warn("RPC响应ID不匹配:", "期望:", id, "实际:", respID)
+		return ErrResponseIDMismatch
+	}
+	if rpcErr != nil {
+		log.Warn("RPC调用错误:", rpcErr.Message, "(代码:", rpcErr.Code, ")")
+		return fmt.Errorf("RPC调用错误: %w", rpcErr)
+	}
+	return nil
+}
+
+// readLine 读取一条完整的非空响应行到buf中，超过大小限制时返回ErrResponseTooLarge
+// 连接关闭且没有读到任何数据时返回io.EOF
+func readLine(reader *bufio.Reader, buf *bytes.Buffer) error {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		buf.Write(chunk)
+		if buf.Len() > maxResponseSize {
+			return ErrResponseTooLarge
+		}
+
+		switch {
+		case err == nil:
+			// 跳过上一个响应遗留的空行
+			if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+				buf.Reset()
+				continue
+			}
+			return nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(bytes.TrimSpace(buf.Bytes())) > 0:
+			// 服务端发送最后一条响应后直接关闭连接时可能没有换行符，交给解析阶段判断是否完整
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// truncateResponse 截断响应数据用于日志记录
+func truncateResponse(data []byte) string {
+	if len(data) > maxLoggedResponseSize {
+		return string(data[:maxLoggedResponseSize]) + "... [截断]"
+	}
+	return string(data)
+}
@@ -0,0 +1,134 @@
+package electrumx
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serve 在管道的服务端写入原始响应后关闭连接
+func serve(t *testing.T, response string) net.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		server.Write([]byte(response))
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestReadRPCResponse(t *testing.T) {
+	// 超过读缓冲区大小的单行响应
+	large := `{"jsonrpc":"2.0","id":1,"result":"` + strings.Repeat("a", readerBufferSize*2) + `"}` + "\n"
+
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantErr  error
+	}{
+		{name: "普通响应", response: `{"jsonrpc":"2.0","id":1,"result":[1,2]}` + "\n", want: `[1,2]`},
+		{name: "跳过遗留空行", response: "\n\r\n" + `{"jsonrpc":"2.0","id":1,"result":true}` + "\n", want: `true`},
+		{name: "末尾没有换行", response: `{"jsonrpc":"2.0","id":1,"result":null}`, want: `null`},
+		{name: "超过读缓冲区", response: large, want: `"` + strings.Repeat("a", readerBufferSize*2) + `"`},
+		{name: "ID不匹配", response: `{"jsonrpc":"2.0","id":2,"result":1}` + "\n", wantErr: ErrResponseIDMismatch},
+		{name: "连接提前关闭", response: "", wantErr: ErrIncompleteResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := readRPCResponse(serve(t, tt.response), 1, "test")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("期望错误%v，实际: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("读取响应失败: %v", err)
+			}
+			if string(result) != tt.want {
+				t.Fatalf("结果不一致: %.50s", result)
+			}
+		})
+	}
+}
+
+func TestReadRPCResponseError(t *testing.T) {
+	conn := serve(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"bad"}}`+"\n")
+	_, err := readRPCResponse(conn, 1, "test")
+	if err == nil || !strings.Contains(err.Error(), "代码: -32600") {
+		t.Fatalf("期望RPC错误，实际: %v", err)
+	}
+}
+
+func TestDecodeRPCResponse(t *testing.T) {
+	var history []struct {
+		TxHash string `json:"tx_hash"`
+		Height int64  `json:"height"`
+	}
+	conn := serve(t, `{"jsonrpc":"2.0","id":7,"result":[{"tx_hash":"aa","height":10},{"tx_hash":"bb","height":0}]}`+"\n")
+	if err := decodeRPCResponse(conn, 7, "test", &history); err != nil {
+		t.Fatalf("流式解码失败: %v", err)
+	}
+	if len(history) != 2 || history[0].TxHash != "aa" || history[1].Height != 0 {
+		t.Fatalf("解码结果不一致: %+v", history)
+	}
+
+	var result json.RawMessage
+	if err := decodeRPCResponse(serve(t, `{"jsonrpc":"2.0","id":7,"res`), 7, "test", &result); !errors.Is(err, ErrIncompleteResponse) {
+		t.Fatalf("期望响应不完整错误，实际: %v", err)
+	}
+}
+
+func TestDecodeRPCResponseEach(t *testing.T) {
+	type item struct {
+		TxHash string `json:"tx_hash"`
+	}
+	collect := func(response string, id int) ([]string, error) {
+		var hashes []string
+		err := decodeRPCResponseEach(serve(t, response), id, "test", func(dec *json.Decoder) error {
+			var v item
+			if err := dec.Decode(&v); err != nil {
+				return err
+			}
+			hashes = append(hashes, v.TxHash)
+			return nil
+		})
+		return hashes, err
+	}
+
+	// id在result之后，未知字段跳过
+	hashes, err := collect(`{"jsonrpc":"2.0","result":[{"tx_hash":"aa"},{"tx_hash":"bb"}],"extra":{"a":[1]},"id":3}`+"\n", 3)
+	if err != nil || strings.Join(hashes, ",") != "aa,bb" {
+		t.Fatalf("逐项解码结果 = %v, %v", hashes, err)
+	}
+	if hashes, err := collect(`{"jsonrpc":"2.0","id":3,"result":null}`, 3); err != nil || len(hashes) != 0 {
+		t.Fatalf("null结果 = %v, %v", hashes, err)
+	}
+	if _, err := collect(`{"jsonrpc":"2.0","result":[],"id":4}`, 3); !errors.Is(err, ErrResponseIDMismatch) {
+		t.Fatalf("期望ID不匹配错误，实际: %v", err)
+	}
+	if _, err := collect(`{"jsonrpc":"2.0","id":3,"error":{"code":1,"message":"bad"}}`, 3); err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Fatalf("期望RPC错误，实际: %v", err)
+	}
+	if _, err := collect(`{"jsonrpc":"2.0","id":3,"result":[{"tx_hash":"aa"},`, 3); !errors.Is(err, ErrIncompleteResponse) {
+		t.Fatalf("期望响应不完整错误，实际: %v", err)
+	}
+
+	// each返回错误时停止读取
+	stop := errors.New("stop")
+	calls := 0
+	err = decodeRPCResponseEach(serve(t, `{"id":1,"result":[1,2,3]}`), 1, "test", func(dec *json.Decoder) error {
+		calls++
+		var v int
+		dec.Decode(&v)
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("each出错后 = %v, 调用%d次", err, calls)
+	}
+}
@@ -0,0 +1,280 @@
+package electrumx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+)
+
+// 协商协议版本时上报的客户端名称和支持的协议版本范围
+const (
+	clientName  = "tbcapi"
+	protocolMin = "1.4"
+	protocolMax = "1.4.2"
+)
+
+// JSON-RPC中表示方法或参数不被支持的错误码
+const (
+	rpcCodeMethodNotFound = -32601
+	rpcCodeInvalidParams  = -32602
+)
+
+// Capability ElectrumX服务器的可选能力，标准协议之外的扩展方法和参数
+type Capability string
+
+const (
+	// CapabilityFrozenBalance blockchain.scripthash.get_frozen_balance方法
+	CapabilityFrozenBalance Capability = "frozen_balance"
+	// CapabilityHistoryFromHeight blockchain.scripthash.get_history的from_height参数
+	CapabilityHistoryFromHeight Capability = "history_from_height"
+	// CapabilityHistoryMaxCount blockchain.scripthash.get_history的max_count参数，位于from_height之后
+	CapabilityHistoryMaxCount Capability = "history_max_count"
+)
+
+// ErrCapabilityUnsupported 当前ElectrumX服务器不支持该功能
+var ErrCapabilityUnsupported = errors.New("ElectrumX服务器不支持该功能")
+
+// ServerInfo 协商得到的服务器信息
+type ServerInfo struct {
+	Software string `json:"software"` // 服务器软件版本
+	Protocol string `json:"protocol"` // 协商的协议版本
+}
+
+// methodCapabilities 整个方法都属于可选能力的扩展方法
+var methodCapabilities = map[string]Capability{
+	"blockchain.scripthash.get_frozen_balance": CapabilityFrozenBalance,
+}
+
+// optionalParam 依赖可选能力的位置参数，发送前按连接所在的服务器解析
+// 服务器支持时展开为value，已知不支持时省略该参数及其后的所有参数
+type optionalParam struct {
+	capability Capability
+	value      interface{}
+}
+
+// serverState 单个服务器协商得到的信息和已确认不支持的能力
+type serverState struct {
+	info        ServerInfo
+	unsupported map[Capability]bool
+}
+
+// serverCapabilities 按服务器地址记录服务器信息和已确认不支持的能力
+// 可选能力默认视为支持，扩展方法返回方法不存在、或去掉可选参数重试后成功时只标记返回错误的服务器；服务器版本变化后重新探测该服务器
+type serverCapabilities struct {
+	mu      sync.RWMutex
+	servers map[string]*serverState
+}
+
+var serverCaps = newServerCapabilities()
+
+func newServerCapabilities() *serverCapabilities {
+	return &serverCapabilities{servers: make(map[string]*serverState)}
+}
+
+// record 记录到address的新连接协商得到的服务器信息，不影响其它服务器的探测结果
+func (s *serverCapabilities) record(address string, info ServerInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.servers[address]
+	if ok && state.info == info {
+		return
+	}
+	if ok {
+		log.Warn("ElectrumX服务器版本变化，重新探测可选能力:", address, state.info.Software, state.info.Protocol, "->", info.Software, info.Protocol)
+	}
+	s.servers[address] = &serverState{info: info, unsupported: make(map[Capability]bool)}
+}
+
+// supports 判断address上的服务器是否支持指定能力
+func (s *serverCapabilities) supports(address string, capability Capability) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.servers[address]
+	return !ok || !state.unsupported[capability]
+}
+
+// info 返回address上的服务器信息，尚未建立过连接时返回false
+func (s *serverCapabilities) info(address string) (ServerInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.servers[address]
+	if !ok {
+		return ServerInfo{}, false
+	}
+	return state.info, true
+}
+
+// markUnsupportedOn 扩展方法返回方法不存在时在返回错误的服务器上记录该能力不支持并返回true
+// 参数无效可能由请求本身引起，不能据此判断，可选参数需去掉后重试确认再调用markUnsupported
+func (s *serverCapabilities) markUnsupportedOn(capability Capability, err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpcCodeMethodNotFound {
+		return false
+	}
+	s.markUnsupported(rpcErr.Server, capability)
+	return true
+}
+
+// markUnsupported 记录address上的服务器不支持该能力
+func (s *serverCapabilities) markUnsupported(address string, capability Capability) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.servers[address]
+	if !ok {
+		state = &serverState{unsupported: make(map[Capability]bool)}
+		s.servers[address] = state
+	}
+	if !state.unsupported[capability] {
+		log.Warn("ElectrumX服务器不支持可选能力:", capability, "服务器:", address, state.info.Software, "协议:", state.info.Protocol)
+	}
+	state.unsupported[capability] = true
+}
+
+// resolveParams 按连接所在的服务器解析请求参数
+// 方法本身属于该服务器已知不支持的能力时不发送请求，返回与服务器相同的方法不存在错误
+func (s *serverCapabilities) resolveParams(address, method string, params interface{}) (interface{}, error) {
+	if capability, ok := methodCapabilities[method]; ok && !s.supports(address, capability) {
+		return nil, &RPCError{Code: rpcCodeMethodNotFound, Message: "unsupported method " + method, Server: address}
+	}
+
+	list, ok := params.([]interface{})
+	if !ok {
+		return params, nil
+	}
+	for i := range list {
+		if _, ok := list[i].(optionalParam); !ok {
+			continue
+		}
+		resolved := append(make([]interface{}, 0, len(list)), list[:i]...)
+		for _, param := range list[i:] {
+			if opt, ok := param.(optionalParam); ok {
+				if !s.supports(address, opt.capability) {
+					break
+				}
+				param = opt.value
+			}
+			resolved = append(resolved, param)
+		}
+		return resolved, nil
+	}
+	return params, nil
+}
+
+// invalidParamsServer 错误为服务端返回的参数无效时返回该服务器的地址
+func invalidParamsServer(err error) (string, bool) {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpcCodeInvalidParams {
+		return "", false
+	}
+	return rpcErr.Server, true
+}
+
+// withoutOptionalParam 返回去掉capability对应的可选参数及其后所有参数的请求参数，用于确认服务器是否不支持该参数
+func withoutOptionalParam(params []interface{}, capability Capability) []interface{} {
+	for i, param := range params {
+		if opt, ok := param.(optionalParam); ok && opt.capability == capability {
+			return params[:i:i]
+		}
+	}
+	return params
+}
+
+// attributeError 为服务端返回的RPC错误记录所在的服务器，能力探测据此只标记该服务器
+func attributeError(err error, address string) {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Server == "" {
+		rpcErr.Server = address
+	}
+}
+
+// preferredAddress 返回新建连接优先使用的服务器，即排在最前的健康服务器，未启用故障切换时为host:port
+func preferredAddress() string {
+	if set := currentServerSet(); set != nil {
+		statuses := set.Status()
+		for _, status := range statuses {
+			if status.Healthy {
+				return status.Address
+			}
+		}
+		if len(statuses) > 0 {
+			return statuses[0].Address
+		}
+		return ""
+	}
+	cfg := config.GetConfig().GetElectrumXConfig()
+	if cfg == nil {
+		return ""
+	}
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+}
+
+// Supports 判断当前优先使用的ElectrumX服务器是否支持指定能力
+// 实际调用按连接所在的服务器判断，这里只用于展示和预判
+func Supports(capability Capability) bool {
+	return serverCaps.supports(preferredAddress(), capability)
+}
+
+// GetServerInfo 获取当前优先使用的服务器协商得到的信息，该服务器尚未连接过时返回任一已连接服务器的信息
+// 尚未建立过连接时为空
+func GetServerInfo() ServerInfo {
+	if info, ok := serverCaps.info(preferredAddress()); ok {
+		return info
+	}
+
+	serverCaps.mu.RLock()
+	defer serverCaps.mu.RUnlock()
+	addresses := make([]string, 0, len(serverCaps.servers))
+	for address, state := range serverCaps.servers {
+		if state.info != (ServerInfo{}) {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) == 0 {
+		return ServerInfo{}
+	}
+	sort.Strings(addresses)
+	return serverCaps.servers[addresses[0]].info
+}
+
+// negotiateVersion 在新连接上发送server.version协商协议版本，协议要求这是连接上的第一个请求
+func negotiateVersion(conn net.Conn, id int) (ServerInfo, error) {
+	req := RPCRequest{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  "server.version",
+		Params:  []interface{}{clientName, []string{protocolMin, protocolMax}},
+	}
+	if err := writeRPCRequest(conn, req); err != nil {
+		return ServerInfo{}, err
+	}
+	result, err := readRPCResponse(conn, id, req.Method)
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("协商协议版本失败: %w", err)
+	}
+
+	var version []string
+	if err := json.Unmarshal(result, &version); err != nil || len(version) != 2 {
+		return ServerInfo{}, fmt.Errorf("协商协议版本失败: 无法解析响应%s", truncateResponse(result))
+	}
+	return ServerInfo{Software: version[0], Protocol: version[1]}, nil
+}
+
+// ServerVersion 获取协商得到的服务器版本信息，尚未建立过连接时先发起一次请求触发协商
+func ServerVersion(ctx context.Context) (ServerInfo, error) {
+	if info := GetServerInfo(); info != (ServerInfo{}) {
+		return info, nil
+	}
+	if _, err := CallMethod(ctx, "server.ping", []interface{}{}); err != nil {
+		return ServerInfo{}, err
+	}
+	return GetServerInfo(), nil
+}
@@ -0,0 +1,171 @@
+package electrumx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+)
+
+// serveVersion 读取客户端的server.version请求后返回指定的协议版本
+func serveVersion(t *testing.T, software, protocol string) (net.Conn, <-chan RPCRequest) {
+	t.Helper()
+	client, server := net.Pipe()
+	requests := make(chan RPCRequest, 1)
+	go func() {
+		defer server.Close()
+		line, err := bufio.NewReader(server).ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req RPCRequest
+		json.Unmarshal(line, &req)
+		requests <- req
+		fmt.Fprintf(server, `{"jsonrpc":"2.0","id":%d,"result":[%q,%q]}`+"\n", req.ID, software, protocol)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client, requests
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	conn, requests := serveVersion(t, "ElectrumX 1.16.0", "1.4")
+	info, err := negotiateVersion(conn, 3)
+	if err != nil {
+		t.Fatalf("协商协议版本失败: %v", err)
+	}
+	if info.Software != "ElectrumX 1.16.0" || info.Protocol != "1.4" {
+		t.Fatalf("协商结果不一致: %+v", info)
+	}
+
+	req := <-requests
+	params, _ := json.Marshal(req.Params)
+	if req.Method != "server.version" || string(params) != `["tbcapi",["1.4","1.4.2"]]` {
+		t.Fatalf("协商请求不正确: %s %s", req.Method, params)
+	}
+}
+
+func TestCapabilityDetection(t *testing.T) {
+	caps := newServerCapabilities()
+	caps.record("a:50001", ServerInfo{Software: "ElectrumX 1.16.0", Protocol: "1.4"})
+	caps.record("b:50001", ServerInfo{Software: "ElectrumX 1.16.0", Protocol: "1.4"})
+
+	// 普通RPC错误不影响能力判断
+	daemonErr := checkResponse(1, &RPCError{Code: 2, Message: "daemon error"}, 1)
+	attributeError(daemonErr, "a:50001")
+	if caps.markUnsupportedOn(CapabilityFrozenBalance, daemonErr) {
+		t.Fatal("非方法不存在错误不应标记为不支持")
+	}
+	if !caps.supports("a:50001", CapabilityFrozenBalance) {
+		t.Fatal("可选能力默认应视为支持")
+	}
+
+	invalid := checkResponse(1, &RPCError{Code: rpcCodeInvalidParams, Message: "invalid params"}, 1)
+	attributeError(invalid, "a:50001")
+	if caps.markUnsupportedOn(CapabilityFrozenBalance, invalid) {
+		t.Fatal("参数无效可能由请求本身引起，不应标记为不支持")
+	}
+
+	notFound := checkResponse(1, &RPCError{Code: rpcCodeMethodNotFound, Message: "unknown method"}, 1)
+	attributeError(notFound, "a:50001")
+	if !caps.markUnsupportedOn(CapabilityFrozenBalance, fmt.Errorf("获取冻结余额失败: %w", notFound)) || caps.supports("a:50001", CapabilityFrozenBalance) {
+		t.Fatal("方法不存在时应标记为不支持")
+	}
+	if !caps.supports("a:50001", CapabilityHistoryFromHeight) {
+		t.Fatal("其他能力不应受影响")
+	}
+	if !caps.supports("b:50001", CapabilityFrozenBalance) {
+		t.Fatal("只应标记返回错误的服务器")
+	}
+
+	// 同一服务器重复连接保留探测结果，其它服务器版本变化不影响该服务器，该服务器版本变化后重新探测
+	caps.record("a:50001", ServerInfo{Software: "ElectrumX 1.16.0", Protocol: "1.4"})
+	caps.record("b:50001", ServerInfo{Software: "ElectrumX 1.17.0", Protocol: "1.4.2"})
+	if caps.supports("a:50001", CapabilityFrozenBalance) {
+		t.Fatal("同一服务器不应重置探测结果")
+	}
+	caps.record("a:50001", ServerInfo{Software: "ElectrumX 1.17.0", Protocol: "1.4.2"})
+	if !caps.supports("a:50001", CapabilityFrozenBalance) {
+		t.Fatal("服务器变化后应重新探测")
+	}
+}
+
+func TestResolveParams(t *testing.T) {
+	caps := newServerCapabilities()
+	caps.markUnsupported("a:50001", CapabilityHistoryFromHeight)
+	caps.markUnsupportedOn(CapabilityFrozenBalance, &RPCError{Code: rpcCodeMethodNotFound, Message: "unknown method", Server: "a:50001"})
+
+	params := historyParams("hash", 100, 0)
+	for address, want := range map[string]string{"a:50001": `["hash"]`, "b:50001": `["hash",100]`} {
+		resolved, err := caps.resolveParams(address, "blockchain.scripthash.get_history", params)
+		encoded, _ := json.Marshal(resolved)
+		if err != nil || string(encoded) != want {
+			t.Errorf("%s上的历史查询参数 = %s, %v", address, encoded, err)
+		}
+	}
+
+	// 条数参数位于起始高度之后，起始高度不被支持时一并省略
+	caps.markUnsupported("c:50001", CapabilityHistoryMaxCount)
+	params = historyParams("hash", 0, 20)
+	for address, want := range map[string]string{"a:50001": `["hash"]`, "b:50001": `["hash",0,20]`, "c:50001": `["hash",0]`} {
+		resolved, err := caps.resolveParams(address, "blockchain.scripthash.get_history", params)
+		encoded, _ := json.Marshal(resolved)
+		if err != nil || string(encoded) != want {
+			t.Errorf("%s上的分页历史查询参数 = %s, %v", address, encoded, err)
+		}
+	}
+
+	// 已知不支持的扩展方法不发送请求，返回方法不存在错误
+	_, err := caps.resolveParams("a:50001", "blockchain.scripthash.get_frozen_balance", []interface{}{"hash"})
+	if !caps.markUnsupportedOn(CapabilityFrozenBalance, err) {
+		t.Fatalf("不支持的方法应返回方法不存在错误: %v", err)
+	}
+	if _, err := caps.resolveParams("b:50001", "blockchain.scripthash.get_frozen_balance", []interface{}{"hash"}); err != nil {
+		t.Fatalf("其它服务器不应受影响: %v", err)
+	}
+}
+
+func TestHistoryParamConfirmedByRetry(t *testing.T) {
+	server := newFakeServer(t)
+	// 服务器支持起始高度参数但不支持条数参数，脚本哈希无效时无论参数如何都返回参数无效
+	var requests atomic.Int32
+	server.respond = func(req RPCRequest) string {
+		requests.Add(1)
+		params, _ := req.Params.([]interface{})
+		if len(params) > 2 || params[0] == "bad" {
+			return fmt.Sprintf(`"error":{"code":%d,"message":"invalid params"}`, rpcCodeInvalidParams)
+		}
+		return `"result":[{"tx_hash":"aa","height":10}]`
+	}
+	server.healthy.Store(true)
+	stubDefaultClient(t, func() (*ElectrumXClient, error) {
+		return &ElectrumXClient{config: server.config()}, nil
+	})
+	savedCaps := serverCaps
+	serverCaps = newServerCapabilities()
+	t.Cleanup(func() { serverCaps = savedCaps })
+	ctx := context.Background()
+
+	// 去掉可选参数重试后仍返回参数无效，错误由请求本身引起，不标记
+	if _, err := GetScriptHashHistoryFrom(ctx, "bad", 5, 2); err == nil {
+		t.Fatal("无效的脚本哈希应返回错误")
+	}
+	if !serverCaps.supports(server.address(), CapabilityHistoryMaxCount) || !serverCaps.supports(server.address(), CapabilityHistoryFromHeight) {
+		t.Fatal("请求本身无效时不应标记可选参数不支持")
+	}
+
+	// 去掉条数参数重试成功，只标记条数参数不支持
+	requests.Store(0)
+	history, err := GetScriptHashHistoryFrom(ctx, "good", 5, 2)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("历史查询 = %v, %v", history, err)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("发送了%d次请求, 期望原请求和一次确认重试", requests.Load())
+	}
+	if serverCaps.supports(server.address(), CapabilityHistoryMaxCount) || !serverCaps.supports(server.address(), CapabilityHistoryFromHeight) {
+		t.Fatal("重试成功后应只标记条数参数不支持")
+	}
+}
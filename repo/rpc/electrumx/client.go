@@ -1,21 +1,23 @@
 package electrumx
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"ginproject/entity/config"
+	"ginproject/middleware/healthscore"
 	"ginproject/middleware/log"
+	"ginproject/repo/rpc/async"
+	"ginproject/repo/rpc/rpctrace"
 )
 
 // RPCRequest 表示RPC请求
@@ -38,6 +40,12 @@ type RPCResponse struct {
 type RPCError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Server  string `json:"-"` // 返回错误的服务器地址
+}
+
+// Error 实现error接口
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (代码: %d)", e.Message, e.Code)
 }
 
 // AsyncResult 表示异步结果
@@ -56,6 +64,9 @@ type ElectrumXClient struct {
 	pool    *ConnPool
 	poolMu  sync.Mutex
 	usePool bool
+
+	// 对冲和切换服务器的请求发往指定服务器时复用的空闲连接
+	serverConns serverIdleConns
 }
 
 // 错误定义
@@ -110,6 +121,7 @@ func (c *ElectrumXClient) EnablePool() error {
 
 	c.pool = pool
 	c.usePool = true
+	healthscore.Upstream(healthscore.UpstreamElectrumX).SetPool(pool.Usage)
 	log.Info("ElectrumX客户端已启用连接池")
 	return nil
 }
@@ -120,6 +132,7 @@ func (c *ElectrumXClient) DisablePool() error {
 	defer c.poolMu.Unlock()
 
 	c.usePool = false
+	c.serverConns.closeAll()
 
 	if c.pool != nil {
 		if err := c.pool.Close(); err != nil {
@@ -127,6 +140,7 @@ func (c *ElectrumXClient) DisablePool() error {
 		}
 		c.pool = nil
 	}
+	healthscore.Upstream(healthscore.UpstreamElectrumX).SetPool(nil)
 
 	log.Info("ElectrumX客户端已禁用连接池")
 	return nil
@@ -146,98 +160,113 @@ func (c *ElectrumXClient) PoolStats() (idleConns, openConns int, err error) {
 }
 
 // Connect 连接到ElectrumX服务器，返回一个新连接由调用者管理
+// 启用多服务器故障切换时连接到优先级最高的健康服务器，建连结果计入该服务器的健康状态
 func (c *ElectrumXClient) Connect() (net.Conn, error) {
-	// 创建一个新连接
-	address := net.JoinHostPort(c.config.Host, fmt.Sprintf("%d", c.config.Port))
-	log.Info("正在创建ElectrumX连接, 服务器地址:", address)
+	return c.ConnectContext(context.Background())
+}
 
-	// 设置连接超时
-	dialer := &net.Dialer{
-		Timeout: time.Duration(c.config.Timeout) * time.Second,
+// ConnectContext 与Connect相同，建连和协议协商不晚于ctx的截止时间，ctx取消时中断
+// 调用方取消导致的失败不计入服务器的健康状态
+func (c *ElectrumXClient) ConnectContext(ctx context.Context) (net.Conn, error) {
+	address, err := c.dialAddress()
+	if err != nil {
+		return nil, err
 	}
-
-	var conn net.Conn
-	var err error
-
-	// 根据是否使用TLS创建连接
-	if c.config.UseTLS {
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // 注意：生产环境中应该验证证书
-		}
-		conn, err = tls.DialWithDialer(dialer, c.config.Protocol, address, tlsConfig)
-	} else {
-		conn, err = dialer.Dial(c.config.Protocol, address)
+	conn, err := c.connectTo(ctx, address)
+	if ctx.Err() == nil {
+		reportServer(address, err)
 	}
+	return conn, err
+}
 
+// connectTo 连接到指定服务器并协商协议版本
+func (c *ElectrumXClient) connectTo(ctx context.Context, address string) (net.Conn, error) {
+	log.Info("正在创建ElectrumX连接, 服务器地址:", address)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.Timeout)*time.Second)
+	defer cancel()
+	conn, err := dialServer(ctx, c.config, address)
 	if err != nil {
 		log.Error("创建ElectrumX连接失败:", err)
 		return nil, fmt.Errorf("创建连接失败: %w", err)
 	}
 
-	// 测试连接
-	deadline := time.Now().Add(time.Duration(c.config.Timeout) * time.Second)
+	// 测试连接，协商期间ctx取消时中断读写
+	deadline, _ := ctx.Deadline()
 	if err := conn.SetDeadline(deadline); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("设置连接超时失败: %w", err)
 	}
 
-	// 构建ping请求
-	id := int(atomic.AddInt32(&c.requestID, 1))
-	pingReq := RPCRequest{
-		JSONRPC: "2.0",
-		ID:      id,
-		Method:  "server.ping",
-		Params:  []interface{}{},
-	}
-
-	pingBytes, err := json.Marshal(pingReq)
-	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("序列化ping请求失败: %w", err)
-	}
-	pingBytes = append(pingBytes, '\n')
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
 
-	// 发送ping请求
-	if _, err = conn.Write(pingBytes); err != nil {
+	// 协商协议版本，同时确认连接可用
+	info, err := negotiateVersion(conn, int(atomic.AddInt32(&c.requestID, 1)))
+	// AfterFunc已经触发时连接的截止时间被改写，不能再使用
+	if !stop() {
 		conn.Close()
-		return nil, fmt.Errorf("发送ping请求失败: %w", err)
+		return nil, fmt.Errorf("创建连接失败: %w", ctx.Err())
 	}
-
-	// 读取响应
-	reader := bufio.NewReader(conn)
-	_, err = reader.ReadBytes('\n')
 	if err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("读取ping响应失败: %w", err)
+		return nil, err
 	}
+	serverCaps.record(address, info)
 
 	// 重置超时
 	conn.SetDeadline(time.Time{})
 
-	log.Info("成功创建并测试了ElectrumX连接")
-	return conn, nil
+	log.Info("成功创建ElectrumX连接, 服务器:", info.Software, "协议版本:", info.Protocol)
+	return &serverConn{Conn: conn, address: address}, nil
+}
+
+// dialServer 按配置的协议建立到服务器的连接，不做协议协商
+func dialServer(ctx context.Context, cfg *config.ElectrumXConfig, address string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout: time.Duration(cfg.Timeout) * time.Second,
+	}
+
+	// 根据是否使用TLS创建连接
+	if cfg.UseTLS {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config: &tls.Config{
+				InsecureSkipVerify: true, // 注意：生产环境中应该验证证书
+			},
+		}
+		return tlsDialer.DialContext(ctx, cfg.Protocol, address)
+	}
+	return dialer.DialContext(ctx, cfg.Protocol, address)
 }
 
-// CallRPC 调用ElectrumX RPC方法
+// CallRPC 经过断路器调用ElectrumX RPC方法
 func (c *ElectrumXClient) CallRPC(method string, params interface{}) (json.RawMessage, error) {
 	// 检查是否使用连接池
 	c.poolMu.Lock()
 	usePool := c.usePool && c.pool != nil
 	c.poolMu.Unlock()
 
-	if usePool {
-		// 使用连接池调用
-		return c.callRPCWithPool(context.Background(), method, params)
-	}
-
-	// 使用传统方式调用
-	return c.callRPCDirect(method, params)
+	var result json.RawMessage
+	err := withBreaker(context.Background(), method, func(ctx context.Context) error {
+		var err error
+		if usePool {
+			// 使用连接池调用
+			result, err = c.callRPCWithPool(ctx, method, params, nil)
+		} else {
+			// 使用传统方式调用
+			result, err = c.callRPCDirect(ctx, method, params)
+		}
+		return err
+	})
+	return result, err
 }
 
 // callRPCDirect 使用直接连接方式调用RPC
-func (c *ElectrumXClient) callRPCDirect(method string, params interface{}) (json.RawMessage, error) {
+func (c *ElectrumXClient) callRPCDirect(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
 	// 创建新连接
-	conn, err := c.Connect()
+	conn, err := c.ConnectContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -246,6 +275,65 @@ func (c *ElectrumXClient) callRPCDirect(method string, params interface{}) (json
 
 	// 获取请求ID
 	id := int(atomic.AddInt32(&c.requestID, 1))
+	start := time.Now()
+	result, err := c.roundTrip(ctx, conn, id, method, params)
+	rpctrace.Record(ctx, rpctrace.UpstreamElectrumX, method, strconv.Itoa(id), start, err)
+	if isServerFailure(ctx, err) {
+		reportServer(connAddress(conn), err)
+	}
+	return result, err
+}
+
+// callRPCWithPool 使用连接池调用RPC，routed不为nil时取得连接后以连接所在的服务器地址调用
+func (c *ElectrumXClient) callRPCWithPool(ctx context.Context, method string, params interface{}, routed func(address string)) (json.RawMessage, error) {
+	c.poolMu.Lock()
+	pool := c.pool
+	c.poolMu.Unlock()
+
+	if pool == nil {
+		return nil, ErrNoPool
+	}
+
+	// 获取一个连接
+	conn, err := pool.GetConn(ctx)
+	if err != nil {
+		log.Error("从连接池获取连接失败:", err)
+		return nil, fmt.Errorf("从连接池获取连接失败: %w", err)
+	}
+	if routed != nil {
+		routed(connAddress(conn))
+	}
+
+	// 获取请求ID
+	id := int(atomic.AddInt32(&c.requestID, 1))
+	start := time.Now()
+	result, err := c.roundTrip(ctx, conn, id, method, params)
+	rpctrace.Record(ctx, rpctrace.UpstreamElectrumX, method, strconv.Itoa(id), start, err)
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		pool.PutConn(conn)
+		return result, err
+	}
+
+	// 连接状态不确定，不再放回池中；调用方已取消时直接返回，服务器故障时幂等读请求切换到其它服务器重试
+	pool.DiscardConn(conn)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	address := connAddress(conn)
+	reportServer(address, err)
+	return c.callOtherServer(ctx, address, method, params, err)
+}
+
+// roundTrip 在连接上发送一次请求并读取响应，ElectrumX的请求ID为整数，trace上下文只通过调用记录关联
+// ctx取消时通过设置连接截止时间中断读写并返回ctx的错误，此时连接不能再复用
+func (c *ElectrumXClient) roundTrip(ctx context.Context, conn net.Conn, id int, method string, params interface{}) (json.RawMessage, error) {
+	// 按连接所在的服务器解析依赖可选能力的参数
+	address := connAddress(conn)
+	params, err := serverCaps.resolveParams(address, method, params)
+	if err != nil {
+		return nil, err
+	}
 
 	// 构建请求
 	req := RPCRequest{
@@ -255,118 +343,141 @@ func (c *ElectrumXClient) callRPCDirect(method string, params interface{}) (json
 		Params:  params,
 	}
 
-	// 编码请求
-	reqBytes, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("序列化RPC请求失败: %w", err)
-	}
-	reqBytes = append(reqBytes, '\n') // ElectrumX要求每个请求以换行符结束
-
 	// 记录日志
 	log.Debug("发送ElectrumX RPC请求:", "method:", method, "params:", params)
 
-	// 设置读写超时
+	// 设置读写超时，不晚于ctx的截止时间
 	deadline := time.Now().Add(time.Duration(c.config.Timeout) * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
 	if err := conn.SetDeadline(deadline); err != nil {
 		log.Warn("设置连接超时失败:", err)
 		return nil, fmt.Errorf("设置连接超时失败: %w", err)
 	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
 
-	// 发送请求
-	_, err = conn.Write(reqBytes)
+	// 发送请求并读取响应，读缓冲区从池中复用
+	err = writeRPCRequest(conn, req)
+	var result json.RawMessage
+	if err == nil {
+		result, err = readRPCResponse(conn, id, method)
+	}
+	if !stop() {
+		return nil, ctx.Err()
+	}
 	if err != nil {
-		log.Error("发送RPC请求失败:", err)
-		return nil, fmt.Errorf("发送RPC请求失败: %w", err)
+		attributeError(err, address)
+		return nil, err
 	}
 
-	// 使用bufio.Reader读取完整响应
-	reader := bufio.NewReader(conn)
-
-	// 使用bytes.Buffer收集响应数据
-	var responseBuffer bytes.Buffer
-
-	// 最大允许的响应大小(10MB)，防止异常大的响应
-	const maxResponseSize = 10 * 1024 * 1024
-
-	// 循环读取响应直到获取完整JSON或达到大小限制
-	for {
-		// 检查是否超出大小限制
-		if responseBuffer.Len() > maxResponseSize {
-			log.Error("RPC响应超过大小限制(", maxResponseSize/1024/1024, "MB)")
-			return nil, fmt.Errorf("RPC响应数据过大，超过%dMB限制", maxResponseSize/1024/1024)
-		}
+	// 重置超时
+	conn.SetDeadline(time.Time{})
+	return result, nil
+}
 
-		// 读取一行数据(ElectrumX响应通常以换行符结束)
-		line, err := reader.ReadBytes('\n')
-		if err != nil && err != io.EOF {
-			log.Error("读取RPC响应失败:", err)
-			return nil, fmt.Errorf("读取RPC响应失败: %w", err)
-		}
+// CallRPCWithContext 带上下文的RPC调用，支持连接池，调用经过断路器
+func (c *ElectrumXClient) CallRPCWithContext(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	// 检查是否使用连接池
+	c.poolMu.Lock()
+	usePool := c.usePool && c.pool != nil
+	c.poolMu.Unlock()
 
-		// 将读取的数据添加到缓冲区
-		if len(line) > 0 {
-			responseBuffer.Write(line)
+	var result json.RawMessage
+	err := withBreaker(ctx, method, func(ctx context.Context) error {
+		var err error
+		if usePool {
+			// 使用连接池调用，幂等读请求按配置对冲
+			result, err = c.callWithHedge(ctx, method, params)
+		} else {
+			// 非连接池调用每次新建连接，ctx取消时中断读写
+			result, err = c.callRPCDirect(ctx, method, params)
 		}
+		return err
+	})
+	return result, err
+}
 
-		// 尝试解析已收集的数据
-		responseData := responseBuffer.Bytes()
-		var resp RPCResponse
+// CallRPCInto 调用RPC并将结果直接解码到out，适用于完整历史、大区块等大响应
+// 使用连接池且未启用对冲时走流式解码路径，否则退化为先取原始结果再解码
+func (c *ElectrumXClient) CallRPCInto(ctx context.Context, method string, params interface{}, out interface{}) error {
+	c.poolMu.Lock()
+	usePool := c.usePool && c.pool != nil
+	c.poolMu.Unlock()
 
-		if jsonErr := json.Unmarshal(responseData, &resp); jsonErr == nil {
-			// 检查ID是否匹配
-			if resp.ID == id {
-				// 重置超时
-				conn.SetDeadline(time.Time{})
+	if usePool && electrumXHedger == nil {
+		return withBreaker(ctx, method, func(ctx context.Context) error {
+			return c.callRPCWithPoolDecode(ctx, method, params, func(conn net.Conn, id int) error {
+				return decodeRPCResponse(conn, id, method, out)
+			})
+		})
+	}
 
-				// 检查错误
-				if resp.Error != nil {
-					log.Warn("RPC调用错误:", resp.Error.Message, "(代码:", resp.Error.Code, ")")
-					return nil, fmt.Errorf("RPC调用错误: %s (代码: %d)", resp.Error.Message, resp.Error.Code)
-				}
+	result, err := c.CallRPCWithContext(ctx, method, params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(result, out); err != nil {
+		return fmt.Errorf("解析RPC结果失败: %w", err)
+	}
+	return nil
+}
 
-				log.Debug("成功接收ElectrumX RPC响应:", "method:", method, "大小:", responseBuffer.Len(), "字节")
-				return resp.Result, nil
-			}
-		}
+// CallRPCEach 调用结果为数组的RPC，逐项交给each解码处理，适用于完整历史等元素很多的响应
+// each每次必须从dec中解码恰好一个元素；返回错误时停止读取并原样返回该错误
+// 调用失败时each可能已经处理了部分元素，调用方应丢弃已处理的结果
+func (c *ElectrumXClient) CallRPCEach(ctx context.Context, method string, params interface{}, each func(dec *json.Decoder) error) error {
+	c.poolMu.Lock()
+	usePool := c.usePool && c.pool != nil
+	c.poolMu.Unlock()
 
-		// 如果遇到EOF且尚未解析成功，说明连接已关闭但数据可能不完整
-		if err == io.EOF {
-			// 记录截断的响应数据(最多500字节)
-			respData := responseBuffer.String()
-			if len(respData) > 500 {
-				respData = respData[:500] + "... [截断]"
-			}
+	if usePool && electrumXHedger == nil {
+		return withBreaker(ctx, method, func(ctx context.Context) error {
+			return c.callRPCWithPoolDecode(ctx, method, params, func(conn net.Conn, id int) error {
+				return decodeRPCResponseEach(conn, id, method, each)
+			})
+		})
+	}
 
-			log.Error("连接关闭但未收到完整响应:", respData)
-			return nil, fmt.Errorf("连接关闭但未收到完整响应")
-		}
+	result, err := c.CallRPCWithContext(ctx, method, params)
+	if err != nil {
+		return err
 	}
+	return decodeEach(json.NewDecoder(bytes.NewReader(result)), each)
 }
 
-// callRPCWithPool 使用连接池调用RPC
-func (c *ElectrumXClient) callRPCWithPool(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+// callRPCWithPoolDecode 通过连接池调用RPC，由decode直接从连接解码响应
+// 上下文取消时通过设置连接截止时间中断读取，出错的连接不再放回池中，调用结果与callWithHedge一样计入健康评分
+func (c *ElectrumXClient) callRPCWithPoolDecode(ctx context.Context, method string, params interface{}, decode func(conn net.Conn, id int) error) (err error) {
+	defer func(start time.Time) {
+		observe(ctx, start, err)
+	}(time.Now())
+
 	c.poolMu.Lock()
 	pool := c.pool
 	c.poolMu.Unlock()
 
 	if pool == nil {
-		return nil, ErrNoPool
+		return ErrNoPool
 	}
 
-	// 获取一个连接
 	conn, err := pool.GetConn(ctx)
 	if err != nil {
 		log.Error("从连接池获取连接失败:", err)
-		return nil, fmt.Errorf("从连接池获取连接失败: %w", err)
+		return fmt.Errorf("从连接池获取连接失败: %w", err)
 	}
 
-	// 确保连接最终会归还到池中
-	defer pool.PutConn(conn)
+	// 连接所在服务器已知不支持该方法时未发送请求，连接可以继续使用
+	address := connAddress(conn)
+	params, err = serverCaps.resolveParams(address, method, params)
+	if err != nil {
+		pool.PutConn(conn)
+		return err
+	}
 
-	// 获取请求ID
 	id := int(atomic.AddInt32(&c.requestID, 1))
-
-	// 构建请求
 	req := RPCRequest{
 		JSONRPC: "2.0",
 		ID:      id,
@@ -374,126 +485,56 @@ func (c *ElectrumXClient) callRPCWithPool(ctx context.Context, method string, pa
 		Params:  params,
 	}
 
-	// 编码请求
-	reqBytes, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("序列化RPC请求失败: %w", err)
-	}
-	reqBytes = append(reqBytes, '\n') // ElectrumX要求每个请求以换行符结束
-
-	// 记录日志
 	log.Debug("通过连接池发送ElectrumX RPC请求:", "method:", method, "params:", params)
 
-	// 设置读写超时
 	deadline := time.Now().Add(time.Duration(c.config.Timeout) * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
 	if err := conn.SetDeadline(deadline); err != nil {
+		pool.DiscardConn(conn)
 		log.Warn("设置连接超时失败:", err)
-		return nil, fmt.Errorf("设置连接超时失败: %w", err)
+		return fmt.Errorf("设置连接超时失败: %w", err)
 	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
 
-	// 发送请求
-	_, err = conn.Write(reqBytes)
-	if err != nil {
-		log.Error("发送RPC请求失败:", err)
-		return nil, fmt.Errorf("发送RPC请求失败: %w", err)
+	start := time.Now()
+	err = writeRPCRequest(conn, req)
+	if err == nil {
+		err = decode(conn, id)
 	}
-
-	// 使用bufio.Reader读取完整响应
-	reader := bufio.NewReader(conn)
-
-	// 使用bytes.Buffer收集响应数据
-	var responseBuffer bytes.Buffer
-
-	// 最大允许的响应大小(10MB)，防止异常大的响应
-	const maxResponseSize = 10 * 1024 * 1024
-
-	// 循环读取响应直到获取完整JSON或达到大小限制
-	for {
-		// 检查是否超出大小限制
-		if responseBuffer.Len() > maxResponseSize {
-			log.Error("RPC响应超过大小限制(", maxResponseSize/1024/1024, "MB)")
-			return nil, fmt.Errorf("RPC响应数据过大，超过%dMB限制", maxResponseSize/1024/1024)
-		}
-
-		// 读取一行数据(ElectrumX响应通常以换行符结束)
-		line, err := reader.ReadBytes('\n')
-		if err != nil && err != io.EOF {
-			log.Error("读取RPC响应失败:", err)
-			return nil, fmt.Errorf("读取RPC响应失败: %w", err)
-		}
-
-		// 将读取的数据添加到缓冲区
-		if len(line) > 0 {
-			responseBuffer.Write(line)
-		}
-
-		// 尝试解析已收集的数据
-		responseData := responseBuffer.Bytes()
-		var resp RPCResponse
-
-		if jsonErr := json.Unmarshal(responseData, &resp); jsonErr == nil {
-			// 检查ID是否匹配
-			if resp.ID == id {
-				// 重置超时
-				conn.SetDeadline(time.Time{})
-
-				// 检查错误
-				if resp.Error != nil {
-					log.Warn("RPC调用错误:", resp.Error.Message, "(代码:", resp.Error.Code, ")")
-					return nil, fmt.Errorf("RPC调用错误: %s (代码: %d)", resp.Error.Message, resp.Error.Code)
-				}
-
-				log.Debug("成功接收ElectrumX RPC响应:", "method:", method, "大小:", responseBuffer.Len(), "字节")
-				return resp.Result, nil
-			}
-		}
-
-		// 如果遇到EOF且尚未解析成功，说明连接已关闭但数据可能不完整
-		if err == io.EOF {
-			// 记录截断的响应数据(最多500字节)
-			respData := responseBuffer.String()
-			if len(respData) > 500 {
-				respData = respData[:500] + "... [截断]"
-			}
-
-			log.Error("连接关闭但未收到完整响应:", respData)
-			return nil, fmt.Errorf("连接关闭但未收到完整响应")
-		}
+	rpctrace.Record(ctx, rpctrace.UpstreamElectrumX, method, strconv.Itoa(id), start, err)
+	attributeError(err, address)
+	if isServerFailure(ctx, err) {
+		reportServer(address, err)
 	}
-}
-
-// CallRPCWithContext 带上下文的RPC调用，支持连接池
-func (c *ElectrumXClient) CallRPCWithContext(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
-	// 检查是否使用连接池
-	c.poolMu.Lock()
-	usePool := c.usePool && c.pool != nil
-	c.poolMu.Unlock()
 
-	if usePool {
-		// 使用连接池调用
-		return c.callRPCWithPool(ctx, method, params)
+	// AfterFunc已经触发时连接的截止时间被改写，不能再复用
+	if !stop() || err != nil {
+		pool.DiscardConn(conn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
 
-	// 对于非连接池调用，忽略上下文，使用传统方式
-	// 在实际应用中可以进一步完善支持上下文的非连接池调用
-	return c.callRPCDirect(method, params)
+	conn.SetDeadline(time.Time{})
+	pool.PutConn(conn)
+	return nil
 }
 
 // CallRPCAsync 异步调用ElectrumX RPC方法
 func (c *ElectrumXClient) CallRPCAsync(ctx context.Context, method string, params interface{}) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
-
-	go func() {
-		defer close(resultChan)
-
+	return async.Go(ctx, "electrumx.CallRPCAsync", func(ctx context.Context) AsyncResult {
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
@@ -501,14 +542,12 @@ func (c *ElectrumXClient) CallRPCAsync(ctx context.Context, method string, param
 		// 调用带上下文的RPC方法
 		result, err := c.CallRPCWithContext(ctx, method, params)
 
-		// 将结果发送到通道
-		resultChan <- AsyncResult{
+		// 返回调用结果
+		return AsyncResult{
 			Result: result,
 			Error:  err,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // Init 初始化ElectrumX RPC客户端
@@ -540,6 +579,22 @@ func Init() error {
 		log.Info("ElectrumX连接池最大连接数未配置，使用默认值:", defaultMaxOpenConns)
 	}
 
+	// 初始化服务发现
+	if err := initDiscovery(config); err != nil {
+		return err
+	}
+
+	// 初始化多服务器故障切换
+	if err := initFailover(config); err != nil {
+		return err
+	}
+
+	// 初始化请求对冲
+	initHedge(config)
+
+	// 初始化断路器
+	initBreaker(config)
+
 	log.Info("ElectrumX RPC客户端初始化完成，服务器:", net.JoinHostPort(config.Host, fmt.Sprintf("%d", config.Port)),
 		"，连接池最大空闲连接:", config.MaxIdleConns, "，最大连接数:", config.MaxOpenConns)
 	return nil
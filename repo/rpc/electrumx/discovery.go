@@ -0,0 +1,76 @@
+package electrumx
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/discovery"
+)
+
+// 上游地址解析器，未启用服务发现时为nil
+var (
+	endpointResolver   *discovery.Resolver
+	endpointResolverMu sync.RWMutex
+)
+
+// initDiscovery 根据配置初始化ElectrumX服务发现
+func initDiscovery(cfg *config.ElectrumXConfig) error {
+	if cfg.Discovery == discovery.ModeStatic {
+		return nil
+	}
+
+	name := cfg.DiscoveryName
+	if name == "" {
+		name = cfg.Host
+	}
+
+	resolver, err := discovery.NewResolver(cfg.Discovery, name, cfg.Port,
+		time.Duration(cfg.DiscoveryInterval)*time.Second)
+	if err != nil {
+		return fmt.Errorf("创建ElectrumX服务发现失败: %w", err)
+	}
+	if err := resolver.Start(); err != nil {
+		// 首次解析失败时回退到静态地址，后台继续刷新
+		log.Warnf("ElectrumX服务发现首次解析失败，暂时使用静态地址: %v", err)
+	}
+
+	endpointResolverMu.Lock()
+	if endpointResolver != nil {
+		endpointResolver.Close()
+	}
+	endpointResolver = resolver
+	endpointResolverMu.Unlock()
+
+	log.Info("ElectrumX服务发现已启用, 模式:", cfg.Discovery, "，名称:", name)
+	return nil
+}
+
+// dialAddress 返回本次建连使用的服务器地址
+// 启用服务发现时按解析结果轮询并跳过不健康的服务器，否则按优先级选择健康的服务器，都未启用时使用host:port
+func (c *ElectrumXClient) dialAddress() (string, error) {
+	endpointResolverMu.RLock()
+	resolver := endpointResolver
+	endpointResolverMu.RUnlock()
+	set := currentServerSet()
+
+	if resolver != nil {
+		for i := len(resolver.Endpoints()); i > 0; i-- {
+			address, err := resolver.Next()
+			if err != nil {
+				break
+			}
+			if set == nil || set.Healthy(address) {
+				return address, nil
+			}
+		}
+	}
+	if set != nil {
+		return set.Pick()
+	}
+
+	return net.JoinHostPort(c.config.Host, fmt.Sprintf("%d", c.config.Port)), nil
+}
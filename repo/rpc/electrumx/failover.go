@@ -0,0 +1,280 @@
+package electrumx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/failover"
+	"ginproject/repo/rpc/rpctrace"
+)
+
+// ElectrumX服务器组，只配置了一个服务器且未启用服务发现时为nil，此时所有连接都建立到host:port
+var (
+	serverSet   *failover.Set
+	serverSetMu sync.RWMutex
+)
+
+// serverConn 记录连接所在的服务器，调用失败时据此更新服务器的健康状态
+type serverConn struct {
+	net.Conn
+	address string
+}
+
+// initFailover 根据配置初始化多服务器故障切换，需在服务发现初始化之后调用
+// 静态模式下host:port优先级最高，servers按配置顺序作为备用；启用服务发现时服务器列表随解析结果刷新
+func initFailover(cfg *config.ElectrumXConfig) error {
+	addresses := []string{net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))}
+	addresses = append(addresses, cfg.Servers...)
+
+	endpointResolverMu.RLock()
+	resolver := endpointResolver
+	endpointResolverMu.RUnlock()
+
+	opts := failover.Options{
+		FailureThreshold: cfg.FailureThreshold,
+		MaxBackoff:       time.Duration(cfg.MaxBackoff) * time.Second,
+		CheckInterval:    time.Duration(cfg.HealthCheckInterval) * time.Second,
+		CheckTimeout:     time.Duration(cfg.Timeout) * time.Second,
+		Probe: func(ctx context.Context, address string) error {
+			return probeServer(ctx, cfg, address)
+		},
+	}
+	if resolver != nil {
+		opts.Refresh = resolver.Endpoints
+		if endpoints := resolver.Endpoints(); len(endpoints) > 0 {
+			addresses = endpoints
+		}
+	}
+
+	var set *failover.Set
+	if resolver != nil || len(addresses) > 1 {
+		var err error
+		set, err = failover.NewSet("ElectrumX", addresses, opts)
+		if err != nil {
+			return fmt.Errorf("创建ElectrumX服务器组失败: %w", err)
+		}
+		set.Start()
+		log.Info("ElectrumX多服务器故障切换已启用, 服务器:", set.Addresses())
+	}
+
+	serverSetMu.Lock()
+	if serverSet != nil {
+		serverSet.Close()
+	}
+	serverSet = set
+	serverSetMu.Unlock()
+	return nil
+}
+
+// currentServerSet 返回当前的服务器组，未启用故障切换时为nil
+func currentServerSet() *failover.Set {
+	serverSetMu.RLock()
+	defer serverSetMu.RUnlock()
+	return serverSet
+}
+
+// ServerStatus 返回各ElectrumX服务器的健康状态，未启用故障切换时返回nil
+func ServerStatus() []failover.EndpointStatus {
+	if set := currentServerSet(); set != nil {
+		return set.Status()
+	}
+	return nil
+}
+
+// isServerFailure 判断调用错误是否由服务器故障引起，服务端返回的业务错误和调用方取消不计入
+func isServerFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var rpcErr *RPCError
+	return !errors.As(err, &rpcErr)
+}
+
+// reportServer 将一次建连或调用的结果计入服务器的健康状态
+// 调用成功不上报以免每次调用都加写锁，失败次数由建连成功和健康检查通过时清零
+func reportServer(address string, err error) {
+	if set := currentServerSet(); set != nil && address != "" {
+		set.Report(address, err)
+	}
+}
+
+// connAddress 返回连接所在的服务器地址，无法识别时返回空
+func connAddress(conn net.Conn) string {
+	if pc, ok := conn.(*pooledConn); ok {
+		conn = pc.Conn
+	}
+	if sc, ok := conn.(*serverConn); ok {
+		return sc.address
+	}
+	return ""
+}
+
+// serverHealthy 判断连接所在的服务器是否健康，未启用故障切换时总是返回true
+func serverHealthy(conn net.Conn) bool {
+	set := currentServerSet()
+	if set == nil {
+		return true
+	}
+	address := connAddress(conn)
+	return address == "" || set.Healthy(address)
+}
+
+// callOtherServer 幂等读请求在服务器故障时切换到其它服务器重试一次，没有其它可用服务器时返回原错误
+func (c *ElectrumXClient) callOtherServer(ctx context.Context, failed string, method string, params interface{}, cause error) (json.RawMessage, error) {
+	set := currentServerSet()
+	if set == nil || !isIdempotent(method) {
+		return nil, cause
+	}
+	address, err := set.PickExcept(failed)
+	if err != nil || address == failed {
+		return nil, cause
+	}
+
+	log.WarnWithContext(ctx, "ElectrumX调用失败，切换服务器重试", "method:", method, "from:", failed, "to:", address, "error:", cause)
+	result, err := c.callServer(ctx, address, method, params)
+	if errors.Is(err, errConnectServer) {
+		return nil, cause
+	}
+	return result, err
+}
+
+// errConnectServer callServer未能建立到目标服务器的连接
+var errConnectServer = errors.New("连接ElectrumX服务器失败")
+
+// callServer 向指定服务器发送一次请求，优先复用该服务器上已协商过协议版本的空闲连接，建连和调用结果计入该服务器的健康状态
+// 复用的连接失败时换用新连接重试一次，建连失败时返回包装了errConnectServer的错误
+func (c *ElectrumXClient) callServer(ctx context.Context, address string, method string, params interface{}) (json.RawMessage, error) {
+	if conn := c.serverConns.get(address); conn != nil {
+		result, err := c.callOnServerConn(ctx, conn, method, params)
+		if ctx.Err() != nil || !isServerFailure(ctx, err) {
+			return result, err
+		}
+		// 空闲连接可能已被服务器关闭，不计入服务器的健康状态
+	}
+
+	conn, err := c.connectTo(ctx, address)
+	if ctx.Err() != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, fmt.Errorf("%w: %w", errConnectServer, ctx.Err())
+	}
+	reportServer(address, err)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConnectServer, err)
+	}
+	now := time.Now()
+	result, err := c.callOnServerConn(ctx, &pooledConn{Conn: conn, createdAt: now, expiresAt: now.Add(defaultMaxLifetime)}, method, params)
+	if isServerFailure(ctx, err) {
+		reportServer(address, err)
+	}
+	return result, err
+}
+
+// callOnServerConn 在指定服务器的连接上发送一次请求，成功或服务端返回业务错误时连接放回空闲列表，否则关闭
+func (c *ElectrumXClient) callOnServerConn(ctx context.Context, conn *pooledConn, method string, params interface{}) (json.RawMessage, error) {
+	id := int(atomic.AddInt32(&c.requestID, 1))
+	start := time.Now()
+	result, err := c.roundTrip(ctx, conn, id, method, params)
+	rpctrace.Record(ctx, rpctrace.UpstreamElectrumX, method, strconv.Itoa(id), start, err)
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		c.serverConns.put(connAddress(conn), conn)
+	} else {
+		conn.Close()
+	}
+	return result, err
+}
+
+// 每个服务器保留的空闲连接数上限，空闲超过serverConnMaxIdle的连接不再复用，避免使用已被服务器关闭的连接
+const (
+	serverConnsPerAddress = 2
+	serverConnMaxIdle     = 30 * time.Second
+)
+
+// serverIdleConns 按服务器地址保存对冲和切换请求用过的空闲连接，避免每次都重新建连和协商协议版本
+// 零值可以直接使用
+type serverIdleConns struct {
+	mu    sync.Mutex
+	conns map[string][]*pooledConn
+}
+
+// get 取出address上最近使用的空闲连接，没有可用连接时返回nil，取出时顺带关闭过期的连接
+func (s *serverIdleConns) get(address string) *pooledConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	conns := s.conns[address]
+	for len(conns) > 0 {
+		conn := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		if now.Sub(conn.lastUsed) < serverConnMaxIdle && now.Before(conn.expiresAt) {
+			s.conns[address] = conns
+			return conn
+		}
+		conn.Close()
+	}
+	delete(s.conns, address)
+	return nil
+}
+
+// put 放回空闲连接，超过上限或服务器已不健康时关闭
+func (s *serverIdleConns) put(address string, conn *pooledConn) {
+	if address == "" || !serverHealthy(conn) {
+		conn.Close()
+		return
+	}
+	conn.lastUsed = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.conns[address]) >= serverConnsPerAddress {
+		conn.Close()
+		return
+	}
+	if s.conns == nil {
+		s.conns = make(map[string][]*pooledConn)
+	}
+	s.conns[address] = append(s.conns[address], conn)
+}
+
+// closeAll 关闭全部空闲连接
+func (s *serverIdleConns) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conns := range s.conns {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	s.conns = nil
+}
+
+// probeServer 健康检查，建立连接并协商协议版本，同时发现服务器版本变化
+func probeServer(ctx context.Context, cfg *config.ElectrumXConfig, address string) error {
+	conn, err := dialServer(ctx, cfg, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	info, err := negotiateVersion(conn, 0)
+	if err != nil {
+		return err
+	}
+	serverCaps.record(address, info)
+	return nil
+}
@@ -0,0 +1,91 @@
+package electrumx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/healthscore"
+	"ginproject/repo/rpc/hedge"
+)
+
+// ElectrumX请求对冲控制器，未启用时为nil
+var electrumXHedger *hedge.Hedger
+
+// initHedge 根据配置初始化对冲控制器
+func initHedge(cfg *config.ElectrumXConfig) {
+	if !cfg.Hedge {
+		electrumXHedger = nil
+		return
+	}
+	electrumXHedger = hedge.NewHedger(time.Duration(cfg.HedgeDelay)*time.Millisecond, cfg.HedgeBudget)
+}
+
+// isIdempotent 判断方法是否为可安全对冲的只读请求
+func isIdempotent(method string) bool {
+	if method == "blockchain.transaction.broadcast" || strings.HasSuffix(method, ".subscribe") {
+		return false
+	}
+	return strings.HasPrefix(method, "blockchain.") || strings.HasPrefix(method, "server.")
+}
+
+// callWithHedge 通过连接池调用RPC，幂等读请求在启用且健康评分允许时进行对冲，调用结果计入健康评分
+// 启用多服务器故障切换时对冲请求发往主请求所在服务器以外的服务器
+func (c *ElectrumXClient) callWithHedge(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	start := time.Now()
+	var result json.RawMessage
+	var err error
+	if electrumXHedger == nil || !isIdempotent(method) || !healthscore.AllowHedge() {
+		result, err = c.callRPCWithPool(ctx, method, params, nil)
+	} else {
+		var primary atomic.Value
+		result, err = hedge.Do(ctx, electrumXHedger, func(ctx context.Context, hedged bool) (json.RawMessage, error) {
+			if !hedged {
+				return c.callRPCWithPool(ctx, method, params, func(address string) { primary.Store(address) })
+			}
+			address, _ := primary.Load().(string)
+			return c.callHedged(ctx, address, method, params)
+		})
+	}
+	observe(ctx, start, err)
+	return result, err
+}
+
+// callHedged 发出对冲请求，通过服务器组选择primary以外的服务器，复用该服务器上已协商过协议版本的空闲连接
+// 主请求尚未取得连接时按优先使用的服务器判断，没有其它可用服务器时仍通过连接池发往同一服务器
+func (c *ElectrumXClient) callHedged(ctx context.Context, primary string, method string, params interface{}) (json.RawMessage, error) {
+	set := currentServerSet()
+	if set == nil {
+		return c.callRPCWithPool(ctx, method, params, nil)
+	}
+	if primary == "" {
+		primary = preferredAddress()
+	}
+	address, err := set.PickExcept(primary)
+	if err != nil || address == primary {
+		return c.callRPCWithPool(ctx, method, params, nil)
+	}
+	return c.callServer(ctx, address, method, params)
+}
+
+// observe 将一次ElectrumX调用计入健康评分，服务端返回的业务错误不计为故障，调用方取消的请求不计入
+func observe(ctx context.Context, start time.Time, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	var rpcErr *RPCError
+	failed := err != nil && !errors.As(err, &rpcErr)
+	healthscore.Upstream(healthscore.UpstreamElectrumX).Observe(time.Since(start), failed)
+}
+
+// GetHedgeStats 获取ElectrumX请求对冲统计
+func GetHedgeStats() (hedge.Stats, bool) {
+	if electrumXHedger == nil {
+		return hedge.Stats{}, false
+	}
+	return electrumXHedger.Stats(), true
+}
@@ -0,0 +1,106 @@
+package electrumx
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ginproject/middleware/healthscore"
+	"ginproject/repo/rpc/failover"
+	"ginproject/repo/rpc/hedge"
+)
+
+func TestHedgeReusesServerConn(t *testing.T) {
+	slow, fast := newFakeServer(t), newFakeServer(t)
+	slow.healthy.Store(true)
+	fast.healthy.Store(true)
+	slow.delay.Store(int64(300 * time.Millisecond))
+
+	set, err := failover.NewSet("ElectrumX", []string{slow.address(), fast.address()}, failover.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverSetMu.Lock()
+	savedSet := serverSet
+	serverSet = set
+	serverSetMu.Unlock()
+	savedHedger := electrumXHedger
+	electrumXHedger = hedge.NewHedger(10*time.Millisecond, 100)
+	t.Cleanup(func() {
+		serverSetMu.Lock()
+		serverSet = savedSet
+		serverSetMu.Unlock()
+		electrumXHedger = savedHedger
+	})
+
+	c := &ElectrumXClient{config: slow.config()}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.pool = &ConnPool{
+		client:            c,
+		conns:             make(chan *pooledConn, 2),
+		maxOpenConns:      2,
+		connTimeout:       time.Second,
+		maxLifetime:       time.Minute,
+		validateAfterIdle: time.Minute,
+		cleanerCtx:        ctx,
+		cleanerCancel:     cancel,
+	}
+	defer c.DisablePool()
+
+	// 主请求发往慢服务器，对冲请求发往另一台服务器并先返回；第二次对冲复用第一次的连接
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		result, err := c.callWithHedge(context.Background(), "server.banner", []interface{}{})
+		if err != nil {
+			t.Fatalf("第%d次调用失败: %v", i, err)
+		}
+		var address string
+		json.Unmarshal(result, &address)
+		if address != fast.address() {
+			t.Fatalf("第%d次调用结果来自%s, 期望对冲服务器%s", i, address, fast.address())
+		}
+		if elapsed := time.Since(start); elapsed >= 300*time.Millisecond {
+			t.Errorf("第%d次调用耗时%v, 对冲请求应先于慢主请求返回", i, elapsed)
+		}
+	}
+	if n := fast.accepted.Load(); n != 1 {
+		t.Errorf("对冲服务器建立了%d个连接, 期望复用同一个连接", n)
+	}
+}
+
+func TestDecodePathObserved(t *testing.T) {
+	server := newFakeServer(t)
+	server.healthy.Store(true)
+	savedHedger := electrumXHedger
+	electrumXHedger = nil
+	t.Cleanup(func() { electrumXHedger = savedHedger })
+
+	c := &ElectrumXClient{config: server.config(), usePool: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.pool = &ConnPool{
+		client:            c,
+		conns:             make(chan *pooledConn, 1),
+		maxOpenConns:      1,
+		connTimeout:       time.Second,
+		maxLifetime:       time.Minute,
+		validateAfterIdle: time.Minute,
+		cleanerCtx:        ctx,
+		cleanerCancel:     cancel,
+	}
+	defer c.DisablePool()
+
+	// 未启用对冲时CallRPCInto和CallRPCEach走流式解码路径，同样要计入健康评分
+	tracker := healthscore.Upstream(healthscore.UpstreamElectrumX)
+	before := tracker.Stats().Samples
+	var address string
+	if err := c.CallRPCInto(context.Background(), "server.banner", []interface{}{}, &address); err != nil {
+		t.Fatalf("CallRPCInto失败: %v", err)
+	}
+	if address != server.address() {
+		t.Fatalf("结果 = %q", address)
+	}
+	if got := tracker.Stats().Samples - before; got != 1 {
+		t.Fatalf("健康评分新增%d个样本, 期望1个", got)
+	}
+}
@@ -0,0 +1,77 @@
+package electrumx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+
+	"go.uber.org/goleak"
+)
+
+// TestMain 全部测试结束后检查是否有遗留的协程
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestRoundTripCanceled(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	// 服务端读取请求后不返回响应
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			if _, err := server.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	c := &ElectrumXClient{config: &config.ElectrumXConfig{Timeout: 30}}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.roundTrip(ctx, client, 1, "blockchain.scripthash.get_history", []interface{}{"00"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("取消后读取未及时中断: %v", elapsed)
+	}
+}
+
+func TestConnectToCanceled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// 服务端接受连接后不回复协议协商
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	c := &ElectrumXClient{config: &config.ElectrumXConfig{Timeout: 30, Protocol: "tcp"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.connectTo(ctx, listener.Addr().String()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("请求到期后协议协商未及时中断: %v", elapsed)
+	}
+	(<-accepted).Close()
+}
@@ -4,77 +4,134 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 
+	"ginproject/entity/config"
 	"ginproject/entity/electrumx"
-	utility "ginproject/entity/utility"
 	"ginproject/middleware/log"
+	"ginproject/middleware/schemawatch"
+	"ginproject/repo/rpc/async"
+	"ginproject/repo/scripthash"
 )
 
-// Global client instance
+// 默认客户端，首次使用时创建；初始化失败不缓存错误，下次调用时重试
 var (
-	defaultClient *ElectrumXClient
-	initialized   = false
-	poolEnabled   = true // 默认启用连接池
+	clientMu      sync.Mutex
+	defaultClient atomic.Pointer[ElectrumXClient]
+	poolEnabled   = true // 默认启用连接池，由clientMu保护
 )
 
-// GetDefaultClient 获取默认的ElectrumX客户端实例
+// newDefaultClient 初始化配置并创建默认客户端，测试中替换以避免连接真实服务器
+var newDefaultClient = func() (*ElectrumXClient, error) {
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	return NewClient()
+}
+
+// GetDefaultClient 获取默认的ElectrumX客户端实例，并发的首次调用只会创建一个客户端和连接池
 func GetDefaultClient() (*ElectrumXClient, error) {
-	if !initialized {
-		if err := Init(); err != nil {
-			return nil, err
-		}
+	if client := defaultClient.Load(); client != nil {
+		return client, nil
+	}
 
-		client, err := NewClient()
-		if err != nil {
-			return nil, err
-		}
-		defaultClient = client
-		initialized = true
-
-		// 默认启用连接池
-		if poolEnabled {
-			if err := defaultClient.EnablePool(); err != nil {
-				log.Error("启用ElectrumX连接池失败:", err)
-			} else {
-				log.Info("ElectrumX连接池已启用")
-			}
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client := defaultClient.Load(); client != nil {
+		return client, nil
+	}
+
+	client, err := newDefaultClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// 默认启用连接池
+	if poolEnabled {
+		if err := client.EnablePool(); err != nil {
+			log.Error("启用ElectrumX连接池失败:", err)
+		} else {
+			log.Info("ElectrumX连接池已启用")
 		}
+	} else if err := client.DisablePool(); err != nil {
+		log.Error("禁用ElectrumX连接池失败:", err)
 	}
 
-	return defaultClient, nil
+	defaultClient.Store(client)
+	return client, nil
+}
+
+// Shutdown 关闭默认客户端的连接池，之后的调用会重新创建客户端
+func Shutdown() error {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	client := defaultClient.Swap(nil)
+	if client == nil {
+		return nil
+	}
+	return client.DisablePool()
 }
 
 // EnablePool 启用ElectrumX连接池
 func EnablePool() error {
-	if !initialized {
-		// 如果客户端尚未初始化，设置标志位，客户端初始化时会启用连接池
-		poolEnabled = true
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	poolEnabled = true
+	client := defaultClient.Load()
+	if client == nil {
+		// 客户端尚未初始化，初始化时会启用连接池
 		return nil
 	}
 
 	// 客户端已初始化，直接启用连接池
-	return defaultClient.EnablePool()
+	return client.EnablePool()
 }
 
 // DisablePool 禁用ElectrumX连接池
 func DisablePool() error {
-	if !initialized {
-		poolEnabled = false
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	poolEnabled = false
+	client := defaultClient.Load()
+	if client == nil {
 		return nil
 	}
 
-	return defaultClient.DisablePool()
+	return client.DisablePool()
 }
 
 // GetClientPoolStats 获取连接池统计信息
 func GetClientPoolStats() (idleConns, openConns int, err error) {
-	if !initialized {
+	client := defaultClient.Load()
+	if client == nil {
 		return 0, 0, fmt.Errorf("ElectrumX客户端尚未初始化")
 	}
 
-	return defaultClient.PoolStats()
+	return client.PoolStats()
+}
+
+// GetClientPoolMetrics 获取连接池统计指标，包括回收和失败的连接数
+func GetClientPoolMetrics() (PoolMetrics, error) {
+	client := defaultClient.Load()
+	if client == nil {
+		return PoolMetrics{}, fmt.Errorf("ElectrumX客户端尚未初始化")
+	}
+
+	client.poolMu.Lock()
+	pool := client.pool
+	client.poolMu.Unlock()
+
+	if pool == nil {
+		return PoolMetrics{}, ErrNoPool
+	}
+	return pool.Metrics(), nil
 }
 
 // CallMethod 调用ElectrumX RPC方法的简便函数
@@ -96,33 +153,60 @@ func CallMethod(ctx context.Context, method string, params []interface{}) (json.
 	return result, nil
 }
 
-// CallMethodAsync 异步调用ElectrumX RPC方法的简便函数
-func CallMethodAsync(ctx context.Context, method string, params []interface{}) <-chan AsyncResult {
-	resultChan := make(chan AsyncResult, 1)
+// CallMethodInto 调用ElectrumX RPC方法并将结果直接解码到out的简便函数
+func CallMethodInto(ctx context.Context, method string, params []interface{}, out interface{}) error {
+	client, err := GetDefaultClient()
+	if err != nil {
+		return fmt.Errorf("获取ElectrumX客户端失败: %w", err)
+	}
 
-	go func() {
-		defer close(resultChan)
+	log.InfoWithContext(ctx, "开始调用ElectrumX方法:", method)
 
+	if err := client.CallRPCInto(ctx, method, params, out); err != nil {
+		log.ErrorWithContext(ctx, "调用ElectrumX方法失败:", method, "错误:", err)
+		return err
+	}
+
+	return nil
+}
+
+// CallMethodEach 调用结果为数组的ElectrumX RPC方法并逐项交给each解码处理的简便函数
+func CallMethodEach(ctx context.Context, method string, params []interface{}, each func(dec *json.Decoder) error) error {
+	client, err := GetDefaultClient()
+	if err != nil {
+		return fmt.Errorf("获取ElectrumX客户端失败: %w", err)
+	}
+
+	log.InfoWithContext(ctx, "开始调用ElectrumX方法:", method)
+
+	if err := client.CallRPCEach(ctx, method, params, each); err != nil {
+		log.ErrorWithContext(ctx, "调用ElectrumX方法失败:", method, "错误:", err)
+		return err
+	}
+
+	return nil
+}
+
+// CallMethodAsync 异步调用ElectrumX RPC方法的简便函数
+func CallMethodAsync(ctx context.Context, method string, params []interface{}) <-chan AsyncResult {
+	return async.Go(ctx, "electrumx.CallMethodAsync", func(ctx context.Context) AsyncResult {
 		// 检查上下文是否已取消
 		select {
 		case <-ctx.Done():
-			resultChan <- AsyncResult{
+			return AsyncResult{
 				Result: nil,
 				Error:  ctx.Err(),
 			}
-			return
 		default:
 			// 继续执行
 		}
 
 		result, err := CallMethod(ctx, method, params)
-		resultChan <- AsyncResult{
+		return AsyncResult{
 			Result: result,
 			Error:  err,
 		}
-	}()
-
-	return resultChan
+	})
 }
 
 // 以下是常用的ElectrumX RPC方法
@@ -165,7 +249,7 @@ func GetBalance(ctx context.Context, scriptHash string) (*electrumx.BalanceRespo
 
 	// 解析响应
 	var balance electrumx.BalanceResponse
-	if err := json.Unmarshal(result.Result, &balance); err != nil {
+	if err := schemawatch.Decode(ctx, schemawatch.SourceElectrumX, "blockchain.scripthash.get_balance", result.Result, &balance); err != nil {
 		log.ErrorWithContext(ctx, "解析脚本哈希余额失败:", err, "原始数据:", string(result.Result))
 		return nil, fmt.Errorf("解析脚本哈希余额失败: %w", err)
 	}
@@ -176,8 +260,24 @@ func GetBalance(ctx context.Context, scriptHash string) (*electrumx.BalanceRespo
 
 // GetTransactionHistory 获取地址交易历史
 func GetTransactionHistory(ctx context.Context, address string) ([]interface{}, error) {
-	resultChan := CallMethodAsync(ctx, "blockchain.scripthash.get_history", []interface{}{address})
-	result := <-resultChan
+	return GetTransactionHistoryFrom(ctx, address, 0)
+}
+
+// GetTransactionHistoryFrom 获取地址从指定高度开始的交易历史，未确认交易始终保留
+func GetTransactionHistoryFrom(ctx context.Context, address string, fromHeight int64) ([]interface{}, error) {
+	if fromHeight < 0 {
+		return nil, ErrInvalidHistoryRange
+	}
+
+	params := historyParams(address, fromHeight, 0)
+	result := <-CallMethodAsync(ctx, "blockchain.scripthash.get_history", params)
+	if server, ok := invalidParamsServer(result.Error); ok && fromHeight > 0 && serverCaps.supports(server, CapabilityHistoryFromHeight) {
+		// 参数无效可能由请求本身引起，去掉起始高度参数重试成功才标记返回错误的服务器不支持该参数，之后该服务器返回完整历史后本地过滤
+		result = <-CallMethodAsync(ctx, "blockchain.scripthash.get_history", withoutOptionalParam(params, CapabilityHistoryFromHeight))
+		if result.Error == nil {
+			serverCaps.markUnsupported(server, CapabilityHistoryFromHeight)
+		}
+	}
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -188,6 +288,21 @@ func GetTransactionHistory(ctx context.Context, address string) ([]interface{},
 		return nil, fmt.Errorf("解析交易历史失败: %w", err)
 	}
 
+	if fromHeight > 0 {
+		filtered := make([]interface{}, 0, len(history))
+		for _, item := range history {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			height, _ := itemMap["height"].(float64)
+			if height < 1 || int64(height) >= fromHeight {
+				filtered = append(filtered, item)
+			}
+		}
+		history = filtered
+	}
+
 	return history, nil
 }
 
@@ -242,21 +357,13 @@ func EstimateFee(ctx context.Context, blocks int) (float64, error) {
 	return fee, nil
 }
 
-// ServerVersion 获取服务器版本信息
-func ServerVersion(ctx context.Context) ([]interface{}, error) {
-	resultChan := CallMethodAsync(ctx, "server.version", []interface{}{"electrumx-client", "1.4"})
-	result := <-resultChan
-	if result.Error != nil {
-		return nil, result.Error
-	}
-
-	var version []interface{}
-	if err := json.Unmarshal(result.Result, &version); err != nil {
-		log.ErrorWithContext(ctx, "解析服务器版本信息失败:", err)
-		return nil, fmt.Errorf("解析服务器版本信息失败: %w", err)
+// GetFeeHistogram 获取内存池费率直方图，返回按费率从高到低排列的[费率, 大小]对
+func GetFeeHistogram(ctx context.Context) ([][2]float64, error) {
+	var histogram [][2]float64
+	if err := CallMethodInto(ctx, "mempool.get_fee_histogram", []interface{}{}, &histogram); err != nil {
+		return nil, err
 	}
-
-	return version, nil
+	return histogram, nil
 }
 
 // ServerPeers 获取服务器的对等节点信息
@@ -314,7 +421,7 @@ func GetListUnspent(ctx context.Context, scriptHash string) (electrumx.UtxoRespo
 
 	// 解析响应
 	var utxos electrumx.UtxoResponse
-	if err := json.Unmarshal(result.Result, &utxos); err != nil {
+	if err := schemawatch.Decode(ctx, schemawatch.SourceElectrumX, "blockchain.scripthash.listunspent", result.Result, &utxos); err != nil {
 		log.ErrorWithContext(ctx, "解析UTXO响应失败:", err)
 		return nil, fmt.Errorf("解析UTXO响应失败: %w", err)
 	}
@@ -344,7 +451,7 @@ func GetScriptHashBalance(ctx context.Context, scriptHash string) (*electrumx.Ba
 
 	// 解析响应
 	var balance electrumx.BalanceResponse
-	if err := json.Unmarshal(result.Result, &balance); err != nil {
+	if err := schemawatch.Decode(ctx, schemawatch.SourceElectrumX, "blockchain.scripthash.get_balance", result.Result, &balance); err != nil {
 		log.ErrorWithContext(ctx, "解析脚本哈希余额失败:", err, "原始数据:", string(result.Result))
 		return nil, fmt.Errorf("解析脚本哈希余额失败: %w", err)
 	}
@@ -364,9 +471,12 @@ func GetScriptHashFrozenBalance(ctx context.Context, scriptHash string) (*electr
 	// 记录开始调用日志
 	log.InfoWithContext(ctx, "开始获取脚本哈希冻结余额:", scriptHash)
 
-	// 调用RPC方法（改为异步）
+	// 调用RPC方法（改为异步），连接所在服务器已知不支持该方法时不发送请求
 	resultChan := CallMethodAsync(ctx, "blockchain.scripthash.get_frozen_balance", []interface{}{scriptHash})
 	result := <-resultChan
+	if result.Error != nil && serverCaps.markUnsupportedOn(CapabilityFrozenBalance, result.Error) {
+		return nil, ErrCapabilityUnsupported
+	}
 	if result.Error != nil {
 		log.ErrorWithContext(ctx, "获取脚本哈希冻结余额失败:", result.Error)
 		return nil, fmt.Errorf("获取脚本哈希冻结余额失败: %w", result.Error)
@@ -374,7 +484,7 @@ func GetScriptHashFrozenBalance(ctx context.Context, scriptHash string) (*electr
 
 	// 解析响应
 	var frozenBalance electrumx.FrozenBalanceResponse
-	if err := json.Unmarshal(result.Result, &frozenBalance); err != nil {
+	if err := schemawatch.Decode(ctx, schemawatch.SourceElectrumX, "blockchain.scripthash.get_frozen_balance", result.Result, &frozenBalance); err != nil {
 		log.ErrorWithContext(ctx, "解析脚本哈希冻结余额失败:", err, "原始数据:", string(result.Result))
 		return nil, fmt.Errorf("解析脚本哈希冻结余额失败: %w", err)
 	}
@@ -501,8 +611,7 @@ func GetBlockByHeight(ctx context.Context, height int64) (*struct {
 
 // AddressToScriptHash 将比特币地址转换为脚本哈希
 func AddressToScriptHash(ctx context.Context, address string) (string, error) {
-	// 调用工具函数进行转换
-	scriptHash, err := utility.AddressToScriptHash(address)
+	scriptHash, err := scripthash.Resolve(ctx, scripthash.KindP2PKH, address)
 	if err != nil {
 		log.ErrorWithContext(ctx, "地址转换为脚本哈希失败:", err)
 		return "", fmt.Errorf("地址转换为脚本哈希失败: %w", err)
@@ -513,9 +622,15 @@ func AddressToScriptHash(ctx context.Context, address string) (string, error) {
 
 // 错误定义
 var (
-	ErrEmptyScriptHash = fmt.Errorf("脚本哈希不能为空")
+	ErrEmptyScriptHash     = fmt.Errorf("脚本哈希不能为空")
+	ErrInvalidHistoryRange = fmt.Errorf("历史查询起始高度或最大条数无效")
+	// ErrHistoryTooLarge 历史记录数超过配置的上限，应使用起始高度缩小查询范围
+	ErrHistoryTooLarge = errors.New("历史记录数超过上限")
 )
 
+// 未配置时单次历史查询允许的记录数上限
+const defaultMaxHistoryItems = 500000
+
 // GetUnspent 获取指定脚本哈希的未花费交易输出
 func GetUnspent(ctx context.Context, scriptHash string) (electrumx.UtxoResponse, error) {
 	log.InfoWithContext(ctx, "开始获取脚本哈希的UTXO", "scriptHash:", scriptHash)
@@ -555,40 +670,198 @@ func ConvertAddressToScript(ctx context.Context, address string) (string, error)
 
 // GetScriptHashHistory 使用上下文获取指定脚本哈希的交易历史
 func GetScriptHashHistory(ctx context.Context, scriptHash string) (electrumx.ElectrumXHistoryResponse, error) {
-	// 参数校验
+	return GetScriptHashHistoryFrom(ctx, scriptHash, 0, 0)
+}
+
+// GetScriptHashHistoryFrom 获取指定脚本哈希从某个高度开始的交易历史
+// fromHeight为0表示从头开始，maxCount为0表示不限制返回条数；未确认交易总是包含在结果中
+func GetScriptHashHistoryFrom(ctx context.Context, scriptHash string, fromHeight int64, maxCount int) (electrumx.ElectrumXHistoryResponse, error) {
+	if maxCount < 0 {
+		log.ErrorWithContext(ctx, "历史分页参数无效", "fromHeight:", fromHeight, "maxCount:", maxCount)
+		return nil, ErrInvalidHistoryRange
+	}
+
+	// 服务器支持时由服务端限制条数，否则完整历史可能很大，逐项解码并只保留需要的前maxCount条
+	collector := &headCollector{max: maxCount}
+	if err := streamScriptHashHistory(ctx, scriptHash, fromHeight, maxCount, collector); err != nil {
+		return nil, err
+	}
+
+	log.InfoWithContext(ctx, "成功获取脚本哈希历史",
+		"scriptHash:", scriptHash,
+		"count:", len(collector.items))
+	return collector.items, nil
+}
+
+// GetScriptHashHistoryTail 获取指定脚本哈希从某个高度开始的交易历史中最新的keep条记录及记录总数
+// 返回的记录保持服务端的顺序(从旧到新)，供从新到旧分页时只保留当前页及之前的记录，不在内存中保留完整历史
+func GetScriptHashHistoryTail(ctx context.Context, scriptHash string, fromHeight int64, keep int) (electrumx.ElectrumXHistoryResponse, int, error) {
+	if keep < 0 {
+		log.ErrorWithContext(ctx, "历史分页参数无效", "fromHeight:", fromHeight, "keep:", keep)
+		return nil, 0, ErrInvalidHistoryRange
+	}
+
+	collector := &tailCollector{keep: keep}
+	if err := streamScriptHashHistory(ctx, scriptHash, fromHeight, 0, collector); err != nil {
+		return nil, 0, err
+	}
+
+	log.InfoWithContext(ctx, "成功获取脚本哈希最新历史",
+		"scriptHash:", scriptHash,
+		"total:", collector.total,
+		"kept:", len(collector.ring))
+	return collector.items(), collector.total, nil
+}
+
+// maxHistoryItems 返回单次历史查询允许的记录数上限
+func maxHistoryItems() int {
+	if limit := config.GetConfig().GetElectrumXConfig().MaxHistoryItems; limit > 0 {
+		return limit
+	}
+	return defaultMaxHistoryItems
+}
+
+// streamScriptHashHistory 逐项解码指定脚本哈希的历史，把起始高度之后的记录和未确认交易交给collector
+// maxCount大于0时请求服务端最多返回maxCount条，服务端返回的记录数超过上限时停止读取并返回ErrHistoryTooLarge
+func streamScriptHashHistory(ctx context.Context, scriptHash string, fromHeight int64, maxCount int, collector historyCollector) error {
 	if scriptHash == "" {
 		log.ErrorWithContext(ctx, "脚本哈希不能为空")
-		return nil, ErrEmptyScriptHash
+		return ErrEmptyScriptHash
+	}
+	if fromHeight < 0 {
+		log.ErrorWithContext(ctx, "历史分页参数无效", "fromHeight:", fromHeight)
+		return ErrInvalidHistoryRange
 	}
 
-	// 记录调用开始
 	log.InfoWithContext(ctx, "开始获取脚本哈希历史",
-		"scriptHash:", scriptHash)
-
-	// 通过通用调用函数执行RPC请求
-	result, err := CallElectrumXRPC(ctx, "blockchain.scripthash.get_history", []interface{}{scriptHash})
+		"scriptHash:", scriptHash,
+		"fromHeight:", fromHeight)
+
+	limit := maxHistoryItems()
+	fetch := func(params []interface{}) error {
+		collector.reset()
+		scanned := 0
+		return CallMethodEach(ctx, "blockchain.scripthash.get_history", params, func(dec *json.Decoder) error {
+			var item electrumx.ElectrumXHistoryItem
+			if err := dec.Decode(&item); err != nil {
+				return fmt.Errorf("解析历史记录失败: %w", err)
+			}
+			if scanned++; scanned > limit {
+				return fmt.Errorf("%w: 超过%d条", ErrHistoryTooLarge, limit)
+			}
+			// 高度小于1的记录为未确认交易，始终保留；服务端可能不支持起始高度参数，这里再做一次本地过滤
+			if item.Height < 1 || item.Height >= fromHeight {
+				collector.add(item)
+			}
+			return nil
+		})
+	}
+
+	params := historyParams(scriptHash, fromHeight, maxCount)
+	err := fetch(params)
+	// 参数无效可能由请求本身引起，从最后一个可选参数开始逐个去掉后重试，重试成功才标记返回错误的服务器不支持该参数
+	// 不支持条数参数时由collector截断，不支持起始高度参数时返回完整历史后本地过滤
+	server, invalid := invalidParamsServer(err)
+	for _, capability := range historyCapabilities(fromHeight, maxCount) {
+		if !invalid {
+			break
+		}
+		if !serverCaps.supports(server, capability) {
+			continue
+		}
+		if err = fetch(withoutOptionalParam(params, capability)); err == nil {
+			serverCaps.markUnsupported(server, capability)
+			break
+		}
+		_, invalid = invalidParamsServer(err)
+	}
 	if err != nil {
 		log.ErrorWithContext(ctx, "获取脚本哈希历史失败",
 			"scriptHash:", scriptHash,
 			"错误:", err)
-		return nil, fmt.Errorf("获取脚本哈希历史失败: %w", err)
+		return fmt.Errorf("获取脚本哈希历史失败: %w", err)
 	}
+	return nil
+}
 
-	// 解析响应数据
-	var history electrumx.ElectrumXHistoryResponse
-	if err := json.Unmarshal(result, &history); err != nil {
-		log.ErrorWithContext(ctx, "解析脚本哈希历史失败",
-			"scriptHash:", scriptHash,
-			"错误:", err)
-		return nil, fmt.Errorf("解析脚本哈希历史失败: %w", err)
+// historyCollector 接收逐项解码的历史记录，决定保留哪些记录
+type historyCollector interface {
+	add(item electrumx.ElectrumXHistoryItem)
+	// reset 清空已接收的记录，重新发起查询前调用
+	reset()
+}
+
+// headCollector 保留最早的max条记录，max为0时保留全部
+type headCollector struct {
+	max   int
+	items electrumx.ElectrumXHistoryResponse
+}
+
+func (c *headCollector) add(item electrumx.ElectrumXHistoryItem) {
+	if c.max == 0 || len(c.items) < c.max {
+		c.items = append(c.items, item)
 	}
+}
 
-	// 记录成功获取
-	log.InfoWithContext(ctx, "成功获取脚本哈希历史",
-		"scriptHash:", scriptHash,
-		"count:", len(history))
+func (c *headCollector) reset() {
+	c.items = nil
+}
 
-	return history, nil
+// tailCollector 用环形缓冲区保留最新的keep条记录并统计记录总数
+type tailCollector struct {
+	keep  int
+	ring  electrumx.ElectrumXHistoryResponse
+	next  int // 缓冲区已满时下一条记录覆盖的位置，即最早一条记录的位置
+	total int
+}
+
+func (c *tailCollector) add(item electrumx.ElectrumXHistoryItem) {
+	c.total++
+	if c.keep == 0 {
+		return
+	}
+	if len(c.ring) < c.keep {
+		c.ring = append(c.ring, item)
+		return
+	}
+	c.ring[c.next] = item
+	c.next = (c.next + 1) % c.keep
+}
+
+func (c *tailCollector) reset() {
+	c.ring, c.next, c.total = nil, 0, 0
+}
+
+// items 按从旧到新的顺序返回保留的记录
+func (c *tailCollector) items() electrumx.ElectrumXHistoryResponse {
+	items := make(electrumx.ElectrumXHistoryResponse, 0, len(c.ring))
+	items = append(items, c.ring[c.next:]...)
+	return append(items, c.ring[:c.next]...)
+}
+
+// historyParams 构建历史查询的RPC参数，指定起始高度时附带from_height，指定条数时再附带max_count
+// max_count是from_height之后的位置参数，只指定条数时from_height为0；发送时由连接所在的服务器是否支持决定是否保留
+func historyParams(scriptHash string, fromHeight int64, maxCount int) []interface{} {
+	params := []interface{}{scriptHash}
+	if fromHeight > 0 || maxCount > 0 {
+		params = append(params, optionalParam{capability: CapabilityHistoryFromHeight, value: fromHeight})
+	}
+	if maxCount > 0 {
+		params = append(params, optionalParam{capability: CapabilityHistoryMaxCount, value: maxCount})
+	}
+	return params
+}
+
+// historyCapabilities 返回历史查询参数依赖的可选能力，位置靠后的参数排在前面
+func historyCapabilities(fromHeight int64, maxCount int) []Capability {
+	var capabilities []Capability
+	if maxCount > 0 {
+		capabilities = append(capabilities, CapabilityHistoryMaxCount)
+	}
+	if fromHeight > 0 || maxCount > 0 {
+		capabilities = append(capabilities, CapabilityHistoryFromHeight)
+	}
+	return capabilities
 }
 
 // GetAddressBalance 获取比特币地址的余额
@@ -702,7 +975,7 @@ func GetScriptHashUnspent(ctx context.Context, scriptHash string) (electrumx.Utx
 
 	// 解析响应数据
 	var utxos electrumx.UtxoResponse
-	if err := json.Unmarshal(result, &utxos); err != nil {
+	if err := schemawatch.Decode(ctx, schemawatch.SourceElectrumX, "blockchain.scripthash.listunspent", result, &utxos); err != nil {
 		log.ErrorWithContext(ctx, "解析脚本哈希UTXO失败",
 			"scriptHash:", scriptHash,
 			"错误:", err)
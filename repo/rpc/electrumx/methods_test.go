@@ -0,0 +1,124 @@
+package electrumx
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ginproject/entity/electrumx"
+)
+
+// stubDefaultClient 替换默认客户端的创建函数，并关闭连接池避免读取配置
+func stubDefaultClient(t *testing.T, create func() (*ElectrumXClient, error)) {
+	t.Helper()
+	orig := newDefaultClient
+	newDefaultClient = create
+	DisablePool()
+	t.Cleanup(func() {
+		Shutdown()
+		newDefaultClient = orig
+		EnablePool()
+	})
+}
+
+func TestGetDefaultClientConcurrent(t *testing.T) {
+	var created atomic.Int32
+	stubDefaultClient(t, func() (*ElectrumXClient, error) {
+		created.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return &ElectrumXClient{}, nil
+	})
+
+	clients := make([]*ElectrumXClient, 16)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i], _ = GetDefaultClient()
+		}()
+	}
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Fatalf("并发首次调用创建了%d个客户端", created.Load())
+	}
+	for _, client := range clients {
+		if client == nil || client != clients[0] {
+			t.Fatal("并发调用返回了不同的客户端")
+		}
+	}
+
+	// 关闭后重新创建
+	if err := Shutdown(); err != nil {
+		t.Fatalf("关闭默认客户端失败: %v", err)
+	}
+	if _, _, err := GetClientPoolStats(); err == nil {
+		t.Fatal("关闭后不应再返回连接池统计")
+	}
+	if client, err := GetDefaultClient(); err != nil || client == clients[0] || created.Load() != 2 {
+		t.Fatalf("关闭后应重新创建客户端: %v, 创建次数%d", err, created.Load())
+	}
+}
+
+func TestGetDefaultClientRetry(t *testing.T) {
+	var calls atomic.Int32
+	stubDefaultClient(t, func() (*ElectrumXClient, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("初始化失败")
+		}
+		return &ElectrumXClient{}, nil
+	})
+
+	if _, err := GetDefaultClient(); err == nil {
+		t.Fatal("期望首次初始化失败")
+	}
+	if client, err := GetDefaultClient(); err != nil || client == nil {
+		t.Fatalf("初始化失败后应重试: %v", err)
+	}
+}
+
+func TestHistoryCollectors(t *testing.T) {
+	items := make([]electrumx.ElectrumXHistoryItem, 7)
+	for i := range items {
+		items[i] = electrumx.ElectrumXHistoryItem{Height: int64(i + 1)}
+	}
+	heights := func(history electrumx.ElectrumXHistoryResponse) []int64 {
+		result := make([]int64, len(history))
+		for i, item := range history {
+			result[i] = item.Height
+		}
+		return result
+	}
+
+	tail := &tailCollector{keep: 3}
+	head := &headCollector{max: 2}
+	for _, item := range items {
+		tail.add(item)
+		head.add(item)
+	}
+	if got := heights(tail.items()); tail.total != 7 || len(got) != 3 || got[0] != 5 || got[2] != 7 {
+		t.Errorf("tail = %v, total = %d", got, tail.total)
+	}
+	if got := heights(head.items); len(got) != 2 || got[1] != 2 {
+		t.Errorf("head = %v", got)
+	}
+
+	// 记录数不足keep时全部保留
+	tail.reset()
+	tail.add(items[0])
+	if got := tail.items(); tail.total != 1 || len(got) != 1 {
+		t.Errorf("reset后 tail = %v, total = %d", got, tail.total)
+	}
+
+	// keep为0时只计数
+	counter := &tailCollector{}
+	for _, item := range items {
+		counter.add(item)
+	}
+	if counter.total != 7 || len(counter.items()) != 0 {
+		t.Errorf("只计数时 total = %d", counter.total)
+	}
+}
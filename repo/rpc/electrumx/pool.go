@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"sync"
 	"time"
@@ -16,35 +17,61 @@ import (
 
 // ConnPool ElectrumX连接池
 type ConnPool struct {
-	mu            sync.Mutex
-	config        *config.ElectrumXConfig
-	client        *ElectrumXClient
-	conns         chan net.Conn
-	maxIdleConns  int
-	maxOpenConns  int
-	connTimeout   time.Duration
-	idleTimeout   time.Duration
-	createdConns  int
-	connErr       error
-	lastConnErr   time.Time
-	closed        bool
-	cleanerCtx    context.Context
-	cleanerCancel context.CancelFunc
+	mu                sync.Mutex
+	config            *config.ElectrumXConfig
+	client            *ElectrumXClient
+	conns             chan *pooledConn
+	maxIdleConns      int
+	maxOpenConns      int
+	connTimeout       time.Duration
+	idleTimeout       time.Duration
+	maxLifetime       time.Duration
+	validateAfterIdle time.Duration
+	createdConns      int
+	recycledConns     int64
+	failedConns       int64
+	connErr           error
+	lastConnErr       time.Time
+	closed            bool
+	cleanerCtx        context.Context
+	cleanerCancel     context.CancelFunc
+}
+
+// pooledConn 连接池中的连接，记录创建时间、过期时间和最后使用时间
+type pooledConn struct {
+	net.Conn
+	createdAt time.Time
+	expiresAt time.Time
+	lastUsed  time.Time
 }
 
 // PoolConfig 连接池配置
 type PoolConfig struct {
-	MaxIdleConns int
-	MaxOpenConns int
-	IdleTimeout  time.Duration
+	MaxIdleConns      int
+	MaxOpenConns      int
+	IdleTimeout       time.Duration
+	MaxLifetime       time.Duration
+	ValidateAfterIdle time.Duration
+}
+
+// PoolMetrics 连接池统计指标
+type PoolMetrics struct {
+	IdleConns     int   `json:"idle_conns"`
+	OpenConns     int   `json:"open_conns"`
+	RecycledConns int64 `json:"recycled_conns"`
+	FailedConns   int64 `json:"failed_conns"`
 }
 
 // 常量定义
 const (
-	defaultIdleTimeout  = 10 * time.Minute
-	defaultMaxIdleConns = 10
-	defaultMaxOpenConns = 20
-	connRetryDelay      = 5 * time.Second
+	defaultIdleTimeout       = 10 * time.Minute
+	defaultMaxIdleConns      = 10
+	defaultMaxOpenConns      = 20
+	defaultMaxLifetime       = 30 * time.Minute
+	defaultValidateAfterIdle = 30 * time.Second
+	connRetryDelay           = 5 * time.Second
+	// lifetimeJitter 连接寿命的随机抖动比例，避免连接集中过期
+	lifetimeJitter = 0.1
 )
 
 var (
@@ -72,6 +99,8 @@ func NewConnPool(client *ElectrumXClient, poolConfig *PoolConfig) (*ConnPool, er
 	maxIdleConns := defaultMaxIdleConns
 	maxOpenConns := defaultMaxOpenConns
 	idleTimeout := defaultIdleTimeout
+	maxLifetime := defaultMaxLifetime
+	validateAfterIdle := defaultValidateAfterIdle
 
 	// 优先使用配置中的值
 	if electrumXConfig.MaxIdleConns > 0 {
@@ -80,6 +109,12 @@ func NewConnPool(client *ElectrumXClient, poolConfig *PoolConfig) (*ConnPool, er
 	if electrumXConfig.MaxOpenConns > 0 {
 		maxOpenConns = electrumXConfig.MaxOpenConns
 	}
+	if electrumXConfig.MaxConnLifetime > 0 {
+		maxLifetime = time.Duration(electrumXConfig.MaxConnLifetime) * time.Second
+	}
+	if electrumXConfig.ValidateAfterIdle > 0 {
+		validateAfterIdle = time.Duration(electrumXConfig.ValidateAfterIdle) * time.Second
+	}
 
 	// 如果提供了池配置，则覆盖默认值
 	if poolConfig != nil {
@@ -92,6 +127,12 @@ func NewConnPool(client *ElectrumXClient, poolConfig *PoolConfig) (*ConnPool, er
 		if poolConfig.IdleTimeout > 0 {
 			idleTimeout = poolConfig.IdleTimeout
 		}
+		if poolConfig.MaxLifetime > 0 {
+			maxLifetime = poolConfig.MaxLifetime
+		}
+		if poolConfig.ValidateAfterIdle > 0 {
+			validateAfterIdle = poolConfig.ValidateAfterIdle
+		}
 	}
 
 	// 确保参数合理
@@ -102,15 +143,17 @@ func NewConnPool(client *ElectrumXClient, poolConfig *PoolConfig) (*ConnPool, er
 	ctx, cancel := context.WithCancel(context.Background())
 
 	pool := &ConnPool{
-		client:        client,
-		config:        electrumXConfig,
-		conns:         make(chan net.Conn, maxIdleConns),
-		maxIdleConns:  maxIdleConns,
-		maxOpenConns:  maxOpenConns,
-		connTimeout:   time.Duration(electrumXConfig.Timeout) * time.Second,
-		idleTimeout:   idleTimeout,
-		cleanerCtx:    ctx,
-		cleanerCancel: cancel,
+		client:            client,
+		config:            electrumXConfig,
+		conns:             make(chan *pooledConn, maxIdleConns),
+		maxIdleConns:      maxIdleConns,
+		maxOpenConns:      maxOpenConns,
+		connTimeout:       time.Duration(electrumXConfig.Timeout) * time.Second,
+		idleTimeout:       idleTimeout,
+		maxLifetime:       maxLifetime,
+		validateAfterIdle: validateAfterIdle,
+		cleanerCtx:        ctx,
+		cleanerCancel:     cancel,
 	}
 
 	// 启动空闲连接清理协程
@@ -118,7 +161,8 @@ func NewConnPool(client *ElectrumXClient, poolConfig *PoolConfig) (*ConnPool, er
 
 	log.Info("ElectrumX连接池已创建, 最大空闲连接:", maxIdleConns,
 		", 最大打开连接:", maxOpenConns,
-		", 空闲超时:", idleTimeout)
+		", 空闲超时:", idleTimeout,
+		", 最大寿命:", maxLifetime)
 	return pool, nil
 }
 
@@ -135,7 +179,7 @@ func (p *ConnPool) GetConn(ctx context.Context) (net.Conn, error) {
 	case conn := <-p.conns:
 		p.mu.Unlock()
 		// 检查连接是否有效
-		if !p.validateConn(conn) {
+		if !p.checkIdleConn(conn) {
 			// 无效连接，创建新连接
 			return p.createConn(ctx)
 		}
@@ -164,8 +208,11 @@ func (p *ConnPool) GetConn(ctx context.Context) (net.Conn, error) {
 
 	select {
 	case conn := <-p.conns:
-		if !p.validateConn(conn) {
+		if !p.checkIdleConn(conn) {
 			// 无效连接，尝试重新获取
+			p.mu.Lock()
+			p.createdConns--
+			p.mu.Unlock()
 			return p.GetConn(ctx)
 		}
 		return conn, nil
@@ -179,10 +226,12 @@ func (p *ConnPool) GetConn(ctx context.Context) (net.Conn, error) {
 
 // createConn 创建新连接
 func (p *ConnPool) createConn(ctx context.Context) (net.Conn, error) {
-	// 如果最近连接有错误，等待一段时间再重试
+	// 如果最近连接有错误，等待一段时间再重试；启用故障切换时由服务器组按服务器退避
+	// 调用方已为新连接占用了名额，放弃创建时归还
 	p.mu.Lock()
-	if p.connErr != nil && time.Since(p.lastConnErr) < connRetryDelay {
+	if p.connErr != nil && time.Since(p.lastConnErr) < connRetryDelay && currentServerSet() == nil {
 		err := p.connErr
+		p.createdConns--
 		p.mu.Unlock()
 		return nil, err
 	}
@@ -196,19 +245,74 @@ func (p *ConnPool) createConn(ctx context.Context) (net.Conn, error) {
 	}
 
 	// 创建连接
-	conn, err := p.client.Connect()
+	conn, err := p.client.ConnectContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		// 调用方取消或超时，不影响后续请求建连
+		p.mu.Lock()
+		p.createdConns--
+		p.mu.Unlock()
+		return nil, err
+	}
 	if err != nil {
 		p.mu.Lock()
 		p.connErr = err
 		p.lastConnErr = time.Now()
 		p.createdConns--
+		p.failedConns++
 		p.mu.Unlock()
 		log.Error("创建ElectrumX连接失败:", err)
 		return nil, err
 	}
 
 	log.Debug("创建新的ElectrumX连接成功")
-	return conn, nil
+	now := time.Now()
+	return &pooledConn{
+		Conn:      conn,
+		createdAt: now,
+		expiresAt: now.Add(p.jitteredLifetime()),
+		lastUsed:  now,
+	}, nil
+}
+
+// jitteredLifetime 返回带随机抖动的连接寿命
+func (p *ConnPool) jitteredLifetime() time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(float64(p.maxLifetime)*lifetimeJitter) + 1))
+	return p.maxLifetime - jitter
+}
+
+// checkIdleConn 检查空闲连接：超过寿命或所在服务器已不健康的连接直接回收，空闲过久的连接强制验证
+// 返回false时连接已被关闭，调用方负责调整计数
+func (p *ConnPool) checkIdleConn(conn *pooledConn) bool {
+	if !serverHealthy(conn) {
+		conn.Close()
+		p.mu.Lock()
+		p.recycledConns++
+		p.mu.Unlock()
+		log.Debug("ElectrumX连接所在服务器不健康，已回收")
+		return false
+	}
+
+	if time.Now().After(conn.expiresAt) {
+		conn.Close()
+		p.mu.Lock()
+		p.recycledConns++
+		p.mu.Unlock()
+		log.Debug("ElectrumX连接超过最大寿命，已回收")
+		return false
+	}
+
+	if time.Since(conn.lastUsed) < p.validateAfterIdle {
+		return true
+	}
+
+	if !p.validateConn(conn) {
+		conn.Close()
+		p.mu.Lock()
+		p.failedConns++
+		p.mu.Unlock()
+		return false
+	}
+	return true
 }
 
 // PutConn 将连接放回池中
@@ -217,23 +321,52 @@ func (p *ConnPool) PutConn(conn net.Conn) {
 		return
 	}
 
+	pc, ok := conn.(*pooledConn)
+	if !ok {
+		now := time.Now()
+		pc = &pooledConn{Conn: conn, createdAt: now, expiresAt: now.Add(p.jitteredLifetime())}
+	}
+	pc.lastUsed = time.Now()
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	if p.closed {
-		conn.Close()
+		pc.Close()
+		return
+	}
+
+	// 超过寿命的连接不再放回，直接回收
+	if pc.lastUsed.After(pc.expiresAt) {
+		p.createdConns--
+		p.recycledConns++
+		pc.Close()
 		return
 	}
 
 	// 尝试将连接放入空闲池
 	select {
-	case p.conns <- conn:
+	case p.conns <- pc:
 		// 成功放入空闲池
 		return
 	default:
 		// 空闲池已满，关闭连接
 		p.createdConns--
-		conn.Close()
+		pc.Close()
+	}
+}
+
+// DiscardConn 关闭一个状态不确定的连接，不再放回池中
+func (p *ConnPool) DiscardConn(conn net.Conn) {
+	if conn == nil {
+		return
+	}
+	conn.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.createdConns--
 	}
 }
 
@@ -310,6 +443,27 @@ func (p *ConnPool) Stats() (idleConns, openConns int) {
 	return len(p.conns), p.createdConns
 }
 
+// Usage 返回正在使用的连接数和连接数上限
+func (p *ConnPool) Usage() (inUse, capacity int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.createdConns - len(p.conns), p.maxOpenConns
+}
+
+// Metrics 获取连接池统计指标，包括回收和失败的连接数
+func (p *ConnPool) Metrics() PoolMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolMetrics{
+		IdleConns:     len(p.conns),
+		OpenConns:     p.createdConns,
+		RecycledConns: p.recycledConns,
+		FailedConns:   p.failedConns,
+	}
+}
+
 // connectionCleaner 定期清理空闲连接
 func (p *ConnPool) connectionCleaner() {
 	ticker := time.NewTicker(p.idleTimeout / 2)
@@ -340,9 +494,33 @@ func (p *ConnPool) connectionCleaner() {
 					}
 				}
 			}
+
+			// 回收超过寿命的空闲连接
+			p.recycleExpiredLocked()
 			p.mu.Unlock()
 		case <-p.cleanerCtx.Done():
 			return
 		}
 	}
 }
+
+// recycleExpiredLocked 回收空闲池中超过寿命的连接，调用方需持有锁
+func (p *ConnPool) recycleExpiredLocked() {
+	now := time.Now()
+	count := len(p.conns)
+	for i := 0; i < count; i++ {
+		select {
+		case conn := <-p.conns:
+			if now.After(conn.expiresAt) {
+				conn.Close()
+				p.createdConns--
+				p.recycledConns++
+				continue
+			}
+			// 未过期的连接放回空闲池
+			p.conns <- conn
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package electrumx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+)
+
+// fakeServer 本地ElectrumX服务器，healthy为false时接受连接后立即关闭，否则应答协议协商
+// 其它请求在delay之后返回服务器地址，设置了respond时返回respond生成的result或error字段
+type fakeServer struct {
+	listener net.Listener
+	healthy  atomic.Bool
+	delay    atomic.Int64
+	accepted atomic.Int32
+	respond  func(req RPCRequest) string
+	wg       sync.WaitGroup
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{listener: listener}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.accepted.Add(1)
+			if !s.healthy.Load() {
+				conn.Close()
+				continue
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadBytes('\n')
+					if err != nil {
+						return
+					}
+					var req RPCRequest
+					json.Unmarshal(line, &req)
+					if req.Method == "server.version" {
+						fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":["ElectrumX 1.16","1.4"]}`+"\n", req.ID)
+						continue
+					}
+					time.Sleep(time.Duration(s.delay.Load()))
+					if s.respond != nil {
+						fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,%s}`+"\n", req.ID, s.respond(req))
+						continue
+					}
+					fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":%q}`+"\n", req.ID, s.address())
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+func (s *fakeServer) address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) config() *config.ElectrumXConfig {
+	host, port, _ := net.SplitHostPort(s.address())
+	portNum, _ := strconv.Atoi(port)
+	return &config.ElectrumXConfig{Host: host, Port: portNum, Protocol: "tcp", Timeout: 5}
+}
+
+func TestPoolRecoversAfterFailures(t *testing.T) {
+	server := newFakeServer(t)
+	p := &ConnPool{
+		client:       &ElectrumXClient{config: server.config()},
+		conns:        make(chan *pooledConn, 2),
+		maxOpenConns: 2,
+		connTimeout:  200 * time.Millisecond,
+		maxLifetime:  time.Minute,
+	}
+
+	// 服务器不可用期间的失败次数超过连接数上限，退避期间的直接返回也不能占用名额
+	for i := 0; i < 5; i++ {
+		if _, err := p.GetConn(context.Background()); err == nil {
+			t.Fatalf("第%d次获取连接应失败", i)
+		}
+	}
+	if _, open := p.Stats(); open != 0 {
+		t.Fatalf("失败后打开的连接数 = %d, 期望 0", open)
+	}
+
+	// 服务器恢复且退避结束后可以重新建连
+	server.healthy.Store(true)
+	p.mu.Lock()
+	p.lastConnErr = time.Now().Add(-connRetryDelay)
+	p.mu.Unlock()
+	conn, err := p.GetConn(context.Background())
+	if err != nil {
+		t.Fatalf("服务器恢复后获取连接失败: %v", err)
+	}
+	conn.Close()
+}
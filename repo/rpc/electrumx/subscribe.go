@@ -0,0 +1,194 @@
+package electrumx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"ginproject/middleware/log"
+)
+
+// 订阅连接上缓冲的通知数，消费方处理过慢时读循环会阻塞等待
+const notificationBufferSize = 256
+
+// ErrSubscriptionClosed 订阅连接已关闭
+var ErrSubscriptionClosed = errors.New("ElectrumX订阅连接已关闭")
+
+// Notification ElectrumX服务端推送的订阅通知
+type Notification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// subscriptionMessage 订阅连接上收到的消息，带ID的是请求响应，不带ID的是通知
+type subscriptionMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// SubscriptionConn 与ElectrumX之间用于订阅的长连接
+// 连接不经过连接池，ElectrumX的订阅绑定在会话上，连接断开后订阅失效，需要重新建立连接并重新订阅
+type SubscriptionConn struct {
+	conn   net.Conn
+	nextID int32
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[int]chan subscriptionMessage
+
+	notifications chan Notification
+	closed        chan struct{}
+	closeOnce     sync.Once
+	err           error
+}
+
+// DialSubscription 建立订阅连接并启动读循环
+func DialSubscription() (*SubscriptionConn, error) {
+	client, err := GetDefaultClient()
+	if err != nil {
+		return nil, fmt.Errorf("获取ElectrumX客户端失败: %w", err)
+	}
+	conn, err := client.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return newSubscriptionConn(conn), nil
+}
+
+// newSubscriptionConn 在已协商版本的连接上启动读循环
+func newSubscriptionConn(conn net.Conn) *SubscriptionConn {
+	s := &SubscriptionConn{
+		conn:          conn,
+		pending:       make(map[int]chan subscriptionMessage),
+		notifications: make(chan Notification, notificationBufferSize),
+		closed:        make(chan struct{}),
+	}
+	go s.readLoop()
+	return s
+}
+
+// Call 在订阅连接上调用RPC方法，订阅类方法必须通过该连接调用才能收到后续通知
+func (s *SubscriptionConn) Call(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+	id := int(atomic.AddInt32(&s.nextID, 1))
+	respChan := make(chan subscriptionMessage, 1)
+
+	s.mu.Lock()
+	select {
+	case <-s.closed:
+		s.mu.Unlock()
+		return nil, s.Err()
+	default:
+	}
+	s.pending[id] = respChan
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	s.writeMu.Lock()
+	err := writeRPCRequest(s.conn, RPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	s.writeMu.Unlock()
+	if err != nil {
+		s.close(err)
+		return nil, err
+	}
+
+	select {
+	case msg := <-respChan:
+		if msg.Error != nil {
+			return nil, fmt.Errorf("RPC调用错误: %w", msg.Error)
+		}
+		return msg.Result, nil
+	case <-s.closed:
+		return nil, s.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Notifications 返回服务端推送的通知，连接关闭后通道不再有新数据，应配合Done使用
+func (s *SubscriptionConn) Notifications() <-chan Notification {
+	return s.notifications
+}
+
+// Done 返回连接关闭时关闭的通道
+func (s *SubscriptionConn) Done() <-chan struct{} {
+	return s.closed
+}
+
+// Err 返回连接关闭的原因
+func (s *SubscriptionConn) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		return ErrSubscriptionClosed
+	}
+	return s.err
+}
+
+// Close 关闭订阅连接
+func (s *SubscriptionConn) Close() error {
+	s.close(ErrSubscriptionClosed)
+	return nil
+}
+
+// close 记录关闭原因并关闭底层连接，只有第一次调用生效
+func (s *SubscriptionConn) close(err error) {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		close(s.closed)
+		s.mu.Unlock()
+		s.conn.Close()
+	})
+}
+
+// readLoop 逐行读取连接上的消息，响应交给等待中的调用方，通知写入通知通道
+func (s *SubscriptionConn) readLoop() {
+	reader := bufio.NewReaderSize(s.conn, readerBufferSize)
+	var buf bytes.Buffer
+	for {
+		buf.Reset()
+		if err := readLine(reader, &buf); err != nil {
+			log.Warn("ElectrumX订阅连接读取失败:", err)
+			s.close(fmt.Errorf("%w: %v", ErrSubscriptionClosed, err))
+			return
+		}
+
+		var msg subscriptionMessage
+		if err := json.Unmarshal(buf.Bytes(), &msg); err != nil {
+			log.Warn("解析ElectrumX订阅消息失败:", truncateResponse(buf.Bytes()))
+			continue
+		}
+
+		if msg.ID != nil {
+			s.mu.Lock()
+			respChan, ok := s.pending[*msg.ID]
+			s.mu.Unlock()
+			if ok {
+				respChan <- msg
+			}
+			continue
+		}
+		if msg.Method == "" {
+			continue
+		}
+		select {
+		case s.notifications <- Notification{Method: msg.Method, Params: msg.Params}:
+		case <-s.closed:
+			return
+		}
+	}
+}
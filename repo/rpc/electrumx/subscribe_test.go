@@ -0,0 +1,65 @@
+package electrumx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionConnDispatch(t *testing.T) {
+	client, server := net.Pipe()
+	s := newSubscriptionConn(client)
+	defer s.Close()
+
+	// 服务端先推送一条通知再返回响应，两者应分别送达
+	go func() {
+		reader := bufio.NewReader(server)
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req RPCRequest
+		json.Unmarshal(line, &req)
+		server.Write([]byte(`{"jsonrpc":"2.0","method":"blockchain.headers.subscribe","params":[{"height":101,"hex":"00"}]}` + "\n"))
+		server.Write([]byte(`{"jsonrpc":"2.0","id":` + string(mustJSON(req.ID)) + `,"result":{"height":100,"hex":"00"}}` + "\n"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := s.Call(ctx, "blockchain.headers.subscribe", []interface{}{})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if string(result) != `{"height":100,"hex":"00"}` {
+		t.Errorf("Call() = %s", result)
+	}
+
+	select {
+	case n := <-s.Notifications():
+		if n.Method != "blockchain.headers.subscribe" {
+			t.Errorf("notification method = %s", n.Method)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("未收到通知")
+	}
+
+	// 服务端断开后连接关闭，后续调用立即失败
+	server.Close()
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("连接断开后Done未关闭")
+	}
+	if _, err := s.Call(ctx, "server.ping", []interface{}{}); !errors.Is(err, ErrSubscriptionClosed) {
+		t.Errorf("Call() after close error = %v", err)
+	}
+}
+
+func mustJSON(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
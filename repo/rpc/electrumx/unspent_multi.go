@@ -0,0 +1,58 @@
+package electrumx
+
+import (
+	"context"
+	"sync"
+
+	"ginproject/entity/electrumx"
+	"ginproject/middleware/log"
+)
+
+// 多脚本哈希查询的最大并发数
+const maxMultiUnspentConcurrency = 8
+
+// ScriptHashUnspentResult 单个脚本哈希的UTXO查询结果
+type ScriptHashUnspentResult struct {
+	ScriptHash string
+	Utxos      electrumx.UtxoResponse
+	Error      error
+}
+
+// GetScriptHashUnspentMulti 并发获取多个脚本哈希的未花费交易输出
+// ElectrumX协议没有批量的listunspent方法，这里对每个脚本哈希并发发起请求
+// 重复的脚本哈希只查询一次，返回结果按首次出现的顺序排列，单个脚本的失败记录在对应结果的Error中
+func GetScriptHashUnspentMulti(ctx context.Context, scriptHashes []string) []ScriptHashUnspentResult {
+	seen := make(map[string]struct{}, len(scriptHashes))
+	results := make([]ScriptHashUnspentResult, 0, len(scriptHashes))
+	for _, scriptHash := range scriptHashes {
+		if _, ok := seen[scriptHash]; ok {
+			continue
+		}
+		seen[scriptHash] = struct{}{}
+		results = append(results, ScriptHashUnspentResult{ScriptHash: scriptHash})
+	}
+
+	log.InfoWithContext(ctx, "开始批量获取脚本哈希的UTXO", "count:", len(results))
+
+	sem := make(chan struct{}, maxMultiUnspentConcurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(res *ScriptHashUnspentResult) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				res.Error = ctx.Err()
+				return
+			}
+
+			res.Utxos, res.Error = GetScriptHashUnspent(ctx, res.ScriptHash)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
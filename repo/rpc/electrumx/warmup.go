@@ -0,0 +1,83 @@
+package electrumx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"ginproject/middleware/log"
+)
+
+// WarmUp 预先建立n个连接放入空闲池，返回成功建立的连接数
+func (p *ConnPool) WarmUp(ctx context.Context, n int) (int, error) {
+	if n > p.maxIdleConns {
+		n = p.maxIdleConns
+	}
+	if n <= 0 {
+		return 0, nil
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		conns    []net.Conn
+		firstErr error
+	)
+
+	// 并发建立连接，全部建立后再统一归还，避免同一连接被重复取出
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := p.GetConn(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}()
+	}
+	wg.Wait()
+
+	for _, conn := range conns {
+		p.PutConn(conn)
+	}
+
+	if len(conns) == 0 && firstErr != nil {
+		return 0, fmt.Errorf("预热ElectrumX连接池失败: %w", firstErr)
+	}
+	return len(conns), nil
+}
+
+// WarmUp 预热默认客户端的连接池并预先协商服务器协议版本
+func WarmUp(ctx context.Context, n int) error {
+	client, err := GetDefaultClient()
+	if err != nil {
+		return fmt.Errorf("获取ElectrumX客户端失败: %w", err)
+	}
+
+	client.poolMu.Lock()
+	pool := client.pool
+	client.poolMu.Unlock()
+
+	if pool != nil {
+		created, err := pool.WarmUp(ctx, n)
+		if err != nil {
+			return err
+		}
+		log.InfoWithContext(ctx, "ElectrumX连接池预热完成", "connections:", created)
+	}
+
+	// 确认已完成协议版本协商
+	info, err := ServerVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("获取ElectrumX服务器版本失败: %w", err)
+	}
+	log.InfoWithContext(ctx, "ElectrumX服务器版本", "software:", info.Software, "protocol:", info.Protocol)
+	return nil
+}
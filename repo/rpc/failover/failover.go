@@ -0,0 +1,420 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+)
+
+// 默认参数
+const (
+	defaultFailureThreshold = 3
+	defaultBaseBackoff      = time.Second
+	defaultMaxBackoff       = time.Minute
+	defaultCheckInterval    = 10 * time.Second
+	defaultCheckTimeout     = 5 * time.Second
+)
+
+// ErrNoHealthyEndpoint 全部上游服务器都不健康且仍在退避中
+var ErrNoHealthyEndpoint = errors.New("没有可用的上游服务器")
+
+// Options 故障切换参数，零值字段使用默认值
+type Options struct {
+	FailureThreshold int                                             // 连续失败多少次后标记为不健康
+	BaseBackoff      time.Duration                                   // 标记为不健康后首次重试前的等待，之后每次失败翻倍
+	MaxBackoff       time.Duration                                   // 重试等待的上限
+	CheckInterval    time.Duration                                   // 健康检查周期
+	CheckTimeout     time.Duration                                   // 单次健康检查的超时时间
+	Probe            func(ctx context.Context, address string) error // 健康检查，为nil时只根据调用结果判断
+	Refresh          func() []string                                 // 每次健康检查前获取最新的地址列表，为nil或返回空时保留当前列表
+}
+
+// EndpointStatus 上游服务器的健康状态
+type EndpointStatus struct {
+	Address   string     `json:"address"`
+	Weight    int        `json:"weight,omitempty"` // 按权重轮询时的权重
+	Healthy   bool       `json:"healthy"`
+	Failures  int        `json:"failures"`             // 连续失败次数
+	RetryAt   *time.Time `json:"retry_at,omitempty"`   // 不健康时下一次允许重试的时间
+	LastError string     `json:"last_error,omitempty"` // 最近一次失败的原因
+	LastCheck *time.Time `json:"last_check,omitempty"` // 最近一次健康检查的时间
+}
+
+// Endpoint 按权重轮询的服务器组中的一个服务器
+type Endpoint struct {
+	Address string
+	Weight  int // 不大于0时视为1
+}
+
+// endpoint 单个上游服务器的状态
+type endpoint struct {
+	address   string
+	weight    int
+	current   int // 平滑加权轮询的当前权重
+	healthy   bool
+	failures  int
+	trips     int // 连续被标记为不健康的次数，决定退避时长
+	retryAt   time.Time
+	lastErr   error
+	lastCheck time.Time
+}
+
+// Set 一组上游服务器，默认按优先级排列，调用优先路由到排在前面的健康服务器；
+// 由NewWeightedSet创建时在健康服务器间按权重平滑轮询
+// 连续失败达到阈值的服务器被标记为不健康，按指数退避等待后由健康检查或实际调用重新尝试
+type Set struct {
+	mu        sync.RWMutex
+	name      string
+	opts      Options
+	weighted  bool
+	endpoints []*endpoint
+	cancel    context.CancelFunc
+	now       func() time.Time
+}
+
+// NewSet 创建服务器组，addresses的顺序即优先级，重复地址只保留第一个
+func NewSet(name string, addresses []string, opts Options) (*Set, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("%s未配置上游服务器", name)
+	}
+	s := newSet(name, opts)
+	s.Update(addresses)
+	return s, nil
+}
+
+// NewWeightedSet 创建按权重轮询的服务器组，重复地址只保留第一个
+// 不健康服务器退避到期后分到一次试探调用，成功即恢复，失败则退避翻倍，即断路器的半开状态
+// 之后由Update加入的服务器权重为1
+func NewWeightedSet(name string, endpoints []Endpoint, opts Options) (*Set, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%s未配置上游服务器", name)
+	}
+	s := newSet(name, opts)
+	s.weighted = true
+
+	addresses := make([]string, 0, len(endpoints))
+	for _, e := range endpoints {
+		addresses = append(addresses, e.Address)
+	}
+	s.Update(addresses)
+	for _, e := range s.endpoints {
+		for _, configured := range endpoints {
+			if configured.Address == e.address && configured.Weight > 0 {
+				e.weight = configured.Weight
+				break
+			}
+		}
+	}
+	return s, nil
+}
+
+// newSet 补全默认参数并创建空的服务器组
+func newSet(name string, opts Options) *Set {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = defaultFailureThreshold
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.MaxBackoff < opts.BaseBackoff {
+		opts.MaxBackoff = opts.BaseBackoff
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = defaultCheckInterval
+	}
+	if opts.CheckTimeout <= 0 {
+		opts.CheckTimeout = defaultCheckTimeout
+	}
+
+	return &Set{name: name, opts: opts, now: time.Now}
+}
+
+// Update 替换服务器列表，保留仍在列表中的服务器的健康状态，传入空列表时不做修改
+func (s *Set) Update(addresses []string) {
+	if len(addresses) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[string]*endpoint, len(s.endpoints))
+	for _, e := range s.endpoints {
+		existing[e.address] = e
+	}
+	endpoints := make([]*endpoint, 0, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		if e, ok := existing[address]; ok {
+			endpoints = append(endpoints, e)
+		} else {
+			endpoints = append(endpoints, &endpoint{address: address, weight: 1, healthy: true})
+		}
+	}
+	if len(endpoints) > 0 {
+		s.endpoints = endpoints
+	}
+}
+
+// Len 返回服务器数量
+func (s *Set) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.endpoints)
+}
+
+// Pick 返回本次调用使用的服务器
+func (s *Set) Pick() (string, error) {
+	return s.PickExcept("")
+}
+
+// PickExcept 返回除exclude外优先级最高的健康服务器，用于调用失败后切换到其它服务器
+// 没有健康服务器时返回退避已到期的服务器中最早到期的一个作为重连尝试，全部仍在退避中时返回错误
+func (s *Set) PickExcept(exclude string) (string, error) {
+	if s.weighted {
+		return s.pickWeighted(exclude)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	var retry *endpoint
+	for _, e := range s.endpoints {
+		if e.address == exclude {
+			continue
+		}
+		if e.healthy {
+			return e.address, nil
+		}
+		if !now.Before(e.retryAt) && (retry == nil || e.retryAt.Before(retry.retryAt)) {
+			retry = e
+		}
+	}
+	if retry != nil {
+		return retry.address, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoHealthyEndpoint, s.name)
+}
+
+// pickWeighted 优先把试探调用分给退避已到期的不健康服务器，并将其下一次试探推迟一个退避周期，
+// 否则在除exclude外的健康服务器间按平滑加权轮询选择
+func (s *Set) pickWeighted(exclude string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, e := range s.endpoints {
+		if e.address != exclude && !e.healthy && !now.Before(e.retryAt) {
+			e.retryAt = now.Add(s.backoff(e.trips))
+			return e.address, nil
+		}
+	}
+
+	var best *endpoint
+	total := 0
+	for _, e := range s.endpoints {
+		if e.address == exclude || !e.healthy {
+			continue
+		}
+		e.current += e.weight
+		total += e.weight
+		if best == nil || e.current > best.current {
+			best = e
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%w: %s", ErrNoHealthyEndpoint, s.name)
+	}
+	best.current -= total
+	return best.address, nil
+}
+
+// Healthy 判断服务器当前是否健康，不在列表中的服务器视为不健康
+func (s *Set) Healthy(address string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.endpoints {
+		if e.address == address {
+			return e.healthy
+		}
+	}
+	return false
+}
+
+// Report 记录一次调用或健康检查的结果，调用方应只上报连接、超时等服务器故障，不上报业务错误
+func (s *Set) Report(address string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var e *endpoint
+	for _, candidate := range s.endpoints {
+		if candidate.address == address {
+			e = candidate
+			break
+		}
+	}
+	if e == nil {
+		return
+	}
+
+	if err == nil {
+		if !e.healthy {
+			log.Infof("上游服务器已恢复: %s %s", s.name, address)
+		}
+		e.healthy, e.failures, e.trips, e.lastErr = true, 0, 0, nil
+		return
+	}
+
+	e.failures++
+	e.lastErr = err
+	if e.healthy && e.failures < s.opts.FailureThreshold {
+		return
+	}
+	if e.healthy {
+		log.Warnf("上游服务器连续失败%d次，切换到其它服务器: %s %s, err=%v", e.failures, s.name, address, err)
+	}
+	e.healthy = false
+	e.current = 0
+	e.trips++
+	e.retryAt = s.now().Add(s.backoff(e.trips))
+}
+
+// backoff 返回第trips次被标记为不健康后的退避时长
+func (s *Set) backoff(trips int) time.Duration {
+	delay := s.opts.BaseBackoff
+	for i := 1; i < trips && delay < s.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.opts.MaxBackoff)
+}
+
+// Status 返回各服务器的健康状态，按优先级排列
+func (s *Set) Status() []EndpointStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]EndpointStatus, 0, len(s.endpoints))
+	for _, e := range s.endpoints {
+		status := EndpointStatus{Address: e.address, Healthy: e.healthy, Failures: e.failures}
+		if s.weighted {
+			status.Weight = e.weight
+		}
+		if !e.healthy {
+			retryAt := e.retryAt
+			status.RetryAt = &retryAt
+		}
+		if e.lastErr != nil {
+			status.LastError = e.lastErr.Error()
+		}
+		if !e.lastCheck.IsZero() {
+			lastCheck := e.lastCheck
+			status.LastCheck = &lastCheck
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Start 启动后台健康检查，未配置健康检查且地址列表不会刷新时不启动
+func (s *Set) Start() {
+	if s.opts.Probe == nil && s.opts.Refresh == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go s.checkLoop(ctx)
+}
+
+// Close 停止后台健康检查
+func (s *Set) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// checkLoop 按周期刷新地址列表并检查服务器健康
+func (s *Set) checkLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.opts.Refresh != nil {
+				s.Update(s.opts.Refresh())
+			}
+			s.check(ctx)
+		}
+	}
+}
+
+// check 并发检查健康的服务器和退避已到期的不健康服务器，仍在退避中的服务器跳过
+func (s *Set) check(ctx context.Context) {
+	if s.opts.Probe == nil {
+		return
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	var addresses []string
+	for _, e := range s.endpoints {
+		if e.healthy || !now.Before(e.retryAt) {
+			e.lastCheck = now
+			addresses = append(addresses, e.address)
+		}
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, address := range addresses {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, s.opts.CheckTimeout)
+			defer cancel()
+			err := s.opts.Probe(probeCtx, address)
+			if ctx.Err() != nil {
+				return
+			}
+			s.Report(address, err)
+		}(address)
+	}
+	wg.Wait()
+}
+
+// Addresses 返回当前的服务器列表，按优先级排列
+func (s *Set) Addresses() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addresses := make([]string, 0, len(s.endpoints))
+	for _, e := range s.endpoints {
+		addresses = append(addresses, e.address)
+	}
+	return addresses
+}
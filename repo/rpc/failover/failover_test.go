@@ -0,0 +1,191 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestSet 创建使用可控时钟的服务器组
+func newTestSet(t *testing.T, addresses []string, opts Options) (*Set, *time.Time) {
+	t.Helper()
+	s, err := NewSet("test", addresses, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestFailoverAndRecovery(t *testing.T) {
+	s, now := newTestSet(t, []string{"a:1", "b:1", "a:1"}, Options{FailureThreshold: 2, BaseBackoff: time.Second, MaxBackoff: 4 * time.Second})
+	if s.Len() != 2 {
+		t.Fatalf("重复地址应只保留一个: %v", s.Addresses())
+	}
+	failure := errors.New("connection refused")
+
+	s.Report("a:1", failure)
+	if got, _ := s.Pick(); got != "a:1" {
+		t.Errorf("未达到失败阈值前应继续使用主服务器: %s", got)
+	}
+	s.Report("a:1", failure)
+	if got, _ := s.Pick(); got != "b:1" {
+		t.Errorf("主服务器不健康时应切换到备用服务器: %s", got)
+	}
+
+	// 全部不健康且在退避中时返回错误
+	s.Report("b:1", failure)
+	s.Report("b:1", failure)
+	if _, err := s.Pick(); !errors.Is(err, ErrNoHealthyEndpoint) {
+		t.Errorf("全部在退避中时应返回ErrNoHealthyEndpoint: %v", err)
+	}
+
+	// 退避到期后允许重连尝试，再次失败时退避翻倍
+	*now = now.Add(time.Second)
+	if got, _ := s.Pick(); got != "a:1" {
+		t.Errorf("退避到期后应重新尝试最早到期的服务器: %s", got)
+	}
+	s.Report("a:1", failure)
+	if status := s.Status()[0]; status.RetryAt == nil || !status.RetryAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("第二次标记为不健康时退避应翻倍: %+v", status)
+	}
+
+	s.Report("a:1", nil)
+	if got, _ := s.Pick(); got != "a:1" || !s.Healthy("a:1") {
+		t.Errorf("恢复后应回到主服务器: %s", got)
+	}
+	if got, _ := s.PickExcept("a:1"); got != "b:1" {
+		t.Errorf("PickExcept应跳过指定服务器: %s", got)
+	}
+}
+
+func TestBackoffCapped(t *testing.T) {
+	s, _ := newTestSet(t, []string{"a:1"}, Options{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	for trips, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := s.backoff(trips); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", trips, got, want)
+		}
+	}
+}
+
+func TestUpdateKeepsState(t *testing.T) {
+	s, _ := newTestSet(t, []string{"a:1", "b:1"}, Options{FailureThreshold: 1})
+	s.Report("a:1", errors.New("timeout"))
+
+	s.Update([]string{"c:1", "a:1"})
+	if got := s.Addresses(); len(got) != 2 || got[0] != "c:1" {
+		t.Fatalf("Addresses = %v", got)
+	}
+	if s.Healthy("a:1") || !s.Healthy("c:1") || s.Healthy("b:1") {
+		t.Errorf("保留的服务器应保持原状态，新服务器视为健康: %+v", s.Status())
+	}
+
+	s.Update(nil)
+	if s.Len() != 2 {
+		t.Errorf("空列表不应清空服务器组")
+	}
+}
+
+func TestCheckProbesEligible(t *testing.T) {
+	probed := make(chan string, 3)
+	s, now := newTestSet(t, []string{"a:1", "b:1"}, Options{
+		FailureThreshold: 1,
+		BaseBackoff:      time.Minute,
+		Probe: func(ctx context.Context, address string) error {
+			probed <- address
+			return nil
+		},
+	})
+	s.Report("b:1", errors.New("timeout"))
+
+	// 仍在退避中的服务器不检查
+	s.check(context.Background())
+	if len(probed) != 1 || <-probed != "a:1" {
+		t.Fatalf("只应检查健康的服务器")
+	}
+
+	*now = now.Add(time.Minute)
+	s.check(context.Background())
+	if len(probed) != 2 || !s.Healthy("b:1") {
+		t.Errorf("退避到期的服务器检查通过后应恢复: %+v", s.Status())
+	}
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	s, err := NewWeightedSet("test", []Endpoint{{Address: "a", Weight: 3}, {Address: "b"}, {Address: "a", Weight: 5}}, Options{FailureThreshold: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := s.Status(); len(status) != 2 || status[0].Weight != 3 || status[1].Weight != 1 {
+		t.Fatalf("重复地址应只保留第一个，未配置权重时为1: %+v", status)
+	}
+
+	counts := map[string]int{}
+	var sequence string
+	for i := 0; i < 8; i++ {
+		address, err := s.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[address]++
+		sequence += address
+	}
+	if counts["a"] != 6 || counts["b"] != 2 {
+		t.Errorf("应按3:1分配: %v", counts)
+	}
+	if sequence != "aabaaaba" {
+		t.Errorf("平滑轮询不应连续集中到同一服务器: %s", sequence)
+	}
+
+	if got, _ := s.PickExcept("a"); got != "b" {
+		t.Errorf("PickExcept应跳过指定服务器: %s", got)
+	}
+}
+
+func TestWeightedHalfOpen(t *testing.T) {
+	s, err := NewWeightedSet("test", []Endpoint{{Address: "a"}, {Address: "b"}}, Options{FailureThreshold: 2, BaseBackoff: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	failure := errors.New("connection refused")
+
+	s.Report("a", failure)
+	s.Report("a", failure)
+	for i := 0; i < 3; i++ {
+		if got, _ := s.Pick(); got != "b" {
+			t.Fatalf("断开的服务器在退避期间不应分到调用: %s", got)
+		}
+	}
+
+	// 退避到期后只分到一次试探调用
+	now = now.Add(time.Second)
+	if got, _ := s.Pick(); got != "a" {
+		t.Fatalf("退避到期后应分到试探调用: %s", got)
+	}
+	if got, _ := s.Pick(); got != "b" {
+		t.Errorf("试探调用结束前不应再分到调用: %s", got)
+	}
+
+	// 试探失败时退避翻倍，成功时恢复轮询
+	s.Report("a", failure)
+	if status := s.Status()[0]; status.Healthy || !status.RetryAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("试探失败后退避应翻倍: %+v", status)
+	}
+	now = now.Add(2 * time.Second)
+	if got, _ := s.Pick(); got != "a" {
+		t.Fatalf("退避到期后应再次试探: %s", got)
+	}
+	s.Report("a", nil)
+	counts := map[string]int{}
+	for i := 0; i < 4; i++ {
+		got, _ := s.Pick()
+		counts[got]++
+	}
+	if counts["a"] != 2 || counts["b"] != 2 {
+		t.Errorf("恢复后应重新参与轮询: %v", counts)
+	}
+}
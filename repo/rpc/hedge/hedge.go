@@ -0,0 +1,175 @@
+package hedge
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 常量定义
+const (
+	// latencyWindow 用于计算P95的延迟采样窗口大小
+	latencyWindow = 256
+	// minSamples 采样数不足时使用默认延迟
+	minSamples = 20
+	// defaultDelay 默认的对冲等待时间
+	defaultDelay = 100 * time.Millisecond
+	// minDelay 对冲等待时间下限，避免过早发出重复请求
+	minDelay = 5 * time.Millisecond
+)
+
+// Hedger 对冲请求控制器
+// 主请求超过P95延迟仍未返回时发出一个重复请求，取先返回的结果，重复请求数量受预算限制
+type Hedger struct {
+	mu         sync.Mutex
+	latencies  []time.Duration
+	next       int
+	fixedDelay time.Duration
+	budget     float64
+
+	total  int64
+	hedged int64
+}
+
+// Stats 对冲统计信息
+type Stats struct {
+	Total  int64         `json:"total"`
+	Hedged int64         `json:"hedged"`
+	Delay  time.Duration `json:"delay"`
+}
+
+// NewHedger 创建对冲控制器
+// fixedDelay为0时使用观测到的P95延迟，budgetPercent为允许对冲的请求占比
+func NewHedger(fixedDelay time.Duration, budgetPercent int) *Hedger {
+	if budgetPercent <= 0 {
+		budgetPercent = 10
+	}
+	if budgetPercent > 100 {
+		budgetPercent = 100
+	}
+	return &Hedger{
+		latencies:  make([]time.Duration, 0, latencyWindow),
+		fixedDelay: fixedDelay,
+		budget:     float64(budgetPercent) / 100,
+	}
+}
+
+// Observe 记录一次请求延迟
+func (h *Hedger) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < latencyWindow {
+		h.latencies = append(h.latencies, d)
+		return
+	}
+	h.latencies[h.next] = d
+	h.next = (h.next + 1) % latencyWindow
+}
+
+// Delay 返回当前的对冲等待时间
+func (h *Hedger) Delay() time.Duration {
+	if h.fixedDelay > 0 {
+		return h.fixedDelay
+	}
+
+	h.mu.Lock()
+	if len(h.latencies) < minSamples {
+		h.mu.Unlock()
+		return defaultDelay
+	}
+	samples := make([]time.Duration, len(h.latencies))
+	copy(samples, h.latencies)
+	h.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	p95 := samples[len(samples)*95/100]
+	if p95 < minDelay {
+		return minDelay
+	}
+	return p95
+}
+
+// Stats 获取对冲统计信息
+func (h *Hedger) Stats() Stats {
+	return Stats{
+		Total:  atomic.LoadInt64(&h.total),
+		Hedged: atomic.LoadInt64(&h.hedged),
+		Delay:  h.Delay(),
+	}
+}
+
+// allow 判断是否还有对冲预算
+func (h *Hedger) allow() bool {
+	total := atomic.LoadInt64(&h.total)
+	hedged := atomic.LoadInt64(&h.hedged)
+	if float64(hedged+1) > float64(total)*h.budget {
+		return false
+	}
+	atomic.AddInt64(&h.hedged, 1)
+	return true
+}
+
+type result[T any] struct {
+	value  T
+	err    error
+	hedged bool
+}
+
+// Do 执行可对冲的请求，fn必须是幂等的读操作
+// fn的hedged参数表示本次是否为对冲请求，调用方据此把对冲请求路由到主请求以外的服务器
+// 返回最先成功的结果；两个请求都失败时返回主请求的错误
+func Do[T any](ctx context.Context, h *Hedger, fn func(ctx context.Context, hedged bool) (T, error)) (T, error) {
+	if h == nil {
+		return fn(ctx, false)
+	}
+
+	atomic.AddInt64(&h.total, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result[T], 2)
+	start := time.Now()
+	launch := func(hedged bool) {
+		value, err := fn(ctx, hedged)
+		results <- result[T]{value: value, err: err, hedged: hedged}
+	}
+
+	go launch(false)
+
+	timer := time.NewTimer(h.Delay())
+	defer timer.Stop()
+
+	inflight := 1
+	var primaryErr *result[T]
+
+	for {
+		select {
+		case <-timer.C:
+			// 主请求超时未返回，在预算内发出对冲请求
+			if inflight == 1 && primaryErr == nil && h.allow() {
+				inflight++
+				go launch(true)
+			}
+		case res := <-results:
+			inflight--
+			if res.err == nil {
+				h.Observe(time.Since(start))
+				return res.value, nil
+			}
+			if !res.hedged {
+				primaryErr = &res
+			}
+			// 主请求总是先发出，两个请求都结束时主请求的错误已记录
+			if inflight == 0 {
+				return primaryErr.value, primaryErr.err
+			}
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelayUsesP95(t *testing.T) {
+	h := NewHedger(0, 10)
+	if got := h.Delay(); got != defaultDelay {
+		t.Fatalf("采样不足时应使用默认延迟: %v", got)
+	}
+
+	// 1ms到100ms各一个采样，P95落在第96个采样上
+	for i := 1; i <= 100; i++ {
+		h.Observe(time.Duration(i) * time.Millisecond)
+	}
+	if got := h.Delay(); got != 96*time.Millisecond {
+		t.Errorf("P95延迟 = %v", got)
+	}
+
+	// 延迟都很小时不低于下限
+	fast := NewHedger(0, 10)
+	for i := 0; i < minSamples; i++ {
+		fast.Observe(time.Microsecond)
+	}
+	if got := fast.Delay(); got != minDelay {
+		t.Errorf("延迟下限 = %v", got)
+	}
+
+	if got := NewHedger(30*time.Millisecond, 10).Delay(); got != 30*time.Millisecond {
+		t.Errorf("固定延迟 = %v", got)
+	}
+}
+
+func TestBudgetExhausted(t *testing.T) {
+	// 预算为10%，前9个请求都不允许对冲，第10个请求用掉唯一的名额
+	h := NewHedger(time.Millisecond, 10)
+	slow := func(ctx context.Context, hedged bool) (bool, error) {
+		time.Sleep(5 * time.Millisecond)
+		return hedged, nil
+	}
+	for i := 1; i <= 10; i++ {
+		if _, err := Do(context.Background(), h, slow); err != nil {
+			t.Fatal(err)
+		}
+		want := int64(0)
+		if i == 10 {
+			want = 1
+		}
+		if stats := h.Stats(); stats.Total != int64(i) || stats.Hedged != want {
+			t.Fatalf("第%d个请求后统计 = %+v", i, stats)
+		}
+	}
+	if _, err := Do(context.Background(), h, slow); err != nil {
+		t.Fatal(err)
+	}
+	if stats := h.Stats(); stats.Hedged != 1 {
+		t.Errorf("预算用完后不应再对冲: %+v", stats)
+	}
+}
+
+func TestFirstResponseWins(t *testing.T) {
+	h := NewHedger(time.Millisecond, 100)
+	primaryDone := make(chan struct{})
+	value, err := Do(context.Background(), h, func(ctx context.Context, hedged bool) (string, error) {
+		if hedged {
+			return "hedge", nil
+		}
+		defer close(primaryDone)
+		<-ctx.Done()
+		return "primary", ctx.Err()
+	})
+	if err != nil || value != "hedge" {
+		t.Fatalf("应返回先完成的对冲请求: %q %v", value, err)
+	}
+	// 返回后取消仍在进行的主请求
+	select {
+	case <-primaryDone:
+	case <-time.After(time.Second):
+		t.Fatal("主请求未被取消")
+	}
+}
+
+func TestBothFailReturnsPrimaryError(t *testing.T) {
+	h := NewHedger(time.Millisecond, 100)
+	errPrimary := errors.New("primary")
+	_, err := Do(context.Background(), h, func(ctx context.Context, hedged bool) (int, error) {
+		if hedged {
+			return 0, errors.New("hedge")
+		}
+		time.Sleep(20 * time.Millisecond)
+		return 0, errPrimary
+	})
+	if !errors.Is(err, errPrimary) {
+		t.Errorf("两个请求都失败时应返回主请求的错误: %v", err)
+	}
+}
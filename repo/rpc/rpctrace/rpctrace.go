@@ -0,0 +1,125 @@
+package rpctrace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"ginproject/entity/config"
+	tracemw "ginproject/middleware/trace"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 内置的trace上下文传播方式
+const (
+	// ModeLog 不改动上游请求，只通过旁路日志和最近调用记录关联，默认方式
+	ModeLog = "log"
+	// ModeID 将trace和span ID编码进节点JSON-RPC请求ID，节点开启RPC调试日志时可以直接按trace检索
+	ModeID = "id"
+	// ModeHeader 将trace和span ID写入节点HTTP请求头，适用于节点前有记录请求头的代理
+	ModeHeader = "header"
+)
+
+// W3C Trace Context请求头
+const traceparentHeader = "traceparent"
+
+// Propagator 决定trace上下文如何带入节点RPC请求
+// ElectrumX的请求ID为整数，无法携带trace，只通过旁路记录关联
+type Propagator interface {
+	// RequestID 返回实际发送的JSON-RPC请求ID，id为不携带trace时的请求ID
+	RequestID(id string, sc trace.SpanContext) string
+	// Header 向节点HTTP请求头写入trace上下文
+	Header(h http.Header, sc trace.SpanContext)
+}
+
+var (
+	mu          sync.RWMutex
+	propagators = map[string]Propagator{
+		ModeLog:    logPropagator{},
+		ModeID:     idPropagator{},
+		ModeHeader: headerPropagator{},
+	}
+	// 当前使用的传播方式
+	current Propagator = logPropagator{}
+)
+
+// Register 注册自定义的传播方式，需在Init之前调用，同名时覆盖内置方式
+func Register(name string, p Propagator) {
+	mu.Lock()
+	defer mu.Unlock()
+	propagators[name] = p
+}
+
+// Init 根据配置选择传播方式并设置最近调用记录的容量，未知的传播方式返回错误并保持默认方式
+func Init(cfg *config.TraceConfig) error {
+	resize(cfg.RecentCalls)
+	setSlowThreshold(cfg.SlowThreshold)
+
+	name := cfg.Propagation
+	if name == "" {
+		name = ModeLog
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	p, ok := propagators[name]
+	if !ok {
+		current = logPropagator{}
+		return fmt.Errorf("未知的trace传播方式: %s", name)
+	}
+	current = p
+	return nil
+}
+
+// RequestID 返回携带ctx中trace上下文的节点请求ID，ctx中没有有效的trace时原样返回
+func RequestID(ctx context.Context, id string) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return id
+	}
+	return propagator().RequestID(id, sc)
+}
+
+// InjectHeader 将ctx中的trace上下文按当前传播方式写入节点HTTP请求头
+func InjectHeader(ctx context.Context, h http.Header) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	propagator().Header(h, sc)
+}
+
+// propagator 返回当前使用的传播方式
+func propagator() Propagator {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// logPropagator 不改动请求
+type logPropagator struct{}
+
+func (logPropagator) RequestID(id string, _ trace.SpanContext) string { return id }
+
+func (logPropagator) Header(http.Header, trace.SpanContext) {}
+
+// idPropagator 请求ID形如blockchain_client@<trace_id>-<span_id>，节点原样返回请求ID
+type idPropagator struct{}
+
+func (idPropagator) RequestID(id string, sc trace.SpanContext) string {
+	return id + "@" + sc.TraceID().String() + "-" + sc.SpanID().String()
+}
+
+func (idPropagator) Header(http.Header, trace.SpanContext) {}
+
+// headerPropagator 写入与API响应头相同的X-Trace-ID和X-Span-ID，并附带W3C traceparent
+type headerPropagator struct{}
+
+func (headerPropagator) RequestID(id string, _ trace.SpanContext) string { return id }
+
+func (headerPropagator) Header(h http.Header, sc trace.SpanContext) {
+	h.Set(tracemw.TraceIDHeader, sc.TraceID().String())
+	h.Set(tracemw.SpanIDHeader, sc.SpanID().String())
+	h.Set(traceparentHeader, fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags()))
+}
@@ -0,0 +1,107 @@
+package rpctrace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"ginproject/entity/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedContext 返回带有固定trace和span ID的上下文
+func tracedContext(t *testing.T, traceHex, spanHex string) context.Context {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex(traceHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(spanHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestPropagation(t *testing.T) {
+	const traceHex, spanHex = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	ctx := tracedContext(t, traceHex, spanHex)
+	defer Init(&config.TraceConfig{})
+
+	if err := Init(&config.TraceConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := RequestID(ctx, "blockchain_client"); got != "blockchain_client" {
+		t.Errorf("默认方式不应改动请求ID: %s", got)
+	}
+
+	if err := Init(&config.TraceConfig{Propagation: ModeID}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := RequestID(ctx, "blockchain_client"), "blockchain_client@"+traceHex+"-"+spanHex; got != want {
+		t.Errorf("RequestID = %s, want %s", got, want)
+	}
+	if got := RequestID(context.Background(), "blockchain_client"); got != "blockchain_client" {
+		t.Errorf("没有trace时不应改动请求ID: %s", got)
+	}
+
+	if err := Init(&config.TraceConfig{Propagation: ModeHeader}); err != nil {
+		t.Fatal(err)
+	}
+	h := http.Header{}
+	InjectHeader(ctx, h)
+	if got, want := h.Get(traceparentHeader), "00-"+traceHex+"-"+spanHex+"-01"; got != want {
+		t.Errorf("traceparent = %s, want %s", got, want)
+	}
+	if h.Get("X-Trace-ID") != traceHex {
+		t.Errorf("X-Trace-ID = %s", h.Get("X-Trace-ID"))
+	}
+
+	if err := Init(&config.TraceConfig{Propagation: "baggage"}); err == nil {
+		t.Error("未知的传播方式应返回错误")
+	}
+	if got := RequestID(ctx, "blockchain_client"); got != "blockchain_client" {
+		t.Errorf("未知的传播方式应退回默认方式: %s", got)
+	}
+}
+
+func TestRecordCorrelatesByTrace(t *testing.T) {
+	if err := Init(&config.TraceConfig{RecentCalls: 3, SlowThreshold: 100}); err != nil {
+		t.Fatal(err)
+	}
+	defer Init(&config.TraceConfig{})
+
+	ctx := tracedContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	other := tracedContext(t, "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331")
+	now := time.Now()
+
+	Record(other, UpstreamNode, "getblock", "blockchain_client", now.Add(-10*time.Millisecond), nil)
+	Record(ctx, UpstreamElectrumX, "blockchain.scripthash.get_history", "7", now.Add(-300*time.Millisecond), nil)
+	Record(ctx, UpstreamNode, "getrawtransaction", "blockchain_client", now.Add(-20*time.Millisecond), errors.New("timeout"))
+
+	calls := CallsByTrace("4bf92f3577b34da6a3ce929d0e0e4736")
+	if len(calls) != 2 {
+		t.Fatalf("关联到%d次调用, want 2", len(calls))
+	}
+	if calls[0].Method != "blockchain.scripthash.get_history" || calls[1].Error != "timeout" {
+		t.Errorf("调用顺序或内容错误: %+v", calls)
+	}
+
+	slow := SlowCalls(10)
+	if len(slow) != 1 || slow[0].Upstream != UpstreamElectrumX || slow[0].SpanID != "00f067aa0ba902b7" {
+		t.Errorf("慢调用错误: %+v", slow)
+	}
+
+	// 超出容量后覆盖最早的记录
+	Record(ctx, UpstreamNode, "getblockcount", "blockchain_client", now, nil)
+	if calls := CallsByTrace("0af7651916cd43dd8448eb211c80319c"); len(calls) != 0 {
+		t.Errorf("最早的记录应被覆盖: %+v", calls)
+	}
+	if calls := CallsByTrace("4bf92f3577b34da6a3ce929d0e0e4736"); len(calls) != 3 {
+		t.Errorf("关联到%d次调用, want 3", len(calls))
+	}
+}
@@ -0,0 +1,190 @@
+package rpctrace
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+
+	"ginproject/middleware/log"
+	tracemw "ginproject/middleware/trace"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 上游名称
+const (
+	UpstreamNode      = "node"
+	UpstreamElectrumX = "electrumx"
+)
+
+// 未配置时的默认值
+const (
+	defaultRecentCalls   = 1024
+	defaultSlowThreshold = 500 * time.Millisecond
+)
+
+// Call 一次上游调用的记录
+type Call struct {
+	Upstream   string    `json:"upstream"`
+	Method     string    `json:"method"`
+	RequestID  string    `json:"request_id"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	SpanID     string    `json:"span_id,omitempty"`
+	Start      time.Time `json:"start"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// SlowRequest 路由指标中记录的慢请求及同一trace中的上游调用
+type SlowRequest struct {
+	Method     string  `json:"method"`
+	Route      string  `json:"route"`
+	TraceID    string  `json:"trace_id"`
+	DurationMs float64 `json:"duration_ms"`
+	Upstream   []Call  `json:"upstream"` // 已超出最近调用记录的容量时为空
+}
+
+// recent 固定容量的最近调用记录，写满后覆盖最早的记录
+var recent = struct {
+	sync.Mutex
+	calls []Call
+	next  int
+	full  bool
+	slow  time.Duration
+}{calls: make([]Call, defaultRecentCalls), slow: defaultSlowThreshold}
+
+func init() {
+	expvar.Publish("upstream_slowlog", expvar.Func(func() any {
+		return SlowCalls(20)
+	}))
+}
+
+// resize 设置最近调用记录的容量，已有记录清空
+func resize(size int) {
+	if size <= 0 {
+		size = defaultRecentCalls
+	}
+	recent.Lock()
+	defer recent.Unlock()
+	recent.calls = make([]Call, size)
+	recent.next = 0
+	recent.full = false
+}
+
+// setSlowThreshold 设置慢调用阈值(毫秒)
+func setSlowThreshold(ms int) {
+	threshold := defaultSlowThreshold
+	if ms > 0 {
+		threshold = time.Duration(ms) * time.Millisecond
+	}
+	recent.Lock()
+	recent.slow = threshold
+	recent.Unlock()
+}
+
+// Record 记录一次上游调用，日志带有ctx中的trace字段，超过阈值的调用以警告级别记录
+// 调用同时保存到最近调用记录，慢请求日志按trace ID关联
+func Record(ctx context.Context, upstream, method, requestID string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	call := Call{
+		Upstream:   upstream,
+		Method:     method,
+		RequestID:  requestID,
+		Start:      start,
+		DurationMs: float64(elapsed.Microseconds()) / 1000,
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		call.TraceID = sc.TraceID().String()
+		call.SpanID = sc.SpanID().String()
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	recent.Lock()
+	recent.calls[recent.next] = call
+	recent.next++
+	if recent.next == len(recent.calls) {
+		recent.next = 0
+		recent.full = true
+	}
+	slow := recent.slow
+	recent.Unlock()
+
+	if elapsed >= slow {
+		log.WarnWithContext(ctx, "上游慢调用", "upstream:", upstream, "method:", method, "id:", requestID, "耗时:", elapsed, "错误:", err)
+		return
+	}
+	log.DebugWithContext(ctx, "上游调用完成", "upstream:", upstream, "method:", method, "id:", requestID, "耗时:", elapsed)
+}
+
+// snapshot 复制当前保存的所有调用记录
+func snapshot() []Call {
+	recent.Lock()
+	defer recent.Unlock()
+	if !recent.full {
+		return append([]Call(nil), recent.calls[:recent.next]...)
+	}
+	return append(append([]Call(nil), recent.calls[recent.next:]...), recent.calls[:recent.next]...)
+}
+
+// CallsByTrace 返回最近调用中属于traceID的上游调用，按开始时间排序
+func CallsByTrace(traceID string) []Call {
+	calls := []Call{}
+	for _, call := range snapshot() {
+		if call.TraceID == traceID {
+			calls = append(calls, call)
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Start.Before(calls[j].Start) })
+	return calls
+}
+
+// SlowCalls 返回最近调用中超过阈值的慢调用，最慢的在前
+func SlowCalls(limit int) []Call {
+	recent.Lock()
+	slow := recent.slow
+	recent.Unlock()
+
+	calls := []Call{}
+	for _, call := range snapshot() {
+		if call.DurationMs >= float64(slow.Microseconds())/1000 {
+			calls = append(calls, call)
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].DurationMs > calls[j].DurationMs })
+	if limit > 0 && len(calls) > limit {
+		calls = calls[:limit]
+	}
+	return calls
+}
+
+// SlowLog 按路由指标的exemplar列出最慢的请求，并关联同一trace中的上游调用，可以定位慢请求具体等待了哪次上游调用
+func SlowLog(ctx context.Context, limit int) ([]SlowRequest, error) {
+	metrics, err := tracemw.CollectRouteMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []SlowRequest{}
+	for _, route := range metrics {
+		for _, ex := range route.Exemplars {
+			requests = append(requests, SlowRequest{
+				Method:     route.Method,
+				Route:      route.Route,
+				TraceID:    ex.TraceID,
+				DurationMs: ex.DurationMs,
+			})
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].DurationMs > requests[j].DurationMs })
+	if limit > 0 && len(requests) > limit {
+		requests = requests[:limit]
+	}
+	for i := range requests {
+		requests[i].Upstream = CallsByTrace(requests[i].TraceID)
+	}
+	return requests, nil
+}
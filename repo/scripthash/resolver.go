@@ -0,0 +1,217 @@
+package scripthash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ginproject/entity/config"
+	"ginproject/entity/dbtable"
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/db"
+	"ginproject/repo/db/script_hash_dao"
+)
+
+// Kind 脚本类型，同一地址在不同脚本下对应不同的脚本哈希
+type Kind string
+
+const (
+	KindP2PKH         Kind = "p2pkh"          // 普通地址脚本
+	KindNftHolder     Kind = "nft"            // NFT持有脚本
+	KindNftCollection Kind = "nft_collection" // NFT集合持有脚本
+	KindMultiSig      Kind = "multisig"       // 多签钱包索引脚本
+	KindFtHolder      Kind = "ft"             // FT持有脚本，与合约ID相关
+)
+
+const (
+	// 默认缓存的映射数量
+	defaultCacheSize = 100000
+	// 等待写入数据库的映射队列长度，队列满时丢弃
+	persistQueueSize = 1024
+	// 单批写入数据库的最大映射数量
+	persistBatchSize = 200
+	// 写入数据库的最大间隔
+	persistFlushInterval = time.Second
+	// 单批写入数据库的超时时间
+	persistTimeout = 5 * time.Second
+)
+
+// ErrUnsupportedKind 不支持直接计算的脚本类型
+var ErrUnsupportedKind = errors.New("不支持的脚本类型")
+
+// Mapping 脚本哈希对应的地址信息
+type Mapping struct {
+	Address   string `json:"address"`
+	Kind      Kind   `json:"kind"`
+	Qualifier string `json:"qualifier,omitempty"` // FT脚本为合约ID
+}
+
+type key struct {
+	kind      Kind
+	address   string
+	qualifier string
+}
+
+// Resolver 地址与脚本哈希的双向解析器，正向结果只依赖地址，计算后常驻内存缓存
+type Resolver struct {
+	forward *cache.LRU[key, string]
+	reverse *cache.LRU[string, Mapping]
+	persist bool
+	pending chan *dbtable.AddressScriptHash
+	once    sync.Once
+}
+
+// NewResolver 创建解析器，persist为true时映射异步写入数据库
+func NewResolver(cacheSize int, persist bool) *Resolver {
+	if cacheSize <= 0 {
+		cacheSize = defaultCacheSize
+	}
+	return &Resolver{
+		forward: cache.NewLRU[key, string](cacheSize, 0),
+		reverse: cache.NewLRU[string, Mapping](cacheSize, 0),
+		persist: persist,
+		pending: make(chan *dbtable.AddressScriptHash, persistQueueSize),
+	}
+}
+
+var (
+	defaultResolver     *Resolver
+	defaultResolverOnce sync.Once
+)
+
+// Default 返回按配置创建的全局解析器
+func Default() *Resolver {
+	defaultResolverOnce.Do(func() {
+		cfg := config.GetConfig().GetScriptHashConfig()
+		defaultResolver = NewResolver(cfg.CacheSize, cfg.Persist)
+		cache.Register("scripthash_forward", defaultResolver.forward)
+		cache.Register("scripthash_reverse", defaultResolver.reverse)
+	})
+	return defaultResolver
+}
+
+// Resolve 使用全局解析器获取地址在指定脚本类型下的脚本哈希
+func Resolve(ctx context.Context, kind Kind, address string) (string, error) {
+	return Default().Resolve(ctx, kind, address)
+}
+
+// ResolveFt 使用全局解析器获取地址持有指定FT的脚本哈希
+func ResolveFt(ctx context.Context, address, contractId string, compute func() (string, error)) (string, error) {
+	return Default().ResolveFt(ctx, address, contractId, compute)
+}
+
+// AddressOf 使用全局解析器按脚本哈希反查地址
+func AddressOf(ctx context.Context, scriptHash string) (Mapping, error) {
+	return Default().AddressOf(ctx, scriptHash)
+}
+
+// Resolve 获取地址在指定脚本类型下的脚本哈希，FT脚本需要代码脚本，使用ResolveFt
+func (r *Resolver) Resolve(ctx context.Context, kind Kind, address string) (string, error) {
+	var compute func() (string, error)
+	switch kind {
+	case KindP2PKH:
+		compute = func() (string, error) { return utility.AddressToScriptHash(address) }
+	case KindNftHolder:
+		compute = func() (string, error) { return utility.ConvertAddressToNftScriptHash(address, false) }
+	case KindNftCollection:
+		compute = func() (string, error) { return utility.ConvertAddressToNftScriptHash(address, true) }
+	case KindMultiSig:
+		compute = func() (string, error) { return utility.ConvertAddressToMultiSigScriptHash(address) }
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
+	}
+	return r.resolve(ctx, key{kind: kind, address: address}, compute)
+}
+
+// ResolveFt 获取地址持有指定FT的脚本哈希，缓存未命中时调用compute计算
+func (r *Resolver) ResolveFt(ctx context.Context, address, contractId string, compute func() (string, error)) (string, error) {
+	return r.resolve(ctx, key{kind: KindFtHolder, address: address, qualifier: contractId}, compute)
+}
+
+// AddressOf 按脚本哈希反查地址，内存中没有时查询数据库，未开启持久化时只能查到本进程解析过的映射
+func (r *Resolver) AddressOf(ctx context.Context, scriptHash string) (Mapping, error) {
+	if mapping, ok := r.reverse.Get(scriptHash); ok {
+		return mapping, nil
+	}
+	if !r.persist {
+		return Mapping{}, db.ErrScriptHashNotFound
+	}
+
+	record, err := script_hash_dao.GetAddressScriptHash(ctx, scriptHash)
+	if err != nil {
+		return Mapping{}, err
+	}
+	mapping := Mapping{Address: record.Address, Kind: Kind(record.Kind), Qualifier: record.Qualifier}
+	r.reverse.Set(scriptHash, mapping)
+	return mapping, nil
+}
+
+// resolve 优先读取缓存，未命中时计算并记录双向映射
+func (r *Resolver) resolve(ctx context.Context, k key, compute func() (string, error)) (string, error) {
+	if scriptHash, ok := r.forward.Get(k); ok {
+		return scriptHash, nil
+	}
+
+	scriptHash, err := compute()
+	if err != nil {
+		return "", err
+	}
+
+	r.forward.Set(k, scriptHash)
+	r.reverse.Set(scriptHash, Mapping{Address: k.address, Kind: k.kind, Qualifier: k.qualifier})
+	if r.persist {
+		r.enqueue(ctx, &dbtable.AddressScriptHash{
+			ScriptHash: scriptHash,
+			Address:    k.address,
+			Kind:       string(k.kind),
+			Qualifier:  k.qualifier,
+		})
+	}
+	return scriptHash, nil
+}
+
+// enqueue 将映射加入写入队列，首次调用时启动后台写入协程
+func (r *Resolver) enqueue(ctx context.Context, mapping *dbtable.AddressScriptHash) {
+	r.once.Do(func() { go r.runPersister(context.WithoutCancel(ctx)) })
+
+	select {
+	case r.pending <- mapping:
+	default:
+		log.WarnWithContext(ctx, "地址脚本哈希映射写入队列已满，丢弃映射", "scriptHash:", mapping.ScriptHash)
+	}
+}
+
+// runPersister 按批量或时间间隔将队列中的映射写入数据库
+func (r *Resolver) runPersister(ctx context.Context) {
+	ticker := time.NewTicker(persistFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*dbtable.AddressScriptHash, 0, persistBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		flushCtx, cancel := context.WithTimeout(ctx, persistTimeout)
+		defer cancel()
+		if err := script_hash_dao.InsertAddressScriptHashes(flushCtx, batch); err != nil {
+			log.WarnWithContext(ctx, "写入地址脚本哈希映射失败", "数量:", len(batch), "错误:", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case mapping := <-r.pending:
+			batch = append(batch, mapping)
+			if len(batch) >= persistBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package scripthash
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ginproject/entity/utility"
+	"ginproject/repo/db"
+)
+
+const testAddress = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	r := NewResolver(16, false)
+
+	for _, kind := range []Kind{KindP2PKH, KindNftHolder, KindNftCollection, KindMultiSig} {
+		got, err := r.Resolve(ctx, kind, testAddress)
+		if err != nil {
+			t.Fatalf("%s 解析失败: %v", kind, err)
+		}
+		mapping, err := r.AddressOf(ctx, got)
+		if err != nil || mapping.Address != testAddress || mapping.Kind != kind {
+			t.Fatalf("%s 反查结果: %+v, %v", kind, mapping, err)
+		}
+	}
+
+	want, _ := utility.AddressToScriptHash(testAddress)
+	if got, _ := r.Resolve(ctx, KindP2PKH, testAddress); got != want {
+		t.Fatalf("P2PKH脚本哈希不一致: %s, 期望 %s", got, want)
+	}
+	if _, err := r.Resolve(ctx, KindP2PKH, "invalid"); err == nil {
+		t.Fatal("无效地址应返回错误")
+	}
+	if _, err := r.Resolve(ctx, KindFtHolder, testAddress); !errors.Is(err, ErrUnsupportedKind) {
+		t.Fatalf("FT脚本应通过ResolveFt解析，实际: %v", err)
+	}
+	if _, err := r.AddressOf(ctx, "unknown"); !errors.Is(err, db.ErrScriptHashNotFound) {
+		t.Fatalf("未解析过的脚本哈希应返回未找到，实际: %v", err)
+	}
+}
+
+func TestResolveFtCachesPerContract(t *testing.T) {
+	ctx := context.Background()
+	r := NewResolver(16, false)
+
+	calls := 0
+	compute := func(hash string) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return hash, nil
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if got, err := r.ResolveFt(ctx, testAddress, "contract-a", compute("aa")); err != nil || got != "aa" {
+			t.Fatalf("解析结果: %s, %v", got, err)
+		}
+	}
+	if got, _ := r.ResolveFt(ctx, testAddress, "contract-b", compute("bb")); got != "bb" {
+		t.Fatalf("不同合约应分别计算，实际: %s", got)
+	}
+	if calls != 2 {
+		t.Fatalf("期望计算2次，实际: %d", calls)
+	}
+
+	mapping, err := r.AddressOf(ctx, "bb")
+	if err != nil || mapping.Kind != KindFtHolder || mapping.Qualifier != "contract-b" {
+		t.Fatalf("反查结果: %+v, %v", mapping, err)
+	}
+}
@@ -0,0 +1,358 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3请求签名使用的算法和服务名
+const (
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	s3Service   = "s3"
+)
+
+// 错误响应体最多读取的字节数
+const s3ErrorBodyLimit = 512
+
+// S3Options S3兼容对象存储的连接参数
+type S3Options struct {
+	Endpoint  string // 服务地址，如https://s3.us-east-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string // 对象键前缀
+	PathStyle bool   // 使用路径风格的地址(endpoint/bucket/key)，否则使用bucket.endpoint/key
+	Client    *http.Client
+}
+
+// S3Store 基于S3兼容服务的对象存储，多个实例共享同一个存储桶
+// 写入内容先缓存到本地临时文件，Close时一次性上传，对象在上传完成后才可见
+type S3Store struct {
+	opts   S3Options
+	base   *url.URL
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store 创建S3兼容对象存储
+func NewS3Store(opts S3Options) (*S3Store, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" || opts.Region == "" {
+		return nil, errors.New("S3存储需要配置服务地址、区域和存储桶")
+	}
+	base, err := url.Parse(opts.Endpoint)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("无效的S3服务地址: %q", opts.Endpoint)
+	}
+	if opts.PathStyle {
+		base.Path = strings.TrimSuffix(base.Path, "/") + "/" + opts.Bucket
+	} else {
+		base.Host = opts.Bucket + "." + base.Host
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3Store{opts: opts, base: base, client: client, now: time.Now}, nil
+}
+
+// objectURL 返回对象键对应的地址，拒绝空键和包含路径分隔符的键
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return nil, fmt.Errorf("无效的对象键: %q", key)
+	}
+	u := *s.base
+	base := strings.TrimSuffix(u.EscapedPath(), "/")
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.opts.Prefix + key
+	u.RawPath = base + "/" + s3Escape(s.opts.Prefix+key)
+	return &u, nil
+}
+
+// s3Escape 按签名要求编码对象路径，只保留非保留字符和路径分隔符
+func s3Escape(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// Create 创建对象，写入内容在Close时上传
+func (s *S3Store) Create(ctx context.Context, key string) (Writer, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp("", "s3-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建对象失败: %w", err)
+	}
+	return &s3Writer{store: s, ctx: ctx, url: u, file: file, hash: sha256.New()}, nil
+}
+
+// Open 读取对象元数据并返回按需发起Range请求的对象
+func (s *S3Store) Open(ctx context.Context, key string) (Object, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, u, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return nil, fmt.Errorf("打开对象失败: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("打开对象失败: %s", resp.Status)
+	}
+
+	info := ObjectInfo{Key: key, Size: resp.ContentLength}
+	if info.Size < 0 {
+		return nil, errors.New("打开对象失败: 响应缺少对象大小")
+	}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return &s3Object{store: s, ctx: ctx, url: u, info: info}, nil
+}
+
+// Delete 删除对象
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, u, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("删除对象失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("删除对象失败: %w", s3ResponseError(resp))
+	}
+	return nil
+}
+
+// do 签名并发送请求
+func (s *S3Store) do(ctx context.Context, method string, u *url.URL, header http.Header, body io.Reader, payloadHash string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.sign(req, payloadHash, s.now())
+	return s.client.Do(req)
+}
+
+// emptyPayloadHash 空请求体的SHA256
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sign 按AWS Signature Version 4为请求添加Authorization请求头，签名覆盖Host和请求中已设置的全部请求头
+func (s *S3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.opts.Region + "/" + s3Service + "/aws4_request"
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", s3Algorithm+" Credential="+s.opts.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3ResponseError 将非成功响应转换为错误，附带响应体开头的错误说明
+func s3ResponseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyLimit))
+	return fmt.Errorf("S3请求失败: %s %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// s3Writer 写入本地临时文件并同时计算内容的SHA256，Close时上传
+type s3Writer struct {
+	store *S3Store
+	ctx   context.Context
+	url   *url.URL
+	file  *os.File
+	hash  hash.Hash
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.hash.Write(p[:n])
+	return n, err
+}
+
+// Close 上传临时文件的内容，无论成功与否都删除临时文件
+func (w *s3Writer) Close() error {
+	defer os.Remove(w.file.Name())
+	defer w.file.Close()
+
+	size, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("写入对象失败: %w", err)
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("写入对象失败: %w", err)
+	}
+	payloadHash := hex.EncodeToString(w.hash.Sum(nil))
+	body := io.NopCloser(io.LimitReader(w.file, size))
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPut, w.url.String(), body)
+	if err != nil {
+		return fmt.Errorf("提交对象失败: %w", err)
+	}
+	req.ContentLength = size
+	w.store.sign(req, payloadHash, w.store.now())
+	resp, err := w.store.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("提交对象失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("提交对象失败: %w", s3ResponseError(resp))
+	}
+	return nil
+}
+
+// Abort 关闭并删除临时文件
+func (w *s3Writer) Abort() error {
+	w.file.Close()
+	if err := os.Remove(w.file.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("清理临时文件失败: %w", err)
+	}
+	return nil
+}
+
+// s3Object 从当前位置开始读取时发起Range请求，Seek后丢弃进行中的响应
+type s3Object struct {
+	store  *S3Store
+	ctx    context.Context
+	url    *url.URL
+	info   ObjectInfo
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *s3Object) Info() ObjectInfo {
+	return o.info
+}
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		header := http.Header{"Range": {"bytes=" + strconv.FormatInt(o.offset, 10) + "-"}}
+		resp, err := o.store.do(o.ctx, http.MethodGet, o.url, header, nil, emptyPayloadHash)
+		if err != nil {
+			return 0, fmt.Errorf("读取对象失败: %w", err)
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			defer resp.Body.Close()
+			return 0, fmt.Errorf("读取对象失败: %w", s3ResponseError(resp))
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	if errors.Is(err, io.EOF) && o.offset < o.info.Size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = o.offset + offset
+	case io.SeekEnd:
+		target = o.info.Size + offset
+	default:
+		return o.offset, errors.New("无效的whence")
+	}
+	if target < 0 {
+		return o.offset, errors.New("无效的偏移量")
+	}
+	if target != o.offset {
+		o.closeBody()
+		o.offset = target
+	}
+	return target, nil
+}
+
+func (o *s3Object) Close() error {
+	o.closeBody()
+	return nil
+}
+
+func (o *s3Object) closeBody() {
+	if o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+}
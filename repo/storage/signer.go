@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrSignatureExpired 下载链接已过期
+	ErrSignatureExpired = errors.New("下载链接已过期")
+	// ErrSignatureInvalid 下载链接签名无效
+	ErrSignatureInvalid = errors.New("下载链接签名无效")
+)
+
+// Signer 为对象键生成和校验带过期时间的签名
+type Signer struct {
+	key []byte
+}
+
+// NewSigner 创建签名器，key为空时随机生成，此时签名只在当前进程内有效
+func NewSigner(key string) *Signer {
+	if key != "" {
+		return &Signer{key: []byte(key)}
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		panic("生成签名密钥失败: " + err.Error())
+	}
+	return &Signer{key: random}
+}
+
+// Sign 返回对象键在expires之前有效的签名
+func (s *Signer) Sign(objectKey string, expires time.Time) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(objectKey))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名，expires为Unix时间戳
+func (s *Signer) Verify(objectKey string, expires int64, signature string, now time.Time) error {
+	expected := s.Sign(objectKey, time.Unix(expires, 0))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureInvalid
+	}
+	if now.Unix() > expires {
+		return ErrSignatureExpired
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrObjectNotFound 对象不存在
+var ErrObjectNotFound = errors.New("对象不存在")
+
+// ObjectInfo 对象元数据
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Object 可随机读取的对象，用于支持按Range断点续传
+type Object interface {
+	io.ReadSeekCloser
+	Info() ObjectInfo
+}
+
+// Writer 对象写入器，Close成功后对象才对读取可见，Abort放弃已写入的内容
+type Writer interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// ObjectStore 对象存储
+type ObjectStore interface {
+	// Create 创建对象并返回写入器
+	Create(ctx context.Context, key string) (Writer, error)
+	// Open 打开对象用于读取，对象不存在时返回ErrObjectNotFound
+	Open(ctx context.Context, key string) (Object, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStore 基于本地目录的对象存储
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore 创建本地目录对象存储，目录不存在时自动创建
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path 返回对象键对应的文件路径，拒绝包含路径分隔符的键
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", fmt.Errorf("无效的对象键: %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// Create 先写入临时文件，关闭时再重命名，避免读取到写了一半的对象
+func (s *LocalStore) Create(ctx context.Context, key string) (Writer, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(s.dir, "."+key+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建对象失败: %w", err)
+	}
+	return &localWriter{File: file, target: path}, nil
+}
+
+// Open 打开对象用于读取
+func (s *LocalStore) Open(ctx context.Context, key string) (Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开对象失败: %w", err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("读取对象信息失败: %w", err)
+	}
+	return &localObject{File: file, info: ObjectInfo{Key: key, Size: stat.Size(), ModTime: stat.ModTime()}}, nil
+}
+
+// Delete 删除对象
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除对象失败: %w", err)
+	}
+	return nil
+}
+
+type localWriter struct {
+	*os.File
+	target string
+}
+
+// Close 关闭临时文件并重命名为目标文件，失败时清理临时文件
+func (w *localWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.File.Name())
+		return fmt.Errorf("写入对象失败: %w", err)
+	}
+	if err := os.Rename(w.File.Name(), w.target); err != nil {
+		os.Remove(w.File.Name())
+		return fmt.Errorf("提交对象失败: %w", err)
+	}
+	return nil
+}
+
+// Abort 关闭并删除临时文件
+func (w *localWriter) Abort() error {
+	w.File.Close()
+	if err := os.Remove(w.File.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("清理临时文件失败: %w", err)
+	}
+	return nil
+}
+
+type localObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+func (o *localObject) Info() ObjectInfo {
+	return o.info
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	ctx := context.Background()
+
+	w, err := store.Create(ctx, "a.ndjson")
+	if err != nil {
+		t.Fatalf("创建对象失败: %v", err)
+	}
+	w.Write([]byte("hello"))
+	if _, err := store.Open(ctx, "a.ndjson"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("写入完成前对象不应可见，实际: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("提交对象失败: %v", err)
+	}
+
+	obj, err := store.Open(ctx, "a.ndjson")
+	if err != nil {
+		t.Fatalf("打开对象失败: %v", err)
+	}
+	obj.Seek(2, io.SeekStart)
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	if string(data) != "llo" || obj.Info().Size != 5 {
+		t.Fatalf("对象内容不一致: %q, %+v", data, obj.Info())
+	}
+
+	aborted, _ := store.Create(ctx, "b.csv")
+	aborted.Write([]byte("partial"))
+	aborted.Abort()
+	if _, err := store.Open(ctx, "b.csv"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("放弃写入的对象不应存在，实际: %v", err)
+	}
+
+	if _, err := store.Create(ctx, "../escape"); err == nil {
+		t.Fatal("包含路径分隔符的键应被拒绝")
+	}
+	if err := store.Delete(ctx, "a.ndjson"); err != nil {
+		t.Fatalf("删除对象失败: %v", err)
+	}
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner("secret")
+	now := time.Unix(1700000000, 0)
+	expires := now.Add(time.Hour)
+	signature := signer.Sign("a.ndjson", expires)
+
+	if err := signer.Verify("a.ndjson", expires.Unix(), signature, now); err != nil {
+		t.Fatalf("签名校验失败: %v", err)
+	}
+	if err := signer.Verify("b.ndjson", expires.Unix(), signature, now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("对象不一致时期望签名无效，实际: %v", err)
+	}
+	if err := signer.Verify("a.ndjson", expires.Unix()+1, signature, now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("篡改过期时间时期望签名无效，实际: %v", err)
+	}
+	if err := signer.Verify("a.ndjson", expires.Unix(), signature, expires.Add(time.Second)); !errors.Is(err, ErrSignatureExpired) {
+		t.Fatalf("期望签名过期，实际: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package addressservice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ginproject/entity/electrumx"
+	"ginproject/entity/utility"
+	"ginproject/logic/job"
+	"ginproject/middleware/log"
+	"ginproject/repo/storage"
+)
+
+// 导出文件的内容类型
+var exportContentTypes = map[string]string{
+	electrumx.ExportFormatNDJSON: "application/x-ndjson; charset=utf-8",
+	electrumx.ExportFormatCSV:    "text/csv; charset=utf-8",
+}
+
+// StartHistoryExport 提交地址历史导出任务
+// @Router /v1/tbc/main/address/{address}/history/export [post]
+func (s *AddressService) StartHistoryExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	address := c.Param("address")
+	format := c.Query("format")
+
+	log.InfoWithContext(ctx, "收到地址历史导出请求", "address:", address, "format:", format)
+
+	if valid, _, err := utility.ValidateWIFAddress(address); err != nil || !valid {
+		log.ErrorWithContext(ctx, "地址验证失败", "address:", address, "错误:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的地址格式",
+		})
+		return
+	}
+
+	snapshot, err := s.addressLogic.StartHistoryExport(ctx, address, format)
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"code": 0,
+		"job":  snapshot,
+	})
+}
+
+// GetHistoryExport 查询导出任务状态，任务完成时返回带签名的下载链接
+// @Router /v1/tbc/main/export/history/{job_id} [get]
+func (s *AddressService) GetHistoryExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	jobId := c.Param("job_id")
+
+	snapshot, download, err := s.addressLogic.GetHistoryExport(ctx, jobId)
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"code": 0,
+		"job":  snapshot,
+	}
+	if download != nil {
+		response["download_url"] = fmt.Sprintf("%s/download?expires=%d&signature=%s", c.Request.URL.Path, download.Expires, download.Signature)
+		response["expires_at"] = download.Expires
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadHistoryExport 下载导出文件，支持Range请求以便断点续传
+// @Router /v1/tbc/main/export/history/{job_id}/download [get]
+func (s *AddressService) DownloadHistoryExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	jobId := c.Param("job_id")
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		respondExportError(c, storage.ErrSignatureInvalid)
+		return
+	}
+
+	object, format, err := s.addressLogic.OpenHistoryExport(ctx, jobId, expires, c.Query("signature"))
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+	defer object.Close()
+
+	filename := jobId + "." + format
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	http.ServeContent(c.Writer, c.Request, filename, object.Info().ModTime, object)
+}
+
+// respondExportError 将导出相关错误转换为HTTP状态码
+func respondExportError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, electrumx.ErrInvalidExportFormat):
+		status = http.StatusBadRequest
+	case errors.Is(err, job.ErrTooManyJobs):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, job.ErrJobNotFound), errors.Is(err, storage.ErrObjectNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, storage.ErrSignatureInvalid):
+		status = http.StatusForbidden
+	case errors.Is(err, storage.ErrSignatureExpired):
+		status = http.StatusGone
+	}
+	log.ErrorWithContext(c.Request.Context(), "地址历史导出请求失败", "status:", status, "错误:", err)
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,74 @@
+package addressservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ginproject/entity/script"
+	"ginproject/logic/address"
+	"ginproject/logic/job"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+)
+
+// StartScriptHashReindex 提交单个脚本哈希的重建索引任务，用于修复个别地址的数据不一致
+// @Router /v1/tbc/main/admin/reindex/scripthash/{hash} [post]
+func (s *AddressService) StartScriptHashReindex(c *gin.Context) {
+	ctx := c.Request.Context()
+	scriptHash := c.Param("hash")
+
+	if err := script.ValidateScriptHash(scriptHash); err != nil {
+		log.ErrorWithContext(ctx, "脚本哈希验证失败", "scriptHash:", scriptHash, "错误:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	snapshot, err := s.addressLogic.StartScriptHashReindex(ctx, scriptHash)
+	if err != nil {
+		respondReindexError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"code": 0,
+		"job":  snapshot,
+	})
+}
+
+// GetScriptHashReindex 查询重建索引任务的进度和结果
+// @Router /v1/tbc/main/admin/reindex/jobs/{job_id} [get]
+func (s *AddressService) GetScriptHashReindex(c *gin.Context) {
+	snapshot, err := s.addressLogic.GetScriptHashReindex(c.Request.Context(), c.Param("job_id"))
+	if err != nil {
+		respondReindexError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"job":  snapshot,
+	})
+}
+
+// respondReindexError 将重建索引相关错误转换为HTTP状态码
+func respondReindexError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, address.ErrReindexUnsupported):
+		status = http.StatusBadRequest
+	case errors.Is(err, job.ErrJobNotFound), errors.Is(err, db.ErrScriptHashNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, job.ErrTooManyJobs):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, address.ErrReindexUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	log.ErrorWithContext(c.Request.Context(), "重建索引请求失败", "status:", status, "错误:", err)
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": err.Error(),
+	})
+}
@@ -1,16 +1,21 @@
 package addressservice
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"ginproject/entity/apperror"
+	"ginproject/entity/electrumx"
 	"ginproject/entity/utility"
 	"ginproject/logic/address"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
 	rpcex "ginproject/repo/rpc/electrumx"
+	"ginproject/service/registry"
 )
 
 // AddressService 地址服务
@@ -25,6 +30,28 @@ func NewAddressService() *AddressService {
 	}
 }
 
+// RegisterRoutes 注册AddressService的路由
+func (s *AddressService) RegisterRoutes(r *registry.Registry) {
+	// 余额、UTXO和历史类接口在响应头中附带链顶信息
+	withTip := registry.WithMiddleware(chaintip.Headers())
+
+	r.GET("/address/:address/unspent", s.GetAddressUnspentUtxos, "获取地址未花费交易输出", registry.WithQuery("min_confirmations"), registry.WithResponse(electrumx.UtxoResponse{}), registry.Consistent(), withTip)
+	r.GET("/address/:address/utxo/age-distribution", s.GetUtxoAgeDistribution, "获取地址UTXO年龄分布", registry.WithResponse(electrumx.UtxoAgeDistributionResponse{}), withTip)
+	r.GET("/address/:address/history", s.GetAddressHistory, "获取地址历史交易", registry.WithQuery("from_height", "cursor"), registry.WithResponse(electrumx.AddressHistoryResponse{}), registry.WithCost(registry.CostHeavy), registry.Consistent(), withTip)
+	r.GET("/address/:address/history/page/:page", s.GetAddressHistoryPagedFromDB, "分页获取地址历史交易", registry.WithQuery("from_height"), registry.WithResponse(electrumx.AddressHistoryResponse{}), registry.WithCost(registry.CostHeavy), registry.Consistent(), withTip)
+	r.GET("/address/:address/allhistory/page/:page", s.GetAddressHistoryPaged, "使用数据库分页获取地址历史交易", registry.WithQuery("from_height", "cursor"), registry.WithResponse(electrumx.AddressHistoryResponse{}), registry.WithCost(registry.CostHeavy), registry.Consistent(), withTip)
+	r.GET("/address/:address/get/balance", s.GetAddressBalance, "获取地址余额", registry.Consistent(), withTip)
+	r.GET("/address/:address/get/balance/frozen", s.GetAddressFrozenBalance, "获取地址冻结余额", registry.Consistent(), withTip)
+	r.GET("/address/:address/summary", s.GetAddressSummary, "获取地址概要和活跃区间", registry.WithResponse(electrumx.AddressSummaryResponse{}), registry.Consistent(), withTip)
+	r.GET("/address/:address/sync", s.SyncAddress, "地址增量同步", registry.WithQuery("since_height"), registry.WithResponse(electrumx.AddressSyncResponse{}), registry.WithCost(registry.CostHeavy))
+	r.POST("/address/:address/history/export", s.StartHistoryExport, "提交地址历史导出任务", registry.WithQuery("format"), registry.WithCost(registry.CostHeavy))
+	r.GET("/export/history/:job_id", s.GetHistoryExport, "查询地址历史导出任务", registry.WithCost(registry.CostLight))
+	r.GET("/export/history/:job_id/download", s.DownloadHistoryExport, "下载地址历史导出文件", registry.WithQuery("expires", "signature"), registry.WithCost(registry.CostLight), registry.LongLived())
+	r.POST("/admin/reindex/scripthash/:hash", s.StartScriptHashReindex, "提交单个脚本哈希的重建索引任务", registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/reindex/jobs/:job_id", s.GetScriptHashReindex, "查询重建索引任务的进度和结果", registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/shadow/stats", s.GetShadowStats, "获取影子流量比对统计", registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+}
+
 // GetAddressUnspentUtxos 获取地址未花费交易输出(UTXO)
 // @Router /v1/tbc/main/address/{address}/unspent/ [get]
 func (s *AddressService) GetAddressUnspentUtxos(c *gin.Context) {
@@ -57,37 +84,27 @@ func (s *AddressService) GetAddressUnspentUtxos(c *gin.Context) {
 
 	log.InfoWithContext(ctx, "地址验证通过", "address:", address, "type:", addrType)
 
-	// 将地址转换为脚本哈希
-	scriptHash, err := utility.AddressToScriptHash(address)
+	// 可选的最少确认数，交易所只入账确认数足够的输出
+	minConfirmations, err := utility.ParseMinConfirmations(c.Query("min_confirmations"))
 	if err != nil {
-		log.ErrorWithContext(ctx, "地址转换为脚本哈希失败", "address:", address, "错误:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    http.StatusInternalServerError,
-			"message": "地址转换失败: " + err.Error(),
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
 		})
 		return
 	}
-
-	log.InfoWithContext(ctx, "地址已转换为脚本哈希", "address:", address, "scriptHash:", scriptHash)
-
-	// 调用RPC获取UTXO列表
-	utxos, err := rpcex.GetListUnspent(ctx, scriptHash)
-	if err != nil {
-		log.ErrorWithContext(ctx, "获取UTXO失败",
-			"address:", address,
-			"scriptHash:", scriptHash,
-			"错误:", err)
+	// 每个UTXO附带按本次请求链顶计算的确认数和区块时间
+	result := <-s.addressLogic.GetAddressUnspentUtxos(ctx, address, minConfirmations)
+	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    http.StatusInternalServerError,
-			"message": "获取UTXO失败: " + err.Error(),
+			"message": result.Error.Error(),
 		})
 		return
 	}
 
-	log.InfoWithContext(ctx, "成功获取地址UTXO", "address:", address, "count:", len(utxos))
-
 	// 返回成功响应
-	c.JSON(http.StatusOK, utxos)
+	c.JSON(http.StatusOK, result.Utxos)
 }
 
 // getAddressHistoryCommon 获取地址历史交易信息的通用处理函数
@@ -105,24 +122,37 @@ func (s *AddressService) getAddressHistoryCommon(
 		return nil, fmt.Errorf("页码无效")
 	}
 
+	// 可选的起始高度参数，用于增量同步
+	fromHeight, err := strconv.ParseInt(ctx.DefaultQuery("from_height", "0"), 10, 64)
+	if err != nil || fromHeight < 0 {
+		return nil, fmt.Errorf("起始高度无效")
+	}
+	// 截断响应返回的续查游标，仅ElectrumX路径支持
+	cursor := ctx.Query("cursor")
+
 	// 根据来源选择不同的查询方法
 	switch source {
 	case "db":
-		return s.addressLogic.GetAddressHistoryPageFromDB(ctx.Request.Context(), address, true, page)
+		return s.addressLogic.GetAddressHistoryPageFromDB(ctx.Request.Context(), address, true, page, fromHeight)
 	case "latest":
-		return s.addressLogic.GetAddressHistoryPage(ctx.Request.Context(), address, false, 0)
+		history, err := s.addressLogic.GetAddressHistoryPage(ctx.Request.Context(), address, false, 0, fromHeight, cursor)
+		if err == nil && !history.Truncated && cursor == "" {
+			s.addressLogic.ShadowHistoryFromDB(ctx.Request.Context(), history, address, false, 0, fromHeight)
+		}
+		return history, err
 	default:
-		return s.addressLogic.GetAddressHistoryPage(ctx.Request.Context(), address, true, page)
+		history, err := s.addressLogic.GetAddressHistoryPage(ctx.Request.Context(), address, true, page, fromHeight, cursor)
+		if err == nil && !history.Truncated && cursor == "" {
+			s.addressLogic.ShadowHistoryFromDB(ctx.Request.Context(), history, address, true, page, fromHeight)
+		}
+		return history, err
 	}
 }
 
 // handleAddressHistoryError 统一处理地址历史查询错误
 func (s *AddressService) handleAddressHistoryError(c *gin.Context, err error) {
 	log.ErrorWithContextf(c.Request.Context(), "获取地址历史交易失败: %v", err)
-	c.JSON(http.StatusOK, gin.H{
-		"code":    http.StatusInternalServerError,
-		"message": "获取地址历史交易失败: " + err.Error(),
-	})
+	c.Error(apperror.Internal("获取地址历史交易失败", err))
 }
 
 // GetAddressHistory 获取地址历史交易信息
@@ -193,6 +223,12 @@ func (s *AddressService) GetAddressHistoryPagedFromDB(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// GetShadowStats 获取历史查询影子流量的比对统计
+// @Router /v1/tbc/main/admin/shadow/stats [get]
+func (s *AddressService) GetShadowStats(c *gin.Context) {
+	c.JSON(http.StatusOK, address.GetShadowStats())
+}
+
 // GetAddressBalance 获取地址余额
 // @Router /v1/tbc/main/address/{address}/get/balance [get]
 func (s *AddressService) GetAddressBalance(c *gin.Context) {
@@ -205,10 +241,7 @@ func (s *AddressService) GetAddressBalance(c *gin.Context) {
 
 	// 参数验证
 	if address == "" {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  http.StatusBadRequest,
-			"message": "地址参数不能为空",
-		})
+		c.Error(apperror.InvalidParam("地址参数不能为空"))
 		return
 	}
 
@@ -216,10 +249,7 @@ func (s *AddressService) GetAddressBalance(c *gin.Context) {
 	balanceData, err := s.addressLogic.GetAddressBalance(ctx, address)
 	if err != nil {
 		log.ErrorWithContext(ctx, "获取地址余额失败", "address:", address, "错误:", err)
-		c.JSON(http.StatusOK, gin.H{
-			"status":  http.StatusInternalServerError,
-			"message": "获取地址余额失败: " + err.Error(),
-		})
+		c.Error(apperror.Internal("获取地址余额失败", err))
 		return
 	}
 
@@ -243,21 +273,22 @@ func (s *AddressService) GetAddressFrozenBalance(c *gin.Context) {
 
 	// 参数验证
 	if address == "" {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  http.StatusBadRequest,
-			"message": "地址参数不能为空",
-		})
+		c.Error(apperror.InvalidParam("地址参数不能为空"))
 		return
 	}
 
 	// 调用业务逻辑层
 	frozenBalanceData, err := s.addressLogic.GetAddressFrozenBalance(ctx, address)
 	if err != nil {
+		if errors.Is(err, rpcex.ErrCapabilityUnsupported) {
+			c.JSON(http.StatusNotImplemented, gin.H{
+				"status":  http.StatusNotImplemented,
+				"message": "当前ElectrumX服务器不支持查询冻结余额",
+			})
+			return
+		}
 		log.ErrorWithContext(ctx, "获取地址冻结余额失败", "address:", address, "错误:", err)
-		c.JSON(http.StatusOK, gin.H{
-			"status":  http.StatusInternalServerError,
-			"message": "获取地址冻结余额失败: " + err.Error(),
-		})
+		c.Error(apperror.Internal("获取地址冻结余额失败", err))
 		return
 	}
 
@@ -0,0 +1,39 @@
+package addressservice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+)
+
+// GetAddressSummary 获取地址余额和首次出现、最近活动的区块，供浏览器展示地址活跃区间
+// @Router /v1/tbc/main/address/{address}/summary [get]
+func (s *AddressService) GetAddressSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+	address := c.Param("address")
+
+	if valid, _, err := utility.ValidateWIFAddress(address); err != nil || !valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的地址格式",
+		})
+		return
+	}
+
+	log.InfoWithContext(ctx, "收到地址概要请求", "address:", address)
+
+	response, err := s.addressLogic.GetAddressSummary(ctx, address)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取地址概要失败", "address:", address, "错误:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "获取地址概要失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,49 @@
+package addressservice
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+)
+
+// SyncAddress 地址增量同步，返回since_height之后的新交易、已花费输出、新UTXO和最新余额
+// @Router /v1/tbc/main/address/{address}/sync [get]
+func (s *AddressService) SyncAddress(c *gin.Context) {
+	ctx := c.Request.Context()
+	address := c.Param("address")
+
+	if valid, _, err := utility.ValidateWIFAddress(address); err != nil || !valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的地址格式",
+		})
+		return
+	}
+
+	sinceHeight, err := strconv.ParseInt(c.DefaultQuery("since_height", "0"), 10, 64)
+	if err != nil || sinceHeight < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "since_height参数无效",
+		})
+		return
+	}
+
+	log.InfoWithContext(ctx, "收到地址增量同步请求", "address:", address, "sinceHeight:", sinceHeight)
+
+	response, err := s.addressLogic.SyncAddress(ctx, address, sinceHeight)
+	if err != nil {
+		log.ErrorWithContext(ctx, "地址增量同步失败", "address:", address, "错误:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "地址增量同步失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
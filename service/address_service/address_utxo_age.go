@@ -0,0 +1,39 @@
+package addressservice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ginproject/entity/utility"
+	"ginproject/middleware/log"
+)
+
+// GetUtxoAgeDistribution 按确认数和金额统计地址UTXO的分布，供钱包规划UTXO合并
+// @Router /v1/tbc/main/address/{address}/utxo/age-distribution [get]
+func (s *AddressService) GetUtxoAgeDistribution(c *gin.Context) {
+	ctx := c.Request.Context()
+	address := c.Param("address")
+
+	if valid, _, err := utility.ValidateWIFAddress(address); err != nil || !valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的地址格式",
+		})
+		return
+	}
+
+	log.InfoWithContext(ctx, "收到UTXO年龄分布请求", "address:", address)
+
+	response, err := s.addressLogic.GetUtxoAgeDistribution(ctx, address)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取UTXO年龄分布失败", "address:", address, "错误:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "获取UTXO年龄分布失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,58 @@
+package block_service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"ginproject/entity/block"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	"ginproject/repo/rpc/blockchain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNextBlock 长轮询等待下一个区块，出块后返回新区块头，超时返回204
+// 传入after时以该区块哈希为基准，链顶已经不是after时立即返回，避免两次请求之间出的块被漏掉
+func (s *blockService) GetNextBlock(c *gin.Context) {
+	ctx := c.Request.Context()
+	timeout, err := block.ParseNextBlockTimeout(c.Query("timeout"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	after := c.Query("after")
+	if after == "" {
+		current, err := chaintip.Current(ctx)
+		if err != nil {
+			log.ErrorWithContext(ctx, "获取链顶失败", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取链顶失败"})
+			return
+		}
+		after = current.Hash
+	} else if err := block.ValidateBlockHash(after); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tip, err := chaintip.Next(waitCtx, after)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			c.Status(http.StatusNoContent)
+		}
+		// 客户端已断开时不再写响应
+		return
+	}
+
+	result := <-blockchain.FetchBlockHeaderByHash(ctx, tip.Hash)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "获取区块头数据失败", "hash", tip.Hash, "error", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取区块头数据失败"})
+		return
+	}
+	c.JSON(http.StatusOK, recountConfirmations(ctx, result.Result))
+}
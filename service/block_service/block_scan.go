@@ -0,0 +1,78 @@
+package block_service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"ginproject/entity/block"
+	blocklogic "ginproject/logic/block"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// txScanWriter 将扫描结果按NDJSON逐行写出，每个区块写完后刷新一次
+// 写出阻塞时扫描的预取也随之暂停，客户端断开后写出失败并终止扫描
+type txScanWriter struct {
+	c       *gin.Context
+	encoder *json.Encoder
+	blocks  int
+	txs     int
+}
+
+func newTxScanWriter(c *gin.Context) *txScanWriter {
+	return &txScanWriter{c: c, encoder: json.NewEncoder(c.Writer)}
+}
+
+// writeBlock 写出区块标记和区块内全部交易的摘要
+func (w *txScanWriter) writeBlock(b *block.BlockWithTxs) error {
+	if err := w.encoder.Encode(block.NewTxScanBlockMarker(b)); err != nil {
+		return err
+	}
+	for i := range b.Tx {
+		if err := w.encoder.Encode(block.SummarizeTx(b.Height, i, &b.Tx[i])); err != nil {
+			return err
+		}
+	}
+	w.blocks++
+	w.txs += len(b.Tx)
+	w.c.Writer.Flush()
+	return nil
+}
+
+// GetBlockRangeTxs 流式返回区块范围内的交易摘要，供ETL批量拉取
+func (s *blockService) GetBlockRangeTxs(c *gin.Context) {
+	ctx := c.Request.Context()
+	from, errFrom := strconv.ParseInt(c.Param("from"), 10, 64)
+	to, errTo := strconv.ParseInt(c.Param("to"), 10, 64)
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "区块高度必须为整数"})
+		return
+	}
+	if err := block.ValidateBlockRange(from, to); err != nil {
+		log.ErrorWithContext(ctx, "区块范围验证失败", "from", from, "to", to, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if tip, err := chaintip.Current(ctx); err == nil && to > tip.Height {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "结束高度超过当前链顶", "tip_height": tip.Height})
+		return
+	}
+
+	log.InfoWithContext(ctx, "开始扫描区块范围交易", "from", from, "to", to)
+	c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := newTxScanWriter(c)
+	height, err := blocklogic.ScanRange(ctx, from, to, w.writeBlock)
+	if err != nil {
+		// 响应头已发送，错误以单独一行输出，客户端可从该高度继续
+		log.ErrorWithContext(ctx, "扫描区块范围交易失败", "height", height, "error", err)
+		w.encoder.Encode(block.TxScanError{Type: block.TxScanRecordError, Height: height, Error: err.Error()})
+		return
+	}
+	w.encoder.Encode(block.TxScanEnd{Type: block.TxScanRecordEnd, From: from, To: to, Blocks: w.blocks, Txs: w.txs})
+	log.InfoWithContext(ctx, "扫描区块范围交易完成", "from", from, "to", to, "txs", w.txs)
+}
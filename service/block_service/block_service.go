@@ -1,9 +1,15 @@
 package block_service
 
 import (
+	"context"
+
 	"ginproject/entity/block"
+	blocklogic "ginproject/logic/block"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/httpcache"
 	"ginproject/middleware/log"
 	"ginproject/repo/rpc/blockchain"
+	"ginproject/service/registry"
 	"net/http"
 	"strconv"
 
@@ -12,11 +18,16 @@ import (
 
 // BlockService 区块服务接口
 type BlockService interface {
+	RegisterRoutes(r *registry.Registry)
 	GetBlockByHeight(c *gin.Context)
 	GetBlockByHash(c *gin.Context)
+	GetRawBlockByHash(c *gin.Context)
+	GetBlockTxsByHash(c *gin.Context)
 	GetBlockHeaderByHeight(c *gin.Context)
 	GetBlockHeaderByHash(c *gin.Context)
 	GetNearby10Headers(c *gin.Context)
+	GetBlockRangeTxs(c *gin.Context)
+	GetNextBlock(c *gin.Context)
 }
 
 // blockService 区块服务实现
@@ -27,6 +38,24 @@ func NewBlockService() BlockService {
 	return &blockService{}
 }
 
+// RegisterRoutes 注册BlockService的路由
+func (s *blockService) RegisterRoutes(r *registry.Registry) {
+	// 确认数按响应头中的链顶统一计算
+	withTip := registry.WithMiddleware(chaintip.Headers())
+	// 按哈希获取的区块内容不变，哈希即为强ETag，需要在链顶中间件之后执行以便304响应也带有最新链顶
+	byHash := registry.WithMiddleware(httpcache.Immutable("hash"))
+
+	r.GET("/block/height/:height", s.GetBlockByHeight, "通过高度获取区块详情", withTip)
+	r.GET("/block/hash/:hash", s.GetBlockByHash, "通过哈希获取区块详情", registry.Cacheable(), withTip, byHash)
+	r.GET("/block/hash/:hash/raw", s.GetRawBlockByHash, "通过哈希获取序列化区块的十六进制", registry.Cacheable(), registry.WithCost(registry.CostHeavy), byHash)
+	r.GET("/block/hash/:hash/txs/page/:page/size/:size", s.GetBlockTxsByHash, "分页获取区块中的交易摘要", registry.WithResponse(block.BlockTxsResponse{}), registry.Cacheable(), withTip)
+	r.GET("/block/height/:height/header", s.GetBlockHeaderByHeight, "通过高度获取区块头信息", registry.Cacheable(), registry.WithCost(registry.CostLight), withTip)
+	r.GET("/block/hash/:hash/header", s.GetBlockHeaderByHash, "通过哈希获取区块头信息", registry.Cacheable(), registry.WithCost(registry.CostLight), withTip, byHash)
+	r.GET("/block/headers", s.GetNearby10Headers, "获取链顶附近的区块头信息", registry.WithQuery("count"), registry.Cacheable(), withTip)
+	r.GET("/block/next", s.GetNextBlock, "长轮询等待下一个区块", registry.WithQuery("timeout", "after"), registry.WithCost(registry.CostLight), registry.LongLived())
+	r.GET("/blocks/:from/:to/txs", s.GetBlockRangeTxs, "流式获取区块范围内的交易摘要", registry.WithCost(registry.CostHeavy))
+}
+
 // GetBlockByHeight 通过高度获取区块详情
 func (s *blockService) GetBlockByHeight(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -54,7 +83,7 @@ func (s *blockService) GetBlockByHeight(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result.Result)
+	c.JSON(http.StatusOK, recountConfirmations(ctx, result.Result))
 }
 
 // GetBlockByHash 通过哈希获取区块详情
@@ -78,7 +107,58 @@ func (s *blockService) GetBlockByHash(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result.Result)
+	revalidateOrphan(c, result.Result)
+	c.JSON(http.StatusOK, recountConfirmations(ctx, result.Result))
+}
+
+// GetRawBlockByHash 通过哈希获取序列化区块，以十六进制纯文本返回
+func (s *blockService) GetRawBlockByHash(c *gin.Context) {
+	ctx := c.Request.Context()
+	hash := c.Param("hash")
+
+	if err := block.ValidateBlockHash(hash); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := <-blockchain.FetchRawBlockByHash(ctx, hash)
+	if result.Error != nil {
+		log.ErrorWithContext(ctx, "获取原始区块失败", "hash", hash, "error", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取原始区块失败"})
+		return
+	}
+
+	c.String(http.StatusOK, result.Result.(string))
+}
+
+// GetBlockTxsByHash 分页获取区块中的交易摘要，只解码当前页的交易
+func (s *blockService) GetBlockTxsByHash(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req block.BlockTxsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := blocklogic.GetBlockTxsPage(ctx, &req)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取区块交易失败", "hash", req.Hash, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取区块交易失败"})
+		return
+	}
+
+	// 与区块详情一致，确认数按本次请求的链顶计算，已不在主链上的区块为0
+	if resp.Confirmations < 0 {
+		resp.Confirmations = 0
+	} else if tip, err := chaintip.Snapshot(ctx); err == nil {
+		resp.Confirmations = chaintip.Confirmations(tip, resp.Height)
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // GetBlockHeaderByHeight 通过高度获取区块头信息
@@ -108,7 +188,7 @@ func (s *blockService) GetBlockHeaderByHeight(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result.Result)
+	c.JSON(http.StatusOK, recountConfirmations(ctx, result.Result))
 }
 
 // GetBlockHeaderByHash 通过哈希获取区块头信息
@@ -132,20 +212,55 @@ func (s *blockService) GetBlockHeaderByHash(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result.Result)
+	revalidateOrphan(c, result.Result)
+	c.JSON(http.StatusOK, recountConfirmations(ctx, result.Result))
 }
 
-// GetNearby10Headers 获取附近的10个区块头信息
+// GetNearby10Headers 获取链顶附近的区块头信息，数量由count参数指定，默认10个
 func (s *blockService) GetNearby10Headers(c *gin.Context) {
 	ctx := c.Request.Context()
-	// 调用RPC获取最近10个区块头信息
-	headersDataChan := blockchain.FetchNearby10Headers(ctx)
+	count, err := block.ParseNearbyHeaderCount(c.Query("count"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 调用RPC获取最近的区块头信息
+	headersDataChan := blockchain.FetchNearbyHeaders(ctx, count)
 	result := <-headersDataChan
 	if result.Error != nil {
-		log.ErrorWithContext(ctx, "获取最近10个区块头数据失败", "error", result.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取最近10个区块头数据失败"})
+		log.ErrorWithContext(ctx, "获取最近的区块头数据失败", "error", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取最近的区块头数据失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, result.Result)
+	c.JSON(http.StatusOK, recountConfirmations(ctx, result.Result))
+}
+
+// recountConfirmations 按本次请求的链顶重新计算区块的确认数，链顶不可用时保留节点返回的值
+func recountConfirmations(ctx context.Context, result interface{}) interface{} {
+	tip, err := chaintip.Snapshot(ctx)
+	if err != nil {
+		return result
+	}
+	switch blocks := result.(type) {
+	case map[string]interface{}:
+		chaintip.RecountBlock(tip, blocks)
+	case []map[string]interface{}:
+		for _, block := range blocks {
+			chaintip.RecountBlock(tip, block)
+		}
+	}
+	return result
+}
+
+// revalidateOrphan 已不在主链上的区块确认数会变化，不作为不可变资源缓存
+func revalidateOrphan(c *gin.Context, result interface{}) {
+	data, ok := result.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if confirmations, _ := data["confirmations"].(float64); confirmations < 0 {
+		httpcache.Revalidate(c)
+	}
 }
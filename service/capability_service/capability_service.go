@@ -0,0 +1,31 @@
+package capability_service
+
+import (
+	"net/http"
+
+	"ginproject/entity/capability"
+	logic "ginproject/logic/capability"
+	"ginproject/service/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CapabilityService 部署能力描述服务
+type CapabilityService struct {
+	prefix string
+}
+
+// NewCapabilityService 创建CapabilityService实例，prefix为路由挂载的接口路径前缀
+func NewCapabilityService(prefix string) *CapabilityService {
+	return &CapabilityService{prefix: prefix}
+}
+
+// RegisterRoutes 注册CapabilityService的路由
+func (s *CapabilityService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/capabilities", s.GetCapabilities, "获取当前部署启用的可选功能", registry.WithResponse(capability.CapabilitiesResponse{}), registry.WithCost(registry.CostLight))
+}
+
+// GetCapabilities 返回各可选功能的启用状态和版本
+func (s *CapabilityService) GetCapabilities(c *gin.Context) {
+	c.JSON(http.StatusOK, logic.GetCapabilities(c.Request.Context(), s.prefix))
+}
@@ -4,15 +4,19 @@ import (
 	"net/http"
 
 	"ginproject/entity/block"
+	blocklogic "ginproject/logic/block"
 	"ginproject/middleware/log"
 	"ginproject/repo/rpc/blockchain"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ChainInfoService 区块链信息服务接口
 type ChainInfoService interface {
+	RegisterRoutes(r *registry.Registry)
 	GetChainInfo(c *gin.Context)
+	GetChainTips(c *gin.Context)
 }
 
 // chainInfoService 区块链信息服务实现
@@ -23,6 +27,12 @@ func NewChainInfoService() ChainInfoService {
 	return &chainInfoService{}
 }
 
+// RegisterRoutes 注册ChainInfoService的路由
+func (s *chainInfoService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/chain/info", s.GetChainInfo, "获取区块链信息", registry.WithResponse(block.ChainInfo{}), registry.Cacheable(), registry.WithCost(registry.CostLight))
+	r.GET("/chain/tips", s.GetChainTips, "获取节点已知的链顶和分叉", registry.WithResponse(block.ChainTipsResponse{}))
+}
+
 // GetChainInfo 获取区块链信息
 func (s *chainInfoService) GetChainInfo(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -66,6 +76,19 @@ func (s *chainInfoService) GetChainInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, chainInfo)
 }
 
+// GetChainTips 获取节点已知的链顶和分叉，用于观察竞争分叉和分叉选择
+func (s *chainInfoService) GetChainTips(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	tips, err := blocklogic.GetChainTips(ctx)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取链顶列表失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取链顶列表失败"})
+		return
+	}
+	c.JSON(http.StatusOK, tips)
+}
+
 // 以下是辅助函数，用于安全地获取map中的各种类型值
 func getString(data map[string]interface{}, key string) string {
 	if val, ok := data[key]; ok {
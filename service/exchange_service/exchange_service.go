@@ -5,6 +5,7 @@ import (
 
 	"ginproject/logic/exchange"
 	"ginproject/middleware/log"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,6 +18,11 @@ func NewExchangeService() *ExchangeService {
 	return &ExchangeService{}
 }
 
+// RegisterRoutes 注册ExchangeService的路由
+func (s *ExchangeService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/exchangerate", s.GetExchangeRate, "获取TBC汇率", registry.Cacheable(), registry.WithCost(registry.CostLight))
+}
+
 // GetExchangeRate 处理获取TBC交易所汇率信息的请求
 func (s *ExchangeService) GetExchangeRate(c *gin.Context) {
 	ctx := c.Request.Context()
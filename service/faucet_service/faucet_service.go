@@ -0,0 +1,51 @@
+package faucet_service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ginproject/entity/faucet"
+	logic "ginproject/logic/faucet"
+	"ginproject/middleware/log"
+	"ginproject/service/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FaucetService 测试网水龙头服务
+type FaucetService struct{}
+
+// NewFaucetService 创建新的水龙头服务实例
+func NewFaucetService() *FaucetService {
+	return &FaucetService{}
+}
+
+// RegisterRoutes 注册FaucetService的路由
+func (s *FaucetService) RegisterRoutes(r *registry.Registry) {
+	r.POST("/faucet/request/:address", s.RequestFunds, "向地址发放测试币", registry.WithAuth(registry.ScopeWrite))
+}
+
+// RequestFunds 向指定地址发放测试币
+func (s *FaucetService) RequestFunds(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req faucet.FaucetRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+
+	resp, statusCode, err := logic.RequestFunds(ctx, &req, c.ClientIP())
+	if err != nil {
+		var cooldownErr *logic.CooldownError
+		if errors.As(err, &cooldownErr) {
+			c.Header("Retry-After", strconv.FormatInt(int64(cooldownErr.RetryAfter.Seconds()+0.5), 10))
+		}
+		log.WarnWithContext(ctx, "水龙头领取失败", "address", req.Address, "error", err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, resp)
+}
@@ -0,0 +1,83 @@
+package ft_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"ginproject/entity/ft"
+	"ginproject/entity/utility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 每写出多少条记录刷新一次响应
+const lpUnspentFlushInterval = 200
+
+// lpUnspentStreamWriter 将LP未花费交易输出逐条写入响应，输出格式与TBC20FTLPUnspentResponse一致
+// 第一条记录写出前不会发送响应头，此前出错时仍可以返回普通的错误响应
+type lpUnspentStreamWriter struct {
+	c       *gin.Context
+	started bool
+	count   int
+}
+
+// start 发送响应头和列表起始部分
+func (w *lpUnspentStreamWriter) start() error {
+	w.started = true
+	w.c.Header("Content-Type", "application/json; charset=utf-8")
+	w.c.Status(http.StatusOK)
+	_, err := w.c.Writer.WriteString(`{"ftUtxoList":[`)
+	return err
+}
+
+// write 写出一条记录
+func (w *lpUnspentStreamWriter) write(item *ft.TBC20FTLPUnspentItem) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	} else if _, err := w.c.Writer.WriteString(","); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if _, err := w.c.Writer.Write(data); err != nil {
+		return err
+	}
+
+	w.count++
+	if w.count%lpUnspentFlushInterval == 0 {
+		w.c.Writer.Flush()
+	}
+	return nil
+}
+
+// finish 写出列表结尾、总数和分页元数据，meta为空时不输出
+func (w *lpUnspentStreamWriter) finish(total int, meta *utility.PageMeta) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w.c.Writer, `],"total":%d`, total); err != nil {
+		return err
+	}
+	if meta != nil {
+		data, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w.c.Writer, `,"meta":%s`, data); err != nil {
+			return err
+		}
+	}
+	if _, err := w.c.Writer.WriteString("}"); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
@@ -1,14 +1,19 @@
 package ft_service
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
-	"ginproject/entity/constant"
+	"ginproject/entity/apperror"
 	"ginproject/entity/ft"
 	"ginproject/entity/utility"
 	ftlogic "ginproject/logic/ft"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	tbchttp "ginproject/repo/http"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +30,51 @@ func NewFtService() *FtService {
 	}
 }
 
+// RegisterRoutes 注册FtService的路由
+func (s *FtService) RegisterRoutes(r *registry.Registry) {
+	// 余额、UTXO和历史类接口在响应头中附带链顶信息
+	withTip := registry.WithMiddleware(chaintip.Headers())
+
+	r.GET("/ft/balance/address/:address/contract/:contract_id", s.GetFtBalanceByAddress, "根据地址和合约ID获取FT余额", withTip)
+	r.GET("/ft/utxo/address/:address/contract/:contract_id", s.GetFtUtxoByAddress, "根据地址和合约ID获取FT UTXO", registry.WithQuery("min_confirmations"), withTip)
+	r.GET("/ft/info/contract/id/:contract_id", s.GetFtInfoByContractId, "根据合约ID获取FT信息", registry.Cacheable(), registry.WithCost(registry.CostLight))
+	r.POST("/ft/balance/address/:address/contract/ids", s.GetMultiFtBalanceByAddress, "获取地址持有的多个代币余额", registry.WithCost(registry.CostHeavy), withTip)
+	r.GET("/ft/pool/nft/info/contract/id/:ft_contract_id", s.GetPoolNFTInfoByContractId, "根据合约ID获取NFT池信息")
+	r.GET("/ft/pool/:pool_id/reserves", s.GetPoolReserves, "获取池子当前储备", registry.WithCost(registry.CostLight), withTip)
+	r.GET("/ft/lp/unspent/by/script/hash:script_hash", s.GetLPUnspentByScriptHash, "根据脚本哈希获取LP未花费交易输出",
+		registry.WithQuery("min_balance", "page", "size"), withTip)
+	r.POST("/ft/lp/unspent/by/script/hashes", s.GetLPUnspentByScriptHashes, "批量获取LP未花费交易输出", registry.WithCost(registry.CostHeavy), withTip)
+	r.GET("/ft/history/address/:address/contract/:contract_id/page/:page/size/:size", s.GetFtHistoryByAddress, "获取地址的FT交易历史", registry.WithQuery("from_height"), registry.WithCost(registry.CostHeavy), withTip)
+	r.GET("/ft/tokens/page/:page/size/:size/orderby/:order_by", s.GetFtTokenList, "获取代币列表", registry.Cacheable())
+	r.GET("/ft/tokens/search", s.SearchFtTokens, "按名称或符号搜索代币", registry.WithQuery("keyword", "page", "size"),
+		registry.Cacheable(), registry.WithResponse(ft.FtTokenListData{}))
+	r.GET("/ft/tokens/held/by/combine/script/:combine_script", s.GetFtTokenListHeldByCombineScript, "通过合并脚本获取持有的代币列表")
+	r.GET("/ft/decode/tx/history/:txid", s.DecodeFtTransactionHistory, "解析FT交易历史", registry.Cacheable(), registry.WithCost(registry.CostHeavy))
+	r.GET("/ft/pools/of/token/contract/id/:ft_contract_id", s.GetPoolsOfTokenByContractId, "获取代币相关流动池列表")
+	r.GET("/ft/token/history/contract/id/:ft_contract_id/page/:page/size/:size", s.GetTokenHistoryByContractId, "获取代币历史交易记录", registry.WithCost(registry.CostHeavy), withTip)
+	r.GET("/ft/pool/history/pool/id/:pool_id/page/:page/size/:size", s.GetPoolHistoryByPoolId, "获取池子历史记录", withTip)
+	r.GET("/ft/pool/list/page/:page/size/:size", s.GetPoolList, "获取交易池列表", registry.Cacheable())
+	r.GET("/ft/tokens/held/by/address/:address", s.GetTokenListHeldByAddress, "获取地址持有的代币列表")
+	r.GET("/ft/portfolio/address/:address", s.GetFtPortfolioByAddress, "获取地址FT资产估值", registry.WithCost(registry.CostHeavy))
+	r.GET("/ft/holder/rank/contract/:contract_id/page/:page/size/:size", s.GetHolderRankByContractId, "获取代币持有者排名", registry.Cacheable())
+	r.GET("/ft/token/metrics/contract/:contract_id", s.GetTokenMetricsByContractId, "获取代币流通速度和休眠比例",
+		registry.WithQuery("period_days", "dormancy_days"), registry.Cacheable(), registry.WithCost(registry.CostHeavy),
+		registry.WithResponse(ft.FtTokenMetricsResponse{}))
+	r.GET("/ft/burns/contract/:contract_id", s.GetFtBurnsByContractId, "获取代币销毁记录和累计销毁数量",
+		registry.WithQuery("page", "size"), registry.WithCost(registry.CostLight), registry.WithResponse(ft.FtBurnsResponse{}))
+	r.GET("/ft/candles/contract/:contract_id", s.GetFtCandlesByContractId, "获取代币池兑换价格K线",
+		registry.WithQuery("interval", "from", "to"), registry.Cacheable(), registry.WithCost(registry.CostLight),
+		registry.WithResponse(ft.FtCandlesResponse{}))
+	r.GET("/ft/icon/contract/:contract_id", s.GetFtIconByContractId, "获取代币元数据中登记的图标，内容为校验后重新编码的PNG", registry.WithCost(registry.CostLight))
+	r.GET("/ft/utxo/combine/script/:combine_script/contract/:contract_id", s.GetFtUtxoByCombineScript, "根据合并脚本和合约ID获取FT UTXO", registry.WithQuery("min_confirmations"), withTip)
+	r.GET("/ft/balance/combine/script/:combine_script/contract/:contract_hash", s.GetFtBalanceByCombineScript, "根据合并脚本和合约哈希获取FT余额", withTip)
+	r.POST("/ft/token/registry/import", s.ImportTokenRegistry, "导入代币元数据", registry.WithAuth(registry.ScopeAdmin))
+	r.POST("/ft/token/:contract_id/verification/challenge", s.CreateTokenVerificationChallenge, "获取代币创建者核验挑战",
+		registry.WithResponse(ft.TokenVerificationChallengeResponse{}), registry.WithAuth(registry.ScopeWrite))
+	r.POST("/ft/token/:contract_id/verification", s.VerifyTokenCreator, "提交创建者签名核验代币",
+		registry.WithRequest(ft.TokenVerificationRequest{}), registry.WithResponse(ft.TokenVerificationResponse{}), registry.WithAuth(registry.ScopeWrite))
+}
+
 // GetFtBalanceByAddress 根据地址和合约ID获取FT余额
 // 路由: GET /v1/tbc/main/ft/balance/address/:address/contract/:contract_id
 func (s *FtService) GetFtBalanceByAddress(c *gin.Context) {
@@ -34,7 +84,7 @@ func (s *FtService) GetFtBalanceByAddress(c *gin.Context) {
 	var req ft.FtBalanceAddressRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -44,7 +94,7 @@ func (s *FtService) GetFtBalanceByAddress(c *gin.Context) {
 	response, err := s.ftLogic.GetFtBalance(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理FT余额查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询FT余额失败"))
+		respondError(c, err, "查询FT余额失败")
 		return
 	}
 
@@ -61,18 +111,25 @@ func (s *FtService) GetFtHistoryByAddress(c *gin.Context) {
 	var req ft.FtHistoryRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定可选的查询参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
 		return
 	}
 
-	log.InfoWithContextf(ctx, "获取FT交易历史请求: 地址=%s, 合约ID=%s, 页码=%d, 每页大小=%d",
-		req.Address, req.ContractId, req.Page, req.Size)
+	log.InfoWithContextf(ctx, "获取FT交易历史请求: 地址=%s, 合约ID=%s, 页码=%d, 每页大小=%d, 起始高度=%d",
+		req.Address, req.ContractId, req.Page, req.Size, req.FromHeight)
 
 	// 调用逻辑层处理业务
 	response, err := s.ftLogic.GetFtHistory(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理FT交易历史查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询FT交易历史失败"))
+		respondError(c, err, "查询FT交易历史失败")
 		return
 	}
 
@@ -89,7 +146,14 @@ func (s *FtService) GetFtUtxoByAddress(c *gin.Context) {
 	var req ft.FtUtxoAddressRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定可选的查询参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
 		return
 	}
 
@@ -99,7 +163,7 @@ func (s *FtService) GetFtUtxoByAddress(c *gin.Context) {
 	response, err := s.ftLogic.GetFtUtxosByAddress(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理FT UTXO查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询FT UTXO失败"))
+		respondError(c, err, "查询FT UTXO失败")
 		return
 	}
 
@@ -116,7 +180,7 @@ func (s *FtService) GetFtInfoByContractId(c *gin.Context) {
 	var req ft.FtInfoContractIdRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -126,7 +190,7 @@ func (s *FtService) GetFtInfoByContractId(c *gin.Context) {
 	response, err := s.ftLogic.GetFtInfoByContractId(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理FT信息查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询FT信息失败"))
+		respondError(c, err, "查询FT信息失败")
 		return
 	}
 
@@ -143,7 +207,7 @@ func (s *FtService) GetMultiFtBalanceByAddress(c *gin.Context) {
 	address := c.Param("address")
 	if address == "" {
 		log.ErrorWithContextf(ctx, "地址参数为空")
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "地址不能为空"))
+		c.Error(apperror.InvalidParam("地址不能为空"))
 		return
 	}
 
@@ -154,7 +218,7 @@ func (s *FtService) GetMultiFtBalanceByAddress(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&reqBody); err != nil {
 		log.ErrorWithContextf(ctx, "绑定JSON请求体失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -170,7 +234,7 @@ func (s *FtService) GetMultiFtBalanceByAddress(c *gin.Context) {
 	responseList, err := s.ftLogic.GetMultiFtBalanceByAddress(ctx, req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理多个FT余额查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询多个FT余额失败"))
+		respondError(c, err, "查询多个FT余额失败")
 		return
 	}
 
@@ -187,7 +251,7 @@ func (s *FtService) GetPoolNFTInfoByContractId(c *gin.Context) {
 	var req ft.TBC20PoolNFTInfoRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -199,9 +263,9 @@ func (s *FtService) GetPoolNFTInfoByContractId(c *gin.Context) {
 		log.ErrorWithContextf(ctx, "处理NFT池信息查询失败: %v", err)
 
 		// 判断是否是未找到NFT池的错误
-		if err.Error() == "未找到NFT池" {
+		if db.IsNotFound(err) {
 			// 返回特定的错误格式
-			c.JSON(http.StatusOK, ft.ErrorResponse{
+			c.JSON(http.StatusNotFound, ft.ErrorResponse{
 				Error: "No pool NFT found.",
 			})
 			return
@@ -233,7 +297,7 @@ func (s *FtService) GetFtTokenList(c *gin.Context) {
 	var req ft.FtTokenListRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -244,7 +308,41 @@ func (s *FtService) GetFtTokenList(c *gin.Context) {
 	response, err := s.ftLogic.GetFtTokenList(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理代币列表查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询代币列表失败"))
+		respondError(c, err, "查询代币列表失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchFtTokens 按名称或符号搜索代币
+// 路由: GET /v1/tbc/main/ft/tokens/search?keyword=&page=&size=
+func (s *FtService) SearchFtTokens(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定查询参数
+	var req ft.FtTokenSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	log.InfoWithContextf(ctx, "搜索代币请求: 关键字=%s, 页码=%d, 每页大小=%d", req.Keyword, req.Page, req.Size)
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.SearchFtTokens(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理代币搜索失败: %v", err)
+		respondError(c, err, "搜索代币失败")
 		return
 	}
 
@@ -261,7 +359,7 @@ func (s *FtService) DecodeFtTransactionHistory(c *gin.Context) {
 	var req ft.FtTxDecodeRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -271,7 +369,7 @@ func (s *FtService) DecodeFtTransactionHistory(c *gin.Context) {
 	response, err := s.ftLogic.DecodeFtTransactionHistory(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理FT交易解析失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "解析FT交易失败"))
+		respondError(c, err, "解析FT交易失败")
 		return
 	}
 
@@ -288,7 +386,7 @@ func (s *FtService) GetFtTokenListHeldByCombineScript(c *gin.Context) {
 	var req ft.TBC20TokenListHeldByCombineScriptRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -298,7 +396,7 @@ func (s *FtService) GetFtTokenListHeldByCombineScript(c *gin.Context) {
 	response, err := s.ftLogic.GetTokensListHeldByCombineScript(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理通过合并脚本获取代币列表查询失败: %v", err)
-		c.JSON(http.StatusInternalServerError, utility.NewErrorResponse(constant.CodeServerError, "查询代币列表失败"))
+		c.Error(apperror.Internal("查询代币列表失败", err))
 		return
 	}
 
@@ -315,7 +413,7 @@ func (s *FtService) GetTokenListHeldByAddress(c *gin.Context) {
 	var req ft.TBC20TokenListHeldByAddressRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -325,7 +423,121 @@ func (s *FtService) GetTokenListHeldByAddress(c *gin.Context) {
 	response, err := s.ftLogic.GetTokensListHeldByAddress(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理地址持有的代币列表查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询地址持有的代币列表失败"))
+		respondError(c, err, "查询地址持有的代币列表失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPoolReserves 获取池子当前储备
+// 路由: GET /v1/tbc/main/ft/pool/:pool_id/reserves
+func (s *FtService) GetPoolReserves(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.TBC20PoolReservesRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	log.InfoWithContextf(ctx, "获取池子储备请求: 池子ID=%s", req.PoolId)
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.GetPoolReserves(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理池子储备查询失败: %v", err)
+		respondError(c, err, "查询池子储备失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTokenMetricsByContractId 获取代币流通速度和休眠比例
+// 路由: GET /v1/tbc/main/ft/token/metrics/contract/:contract_id
+func (s *FtService) GetTokenMetricsByContractId(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.FtTokenMetricsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定可选的查询参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	log.InfoWithContextf(ctx, "获取代币流通指标请求: 合约ID=%s, 统计周期=%d天, 休眠天数=%d",
+		req.ContractId, req.PeriodDays, req.DormancyDays)
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.GetFtTokenMetrics(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理代币流通指标查询失败: %v", err)
+		respondError(c, err, "查询代币流通指标失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// GetFtBurnsByContractId 获取代币转入销毁地址的记录和累计销毁数量
+func (s *FtService) GetFtBurnsByContractId(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.FtBurnsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定分页查询参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.GetFtBurns(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理代币销毁记录查询失败: %v", err)
+		respondError(c, err, "查询代币销毁记录失败")
 		return
 	}
 
@@ -333,6 +545,77 @@ func (s *FtService) GetTokenListHeldByAddress(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetFtCandlesByContractId 获取代币按池兑换聚合的价格K线
+func (s *FtService) GetFtCandlesByContractId(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.FtCandlesRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定周期和时间范围查询参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.GetFtCandles(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理代币K线查询失败: %v", err)
+		respondError(c, err, "查询代币K线失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// GetFtIconByContractId 获取代币图标，外部图标经过类型、大小和尺寸校验并重新编码为PNG后返回，不直接转发远程内容
+// 路由: GET /v1/tbc/main/ft/icon/contract/:contract_id
+func (s *FtService) GetFtIconByContractId(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.FtInfoContractIdRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 调用逻辑层处理业务
+	object, err := s.ftLogic.GetTokenIcon(ctx, req.ContractId)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "获取代币图标失败: %v", err)
+		if errors.Is(err, tbchttp.ErrIconRejected) {
+			c.Error(apperror.NotFound("代币图标不可用"))
+			return
+		}
+		respondError(c, err, "获取代币图标失败")
+		return
+	}
+	defer object.Close()
+
+	c.Header("Content-Type", "image/png")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'")
+	c.Header("Cache-Control", "public, max-age=3600")
+	http.ServeContent(c.Writer, c.Request, "", object.Info().ModTime, object)
+}
+
 // GetPoolsOfTokenByContractId 获取代币相关的流动池列表
 // 路由: GET /v1/tbc/main/ft/pools/of/token/contract/id/:ft_contract_id
 func (s *FtService) GetPoolsOfTokenByContractId(c *gin.Context) {
@@ -342,7 +625,7 @@ func (s *FtService) GetPoolsOfTokenByContractId(c *gin.Context) {
 	var req ft.TBC20PoolListRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -352,7 +635,7 @@ func (s *FtService) GetPoolsOfTokenByContractId(c *gin.Context) {
 	response, err := s.ftLogic.GetPoolListByFtContractId(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理代币相关流动池列表查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询代币相关流动池列表失败"))
+		respondError(c, err, "查询代币相关流动池列表失败")
 		return
 	}
 
@@ -369,7 +652,7 @@ func (s *FtService) GetTokenHistoryByContractId(c *gin.Context) {
 	var req ft.FtTokenHistoryRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -380,7 +663,7 @@ func (s *FtService) GetTokenHistoryByContractId(c *gin.Context) {
 	response, err := s.ftLogic.GetTokenHistory(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理代币历史交易记录查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询代币历史交易记录失败"))
+		respondError(c, err, "查询代币历史交易记录失败")
 		return
 	}
 
@@ -397,7 +680,7 @@ func (s *FtService) GetPoolHistoryByPoolId(c *gin.Context) {
 	var req ft.TBC20PoolHistoryRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -408,7 +691,7 @@ func (s *FtService) GetPoolHistoryByPoolId(c *gin.Context) {
 	response, err := s.ftLogic.GetPoolHistory(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理池子历史记录查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询池子历史记录失败"))
+		respondError(c, err, "查询池子历史记录失败")
 		return
 	}
 
@@ -425,7 +708,7 @@ func (s *FtService) GetPoolList(c *gin.Context) {
 	var req ft.TBC20PoolPageRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -435,7 +718,7 @@ func (s *FtService) GetPoolList(c *gin.Context) {
 	response, err := s.ftLogic.GetAllPoolList(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理交易池列表查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询交易池列表失败"))
+		respondError(c, err, "查询交易池列表失败")
 		return
 	}
 
@@ -452,7 +735,7 @@ func (s *FtService) GetHolderRankByContractId(c *gin.Context) {
 	var req ft.FtHolderRankRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
@@ -463,7 +746,7 @@ func (s *FtService) GetHolderRankByContractId(c *gin.Context) {
 	response, err := s.ftLogic.GetFtHolderRank(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理代币持有者排名查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询代币持有者排名失败"))
+		respondError(c, err, "查询代币持有者排名失败")
 		return
 	}
 
@@ -480,14 +763,21 @@ func (s *FtService) GetFtUtxoByCombineScript(c *gin.Context) {
 	var req ft.FtUtxoCombineScriptRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定可选的查询参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
 		return
 	}
 
 	// 验证请求参数
 	if err := req.Validate(); err != nil {
 		log.ErrorWithContextf(ctx, "验证请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, err.Error()))
+		c.Error(apperror.InvalidParam(err.Error()))
 		return
 	}
 
@@ -498,7 +788,7 @@ func (s *FtService) GetFtUtxoByCombineScript(c *gin.Context) {
 	response, err := s.ftLogic.GetFtUtxosByCombineScript(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理基于合并脚本的FT UTXO查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询FT UTXO失败"))
+		respondError(c, err, "查询FT UTXO失败")
 		return
 	}
 
@@ -515,14 +805,14 @@ func (s *FtService) GetFtBalanceByCombineScript(c *gin.Context) {
 	var req ft.FtBalanceCombineScriptRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
 		return
 	}
 
 	// 验证请求参数
 	if err := req.Validate(); err != nil {
 		log.ErrorWithContextf(ctx, "验证请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, err.Error()))
+		c.Error(apperror.InvalidParam(err.Error()))
 		return
 	}
 
@@ -533,7 +823,7 @@ func (s *FtService) GetFtBalanceByCombineScript(c *gin.Context) {
 	response, err := s.ftLogic.GetFtBalanceByCombineScript(ctx, &req)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理基于脚本的FT余额查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询FT余额失败"))
+		respondError(c, err, "查询FT余额失败")
 		return
 	}
 
@@ -550,27 +840,227 @@ func (s *FtService) GetLPUnspentByScriptHash(c *gin.Context) {
 	var req ft.LPUnspentByScriptHashRequest
 	if err := c.ShouldBindUri(&req); err != nil {
 		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, "无效的请求参数"))
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 绑定可选的过滤和分页参数
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定查询参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的查询参数"))
 		return
 	}
 
 	// 验证参数
 	if err := req.Validate(); err != nil {
 		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeInvalidParams, err.Error()))
+		c.Error(apperror.InvalidParam(err.Error()))
 		return
 	}
 
-	log.InfoWithContextf(ctx, "获取LP未花费交易输出请求: 脚本哈希=%s", req.ScriptHash)
+	log.InfoWithContextf(ctx, "获取LP未花费交易输出请求: 脚本哈希=%s, 最小余额=%d, 页码=%d, 每页大小=%d",
+		req.ScriptHash, req.MinBalance, req.Page, req.Size)
 
-	// 调用逻辑层处理业务
-	response, err := s.ftLogic.GetLPUnspentByScriptHash(ctx, &req)
+	// LP输出可能很多，查询结果逐条写入响应
+	writer := &lpUnspentStreamWriter{c: c}
+	total, err := s.ftLogic.StreamLPUnspentByScriptHash(ctx, &req, writer.write)
 	if err != nil {
 		log.ErrorWithContextf(ctx, "处理LP未花费交易输出查询失败: %v", err)
-		c.JSON(http.StatusOK, utility.NewErrorResponse(constant.CodeServerError, "查询LP未花费交易输出失败"))
+		if writer.started {
+			// 响应已经开始写出，中断连接使客户端收到不完整的响应而不是被截断的列表
+			panic(http.ErrAbortHandler)
+		}
+		respondError(c, err, "查询LP未花费交易输出失败")
+		return
+	}
+
+	// 未指定每页记录数时返回全部记录，不输出分页元数据
+	var meta *utility.PageMeta
+	if req.Size > 0 {
+		meta = utility.NewPageMeta(req.Page, req.Size, int64(total))
+	}
+	if err := writer.finish(total, meta); err != nil {
+		log.WarnWithContextf(ctx, "写出LP未花费交易输出响应失败: %v", err)
+	}
+}
+
+// GetLPUnspentByScriptHashes 批量获取多个脚本哈希的LP未花费交易输出
+// 路由: POST /v1/tbc/main/ft/lp/unspent/by/script/hashes
+func (s *FtService) GetLPUnspentByScriptHashes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定JSON请求体
+	var req ft.LPUnspentByScriptHashesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定JSON请求体失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	log.InfoWithContextf(ctx, "批量获取LP未花费交易输出请求: 脚本哈希数量=%d", len(req.ScriptHashes))
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.GetLPUnspentByScriptHashes(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理批量LP未花费交易输出查询失败: %v", err)
+		respondError(c, err, "批量查询LP未花费交易输出失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// GetFtPortfolioByAddress 获取地址持有的代币及其TBC和法币估值
+// 路由: GET /v1/tbc/main/ft/portfolio/address/:address
+func (s *FtService) GetFtPortfolioByAddress(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.FtPortfolioAddressRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	log.InfoWithContextf(ctx, "获取地址FT资产估值请求: 地址=%s", req.Address)
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.GetFtPortfolioByAddress(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "处理地址FT资产估值查询失败: %v", err)
+		respondError(c, err, "查询地址FT资产估值失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// ImportTokenRegistry 导入人工维护的代币元数据（图标、官网、描述、标签、社交媒体）
+// 路由: POST /v1/tbc/main/ft/token/registry/import
+func (s *FtService) ImportTokenRegistry(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定JSON请求体
+	var req ft.TokenRegistryImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定JSON请求体失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	log.InfoWithContextf(ctx, "导入代币元数据请求: 代币数量=%d", len(req.Tokens))
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.ImportTokenRegistry(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "导入代币元数据失败: %v", err)
+		respondError(c, err, "导入代币元数据失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateTokenVerificationChallenge 获取代币创建者核验挑战，挑战在有效期内只能用于该代币
+// 路由: POST /v1/tbc/main/ft/token/:contract_id/verification/challenge
+func (s *FtService) CreateTokenVerificationChallenge(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.TokenVerificationChallengeRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.CreateTokenVerificationChallenge(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "生成代币核验挑战失败: %v", err)
+		if errors.Is(err, ftlogic.ErrVerificationFailed) {
+			c.Error(apperror.InvalidParam(err.Error()))
+			return
+		}
+		respondError(c, err, "生成代币核验挑战失败")
 		return
 	}
 
 	// 返回成功响应
 	c.JSON(http.StatusOK, response)
 }
+
+// VerifyTokenCreator 校验创建者对核验挑战的签名，通过后代币信息和列表中显示已核验
+// 路由: POST /v1/tbc/main/ft/token/:contract_id/verification
+func (s *FtService) VerifyTokenCreator(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 绑定请求参数
+	var req ft.TokenVerificationRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定请求参数失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.ErrorWithContextf(ctx, "绑定JSON请求体失败: %v", err)
+		c.Error(apperror.InvalidParam("无效的请求参数"))
+		return
+	}
+
+	// 验证参数
+	if err := req.Validate(); err != nil {
+		log.ErrorWithContextf(ctx, "参数验证失败: %v", err)
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	// 调用逻辑层处理业务
+	response, err := s.ftLogic.VerifyTokenCreator(ctx, &req)
+	if err != nil {
+		log.ErrorWithContextf(ctx, "代币创建者核验失败: %v", err)
+		if errors.Is(err, ftlogic.ErrVerificationFailed) {
+			c.Error(apperror.InvalidParam(err.Error()))
+			return
+		}
+		respondError(c, err, "代币创建者核验失败")
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
+}
+
+// respondError 记录逻辑层错误，由错误处理中间件按原因转换为记录不存在、上游超时等响应，分页参数超限时为参数错误
+func respondError(c *gin.Context, err error, message string) {
+	if errors.Is(err, utility.ErrPageSizeTooLarge) || errors.Is(err, utility.ErrInvalidPageSize) {
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+	c.Error(apperror.Internal(message, err))
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+
+	"ginproject/entity/consistency"
+	consistencylogic "ginproject/logic/consistency"
+	"ginproject/logic/job"
+	"ginproject/middleware/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartConsistencyCheck 提交一致性检查任务，抽样比较ElectrumX路径和数据库路径的结果，用于索引器发布前的核验
+// @Router /v1/tbc/main/admin/consistency/check [post]
+func (s *HealthService) StartConsistencyCheck(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req consistency.CheckRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := consistencylogic.StartCheck(ctx, &req)
+	if err != nil {
+		respondConsistencyError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"job": snapshot,
+	})
+}
+
+// GetConsistencyCheck 查询一致性检查任务的进度和报告
+// @Router /v1/tbc/main/admin/consistency/check/{job_id} [get]
+func (s *HealthService) GetConsistencyCheck(c *gin.Context) {
+	snapshot, err := consistencylogic.GetCheck(c.Request.Context(), c.Param("job_id"))
+	if err != nil {
+		respondConsistencyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"job": snapshot,
+	})
+}
+
+// respondConsistencyError 将一致性检查相关错误转换为HTTP状态码
+func respondConsistencyError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, job.ErrJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, job.ErrTooManyJobs):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, consistencylogic.ErrCheckUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	log.ErrorWithContext(c.Request.Context(), "一致性检查请求失败", "status:", status, "错误:", err)
+	c.JSON(status, gin.H{"error": err.Error()})
+}
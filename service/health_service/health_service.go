@@ -2,8 +2,23 @@ package health
 
 import (
 	"net/http"
+	"strconv"
 
+	alertEntity "ginproject/entity/alert"
+	schedulerEntity "ginproject/entity/scheduler"
+	alertlogic "ginproject/logic/alert"
+	"ginproject/logic/scheduler"
+	"ginproject/middleware/auth"
+	"ginproject/middleware/fingerprint"
+	"ginproject/middleware/healthscore"
 	"ginproject/middleware/log"
+	"ginproject/repo/cache"
+	"ginproject/repo/eventbus"
+	"ginproject/repo/rpc/async"
+	"ginproject/repo/rpc/blockchain"
+	"ginproject/repo/rpc/electrumx"
+	"ginproject/repo/rpc/rpctrace"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,11 +32,174 @@ func NewHealthService() *HealthService {
 	return &HealthService{}
 }
 
+// RegisterRoutes 注册HealthService的路由
+func (s *HealthService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/health", s.HealthCheck, "健康检查", registry.WithCost(registry.CostLight))
+	r.GET("/health/score", s.GetHealthScore, "获取综合健康评分，可作为负载均衡权重", registry.WithResponse(healthscore.Score{}), registry.WithCost(registry.CostLight))
+	r.GET("/admin/caches", s.GetCacheStats, "获取各缓存的命中统计", registry.WithQuery("top"), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.POST("/admin/readonly", s.SetReadOnly, "运行时切换只读模式", registry.WithQuery("enabled"), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/requests", s.GetRequestStats, "获取当前统计窗口内请求数最多的请求指纹", registry.WithQuery("top"), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/upstreams", s.GetUpstreamStatus, "获取各上游服务器的健康状态和进行中的异步上游调用", registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/slowlog", s.GetSlowLog, "获取最慢的请求及其期间的上游调用", registry.WithQuery("top"), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/jobs", s.GetJobStatuses, "获取周期任务的运行状态", registry.WithResponse([]schedulerEntity.JobStatus{}), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/alerts", s.GetAlertStatuses, "获取告警规则在本实例上的评估状态", registry.WithResponse([]alertEntity.RuleStatus{}), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/events", s.GetEventStats, "获取事件总线各主题和订阅的状态", registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.POST("/admin/events/replay", s.ReplayEvents, "将消费者移动到指定偏移量重放事件", registry.WithQuery("consumer", "topic", "offset"), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.POST("/admin/consistency/check", s.StartConsistencyCheck, "提交ElectrumX与数据库结果的一致性抽样检查任务", registry.WithQuery("addresses", "ft_holdings", "settle_depth"), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.GET("/admin/consistency/check/:job_id", s.GetConsistencyCheck, "查询一致性检查任务的进度和报告", registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+}
+
 // HealthCheck 健康检查
 func (s *HealthService) HealthCheck(c *gin.Context) {
 	ctx := c.Request.Context()
 	log.InfoWithContext(ctx, "HealthCheck")
 	c.JSON(http.StatusOK, gin.H{
-		"status": "Turing API is running.",
+		"status":    "Turing API is running.",
+		"read_only": auth.IsReadOnly(),
+	})
+}
+
+// GetHealthScore 返回由上游延迟、错误率、连接池占用和索引进度计算的综合健康评分
+// 评分同时写入X-Health-Score响应头，便于只读取响应头的负载均衡器使用
+func (s *HealthService) GetHealthScore(c *gin.Context) {
+	score := healthscore.Current()
+	c.Header("X-Health-Score", strconv.Itoa(score.Score))
+	c.JSON(http.StatusOK, score)
+}
+
+// SetReadOnly 运行时切换只读模式，集群模式下对所有实例生效并在重启后保持，否则重启后恢复为配置文件的设置
+func (s *HealthService) SetReadOnly(c *gin.Context) {
+	ctx := c.Request.Context()
+	enabled, err := strconv.ParseBool(c.Query("enabled"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "enabled参数必须为true或false"})
+		return
+	}
+
+	if err := auth.SwitchReadOnly(ctx, enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.WarnWithContext(ctx, "只读模式已切换", "enabled:", enabled, "ip:", c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{
+		"read_only": enabled,
+	})
+}
+
+// 缓存统计默认和最多返回的热点键数量
+const (
+	defaultCacheTopKeys = 10
+	maxCacheTopKeys     = 100
+)
+
+// GetCacheStats 返回各缓存的大小、命中率和热点键，用于调整缓存容量和有效期
+func (s *HealthService) GetCacheStats(c *gin.Context) {
+	top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(defaultCacheTopKeys)))
+	if err != nil || top < 0 || top > maxCacheTopKeys {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top参数必须为0到100之间的整数"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"caches": cache.AllStats(top),
+	})
+}
+
+// 请求指纹统计默认和最多返回的指纹数量
+const (
+	defaultRequestTop = 20
+	maxRequestTop     = 1000
+)
+
+// GetRequestStats 返回当前统计窗口内请求数最多的请求指纹，用于发现抓取等异常访问模式
+func (s *HealthService) GetRequestStats(c *gin.Context) {
+	top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(defaultRequestTop)))
+	if err != nil || top <= 0 || top > maxRequestTop {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top参数必须为1到1000之间的整数"})
+		return
+	}
+
+	c.JSON(http.StatusOK, fingerprint.Top(top))
+}
+
+// 慢请求日志默认和最多返回的请求数量
+const (
+	defaultSlowLogTop = 20
+	maxSlowLogTop     = 200
+)
+
+// GetSlowLog 返回路由指标中最慢的请求和同一trace中的节点、ElectrumX调用，以及最近的上游慢调用
+// 请求的trace ID与响应头X-Trace-ID和日志中的trace_id一致
+func (s *HealthService) GetSlowLog(c *gin.Context) {
+	top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(defaultSlowLogTop)))
+	if err != nil || top <= 0 || top > maxSlowLogTop {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top参数必须为1到200之间的整数"})
+		return
+	}
+
+	requests, err := rpctrace.SlowLog(c.Request.Context(), top)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"upstream": rpctrace.SlowCalls(top),
+	})
+}
+
+// GetUpstreamStatus 返回多服务器故障切换中各服务器的健康状态，未启用故障切换的上游为null
+// producers列出各异步上游调用的运行数和最长运行时长，调用方放弃后仍长时间运行的调用可据此排查
+// breakers列出各上游按方法的断路器状态、并发数和累计拒绝数
+func (s *HealthService) GetUpstreamStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"node":      blockchain.NodeStatus(),
+		"electrumx": electrumx.ServerStatus(),
+		"producers": async.Stats(),
+		"breakers": gin.H{
+			"node":      blockchain.BreakerStats(),
+			"electrumx": electrumx.BreakerStats(),
+		},
+	})
+}
+
+// GetJobStatuses 返回各周期任务的周期和最近一次运行结果，共享任务的结果可能来自其它实例
+func (s *HealthService) GetJobStatuses(c *gin.Context) {
+	c.JSON(http.StatusOK, scheduler.Statuses(c.Request.Context()))
+}
+
+// GetAlertStatuses 返回各告警规则的最近一次评估结果，每个实例按各自观察到的指标评估
+func (s *HealthService) GetAlertStatuses(c *gin.Context) {
+	c.JSON(http.StatusOK, alertlogic.Statuses())
+}
+
+// GetEventStats 返回事件总线各主题的偏移量范围和各消费者的投递进度
+func (s *HealthService) GetEventStats(c *gin.Context) {
+	topics, subscriptions := eventbus.Default().Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"topics":        topics,
+		"subscriptions": subscriptions,
+	})
+}
+
+// ReplayEvents 将消费者在主题上的位置移动到指定偏移量，之后的事件会重新投递
+func (s *HealthService) ReplayEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	consumer, topic := c.Query("consumer"), c.Query("topic")
+	offset, err := strconv.ParseUint(c.Query("offset"), 10, 64)
+	if consumer == "" || topic == "" || err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "consumer和topic不能为空，offset必须为非负整数"})
+		return
+	}
+
+	if err := eventbus.Default().Seek(consumer, topic, offset); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	log.WarnWithContext(ctx, "事件重放", "consumer:", consumer, "topic:", topic, "offset:", offset, "ip:", c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{
+		"consumer": consumer,
+		"topic":    topic,
+		"offset":   offset,
 	})
 }
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"expvar"
+	"net/http/pprof"
+
+	"ginproject/entity/config"
+	"ginproject/middleware/fingerprint"
+	"ginproject/middleware/recovery"
+	"ginproject/middleware/trace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalEnabled 判断是否配置了内部监听
+func InternalEnabled() bool {
+	return config.GetConfig().GetServerConfig().Internal.Port > 0
+}
+
+// NewInternalRouter 创建内部监听使用的路由，提供/metrics和可选的pprof接口
+func NewInternalRouter() *gin.Engine {
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(recovery.Recovery())
+	r.Use(trace.GinMiddleware())
+	r.Use(fingerprint.AccessLog())
+
+	// 进程指标，包括内存统计和各模块通过expvar发布的计数
+	r.GET("/metrics", gin.WrapH(expvar.Handler()))
+
+	if config.GetConfig().GetServerConfig().Internal.Pprof {
+		debug := r.Group("/debug/pprof")
+		debug.GET("/", gin.WrapF(pprof.Index))
+		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/profile", gin.WrapF(pprof.Profile))
+		debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/trace", gin.WrapF(pprof.Trace))
+		// allocs、heap、goroutine等命名profile
+		debug.GET("/:name", func(c *gin.Context) {
+			pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		})
+	}
+	return r
+}
@@ -1,8 +1,13 @@
 package mempool_service
 
 import (
+	"encoding/hex"
+	"ginproject/entity/mempool"
+	logic "ginproject/logic/mempool"
 	"ginproject/middleware/log"
+	"ginproject/repo/db"
 	"ginproject/repo/rpc/blockchain"
+	"ginproject/service/registry"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,7 +15,11 @@ import (
 
 // MempoolService 内存池服务接口
 type MempoolService interface {
+	RegisterRoutes(r *registry.Registry)
 	GetMemPoolTxs(c *gin.Context)
+	GetFeeHistogram(c *gin.Context)
+	GetMempoolInfo(c *gin.Context)
+	GetMempoolTx(c *gin.Context)
 }
 
 // mempoolService 内存池服务实现
@@ -21,6 +30,14 @@ func NewMempoolService() MempoolService {
 	return &mempoolService{}
 }
 
+// RegisterRoutes 注册MempoolService的路由
+func (s *mempoolService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/mempool/mempool/txs", s.GetMemPoolTxs, "获取内存池交易列表")
+	r.GET("/mempool/fee-histogram", s.GetFeeHistogram, "获取内存池费率直方图", registry.WithResponse(mempool.FeeHistogramResponse{}), registry.WithCost(registry.CostLight))
+	r.GET("/mempool/info", s.GetMempoolInfo, "获取内存池交易数量、大小和费率直方图", registry.WithResponse(mempool.MempoolInfoResponse{}), registry.WithCost(registry.CostLight))
+	r.GET("/mempool/tx/:txid", s.GetMempoolTx, "获取内存池中未确认交易的详情、手续费和祖先交易", registry.WithResponse(mempool.MempoolTxResponse{}))
+}
+
 // GetMemPoolTxs 获取内存池中的交易
 func (s *mempoolService) GetMemPoolTxs(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -37,3 +54,54 @@ func (s *mempoolService) GetMemPoolTxs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result.Result)
 }
+
+// GetFeeHistogram 获取内存池费率直方图
+func (s *mempoolService) GetFeeHistogram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := logic.GetFeeHistogram(ctx)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取内存池费率直方图失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取内存池费率直方图失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetMempoolInfo 获取内存池概况和费率直方图
+func (s *mempoolService) GetMempoolInfo(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := logic.GetMempoolInfo(ctx)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取内存池概况失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取内存池概况失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetMempoolTx 获取内存池中未确认交易的详情，交易不在内存池中时返回404
+func (s *mempoolService) GetMempoolTx(c *gin.Context) {
+	ctx := c.Request.Context()
+	txid := c.Param("txid")
+	if _, err := hex.DecodeString(txid); err != nil || len(txid) != 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "交易ID必须为64位十六进制字符串"})
+		return
+	}
+
+	resp, err := logic.GetMempoolTx(ctx, txid)
+	if err != nil {
+		if db.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "交易不在内存池中"})
+			return
+		}
+		log.ErrorWithContext(ctx, "获取内存池交易详情失败", "txid", txid, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取内存池交易详情失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
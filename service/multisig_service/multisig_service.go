@@ -6,12 +6,14 @@ import (
 	"ginproject/entity/multisig"
 	logic_multisig "ginproject/logic/multisig"
 	"ginproject/middleware/log"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
 
 // MultisigService 多签名服务接口
 type MultisigService interface {
+	RegisterRoutes(r *registry.Registry)
 	GetMultiWalletByAddress(c *gin.Context)
 }
 
@@ -23,6 +25,11 @@ func NewMultisigService() MultisigService {
 	return &multisigService{}
 }
 
+// RegisterRoutes 注册MultisigService的路由
+func (s *multisigService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/multisig/pubkeys/address/:address", s.GetMultiWalletByAddress, "根据地址获取多签名地址及其公钥列表")
+}
+
 // GetMultiWalletByAddress 根据地址获取多签名钱包信息
 func (s *multisigService) GetMultiWalletByAddress(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -1,12 +1,17 @@
 package nft_service
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"ginproject/entity/apperror"
 	"ginproject/entity/nft"
+	"ginproject/entity/utility"
 	nftLogic "ginproject/logic/nft"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,6 +28,23 @@ func NewNftService() *NftService {
 	}
 }
 
+// RegisterRoutes 注册NftService的路由
+func (s *NftService) RegisterRoutes(r *registry.Registry) {
+	// 余额、UTXO和历史类接口在响应头中附带链顶信息
+	withTip := registry.WithMiddleware(chaintip.Headers())
+
+	r.GET("/nft/collection/address/:address/page/:page/size/:size", s.GetCollectionsByAddress, "获取地址的NFT集合")
+	r.GET("/nft/address/:address/page/:page/size/:size", s.GetNftsByAddress, "获取地址的NFT资产", registry.WithQuery("if_extra_collection_info_needed", "min_confirmations"), withTip)
+	r.GET("/nft/script/hash/:script_hash/page/:page/size/:size", s.GetNftsByScriptHash, "获取脚本哈希的NFT资产", registry.WithQuery("min_confirmations"), withTip)
+	r.GET("/nft/collection/id/:collection_id/page/:page/size/:size", s.GetNftsByCollectionId, "获取集合的NFT资产", registry.WithQuery("sort", "order"), registry.Cacheable())
+	r.GET("/nft/search", s.SearchNfts, "按集合名称、NFT名称、创建者和属性搜索NFT", registry.WithQuery("collection", "name", "creator", "attr", "page", "size"), registry.WithResponse(nft.NftListResponse{}))
+	r.GET("/nft/history/address/:address/page/:page/size/:size", s.GetNftHistory, "获取地址的NFT交易历史", registry.WithQuery("from_height"), registry.WithCost(registry.CostHeavy), withTip)
+	r.GET("/nft/collections/page/:page/size/:size", s.GetAllCollections, "获取所有NFT集合", registry.Cacheable())
+	r.GET("/nft/collection/info/:collection_id", s.GetDetailCollectionInfo, "获取集合详细信息", registry.Cacheable(), registry.WithCost(registry.CostLight))
+	r.POST("/nft/infos/contract_ids", s.GetNftsByContractIds, "根据合约ID获取NFT信息", registry.WithCost(registry.CostHeavy))
+	r.GET("/nft/portfolio/address/:address", s.GetNftPortfolio, "获取地址的NFT持仓汇总")
+}
+
 // GetNftsByContractIds 根据合约ID获取NFT信息
 func (s *NftService) GetNftsByContractIds(c *gin.Context) {
 	var req nft.NftsByContractIdsRequest
@@ -38,10 +60,10 @@ func (s *NftService) GetNftsByContractIds(c *gin.Context) {
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetNftsByContractIds(c, req.ContractList, req.IfIconNeeded)
+	response, err := s.logic.GetNftsByContractIds(c.Request.Context(), req.ContractList, req.IfIconNeeded)
 	if err != nil {
 		log.ErrorWithContext(c, "获取合约ID列表NFT信息失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取合约ID列表NFT信息失败: " + err.Error()})
+		c.Error(apperror.Internal("获取合约ID列表NFT信息失败", err))
 		return
 	}
 
@@ -76,16 +98,16 @@ func (s *NftService) GetCollectionsByAddress(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "页码不能为负数"})
 		return
 	}
-	if size <= 0 || size > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "每页记录数必须在1-10000之间"})
+	if err := utility.ValidatePageSize(utility.PageEndpointNftCollectionByAddress, size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetCollectionByAddressPageSize(c, address, page, size)
+	response, err := s.logic.GetCollectionByAddressPageSize(c.Request.Context(), address, page, size)
 	if err != nil {
 		log.ErrorWithContext(c, "获取地址NFT集合失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取地址NFT集合失败: " + err.Error()})
+		c.Error(apperror.Internal("获取地址NFT集合失败", err))
 		return
 	}
 
@@ -127,16 +149,21 @@ func (s *NftService) GetNftsByAddress(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "页码不能为负数"})
 		return
 	}
-	if size <= 0 || size > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "每页记录数必须在1-10000之间"})
+	if err := utility.ValidatePageSize(utility.PageEndpointNftByAddress, size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	minConfirmations, err := utility.ParseMinConfirmations(c.Query("min_confirmations"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetNftByAddressPageSize(c, address, page, size, ifExtraCollectionInfo)
+	response, err := s.logic.GetNftByAddressPageSize(c.Request.Context(), address, page, size, ifExtraCollectionInfo, minConfirmations)
 	if err != nil {
 		log.ErrorWithContext(c, "获取地址NFT资产失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取地址NFT资产失败: " + err.Error()})
+		c.Error(apperror.Internal("获取地址NFT资产失败", err))
 		return
 	}
 
@@ -171,17 +198,22 @@ func (s *NftService) GetNftsByScriptHash(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "页码不能为负数"})
 		return
 	}
-	if size <= 0 || size > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "每页记录数必须在1-10000之间"})
+	if err := utility.ValidatePageSize(utility.PageEndpointNftByScriptHash, size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	minConfirmations, err := utility.ParseMinConfirmations(c.Query("min_confirmations"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	log.InfoWithContext(c, "获取脚本哈希NFT资产", "scriptHash", scriptHash, "page", page, "size", size)
 	// 调用API逻辑层
-	response, err := s.logic.GetNftByScriptHashPageSize(c, scriptHash, page, size)
+	response, err := s.logic.GetNftByScriptHashPageSize(c.Request.Context(), scriptHash, page, size, minConfirmations)
 	if err != nil {
 		log.ErrorWithContext(c, "获取脚本哈希NFT资产失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取脚本哈希NFT资产失败: " + err.Error()})
+		c.Error(apperror.Internal("获取脚本哈希NFT资产失败", err))
 		return
 	}
 
@@ -216,16 +248,46 @@ func (s *NftService) GetNftsByCollectionId(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "页码不能为负数"})
 		return
 	}
-	if size <= 0 || size > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "每页记录数必须在1-10000之间"})
+	if err := utility.ValidatePageSize(utility.PageEndpointNftByCollection, size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 可选的排序字段和方向，默认按集合索引升序
+	sort, err := nft.ParseNftSort(c.Query("sort"), c.Query("order"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetNftByCollectionIdPageSize(c, collectionId, page, size)
+	response, err := s.logic.GetNftByCollectionIdPageSize(c.Request.Context(), collectionId, sort, page, size)
 	if err != nil {
 		log.ErrorWithContext(c, "获取集合NFT资产失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取集合NFT资产失败: " + err.Error()})
+		c.Error(apperror.Internal("获取集合NFT资产失败", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchNfts 按集合名称、NFT名称、创建者地址和属性键值搜索NFT
+func (s *NftService) SearchNfts(c *gin.Context) {
+	var req nft.NftSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+
+	// 调用API逻辑层，参数校验在逻辑层完成
+	response, err := s.logic.SearchNfts(c.Request.Context(), &req)
+	if err != nil {
+		var nftErr *nft.NftError
+		if errors.As(err, &nftErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.ErrorWithContext(c, "搜索NFT失败", "error", err)
+		c.Error(apperror.Internal("搜索NFT失败", err))
 		return
 	}
 
@@ -260,16 +322,23 @@ func (s *NftService) GetNftHistory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "页码不能为负数"})
 		return
 	}
-	if size <= 0 || size > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "每页记录数必须在1-10000之间"})
+	if err := utility.ValidatePageSize(utility.PageEndpointNftHistory, size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 可选的起始高度参数，用于增量同步
+	fromHeight, err := strconv.ParseInt(c.DefaultQuery("from_height", "0"), 10, 64)
+	if err != nil || fromHeight < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "起始高度必须为非负整数"})
 		return
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetNftHistoryByAddress(c, address, page, size)
+	response, err := s.logic.GetNftHistoryByAddress(c.Request.Context(), address, page, size, fromHeight)
 	if err != nil {
 		log.ErrorWithContext(c, "获取NFT历史记录失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取NFT历史记录失败: " + err.Error()})
+		c.Error(apperror.Internal("获取NFT历史记录失败", err))
 		return
 	}
 
@@ -299,16 +368,16 @@ func (s *NftService) GetAllCollections(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "页码不能为负数"})
 		return
 	}
-	if size <= 0 || size > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "每页记录数必须在1-10000之间"})
+	if err := utility.ValidatePageSize(utility.PageEndpointNftAllCollections, size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetCollectionsByPageSize(c, page, size)
+	response, err := s.logic.GetCollectionsByPageSize(c.Request.Context(), page, size)
 	if err != nil {
 		log.ErrorWithContext(c, "获取所有NFT集合失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取所有NFT集合失败: " + err.Error()})
+		c.Error(apperror.Internal("获取所有NFT集合失败", err))
 		return
 	}
 
@@ -327,10 +396,33 @@ func (s *NftService) GetDetailCollectionInfo(c *gin.Context) {
 	}
 
 	// 调用API逻辑层
-	response, err := s.logic.GetDetailCollectionInfo(c, collectionId)
+	response, err := s.logic.GetDetailCollectionInfo(c.Request.Context(), collectionId)
 	if err != nil {
 		log.ErrorWithContext(c, "获取集合详细信息失败", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取集合详细信息失败: " + err.Error()})
+		c.Error(apperror.Internal("获取集合详细信息失败", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetNftPortfolio 获取地址按集合分组的NFT持仓汇总
+// @Router /v1/tbc/main/nft/portfolio/address/{address} [get]
+func (s *NftService) GetNftPortfolio(c *gin.Context) {
+	// 获取路径参数
+	address := c.Param("address")
+
+	// 参数校验
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "地址不能为空"})
+		return
+	}
+
+	// 调用API逻辑层
+	response, err := s.logic.GetNftPortfolioByAddress(c.Request.Context(), address)
+	if err != nil {
+		log.ErrorWithContext(c, "获取NFT持仓汇总失败", "error", err)
+		c.Error(apperror.Internal("获取NFT持仓汇总失败", err))
 		return
 	}
 
@@ -0,0 +1,316 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"ginproject/middleware/consistency"
+	"ginproject/middleware/costclass"
+	"ginproject/middleware/fingerprint"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CostClass 路由的开销等级，用于限流分级和指标标签
+type CostClass string
+
+const (
+	CostLight  CostClass = "light"  // 单次简单查询
+	CostNormal CostClass = "normal" // 常规查询
+	CostHeavy  CostClass = "heavy"  // 需要大量上游调用或逐笔解析交易的查询
+)
+
+// AuthScope 路由的访问权限范围
+type AuthScope string
+
+const (
+	ScopePublic AuthScope = "public" // 公开接口
+	ScopeWrite  AuthScope = "write"  // 会改变链上或服务端状态的接口
+	ScopeAdmin  AuthScope = "admin"  // 运维管理接口
+)
+
+// 上下文中保存路由元数据的键
+const routeContextKey = "route_meta"
+
+// Route 路由元数据
+type Route struct {
+	Method      string          // HTTP方法
+	Path        string          // 相对于API分组的路径
+	Handler     gin.HandlerFunc // 处理函数
+	Summary     string          // 接口说明
+	Query       []string        // 支持的查询参数，路径参数从Path中自动解析
+	Cacheable   bool            // 响应是否可缓存
+	Cost        CostClass       // 开销等级，为空时按normal处理
+	Auth        AuthScope       // 访问权限，为空时按public处理
+	LongLived   bool            // 长连接或长轮询接口，不受开销等级的请求超时和并发上限约束
+	Name        string          // 接口名称，生成客户端时用作方法名，为空时取处理函数名
+	Request     reflect.Type    // 请求体类型，为空时客户端按任意JSON处理
+	Response    reflect.Type    // 成功响应的类型，为空时客户端返回原始JSON
+	Middlewares []gin.HandlerFunc
+}
+
+// HandlerName 返回接口名称，未设置Name时取处理函数名，如 (*AddressService).GetAddressHistory 返回GetAddressHistory
+func (r Route) HandlerName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(r.Handler).Pointer()).Name()
+	// 方法值的函数名带有-fm后缀
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// PathParams 返回路径中的参数名
+func (r Route) PathParams() []string {
+	var params []string
+	for _, segment := range strings.Split(r.Path, "/") {
+		// 兼容 /hash:script_hash 这类参数不在段首的写法
+		if idx := strings.IndexAny(segment, ":*"); idx >= 0 {
+			params = append(params, segment[idx+1:])
+		}
+	}
+	return params
+}
+
+// Registry 路由注册表
+type Registry struct {
+	mu     sync.RWMutex
+	routes []Route
+	keys   map[string]struct{}
+	scopes map[AuthScope][]gin.HandlerFunc
+	costs  map[CostClass][]gin.HandlerFunc
+	cached []gin.HandlerFunc
+	inner  []gin.HandlerFunc
+}
+
+// New 创建路由注册表
+func New() *Registry {
+	return &Registry{
+		keys:   make(map[string]struct{}),
+		scopes: make(map[AuthScope][]gin.HandlerFunc),
+		costs:  make(map[CostClass][]gin.HandlerFunc),
+	}
+}
+
+// UseScope 为指定访问权限的所有路由添加中间件，如管理接口的令牌校验
+func (r *Registry) UseScope(scope AuthScope, middlewares ...gin.HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scopes[scope] = append(r.scopes[scope], middlewares...)
+}
+
+// UseCost 为指定开销等级的所有路由添加中间件，如按健康评分拒绝高开销请求
+// 开销中间件在访问权限中间件之后执行，未通过鉴权的请求不会计入限流
+func (r *Registry) UseCost(cost CostClass, middlewares ...gin.HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.costs[cost] = append(r.costs[cost], middlewares...)
+}
+
+// UseCacheable 为所有可缓存的GET路由添加中间件，如生成ETag并处理条件请求
+// 可缓存中间件在开销中间件之后执行，紧挨着路由自身的中间件和处理函数
+func (r *Registry) UseCacheable(middlewares ...gin.HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cached = append(r.cached, middlewares...)
+}
+
+// UseInner 为所有路由添加紧挨着处理函数的中间件，如将处理函数记录的错误转换为错误响应
+// 内层中间件在路由自身的中间件之后执行，外层的ETag等中间件看到的是其写入的最终响应
+func (r *Registry) UseInner(middlewares ...gin.HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inner = append(r.inner, middlewares...)
+}
+
+// Add 注册一条路由，同一方法和路径重复注册时panic，便于启动时尽早发现问题
+func (r *Registry) Add(route Route) {
+	if route.Handler == nil {
+		panic(fmt.Sprintf("路由 %s %s 缺少处理函数", route.Method, route.Path))
+	}
+	if route.Cost == "" {
+		route.Cost = CostNormal
+	}
+	if route.Auth == "" {
+		route.Auth = ScopePublic
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := route.Method + " " + route.Path
+	if _, ok := r.keys[key]; ok {
+		panic(fmt.Sprintf("路由 %s 重复注册", key))
+	}
+	r.keys[key] = struct{}{}
+	r.routes = append(r.routes, route)
+}
+
+// GET 注册GET路由
+func (r *Registry) GET(path string, handler gin.HandlerFunc, summary string, opts ...Option) {
+	r.Add(build(http.MethodGet, path, handler, summary, opts))
+}
+
+// POST 注册POST路由
+func (r *Registry) POST(path string, handler gin.HandlerFunc, summary string, opts ...Option) {
+	r.Add(build(http.MethodPost, path, handler, summary, opts))
+}
+
+// DELETE 注册DELETE路由
+func (r *Registry) DELETE(path string, handler gin.HandlerFunc, summary string, opts ...Option) {
+	r.Add(build(http.MethodDelete, path, handler, summary, opts))
+}
+
+// Routes 返回已注册路由的副本，按注册顺序排列
+func (r *Registry) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]Route, len(r.routes))
+	copy(routes, r.routes)
+	return routes
+}
+
+// Mount 将注册表中的路由挂载到gin路由组
+// 每个请求在进入处理函数前会把路由元数据和请求指纹写入上下文，供限流、指标和访问日志等中间件读取
+func (r *Registry) Mount(group *gin.RouterGroup) {
+	r.MountIf(group, nil)
+}
+
+// MountIf 只挂载keep返回true的路由，keep为nil时挂载全部路由
+func (r *Registry) MountIf(group *gin.RouterGroup, keep func(Route) bool) {
+	for _, route := range r.Routes() {
+		route := route
+		if keep != nil && !keep(route) {
+			continue
+		}
+		r.mu.RLock()
+		scoped := r.scopes[route.Auth]
+		costed := r.costs[route.Cost]
+		var cached []gin.HandlerFunc
+		if route.Cacheable && route.Method == http.MethodGet {
+			cached = r.cached
+		}
+		inner := r.inner
+		r.mu.RUnlock()
+
+		handlers := make([]gin.HandlerFunc, 0, len(scoped)+len(costed)+len(cached)+len(route.Middlewares)+len(inner)+3)
+		handlers = append(handlers, func(c *gin.Context) {
+			c.Set(routeContextKey, route)
+			if route.LongLived {
+				costclass.Exempt(c)
+			}
+			c.Next()
+		})
+		handlers = append(handlers, fingerprint.Middleware(route.Method, route.Path, route.Query))
+		handlers = append(handlers, scoped...)
+		handlers = append(handlers, costed...)
+		handlers = append(handlers, cached...)
+		handlers = append(handlers, route.Middlewares...)
+		handlers = append(handlers, inner...)
+		handlers = append(handlers, route.Handler)
+		group.Handle(route.Method, route.Path, handlers...)
+	}
+}
+
+// FromContext 获取当前请求匹配的路由元数据
+func FromContext(c *gin.Context) (Route, bool) {
+	value, ok := c.Get(routeContextKey)
+	if !ok {
+		return Route{}, false
+	}
+	route, ok := value.(Route)
+	return route, ok
+}
+
+// Option 路由元数据选项
+type Option func(*Route)
+
+// WithQuery 声明支持的查询参数
+func WithQuery(params ...string) Option {
+	return func(r *Route) {
+		r.Query = append(r.Query, params...)
+	}
+}
+
+// Cacheable 标记响应可缓存
+func Cacheable() Option {
+	return func(r *Route) {
+		r.Cacheable = true
+	}
+}
+
+// WithCost 设置开销等级
+func WithCost(cost CostClass) Option {
+	return func(r *Route) {
+		r.Cost = cost
+	}
+}
+
+// LongLived 标记为长连接或长轮询接口，请求时长由接口自身控制
+func LongLived() Option {
+	return func(r *Route) {
+		r.LongLived = true
+	}
+}
+
+// WithAuth 设置访问权限
+func WithAuth(scope AuthScope) Option {
+	return func(r *Route) {
+		r.Auth = scope
+	}
+}
+
+// WithName 设置接口名称，多个路由共用同一个处理函数时用于区分
+func WithName(name string) Option {
+	return func(r *Route) {
+		r.Name = name
+	}
+}
+
+// WithRequest 声明请求体类型，v为该类型的零值
+func WithRequest(v any) Option {
+	return func(r *Route) {
+		r.Request = reflect.TypeOf(v)
+	}
+}
+
+// WithResponse 声明成功响应的类型，v为该类型的零值
+func WithResponse(v any) Option {
+	return func(r *Route) {
+		r.Response = reflect.TypeOf(v)
+	}
+}
+
+// WithMiddleware 为单个路由添加中间件
+func WithMiddleware(middlewares ...gin.HandlerFunc) Option {
+	return func(r *Route) {
+		r.Middlewares = append(r.Middlewares, middlewares...)
+	}
+}
+
+// build 根据选项构造路由
+func build(method, path string, handler gin.HandlerFunc, summary string, opts []Option) Route {
+	route := Route{
+		Method:  method,
+		Path:    path,
+		Handler: handler,
+		Summary: summary,
+	}
+	for _, opt := range opts {
+		opt(&route)
+	}
+	return route
+}
+
+// Consistent 路由接受广播返回的after一致性令牌，等待令牌中的交易可见后再返回数据
+func Consistent() Option {
+	return func(r *Route) {
+		r.Query = append(r.Query, consistency.QueryParam)
+		r.Middlewares = append(r.Middlewares, consistency.After())
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	address_service "ginproject/service/address_service"
+	block_service "ginproject/service/block_service"
+	capability_service "ginproject/service/capability_service"
+	chain_info_service "ginproject/service/chain_info_service"
+	exchange_service "ginproject/service/exchange_service"
+	faucet_service "ginproject/service/faucet_service"
+	ft_service "ginproject/service/ft_service"
+	health_service "ginproject/service/health_service"
+	mempool_service "ginproject/service/mempool_service"
+	multisig_service "ginproject/service/multisig_service"
+	nft_service "ginproject/service/nft_service"
+	"ginproject/service/registry"
+	script_service "ginproject/service/script_service"
+	stats_service "ginproject/service/stats_service"
+	subscription_service "ginproject/service/subscription_service"
+	transaction_service "ginproject/service/transaction"
+	tx_broadcast_service "ginproject/service/tx_broadcast_service"
+	wallet_service "ginproject/service/wallet_service"
+)
+
+// APIPrefix 接口路径前缀
+const APIPrefix = "/v1/tbc/main"
+
+// RegisterServices 将各服务的路由注册到路由表，服务启动和客户端生成共用同一份路由
+func RegisterServices(reg *registry.Registry) {
+	// 健康检查、能力描述与交易所服务
+	health_service.NewHealthService().RegisterRoutes(reg)
+	exchange_service.NewExchangeService().RegisterRoutes(reg)
+	capability_service.NewCapabilityService(APIPrefix).RegisterRoutes(reg)
+
+	// FT与NFT服务
+	ft_service.NewFtService().RegisterRoutes(reg)
+	nft_service.NewNftService().RegisterRoutes(reg)
+
+	// 地址、脚本与多签服务
+	address_service.NewAddressService().RegisterRoutes(reg)
+	script_service.NewScriptService().RegisterRoutes(reg)
+	multisig_service.NewMultisigService().RegisterRoutes(reg)
+
+	// 区块、链信息与内存池服务
+	block_service.NewBlockService().RegisterRoutes(reg)
+	chain_info_service.NewChainInfoService().RegisterRoutes(reg)
+	mempool_service.NewMempoolService().RegisterRoutes(reg)
+	stats_service.NewStatsService().RegisterRoutes(reg)
+
+	// WebSocket订阅服务，未启用时接口返回404
+	subscription_service.NewSubscriptionService().RegisterRoutes(reg)
+
+	// 交易广播与交易服务
+	tx_broadcast_service.NewTxBroadcastService().RegisterRoutes(reg)
+	transaction_service.NewTransactionService().RegisterRoutes(reg)
+
+	// 跟踪钱包服务
+	wallet_service.NewWalletService().RegisterRoutes(reg)
+
+	// 测试网水龙头服务，未启用时接口返回404
+	faucet_service.NewFaucetService().RegisterRoutes(reg)
+}
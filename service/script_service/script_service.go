@@ -2,10 +2,14 @@ package script_service
 
 import (
 	"net/http"
+	"strconv"
 
 	"ginproject/entity/script"
+	scriptlogic "ginproject/logic/script"
+	"ginproject/middleware/chaintip"
 	"ginproject/middleware/log"
 	"ginproject/repo/rpc/electrumx"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +22,16 @@ func NewScriptService() *ScriptService {
 	return &ScriptService{}
 }
 
+// RegisterRoutes 注册ScriptService的路由
+func (s *ScriptService) RegisterRoutes(r *registry.Registry) {
+	// 余额、UTXO和历史类接口在响应头中附带链顶信息
+	withTip := registry.WithMiddleware(chaintip.Headers())
+
+	r.GET("/script/hash/:script_hash/unspent", s.GetScriptUnspent, "获取脚本哈希未花费交易输出", registry.Consistent(), withTip)
+	r.GET("/script/hash/:script_hash/history", s.GetScriptHistory, "获取脚本哈希历史交易", registry.WithQuery("from_height", "split"), registry.WithCost(registry.CostHeavy), registry.Consistent(), withTip)
+	r.POST("/script/decode", s.DecodeScript, "解码十六进制或ASM脚本", registry.WithRequest(script.DecodeScriptRequest{}), registry.WithResponse(script.DecodeScriptResponse{}), registry.WithCost(registry.CostLight))
+}
+
 // GetScriptUnspent 获取脚本的未花费交易输出
 func (s *ScriptService) GetScriptUnspent(c *gin.Context) {
 	// 获取上下文和脚本哈希参数
@@ -69,12 +83,28 @@ func (s *ScriptService) GetScriptHistory(c *gin.Context) {
 		return
 	}
 
+	// 可选的起始高度参数，用于增量同步
+	fromHeight, err := strconv.ParseInt(c.DefaultQuery("from_height", "0"), 10, 64)
+	if err != nil || fromHeight < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "起始高度必须为非负整数"})
+		return
+	}
+
+	// 可选的拆分参数，为true时按已确认和内存池两部分返回
+	split, err := strconv.ParseBool(c.DefaultQuery("split", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "split参数必须为true或false"})
+		return
+	}
+
 	// 记录API调用
 	log.InfoWithContext(ctx, "开始获取脚本历史记录",
-		"scriptHash", scriptHash)
+		"scriptHash", scriptHash,
+		"fromHeight", fromHeight,
+		"split", split)
 
 	// 调用RPC获取脚本历史记录
-	history, err := electrumx.GetScriptHashHistory(ctx, scriptHash)
+	history, err := electrumx.GetScriptHashHistoryFrom(ctx, scriptHash, fromHeight, 0)
 	if err != nil {
 		log.ErrorWithContext(ctx, "获取脚本历史记录失败",
 			"scriptHash", scriptHash,
@@ -87,5 +117,32 @@ func (s *ScriptService) GetScriptHistory(c *gin.Context) {
 	log.InfoWithContext(ctx, "成功获取脚本历史记录",
 		"scriptHash", scriptHash,
 		"count", len(history))
+	if split {
+		c.JSON(http.StatusOK, script.SplitHistory(scriptHash, history))
+		return
+	}
 	c.JSON(http.StatusOK, history)
 }
+
+// DecodeScript 解码十六进制或ASM脚本，返回类型、地址、代币持有者、多签组成和OP_RETURN数据
+func (s *ScriptService) DecodeScript(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req script.DecodeScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := scriptlogic.DecodeScript(ctx, &req)
+	if err != nil {
+		log.ErrorWithContext(ctx, "解码脚本失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解码脚本失败"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -12,25 +12,40 @@ import (
 	"ginproject/entity/config"
 	"ginproject/middleware/log"
 	"ginproject/repo/db"
+	"ginproject/repo/rpc/electrumx"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Server struct {
-	server *http.Server
+	server   *http.Server
+	internal *http.Server // 内部监听，未配置时为空
 }
 
-// CreateServer 创建HTTP服务器
-func CreateServer(r *gin.Engine) *Server {
+// TrustProxies 按配置设置路由受信任的反向代理，未配置时不信任任何代理，c.ClientIP()取连接的对端地址
+// 否则任何客户端都能通过伪造X-Forwarded-For绕过按IP的限流和水龙头冷却
+func TrustProxies(r *gin.Engine) error {
+	return r.SetTrustedProxies(config.GetConfig().GetServerConfig().TrustedProxies)
+}
+
+// CreateServer 创建HTTP服务器，internal不为空且配置了内部端口时同时创建内部监听
+func CreateServer(r *gin.Engine, internal *gin.Engine) *Server {
 	// 获取服务器配置
 	serverConfig := config.GetConfig().GetServerConfig()
 	addr := fmt.Sprintf("%s:%d", serverConfig.Host, serverConfig.Port)
-	return &Server{
+	s := &Server{
 		server: &http.Server{
 			Addr:    addr,
 			Handler: r,
 		},
 	}
+	if internal != nil && serverConfig.Internal.Port > 0 {
+		s.internal = &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", serverConfig.Internal.Host, serverConfig.Internal.Port),
+			Handler: internal,
+		}
+	}
+	return s
 }
 
 // Start 启动服务并处理优雅关闭
@@ -43,6 +58,15 @@ func (h *Server) Start() {
 			os.Exit(1)
 		}
 	}()
+	if h.internal != nil {
+		go func() {
+			log.Info("内部服务启动成功", "地址:", h.internal.Addr)
+			if err := h.internal.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("内部服务启动失败", "错误:", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// 等待中断信号并优雅地关闭服务器
 	h.WaitForInterruptAndShutdown()
@@ -80,6 +104,17 @@ func (h *Server) GracefulShutdown() {
 		log.Info("HTTP服务已关闭", "地址:", h.server.Addr)
 	}
 
+	if h.internal != nil {
+		if err := h.internal.Shutdown(ctx); err != nil {
+			log.Error("内部服务关闭时发生错误", "错误:", err, "地址:", h.internal.Addr)
+		}
+	}
+
+	// 关闭ElectrumX连接池
+	if err := electrumx.Shutdown(); err != nil {
+		log.Error("关闭ElectrumX连接池时发生错误", "错误:", err)
+	}
+
 	// 关闭数据库连接
 	db.Close()
 
@@ -0,0 +1,75 @@
+package stats_service
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ginproject/entity/analytics"
+	analyticslogic "ginproject/logic/analytics"
+	"ginproject/middleware/log"
+	"ginproject/service/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 未指定时间范围时默认统计最近一天，按小时聚合
+const (
+	defaultActivityRange    = 24 * time.Hour
+	defaultActivityInterval = 3600
+)
+
+// StatsService 链上统计服务接口
+type StatsService interface {
+	RegisterRoutes(r *registry.Registry)
+	GetActivity(c *gin.Context)
+}
+
+// statsService 链上统计服务实现
+type statsService struct{}
+
+// NewStatsService 创建链上统计服务实例
+func NewStatsService() StatsService {
+	return &statsService{}
+}
+
+// RegisterRoutes 注册StatsService的路由
+func (s *statsService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/stats/activity", s.GetActivity, "获取链上活跃度时间序列", registry.WithQuery("from", "to", "interval"), registry.WithResponse(analytics.ActivityResponse{}), registry.WithCost(registry.CostHeavy))
+}
+
+// GetActivity 获取链上活跃度时间序列，启用分析库时由分析库计算
+func (s *statsService) GetActivity(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	now := time.Now().Unix()
+	query := analytics.ActivityQuery{
+		From:     now - int64(defaultActivityRange/time.Second),
+		To:       now,
+		Interval: defaultActivityInterval,
+	}
+	for name, target := range map[string]*int64{"from": &query.From, "to": &query.To, "interval": &query.Interval} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": name + "必须为整数"})
+			return
+		}
+		*target = parsed
+	}
+	if err := query.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := analyticslogic.Activity(ctx, query)
+	if err != nil {
+		log.ErrorWithContext(ctx, "获取链上活跃度失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取链上活跃度失败"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,89 @@
+package subscription_service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"ginproject/entity/subscription"
+	logic "ginproject/logic/subscription"
+	"ginproject/middleware/log"
+	"ginproject/service/registry"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// SubscriptionService WebSocket订阅服务
+type SubscriptionService struct{}
+
+// NewSubscriptionService 创建新的WebSocket订阅服务实例
+func NewSubscriptionService() *SubscriptionService {
+	return &SubscriptionService{}
+}
+
+// RegisterRoutes 注册SubscriptionService的路由
+func (s *SubscriptionService) RegisterRoutes(r *registry.Registry) {
+	r.GET("/ws", s.Subscribe, "通过WebSocket订阅新区块头和地址活动", registry.WithCost(registry.CostLight), registry.LongLived())
+}
+
+// Subscribe 升级为WebSocket连接，客户端发送subscribe/unsubscribe请求，服务端推送订阅通知
+func (s *SubscriptionService) Subscribe(c *gin.Context) {
+	hub := logic.Default()
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "WebSocket订阅未启用"})
+		return
+	}
+
+	client, err := hub.Connect()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer client.Close()
+
+	// 不设置Handshake时不校验Origin，订阅接口只推送公开的链上数据
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		serveConn(ws, client)
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveConn 读取客户端请求并处理，写协程按顺序写出回复和通知，任一方向出错时关闭连接
+func serveConn(ws *websocket.Conn, client *logic.Client) {
+	ctx := ws.Request().Context()
+	defer ws.Close()
+
+	go func() {
+		defer ws.Close()
+		for {
+			select {
+			case data := <-client.Messages():
+				if err := websocket.Message.Send(ws, string(data)); err != nil {
+					client.Close()
+					return
+				}
+			case <-client.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		var req subscription.Request
+		if err := websocket.JSON.Receive(ws, &req); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				client.Send(subscription.NewErrorMessage(0, subscription.ErrInvalidRequest))
+				continue
+			}
+			if !errors.Is(err, io.EOF) {
+				log.WarnWithContext(ctx, "读取WebSocket请求失败", "error", err)
+			}
+			return
+		}
+		client.Send(client.Handle(ctx, &req))
+	}
+}
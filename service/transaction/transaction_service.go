@@ -4,9 +4,15 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"ginproject/entity/apperror"
+	mempoolEntity "ginproject/entity/mempool"
 	txEntity "ginproject/entity/transaction"
+	mempoolLogic "ginproject/logic/mempool"
 	txLogic "ginproject/logic/transaction"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/httpcache"
 	"ginproject/middleware/log"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,6 +25,17 @@ func NewTransactionService() *TransactionService {
 	return &TransactionService{}
 }
 
+// RegisterRoutes 注册TransactionService的路由
+func (s *TransactionService) RegisterRoutes(r *registry.Registry) {
+	r.POST("/tx/raw/decode", s.DecodeTxRaw, "解码原始交易", registry.WithRequest(txEntity.TxDecodeRawRequest{}), registry.WithResponse(txEntity.TxDecodeResponse{}), registry.WithCost(registry.CostLight))
+	// 交易内容由交易ID唯一确定，交易ID即为强ETag
+	byTxid := registry.WithMiddleware(httpcache.Immutable("txid"))
+	r.GET("/tx/hex/:txid", s.GetTxRawHex, "获取交易原始十六进制数据", registry.Cacheable(), registry.WithCost(registry.CostLight), registry.Consistent(), byTxid)
+	r.GET("/tx/hex/:txid/decode", s.DecodeTxByHash, "通过交易ID解码交易", registry.Cacheable(), registry.Consistent(), registry.WithMiddleware(chaintip.Headers()), byTxid)
+	r.POST("/tx/vins", s.GetTxVins, "获取交易输入数据", registry.WithCost(registry.CostHeavy))
+	r.GET("/tx/fee/estimate", s.EstimateFee, "估算多个确认目标的费率和按内存池积压调整的推荐费率", registry.WithQuery("blocks"), registry.WithResponse(mempoolEntity.FeeEstimateResponse{}), registry.Cacheable())
+}
+
 // BroadcastTxRaw 广播单笔原始交易
 // POST /tx/raw
 func (s *TransactionService) BroadcastTxRaw(c *gin.Context) {
@@ -119,6 +136,10 @@ func (s *TransactionService) DecodeTxByHash(c *gin.Context) {
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
+	// 未确认交易的区块信息和确认数会变化，不作为不可变资源缓存
+	if resp.BlockHash == "" {
+		httpcache.Revalidate(c)
+	}
 
 	// 返回结果
 	c.JSON(statusCode, resp)
@@ -148,3 +169,27 @@ func (s *TransactionService) GetTxVins(c *gin.Context) {
 	// 返回结果
 	c.JSON(statusCode, resp)
 }
+
+// EstimateFee 估算手续费，blocks为期望确认的区块数，默认6
+// GET /tx/fee/estimate?blocks=n
+func (s *TransactionService) EstimateFee(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req mempoolEntity.FeeEstimateRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(apperror.InvalidParam("blocks必须为整数"))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(apperror.InvalidParam(err.Error()))
+		return
+	}
+
+	resp, err := mempoolLogic.EstimateFee(ctx, &req)
+	if err != nil {
+		c.Error(apperror.Internal("估算手续费失败", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -2,10 +2,13 @@ package tx_broadcast_service
 
 import (
 	"net/http"
+	"strconv"
 
 	"ginproject/entity/broadcast"
 	logic "ginproject/logic/broadcast"
 	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/service/registry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,7 +21,17 @@ func NewTxBroadcastService() *TxBroadcastService {
 	return &TxBroadcastService{}
 }
 
-// BroadcastTxRaw 广播单笔原始交易
+// RegisterRoutes 注册TxBroadcastService的路由
+func (s *TxBroadcastService) RegisterRoutes(r *registry.Registry) {
+	single := []registry.Option{registry.WithQuery("dry_run"), registry.WithRequest(broadcast.TxBroadcastRequest{}), registry.WithResponse(broadcast.BroadcastResponse{}), registry.WithAuth(registry.ScopeWrite)}
+	r.POST("/broadcast/tx/raw", s.BroadcastTxRaw, "广播单笔原始交易", single...)
+	r.POST("/broadcast/txs/raw", s.BroadcastTxsRaw, "批量广播原始交易", registry.WithRequest(broadcast.TxsBroadcastRequest{}), registry.WithResponse(broadcast.TxsBroadcastResponse{}), registry.WithCost(registry.CostHeavy), registry.WithAuth(registry.ScopeWrite))
+	r.POST("/tx/raw", s.BroadcastTxRaw, "广播单笔原始交易", append(single, registry.WithName("BroadcastTxRawLegacy"))...)
+	r.GET("/admin/broadcast/failures", s.ListBroadcastFailures, "获取广播失败的交易记录", registry.WithQuery("status", "page", "size"), registry.WithResponse(broadcast.BroadcastFailuresResponse{}), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeAdmin))
+	r.POST("/admin/broadcast/failures/:id/replay", s.ReplayBroadcastFailure, "重新广播失败记录中的交易", registry.WithResponse(broadcast.ReplayFailureResponse{}), registry.WithAuth(registry.ScopeAdmin))
+}
+
+// BroadcastTxRaw 广播单笔原始交易，dry_run=true时只预检交易并返回拒绝原因，不广播
 func (s *TxBroadcastService) BroadcastTxRaw(c *gin.Context) {
 	// 获取上下文
 	ctx := c.Request.Context()
@@ -31,10 +44,24 @@ func (s *TxBroadcastService) BroadcastTxRaw(c *gin.Context) {
 		return
 	}
 
-	log.InfoWithContext(ctx, "广播单笔原始交易", "req", req)
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run参数无效: " + value})
+			return
+		}
+		dryRun = parsed
+	}
+
+	log.InfoWithContext(ctx, "广播单笔原始交易", "req", req, "dryRun", dryRun)
 
 	// 调用业务逻辑层处理请求
-	resp, statusCode, err := logic.BroadcastTxRaw(ctx, &req)
+	handle := logic.BroadcastTxRaw
+	if dryRun {
+		handle = logic.DryRunTxRaw
+	}
+	resp, statusCode, err := handle(ctx, &req)
 	if err != nil {
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -69,3 +96,48 @@ func (s *TxBroadcastService) BroadcastTxsRaw(c *gin.Context) {
 	// 返回结果
 	c.JSON(statusCode, resp)
 }
+
+// ListBroadcastFailures 获取广播失败的交易记录
+func (s *TxBroadcastService) ListBroadcastFailures(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req broadcast.BroadcastFailuresRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := logic.ListBroadcastFailures(ctx, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取广播失败记录失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReplayBroadcastFailure 重新广播失败记录中的交易，节点再次拒绝时响应中包含错误信息
+func (s *TxBroadcastService) ReplayBroadcastFailure(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req broadcast.ReplayFailureRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数无效: " + err.Error()})
+		return
+	}
+
+	resp, err := logic.ReplayBroadcastFailure(ctx, req.Id)
+	if err != nil {
+		if db.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.ErrorWithContext(ctx, "重放广播失败交易失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "重放广播失败交易失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
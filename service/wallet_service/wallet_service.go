@@ -0,0 +1,287 @@
+package wallet_service
+
+import (
+	"errors"
+	"net/http"
+
+	"ginproject/entity/wallet"
+	logic "ginproject/logic/wallet"
+	"ginproject/middleware/chaintip"
+	"ginproject/middleware/log"
+	"ginproject/repo/db"
+	"ginproject/service/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WalletService 跟踪钱包服务
+type WalletService struct{}
+
+// NewWalletService 创建新的跟踪钱包服务实例
+func NewWalletService() *WalletService {
+	return &WalletService{}
+}
+
+// RegisterRoutes 注册WalletService的路由
+func (s *WalletService) RegisterRoutes(r *registry.Registry) {
+	withTip := registry.WithMiddleware(chaintip.Headers())
+
+	r.POST("/wallet", s.CreateWallet, "创建跟踪钱包", registry.WithRequest(wallet.CreateWalletRequest{}), registry.WithResponse(wallet.WalletSummaryResponse{}), registry.WithCost(registry.CostHeavy), registry.WithAuth(registry.ScopeWrite))
+	r.POST("/wallet/descriptor/expand", s.PreviewDescriptor, "预览输出描述符展开的地址", registry.WithRequest(wallet.DescriptorImport{}), registry.WithResponse(wallet.DescriptorExpandResponse{}), registry.WithCost(registry.CostHeavy))
+	r.POST("/recovery/scan", s.ScanRecovery, "扫描候选地址或扩展公钥中有链上历史的地址", registry.WithRequest(wallet.RecoveryScanRequest{}), registry.WithResponse(wallet.RecoveryScanResponse{}), registry.WithCost(registry.CostHeavy))
+	r.GET("/wallet/:wallet_id/summary", s.GetWalletSummary, "获取跟踪钱包汇总数据", registry.WithResponse(wallet.WalletSummaryResponse{}), registry.WithCost(registry.CostLight), withTip)
+	r.GET("/wallet/:wallet_id/history", s.GetWalletHistory, "获取跟踪钱包交易历史", registry.WithQuery("page", "size"), registry.WithResponse(wallet.WalletHistoryResponse{}), registry.WithCost(registry.CostLight), withTip)
+	r.GET("/wallet/:wallet_id/addresses", s.GetWalletAddresses, "获取跟踪钱包地址的监听状态", registry.WithQuery("status", "page", "size"), registry.WithResponse(wallet.WalletAddressesResponse{}), registry.WithCost(registry.CostLight))
+	r.POST("/wallet/:wallet_id/deposit-addresses", s.RegisterDepositAddresses, "登记带过期策略的充值地址", registry.WithRequest(wallet.RegisterDepositAddressesRequest{}), registry.WithResponse(wallet.WalletAddressesResponse{}), registry.WithCost(registry.CostHeavy), registry.WithAuth(registry.ScopeWrite))
+	r.DELETE("/wallet/:wallet_id", s.DeleteWallet, "删除跟踪钱包", registry.WithAuth(registry.ScopeWrite))
+	r.POST("/wallet/:wallet_id/notification", s.SetWalletNotification, "设置跟踪钱包通知", registry.WithRequest(wallet.NotificationRequest{}), registry.WithResponse(wallet.NotificationResponse{}), registry.WithAuth(registry.ScopeWrite))
+	r.GET("/wallet/:wallet_id/notification", s.GetWalletNotification, "获取跟踪钱包通知设置", registry.WithResponse(wallet.NotificationResponse{}), registry.WithCost(registry.CostLight), registry.WithAuth(registry.ScopeWrite))
+	r.DELETE("/wallet/:wallet_id/notification", s.DeleteWalletNotification, "删除跟踪钱包通知设置", registry.WithAuth(registry.ScopeWrite))
+}
+
+// CreateWallet 创建跟踪钱包
+func (s *WalletService) CreateWallet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req wallet.CreateWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.CreateWallet(ctx, &req)
+	if err != nil {
+		if errors.Is(err, logic.ErrInvalidWallet) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.ErrorWithContext(ctx, "创建跟踪钱包失败", "错误:", err)
+		respondError(c, http.StatusInternalServerError, "创建跟踪钱包失败: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// PreviewDescriptor 预览输出描述符展开的地址，不创建钱包
+func (s *WalletService) PreviewDescriptor(c *gin.Context) {
+	var req wallet.DescriptorImport
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.PreviewDescriptor(&req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ScanRecovery 扫描候选地址或扩展公钥派生的地址，返回有链上历史的地址，用于恢复钱包
+func (s *WalletService) ScanRecovery(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req wallet.RecoveryScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.ScanRecovery(ctx, &req)
+	if err != nil {
+		if errors.Is(err, logic.ErrInvalidWallet) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.ErrorWithContext(ctx, "钱包恢复扫描失败", "错误:", err)
+		respondError(c, http.StatusInternalServerError, "钱包恢复扫描失败: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWalletSummary 获取跟踪钱包汇总数据
+func (s *WalletService) GetWalletSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req wallet.WalletIdRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.GetWalletSummary(ctx, req.WalletId)
+	if err != nil {
+		respondLookupError(c, err, "获取跟踪钱包汇总数据失败")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWalletHistory 获取跟踪钱包交易历史
+func (s *WalletService) GetWalletHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req wallet.WalletHistoryRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := logic.GetWalletHistory(ctx, &req)
+	if err != nil {
+		respondLookupError(c, err, "获取跟踪钱包交易历史失败")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWalletAddresses 获取跟踪钱包地址的监听状态
+func (s *WalletService) GetWalletAddresses(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req wallet.WalletAddressesRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := logic.GetWalletAddresses(ctx, &req)
+	if err != nil {
+		respondLookupError(c, err, "获取跟踪钱包地址失败")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RegisterDepositAddresses 向跟踪钱包登记充值地址，到期或收到充值后自动停止监听
+func (s *WalletService) RegisterDepositAddresses(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var uri wallet.WalletIdRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	var req wallet.RegisterDepositAddressesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.RegisterDepositAddresses(ctx, uri.WalletId, &req)
+	if err != nil {
+		if errors.Is(err, logic.ErrInvalidWallet) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondLookupError(c, err, "登记充值地址失败")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteWallet 删除跟踪钱包
+func (s *WalletService) DeleteWallet(c *gin.Context) {
+	var req wallet.WalletIdRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	if err := logic.DeleteWallet(c.Request.Context(), req.WalletId); err != nil {
+		respondLookupError(c, err, "删除跟踪钱包失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "success"})
+}
+
+// SetWalletNotification 设置跟踪钱包的即时通知或每日汇总通知
+func (s *WalletService) SetWalletNotification(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var uri wallet.WalletIdRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+	var req wallet.NotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.SetWalletNotification(ctx, uri.WalletId, &req)
+	if err != nil {
+		if errors.Is(err, logic.ErrInvalidWallet) || errors.Is(err, logic.ErrNotifierUnavailable) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondLookupError(c, err, "设置跟踪钱包通知失败")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWalletNotification 获取跟踪钱包的通知设置
+func (s *WalletService) GetWalletNotification(c *gin.Context) {
+	var req wallet.WalletIdRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	resp, err := logic.GetWalletNotification(c.Request.Context(), req.WalletId)
+	if err != nil {
+		respondLookupError(c, err, "获取跟踪钱包通知设置失败")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteWalletNotification 删除跟踪钱包的通知设置
+func (s *WalletService) DeleteWalletNotification(c *gin.Context) {
+	var req wallet.WalletIdRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "请求参数无效: "+err.Error())
+		return
+	}
+
+	if err := logic.DeleteWalletNotification(c.Request.Context(), req.WalletId); err != nil {
+		respondLookupError(c, err, "删除跟踪钱包通知设置失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "success"})
+}
+
+// respondError 返回错误响应
+func respondError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"code": status, "message": message})
+}
+
+// respondLookupError 钱包不存在时返回404，其他错误返回500
+func respondLookupError(c *gin.Context, err error, message string) {
+	if db.IsNotFound(err) {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	log.ErrorWithContext(c.Request.Context(), message, "错误:", err)
+	respondError(c, http.StatusInternalServerError, message+": "+err.Error())
+}
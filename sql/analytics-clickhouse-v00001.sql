@@ -0,0 +1,17 @@
+-- ClickHouse分析库的交易摘要表，由服务在出块后写入，按(height, txid)去重，重复写入同一区块不会产生重复数据
+CREATE DATABASE IF NOT EXISTS tbc_analytics;
+
+CREATE TABLE IF NOT EXISTS tbc_analytics.tx_summaries (
+    height UInt64 COMMENT '区块高度',
+    block_time DateTime COMMENT '区块时间',
+    txid FixedString(64) COMMENT '交易哈希',
+    tx_index UInt32 COMMENT '交易在区块中的位置',
+    tx_type LowCardinality(String) COMMENT '交易类型',
+    size UInt32 COMMENT '交易大小(字节)',
+    vin_count UInt32 COMMENT '输入数量',
+    vout_count UInt32 COMMENT '输出数量',
+    total_out Float64 COMMENT '输出总额(TBC)',
+    addresses Array(String) COMMENT '输出中出现的地址'
+) ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMM(block_time)
+ORDER BY (height, txid);
@@ -0,0 +1,14 @@
+-- 代币元数据登记表，保存链上创世数据之外的人工维护信息
+CREATE TABLE IF NOT EXISTS TBC20721.token_registry (
+    ft_contract_id CHAR(64) NOT NULL COMMENT '代币合约ID',
+    logo_url VARCHAR(255) NOT NULL DEFAULT '' COMMENT '代币图标URL',
+    website VARCHAR(255) NOT NULL DEFAULT '' COMMENT '项目官网',
+    description TEXT COMMENT '项目描述',
+    tags VARCHAR(255) NOT NULL DEFAULT '' COMMENT '标签，逗号分隔',
+    socials TEXT COMMENT '社交媒体链接，JSON对象，如：{"twitter":"https://..."}',
+    verified BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否经过人工核验',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录更新时间',
+    PRIMARY KEY (ft_contract_id),
+    INDEX idx_verified (verified)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='代币元数据登记表';
@@ -0,0 +1,36 @@
+-- 跟踪钱包表，保存钱包的汇总数据，由后台同步任务定期刷新
+CREATE TABLE IF NOT EXISTS TBC20721.tracked_wallets (
+    wallet_id CHAR(32) NOT NULL COMMENT '钱包ID',
+    name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '钱包名称',
+    confirmed BIGINT NOT NULL DEFAULT 0 COMMENT '已确认余额(聪)',
+    unconfirmed BIGINT NOT NULL DEFAULT 0 COMMENT '未确认余额(聪)',
+    tx_count INT NOT NULL DEFAULT 0 COMMENT '去重后的交易数量',
+    last_activity_height BIGINT NOT NULL DEFAULT 0 COMMENT '最近一笔已确认交易的区块高度',
+    last_activity_time BIGINT NOT NULL DEFAULT 0 COMMENT '最近一笔已确认交易的区块时间戳',
+    synced_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近一次同步完成时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录更新时间',
+    PRIMARY KEY (wallet_id),
+    INDEX idx_synced_at (synced_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='跟踪钱包表';
+
+-- 跟踪钱包地址表，xpub派生的地址记录来源扩展公钥和派生路径
+CREATE TABLE IF NOT EXISTS TBC20721.tracked_wallet_addresses (
+    wallet_id CHAR(32) NOT NULL COMMENT '钱包ID',
+    address VARCHAR(64) NOT NULL COMMENT '地址',
+    script_hash CHAR(64) NOT NULL COMMENT '地址对应的脚本哈希',
+    source VARCHAR(128) NOT NULL DEFAULT '' COMMENT '来源扩展公钥，直接导入的地址为空',
+    derivation_path VARCHAR(32) NOT NULL DEFAULT '' COMMENT '相对扩展公钥的派生路径，如：0/5',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    PRIMARY KEY (wallet_id, address)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='跟踪钱包地址表';
+
+-- 跟踪钱包交易表，钱包内所有地址的历史交易合并去重后的结果
+CREATE TABLE IF NOT EXISTS TBC20721.tracked_wallet_txs (
+    wallet_id CHAR(32) NOT NULL COMMENT '钱包ID',
+    tx_hash CHAR(64) NOT NULL COMMENT '交易哈希',
+    height BIGINT NOT NULL COMMENT '交易所在区块高度，未确认交易小于等于0',
+    addresses TEXT COMMENT '交易涉及的钱包地址，逗号分隔',
+    PRIMARY KEY (wallet_id, tx_hash),
+    INDEX idx_wallet_height (wallet_id, height)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='跟踪钱包交易表';
@@ -0,0 +1,10 @@
+-- 地址与脚本哈希映射表，记录地址在各类脚本下对应的ElectrumX脚本哈希，用于按脚本哈希反查地址
+CREATE TABLE IF NOT EXISTS TBC20721.address_script_hashes (
+    script_hash CHAR(64) NOT NULL COMMENT '脚本哈希',
+    address VARCHAR(64) NOT NULL COMMENT '地址',
+    kind VARCHAR(16) NOT NULL COMMENT '脚本类型：p2pkh、nft、nft_collection、multisig、ft',
+    qualifier VARCHAR(64) NOT NULL DEFAULT '' COMMENT '附加限定，FT脚本为合约ID，其他类型为空',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    PRIMARY KEY (script_hash),
+    INDEX idx_address_kind (address, kind)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='地址与脚本哈希映射表';
@@ -0,0 +1,6 @@
+-- FT交易输出的创建和花费高度，由索引器写入，用于计算代币流通速度和休眠比例；历史数据回填前为NULL
+ALTER TABLE TBC20721.ft_txo_set
+    ADD COLUMN create_height BIGINT NULL COMMENT '输出所在交易的区块高度，未确认或未回填时为NULL',
+    ADD COLUMN spend_height BIGINT NULL COMMENT '花费该输出的交易的区块高度，未花费或未回填时为NULL',
+    ADD INDEX idx_contract_create_height (ft_contract_id, create_height),
+    ADD INDEX idx_contract_spend_height (ft_contract_id, spend_height);
@@ -0,0 +1,4 @@
+-- NFT UTXO所在交易的区块高度，由索引器写入，用于按确认数过滤；历史数据回填前为NULL
+ALTER TABLE TBC20721.nft_utxo_set
+    ADD COLUMN nft_utxo_height BIGINT NULL COMMENT 'NFT UTXO所在交易的区块高度，未确认或未回填时为NULL',
+    ADD INDEX idx_holder_utxo_height (nft_holder_script_hash, nft_utxo_height);
@@ -0,0 +1,13 @@
+-- 索引器按自然键幂等写入，崩溃后重放或重组回滚后重新应用区块不会产生重复记录
+-- 参与方表此前没有唯一约束，先清理历史重复记录，每组保留Fid最小的一条
+DELETE p1 FROM TBC20721.transaction_participants p1
+    JOIN TBC20721.transaction_participants p2
+    ON p1.tx_hash = p2.tx_hash AND p1.address = p2.address AND p1.role = p2.role AND p1.Fid > p2.Fid;
+
+ALTER TABLE TBC20721.transaction_participants
+    ADD UNIQUE KEY uk_tx_address_role (tx_hash, address, role);
+
+-- 重组回滚按高度恢复和删除代币输出
+ALTER TABLE TBC20721.ft_txo_set
+    ADD INDEX idx_create_height (create_height),
+    ADD INDEX idx_spend_height (spend_height);
@@ -0,0 +1,14 @@
+-- 广播失败记录表，节点拒绝或超时的交易保存原始数据，供运维在节点恢复后重放
+CREATE TABLE IF NOT EXISTS TBC20721.broadcast_failures (
+    id BIGINT NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+    txid CHAR(64) NOT NULL COMMENT '交易ID，由原始交易计算得到',
+    tx_hex LONGTEXT NOT NULL COMMENT '原始交易16进制数据',
+    error TEXT COMMENT '最近一次广播的错误信息',
+    attempts INT NOT NULL DEFAULT 1 COMMENT '广播失败次数，包括重放',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' COMMENT '状态：pending待处理，replayed已重放成功',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '首次失败时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录更新时间',
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_txid (txid),
+    INDEX idx_status_updated (status, updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='广播失败记录表';
@@ -0,0 +1,13 @@
+-- 地址活跃度索引表，记录地址首次收款和最近一次活动的区块，地址概要接口不再需要拉取完整历史
+-- 由事件总线上的交易事件维护，只统计接入之后发布的区块，历史数据需要通过重放事件回填
+CREATE TABLE IF NOT EXISTS TBC20721.address_activity (
+    address VARCHAR(64) NOT NULL COMMENT '地址',
+    first_seen_height BIGINT NOT NULL COMMENT '地址首次出现在交易输出中的区块高度',
+    first_seen_time BIGINT NOT NULL DEFAULT 0 COMMENT '首次出现的区块时间戳',
+    first_seen_tx CHAR(64) NOT NULL DEFAULT '' COMMENT '首次出现的交易哈希',
+    last_active_height BIGINT NOT NULL COMMENT '地址最近一次出现在交易输出中的区块高度',
+    last_active_time BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次出现的区块时间戳',
+    last_active_tx CHAR(64) NOT NULL DEFAULT '' COMMENT '最近一次出现的交易哈希',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录更新时间',
+    PRIMARY KEY (address)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='地址活跃度索引表';
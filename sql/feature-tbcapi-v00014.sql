@@ -0,0 +1,13 @@
+-- 跟踪钱包通知设置表，每个钱包一条，钱包有新的已确认交易时按设置即时通知或每日汇总通知
+CREATE TABLE IF NOT EXISTS TBC20721.tracked_wallet_notifications (
+    wallet_id CHAR(32) NOT NULL COMMENT '钱包ID',
+    channel VARCHAR(16) NOT NULL COMMENT '通知渠道，如：email',
+    target VARCHAR(255) NOT NULL COMMENT '通知接收方，email渠道为邮箱地址',
+    mode VARCHAR(16) NOT NULL COMMENT '通知方式：instant即时通知，daily每日汇总',
+    notified_height BIGINT NOT NULL DEFAULT 0 COMMENT '已通知的最高区块高度，只通知高于该高度的交易',
+    last_sent_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近一次检查并发送通知的时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录更新时间',
+    PRIMARY KEY (wallet_id),
+    INDEX idx_mode_last_sent (mode, last_sent_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='跟踪钱包通知设置表';
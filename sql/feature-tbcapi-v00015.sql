@@ -0,0 +1,6 @@
+-- 集合NFT列表按铸造时间、最后转移时间和转移次数排序使用的联合索引
+-- 排序字段相同时按集合索引同方向排序，降序查询可反向扫描索引
+ALTER TABLE TBC20721.nft_utxo_set
+ADD INDEX idx_collection_create (collection_id, nft_create_timestamp, collection_index),
+ADD INDEX idx_collection_last_transfer (collection_id, nft_last_transfer_timestamp, collection_index),
+ADD INDEX idx_collection_transfer_count (collection_id, nft_transfer_time_count, collection_index);
@@ -0,0 +1,10 @@
+-- 代币持有者排名快照表，由后台快照任务按周期保存每个代币前N名持有者的名次，排名接口据此计算名次变化
+-- 每次快照整体替换同一代币的记录，表中只保留最近一次快照
+CREATE TABLE IF NOT EXISTS TBC20721.ft_holder_rank_snapshot (
+    ft_contract_id CHAR(64) NOT NULL COMMENT '代币合约ID',
+    ft_holder_combine_script CHAR(42) NOT NULL COMMENT '持有者组合脚本',
+    holder_rank INT NOT NULL COMMENT '快照时的名次，从1开始',
+    ft_balance BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '快照时的代币余额',
+    snapshot_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '快照时间',
+    PRIMARY KEY (ft_contract_id, ft_holder_combine_script)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='代币持有者排名快照表';
@@ -0,0 +1,15 @@
+-- NFT属性索引表，由后台任务解析nft_utxo_set中的JSON属性写入，NFT搜索接口按属性键值过滤
+-- NFT属性铸造后不再变化，任务按创建时间增量索引，重复写入时忽略
+CREATE TABLE IF NOT EXISTS TBC20721.nft_attribute_index (
+    nft_contract_id CHAR(64) NOT NULL COMMENT 'NFT合约ID',
+    attr_key VARCHAR(64) NOT NULL COMMENT '属性键',
+    attr_value VARCHAR(128) NOT NULL COMMENT '属性值，非字符串值保存其JSON文本',
+    nft_create_timestamp INT NOT NULL DEFAULT 0 COMMENT 'NFT创建时间戳，用于增量索引的断点',
+    PRIMARY KEY (nft_contract_id, attr_key),
+    INDEX idx_attr_key_value (attr_key, attr_value),
+    INDEX idx_create_timestamp (nft_create_timestamp)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='NFT属性索引表';
+
+-- 属性索引任务按创建时间和合约ID顺序扫描NFT
+ALTER TABLE TBC20721.nft_utxo_set
+ADD INDEX idx_create_contract (nft_create_timestamp, nft_contract_id);
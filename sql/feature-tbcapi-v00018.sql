@@ -0,0 +1,14 @@
+-- 周期任务表，多实例部署时各实例通过条件更新抢占任务，同一任务同一时间只有一个实例运行
+-- locked_until为抢占的租约到期时间，实例异常退出后租约到期即可由其它实例接管
+CREATE TABLE IF NOT EXISTS TBC20721.scheduler_jobs (
+    job_name VARCHAR(64) NOT NULL COMMENT '任务名称',
+    owner VARCHAR(128) NOT NULL DEFAULT '' COMMENT '最近一次运行任务的实例',
+    locked_until DATETIME(3) NULL COMMENT '租约到期时间，未运行时为NULL',
+    last_started_at DATETIME(3) NULL COMMENT '最近一次开始运行的时间',
+    last_finished_at DATETIME(3) NULL COMMENT '最近一次结束运行的时间',
+    last_status VARCHAR(16) NOT NULL DEFAULT '' COMMENT '最近一次运行结果：succeeded/failed',
+    last_error TEXT NULL COMMENT '最近一次失败的原因',
+    last_duration_ms BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次运行耗时(毫秒)',
+    run_count BIGINT NOT NULL DEFAULT 0 COMMENT '累计运行次数',
+    PRIMARY KEY (job_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='周期任务表';
@@ -0,0 +1,11 @@
+-- 跟踪钱包地址的监听策略，交易所登记的充值地址可设置过期时间或在收到充值后停止监听
+-- 已停止监听的地址保留记录用于查询状态，但不再参与同步
+ALTER TABLE TBC20721.tracked_wallet_addresses
+ADD COLUMN watch_status VARCHAR(16) NOT NULL DEFAULT 'watching' COMMENT '监听状态：watching监听中、expired已过期、deposited已收到充值',
+ADD COLUMN expires_at TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间，为空表示不过期',
+ADD COLUMN stop_confirmations INT NOT NULL DEFAULT 0 COMMENT '首笔充值达到该确认数后停止监听，0表示不因充值停止',
+ADD COLUMN registered_height BIGINT NOT NULL DEFAULT 0 COMMENT '登记时的链顶高度，只有之后确认的交易视为充值',
+ADD COLUMN first_deposit_txid CHAR(64) NOT NULL DEFAULT '' COMMENT '首笔已确认充值的交易哈希',
+ADD COLUMN first_deposit_height BIGINT NOT NULL DEFAULT 0 COMMENT '首笔已确认充值的区块高度',
+ADD COLUMN stopped_at TIMESTAMP NULL DEFAULT NULL COMMENT '停止监听的时间',
+ADD INDEX idx_wallet_status (wallet_id, watch_status);
@@ -0,0 +1,19 @@
+-- 代币销毁记录表，转入销毁地址1BitcoinEaterAddressDontSendf59kuE的代币输出视为销毁
+-- 由后台任务消费代币转账事件写入，重复投递时按交易和输出索引去重
+CREATE TABLE IF NOT EXISTS TBC20721.ft_burns (
+    txid CHAR(64) NOT NULL COMMENT '销毁交易哈希',
+    vout INT NOT NULL COMMENT '转入销毁地址的输出索引',
+    ft_contract_id CHAR(64) NOT NULL COMMENT '代币合约ID',
+    amount BIGINT UNSIGNED NOT NULL COMMENT '销毁的代币数量，未按精度换算',
+    height BIGINT NOT NULL DEFAULT 0 COMMENT '销毁交易所在区块高度，历史回填且未知时为0',
+    block_time BIGINT NOT NULL DEFAULT 0 COMMENT '区块时间戳，历史回填时为0',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    PRIMARY KEY (txid, vout),
+    INDEX idx_contract_height (ft_contract_id, height)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='代币销毁记录表';
+
+-- 回填事件消费者启动前的销毁记录，销毁地址的组合脚本为其公钥哈希加类型00
+INSERT IGNORE INTO TBC20721.ft_burns (txid, vout, ft_contract_id, amount, height)
+SELECT utxo_txid, utxo_vout, ft_contract_id, ft_balance, IFNULL(create_height, 0)
+FROM TBC20721.ft_txo_set
+WHERE ft_holder_combine_script = '759d6677091e973b9e9d99f19c68fbf43e3f05f900';
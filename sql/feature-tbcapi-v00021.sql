@@ -0,0 +1,4 @@
+-- 跟踪钱包支持导入输出描述符，来源字段需要容纳含多个扩展公钥的多签描述符
+ALTER TABLE TBC20721.tracked_wallet_addresses
+    MODIFY COLUMN source VARCHAR(2048) NOT NULL DEFAULT '' COMMENT '来源扩展公钥或输出描述符，直接导入的地址为空',
+    MODIFY COLUMN derivation_path VARCHAR(32) NOT NULL DEFAULT '' COMMENT '相对扩展公钥的派生路径，如：0/5；描述符为展开序号';
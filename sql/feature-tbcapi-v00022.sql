@@ -0,0 +1,6 @@
+-- 代币创建者可通过签名证明控制创世输出，核验方式区分人工核验和创建者签名核验
+ALTER TABLE TBC20721.token_registry
+    ADD COLUMN verification_method VARCHAR(16) NOT NULL DEFAULT '' COMMENT '核验方式：registry为人工核验，creator为创建者签名核验，未核验为空' AFTER verified,
+    ADD COLUMN verified_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近一次核验通过的时间' AFTER verification_method;
+
+UPDATE TBC20721.token_registry SET verification_method = 'registry' WHERE verified = TRUE;
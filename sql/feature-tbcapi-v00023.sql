@@ -0,0 +1,33 @@
+-- 池兑换记录表，池NFT交易前后LP数量不变且TBC和代币储备反向变化时视为一次兑换
+-- 由后台任务消费代币转账事件写入，重复投递时按交易去重
+CREATE TABLE IF NOT EXISTS TBC20721.ft_pool_swaps (
+    txid CHAR(64) NOT NULL COMMENT '兑换交易哈希，即新的池NFT交易',
+    ft_contract_id CHAR(64) NOT NULL COMMENT '代币合约ID',
+    height BIGINT NOT NULL COMMENT '兑换交易所在区块高度',
+    block_time BIGINT NOT NULL COMMENT '区块时间戳',
+    tx_index INT NOT NULL DEFAULT 0 COMMENT '交易在区块中的位置',
+    tbc_change BIGINT NOT NULL COMMENT '池中TBC的变化量(聪)，正数表示买入代币',
+    ft_change BIGINT NOT NULL COMMENT '池中代币的变化量，未按精度换算',
+    tbc_reserve BIGINT NOT NULL COMMENT '兑换后池中的TBC储备(聪)',
+    ft_reserve BIGINT NOT NULL COMMENT '兑换后池中的代币储备，未按精度换算',
+    price DOUBLE NOT NULL COMMENT '成交价格，每个代币的TBC数量，已按精度换算',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    PRIMARY KEY (txid),
+    INDEX idx_contract_time (ft_contract_id, block_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='池兑换记录表';
+
+-- 代币价格K线表，由池兑换记录按区块时间聚合，每次写入兑换记录后重建受影响的时间段
+CREATE TABLE IF NOT EXISTS TBC20721.ft_price_candles (
+    ft_contract_id CHAR(64) NOT NULL COMMENT '代币合约ID',
+    interval_name VARCHAR(8) NOT NULL COMMENT 'K线周期：1m、1h、1d',
+    bucket_start BIGINT NOT NULL COMMENT '时间段起始的Unix时间戳',
+    open DOUBLE NOT NULL COMMENT '开盘价',
+    high DOUBLE NOT NULL COMMENT '最高价',
+    low DOUBLE NOT NULL COMMENT '最低价',
+    close DOUBLE NOT NULL COMMENT '收盘价',
+    volume_tbc BIGINT UNSIGNED NOT NULL COMMENT 'TBC成交量(聪)',
+    volume_ft BIGINT UNSIGNED NOT NULL COMMENT '代币成交量，未按精度换算',
+    trades INT NOT NULL COMMENT '成交笔数',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最近一次重建时间',
+    PRIMARY KEY (ft_contract_id, interval_name, bucket_start)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='代币价格K线表';
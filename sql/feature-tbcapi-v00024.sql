@@ -0,0 +1,8 @@
+-- 跟踪钱包同步重试，后台任务按最近一次尝试同步的时间排序，连续失败的钱包按退避时间推迟重试
+ALTER TABLE TBC20721.tracked_wallets
+    ADD COLUMN last_attempt_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近一次尝试同步的时间，无论成功与否',
+    ADD COLUMN sync_failures INT NOT NULL DEFAULT 0 COMMENT '连续同步失败的次数，同步成功后清零',
+    ADD COLUMN next_attempt_at TIMESTAMP NULL DEFAULT NULL COMMENT '同步失败后下一次允许重试的时间',
+    ADD INDEX idx_last_attempt_at (last_attempt_at);
+
+UPDATE TBC20721.tracked_wallets SET last_attempt_at = synced_at WHERE last_attempt_at IS NULL;